@@ -0,0 +1,83 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package agent
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/nomad-autoscaler/agent/config"
+	"github.com/hashicorp/nomad-autoscaler/plugins"
+	"github.com/hashicorp/nomad-autoscaler/policy"
+	nomadHelper "github.com/hashicorp/nomad-autoscaler/sdk/helper/nomad"
+)
+
+// RequiredNomadCapabilities returns the Nomad ACL capabilities required by
+// the policy sources and target plugins configured in the agent.
+func RequiredNomadCapabilities(cfg *config.Agent) []nomadHelper.Capability {
+	var caps []nomadHelper.Capability
+
+	if cfg.Policy != nil {
+		for _, s := range cfg.Policy.Sources {
+			if policy.SourceName(s.Name) == policy.SourceNameNomad && s.Enabled != nil && *s.Enabled {
+				caps = append(caps, nomadHelper.CapabilityListScalingPolicies)
+				break
+			}
+		}
+	}
+
+	// The Nomad target scales jobs, while any other target scales the
+	// cluster and drains the nodes removed.
+	var horizontal, cluster bool
+	for _, t := range cfg.Targets {
+		if t.Driver == plugins.InternalTargetNomad {
+			horizontal = true
+		} else {
+			cluster = true
+		}
+	}
+	if horizontal {
+		caps = append(caps, nomadHelper.CapabilityReadJob, nomadHelper.CapabilityScaleJob)
+	}
+	if cluster {
+		caps = append(caps, nomadHelper.CapabilityNodeWrite)
+	}
+
+	return caps
+}
+
+// CheckNomadACL verifies the Nomad ACL token of the agent has the
+// capabilities required by the agent, according to the nomad acl_preflight
+// configuration. It returns an error listing the missing capabilities only
+// when the check is enforced. Failures to reach Nomad are logged, since the
+// agent retries its requests once Nomad is available.
+func (a *Agent) CheckNomadACL() error {
+	mode := a.config.Nomad.ACLPreflight
+	if mode == config.ACLPreflightDisabled {
+		return nil
+	}
+
+	missing, err := nomadHelper.MissingCapabilities(
+		a.NomadClient, a.nomadCfg.Namespace, RequiredNomadCapabilities(a.config))
+	if err != nil {
+		a.logger.Warn("failed to check Nomad ACL token capabilities", "error", err)
+		return nil
+	}
+	if len(missing) == 0 {
+		a.logger.Debug("Nomad ACL token has the required capabilities")
+		return nil
+	}
+
+	names := make([]string, len(missing))
+	for i, c := range missing {
+		names[i] = c.String()
+	}
+
+	if mode == config.ACLPreflightWarn {
+		a.logger.Warn("Nomad ACL token is missing required capabilities",
+			"namespace", a.nomadCfg.Namespace, "capabilities", strings.Join(names, ", "))
+		return nil
+	}
+	return fmt.Errorf("ACL token is missing required Nomad capabilities: %s", strings.Join(names, ", "))
+}
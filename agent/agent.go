@@ -8,14 +8,18 @@ import (
 	"fmt"
 	"os"
 	"os/signal"
+	"sync"
 	"syscall"
 
 	metrics "github.com/armon/go-metrics"
 	"github.com/hashicorp/go-hclog"
 	"github.com/hashicorp/nomad-autoscaler/agent/config"
+	"github.com/hashicorp/nomad-autoscaler/agent/winsvc"
 	"github.com/hashicorp/nomad-autoscaler/plugins/manager"
 	"github.com/hashicorp/nomad-autoscaler/policy"
 	filePolicy "github.com/hashicorp/nomad-autoscaler/policy/file"
+	haPolicy "github.com/hashicorp/nomad-autoscaler/policy/ha"
+	nodePoolPolicy "github.com/hashicorp/nomad-autoscaler/policy/nodepool"
 	nomadPolicy "github.com/hashicorp/nomad-autoscaler/policy/nomad"
 	"github.com/hashicorp/nomad-autoscaler/policyeval"
 	"github.com/hashicorp/nomad-autoscaler/sdk"
@@ -23,11 +27,17 @@ import (
 	"github.com/hashicorp/nomad/api"
 )
 
+// evalTokensPathSuffix is appended to the HA lock path to build the path
+// prefix of the variables where the tokens of the evaluations in flight are
+// recorded.
+const evalTokensPathSuffix = "/evaluations"
+
 type Agent struct {
 	NomadClient *api.Client
 
 	logger        hclog.Logger
 	config        *config.Agent
+	configLock    sync.RWMutex
 	configPaths   []string
 	pluginManager *manager.PluginManager
 	policySources map[policy.SourceName]policy.Source
@@ -35,6 +45,18 @@ type Agent struct {
 	inMemSink     *metrics.InmemSink
 	evalBroker    *policyeval.Broker
 
+	// evalTokens is where the tokens of the evaluations in flight are shared
+	// with other agents when running in HA mode. It's nil otherwise.
+	evalTokens *haPolicy.VariableEvalTokens
+
+	// evalStore is where the pending evaluations are persisted so they
+	// survive agent restarts. It's nil when persistence is disabled.
+	evalStore *haPolicy.VariableEvalStore
+
+	// approvals holds the scaling actions waiting for manual approval. It is
+	// exposed through the HTTP API.
+	approvals *policyeval.ApprovalQueue
+
 	// nomadCfg is the merged Nomad API configuration that should be used when
 	// setting up all clients. It is the result of the Nomad api.DefaultConfig
 	// merged with the user-specified Nomad config.Nomad.
@@ -43,16 +65,28 @@ type Agent struct {
 	// entReload is used to notify the Enterprise license watcher to reload its
 	// configuration.
 	entReload chan any
+
+	// subsystemLoggers holds the loggers of the agent subsystems whose level
+	// can be changed at runtime.
+	subsystemLoggers     map[string]hclog.Logger
+	subsystemLoggersLock sync.RWMutex
+
+	// configuredLogLevel is the log level set in the agent configuration.
+	configuredLogLevel hclog.Level
 }
 
 func NewAgent(c *config.Agent, configPaths []string, logger hclog.Logger) *Agent {
-	return &Agent{
+	a := &Agent{
 		logger:      logger,
 		config:      c,
 		configPaths: configPaths,
 		nomadCfg:    nomadHelper.MergeDefaultWithAgentConfig(c.Nomad),
 		entReload:   make(chan any),
+		approvals:   policyeval.NewApprovalQueue(),
 	}
+	a.setupLoggers()
+	a.setupProxy()
+	return a
 }
 
 func (a *Agent) Run(ctx context.Context) error {
@@ -83,11 +117,47 @@ func (a *Agent) Run(ctx context.Context) error {
 
 	// Launch eval broker and workers.
 	a.evalBroker = policyeval.NewBroker(
-		a.logger.ResetNamed("policy_eval"),
+		a.subsystemLoggers[logSubsystemPolicyEval],
 		a.config.PolicyEval.AckTimeout,
 		a.config.PolicyEval.DeliveryLimit)
+
+	// In HA mode, the tokens of the evaluations in flight are shared with
+	// the other agents so a new leader doesn't evaluate a policy that the
+	// previous leader is still evaluating.
+	if ha := a.config.HighAvailability; ha != nil && ha.Enabled != nil && *ha.Enabled {
+		a.evalTokens = haPolicy.NewVariableEvalTokens(
+			a.NomadClient, ha.LockNamespace, ha.LockPath+evalTokensPathSuffix)
+		a.evalBroker.SetEvalTokenStore(a.evalTokens)
+	}
+
+	// Persist pending evaluations and replay the ones left by the previous
+	// run of the agent.
+	if path := a.config.PolicyEval.PersistPath; path != "" {
+		a.evalStore = haPolicy.NewVariableEvalStore(a.NomadClient, a.nomadCfg.Namespace, path)
+		a.evalBroker.SetEvalStore(a.evalStore)
+
+		if _, err := a.evalBroker.Restore(a.config.PolicyEval.PersistMaxAge); err != nil {
+			a.logger.Warn("failed to restore persisted evaluations", "error", err)
+		}
+	}
 	a.initWorkers(ctx)
 
+	// Launch the monitor that recovers policies stuck scaling their target.
+	stuckScalingMonitor := policyeval.NewStuckScalingMonitor(
+		a.subsystemLoggers[logSubsystemPolicyEval],
+		a.pluginManager,
+		a.policyManager,
+		a.config.PolicyEval.StuckScalingMultiplier)
+	go stuckScalingMonitor.Run(ctx)
+
+	// Launch the monitor that detects targets modified outside the autoscaler.
+	driftMonitor := policyeval.NewDriftMonitor(
+		a.subsystemLoggers[logSubsystemPolicyEval],
+		a.pluginManager,
+		a.policyManager,
+		a.config.PolicyEval.DriftCheckInterval)
+	go driftMonitor.Run(ctx)
+
 	a.initEnt(ctx, a.entReload)
 
 	// Launch the eval handler.
@@ -111,7 +181,7 @@ func (a *Agent) runEvalHandler(ctx context.Context, evalCh chan *sdk.ScalingEval
 }
 
 func (a *Agent) initWorkers(ctx context.Context) {
-	policyEvalLogger := a.logger.ResetNamed("policy_eval")
+	policyEvalLogger := a.subsystemLoggers[logSubsystemPolicyEval]
 
 	workersCount := []interface{}{}
 	for k, v := range a.config.PolicyEval.Workers {
@@ -121,13 +191,13 @@ func (a *Agent) initWorkers(ctx context.Context) {
 
 	for i := 0; i < a.config.PolicyEval.Workers["horizontal"]; i++ {
 		w := policyeval.NewBaseWorker(
-			policyEvalLogger, a.pluginManager, a.policyManager, a.evalBroker, "horizontal")
+			policyEvalLogger, a.pluginManager, a.policyManager, a.evalBroker, a.approvals, a.config.PolicyEval.Explain, "horizontal")
 		go w.Run(ctx)
 	}
 
 	for i := 0; i < a.config.PolicyEval.Workers["cluster"]; i++ {
 		w := policyeval.NewBaseWorker(
-			policyEvalLogger, a.pluginManager, a.policyManager, a.evalBroker, "cluster")
+			policyEvalLogger, a.pluginManager, a.policyManager, a.evalBroker, a.approvals, a.config.PolicyEval.Explain, "cluster")
 		go w.Run(ctx)
 	}
 }
@@ -139,6 +209,7 @@ func (a *Agent) setupPolicyManager() (chan *sdk.ScalingEvaluation, error) {
 	cfgDefaults := policy.ConfigDefaults{
 		DefaultEvaluationInterval: a.config.Policy.DefaultEvaluationInterval,
 		DefaultCooldown:           a.config.Policy.DefaultCooldown,
+		DefaultQueryWindowOffset:  a.config.Policy.DefaultQueryWindowOffset,
 	}
 	policyProcessor := policy.NewProcessor(&cfgDefaults, a.getNomadAPMNames())
 
@@ -151,13 +222,20 @@ func (a *Agent) setupPolicyManager() (chan *sdk.ScalingEvaluation, error) {
 
 		switch policy.SourceName(s.Name) {
 		case policy.SourceNameNomad:
-			sources[policy.SourceNameNomad] = nomadPolicy.NewNomadSource(a.logger, a.NomadClient, policyProcessor)
+			nomadSource := nomadPolicy.NewNomadSource(a.logger, a.NomadClient, policyProcessor)
+			nomadSource.SetNamespaceRules(a.namespaceRules())
+			sources[policy.SourceNameNomad] = nomadSource
 		case policy.SourceNameFile:
 			// Only setup the file source if operators have configured a
 			// scaling policy directory to read from.
 			if a.config.Policy.Dir != "" {
+				if err := a.checkPolicyDirPermissions(); err != nil {
+					return nil, err
+				}
 				sources[policy.SourceNameFile] = filePolicy.NewFileSource(a.logger, a.config.Policy.Dir, policyProcessor)
 			}
+		case policy.SourceNameNodePool:
+			sources[policy.SourceNameNodePool] = nodePoolPolicy.NewNodePoolSource(a.logger, a.NomadClient, policyProcessor)
 		}
 	}
 
@@ -167,12 +245,51 @@ func (a *Agent) setupPolicyManager() (chan *sdk.ScalingEvaluation, error) {
 		return nil, fmt.Errorf("no policy source available")
 	}
 
+	// Setup the policy mutators configured by operators.
+	var mutators []policy.Mutator
+	for _, m := range a.config.Policy.Mutators {
+		mutator, err := policy.NewMutator(m.Type, m.Config)
+		if err != nil {
+			return nil, err
+		}
+		mutators = append(mutators, mutator)
+	}
+
+	var guardrails *policy.Guardrails
+	if g := a.config.Guardrails; g != nil {
+		guardrails = &policy.Guardrails{
+			MaxCount:               g.MaxCount,
+			MaxStep:                g.MaxStep,
+			DenyTargets:            g.DenyTargets,
+			DenyNamespaces:         g.DenyNamespaces,
+			MinEvaluationInterval:  g.MinEvaluationInterval,
+			MinCooldown:            g.MinCooldown,
+			MaxTargetActions:       g.MaxTargetActions,
+			MaxTargetActionsPeriod: g.MaxTargetActionsPeriod,
+		}
+	}
+
 	a.policySources = sources
-	a.policyManager = policy.NewManager(a.logger, a.policySources, a.pluginManager, a.config.Telemetry.CollectionInterval)
+	a.policyManager = policy.NewManager(a.subsystemLoggers[logSubsystemPolicyManager],
+		a.policySources, a.pluginManager, a.config.Telemetry.CollectionInterval, mutators, guardrails)
 
 	return make(chan *sdk.ScalingEvaluation, 10), nil
 }
 
+// namespaceRules returns the rules used by the Nomad policy source to
+// authorize policies from each namespace.
+func (a *Agent) namespaceRules() map[string]*nomadPolicy.NamespaceRule {
+	rules := make(map[string]*nomadPolicy.NamespaceRule, len(a.config.Policy.Namespaces))
+	for _, ns := range a.config.Policy.Namespaces {
+		rules[ns.Name] = &nomadPolicy.NamespaceRule{
+			Targets:    ns.Targets,
+			Strategies: ns.Strategies,
+			APMs:       ns.APMs,
+		}
+	}
+	return rules
+}
+
 func (a *Agent) stop() {
 	// Kill all the plugins.
 	if a.pluginManager != nil {
@@ -201,13 +318,21 @@ func (a *Agent) reload() {
 	// Reload config files from disk.
 	// Exit on error so operators can detect and correct configuration early.
 	// TODO: revisit this once we have a better mechanism for surfacing errors.
-	newCfg, err := config.LoadPaths(a.configPaths)
+	newCfg, warnings, err := config.LoadPaths(a.configPaths, !a.config.NoStrict)
+	for _, w := range warnings {
+		a.logger.Warn("invalid Autoscaler configuration", "warning", w.Error())
+	}
 	if err != nil {
 		a.logger.Error("failed to reload Autoscaler configuration", "error", err)
 		os.Exit(1)
 	}
 
+	// Keep the parsing mode set using the CLI flag.
+	newCfg.NoStrict = a.config.NoStrict
+
+	a.configLock.Lock()
 	a.config = newCfg
+	a.configLock.Unlock()
 	a.nomadCfg = nomadHelper.MergeDefaultWithAgentConfig(newCfg.Nomad)
 
 	if err := a.GenerateNomadClient(); err != nil {
@@ -222,9 +347,20 @@ func (a *Agent) reload() {
 	ps, ok := a.policySources[policy.SourceNameNomad]
 	if ok {
 		ps.(*nomadPolicy.Source).SetNomadClient(a.NomadClient)
+		ps.(*nomadPolicy.Source).SetNamespaceRules(a.namespaceRules())
+	}
+	if ps, ok := a.policySources[policy.SourceNameNodePool]; ok {
+		ps.(*nodePoolPolicy.Source).SetNomadClient(a.NomadClient)
 	}
 	a.policyManager.ReloadSources()
 
+	if a.evalTokens != nil {
+		a.evalTokens.SetNomadClient(a.NomadClient)
+	}
+	if a.evalStore != nil {
+		a.evalStore.SetNomadClient(a.NomadClient)
+	}
+
 	a.logger.Debug("reloading plugins")
 	if err := a.pluginManager.Reload(a.setupPluginsConfig()); err != nil {
 		a.logger.Error("failed to reload plugins", "error", err)
@@ -236,19 +372,30 @@ func (a *Agent) handleSignals() {
 
 	signalCh := make(chan os.Signal, 3)
 	signal.Notify(signalCh, os.Interrupt, syscall.SIGTERM, syscall.SIGHUP)
+	if logLevelSignal != nil {
+		signal.Notify(signalCh, logLevelSignal)
+	}
 
-	// Wait to receive a signal. This blocks until we are notified.
+	// Wait to receive a signal. This blocks until we are notified. When
+	// running as a Windows service, the service control requests are received
+	// as signals too.
 	for {
-		sig := <-signalCh
+		var sig os.Signal
+		select {
+		case sig = <-signalCh:
+		case sig = <-winsvc.SignalChannel():
+		}
 
 		a.logger.Info("caught signal", "signal", sig.String())
 
 		// Check the signal we received. If it was a SIGHUP perform the reload
-		// tasks and then continue to wait for another signal. Everything else
-		// means exit.
+		// tasks and if it was the log level signal toggle the log level, then
+		// continue to wait for another signal. Everything else means exit.
 		switch sig {
 		case syscall.SIGHUP:
 			a.reload()
+		case logLevelSignal:
+			a.toggleLogLevel()
 		default:
 			return
 		}
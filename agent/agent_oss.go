@@ -6,9 +6,16 @@
 
 package agent
 
-import "context"
+import (
+	"context"
+
+	"github.com/hashicorp/nomad-autoscaler/policyeval"
+	"github.com/hashicorp/nomad-autoscaler/sdk"
+)
 
 func (a *Agent) initEnt(ctx context.Context, reload <-chan any) {
+	a.initVerticalWorkers(ctx)
+
 	go func() {
 		for {
 			select {
@@ -20,3 +27,20 @@ func (a *Agent) initEnt(ctx context.Context, reload <-chan any) {
 		}
 	}()
 }
+
+// initVerticalWorkers starts the workers which evaluate the vertical policies
+// loaded from the file policy source. These are handled by the base worker
+// and use the same plugins as horizontal policies, which provides basic
+// rightsizing of task resources.
+func (a *Agent) initVerticalWorkers(ctx context.Context) {
+	policyEvalLogger := a.subsystemLoggers[logSubsystemPolicyEval]
+
+	queues := []string{sdk.ScalingPolicyTypeVerticalCPU, sdk.ScalingPolicyTypeVerticalMem, sdk.ScalingPolicyTypeVertical}
+	for _, queue := range queues {
+		for i := 0; i < a.config.PolicyEval.Workers[queue]; i++ {
+			w := policyeval.NewBaseWorker(
+				policyEvalLogger, a.pluginManager, a.policyManager, a.evalBroker, a.approvals, a.config.PolicyEval.Explain, queue)
+			go w.Run(ctx)
+		}
+	}
+}
@@ -6,18 +6,25 @@ package config
 import (
 	"errors"
 	"fmt"
+	"net/url"
 	"os"
 	"path/filepath"
 	"sort"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/hashicorp/go-multierror"
-	"github.com/hashicorp/hcl/v2/hclsimple"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/gohcl"
+	"github.com/hashicorp/hcl/v2/hclparse"
 	"github.com/hashicorp/nomad-autoscaler/plugins"
 	"github.com/hashicorp/nomad-autoscaler/sdk/helper/file"
 	"github.com/hashicorp/nomad-autoscaler/sdk/helper/ptr"
 	"github.com/hashicorp/nomad/api"
 	"github.com/mitchellh/copystructure"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
 )
 
 // Agent is the overall configuration of an autoscaler agent and includes all
@@ -49,10 +56,39 @@ type Agent struct {
 	// PluginDir is the directory that holds the autoscaler plugin binaries.
 	PluginDir string `hcl:"plugin_dir,optional"`
 
+	// PermissionChecks controls the checks performed on the ownership and
+	// permissions of the plugin binaries and policy files. It must be one of
+	// PermissionChecksEnforce, PermissionChecksWarn, or
+	// PermissionChecksDisabled.
+	PermissionChecks string `hcl:"permission_checks,optional"`
+
+	// PluginIdleTimeout enables lazy dispensing of external plugins. When
+	// set, external plugin processes are only launched once a policy uses
+	// them and are shut down after being unused for this period. A zero value
+	// launches all configured plugins on startup and keeps them running.
+	PluginIdleTimeout    time.Duration
+	PluginIdleTimeoutHCL string `hcl:"plugin_idle_timeout,optional" json:"-"`
+
+	// NoStrict reports unknown arguments and blocks in config files as
+	// warnings instead of errors. It can only be set using the -no-strict CLI
+	// flag, since it controls how config files are parsed.
+	NoStrict bool
+
+	// DevFaultInjection injects faults into APM queries, target calls and
+	// plugins at the configured rates. It is meant for testing the
+	// resilience of the agent and the alerting of operators in development
+	// environments, usually along with the simulator target, and can only be
+	// set using the -dev-fault-injection CLI flag.
+	DevFaultInjection *FaultInjection
+
 	// DynamicApplicationSizing is the configuration for the components used
 	// in Dynamic Application Sizing.
 	DynamicApplicationSizing *DynamicApplicationSizing `hcl:"dynamic_application_sizing,block" modes:"ent"`
 
+	// Guardrails are the limits enforced on all scaling policies, regardless
+	// of what individual policies request.
+	Guardrails *Guardrails `hcl:"guardrails,block"`
+
 	// HTTP is the configuration used to setup the HTTP health server.
 	HTTP *HTTP `hcl:"http,block"`
 
@@ -66,6 +102,10 @@ type Agent struct {
 	// to start for each policy type.
 	PolicyEval *PolicyEval `hcl:"policy_eval,block"`
 
+	// Proxy is the configuration of the HTTP proxies used to reach the Nomad
+	// API and the external services queried by plugins.
+	Proxy *Proxy `hcl:"proxy,block"`
+
 	// Telemetry is the configuration used to setup metrics collection.
 	Telemetry *Telemetry `hcl:"telemetry,block"`
 
@@ -75,6 +115,7 @@ type Agent struct {
 	APMs       []*Plugin `hcl:"apm,block"`
 	Targets    []*Plugin `hcl:"target,block"`
 	Strategies []*Plugin `hcl:"strategy,block"`
+	EventSinks []*Plugin `hcl:"event_sink,block"`
 }
 
 // DynamicApplicationSizing contains configuration values to control the
@@ -114,11 +155,31 @@ type DynamicApplicationSizing struct {
 // health server.
 type HTTP struct {
 
-	// BindAddress is the tcp address to bind to.
+	// BindAddress is the tcp address to bind to. An address using the
+	// unix:// scheme binds to the Unix socket at the given path instead.
 	BindAddress string `hcl:"bind_address,optional"`
 
 	// BindPort is the port used to run the HTTP server.
 	BindPort int `hcl:"bind_port,optional"`
+
+	// TLSCertFile is the path to a PEM-encoded certificate used to serve the
+	// HTTP API over TLS. It must be set alongside TLSKeyFile.
+	TLSCertFile string `hcl:"tls_cert_file,optional"`
+
+	// TLSKeyFile is the path to the PEM-encoded private key of TLSCertFile.
+	TLSKeyFile string `hcl:"tls_key_file,optional"`
+
+	// TLSCAFile is the path to a PEM-encoded CA cert file used to verify the
+	// certificates presented by HTTP API clients.
+	TLSCAFile string `hcl:"tls_ca_file,optional"`
+
+	// TLSVerifyClient requires HTTP API clients to present a certificate
+	// signed by the CA in TLSCAFile.
+	TLSVerifyClient bool `hcl:"tls_verify_client,optional"`
+
+	// Token, if set, must be provided as a bearer token in the Authorization
+	// header of all HTTP API requests, except for the health endpoint.
+	Token string `hcl:"token,optional"`
 }
 
 // Nomad holds the user specified configuration for connectivity to the Nomad
@@ -166,6 +227,11 @@ type Nomad struct {
 	// are held open. Defaults to 5m.
 	BlockQueryWaitTime    time.Duration
 	BlockQueryWaitTimeHCL string `hcl:"block_query_wait_time,optional"`
+
+	// ACLPreflight controls the check of the capabilities of the ACL token
+	// performed when the agent starts. It must be one of ACLPreflightEnforce,
+	// ACLPreflightWarn, or ACLPreflightDisabled.
+	ACLPreflight string `hcl:"acl_preflight,optional"`
 }
 
 // Telemetry holds the user specified configuration for metrics collection.
@@ -316,6 +382,33 @@ type Plugin struct {
 	Driver string            `hcl:"driver"`
 	Args   []string          `hcl:"args,optional"`
 	Config map[string]string `hcl:"config,optional"`
+
+	// PolicyOverrides is the list of config keys that scaling policies are
+	// allowed to override. Policies that override plugin config use a
+	// separate plugin instance, which is stopped once no policy uses it.
+	PolicyOverrides []string `hcl:"policy_overrides,optional"`
+
+	// CallTimeout is the maximum duration of each call made to an external
+	// plugin. It applies in addition to the timeout derived from the policy
+	// evaluation interval, with the shortest of the two being used. A zero
+	// value does not limit the duration of calls.
+	CallTimeout    time.Duration
+	CallTimeoutHCL string `hcl:"call_timeout,optional" json:"-"`
+
+	// MaxConcurrentCalls is the maximum number of calls that can be in
+	// progress to an external plugin at once. Calls above the limit wait for
+	// a previous call to finish. A zero value does not limit concurrency.
+	MaxConcurrentCalls int `hcl:"max_concurrent_calls,optional"`
+
+	// StartupTimeout is the maximum duration to wait for an external plugin
+	// to start. Defaults to one minute.
+	StartupTimeout    time.Duration
+	StartupTimeoutHCL string `hcl:"startup_timeout,optional" json:"-"`
+
+	// SlowCallThreshold is the duration above which calls to an external
+	// plugin are logged and counted as slow. Defaults to five seconds.
+	SlowCallThreshold    time.Duration
+	SlowCallThresholdHCL string `hcl:"slow_call_threshold,optional" json:"-"`
 }
 
 // Policy holds the configuration information specific to the policy manager
@@ -336,8 +429,24 @@ type Policy struct {
 	DefaultEvaluationInterval    time.Duration
 	DefaultEvaluationIntervalHCL string `hcl:"default_evaluation_interval,optional" json:"-"`
 
+	// DefaultQueryWindowOffset is the query window offset used by policy
+	// checks when `query_window_offset` is not defined in the check or the
+	// policy. It can be used to account for known metric ingestion delays.
+	DefaultQueryWindowOffset    time.Duration
+	DefaultQueryWindowOffsetHCL string `hcl:"default_query_window_offset,optional" json:"-"`
+
 	// Sources store configuration for policy sources.
 	Sources []*PolicySource `hcl:"source,block"`
+
+	// Namespaces restrict the plugins that policies read from each Nomad
+	// namespace are allowed to use. The "*" namespace applies to namespaces
+	// without their own block.
+	Namespaces []*PolicyNamespace `hcl:"namespace,block"`
+
+	// Mutators store configuration for the mutators applied, in order, to
+	// all policies before they are evaluated. Changes require an agent
+	// restart.
+	Mutators []*PolicyMutator `hcl:"mutator,block"`
 }
 
 // PolicyEval holds the configuration related to the policy evaluation process.
@@ -354,6 +463,136 @@ type PolicyEval struct {
 
 	// Workers hold the number of workers to initialize for each queue.
 	Workers map[string]int `hcl:"workers,optional"`
+
+	// Explain enables the structured decision log for all policies. When
+	// false, the log can still be enabled for individual policies.
+	Explain bool `hcl:"explain,optional"`
+
+	// StuckScalingMultiplier is the multiple of the expected duration of the
+	// target scale operation after which a policy that is still scaling is
+	// considered stuck. The expected duration is based on the latency of the
+	// recent calls to the target plugin.
+	StuckScalingMultiplier float64 `hcl:"stuck_scaling_multiplier,optional"`
+
+	// DriftCheckInterval is the interval at which the last count desired by
+	// each policy is compared with the current count of its target, to detect
+	// targets modified outside the autoscaler. A negative value disables the
+	// check.
+	DriftCheckInterval    time.Duration
+	DriftCheckIntervalHCL string `hcl:"drift_check_interval,optional" json:"-"`
+
+	// PersistPath is the path prefix of the Nomad variables where pending
+	// evaluations are persisted, so they are replayed when the agent
+	// restarts. Persistence is disabled when empty.
+	//
+	// Each evaluation is stored in its own variable, which is written when
+	// the evaluation is enqueued and deleted once it's done. This adds about
+	// two Nomad variable writes per policy evaluation, which go through the
+	// Raft log of the Nomad servers, so short evaluation intervals across
+	// many policies increase the load on the servers accordingly.
+	PersistPath string `hcl:"persist_path,optional"`
+
+	// PersistMaxAge is the maximum age of the persisted evaluations replayed
+	// on startup. Older evaluations are discarded.
+	PersistMaxAge    time.Duration
+	PersistMaxAgeHCL string `hcl:"persist_max_age,optional" json:"-"`
+}
+
+// Proxy holds the HTTP proxy configuration of the agent. The values are
+// exported as the standard proxy environment variables on startup, so they
+// apply to the Nomad client, the builtin plugins and the external plugin
+// processes. Changes require an agent restart.
+type Proxy struct {
+	// HTTPProxy and HTTPSProxy are the URLs of the proxies used for HTTP and
+	// HTTPS requests respectively.
+	HTTPProxy  string `hcl:"http_proxy,optional"`
+	HTTPSProxy string `hcl:"https_proxy,optional"`
+
+	// NoProxy is a comma-separated list of hosts, domains and CIDR ranges
+	// which are accessed directly.
+	NoProxy string `hcl:"no_proxy,optional"`
+}
+
+// Guardrails holds the agent-level limits enforced on all scaling policies.
+// MaxCount and MaxStep are keyed by policy type, such as cluster or
+// horizontal. Changes require an agent restart.
+type Guardrails struct {
+	// MaxCount is the absolute maximum count of targets. Policies with a
+	// higher max value are limited to it.
+	MaxCount map[string]int64 `hcl:"max_count,optional"`
+
+	// MaxStep is the maximum difference between the current and the new
+	// count of a target in a single scaling action.
+	MaxStep map[string]int64 `hcl:"max_step,optional"`
+
+	// DenyTargets and DenyNamespaces are the target plugins and Nomad
+	// namespaces that policies are not allowed to scale.
+	DenyTargets    []string `hcl:"deny_targets,optional"`
+	DenyNamespaces []string `hcl:"deny_namespaces,optional"`
+
+	// MinEvaluationInterval and MinCooldown are the shortest evaluation
+	// interval and cooldown allowed for policies. Policies with shorter
+	// values are raised to them, which protects targets and APMs from
+	// policies that evaluate or scale too often.
+	MinEvaluationInterval    time.Duration
+	MinEvaluationIntervalHCL string `hcl:"min_evaluation_interval,optional" json:"-"`
+	MinCooldown              time.Duration
+	MinCooldownHCL           string `hcl:"min_cooldown,optional" json:"-"`
+
+	// MaxTargetActions is the maximum number of scaling actions a single
+	// target can receive within MaxTargetActionsPeriod, regardless of how
+	// many policies scale it. A zero value doesn't limit scaling actions.
+	MaxTargetActions          int `hcl:"max_target_actions,optional"`
+	MaxTargetActionsPeriod    time.Duration
+	MaxTargetActionsPeriodHCL string `hcl:"max_target_actions_period,optional" json:"-"`
+}
+
+// FaultInjection holds the probability, between 0 and 1, of each fault
+// injected by the agent in development mode.
+type FaultInjection struct {
+	// APMTimeout is the probability of APM queries timing out.
+	APMTimeout float64
+
+	// TargetError is the probability of target status and scale calls
+	// failing.
+	TargetError float64
+
+	// PluginCrash is the probability of plugins being killed when they are
+	// dispensed, as if they had crashed.
+	PluginCrash float64
+}
+
+// ParseFaultInjection parses a comma separated list of fault=rate pairs, such
+// as "apm_timeout=0.1,target_error=0.05,plugin_crash=0.01".
+func ParseFaultInjection(s string) (*FaultInjection, error) {
+	var f FaultInjection
+
+	for _, entry := range strings.Split(s, ",") {
+		name, raw, ok := strings.Cut(strings.TrimSpace(entry), "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid fault injection entry %q, must be in the form <fault>=<rate>", entry)
+		}
+
+		var dst *float64
+		switch strings.TrimSpace(name) {
+		case "apm_timeout":
+			dst = &f.APMTimeout
+		case "target_error":
+			dst = &f.TargetError
+		case "plugin_crash":
+			dst = &f.PluginCrash
+		default:
+			return nil, fmt.Errorf("unknown fault %q, must be one of apm_timeout, target_error, or plugin_crash", name)
+		}
+
+		rate, err := strconv.ParseFloat(strings.TrimSpace(raw), 64)
+		if err != nil || rate < 0 || rate > 1 {
+			return nil, fmt.Errorf("invalid rate %q for fault %s, must be a number between 0 and 1", raw, name)
+		}
+		*dst = rate
+	}
+
+	return &f, nil
 }
 
 // PolicySource is an individual configured policy source.
@@ -362,6 +601,47 @@ type PolicySource struct {
 	Enabled *bool  `hcl:"enabled,optional"`
 }
 
+// PolicyNamespace is the configuration of the plugins allowed for policies of
+// a Nomad namespace. Empty lists don't restrict the plugin type.
+type PolicyNamespace struct {
+	Name       string   `hcl:"name,label"`
+	Targets    []string `hcl:"targets,optional"`
+	Strategies []string `hcl:"strategies,optional"`
+	APMs       []string `hcl:"apms,optional"`
+}
+
+// PolicyMutator is an individual configured policy mutator.
+type PolicyMutator struct {
+	// Type is the mutator type, which defines the keys supported in Config.
+	Type string `hcl:"type,label"`
+
+	// Config is the mapping of config values used by the mutator.
+	Config map[string]string `hcl:"config,optional"`
+}
+
+const (
+	// PermissionChecksEnforce fails to launch plugins and load policies with
+	// unsafe ownership or permissions.
+	PermissionChecksEnforce = "enforce"
+
+	// PermissionChecksWarn logs plugins and policies with unsafe ownership or
+	// permissions. World-writable plugin binaries are still not launched.
+	PermissionChecksWarn = "warn"
+
+	// PermissionChecksDisabled disables all permission checks.
+	PermissionChecksDisabled = "disabled"
+
+	// ACLPreflightEnforce fails to start the agent if the Nomad ACL token is
+	// missing any of the capabilities required by the agent.
+	ACLPreflightEnforce = "enforce"
+
+	// ACLPreflightWarn logs the capabilities missing from the Nomad ACL token.
+	ACLPreflightWarn = "warn"
+
+	// ACLPreflightDisabled disables the Nomad ACL token check.
+	ACLPreflightDisabled = "disabled"
+)
+
 const (
 	// defaultLogLevel is the default log level used for the Autoscaler agent.
 	defaultLogLevel = "info"
@@ -384,6 +664,14 @@ const (
 	// which do not explicitly configure a cooldown.
 	defaultPolicyCooldown = 5 * time.Minute
 
+	// defaultGuardrailsMinEvaluationInterval is the default shortest
+	// evaluation interval allowed for policies.
+	defaultGuardrailsMinEvaluationInterval = time.Second
+
+	// defaultGuardrailsMaxTargetActionsPeriod is the default period in which
+	// the number of scaling actions of a target is limited.
+	defaultGuardrailsMaxTargetActionsPeriod = time.Hour
+
 	// defaultTelemetryCollectionInterval is the default telemetry metrics
 	// collection interval.
 	defaultTelemetryCollectionInterval = 1 * time.Second
@@ -396,6 +684,18 @@ const (
 	// eval must be ACK'd.
 	defaultPolicyEvalAckTimeout = 5 * time.Minute
 
+	// defaultPolicyEvalStuckScalingMultiplier is the default multiple of the
+	// expected target scale duration after which scaling is considered stuck.
+	defaultPolicyEvalStuckScalingMultiplier = 10
+
+	// defaultPolicyEvalDriftCheckInterval is the default interval at which
+	// policies are checked for drift between their desired and actual count.
+	defaultPolicyEvalDriftCheckInterval = 5 * time.Minute
+
+	// defaultPolicyEvalPersistMaxAge is the default maximum age of the
+	// persisted evaluations replayed on startup.
+	defaultPolicyEvalPersistMaxAge = 10 * time.Minute
+
 	// defaultLockPath is the default path used for the lock that syncs the leader
 	// election.
 	defaultLockPath = "nomad-autoscaler/lock"
@@ -420,11 +720,52 @@ const (
 	// policySourceNomad is the source for policies that originate from the
 	// Nomad scaling policies API.
 	policySourceNomad = "nomad"
+
+	// policySourceNodePool is the source for cluster policies that are
+	// attached to Nomad node pools.
+	policySourceNodePool = "node_pool"
+
+	// policyMutatorTargetConfig, policyMutatorMax and policyMutatorAPMSource
+	// are the supported policy mutator types.
+	policyMutatorTargetConfig = "target_config"
+	policyMutatorMax          = "max"
+	policyMutatorAPMSource    = "apm_source"
 )
 
 var defaultPolicyEvalWorkers = map[string]int{
-	"cluster":    10,
-	"horizontal": 10,
+	"cluster":      10,
+	"horizontal":   10,
+	"vertical_cpu": 2,
+	"vertical_mem": 2,
+	"vertical":     2,
+}
+
+// nomadFromEnv returns the default Nomad configuration populated from the
+// standard Nomad CLI environment variables. These are used as fallbacks, so
+// values set in config files or CLI flags take precedence.
+func nomadFromEnv() *Nomad {
+	n := &Nomad{
+		Address:       os.Getenv("NOMAD_ADDR"),
+		Region:        os.Getenv("NOMAD_REGION"),
+		Namespace:     os.Getenv("NOMAD_NAMESPACE"),
+		Token:         os.Getenv("NOMAD_TOKEN"),
+		HTTPAuth:      os.Getenv("NOMAD_HTTP_AUTH"),
+		CACert:        os.Getenv("NOMAD_CACERT"),
+		CAPath:        os.Getenv("NOMAD_CAPATH"),
+		ClientCert:    os.Getenv("NOMAD_CLIENT_CERT"),
+		ClientKey:     os.Getenv("NOMAD_CLIENT_KEY"),
+		TLSServerName: os.Getenv("NOMAD_TLS_SERVER_NAME"),
+
+		BlockQueryWaitTime: defaultBlockQueryWaitTime,
+		ACLPreflight:       ACLPreflightEnforce,
+	}
+
+	// Match the Nomad CLI and ignore invalid values.
+	if v := os.Getenv("NOMAD_SKIP_VERIFY"); v != "" {
+		n.SkipVerify, _ = strconv.ParseBool(v)
+	}
+
+	return n
 }
 
 // Default is used to generate a new default agent configuration.
@@ -440,14 +781,13 @@ func Default() (*Agent, error) {
 	return &Agent{
 		LogLevel:                 defaultLogLevel,
 		PluginDir:                pwd + defaultPluginDirSuffix,
+		PermissionChecks:         PermissionChecksWarn,
 		DynamicApplicationSizing: &DynamicApplicationSizing{},
 		HTTP: &HTTP{
 			BindAddress: defaultHTTPBindAddress,
 			BindPort:    defaultHTTPBindPort,
 		},
-		Nomad: &Nomad{
-			BlockQueryWaitTime: defaultBlockQueryWaitTime,
-		},
+		Nomad: nomadFromEnv(),
 		Telemetry: &Telemetry{
 			CollectionInterval: defaultTelemetryCollectionInterval,
 		},
@@ -460,9 +800,17 @@ func Default() (*Agent, error) {
 			},
 		},
 		PolicyEval: &PolicyEval{
-			DeliveryLimit: defaultPolicyEvalDeliveryLimit,
-			AckTimeout:    defaultPolicyEvalAckTimeout,
-			Workers:       defaultPolicyEvalWorkers,
+			DeliveryLimit:          defaultPolicyEvalDeliveryLimit,
+			AckTimeout:             defaultPolicyEvalAckTimeout,
+			Workers:                defaultPolicyEvalWorkers,
+			StuckScalingMultiplier: defaultPolicyEvalStuckScalingMultiplier,
+			DriftCheckInterval:     defaultPolicyEvalDriftCheckInterval,
+			PersistMaxAge:          defaultPolicyEvalPersistMaxAge,
+		},
+		Proxy: &Proxy{},
+		Guardrails: &Guardrails{
+			MinEvaluationInterval:  defaultGuardrailsMinEvaluationInterval,
+			MaxTargetActionsPeriod: defaultGuardrailsMaxTargetActionsPeriod,
 		},
 		APMs: []*Plugin{
 			{Name: plugins.InternalAPMNomad, Driver: plugins.InternalAPMNomad},
@@ -470,6 +818,7 @@ func Default() (*Agent, error) {
 		Strategies: []*Plugin{
 			{Name: plugins.InternalStrategyFixedValue, Driver: plugins.InternalStrategyFixedValue},
 			{Name: plugins.InternalStrategyPassThrough, Driver: plugins.InternalStrategyPassThrough},
+			{Name: plugins.InternalStrategyPercentile, Driver: plugins.InternalStrategyPercentile},
 			{Name: plugins.InternalStrategyTargetValue, Driver: plugins.InternalStrategyTargetValue},
 			{Name: plugins.InternalStrategyThreshold, Driver: plugins.InternalStrategyThreshold},
 		},
@@ -509,6 +858,18 @@ func (a *Agent) Merge(b *Agent) *Agent {
 	if b.PluginDir != "" {
 		result.PluginDir = b.PluginDir
 	}
+	if b.PermissionChecks != "" {
+		result.PermissionChecks = b.PermissionChecks
+	}
+	if b.PluginIdleTimeout != 0 {
+		result.PluginIdleTimeout = b.PluginIdleTimeout
+	}
+	if b.NoStrict {
+		result.NoStrict = true
+	}
+	if b.DevFaultInjection != nil {
+		result.DevFaultInjection = b.DevFaultInjection
+	}
 
 	if b.DynamicApplicationSizing != nil {
 		result.DynamicApplicationSizing = result.DynamicApplicationSizing.merge(b.DynamicApplicationSizing)
@@ -538,6 +899,14 @@ func (a *Agent) Merge(b *Agent) *Agent {
 		result.PolicyEval = result.PolicyEval.merge(b.PolicyEval)
 	}
 
+	if b.Proxy != nil {
+		result.Proxy = result.Proxy.merge(b.Proxy)
+	}
+
+	if b.Guardrails != nil {
+		result.Guardrails = result.Guardrails.merge(b.Guardrails)
+	}
+
 	if len(result.APMs) == 0 && len(b.APMs) != 0 {
 		apmCopy := make([]*Plugin, len(b.APMs))
 		for i, v := range b.APMs {
@@ -568,6 +937,16 @@ func (a *Agent) Merge(b *Agent) *Agent {
 		result.Strategies = pluginConfigSetMerge(result.Strategies, b.Strategies)
 	}
 
+	if len(result.EventSinks) == 0 && len(b.EventSinks) != 0 {
+		eventSinkCopy := make([]*Plugin, len(b.EventSinks))
+		for i, v := range b.EventSinks {
+			eventSinkCopy[i] = v.copy()
+		}
+		result.EventSinks = eventSinkCopy
+	} else if len(b.EventSinks) != 0 {
+		result.EventSinks = pluginConfigSetMerge(result.EventSinks, b.EventSinks)
+	}
+
 	return &result
 }
 
@@ -577,14 +956,63 @@ func (a *Agent) Validate() error {
 	modeChecker := NewModeChecker()
 	result = multierror.Append(result, modeChecker.ValidateStruct(a))
 
+	switch a.PermissionChecks {
+	case "", PermissionChecksEnforce, PermissionChecksWarn, PermissionChecksDisabled:
+	default:
+		result = multierror.Append(result, fmt.Errorf("invalid permission_checks %q, must be one of %s, %s, or %s",
+			a.PermissionChecks, PermissionChecksEnforce, PermissionChecksWarn, PermissionChecksDisabled))
+	}
+
+	if a.Nomad != nil {
+		switch a.Nomad.ACLPreflight {
+		case "", ACLPreflightEnforce, ACLPreflightWarn, ACLPreflightDisabled:
+		default:
+			result = multierror.Append(result, fmt.Errorf("invalid nomad acl_preflight %q, must be one of %s, %s, or %s",
+				a.Nomad.ACLPreflight, ACLPreflightEnforce, ACLPreflightWarn, ACLPreflightDisabled))
+		}
+	}
+
+	if a.PluginIdleTimeout < 0 {
+		result = multierror.Append(result, errors.New("plugin_idle_timeout must not be negative"))
+	}
+
+	for _, plugins := range [][]*Plugin{a.APMs, a.Targets, a.Strategies, a.EventSinks} {
+		for _, p := range plugins {
+			result = multierror.Append(result, p.validate())
+		}
+	}
+
+	if a.HTTP != nil {
+		result = multierror.Append(result, a.HTTP.validate())
+	}
+
 	if a.PolicyEval != nil {
 		result = multierror.Append(result, a.PolicyEval.validate())
 	}
 
+	if a.Proxy != nil {
+		result = multierror.Append(result, a.Proxy.validate())
+	}
+
+	if a.Guardrails != nil {
+		result = multierror.Append(result, a.Guardrails.validate())
+	}
+
 	if a.Policy != nil {
 		for _, s := range a.Policy.Sources {
 			result = multierror.Append(result, s.validate())
 		}
+		for _, m := range a.Policy.Mutators {
+			result = multierror.Append(result, m.validate())
+		}
+
+		namespaces := make(map[string]bool, len(a.Policy.Namespaces))
+		for _, ns := range a.Policy.Namespaces {
+			if namespaces[ns.Name] {
+				result = multierror.Append(result, fmt.Errorf("namespace[%s] -> duplicate namespace", ns.Name))
+			}
+			namespaces[ns.Name] = true
+		}
 	}
 
 	return result.ErrorOrNil()
@@ -638,10 +1066,189 @@ func (h *HTTP) merge(b *HTTP) *HTTP {
 	if b.BindPort != 0 {
 		result.BindPort = b.BindPort
 	}
+	if b.TLSCertFile != "" {
+		result.TLSCertFile = b.TLSCertFile
+	}
+	if b.TLSKeyFile != "" {
+		result.TLSKeyFile = b.TLSKeyFile
+	}
+	if b.TLSCAFile != "" {
+		result.TLSCAFile = b.TLSCAFile
+	}
+	if b.TLSVerifyClient {
+		result.TLSVerifyClient = b.TLSVerifyClient
+	}
+	if b.Token != "" {
+		result.Token = b.Token
+	}
+
+	return &result
+}
+
+// TLSEnabled returns whether the HTTP server should serve requests over TLS.
+func (h *HTTP) TLSEnabled() bool {
+	return h.TLSCertFile != "" || h.TLSKeyFile != ""
+}
+
+func (h *HTTP) validate() *multierror.Error {
+	var result *multierror.Error
+	prefix := "http ->"
+
+	if (h.TLSCertFile == "") != (h.TLSKeyFile == "") {
+		result = multierror.Append(result, errors.New("tls_cert_file and tls_key_file must be set together"))
+	}
+	if h.TLSCAFile != "" && !h.TLSEnabled() {
+		result = multierror.Append(result, errors.New("tls_ca_file requires tls_cert_file and tls_key_file"))
+	}
+	if h.TLSVerifyClient && h.TLSCAFile == "" {
+		result = multierror.Append(result, errors.New("tls_verify_client requires tls_ca_file"))
+	}
+
+	// Prefix all errors.
+	if result != nil {
+		for i, err := range result.Errors {
+			result.Errors[i] = multierror.Prefix(err, prefix)
+		}
+	}
+	return result
+}
+
+func (p *Proxy) merge(b *Proxy) *Proxy {
+	if p == nil {
+		return b
+	}
+
+	result := *p
+
+	if b.HTTPProxy != "" {
+		result.HTTPProxy = b.HTTPProxy
+	}
+	if b.HTTPSProxy != "" {
+		result.HTTPSProxy = b.HTTPSProxy
+	}
+	if b.NoProxy != "" {
+		result.NoProxy = b.NoProxy
+	}
+
+	return &result
+}
+
+func (p *Proxy) validate() *multierror.Error {
+	var result *multierror.Error
+	prefix := "proxy ->"
+
+	for _, v := range []struct{ key, val string }{
+		{"http_proxy", p.HTTPProxy},
+		{"https_proxy", p.HTTPSProxy},
+	} {
+		if v.val == "" {
+			continue
+		}
+		if u, err := url.Parse(v.val); err != nil || u.Scheme == "" || u.Host == "" {
+			result = multierror.Append(result, fmt.Errorf("%s must be a URL including the scheme, found %q", v.key, v.val))
+		}
+	}
+
+	// Prefix all errors.
+	if result != nil {
+		for i, err := range result.Errors {
+			result.Errors[i] = multierror.Prefix(err, prefix)
+		}
+	}
+	return result
+}
+
+func (g *Guardrails) merge(b *Guardrails) *Guardrails {
+	if g == nil {
+		return b
+	}
+
+	result := *g
+
+	if len(b.MaxCount) != 0 {
+		result.MaxCount = make(map[string]int64, len(g.MaxCount)+len(b.MaxCount))
+		for k, v := range g.MaxCount {
+			result.MaxCount[k] = v
+		}
+		for k, v := range b.MaxCount {
+			result.MaxCount[k] = v
+		}
+	}
+	if len(b.MaxStep) != 0 {
+		result.MaxStep = make(map[string]int64, len(g.MaxStep)+len(b.MaxStep))
+		for k, v := range g.MaxStep {
+			result.MaxStep[k] = v
+		}
+		for k, v := range b.MaxStep {
+			result.MaxStep[k] = v
+		}
+	}
+	if len(b.DenyTargets) != 0 {
+		result.DenyTargets = append(append([]string{}, g.DenyTargets...), b.DenyTargets...)
+	}
+	if len(b.DenyNamespaces) != 0 {
+		result.DenyNamespaces = append(append([]string{}, g.DenyNamespaces...), b.DenyNamespaces...)
+	}
+	if b.MinEvaluationInterval != 0 {
+		result.MinEvaluationInterval = b.MinEvaluationInterval
+	}
+	if b.MinCooldown != 0 {
+		result.MinCooldown = b.MinCooldown
+	}
+	if b.MaxTargetActions != 0 {
+		result.MaxTargetActions = b.MaxTargetActions
+	}
+	if b.MaxTargetActionsPeriod != 0 {
+		result.MaxTargetActionsPeriod = b.MaxTargetActionsPeriod
+	}
 
 	return &result
 }
 
+func (g *Guardrails) validate() *multierror.Error {
+	var result *multierror.Error
+	prefix := "guardrails ->"
+
+	for _, v := range []struct {
+		key    string
+		limits map[string]int64
+	}{
+		{"max_count", g.MaxCount},
+		{"max_step", g.MaxStep},
+	} {
+		for policyType, limit := range v.limits {
+			// Each policy type has a default number of workers.
+			if _, ok := defaultPolicyEvalWorkers[policyType]; !ok {
+				result = multierror.Append(result, fmt.Errorf("%s has invalid policy type %q", v.key, policyType))
+			}
+			if limit < 0 {
+				result = multierror.Append(result, fmt.Errorf("%s for %q must not be negative", v.key, policyType))
+			}
+		}
+	}
+
+	if g.MinEvaluationInterval < 0 {
+		result = multierror.Append(result, errors.New("min_evaluation_interval must not be negative"))
+	}
+	if g.MinCooldown < 0 {
+		result = multierror.Append(result, errors.New("min_cooldown must not be negative"))
+	}
+	if g.MaxTargetActions < 0 {
+		result = multierror.Append(result, errors.New("max_target_actions must not be negative"))
+	}
+	if g.MaxTargetActionsPeriod < 0 {
+		result = multierror.Append(result, errors.New("max_target_actions_period must not be negative"))
+	}
+
+	// Prefix all errors.
+	if result != nil {
+		for i, err := range result.Errors {
+			result.Errors[i] = multierror.Prefix(err, prefix)
+		}
+	}
+	return result
+}
+
 func (n *Nomad) merge(b *Nomad) *Nomad {
 	if n == nil {
 		return b
@@ -685,6 +1292,9 @@ func (n *Nomad) merge(b *Nomad) *Nomad {
 	if b.BlockQueryWaitTime != 0 {
 		result.BlockQueryWaitTime = b.BlockQueryWaitTime
 	}
+	if b.ACLPreflight != "" {
+		result.ACLPreflight = b.ACLPreflight
+	}
 
 	return &result
 }
@@ -809,10 +1419,44 @@ func (p *Plugin) merge(o *Plugin) *Plugin {
 	if len(o.Config) != 0 {
 		m.Config = o.Config
 	}
+	if len(o.PolicyOverrides) != 0 {
+		m.PolicyOverrides = o.PolicyOverrides
+	}
+	if o.CallTimeout != 0 {
+		m.CallTimeout = o.CallTimeout
+	}
+	if o.MaxConcurrentCalls != 0 {
+		m.MaxConcurrentCalls = o.MaxConcurrentCalls
+	}
+	if o.StartupTimeout != 0 {
+		m.StartupTimeout = o.StartupTimeout
+	}
+	if o.SlowCallThreshold != 0 {
+		m.SlowCallThreshold = o.SlowCallThreshold
+	}
 
 	return m.copy()
 }
 
+func (p *Plugin) validate() error {
+	var result *multierror.Error
+
+	if p.CallTimeout < 0 {
+		result = multierror.Append(result, fmt.Errorf("plugin %q call_timeout must not be negative", p.Name))
+	}
+	if p.MaxConcurrentCalls < 0 {
+		result = multierror.Append(result, fmt.Errorf("plugin %q max_concurrent_calls must not be negative", p.Name))
+	}
+	if p.StartupTimeout < 0 {
+		result = multierror.Append(result, fmt.Errorf("plugin %q startup_timeout must not be negative", p.Name))
+	}
+	if p.SlowCallThreshold < 0 {
+		result = multierror.Append(result, fmt.Errorf("plugin %q slow_call_threshold must not be negative", p.Name))
+	}
+
+	return result.ErrorOrNil()
+}
+
 func (p *Plugin) copy() *Plugin {
 	if p == nil {
 		return nil
@@ -843,6 +1487,9 @@ func (p *Policy) merge(b *Policy) *Policy {
 	if b.DefaultEvaluationInterval != 0 {
 		result.DefaultEvaluationInterval = b.DefaultEvaluationInterval
 	}
+	if b.DefaultQueryWindowOffset != 0 {
+		result.DefaultQueryWindowOffset = b.DefaultQueryWindowOffset
+	}
 
 	if len(result.Sources) == 0 && len(b.Sources) != 0 {
 		sourceCopy := make([]*PolicySource, len(b.Sources))
@@ -854,6 +1501,34 @@ func (p *Policy) merge(b *Policy) *Policy {
 		result.Sources = policySourceConfigSetMerge(result.Sources, b.Sources)
 	}
 
+	// Namespaces defined later replace the ones with the same name.
+	if len(b.Namespaces) != 0 {
+		index := make(map[string]int, len(result.Namespaces))
+		namespaces := make([]*PolicyNamespace, 0, len(result.Namespaces)+len(b.Namespaces))
+		for _, ns := range append(append([]*PolicyNamespace{}, result.Namespaces...), b.Namespaces...) {
+			if i, ok := index[ns.Name]; ok {
+				namespaces[i] = ns
+				continue
+			}
+			index[ns.Name] = len(namespaces)
+			namespaces = append(namespaces, ns)
+		}
+		result.Namespaces = namespaces
+	}
+
+	// Mutators are applied in order, so the ones defined later are appended
+	// to the chain.
+	if len(b.Mutators) != 0 {
+		mutators := make([]*PolicyMutator, 0, len(result.Mutators)+len(b.Mutators))
+		for _, m := range result.Mutators {
+			mutators = append(mutators, m.copy())
+		}
+		for _, m := range b.Mutators {
+			mutators = append(mutators, m.copy())
+		}
+		result.Mutators = mutators
+	}
+
 	return &result
 }
 
@@ -877,6 +1552,26 @@ func (pw *PolicyEval) merge(in *PolicyEval) *PolicyEval {
 		result.Workers[k] = v
 	}
 
+	if in.Explain {
+		result.Explain = true
+	}
+
+	if in.StuckScalingMultiplier != 0 {
+		result.StuckScalingMultiplier = in.StuckScalingMultiplier
+	}
+
+	if in.DriftCheckInterval != 0 {
+		result.DriftCheckInterval = in.DriftCheckInterval
+	}
+
+	if in.PersistPath != "" {
+		result.PersistPath = in.PersistPath
+	}
+
+	if in.PersistMaxAge != 0 {
+		result.PersistMaxAge = in.PersistMaxAge
+	}
+
 	return &result
 }
 
@@ -894,6 +1589,14 @@ func (pw *PolicyEval) validate() *multierror.Error {
 		}
 	}
 
+	if pw.StuckScalingMultiplier != 0 && pw.StuckScalingMultiplier < 1 {
+		result = multierror.Append(result, errors.New("stuck_scaling_multiplier must be at least 1"))
+	}
+
+	if pw.PersistMaxAge < 0 {
+		result = multierror.Append(result, errors.New("persist_max_age can't be negative"))
+	}
+
 	// Prefix all errors.
 	if result != nil {
 		for i, err := range result.Errors {
@@ -940,8 +1643,9 @@ func (s *PolicySource) validate() *multierror.Error {
 	prefix := fmt.Sprintf("source[%s] ->", s.Name)
 
 	validSources := map[string]bool{
-		policySourceNomad: true,
-		policySourceFile:  true,
+		policySourceNomad:    true,
+		policySourceFile:     true,
+		policySourceNodePool: true,
 	}
 	if _, ok := validSources[s.Name]; !ok {
 		result = multierror.Append(result, fmt.Errorf("invalid source %q", s.Name))
@@ -956,6 +1660,47 @@ func (s *PolicySource) validate() *multierror.Error {
 	return result
 }
 
+func (m *PolicyMutator) copy() *PolicyMutator {
+	if m == nil {
+		return nil
+	}
+
+	c := *m
+	if m.Config != nil {
+		c.Config = make(map[string]string, len(m.Config))
+		for k, v := range m.Config {
+			c.Config[k] = v
+		}
+	}
+	return &c
+}
+
+func (m *PolicyMutator) validate() *multierror.Error {
+	var result *multierror.Error
+	prefix := fmt.Sprintf("mutator[%s] ->", m.Type)
+
+	switch m.Type {
+	case policyMutatorTargetConfig, policyMutatorAPMSource:
+		if len(m.Config) == 0 {
+			result = multierror.Append(result, errors.New("config must not be empty"))
+		}
+	case policyMutatorMax:
+		if max, err := strconv.ParseInt(m.Config["max"], 10, 64); err != nil || max < 0 {
+			result = multierror.Append(result, fmt.Errorf("invalid max value %q", m.Config["max"]))
+		}
+	default:
+		result = multierror.Append(result, fmt.Errorf("invalid mutator %q", m.Type))
+	}
+
+	// Prefix all errors.
+	if result != nil {
+		for i, err := range result.Errors {
+			result.Errors[i] = multierror.Prefix(err, prefix)
+		}
+	}
+	return result
+}
+
 // pluginConfigSetMerge merges two sets of plugin configs. For plugins with the
 // same name, the configs are merged.
 func pluginConfigSetMerge(first, second []*Plugin) []*Plugin {
@@ -1032,16 +1777,122 @@ func policySourceConfigSetMerge(first, second []*PolicySource) []*PolicySource {
 	return out
 }
 
-func parseFile(file string, cfg *Agent) error {
-	if err := hclsimple.DecodeFile(file, nil, cfg); err != nil {
-		return err
+// evalContext is the HCL evaluation context used when parsing agent config
+// files. It provides the env function which allows reading values, such as
+// secrets and addresses, from the agent environment.
+var evalContext = &hcl.EvalContext{
+	Functions: map[string]function.Function{
+		"env": envFunc,
+	},
+}
+
+// envFunc returns the value of the named environment variable, or an empty
+// string if it is not set.
+var envFunc = function.New(&function.Spec{
+	Params: []function.Parameter{
+		{Name: "name", Type: cty.String},
+	},
+	Type: function.StaticReturnType(cty.String),
+	Impl: func(args []cty.Value, _ cty.Type) (cty.Value, error) {
+		return cty.StringVal(os.Getenv(args[0].AsString())), nil
+	},
+})
+
+// unknownKeyDiagSummaries are the summaries of the HCL diagnostics reported
+// when a config file contains unknown arguments or blocks, or blocks placed
+// in the wrong location.
+var unknownKeyDiagSummaries = map[string]bool{
+	"Unsupported argument":   true,
+	"Unsupported block type": true,
+}
+
+// decodeFile decodes the HCL or JSON config file into cfg. Unknown arguments
+// and blocks are reported as errors if strict is true, otherwise they are
+// returned as warnings.
+func decodeFile(file string, cfg *Agent, strict bool) (hcl.Diagnostics, error) {
+	parser := hclparse.NewParser()
+
+	var (
+		f     *hcl.File
+		diags hcl.Diagnostics
+	)
+	switch suffix := strings.ToLower(filepath.Ext(file)); suffix {
+	case ".hcl":
+		f, diags = parser.ParseHCLFile(file)
+	case ".json":
+		f, diags = parser.ParseJSONFile(file)
+	default:
+		return nil, fmt.Errorf("unsupported file format %q", suffix)
+	}
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	diags = gohcl.DecodeBody(f.Body, evalContext, cfg)
+
+	var warnings, errs hcl.Diagnostics
+	for _, diag := range diags {
+		if !strict && unknownKeyDiagSummaries[diag.Summary] {
+			diag.Severity = hcl.DiagWarning
+		}
+
+		if diag.Severity == hcl.DiagError {
+			errs = append(errs, diag)
+		} else {
+			warnings = append(warnings, diag)
+		}
+	}
+	if errs.HasErrors() {
+		return warnings, errs
+	}
+	return warnings, nil
+}
+
+func parseFile(file string, cfg *Agent, strict bool) (hcl.Diagnostics, error) {
+	warnings, err := decodeFile(file, cfg, strict)
+	if err != nil {
+		return warnings, err
+	}
+
+	if cfg.PluginIdleTimeoutHCL != "" {
+		d, err := time.ParseDuration(cfg.PluginIdleTimeoutHCL)
+		if err != nil {
+			return warnings, err
+		}
+		cfg.PluginIdleTimeout = d
+	}
+
+	for _, plugins := range [][]*Plugin{cfg.APMs, cfg.Targets, cfg.Strategies, cfg.EventSinks} {
+		for _, p := range plugins {
+			if p.CallTimeoutHCL != "" {
+				d, err := time.ParseDuration(p.CallTimeoutHCL)
+				if err != nil {
+					return warnings, err
+				}
+				p.CallTimeout = d
+			}
+			if p.StartupTimeoutHCL != "" {
+				d, err := time.ParseDuration(p.StartupTimeoutHCL)
+				if err != nil {
+					return warnings, err
+				}
+				p.StartupTimeout = d
+			}
+			if p.SlowCallThresholdHCL != "" {
+				d, err := time.ParseDuration(p.SlowCallThresholdHCL)
+				if err != nil {
+					return warnings, err
+				}
+				p.SlowCallThreshold = d
+			}
+		}
 	}
 
 	if cfg.Nomad != nil {
 		if cfg.Nomad.BlockQueryWaitTimeHCL != "" {
 			w, err := time.ParseDuration(cfg.Nomad.BlockQueryWaitTimeHCL)
 			if err != nil {
-				return err
+				return warnings, err
 			}
 			cfg.Nomad.BlockQueryWaitTime = w
 		}
@@ -1051,7 +1902,7 @@ func parseFile(file string, cfg *Agent) error {
 		if cfg.Policy.DefaultCooldownHCL != "" {
 			d, err := time.ParseDuration(cfg.Policy.DefaultCooldownHCL)
 			if err != nil {
-				return err
+				return warnings, err
 			}
 			cfg.Policy.DefaultCooldown = d
 		}
@@ -1059,11 +1910,19 @@ func parseFile(file string, cfg *Agent) error {
 		if cfg.Policy.DefaultEvaluationIntervalHCL != "" {
 			d, err := time.ParseDuration(cfg.Policy.DefaultEvaluationIntervalHCL)
 			if err != nil {
-				return err
+				return warnings, err
 			}
 			cfg.Policy.DefaultEvaluationInterval = d
 		}
 
+		if cfg.Policy.DefaultQueryWindowOffsetHCL != "" {
+			d, err := time.ParseDuration(cfg.Policy.DefaultQueryWindowOffsetHCL)
+			if err != nil {
+				return warnings, err
+			}
+			cfg.Policy.DefaultQueryWindowOffset = d
+		}
+
 		for _, source := range cfg.Policy.Sources {
 			if source.Enabled == nil {
 				// Default to true if source block is defined.
@@ -1072,18 +1931,44 @@ func parseFile(file string, cfg *Agent) error {
 		}
 	}
 
+	if cfg.Guardrails != nil {
+		if cfg.Guardrails.MinEvaluationIntervalHCL != "" {
+			d, err := time.ParseDuration(cfg.Guardrails.MinEvaluationIntervalHCL)
+			if err != nil {
+				return warnings, err
+			}
+			cfg.Guardrails.MinEvaluationInterval = d
+		}
+
+		if cfg.Guardrails.MinCooldownHCL != "" {
+			d, err := time.ParseDuration(cfg.Guardrails.MinCooldownHCL)
+			if err != nil {
+				return warnings, err
+			}
+			cfg.Guardrails.MinCooldown = d
+		}
+
+		if cfg.Guardrails.MaxTargetActionsPeriodHCL != "" {
+			d, err := time.ParseDuration(cfg.Guardrails.MaxTargetActionsPeriodHCL)
+			if err != nil {
+				return warnings, err
+			}
+			cfg.Guardrails.MaxTargetActionsPeriod = d
+		}
+	}
+
 	if cfg.Telemetry != nil {
 		if cfg.Telemetry.CollectionIntervalHCL != "" {
 			d, err := time.ParseDuration(cfg.Telemetry.CollectionIntervalHCL)
 			if err != nil {
-				return err
+				return warnings, err
 			}
 			cfg.Telemetry.CollectionInterval = d
 		}
 		if cfg.Telemetry.PrometheusRetentionTimeHCL != "" {
 			d, err := time.ParseDuration(cfg.Telemetry.PrometheusRetentionTimeHCL)
 			if err != nil {
-				return err
+				return warnings, err
 			}
 			cfg.Telemetry.PrometheusRetentionTime = d
 		}
@@ -1093,11 +1978,27 @@ func parseFile(file string, cfg *Agent) error {
 		if cfg.PolicyEval.AckTimeoutHCL != "" {
 			t, err := time.ParseDuration(cfg.PolicyEval.AckTimeoutHCL)
 			if err != nil {
-				return err
+				return warnings, err
 			}
 			cfg.PolicyEval.AckTimeout = t
 		}
 
+		if cfg.PolicyEval.DriftCheckIntervalHCL != "" {
+			d, err := time.ParseDuration(cfg.PolicyEval.DriftCheckIntervalHCL)
+			if err != nil {
+				return warnings, err
+			}
+			cfg.PolicyEval.DriftCheckInterval = d
+		}
+
+		if cfg.PolicyEval.PersistMaxAgeHCL != "" {
+			d, err := time.ParseDuration(cfg.PolicyEval.PersistMaxAgeHCL)
+			if err != nil {
+				return warnings, err
+			}
+			cfg.PolicyEval.PersistMaxAge = d
+		}
+
 		if cfg.PolicyEval.DeliveryLimitPtr != nil {
 			cfg.PolicyEval.DeliveryLimit = *cfg.PolicyEval.DeliveryLimitPtr
 		}
@@ -1107,14 +2008,14 @@ func parseFile(file string, cfg *Agent) error {
 		if cfg.HighAvailability.LockDelayHCL != "" {
 			d, err := time.ParseDuration(cfg.HighAvailability.LockDelayHCL)
 			if err != nil {
-				return err
+				return warnings, err
 			}
 			cfg.HighAvailability.LockDelay = d
 		}
 		if cfg.HighAvailability.LockTTLHCL != "" {
 			d, err := time.ParseDuration(cfg.HighAvailability.LockTTLHCL)
 			if err != nil {
-				return err
+				return warnings, err
 			}
 			cfg.HighAvailability.LockTTL = d
 		}
@@ -1124,7 +2025,7 @@ func parseFile(file string, cfg *Agent) error {
 		if cfg.DynamicApplicationSizing.MetricsPreloadThresholdHCL != "" {
 			t, err := time.ParseDuration(cfg.DynamicApplicationSizing.MetricsPreloadThresholdHCL)
 			if err != nil {
-				return err
+				return warnings, err
 			}
 			cfg.DynamicApplicationSizing.MetricsPreloadThreshold = t
 		}
@@ -1132,31 +2033,38 @@ func parseFile(file string, cfg *Agent) error {
 		if cfg.DynamicApplicationSizing.EvaluateAfterHCL != "" {
 			t, err := time.ParseDuration(cfg.DynamicApplicationSizing.EvaluateAfterHCL)
 			if err != nil {
-				return err
+				return warnings, err
 			}
 			cfg.DynamicApplicationSizing.EvaluateAfter = t
 		}
 	}
 
-	return nil
+	return warnings, nil
 }
 
-func LoadPaths(paths []string) (*Agent, error) {
+// LoadPaths loads and merges the configuration at the given paths on top of
+// the default configuration. If strict is false, unknown arguments and blocks
+// are returned as warnings instead of causing an error.
+func LoadPaths(paths []string, strict bool) (*Agent, hcl.Diagnostics, error) {
 	// Grab a default config as the base.
 	cfg, err := Default()
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	var validationErr *multierror.Error
+	var (
+		validationErr *multierror.Error
+		warnings      hcl.Diagnostics
+	)
 
 	// Merge in the enterprise overlay.
 	cfg = cfg.Merge(DefaultEntConfig())
 
 	for _, path := range paths {
-		current, err := Load(path)
+		current, pathWarnings, err := Load(path, strict)
+		warnings = append(warnings, pathWarnings...)
 		if err != nil {
-			return nil, fmt.Errorf("error loading configuration from %s: %s", path, err)
+			return nil, warnings, fmt.Errorf("error loading configuration from %s: %s", path, err)
 		}
 
 		if err := current.Validate(); err != nil {
@@ -1175,56 +2083,62 @@ func LoadPaths(paths []string) (*Agent, error) {
 	}
 
 	if validationErr != nil {
-		return nil, fmt.Errorf("invalid configuration. %v", validationErr)
+		return nil, warnings, fmt.Errorf("invalid configuration. %v", validationErr)
 	}
 
-	return cfg, nil
+	return cfg, warnings, nil
 }
 
 // Load loads the configuration at the given path, regardless if its a file or
 // directory. Called for each -config to build up the runtime config value.
-func Load(path string) (*Agent, error) {
+func Load(path string, strict bool) (*Agent, hcl.Diagnostics, error) {
 	fi, err := os.Stat(path)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	if fi.IsDir() {
-		return loadDir(path)
+		return loadDir(path, strict)
 	}
 
 	cleaned := filepath.Clean(path)
 
 	cfg := &Agent{}
-	if err := parseFile(cleaned, cfg); err != nil {
-		return nil, fmt.Errorf("error parsing config file %s: %v", cleaned, err)
+	warnings, err := parseFile(cleaned, cfg, strict)
+	if err != nil {
+		return nil, warnings, fmt.Errorf("error parsing config file %s: %v", cleaned, err)
 	}
-	return cfg, nil
+	return cfg, warnings, nil
 }
 
 // loadDir loads all the configurations in the given directory in alphabetical
 // order.
-func loadDir(dir string) (*Agent, error) {
+func loadDir(dir string, strict bool) (*Agent, hcl.Diagnostics, error) {
 
 	files, err := file.GetFileListFromDir(dir, ".hcl", ".json")
 	if err != nil {
-		return nil, fmt.Errorf("failed to load config directory: %v", err)
+		return nil, nil, fmt.Errorf("failed to load config directory: %v", err)
 	}
 
 	// Fast-path if we have no files
 	if len(files) == 0 {
-		return &Agent{}, nil
+		return &Agent{}, nil, nil
 	}
 
 	sort.Strings(files)
 
-	var result *Agent
+	var (
+		result   *Agent
+		warnings hcl.Diagnostics
+	)
 	for _, f := range files {
 
 		cfg := &Agent{}
 
-		if err := parseFile(f, cfg); err != nil {
-			return nil, fmt.Errorf("error parsing config file %s: %v", f, err)
+		fileWarnings, err := parseFile(f, cfg, strict)
+		warnings = append(warnings, fileWarnings...)
+		if err != nil {
+			return nil, warnings, fmt.Errorf("error parsing config file %s: %v", f, err)
 		}
 
 		if result == nil {
@@ -1234,5 +2148,5 @@ func loadDir(dir string) (*Agent, error) {
 		}
 	}
 
-	return result, nil
+	return result, warnings, nil
 }
@@ -0,0 +1,97 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/mitchellh/copystructure"
+)
+
+// RedactedValue replaces the secret values of a redacted configuration.
+const RedactedValue = "<redacted>"
+
+// redactedPluginConfigKeys are the substrings of plugin config keys whose
+// values are considered secret, such as nomad_token or secret_access_key.
+// Database connection strings, such as the dsn of the sql APM, are secret as
+// they usually include the credentials.
+var redactedPluginConfigKeys = []string{"token", "secret", "password", "key", "auth", "credential", "dsn"}
+
+// Redacted returns a copy of the configuration with secret values, such as
+// ACL tokens and plugin credentials, replaced by RedactedValue so it can be
+// safely exposed through the HTTP API.
+func (a *Agent) Redacted() (*Agent, error) {
+	c, err := copystructure.Copy(a)
+	if err != nil {
+		return nil, fmt.Errorf("failed to copy agent config: %v", err)
+	}
+	result := c.(*Agent)
+
+	if result.HTTP != nil {
+		result.HTTP.Token = redact(result.HTTP.Token)
+	}
+	if result.Nomad != nil {
+		result.Nomad.Token = redact(result.Nomad.Token)
+		result.Nomad.HTTPAuth = redact(result.Nomad.HTTPAuth)
+	}
+	if result.Telemetry != nil {
+		result.Telemetry.CirconusAPIToken = redact(result.Telemetry.CirconusAPIToken)
+	}
+	if result.Proxy != nil {
+		result.Proxy.HTTPProxy = redactURL(result.Proxy.HTTPProxy)
+		result.Proxy.HTTPSProxy = redactURL(result.Proxy.HTTPSProxy)
+	}
+
+	for _, plugins := range [][]*Plugin{result.APMs, result.Targets, result.Strategies, result.EventSinks} {
+		for _, p := range plugins {
+			for k, v := range p.Config {
+				if isSecretPluginConfigKey(k) {
+					p.Config[k] = redact(v)
+				} else {
+					p.Config[k] = redactURL(v)
+				}
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// redact returns RedactedValue if the value is set.
+func redact(v string) string {
+	if v == "" {
+		return ""
+	}
+	return RedactedValue
+}
+
+// redactURL redacts the user info included in the URL, if any. Values that
+// are not URLs with user info are returned unmodified.
+func redactURL(v string) string {
+	u, err := url.Parse(v)
+	if err != nil || u.User == nil {
+		return v
+	}
+
+	// The user name alone may be a credential, such as an API token.
+	if _, ok := u.User.Password(); !ok {
+		u.User = url.User("xxxxx")
+		return u.String()
+	}
+	return u.Redacted()
+}
+
+// isSecretPluginConfigKey returns true if the value of the plugin config key
+// must be redacted.
+func isSecretPluginConfigKey(k string) bool {
+	k = strings.ToLower(k)
+	for _, s := range redactedPluginConfigKeys {
+		if strings.Contains(k, s) {
+			return true
+		}
+	}
+	return false
+}
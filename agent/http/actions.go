@@ -0,0 +1,59 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package http
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/hashicorp/nomad-autoscaler/policyeval"
+)
+
+// actionsRequest handles the requests for the `/v1/actions` endpoint.
+func (s *Server) actionsRequest(_ http.ResponseWriter, r *http.Request) (interface{}, error) {
+	if r.Method != http.MethodGet {
+		return nil, newCodedError(http.StatusMethodNotAllowed, errInvalidMethod)
+	}
+	return s.agent.PendingActions(), nil
+}
+
+// actionSpecificRequest handles the requests for the `/v1/actions/` endpoint
+// sub-paths, which are used to approve or reject a pending scaling action.
+func (s *Server) actionSpecificRequest(_ http.ResponseWriter, r *http.Request) (interface{}, error) {
+	path := strings.TrimPrefix(r.URL.Path, actionRoutePattern)
+
+	id, op, ok := strings.Cut(path, "/")
+	if !ok || id == "" {
+		return nil, newCodedError(http.StatusNotFound, "")
+	}
+
+	if r.Method != http.MethodPost && r.Method != http.MethodPut {
+		return nil, newCodedError(http.StatusMethodNotAllowed, errInvalidMethod)
+	}
+
+	var (
+		action *policyeval.PendingAction
+		err    error
+	)
+
+	switch op {
+	case "approve":
+		action, err = s.agent.ApproveAction(id)
+	case "reject":
+		action, err = s.agent.RejectAction(id)
+	default:
+		return nil, newCodedError(http.StatusNotFound, "")
+	}
+
+	switch {
+	case errors.Is(err, policyeval.ErrPendingActionNotFound):
+		return nil, newCodedError(http.StatusNotFound, err.Error())
+	case errors.Is(err, policyeval.ErrPendingActionStale), errors.Is(err, policyeval.ErrPendingActionBusy):
+		return nil, newCodedError(http.StatusConflict, err.Error())
+	case err != nil:
+		return nil, err
+	}
+	return action, nil
+}
@@ -0,0 +1,67 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServer_actions(t *testing.T) {
+	testCases := []struct {
+		inputReq         *http.Request
+		expectedRespCode int
+		name             string
+	}{
+		{
+			inputReq:         httptest.NewRequest("GET", "/v1/actions", nil),
+			expectedRespCode: 200,
+			name:             "list pending actions",
+		},
+		{
+			inputReq:         httptest.NewRequest("POST", "/v1/actions", nil),
+			expectedRespCode: 405,
+			name:             "list with incorrect request method",
+		},
+		{
+			inputReq:         httptest.NewRequest("POST", "/v1/actions/pending/approve", nil),
+			expectedRespCode: 200,
+			name:             "approve action",
+		},
+		{
+			inputReq:         httptest.NewRequest("PUT", "/v1/actions/pending/reject", nil),
+			expectedRespCode: 200,
+			name:             "reject action",
+		},
+		{
+			inputReq:         httptest.NewRequest("POST", "/v1/actions/unknown/approve", nil),
+			expectedRespCode: 404,
+			name:             "unknown action",
+		},
+		{
+			inputReq:         httptest.NewRequest("POST", "/v1/actions/pending/ignore", nil),
+			expectedRespCode: 404,
+			name:             "unknown operation",
+		},
+		{
+			inputReq:         httptest.NewRequest("GET", "/v1/actions/pending/approve", nil),
+			expectedRespCode: 405,
+			name:             "approve with incorrect request method",
+		},
+	}
+
+	srv, stopSrv := TestServer(t, false)
+	defer stopSrv()
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			srv.mux.ServeHTTP(w, tc.inputReq)
+			assert.Equal(t, tc.expectedRespCode, w.Code)
+		})
+	}
+}
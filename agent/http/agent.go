@@ -4,16 +4,36 @@
 package http
 
 import (
+	"fmt"
 	"net/http"
 	"strings"
+
+	"github.com/hashicorp/nomad-autoscaler/agent/config"
 )
 
+// agentConfigResponse is the response of the `/v1/agent/config` endpoint.
+type agentConfigResponse struct {
+	// Config is the merged runtime configuration of the agent, with secret
+	// values redacted.
+	Config *config.Agent
+
+	// Paths are the config files and directories loaded by the agent, in
+	// the order they were merged.
+	Paths []string
+}
+
 // agentSpecificRequest handles the requests for the `/v1/agent/` endpoint and sub-paths.
 func (s *Server) agentSpecificRequest(w http.ResponseWriter, r *http.Request) (interface{}, error) {
 	path := strings.TrimPrefix(r.URL.Path, "/v1/agent")
 	switch {
 	case strings.HasSuffix(path, "/reload"):
 		return s.agentReload(w, r)
+	case strings.HasSuffix(path, "/log-level"):
+		return s.agentLogLevel(w, r)
+	case strings.HasSuffix(path, "/config"):
+		return s.agentConfig(w, r)
+	case strings.HasSuffix(path, "/plugins"):
+		return s.agentPlugins(w, r)
 	default:
 		return nil, newCodedError(http.StatusNotFound, "")
 	}
@@ -26,3 +46,45 @@ func (s *Server) agentReload(w http.ResponseWriter, r *http.Request) (interface{
 
 	return s.agent.ReloadAgent(w, r)
 }
+
+// agentLogLevel returns the current log levels on GET requests and changes the
+// log level of the agent, or a single subsystem, on PUT and POST requests.
+func (s *Server) agentLogLevel(_ http.ResponseWriter, r *http.Request) (interface{}, error) {
+	switch r.Method {
+	case http.MethodGet:
+		return s.agent.LogLevels(), nil
+	case http.MethodPost, http.MethodPut:
+		level := r.URL.Query().Get("level")
+		if level == "" {
+			return nil, newCodedError(http.StatusBadRequest, "missing level query parameter")
+		}
+
+		if err := s.agent.SetLogLevel(r.URL.Query().Get("subsystem"), level); err != nil {
+			return nil, newCodedError(http.StatusBadRequest, err.Error())
+		}
+		return s.agent.LogLevels(), nil
+	default:
+		return nil, newCodedError(http.StatusMethodNotAllowed, errInvalidMethod)
+	}
+}
+
+// agentConfig returns the redacted runtime configuration of the agent.
+func (s *Server) agentConfig(_ http.ResponseWriter, r *http.Request) (interface{}, error) {
+	if r.Method != http.MethodGet {
+		return nil, newCodedError(http.StatusMethodNotAllowed, errInvalidMethod)
+	}
+
+	cfg, paths, err := s.agent.RuntimeConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read agent config: %v", err)
+	}
+	return &agentConfigResponse{Config: cfg, Paths: paths}, nil
+}
+
+// agentPlugins returns the status of the plugins configured in the agent.
+func (s *Server) agentPlugins(_ http.ResponseWriter, r *http.Request) (interface{}, error) {
+	if r.Method != http.MethodGet {
+		return nil, newCodedError(http.StatusMethodNotAllowed, errInvalidMethod)
+	}
+	return s.agent.PluginStatuses(), nil
+}
@@ -4,11 +4,15 @@
 package http
 
 import (
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
+	"github.com/hashicorp/nomad-autoscaler/agent/config"
+	"github.com/hashicorp/nomad-autoscaler/plugins/manager"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestServer_agentReload(t *testing.T) {
@@ -42,3 +46,126 @@ func TestServer_agentReload(t *testing.T) {
 		})
 	}
 }
+
+func TestServer_agentLogLevel(t *testing.T) {
+	testCases := []struct {
+		inputReq         *http.Request
+		expectedRespCode int
+		name             string
+	}{
+		{
+			inputReq:         httptest.NewRequest("GET", "/v1/agent/log-level", nil),
+			expectedRespCode: 200,
+			name:             "read log levels",
+		},
+		{
+			inputReq:         httptest.NewRequest("PUT", "/v1/agent/log-level?level=debug&subsystem=policy_eval", nil),
+			expectedRespCode: 200,
+			name:             "successfully set log level",
+		},
+		{
+			inputReq:         httptest.NewRequest("PUT", "/v1/agent/log-level", nil),
+			expectedRespCode: 400,
+			name:             "missing level",
+		},
+		{
+			inputReq:         httptest.NewRequest("POST", "/v1/agent/log-level?level=loud", nil),
+			expectedRespCode: 400,
+			name:             "invalid level",
+		},
+		{
+			inputReq:         httptest.NewRequest("DELETE", "/v1/agent/log-level", nil),
+			expectedRespCode: 405,
+			name:             "incorrect request method",
+		},
+	}
+
+	srv, stopSrv := TestServer(t, false)
+	defer stopSrv()
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			w := httptest.NewRecorder()
+			srv.mux.ServeHTTP(w, tc.inputReq)
+			assert.Equal(tc.expectedRespCode, w.Code)
+		})
+	}
+}
+
+func TestServer_agentConfig(t *testing.T) {
+	testCases := []struct {
+		inputReq         *http.Request
+		expectedRespCode int
+		name             string
+	}{
+		{
+			inputReq:         httptest.NewRequest("GET", "/v1/agent/config", nil),
+			expectedRespCode: 200,
+			name:             "successfully get config",
+		},
+		{
+			inputReq:         httptest.NewRequest("PUT", "/v1/agent/config", nil),
+			expectedRespCode: 405,
+			name:             "incorrect request method",
+		},
+	}
+
+	srv, stopSrv := TestServer(t, false)
+	defer stopSrv()
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			srv.mux.ServeHTTP(w, tc.inputReq)
+			assert.Equal(t, tc.expectedRespCode, w.Code)
+
+			if w.Code == http.StatusOK {
+				var resp agentConfigResponse
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+				assert.Equal(t, []string{"/etc/nomad-autoscaler.d"}, resp.Paths)
+				require.NotNil(t, resp.Config.Nomad)
+				assert.Equal(t, config.RedactedValue, resp.Config.Nomad.Token)
+			}
+		})
+	}
+}
+
+func TestServer_agentPlugins(t *testing.T) {
+	testCases := []struct {
+		inputReq         *http.Request
+		expectedRespCode int
+		name             string
+	}{
+		{
+			inputReq:         httptest.NewRequest("GET", "/v1/agent/plugins", nil),
+			expectedRespCode: 200,
+			name:             "successfully list plugins",
+		},
+		{
+			inputReq:         httptest.NewRequest("POST", "/v1/agent/plugins", nil),
+			expectedRespCode: 405,
+			name:             "incorrect request method",
+		},
+	}
+
+	srv, stopSrv := TestServer(t, false)
+	defer stopSrv()
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			srv.mux.ServeHTTP(w, tc.inputReq)
+			assert.Equal(t, tc.expectedRespCode, w.Code)
+
+			if w.Code == http.StatusOK {
+				var resp []manager.PluginStatus
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+				require.Len(t, resp, 1)
+				assert.Equal(t, "prometheus", resp[0].Name)
+				assert.True(t, resp[0].Healthy)
+			}
+		})
+	}
+}
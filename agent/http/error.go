@@ -7,6 +7,10 @@ package http
 // the incorrect method.
 const errInvalidMethod = "Invalid method"
 
+// errPermissionDenied is the error message used when a HTTP request does not
+// include the token configured for the agent HTTP API.
+const errPermissionDenied = "Permission denied"
+
 // codedError defines the interface used for custom HTTP error handling. It
 // ensures we include a message and response code to differentiate between
 // internal and other errors.
@@ -0,0 +1,117 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/nomad-autoscaler/policyeval"
+)
+
+// grafanaTargetSeparator separates the policy ID from the series name in the
+// Grafana target names, such as <policy_id>:desired_count.
+const grafanaTargetSeparator = ":"
+
+// grafanaSearchRequest is the body of the Grafana JSON datasource search
+// requests. Target is an optional filter of the target names.
+type grafanaSearchRequest struct {
+	Target string `json:"target"`
+}
+
+// grafanaQueryRequest is the body of the Grafana JSON datasource query
+// requests.
+type grafanaQueryRequest struct {
+	Range struct {
+		From time.Time `json:"from"`
+		To   time.Time `json:"to"`
+	} `json:"range"`
+	Targets []struct {
+		Target string `json:"target"`
+	} `json:"targets"`
+}
+
+// grafanaTimeseries is a series of the Grafana JSON datasource query
+// responses. Each datapoint is a value and a timestamp in milliseconds.
+type grafanaTimeseries struct {
+	Target     string      `json:"target"`
+	Datapoints [][]float64 `json:"datapoints"`
+}
+
+// grafanaRequest handles the requests for the `/v1/grafana/` endpoint and
+// sub-paths, which implement the API of the Grafana JSON datasource so the
+// policy decision history can be graphed.
+func (s *Server) grafanaRequest(w http.ResponseWriter, r *http.Request) (interface{}, error) {
+	switch strings.TrimPrefix(r.URL.Path, grafanaRoutePattern) {
+	case "":
+		// The datasource checks the connection using the root path.
+		if r.Method != http.MethodGet {
+			return nil, newCodedError(http.StatusMethodNotAllowed, errInvalidMethod)
+		}
+		return struct{}{}, nil
+	case "search", "metrics":
+		return s.grafanaSearch(w, r)
+	case "query":
+		return s.grafanaQuery(w, r)
+	default:
+		return nil, newCodedError(http.StatusNotFound, "")
+	}
+}
+
+// grafanaSearch returns the names of the targets that can be queried.
+func (s *Server) grafanaSearch(_ http.ResponseWriter, r *http.Request) (interface{}, error) {
+	if r.Method != http.MethodPost {
+		return nil, newCodedError(http.StatusMethodNotAllowed, errInvalidMethod)
+	}
+
+	var req grafanaSearchRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			return nil, newCodedError(http.StatusBadRequest, err.Error())
+		}
+	}
+
+	targets := []string{}
+	for _, series := range s.agent.DecisionHistory(time.Time{}, time.Time{}) {
+		target := series.PolicyID + grafanaTargetSeparator + series.Name
+		if strings.Contains(target, req.Target) {
+			targets = append(targets, target)
+		}
+	}
+	return targets, nil
+}
+
+// grafanaQuery returns the points of the requested targets within the time
+// range of the request.
+func (s *Server) grafanaQuery(_ http.ResponseWriter, r *http.Request) (interface{}, error) {
+	if r.Method != http.MethodPost {
+		return nil, newCodedError(http.StatusMethodNotAllowed, errInvalidMethod)
+	}
+
+	var req grafanaQueryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, newCodedError(http.StatusBadRequest, err.Error())
+	}
+
+	history := make(map[string]policyeval.DecisionSeries)
+	for _, series := range s.agent.DecisionHistory(req.Range.From, req.Range.To) {
+		history[series.PolicyID+grafanaTargetSeparator+series.Name] = series
+	}
+
+	out := make([]grafanaTimeseries, 0, len(req.Targets))
+	for _, t := range req.Targets {
+		if t.Target == "" {
+			continue
+		}
+
+		ts := grafanaTimeseries{Target: t.Target, Datapoints: [][]float64{}}
+		for _, p := range history[t.Target].Points {
+			ts.Datapoints = append(ts.Datapoints, []float64{p.Value, float64(p.Timestamp.UnixMilli())})
+		}
+		out = append(out, ts)
+	}
+	return out, nil
+}
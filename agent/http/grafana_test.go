@@ -0,0 +1,92 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServer_grafanaRequest(t *testing.T) {
+	testCases := []struct {
+		inputReq         *http.Request
+		expectedRespCode int
+		expectedResp     string
+		name             string
+	}{
+		{
+			inputReq:         httptest.NewRequest("GET", "/v1/grafana/", nil),
+			expectedRespCode: 200,
+			expectedResp:     `{}`,
+			name:             "test connection",
+		},
+		{
+			inputReq:         httptest.NewRequest("POST", "/v1/grafana/search", strings.NewReader(`{"target":"desired"}`)),
+			expectedRespCode: 200,
+			expectedResp:     `["policy:desired_count"]`,
+			name:             "search targets with filter",
+		},
+		{
+			inputReq:         httptest.NewRequest("POST", "/v1/grafana/search", nil),
+			expectedRespCode: 200,
+			expectedResp:     `["policy:actual_count","policy:desired_count"]`,
+			name:             "search targets without body",
+		},
+		{
+			inputReq: httptest.NewRequest("POST", "/v1/grafana/query", strings.NewReader(`{
+  "range": {"from": "2024-01-01T11:00:00Z", "to": "2024-01-01T13:00:00Z"},
+  "targets": [{"target": "policy:desired_count"}, {"target": "policy:unknown"}]
+}`)),
+			expectedRespCode: 200,
+			expectedResp:     `[{"target":"policy:desired_count","datapoints":[[3,1704110400000]]},{"target":"policy:unknown","datapoints":[]}]`,
+			name:             "query targets",
+		},
+		{
+			inputReq: httptest.NewRequest("POST", "/v1/grafana/query", strings.NewReader(`{
+  "range": {"from": "2024-01-02T11:00:00Z", "to": "2024-01-02T13:00:00Z"},
+  "targets": [{"target": "policy:desired_count"}]
+}`)),
+			expectedRespCode: 200,
+			expectedResp:     `[{"target":"policy:desired_count","datapoints":[]}]`,
+			name:             "query targets outside range",
+		},
+		{
+			inputReq:         httptest.NewRequest("POST", "/v1/grafana/query", strings.NewReader(`{`)),
+			expectedRespCode: 400,
+			name:             "invalid query body",
+		},
+		{
+			inputReq:         httptest.NewRequest("GET", "/v1/grafana/query", nil),
+			expectedRespCode: 405,
+			name:             "incorrect request method",
+		},
+		{
+			inputReq:         httptest.NewRequest("GET", "/v1/grafana/annotations", nil),
+			expectedRespCode: 404,
+			name:             "unknown path",
+		},
+	}
+
+	srv, stopSrv := TestServer(t, false)
+	defer stopSrv()
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			srv.mux.ServeHTTP(w, tc.inputReq)
+			assert.Equal(t, tc.expectedRespCode, w.Code)
+
+			if tc.expectedResp != "" {
+				require.True(t, json.Valid(w.Body.Bytes()))
+				assert.JSONEq(t, tc.expectedResp, w.Body.String())
+			}
+		})
+	}
+}
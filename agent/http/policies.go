@@ -0,0 +1,26 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/hashicorp/nomad-autoscaler/policy"
+)
+
+// policiesReload handles the requests for the `/v1/policies/reload` endpoint.
+// The optional source query parameter restricts the reload to a single policy
+// source.
+func (s *Server) policiesReload(_ http.ResponseWriter, r *http.Request) (interface{}, error) {
+	if r.Method != http.MethodPost && r.Method != http.MethodPut {
+		return nil, newCodedError(http.StatusMethodNotAllowed, errInvalidMethod)
+	}
+
+	err := s.agent.ReloadPolicySources(r.URL.Query().Get("source"))
+	if errors.Is(err, policy.ErrSourceNotFound) {
+		return nil, newCodedError(http.StatusNotFound, err.Error())
+	}
+	return nil, err
+}
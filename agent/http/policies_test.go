@@ -0,0 +1,52 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServer_policiesReload(t *testing.T) {
+	testCases := []struct {
+		inputReq         *http.Request
+		expectedRespCode int
+		name             string
+	}{
+		{
+			inputReq:         httptest.NewRequest("POST", "/v1/policies/reload", nil),
+			expectedRespCode: 200,
+			name:             "successfully reload all sources",
+		},
+		{
+			inputReq:         httptest.NewRequest("PUT", "/v1/policies/reload?source=nomad", nil),
+			expectedRespCode: 200,
+			name:             "successfully reload named source",
+		},
+		{
+			inputReq:         httptest.NewRequest("POST", "/v1/policies/reload?source=invalid", nil),
+			expectedRespCode: 404,
+			name:             "unknown source",
+		},
+		{
+			inputReq:         httptest.NewRequest("GET", "/v1/policies/reload", nil),
+			expectedRespCode: 405,
+			name:             "incorrect request method",
+		},
+	}
+
+	srv, stopSrv := TestServer(t, false)
+	defer stopSrv()
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			srv.mux.ServeHTTP(w, tc.inputReq)
+			assert.Equal(t, tc.expectedRespCode, w.Code)
+		})
+	}
+}
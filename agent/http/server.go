@@ -6,16 +6,25 @@ package http
 import (
 	"bytes"
 	"context"
+	"crypto/subtle"
+	"crypto/tls"
+	"crypto/x509"
 	"fmt"
 	"net"
 	"net/http"
 	"net/http/pprof"
+	"os"
+	"strconv"
+	"strings"
 	"sync/atomic"
 	"time"
 
 	hclog "github.com/hashicorp/go-hclog"
 	"github.com/hashicorp/go-msgpack/codec"
 	"github.com/hashicorp/nomad-autoscaler/agent/config"
+	"github.com/hashicorp/nomad-autoscaler/plugins/manager"
+	"github.com/hashicorp/nomad-autoscaler/policy"
+	"github.com/hashicorp/nomad-autoscaler/policyeval"
 )
 
 const (
@@ -31,6 +40,24 @@ const (
 	// register endpoints related to the agent.
 	agentRoutePattern = "/v1/agent/"
 
+	// actionsRoutePattern and actionRoutePattern are the Autoscaler HTTP
+	// router patterns which are used to register the endpoints that list,
+	// approve and reject scaling actions waiting for manual approval.
+	actionsRoutePattern = "/v1/actions"
+	actionRoutePattern  = "/v1/actions/"
+
+	// statusRoutePattern is the Autoscaler HTTP router pattern which is used
+	// to register the endpoint that reports the internal status of the agent.
+	statusRoutePattern = "/v1/status"
+
+	// policiesReloadRoutePattern is the Autoscaler HTTP router pattern which
+	// is used to register the endpoint that reloads the policy sources.
+	policiesReloadRoutePattern = "/v1/policies/reload"
+
+	// grafanaRoutePattern is the Autoscaler HTTP router pattern which is used
+	// to register the endpoints of the Grafana JSON datasource API.
+	grafanaRoutePattern = "/v1/grafana/"
+
 	// healthAliveness is used to define the health of the Autoscaler agent. It
 	// currently can only be in two states; ready or unavailable and depends
 	// entirely on whether the server is serving or not.
@@ -38,6 +65,10 @@ const (
 	healthAlivenessUnavailable
 )
 
+// unixSocketPrefix is the bind address prefix used to indicate the HTTP
+// server should listen on a Unix socket.
+const unixSocketPrefix = "unix://"
+
 // AgentHTTP is the interface that defines the HTTP handlers that an Agent
 // must implement in order to be accessible through the HTTP API.
 type AgentHTTP interface {
@@ -46,6 +77,46 @@ type AgentHTTP interface {
 
 	// ReloadAgent triggers the agent to reload policies and configuration.
 	ReloadAgent(resp http.ResponseWriter, req *http.Request) (interface{}, error)
+
+	// LogLevels returns the current log level of the agent and each of its
+	// subsystems.
+	LogLevels() map[string]string
+
+	// SetLogLevel changes the log level of the named subsystem, or of the
+	// whole agent if subsystem is empty.
+	SetLogLevel(subsystem, level string) error
+
+	// PendingActions returns the scaling actions waiting for approval.
+	PendingActions() []*policyeval.PendingAction
+
+	// ApproveAction executes the pending scaling action with the given ID.
+	ApproveAction(id string) (*policyeval.PendingAction, error)
+
+	// RejectAction discards the pending scaling action with the given ID.
+	RejectAction(id string) (*policyeval.PendingAction, error)
+
+	// PluginTimings returns the latency summaries of the recent plugin calls.
+	PluginTimings() []policyeval.PluginTiming
+
+	// DecisionHistory returns the recent decision history of the policies
+	// within the time range.
+	DecisionHistory(from, to time.Time) []policyeval.DecisionSeries
+
+	// RuntimeConfig returns the merged configuration of the agent, with
+	// secret values redacted, and the config paths it was loaded from.
+	RuntimeConfig() (*config.Agent, []string, error)
+
+	// PluginStatuses returns the status of the plugins configured in the
+	// agent.
+	PluginStatuses() []manager.PluginStatus
+
+	// ReloadPolicySources reloads the named policy source, or all sources if
+	// source is empty, without reloading the rest of the agent.
+	ReloadPolicySources(source string) error
+
+	// PolicyStatuses returns the state of the handlers of the policies
+	// monitored by the agent.
+	PolicyStatuses() []policy.HandlerStatus
 }
 
 type Server struct {
@@ -66,6 +137,10 @@ type Server struct {
 	// agent is the reference to an object that implements the AgentHTTP
 	// interface to handle agent requests.
 	agent AgentHTTP
+
+	// token is the bearer token required to access the HTTP API. If empty,
+	// requests are not authenticated.
+	token string
 }
 
 // NewHTTPServer creates a new agent HTTP server.
@@ -76,12 +151,18 @@ func NewHTTPServer(debug, prom bool, cfg *config.HTTP, log hclog.Logger, agent A
 		mux:         http.NewServeMux(),
 		agent:       agent,
 		promEnabled: prom,
+		token:       cfg.Token,
 	}
 
 	// Setup our handlers.
 	srv.mux.HandleFunc(healthRoutePattern, srv.wrap(srv.getHealth))
 	srv.mux.HandleFunc(metricsRoutePattern, srv.wrap(srv.getMetrics))
 	srv.mux.HandleFunc(agentRoutePattern, srv.wrap(srv.agentSpecificRequest))
+	srv.mux.HandleFunc(actionsRoutePattern, srv.wrap(srv.actionsRequest))
+	srv.mux.HandleFunc(actionRoutePattern, srv.wrap(srv.actionSpecificRequest))
+	srv.mux.HandleFunc(statusRoutePattern, srv.wrap(srv.getStatus))
+	srv.mux.HandleFunc(grafanaRoutePattern, srv.wrap(srv.grafanaRequest))
+	srv.mux.HandleFunc(policiesReloadRoutePattern, srv.wrap(srv.policiesReload))
 
 	// Setup the debugging endpoints.
 	if debug {
@@ -92,10 +173,12 @@ func NewHTTPServer(debug, prom bool, cfg *config.HTTP, log hclog.Logger, agent A
 		srv.mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
 	}
 
+	network, addr := listenAddr(cfg)
+
 	// Configure the HTTP server to the most basic level.
 	srv.srv = &http.Server{
-		Addr:         fmt.Sprintf("%s:%v", cfg.BindAddress, cfg.BindPort),
-		Handler:      srv.mux,
+		Addr:         addr,
+		Handler:      srv.authenticate(srv.mux),
 		ReadTimeout:  5 * time.Second,
 		WriteTimeout: 15 * time.Second,
 		IdleTimeout:  15 * time.Second,
@@ -104,15 +187,101 @@ func NewHTTPServer(debug, prom bool, cfg *config.HTTP, log hclog.Logger, agent A
 	// Announce on the configured network address. If there is an error in the
 	// configured HTTP bind parameters, it will be caught here and the error
 	// passed up to the agent.
-	ln, err := net.Listen("tcp", srv.srv.Addr)
+	if network == "unix" {
+		// Remove any socket left behind by a previous agent which did not
+		// shutdown cleanly, otherwise the bind will fail.
+		if err := os.Remove(addr); err != nil && !os.IsNotExist(err) {
+			return nil, fmt.Errorf("could not remove existing unix socket: %v", err)
+		}
+	}
+
+	ln, err := net.Listen(network, addr)
 	if err != nil {
 		return nil, fmt.Errorf("could not setup HTTP listener: %v", err)
 	}
+
+	if cfg.TLSEnabled() {
+		tlsConf, err := tlsConfig(cfg)
+		if err != nil {
+			_ = ln.Close()
+			return nil, fmt.Errorf("could not setup HTTP TLS: %v", err)
+		}
+		srv.srv.TLSConfig = tlsConf
+		ln = tls.NewListener(ln, tlsConf)
+	}
 	srv.ln = ln
 
 	return srv, nil
 }
 
+// listenAddr returns the network and address the HTTP server should listen
+// on. A bind address using the unix:// scheme results in the server listening
+// on the Unix socket at the given path, ignoring the bind port.
+func listenAddr(cfg *config.HTTP) (string, string) {
+	if path, ok := strings.CutPrefix(cfg.BindAddress, unixSocketPrefix); ok {
+		return "unix", path
+	}
+	return "tcp", net.JoinHostPort(cfg.BindAddress, strconv.Itoa(cfg.BindPort))
+}
+
+// tlsConfig builds the TLS configuration used to serve the HTTP API from the
+// agent config.
+func tlsConfig(cfg *config.HTTP) (*tls.Config, error) {
+	cert, err := tls.LoadX509KeyPair(cfg.TLSCertFile, cfg.TLSKeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load certificate: %v", err)
+	}
+
+	tlsConf := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+
+	if cfg.TLSCAFile != "" {
+		caPEM, err := os.ReadFile(cfg.TLSCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA file: %v", err)
+		}
+
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caPEM) {
+			return nil, fmt.Errorf("failed to parse CA file %s", cfg.TLSCAFile)
+		}
+		tlsConf.ClientCAs = pool
+		tlsConf.ClientAuth = tls.VerifyClientCertIfGiven
+	}
+
+	if cfg.TLSVerifyClient {
+		tlsConf.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+
+	return tlsConf, nil
+}
+
+// authenticate wraps the handler so that requests must include the configured
+// bearer token. The health endpoint is always accessible so it can be used by
+// liveness probes.
+func (s *Server) authenticate(next http.Handler) http.Handler {
+	if s.token == "" {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == healthRoutePattern {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) != 1 {
+			s.handleHTTPError(w, r, newCodedError(http.StatusForbidden, errPermissionDenied))
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
 // Run is used to serve the HTTP server. The function will block and should be
 // run via a go-routine. Unless http.Server.Serve panics/fails, the server can
 // be stopped by calling the Stop function.
@@ -4,13 +4,19 @@
 package http
 
 import (
+	"context"
 	"errors"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"testing"
 
 	hclog "github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/nomad-autoscaler/agent/config"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestServer_handlerHTTPError(t *testing.T) {
@@ -50,3 +56,126 @@ func TestServer_handlerHTTPError(t *testing.T) {
 		})
 	}
 }
+
+func TestServer_authenticate(t *testing.T) {
+	testCases := []struct {
+		name             string
+		token            string
+		path             string
+		authHeader       string
+		expectedRespCode int
+	}{
+		{
+			name:             "no token configured",
+			path:             "/v1/agent/reload",
+			expectedRespCode: http.StatusOK,
+		},
+		{
+			name:             "valid token",
+			token:            "secret",
+			path:             "/v1/agent/reload",
+			authHeader:       "Bearer secret",
+			expectedRespCode: http.StatusOK,
+		},
+		{
+			name:             "invalid token",
+			token:            "secret",
+			path:             "/v1/agent/reload",
+			authHeader:       "Bearer wrong",
+			expectedRespCode: http.StatusForbidden,
+		},
+		{
+			name:             "missing token",
+			token:            "secret",
+			path:             "/v1/metrics",
+			expectedRespCode: http.StatusForbidden,
+		},
+		{
+			name:             "health does not require token",
+			token:            "secret",
+			path:             healthRoutePattern,
+			expectedRespCode: http.StatusOK,
+		},
+	}
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			srv := &Server{log: hclog.NewNullLogger(), token: tc.token}
+
+			req := httptest.NewRequest("GET", tc.path, nil)
+			if tc.authHeader != "" {
+				req.Header.Set("Authorization", tc.authHeader)
+			}
+			w := httptest.NewRecorder()
+
+			srv.authenticate(next).ServeHTTP(w, req)
+			assert.Equal(t, tc.expectedRespCode, w.Code)
+		})
+	}
+}
+
+func Test_listenAddr(t *testing.T) {
+	testCases := []struct {
+		name            string
+		input           *config.HTTP
+		expectedNetwork string
+		expectedAddr    string
+	}{
+		{
+			name:            "tcp",
+			input:           &config.HTTP{BindAddress: "127.0.0.1", BindPort: 8080},
+			expectedNetwork: "tcp",
+			expectedAddr:    "127.0.0.1:8080",
+		},
+		{
+			name:            "tcp ipv6",
+			input:           &config.HTTP{BindAddress: "::1", BindPort: 8080},
+			expectedNetwork: "tcp",
+			expectedAddr:    "[::1]:8080",
+		},
+		{
+			name:            "unix socket",
+			input:           &config.HTTP{BindAddress: "unix:///run/nomad-autoscaler.sock", BindPort: 8080},
+			expectedNetwork: "unix",
+			expectedAddr:    "/run/nomad-autoscaler.sock",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			network, addr := listenAddr(tc.input)
+			assert.Equal(t, tc.expectedNetwork, network)
+			assert.Equal(t, tc.expectedAddr, addr)
+		})
+	}
+}
+
+func TestServer_unixSocket(t *testing.T) {
+	sock := filepath.Join(t.TempDir(), "autoscaler.sock")
+
+	// Create a stale file at the socket path to ensure it is replaced.
+	require.NoError(t, os.WriteFile(sock, nil, 0600))
+
+	srv, err := NewHTTPServer(false, false, &config.HTTP{BindAddress: "unix://" + sock}, hclog.NewNullLogger(), nil)
+	require.NoError(t, err)
+	go srv.Start()
+	defer srv.Stop()
+
+	client := &http.Client{
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				var d net.Dialer
+				return d.DialContext(ctx, "unix", sock)
+			},
+		},
+	}
+
+	resp, err := client.Get("http://unix/v1/health")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
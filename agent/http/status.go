@@ -0,0 +1,33 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package http
+
+import (
+	"net/http"
+
+	"github.com/hashicorp/nomad-autoscaler/policy"
+	"github.com/hashicorp/nomad-autoscaler/policyeval"
+)
+
+// statusResponse is the response of the `/v1/status` endpoint.
+type statusResponse struct {
+	// PluginTimings summarizes the latency of the recent calls made to each
+	// plugin operation.
+	PluginTimings []policyeval.PluginTiming
+
+	// Policies is the state of the handler of each policy monitored by the
+	// agent.
+	Policies []policy.HandlerStatus
+}
+
+// getStatus handles the requests for the `/v1/status` endpoint.
+func (s *Server) getStatus(_ http.ResponseWriter, r *http.Request) (interface{}, error) {
+	if r.Method != http.MethodGet {
+		return nil, newCodedError(http.StatusMethodNotAllowed, errInvalidMethod)
+	}
+	return &statusResponse{
+		PluginTimings: s.agent.PluginTimings(),
+		Policies:      s.agent.PolicyStatuses(),
+	}, nil
+}
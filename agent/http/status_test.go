@@ -0,0 +1,57 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hashicorp/nomad-autoscaler/policy"
+	"github.com/hashicorp/nomad-autoscaler/sdk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServer_getStatus(t *testing.T) {
+	testCases := []struct {
+		inputReq         *http.Request
+		expectedRespCode int
+		name             string
+	}{
+		{
+			inputReq:         httptest.NewRequest("GET", "/v1/status", nil),
+			expectedRespCode: 200,
+			name:             "successfully get status",
+		},
+		{
+			inputReq:         httptest.NewRequest("PUT", "/v1/status", nil),
+			expectedRespCode: 405,
+			name:             "incorrect request method",
+		},
+	}
+
+	srv, stopSrv := TestServer(t, false)
+	defer stopSrv()
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			srv.mux.ServeHTTP(w, tc.inputReq)
+			assert.Equal(t, tc.expectedRespCode, w.Code)
+
+			if w.Code == http.StatusOK {
+				var resp statusResponse
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+				require.Len(t, resp.PluginTimings, 1)
+				assert.Equal(t, "prometheus", resp.PluginTimings[0].PluginName)
+				require.Len(t, resp.Policies, 1)
+				assert.Equal(t, policy.HandlerStateScaling, resp.Policies[0].State)
+				assert.Equal(t, "cpu", resp.Policies[0].LastCheck)
+				assert.Equal(t, &sdk.ScalingActionExplanation{Ratio: 1.5, Samples: 1}, resp.Policies[0].LastExplanation)
+			}
+		})
+	}
+}
@@ -3,7 +3,15 @@
 
 package agent
 
-import "net/http"
+import (
+	"net/http"
+	"time"
+
+	"github.com/hashicorp/nomad-autoscaler/agent/config"
+	"github.com/hashicorp/nomad-autoscaler/plugins/manager"
+	"github.com/hashicorp/nomad-autoscaler/policy"
+	"github.com/hashicorp/nomad-autoscaler/policyeval"
+)
 
 // The methods in this file implement in the http.AgentHTTP interface.
 
@@ -15,3 +23,61 @@ func (a *Agent) ReloadAgent(_ http.ResponseWriter, _ *http.Request) (interface{}
 	a.reload()
 	return nil, nil
 }
+
+func (a *Agent) PendingActions() []*policyeval.PendingAction {
+	return a.approvals.List()
+}
+
+func (a *Agent) ApproveAction(id string) (*policyeval.PendingAction, error) {
+	action, err := a.approvals.Approve(id)
+	if action != nil {
+		a.logger.Info("approved scaling action", "pending_action_id", id, "policy_id", action.PolicyID)
+	}
+	return action, err
+}
+
+func (a *Agent) RejectAction(id string) (*policyeval.PendingAction, error) {
+	action, err := a.approvals.Reject(id)
+	if action != nil {
+		a.logger.Info("rejected scaling action", "pending_action_id", id, "policy_id", action.PolicyID)
+	}
+	return action, err
+}
+
+func (a *Agent) PluginTimings() []policyeval.PluginTiming {
+	return policyeval.PluginTimings()
+}
+
+func (a *Agent) DecisionHistory(from, to time.Time) []policyeval.DecisionSeries {
+	return policyeval.DecisionHistory(from, to)
+}
+
+func (a *Agent) RuntimeConfig() (*config.Agent, []string, error) {
+	a.configLock.RLock()
+	defer a.configLock.RUnlock()
+
+	cfg, err := a.config.Redacted()
+	if err != nil {
+		return nil, nil, err
+	}
+	return cfg, a.configPaths, nil
+}
+
+func (a *Agent) PluginStatuses() []manager.PluginStatus {
+	return a.pluginManager.PluginStatuses()
+}
+
+func (a *Agent) ReloadPolicySources(source string) error {
+	if source == "" {
+		a.logger.Info("reloading all policy sources")
+		a.policyManager.ReloadSources()
+		return nil
+	}
+
+	a.logger.Info("reloading policy source", "policy_source", source)
+	return a.policyManager.ReloadSource(policy.SourceName(source))
+}
+
+func (a *Agent) PolicyStatuses() []policy.HandlerStatus {
+	return a.policyManager.HandlerStatuses()
+}
@@ -0,0 +1,91 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package agent
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/go-hclog"
+)
+
+const (
+	// logSubsystemAgent is the name used to identify the root agent logger.
+	// Changing its level also changes the level of all subsystems.
+	logSubsystemAgent = "agent"
+
+	logSubsystemPolicyManager = "policy_manager"
+	logSubsystemPluginManager = "plugin_manager"
+	logSubsystemPolicyEval    = "policy_eval"
+)
+
+// setupLoggers creates the loggers of each agent subsystem so their level can
+// be changed at runtime. The agent logger must be created with the hclog
+// SyncParentLevel option, so that changing the level of a subsystem logger
+// also changes the level of all the loggers derived from it.
+func (a *Agent) setupLoggers() {
+	a.subsystemLoggers = map[string]hclog.Logger{
+		logSubsystemAgent:         a.logger,
+		logSubsystemPolicyManager: a.logger.ResetNamed(logSubsystemPolicyManager),
+		logSubsystemPluginManager: a.logger.ResetNamed(logSubsystemPluginManager),
+		logSubsystemPolicyEval:    a.logger.ResetNamed(logSubsystemPolicyEval),
+	}
+	a.configuredLogLevel = a.logger.GetLevel()
+}
+
+// LogLevels returns the current log level of the agent and its subsystems.
+func (a *Agent) LogLevels() map[string]string {
+	a.subsystemLoggersLock.RLock()
+	defer a.subsystemLoggersLock.RUnlock()
+
+	levels := make(map[string]string, len(a.subsystemLoggers))
+	for name, l := range a.subsystemLoggers {
+		levels[name] = l.GetLevel().String()
+	}
+	return levels
+}
+
+// SetLogLevel changes the log level of the named subsystem. If subsystem is
+// empty, the level of the agent and all its subsystems is changed.
+func (a *Agent) SetLogLevel(subsystem, level string) error {
+	if subsystem == "" {
+		subsystem = logSubsystemAgent
+	}
+
+	lvl := hclog.LevelFromString(level)
+	if lvl == hclog.NoLevel {
+		return fmt.Errorf("invalid log level %q", level)
+	}
+
+	a.subsystemLoggersLock.Lock()
+	defer a.subsystemLoggersLock.Unlock()
+
+	l, ok := a.subsystemLoggers[subsystem]
+	if !ok {
+		names := make([]string, 0, len(a.subsystemLoggers))
+		for name := range a.subsystemLoggers {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return fmt.Errorf("invalid subsystem %q, must be one of %s", subsystem, strings.Join(names, ", "))
+	}
+
+	l.SetLevel(lvl)
+	a.logger.Info("log level changed", "subsystem", subsystem, "level", lvl.String())
+	return nil
+}
+
+// toggleLogLevel switches the level of the agent and all its subsystems
+// between the configured level and trace. It is used to quickly increase
+// logging verbosity by sending a signal to the agent.
+func (a *Agent) toggleLogLevel() {
+	level := hclog.Trace
+	if a.logger.GetLevel() == hclog.Trace {
+		level = a.configuredLogLevel
+	}
+
+	// The level is always valid, so the error can be ignored.
+	_ = a.SetLogLevel(logSubsystemAgent, level.String())
+}
@@ -0,0 +1,65 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package agent
+
+import (
+	"testing"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAgent_SetLogLevel(t *testing.T) {
+	a := &Agent{
+		logger: hclog.New(&hclog.LoggerOptions{
+			Name:            "agent",
+			Level:           hclog.Info,
+			SyncParentLevel: true,
+		}),
+	}
+	a.setupLoggers()
+
+	// Loggers derived from a subsystem logger must follow its level.
+	workerLogger := a.subsystemLoggers[logSubsystemPolicyEval].Named("worker")
+
+	// Changing a single subsystem must not affect the others.
+	require.NoError(t, a.SetLogLevel(logSubsystemPolicyEval, "debug"))
+	assert.Equal(t, map[string]string{
+		logSubsystemAgent:         "info",
+		logSubsystemPolicyManager: "info",
+		logSubsystemPluginManager: "info",
+		logSubsystemPolicyEval:    "debug",
+	}, a.LogLevels())
+	assert.True(t, workerLogger.IsDebug())
+
+	// Changing the agent level changes all subsystems.
+	require.NoError(t, a.SetLogLevel("", "warn"))
+	for name, level := range a.LogLevels() {
+		assert.Equal(t, "warn", level, name)
+	}
+	assert.False(t, workerLogger.IsInfo())
+
+	assert.EqualError(t, a.SetLogLevel("", "loud"), `invalid log level "loud"`)
+	assert.EqualError(t, a.SetLogLevel("nomad", "debug"),
+		`invalid subsystem "nomad", must be one of agent, plugin_manager, policy_eval, policy_manager`)
+}
+
+func TestAgent_toggleLogLevel(t *testing.T) {
+	a := &Agent{
+		logger: hclog.New(&hclog.LoggerOptions{
+			Level:           hclog.Warn,
+			SyncParentLevel: true,
+		}),
+	}
+	a.setupLoggers()
+
+	a.toggleLogLevel()
+	assert.Equal(t, hclog.Trace, a.logger.GetLevel())
+	assert.Equal(t, hclog.Trace, a.subsystemLoggers[logSubsystemPolicyManager].GetLevel())
+
+	a.toggleLogLevel()
+	assert.Equal(t, hclog.Warn, a.logger.GetLevel())
+	assert.Equal(t, hclog.Warn, a.subsystemLoggers[logSubsystemPolicyManager].GetLevel())
+}
@@ -0,0 +1,47 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package agent
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/hashicorp/nomad-autoscaler/agent/config"
+	"github.com/hashicorp/nomad-autoscaler/sdk/helper/file"
+)
+
+// checkPolicyDirPermissions verifies the ownership and permissions of the
+// policy directory and the policy files within it. Issues are logged, unless
+// permission checks are enforced in which case an error is returned.
+func (a *Agent) checkPolicyDirPermissions() error {
+	if a.config.PermissionChecks == config.PermissionChecksDisabled {
+		return nil
+	}
+
+	issues, err := file.CheckDirPermissions(a.config.Policy.Dir, ".hcl", ".json")
+	if err != nil {
+		// A missing policy dir is reported by the file policy source.
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to check policy dir permissions: %v", err)
+	}
+	if len(issues) == 0 {
+		return nil
+	}
+
+	if a.config.PermissionChecks == config.PermissionChecksEnforce {
+		msgs := make([]string, 0, len(issues))
+		for _, issue := range issues {
+			msgs = append(msgs, issue.String())
+		}
+		return fmt.Errorf("policy dir has unsafe permissions: %s", strings.Join(msgs, ", "))
+	}
+
+	for _, issue := range issues {
+		a.logger.Warn("policy file has unsafe permissions", "path", issue.Path, "issue", issue.Reason)
+	}
+	return nil
+}
@@ -17,7 +17,8 @@ import (
 // and forks the configured plugins for use.
 func (a *Agent) setupPlugins() error {
 
-	a.pluginManager = manager.NewPluginManager(a.logger, a.config.PluginDir, a.setupPluginsConfig())
+	a.pluginManager = manager.NewPluginManager(a.subsystemLoggers[logSubsystemPluginManager], a.config.PluginDir, a.config.PermissionChecks, a.config.PluginIdleTimeout, a.setupPluginsConfig())
+	a.pluginManager.EnableFaultInjection(a.config.DevFaultInjection)
 
 	// Trigger the loading of the plugins which will be available to the agent.
 	// Any errors here will cause the agent to fail, but will include wrapped
@@ -40,6 +41,9 @@ func (a *Agent) setupPluginsConfig() map[string][]*config.Plugin {
 	if len(a.config.Targets) > 0 {
 		cfg[sdk.PluginTypeTarget] = a.config.Targets
 	}
+	if len(a.config.EventSinks) > 0 {
+		cfg[sdk.PluginTypeEventSink] = a.config.EventSinks
+	}
 
 	// Iterate the configs and perform the config setup on each. If the
 	// operator did not specify any config, it will be nil so make sure we
@@ -0,0 +1,60 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package agent
+
+import (
+	"net/url"
+	"os"
+)
+
+// setupProxy exports the agent proxy configuration as the standard proxy
+// environment variables. The Nomad client and the builtin plugins read them
+// through http.ProxyFromEnvironment, and external plugins inherit them from
+// the agent process. Both the upper and lower case variables are set, since
+// tools differ on which ones they read.
+//
+// The variables must be set before any HTTP request is made, as Go reads them
+// only once per process.
+func (a *Agent) setupProxy() {
+	cfg := a.config.Proxy
+	if cfg == nil {
+		return
+	}
+
+	vars := []struct {
+		value string
+		names []string
+	}{
+		{cfg.HTTPProxy, []string{"HTTP_PROXY", "http_proxy"}},
+		{cfg.HTTPSProxy, []string{"HTTPS_PROXY", "https_proxy"}},
+		{cfg.NoProxy, []string{"NO_PROXY", "no_proxy"}},
+	}
+
+	for _, v := range vars {
+		if v.value == "" {
+			continue
+		}
+		for _, name := range v.names {
+			if err := os.Setenv(name, v.value); err != nil {
+				a.logger.Error("failed to set proxy environment variable", "name", name, "error", err)
+			}
+		}
+	}
+
+	if cfg.HTTPProxy != "" || cfg.HTTPSProxy != "" {
+		a.logger.Info("using HTTP proxy",
+			"http_proxy", redactProxyURL(cfg.HTTPProxy),
+			"https_proxy", redactProxyURL(cfg.HTTPSProxy),
+			"no_proxy", cfg.NoProxy)
+	}
+}
+
+// redactProxyURL hides the password of the proxy URL so it can be logged.
+func redactProxyURL(s string) string {
+	u, err := url.Parse(s)
+	if err != nil {
+		return s
+	}
+	return u.Redacted()
+}
@@ -0,0 +1,16 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+//go:build !windows
+// +build !windows
+
+package agent
+
+import (
+	"os"
+	"syscall"
+)
+
+// logLevelSignal is the signal used to toggle the agent log level between the
+// configured level and trace.
+var logLevelSignal os.Signal = syscall.SIGUSR1
@@ -0,0 +1,13 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+//go:build windows
+// +build windows
+
+package agent
+
+import "os"
+
+// logLevelSignal is nil on Windows since SIGUSR1 is not available. The log
+// level can still be changed using the HTTP API.
+var logLevelSignal os.Signal
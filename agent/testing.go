@@ -4,9 +4,17 @@
 package agent
 
 import (
+	"fmt"
 	"net/http"
+	"time"
 
 	metrics "github.com/armon/go-metrics"
+	"github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/nomad-autoscaler/agent/config"
+	"github.com/hashicorp/nomad-autoscaler/plugins/manager"
+	"github.com/hashicorp/nomad-autoscaler/policy"
+	"github.com/hashicorp/nomad-autoscaler/policyeval"
+	"github.com/hashicorp/nomad-autoscaler/sdk"
 )
 
 type MockAgentHTTP struct{}
@@ -23,3 +31,87 @@ func (m *MockAgentHTTP) DisplayMetrics(resp http.ResponseWriter, req *http.Reque
 func (m *MockAgentHTTP) ReloadAgent(resp http.ResponseWriter, req *http.Request) (interface{}, error) {
 	return nil, nil
 }
+
+func (m *MockAgentHTTP) LogLevels() map[string]string {
+	return map[string]string{logSubsystemAgent: hclog.Info.String()}
+}
+
+func (m *MockAgentHTTP) SetLogLevel(_, level string) error {
+	if hclog.LevelFromString(level) == hclog.NoLevel {
+		return fmt.Errorf("invalid log level %q", level)
+	}
+	return nil
+}
+
+func (m *MockAgentHTTP) PendingActions() []*policyeval.PendingAction {
+	return []*policyeval.PendingAction{{ID: "pending", PolicyID: "policy"}}
+}
+
+func (m *MockAgentHTTP) ApproveAction(id string) (*policyeval.PendingAction, error) {
+	if id != "pending" {
+		return nil, policyeval.ErrPendingActionNotFound
+	}
+	return &policyeval.PendingAction{ID: id, PolicyID: "policy"}, nil
+}
+
+func (m *MockAgentHTTP) RejectAction(id string) (*policyeval.PendingAction, error) {
+	if id != "pending" {
+		return nil, policyeval.ErrPendingActionNotFound
+	}
+	return &policyeval.PendingAction{ID: id, PolicyID: "policy"}, nil
+}
+
+func (m *MockAgentHTTP) PluginTimings() []policyeval.PluginTiming {
+	return []policyeval.PluginTiming{{PluginType: "apm", PluginName: "prometheus", Operation: "query", Count: 1}}
+}
+
+func (m *MockAgentHTTP) DecisionHistory(from, to time.Time) []policyeval.DecisionSeries {
+	ts := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	if (!from.IsZero() && ts.Before(from)) || (!to.IsZero() && ts.After(to)) {
+		return nil
+	}
+	return []policyeval.DecisionSeries{
+		{PolicyID: "policy", Name: policyeval.DecisionSeriesActualCount, Points: []policyeval.DecisionPoint{{Timestamp: ts, Value: 2}}},
+		{PolicyID: "policy", Name: policyeval.DecisionSeriesDesiredCount, Points: []policyeval.DecisionPoint{{Timestamp: ts, Value: 3}}},
+	}
+}
+
+func (m *MockAgentHTTP) RuntimeConfig() (*config.Agent, []string, error) {
+	cfg := &config.Agent{
+		LogLevel: "INFO",
+		Nomad:    &config.Nomad{Address: "http://127.0.0.1:4646", Token: config.RedactedValue},
+	}
+	return cfg, []string{"/etc/nomad-autoscaler.d"}, nil
+}
+
+func (m *MockAgentHTTP) PluginStatuses() []manager.PluginStatus {
+	return []manager.PluginStatus{{
+		Name:    "prometheus",
+		Type:    "apm",
+		Driver:  "prometheus",
+		Status:  manager.PluginStatusRunning,
+		Healthy: true,
+	}}
+}
+
+func (m *MockAgentHTTP) ReloadPolicySources(source string) error {
+	switch source {
+	case "", string(policy.SourceNameNomad):
+		return nil
+	default:
+		return fmt.Errorf("%w: %s", policy.ErrSourceNotFound, source)
+	}
+}
+
+func (m *MockAgentHTTP) PolicyStatuses() []policy.HandlerStatus {
+	return []policy.HandlerStatus{{
+		PolicyID:  "policy",
+		State:     policy.HandlerStateScaling,
+		Since:     time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC),
+		LastCheck: "cpu",
+		LastExplanation: &sdk.ScalingActionExplanation{
+			Ratio:   1.5,
+			Samples: 1,
+		},
+	}}
+}
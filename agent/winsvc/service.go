@@ -0,0 +1,63 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+// Package winsvc integrates the agent with the Windows service control
+// manager, so the autoscaler can run supervised on Windows hosts.
+package winsvc
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// ServiceName is the name of the Windows service and of its event log
+// source.
+const ServiceName = "nomad-autoscaler"
+
+// eventID is the ID of the events written to the Windows event log.
+const eventID = 1
+
+var (
+	// isService is set if the process runs as a Windows service.
+	isService bool
+
+	// signalCh receives the service control requests translated to signals.
+	signalCh = make(chan os.Signal, 3)
+)
+
+// IsService returns true if the process runs as a Windows service.
+func IsService() bool {
+	return isService
+}
+
+// SignalChannel returns the channel that receives the requests sent by the
+// Windows service control manager, translated to the equivalent signals: stop
+// and shutdown requests are sent as os.Interrupt, and parameter change
+// requests as syscall.SIGHUP to reload the agent. It never receives when the
+// process doesn't run as a Windows service.
+func SignalChannel() <-chan os.Signal {
+	return signalCh
+}
+
+// eventLogMessage formats a log line for the Windows event log, which
+// doesn't support structured data.
+func eventLogMessage(name, msg string, args ...interface{}) string {
+	var b strings.Builder
+
+	if name != "" {
+		b.WriteString(name)
+		b.WriteString(": ")
+	}
+	b.WriteString(msg)
+
+	for i := 0; i < len(args); i += 2 {
+		if i+1 == len(args) {
+			fmt.Fprintf(&b, " EXTRA_VALUE_AT_END=%v", args[i])
+			break
+		}
+		fmt.Fprintf(&b, " %v=%v", args[i], args[i+1])
+	}
+
+	return b.String()
+}
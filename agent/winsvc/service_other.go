@@ -0,0 +1,15 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+//go:build !windows
+// +build !windows
+
+package winsvc
+
+import "github.com/hashicorp/go-hclog"
+
+// RegisterEventLogSink is a no-op since the Windows event log is only
+// available on Windows.
+func RegisterEventLogSink(hclog.InterceptLogger, hclog.Level) error {
+	return nil
+}
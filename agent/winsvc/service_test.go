@@ -0,0 +1,46 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package winsvc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_eventLogMessage(t *testing.T) {
+	testCases := []struct {
+		name     string
+		logger   string
+		msg      string
+		args     []interface{}
+		expected string
+	}{
+		{
+			name:     "no args",
+			logger:   "agent",
+			msg:      "starting Nomad Autoscaler agent",
+			expected: "agent: starting Nomad Autoscaler agent",
+		},
+		{
+			name:     "args",
+			logger:   "agent.policy_eval",
+			msg:      "failed to scale target",
+			args:     []interface{}{"policy_id", "abc", "count", 3},
+			expected: "agent.policy_eval: failed to scale target policy_id=abc count=3",
+		},
+		{
+			name:     "odd args",
+			msg:      "caught signal",
+			args:     []interface{}{"signal", "interrupt", "extra"},
+			expected: "caught signal signal=interrupt EXTRA_VALUE_AT_END=extra",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, eventLogMessage(tc.logger, tc.msg, tc.args...))
+		})
+	}
+}
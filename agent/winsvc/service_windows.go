@@ -0,0 +1,93 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+//go:build windows
+// +build windows
+
+package winsvc
+
+import (
+	"os"
+	"syscall"
+
+	"github.com/hashicorp/go-hclog"
+	"golang.org/x/sys/windows/svc"
+	"golang.org/x/sys/windows/svc/eventlog"
+)
+
+func init() {
+	var err error
+	isService, err = svc.IsWindowsService()
+	if err != nil || !isService {
+		return
+	}
+
+	// The service name is ignored by services running in their own process.
+	go func() { _ = svc.Run(ServiceName, serviceWindows{}) }()
+}
+
+// serviceWindows implements the svc.Handler interface to handle the requests
+// of the Windows service control manager.
+type serviceWindows struct{}
+
+// Execute reports the service as running and forwards the service control
+// requests to the agent as signals until the service is stopped.
+func (serviceWindows) Execute(_ []string, r <-chan svc.ChangeRequest, s chan<- svc.Status) (bool, uint32) {
+	const accepts = svc.AcceptStop | svc.AcceptShutdown | svc.AcceptParamChange
+	s <- svc.Status{State: svc.Running, Accepts: accepts}
+
+	for c := range r {
+		switch c.Cmd {
+		case svc.Interrogate:
+			s <- c.CurrentStatus
+		case svc.ParamChange:
+			signalCh <- syscall.SIGHUP
+		case svc.Stop, svc.Shutdown:
+			s <- svc.Status{State: svc.StopPending}
+			signalCh <- os.Interrupt
+			return false, 0
+		}
+	}
+	return false, 0
+}
+
+// RegisterEventLogSink forwards the logs at or above the level to the
+// Windows event log when the process runs as a Windows service, since the
+// output of services is discarded. The event log source is expected to be
+// registered when the service is installed, for example with the PowerShell
+// New-EventLog cmdlet. It's a no-op if the process is not a service.
+func RegisterEventLogSink(logger hclog.InterceptLogger, level hclog.Level) error {
+	if !isService {
+		return nil
+	}
+
+	l, err := eventlog.Open(ServiceName)
+	if err != nil {
+		return err
+	}
+
+	logger.RegisterSink(&eventLogSink{log: l, level: level})
+	return nil
+}
+
+// eventLogSink is a hclog.SinkAdapter that writes to the Windows event log.
+type eventLogSink struct {
+	log   *eventlog.Log
+	level hclog.Level
+}
+
+func (s *eventLogSink) Accept(name string, level hclog.Level, msg string, args ...interface{}) {
+	if level < s.level {
+		return
+	}
+
+	msg = eventLogMessage(name, msg, args...)
+	switch {
+	case level >= hclog.Error:
+		_ = s.log.Error(eventID, msg)
+	case level == hclog.Warn:
+		_ = s.log.Warning(eventID, msg)
+	default:
+		_ = s.log.Info(eventID, msg)
+	}
+}
@@ -16,6 +16,7 @@ import (
 	"github.com/hashicorp/nomad-autoscaler/agent"
 	"github.com/hashicorp/nomad-autoscaler/agent/config"
 	agentHTTP "github.com/hashicorp/nomad-autoscaler/agent/http"
+	"github.com/hashicorp/nomad-autoscaler/agent/winsvc"
 	"github.com/hashicorp/nomad-autoscaler/policy"
 	flaghelper "github.com/hashicorp/nomad-autoscaler/sdk/helper/flag"
 	"github.com/hashicorp/nomad-autoscaler/sdk/helper/ptr"
@@ -70,6 +71,27 @@ Options:
     specified, the plugin directory defaults to be that of
     <current-dir>/plugins/.
 
+  -permission-checks=<mode>
+    Controls the checks performed on the ownership and permissions of plugin
+    binaries and policy files. Valid values are enforce, warn, and disabled.
+    In warn mode, world-writable plugin binaries are still not launched. The
+    default is warn.
+
+  -plugin-idle-timeout=<dur>
+    Only launch external plugins once a policy uses them and shut them down
+    after being unused for the specified duration. The default is 0, which
+    launches all configured plugins on startup.
+
+  -no-strict
+    Report unknown arguments and blocks in config files as warnings instead
+    of failing to start. The default is false.
+
+  -dev-fault-injection=<fault=rate>
+    Inject faults at the given rates, between 0 and 1, to test the resilience
+    of the agent and the alerting of operators. Formatted as
+    <fault1>=<rate>,<fault2>=<rate>. Supported faults are "apm_timeout",
+    "target_error" and "plugin_crash". Only use in development environments.
+
 Dynamic Application Sizing Options (Enterprise-only):
 
   -das-evaluate-after=<dur>
@@ -102,13 +124,36 @@ HTTP Options:
 
   -http-bind-address=<addr>
     The HTTP address that the health server will bind to. The default is
-    127.0.0.1.
+    127.0.0.1. An address in the form unix:///path/to/socket binds the server
+    to a Unix socket instead.
 
   -http-bind-port=<port>
     The port that the health server will bind to. The default is 8080.
 
+  -http-tls-cert-file=<path>
+    Path to a PEM encoded certificate used to serve the HTTP API over TLS.
+    Must be specified alongside -http-tls-key-file.
+
+  -http-tls-key-file=<path>
+    Path to the PEM encoded private key of the HTTP API certificate.
+
+  -http-tls-ca-file=<path>
+    Path to a PEM encoded CA cert file used to verify HTTP API client
+    certificates.
+
+  -http-tls-verify-client
+    Require HTTP API clients to present a certificate signed by the CA in
+    -http-tls-ca-file. The default is false.
+
+  -http-token=<token>
+    A token that must be sent as a bearer token in the Authorization header
+    of all HTTP API requests, except for the health endpoint.
+
 Nomad Options:
 
+  The standard Nomad CLI environment variables, such as NOMAD_ADDR and
+  NOMAD_TOKEN, are used when the equivalent option is not set.
+
   -nomad-address=<addr>
     The address of the Nomad server in the form of protocol://addr:port. The
     default is http://127.0.0.1:4646.
@@ -153,6 +198,12 @@ Nomad Options:
     How long applicable Nomad API requests supporting blocking queries are held
     open. Defaults to 5m.
 
+  -nomad-acl-preflight=<mode>
+    Controls the check of the Nomad ACL token capabilities required by the
+    agent when it starts. Supported modes are "enforce", which fails to start
+    the agent if any capability is missing, "warn" and "disabled". Defaults
+    to "enforce".
+
 Policy Options:
 
   -policy-dir=<path>
@@ -166,6 +217,10 @@ Policy Options:
     The default evaluation interval that will be applied to all scaling policies
     which do not specify an evaluation interval.
 
+  -policy-default-query-window-offset=<dur>
+    The default query window offset that will be applied to all policy checks
+    which do not specify a query window offset in the check or the policy.
+
 Policy Evaluation Options:
 
   -policy-eval-ack-timeout=<dur>
@@ -176,9 +231,16 @@ Policy Evaluation Options:
 
   -policy-eval-workers=<key:value>
     The number of workers to initialize for each queue, formatted as
-    <queue1>:<num>,<queue2>:<num>. Nomad Autoscaler supports "cluster" and
-    "horizontal" queues. Nomad Autoscaler Enterprise supports additional
-    "vertical_mem" and "vertical_cpu" queues.
+    <queue1>:<num>,<queue2>:<num>. Nomad Autoscaler supports "cluster",
+    "horizontal", "vertical_cpu", "vertical_mem" and "vertical" queues.
+    Vertical policies can only be loaded from files, unless running Nomad
+    Autoscaler Enterprise.
+
+  -policy-eval-explain
+    Write a structured decision log describing each policy evaluation,
+    including check metrics, strategy results, caps, check selection and
+    cooldown. Can also be enabled for individual policies using the policy
+    explain option.
 
 Policy Source Options:
 
@@ -188,6 +250,20 @@ Policy Source Options:
   -policy-source-disable-nomad
     Disable the sourcing of policies from the Nomad API.
 
+Proxy Options:
+
+  -proxy-http=<url>
+    The URL of the proxy used for HTTP requests made by the agent and its
+    plugins.
+
+  -proxy-https=<url>
+    The URL of the proxy used for HTTPS requests made by the agent and its
+    plugins.
+
+  -proxy-no-proxy=<hosts>
+    A comma-separated list of hosts, domains and CIDR ranges that should be
+    accessed without using a proxy.
+
 Telemetry Options:
 
   -telemetry-disable-hostname
@@ -327,8 +403,18 @@ func (c *AgentCommand) Run(args []string) int {
 		Level:           hclog.LevelFromString(parsedConfig.LogLevel),
 		JSONFormat:      parsedConfig.LogJson,
 		IncludeLocation: parsedConfig.LogIncludeLocation,
+
+		// Allow the log level of the agent subsystems to be changed
+		// independently at runtime.
+		SyncParentLevel: true,
 	})
 
+	// Windows services have no console, so the logs are also sent to the
+	// Windows event log.
+	if err := winsvc.RegisterEventLogSink(logger, hclog.Info); err != nil {
+		logger.Warn("failed to open the Windows event log", "error", err)
+	}
+
 	logger.Info("starting Nomad Autoscaler agent")
 
 	// Compile agent information for output later
@@ -399,6 +485,12 @@ func (c *AgentCommand) Run(args []string) int {
 		return 1
 	}
 
+	// Fail fast if the Nomad ACL token can't perform the agent operations.
+	if err := c.agent.CheckNomadACL(); err != nil {
+		logger.Error("failed to check the Nomad ACL token", "error", err)
+		return 1
+	}
+
 	switch *parsedConfig.HighAvailability.Enabled {
 	case true:
 		logger.Info("running in HA mode",
@@ -451,6 +543,7 @@ func (c *AgentCommand) readConfig() (*config.Agent, []string) {
 			Sources: []*config.PolicySource{},
 		},
 		PolicyEval:       &config.PolicyEval{},
+		Proxy:            &config.Proxy{},
 		Telemetry:        &config.Telemetry{},
 		HighAvailability: &config.HighAvailability{},
 	}
@@ -458,6 +551,7 @@ func (c *AgentCommand) readConfig() (*config.Agent, []string) {
 	var disableFileSource bool
 	var disableNomadSource bool
 	var enableHighAvailability bool
+	var devFaultInjection string
 
 	modeChecker := config.NewModeChecker()
 
@@ -471,6 +565,10 @@ func (c *AgentCommand) readConfig() (*config.Agent, []string) {
 	flags.BoolVar(&cmdConfig.LogIncludeLocation, "log-include-location", false, "")
 	flags.BoolVar(&cmdConfig.EnableDebug, "enable-debug", false, "")
 	flags.StringVar(&cmdConfig.PluginDir, "plugin-dir", "", "")
+	flags.StringVar(&cmdConfig.PermissionChecks, "permission-checks", "", "")
+	flags.DurationVar(&cmdConfig.PluginIdleTimeout, "plugin-idle-timeout", 0, "")
+	flags.BoolVar(&cmdConfig.NoStrict, "no-strict", false, "")
+	flags.StringVar(&devFaultInjection, "dev-fault-injection", "", "")
 
 	// Specify our Dynamic Application Sizing flags.
 	modeChecker.Flag("das-evaluate-after", []string{"ent"}, func(name string) {
@@ -507,6 +605,11 @@ func (c *AgentCommand) readConfig() (*config.Agent, []string) {
 	// Specify our HTTP bind flags.
 	flags.StringVar(&cmdConfig.HTTP.BindAddress, "http-bind-address", "", "")
 	flags.IntVar(&cmdConfig.HTTP.BindPort, "http-bind-port", 0, "")
+	flags.StringVar(&cmdConfig.HTTP.TLSCertFile, "http-tls-cert-file", "", "")
+	flags.StringVar(&cmdConfig.HTTP.TLSKeyFile, "http-tls-key-file", "", "")
+	flags.StringVar(&cmdConfig.HTTP.TLSCAFile, "http-tls-ca-file", "", "")
+	flags.BoolVar(&cmdConfig.HTTP.TLSVerifyClient, "http-tls-verify-client", false, "")
+	flags.StringVar(&cmdConfig.HTTP.Token, "http-token", "", "")
 
 	// Specify our Nomad client CLI flags.
 	flags.StringVar(&cmdConfig.Nomad.Address, "nomad-address", "", "")
@@ -521,6 +624,7 @@ func (c *AgentCommand) readConfig() (*config.Agent, []string) {
 	flags.StringVar(&cmdConfig.Nomad.TLSServerName, "nomad-tls-server-name", "", "")
 	flags.BoolVar(&cmdConfig.Nomad.SkipVerify, "nomad-skip-verify", false, "")
 	flags.DurationVar(&cmdConfig.Nomad.BlockQueryWaitTime, "nomad-block-query-wait-time", 0, "")
+	flags.StringVar(&cmdConfig.Nomad.ACLPreflight, "nomad-acl-preflight", "", "")
 
 	// Specify our Policy CLI flags.
 	flags.StringVar(&cmdConfig.Policy.Dir, "policy-dir", "", "")
@@ -532,6 +636,10 @@ func (c *AgentCommand) readConfig() (*config.Agent, []string) {
 		cmdConfig.Policy.DefaultEvaluationInterval = d
 		return nil
 	}), "policy-default-evaluation-interval", "")
+	flags.Var((flaghelper.FuncDurationVar)(func(d time.Duration) error {
+		cmdConfig.Policy.DefaultQueryWindowOffset = d
+		return nil
+	}), "policy-default-query-window-offset", "")
 
 	// Specify our Policy Eval flags.
 	flags.IntVar(&cmdConfig.PolicyEval.DeliveryLimit, "policy-eval-delivery-limit", 0, "")
@@ -543,6 +651,12 @@ func (c *AgentCommand) readConfig() (*config.Agent, []string) {
 		cmdConfig.PolicyEval.Workers = m
 		return nil
 	}), "policy-eval-workers", "")
+	flags.BoolVar(&cmdConfig.PolicyEval.Explain, "policy-eval-explain", false, "")
+
+	// Specify our Proxy flags.
+	flags.StringVar(&cmdConfig.Proxy.HTTPProxy, "proxy-http", "", "")
+	flags.StringVar(&cmdConfig.Proxy.HTTPSProxy, "proxy-https", "", "")
+	flags.StringVar(&cmdConfig.Proxy.NoProxy, "proxy-no-proxy", "", "")
 
 	// Specify our Policy Sources flags.
 	flags.BoolVar(&disableFileSource, "policy-source-disable-file", false, "")
@@ -610,13 +724,25 @@ func (c *AgentCommand) readConfig() (*config.Agent, []string) {
 	if enableHighAvailability {
 		cmdConfig.HighAvailability.Enabled = ptr.Of(true)
 	}
+
+	if devFaultInjection != "" {
+		faults, err := config.ParseFaultInjection(devFaultInjection)
+		if err != nil {
+			fmt.Printf("Invalid -dev-fault-injection value: %s\n", err)
+			return nil, configPath
+		}
+		cmdConfig.DevFaultInjection = faults
+	}
 	// Validate config values from flags.
 	if err := cmdConfig.Validate(); err != nil {
 		fmt.Printf("%s\n", err)
 		return nil, configPath
 	}
 
-	fileConfig, err := config.LoadPaths(configPath)
+	fileConfig, warnings, err := config.LoadPaths(configPath, !cmdConfig.NoStrict)
+	for _, w := range warnings {
+		fmt.Printf("Warning: %s\n", w.Error())
+	}
 	if err != nil {
 		fmt.Printf("%s\n", err)
 		return nil, configPath
@@ -34,6 +34,8 @@ func TestCommandAgent_readConfig(t *testing.T) {
 				"-log-include-location",
 				"-enable-debug",
 				"-plugin-dir", "./plugins",
+				"-permission-checks", "enforce",
+				"-plugin-idle-timeout", "10m",
 			},
 			want: defaultConfig.Merge(&config.Agent{
 				LogLevel:           "WARN",
@@ -41,6 +43,8 @@ func TestCommandAgent_readConfig(t *testing.T) {
 				LogIncludeLocation: true,
 				EnableDebug:        true,
 				PluginDir:          "./plugins",
+				PermissionChecks:   "enforce",
+				PluginIdleTimeout:  10 * time.Minute,
 			}),
 		},
 		{
@@ -48,11 +52,21 @@ func TestCommandAgent_readConfig(t *testing.T) {
 			args: []string{
 				"-http-bind-address", "10.0.0.1",
 				"-http-bind-port", "9999",
+				"-http-tls-cert-file", "./server.pem",
+				"-http-tls-key-file", "./server-key.pem",
+				"-http-tls-ca-file", "./ca.pem",
+				"-http-tls-verify-client",
+				"-http-token", "secret",
 			},
 			want: defaultConfig.Merge(&config.Agent{
 				HTTP: &config.HTTP{
-					BindAddress: "10.0.0.1",
-					BindPort:    9999,
+					BindAddress:     "10.0.0.1",
+					BindPort:        9999,
+					TLSCertFile:     "./server.pem",
+					TLSKeyFile:      "./server-key.pem",
+					TLSCAFile:       "./ca.pem",
+					TLSVerifyClient: true,
+					Token:           "secret",
 				},
 			}),
 		},
@@ -95,12 +109,14 @@ func TestCommandAgent_readConfig(t *testing.T) {
 				"-policy-dir", "./policies",
 				"-policy-default-cooldown", "10m",
 				"-policy-default-evaluation-interval", "20s",
+				"-policy-default-query-window-offset", "3m",
 			},
 			want: defaultConfig.Merge(&config.Agent{
 				Policy: &config.Policy{
 					Dir:                       "./policies",
 					DefaultCooldown:           10 * time.Minute,
 					DefaultEvaluationInterval: 20 * time.Second,
+					DefaultQueryWindowOffset:  3 * time.Minute,
 				},
 			}),
 		},
@@ -242,6 +258,7 @@ func TestCommandAgent_readConfig(t *testing.T) {
 					Dir:                       "./policy-dir-from-file",
 					DefaultCooldown:           12 * time.Second,
 					DefaultEvaluationInterval: 50 * time.Minute,
+					DefaultQueryWindowOffset:  2 * time.Minute,
 					Sources: []*config.PolicySource{
 						{Name: "file", Enabled: ptr.Of(false)},
 						{Name: "nomad", Enabled: ptr.Of(false)},
@@ -329,6 +346,7 @@ func TestCommandAgent_readConfig(t *testing.T) {
 					Dir:                       "./policy-dir-from-file",
 					DefaultCooldown:           12 * time.Second,
 					DefaultEvaluationInterval: 50 * time.Minute,
+					DefaultQueryWindowOffset:  2 * time.Minute,
 					Sources: []*config.PolicySource{
 						{Name: "file", Enabled: ptr.Of(false)},
 						{Name: "nomad", Enabled: ptr.Of(false)},
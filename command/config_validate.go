@@ -0,0 +1,262 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package command
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/nomad-autoscaler/agent"
+	"github.com/hashicorp/nomad-autoscaler/agent/config"
+	"github.com/hashicorp/nomad-autoscaler/plugins/manager"
+	"github.com/hashicorp/nomad-autoscaler/sdk"
+	flaghelper "github.com/hashicorp/nomad-autoscaler/sdk/helper/flag"
+	nomadHelper "github.com/hashicorp/nomad-autoscaler/sdk/helper/nomad"
+	"github.com/hashicorp/nomad/api"
+)
+
+type ConfigValidateCommand struct{}
+
+// Help should return long-form help text that includes the command-line
+// usage, a brief few sentences explaining the function of the command,
+// and the complete list of flags the command accepts.
+func (c *ConfigValidateCommand) Help() string {
+	helpText := `
+Usage: nomad-autoscaler config validate [options]
+
+  Loads, merges and validates the agent configuration, then performs the same
+  startup steps as the agent and reports any problem found:
+
+    - every configured plugin is launched and its config is set
+    - the policies in the policy directory are validated
+    - the Nomad API is reachable and the ACL token has the capabilities
+      required by the policy sources and targets of the agent
+
+  The exit code is 1 if any error is found, which makes the command suitable
+  for use in CI pipelines.
+
+Options:
+
+  -config=<path>
+    The path to either a single config file or a directory of config files
+    to validate. Can be specified multiple times.
+
+  -plugin-dir=<path>
+    The plugin directory used to discover external plugins. Overrides the
+    plugin_dir value of the configuration.
+
+  -skip-plugins
+    Don't launch the configured plugins.
+
+  -skip-nomad
+    Don't connect to the Nomad API.
+`
+	return strings.TrimSpace(helpText)
+}
+
+// Synopsis is a one-line, short synopsis of the command.
+func (c *ConfigValidateCommand) Synopsis() string {
+	return "Validates the agent configuration, plugins and Nomad access"
+}
+
+// Run runs the command with the given CLI arguments and returns the exit
+// status.
+func (c *ConfigValidateCommand) Run(args []string) int {
+	var (
+		configPath  []string
+		pluginDir   string
+		skipPlugins bool
+		skipNomad   bool
+	)
+
+	flags := flag.NewFlagSet("config validate", flag.ContinueOnError)
+	flags.Usage = func() { fmt.Println(c.Help()) }
+	flags.Var((*flaghelper.StringFlag)(&configPath), "config", "")
+	flags.StringVar(&pluginDir, "plugin-dir", "", "")
+	flags.BoolVar(&skipPlugins, "skip-plugins", false, "")
+	flags.BoolVar(&skipNomad, "skip-nomad", false, "")
+
+	if err := flags.Parse(args); err != nil {
+		return 1
+	}
+
+	r := &validationReport{w: os.Stdout}
+
+	r.section("Agent configuration")
+	cfg, warnings, err := config.LoadPaths(configPath, true)
+	for _, w := range warnings {
+		r.warn("%s", w.Error())
+	}
+	if err != nil {
+		r.error("%s", strings.TrimSpace(err.Error()))
+		return r.exitCode()
+	}
+	r.ok("loaded and validated %d config path(s)", len(configPath))
+
+	if pluginDir != "" {
+		cfg.PluginDir = pluginDir
+	}
+
+	if !skipPlugins {
+		logger := hclog.New(&hclog.LoggerOptions{
+			Name:  "config-validate",
+			Level: hclog.Off,
+		})
+		pm := manager.NewPluginManager(logger, cfg.PluginDir, cfg.PermissionChecks, 0, agentPluginsConfig(cfg))
+		validatePlugins(r, pm)
+		pm.KillPlugins()
+	}
+
+	if cfg.Policy.Dir != "" {
+		validatePolicyDir(r, cfg)
+	}
+
+	if !skipNomad {
+		nomadCfg := nomadHelper.MergeDefaultWithAgentConfig(cfg.Nomad)
+		client, err := api.NewClient(nomadCfg)
+		r.section("Nomad")
+		if err != nil {
+			r.error("failed to create Nomad client: %v", err)
+		} else {
+			validateNomad(r, client, nomadCfg.Address, nomadCfg.Namespace, agent.RequiredNomadCapabilities(cfg))
+		}
+	}
+
+	fmt.Fprintf(r.w, "\n%d error(s), %d warning(s)\n", r.errors, r.warnings)
+	return r.exitCode()
+}
+
+// validationReport writes the results of the config validation checks and
+// counts the errors and warnings found.
+type validationReport struct {
+	w        io.Writer
+	errors   int
+	warnings int
+}
+
+func (r *validationReport) section(name string) {
+	fmt.Fprintf(r.w, "==> %s\n", name)
+}
+
+func (r *validationReport) ok(format string, a ...interface{}) {
+	fmt.Fprintf(r.w, "OK: %s\n", fmt.Sprintf(format, a...))
+}
+
+func (r *validationReport) warn(format string, a ...interface{}) {
+	r.warnings++
+	fmt.Fprintf(r.w, "Warning: %s\n", fmt.Sprintf(format, a...))
+}
+
+func (r *validationReport) error(format string, a ...interface{}) {
+	r.errors++
+	fmt.Fprintf(r.w, "Error: %s\n", fmt.Sprintf(format, a...))
+}
+
+func (r *validationReport) exitCode() int {
+	if r.errors > 0 {
+		return 1
+	}
+	return 0
+}
+
+// agentPluginsConfig returns the config of all the plugins used by the agent.
+func agentPluginsConfig(cfg *config.Agent) map[string][]*config.Plugin {
+	return pluginsConfig(cfg, map[string][]*config.Plugin{
+		sdk.PluginTypeAPM:       cfg.APMs,
+		sdk.PluginTypeStrategy:  cfg.Strategies,
+		sdk.PluginTypeTarget:    cfg.Targets,
+		sdk.PluginTypeEventSink: cfg.EventSinks,
+	})
+}
+
+// validatePlugins launches all the plugins of the plugin manager and reports
+// the ones that failed to launch or to set their config.
+func validatePlugins(r *validationReport, pm *manager.PluginManager) {
+	r.section("Plugins")
+
+	// The errors returned by Load are also recorded in the status of each
+	// plugin, which allows reporting them individually.
+	_ = pm.Load()
+
+	for _, s := range pm.PluginStatuses() {
+		if s.Healthy {
+			r.ok("%s plugin %s (%s)", s.Type, s.Name, s.Driver)
+			continue
+		}
+		r.error("%s plugin %s (%s): %s", s.Type, s.Name, s.Driver, s.Error)
+	}
+}
+
+// validatePolicyDir validates the policies in the policy directory of the
+// agent.
+func validatePolicyDir(r *validationReport, cfg *config.Agent) {
+	r.section("Policies")
+
+	files, err := policyFiles([]string{cfg.Policy.Dir})
+	if err != nil {
+		r.error("%v", err)
+		return
+	}
+
+	var buf bytes.Buffer
+	numErrors, numWarnings := validatePolicyFiles(&buf, newPolicyProcessor(cfg), files)
+	r.errors += numErrors
+	r.warnings += numWarnings
+	_, _ = buf.WriteTo(r.w)
+
+	if numErrors == 0 {
+		r.ok("validated %d policy file(s) in %s", len(files), cfg.Policy.Dir)
+	}
+}
+
+// validateNomad checks the agent can reach the Nomad API and that its ACL
+// token has the capabilities required by the agent in the namespace, or in
+// each namespace if the namespace is the wildcard.
+func validateNomad(r *validationReport, client *api.Client, address, namespace string, caps []nomadHelper.Capability) {
+	leader, err := client.Status().Leader()
+	if err != nil {
+		r.error("failed to connect to Nomad at %s: %v", address, err)
+		return
+	}
+	r.ok("connected to Nomad at %s, leader is %s", address, leader)
+
+	token, _, err := client.ACLTokens().Self(nil)
+	switch {
+	case err != nil && strings.Contains(err.Error(), "ACL support disabled"):
+		r.ok("ACLs are disabled")
+	case err != nil:
+		r.error("failed to read ACL token: %v", err)
+		return
+	default:
+		r.ok("ACL token %q is valid", token.Name)
+	}
+
+	missing, err := nomadHelper.MissingCapabilities(client, namespace, caps)
+	if err != nil {
+		r.error("%v", err)
+		return
+	}
+	for _, c := range caps {
+		var capMissing bool
+		for _, m := range missing {
+			if m.Capability != c {
+				continue
+			}
+			capMissing = true
+			if m.Namespace == "" {
+				r.error("ACL token is missing the %s capability", c)
+			} else {
+				r.error("ACL token is missing the %s capability in namespace %s", c, m.Namespace)
+			}
+		}
+		if !capMissing {
+			r.ok("ACL token has the %s capability", c)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package command
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	nomadHelper "github.com/hashicorp/nomad-autoscaler/sdk/helper/nomad"
+	"github.com/hashicorp/nomad/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var allCapabilities = []nomadHelper.Capability{
+	nomadHelper.CapabilityListScalingPolicies,
+	nomadHelper.CapabilityReadJob,
+	nomadHelper.CapabilityScaleJob,
+	nomadHelper.CapabilityNodeWrite,
+}
+
+func Test_validateNomad(t *testing.T) {
+	testCases := []struct {
+		name             string
+		handler          http.HandlerFunc
+		expectedErrors   int
+		expectedWarnings int
+		expectedOutput   []string
+	}{
+		{
+			name: "ACLs disabled",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				switch r.URL.Path {
+				case "/v1/status/leader":
+					_, _ = w.Write([]byte(`"10.0.0.1:4647"`))
+				case "/v1/acl/token/self":
+					w.WriteHeader(http.StatusBadRequest)
+					_, _ = w.Write([]byte("ACL support disabled"))
+				case "/v1/scaling/policies":
+					_, _ = w.Write([]byte(`[]`))
+				default:
+					// The probed jobs and nodes don't exist.
+					w.WriteHeader(http.StatusNotFound)
+				}
+			},
+			expectedOutput: []string{
+				"OK: connected to Nomad at {{address}}, leader is 10.0.0.1:4647",
+				"OK: ACLs are disabled",
+				"OK: ACL token has the list-scaling-policies capability",
+				"OK: ACL token has the read-job capability",
+				"OK: ACL token has the scale-job capability",
+				"OK: ACL token has the node:write capability",
+			},
+		},
+		{
+			name: "missing capabilities",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				switch r.URL.Path {
+				case "/v1/status/leader":
+					_, _ = w.Write([]byte(`"10.0.0.1:4647"`))
+				case "/v1/acl/token/self":
+					_, _ = w.Write([]byte(`{"Name": "autoscaler"}`))
+				case "/v1/scaling/policies":
+					_, _ = w.Write([]byte(`[]`))
+				default:
+					if strings.HasPrefix(r.URL.Path, "/v1/job/") && r.Method == http.MethodGet {
+						w.WriteHeader(http.StatusNotFound)
+						return
+					}
+					w.WriteHeader(http.StatusForbidden)
+					_, _ = w.Write([]byte("Permission denied"))
+				}
+			},
+			expectedErrors: 2,
+			expectedOutput: []string{
+				`OK: ACL token "autoscaler" is valid`,
+				"OK: ACL token has the list-scaling-policies capability",
+				"OK: ACL token has the read-job capability",
+				"Error: ACL token is missing the scale-job capability in namespace default",
+				"Error: ACL token is missing the node:write capability",
+			},
+		},
+		{
+			name: "invalid token",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				switch r.URL.Path {
+				case "/v1/status/leader":
+					_, _ = w.Write([]byte(`"10.0.0.1:4647"`))
+				default:
+					w.WriteHeader(http.StatusForbidden)
+					_, _ = w.Write([]byte("ACL token not found"))
+				}
+			},
+			expectedErrors: 1,
+			expectedOutput: []string{
+				"Error: failed to read ACL token: Unexpected response code: 403 (ACL token not found)",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				// Query responses must include the index headers.
+				w.Header().Set("X-Nomad-Index", "1")
+				w.Header().Set("X-Nomad-LastContact", "0")
+				tc.handler(w, r)
+			}))
+			defer srv.Close()
+
+			client, err := api.NewClient(&api.Config{Address: srv.URL})
+			require.NoError(t, err)
+
+			var buf bytes.Buffer
+			r := &validationReport{w: &buf}
+			validateNomad(r, client, srv.URL, "", allCapabilities)
+
+			assert.Equal(t, tc.expectedErrors, r.errors)
+			assert.Equal(t, tc.expectedWarnings, r.warnings)
+			for _, line := range tc.expectedOutput {
+				line = strings.ReplaceAll(line, "{{address}}", srv.URL)
+				assert.Contains(t, buf.String(), line+"\n")
+			}
+		})
+	}
+}
+
+func Test_validateNomad_unreachable(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	srv.Close()
+
+	client, err := api.NewClient(&api.Config{Address: srv.URL})
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	r := &validationReport{w: &buf}
+	validateNomad(r, client, srv.URL, "", allCapabilities)
+
+	assert.Equal(t, 1, r.errors)
+	assert.Equal(t, 1, r.exitCode())
+	assert.Contains(t, buf.String(), "Error: failed to connect to Nomad at "+srv.URL)
+}
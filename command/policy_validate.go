@@ -0,0 +1,166 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package command
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/nomad-autoscaler/agent/config"
+	"github.com/hashicorp/nomad-autoscaler/policy"
+	filePolicy "github.com/hashicorp/nomad-autoscaler/policy/file"
+	fileHelper "github.com/hashicorp/nomad-autoscaler/sdk/helper/file"
+	flaghelper "github.com/hashicorp/nomad-autoscaler/sdk/helper/flag"
+)
+
+type PolicyValidateCommand struct{}
+
+// Help should return long-form help text that includes the command-line
+// usage, a brief few sentences explaining the function of the command,
+// and the complete list of flags the command accepts.
+func (c *PolicyValidateCommand) Help() string {
+	helpText := `
+Usage: nomad-autoscaler policy validate [options] <path> [<path>...]
+
+  Validates scaling policy files and reports lint warnings for policies which
+  are valid but likely to cause unexpected scaling behaviour, along with a
+  suggestion to fix them. Directories are searched for .hcl and .json files.
+
+  The exit code is 1 if any policy is invalid. Warnings don't affect the exit
+  code unless the -strict option is set.
+
+Options:
+
+  -config=<path>
+    The path to either a single config file or a directory of config files
+    used to set the policy defaults, as used by the agent.
+
+  -strict
+    Exit with code 1 if any lint warning is reported.
+`
+	return strings.TrimSpace(helpText)
+}
+
+// Synopsis is a one-line, short synopsis of the command.
+func (c *PolicyValidateCommand) Synopsis() string {
+	return "Validates and lints scaling policy files"
+}
+
+// Run runs the command with the given CLI arguments and returns the exit
+// status.
+func (c *PolicyValidateCommand) Run(args []string) int {
+	var (
+		configPath []string
+		strict     bool
+	)
+
+	flags := flag.NewFlagSet("policy validate", flag.ContinueOnError)
+	flags.Usage = func() { fmt.Println(c.Help()) }
+	flags.Var((*flaghelper.StringFlag)(&configPath), "config", "")
+	flags.BoolVar(&strict, "strict", false, "")
+
+	if err := flags.Parse(args); err != nil {
+		return 1
+	}
+
+	paths := flags.Args()
+	if len(paths) == 0 {
+		fmt.Println("At least one policy file or directory is required.")
+		fmt.Println("Run 'nomad-autoscaler policy validate --help' for more information.")
+		return 1
+	}
+
+	cfg, warnings, err := config.LoadPaths(configPath, true)
+	for _, w := range warnings {
+		fmt.Printf("Warning: %s\n", w.Error())
+	}
+	if err != nil {
+		fmt.Printf("%s\n", err)
+		return 1
+	}
+
+	files, err := policyFiles(paths)
+	if err != nil {
+		fmt.Printf("%s\n", err)
+		return 1
+	}
+
+	numErrors, numWarnings := validatePolicyFiles(os.Stdout, newPolicyProcessor(cfg), files)
+
+	fmt.Printf("\n%d file(s) validated, %d error(s), %d warning(s)\n", len(files), numErrors, numWarnings)
+	if numErrors > 0 || (strict && numWarnings > 0) {
+		return 1
+	}
+	return 0
+}
+
+// policyFiles returns the policy files in the paths, expanding directories.
+func policyFiles(paths []string) ([]string, error) {
+	var files []string
+
+	for _, p := range paths {
+		fi, err := os.Stat(p)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %v", p, err)
+		}
+		if !fi.IsDir() {
+			files = append(files, p)
+			continue
+		}
+
+		dirFiles, err := fileHelper.GetFileListFromDir(p, ".hcl", ".json")
+		if err != nil {
+			return nil, fmt.Errorf("failed to list files in directory %s: %v", p, err)
+		}
+		files = append(files, dirFiles...)
+	}
+
+	return files, nil
+}
+
+// validatePolicyFiles writes the validation errors and lint warnings of the
+// policies in the files and returns the number of each found.
+func validatePolicyFiles(w io.Writer, pr *policy.Processor, files []string) (int, int) {
+	var numErrors, numWarnings int
+
+	for _, file := range files {
+		policies, err := filePolicy.DecodeFile(file, pr)
+		if err != nil {
+			numErrors++
+			fmt.Fprintf(w, "Error: %s: %s\n", file, strings.TrimSpace(err.Error()))
+		}
+
+		names := make([]string, 0, len(policies))
+		for name := range policies {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			p := policies[name]
+
+			// Policies which fail the processor validation have already been
+			// reported by the decoding.
+			if pr.ValidatePolicy(p) != nil {
+				continue
+			}
+			if err := p.Validate(); err != nil {
+				numErrors++
+				fmt.Fprintf(w, "Error: %s: %s: %s\n", file, name, strings.TrimSpace(err.Error()))
+				continue
+			}
+
+			for _, lw := range policy.Lint(p) {
+				numWarnings++
+				fmt.Fprintf(w, "Warning: %s: %s: %s\n", file, name, lw.String())
+			}
+		}
+	}
+
+	return numErrors, numWarnings
+}
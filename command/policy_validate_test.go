@@ -0,0 +1,78 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package command
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/nomad-autoscaler/policy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_validatePolicyFiles(t *testing.T) {
+	dir := t.TempDir()
+
+	valid := filepath.Join(dir, "valid.hcl")
+	require.NoError(t, os.WriteFile(valid, []byte(`
+scaling "cache" {
+  min = 2
+  max = 2
+
+  policy {
+    cooldown = "10s"
+
+    check "cpu" {
+      source = "prometheus"
+      query  = "cpu"
+
+      strategy "target-value" {
+        target = "70"
+      }
+    }
+
+    target "aws-asg" {
+      aws_asg_name = "cache"
+    }
+  }
+}
+`), 0o600))
+
+	invalid := filepath.Join(dir, "invalid.hcl")
+	require.NoError(t, os.WriteFile(invalid, []byte(`
+scaling "web" {
+  min = 5
+  max = 1
+
+  policy {
+    target "aws-asg" {
+      aws_asg_name = "web"
+    }
+  }
+}
+`), 0o600))
+
+	files, err := policyFiles([]string{dir})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{valid, invalid}, files)
+
+	pr := policy.NewProcessor(&policy.ConfigDefaults{
+		DefaultEvaluationInterval: time.Minute,
+		DefaultCooldown:           5 * time.Minute,
+	}, nil)
+
+	var buf bytes.Buffer
+	numErrors, numWarnings := validatePolicyFiles(&buf, pr, []string{valid, invalid})
+	assert.Equal(t, 1, numErrors)
+	assert.Equal(t, 2, numWarnings)
+
+	out := buf.String()
+	assert.Contains(t, out, "Warning: "+valid+": cache: cooldown 10s is shorter than the evaluation interval 1m0s")
+	assert.Contains(t, out, "Warning: "+valid+": cache: min and max are both 2")
+	assert.Contains(t, out, "Error: "+invalid+": ")
+}
@@ -0,0 +1,300 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package command
+
+import (
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/nomad-autoscaler/agent/config"
+	"github.com/hashicorp/nomad-autoscaler/plugins"
+	"github.com/hashicorp/nomad-autoscaler/plugins/manager"
+	"github.com/hashicorp/nomad-autoscaler/policy"
+	filePolicy "github.com/hashicorp/nomad-autoscaler/policy/file"
+	"github.com/hashicorp/nomad-autoscaler/policyeval"
+	"github.com/hashicorp/nomad-autoscaler/sdk"
+	flaghelper "github.com/hashicorp/nomad-autoscaler/sdk/helper/flag"
+	nomadHelper "github.com/hashicorp/nomad-autoscaler/sdk/helper/nomad"
+)
+
+type SimulateCommand struct{}
+
+// Help should return long-form help text that includes the command-line
+// usage, a brief few sentences explaining the function of the command,
+// and the complete list of flags the command accepts.
+func (c *SimulateCommand) Help() string {
+	helpText := `
+Usage: nomad-autoscaler simulate [options]
+
+  Replays the historical metrics of a scaling policy file through the policy
+  strategies and prints the count the policy would have produced at each
+  evaluation. It can be used to tune policies before they are rolled out.
+
+  The target is not queried or modified, so the simulation starts from the
+  initial count and assumes every scaling action succeeds immediately. Check
+  groups are not taken into account.
+
+Options:
+
+  -config=<path>
+    The path to either a single config file or a directory of config files
+    used to configure the APM and strategy plugins, as used by the agent.
+
+  -policy=<path>
+    The path to the scaling policy file to simulate. Required.
+
+  -policy-name=<name>
+    The name of the policy to simulate. Required if the file defines more
+    than one policy.
+
+  -from=<time>
+    The start of the simulation, as an RFC3339 timestamp or a duration
+    relative to the current time, such as 24h. Required.
+
+  -to=<time>
+    The end of the simulation, as an RFC3339 timestamp or a duration relative
+    to the current time. The default is the current time.
+
+  -initial-count=<count>
+    The count of the target at the start of the simulation. The default is
+    the policy min value.
+
+  -plugin-dir=<path>
+    The directory used to discover external plugins. The default is the
+    plugin directory of the agent configuration.
+
+  -format=<format>
+    The output format. Valid values are table and csv. The default is table.
+`
+	return strings.TrimSpace(helpText)
+}
+
+// Synopsis is a one-line, short synopsis of the command.
+func (c *SimulateCommand) Synopsis() string {
+	return "Simulates a scaling policy using historical metrics"
+}
+
+// Run runs the command with the given CLI arguments and returns the exit
+// status.
+func (c *SimulateCommand) Run(args []string) int {
+	var (
+		configPath   []string
+		policyPath   string
+		policyName   string
+		fromStr      string
+		toStr        string
+		initialCount int64
+		pluginDir    string
+		format       string
+	)
+
+	flags := flag.NewFlagSet("simulate", flag.ContinueOnError)
+	flags.Usage = func() { fmt.Println(c.Help()) }
+	flags.Var((*flaghelper.StringFlag)(&configPath), "config", "")
+	flags.StringVar(&policyPath, "policy", "", "")
+	flags.StringVar(&policyName, "policy-name", "", "")
+	flags.StringVar(&fromStr, "from", "", "")
+	flags.StringVar(&toStr, "to", "", "")
+	flags.Int64Var(&initialCount, "initial-count", -1, "")
+	flags.StringVar(&pluginDir, "plugin-dir", "", "")
+	flags.StringVar(&format, "format", "table", "")
+
+	if err := flags.Parse(args); err != nil {
+		return 1
+	}
+
+	if policyPath == "" || fromStr == "" {
+		fmt.Println("The -policy and -from options are required.")
+		fmt.Println("Run 'nomad-autoscaler simulate --help' for more information.")
+		return 1
+	}
+	if format != "table" && format != "csv" {
+		fmt.Printf("Invalid format %q, must be one of table or csv\n", format)
+		return 1
+	}
+
+	now := time.Now().UTC()
+	from, err := parseSimulationTime(fromStr, now)
+	if err != nil {
+		fmt.Printf("Invalid -from value: %v\n", err)
+		return 1
+	}
+	to := now
+	if toStr != "" {
+		if to, err = parseSimulationTime(toStr, now); err != nil {
+			fmt.Printf("Invalid -to value: %v\n", err)
+			return 1
+		}
+	}
+
+	cfg, warnings, err := config.LoadPaths(configPath, true)
+	for _, w := range warnings {
+		fmt.Printf("Warning: %s\n", w.Error())
+	}
+	if err != nil {
+		fmt.Printf("%s\n", err)
+		return 1
+	}
+	if pluginDir != "" {
+		cfg.PluginDir = pluginDir
+	}
+
+	p, err := loadSimulationPolicy(cfg, policyPath, policyName)
+	if err != nil {
+		fmt.Printf("Failed to load policy: %v\n", err)
+		return 1
+	}
+	if initialCount < 0 {
+		initialCount = p.Min
+	}
+
+	logger := hclog.New(&hclog.LoggerOptions{
+		Name:  "simulate",
+		Level: hclog.LevelFromString(cfg.LogLevel),
+	})
+
+	pm := manager.NewPluginManager(logger, cfg.PluginDir, cfg.PermissionChecks, 0, simulationPluginsConfig(cfg))
+	defer pm.KillPlugins()
+	if err := pm.Load(); err != nil {
+		fmt.Printf("Failed to load plugins: %v\n", err)
+		return 1
+	}
+
+	steps, err := policyeval.Simulate(pm, p, sdk.TimeRange{From: from, To: to}, initialCount)
+	if err != nil {
+		fmt.Printf("Failed to simulate policy: %v\n", err)
+		return 1
+	}
+
+	writeSimulation(os.Stdout, p, steps, format)
+	return 0
+}
+
+// parseSimulationTime parses an RFC3339 timestamp or a duration which is
+// subtracted from now.
+func parseSimulationTime(s string, now time.Time) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+
+	d, err := time.ParseDuration(strings.TrimPrefix(s, "-"))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%q is not an RFC3339 timestamp or a duration", s)
+	}
+	return now.Add(-d), nil
+}
+
+// loadSimulationPolicy decodes the policy file, applying the agent defaults,
+// and returns the policy to simulate.
+func loadSimulationPolicy(cfg *config.Agent, path, name string) (*sdk.ScalingPolicy, error) {
+	policies, err := filePolicy.DecodeFile(path, newPolicyProcessor(cfg))
+	if err != nil {
+		return nil, err
+	}
+
+	if name != "" {
+		p, ok := policies[name]
+		if !ok {
+			return nil, fmt.Errorf("policy %q doesn't exist in file %s", name, path)
+		}
+		return p, nil
+	}
+
+	switch len(policies) {
+	case 0:
+		return nil, fmt.Errorf("no policy found in file %s", path)
+	case 1:
+		for _, p := range policies {
+			return p, nil
+		}
+	}
+	return nil, fmt.Errorf("file %s defines %d policies, use -policy-name to select one", path, len(policies))
+}
+
+// newPolicyProcessor returns a policy processor which applies the policy
+// defaults of the agent config.
+func newPolicyProcessor(cfg *config.Agent) *policy.Processor {
+	var nomadAPMs []string
+	for _, apm := range cfg.APMs {
+		if apm.Driver == plugins.InternalAPMNomad {
+			nomadAPMs = append(nomadAPMs, apm.Name)
+		}
+	}
+
+	return policy.NewProcessor(&policy.ConfigDefaults{
+		DefaultEvaluationInterval: cfg.Policy.DefaultEvaluationInterval,
+		DefaultCooldown:           cfg.Policy.DefaultCooldown,
+		DefaultQueryWindowOffset:  cfg.Policy.DefaultQueryWindowOffset,
+	}, nomadAPMs)
+}
+
+// simulationPluginsConfig returns the config of the APM and strategy plugins,
+// which are the only plugins used by the simulation.
+func simulationPluginsConfig(cfg *config.Agent) map[string][]*config.Plugin {
+	return pluginsConfig(cfg, map[string][]*config.Plugin{
+		sdk.PluginTypeAPM:      cfg.APMs,
+		sdk.PluginTypeStrategy: cfg.Strategies,
+	})
+}
+
+// pluginsConfig merges the Nomad config of the agent into the config of the
+// plugins, unless they opt out, as done by the agent.
+func pluginsConfig(cfg *config.Agent, result map[string][]*config.Plugin) map[string][]*config.Plugin {
+	nomadCfg := nomadHelper.MergeDefaultWithAgentConfig(cfg.Nomad)
+
+	for _, cfgs := range result {
+		for _, c := range cfgs {
+			if c.Config == nil {
+				c.Config = make(map[string]string)
+			}
+			if inherit, err := strconv.ParseBool(c.Config[plugins.ConfigKeyNomadConfigInherit]); err != nil || inherit {
+				nomadHelper.MergeMapWithAgentConfig(c.Config, nomadCfg)
+			}
+		}
+	}
+	return result
+}
+
+// writeSimulation writes the simulation steps in the given format.
+func writeSimulation(w io.Writer, p *sdk.ScalingPolicy, steps []policyeval.SimulationStep, format string) {
+	checks := make([]string, 0, len(p.Checks))
+	for _, c := range p.Checks {
+		checks = append(checks, c.Name)
+	}
+	sort.Strings(checks)
+
+	rows := [][]string{append(append([]string{"Time", "Count", "Direction", "Check"}, checks...), "Reason")}
+	for _, s := range steps {
+		row := []string{s.Time.Format(time.RFC3339), strconv.FormatInt(s.Count, 10), s.Direction.String(), s.Check}
+		for _, c := range checks {
+			if v, ok := s.Metrics[c]; ok {
+				row = append(row, strconv.FormatFloat(v, 'g', -1, 64))
+			} else {
+				row = append(row, "")
+			}
+		}
+		rows = append(rows, append(row, s.Reason))
+	}
+
+	if format == "csv" {
+		cw := csv.NewWriter(w)
+		_ = cw.WriteAll(rows)
+		return
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, row := range rows {
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+	_ = tw.Flush()
+}
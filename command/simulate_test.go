@@ -0,0 +1,66 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package command
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/nomad-autoscaler/policyeval"
+	"github.com/hashicorp/nomad-autoscaler/sdk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_parseSimulationTime(t *testing.T) {
+	now := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+
+	got, err := parseSimulationTime("2024-01-01T12:00:00Z", now)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC), got)
+
+	got, err = parseSimulationTime("24h", now)
+	require.NoError(t, err)
+	assert.Equal(t, now.Add(-24*time.Hour), got)
+
+	got, err = parseSimulationTime("-1h", now)
+	require.NoError(t, err)
+	assert.Equal(t, now.Add(-time.Hour), got)
+
+	_, err = parseSimulationTime("yesterday", now)
+	assert.EqualError(t, err, `"yesterday" is not an RFC3339 timestamp or a duration`)
+}
+
+func Test_writeSimulation(t *testing.T) {
+	p := &sdk.ScalingPolicy{
+		Checks: []*sdk.ScalingPolicyCheck{{Name: "mem"}, {Name: "cpu"}},
+	}
+	ts := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	steps := []policyeval.SimulationStep{
+		{
+			Time:      ts,
+			Count:     4,
+			Direction: sdk.ScaleDirectionUp,
+			Check:     "cpu",
+			Reason:    "scaling up, metric 100",
+			Metrics:   map[string]float64{"cpu": 100},
+		},
+		{
+			Time:      ts.Add(time.Minute),
+			Count:     4,
+			Direction: sdk.ScaleDirectionNone,
+			Reason:    "policy in cooldown",
+		},
+	}
+
+	var buf bytes.Buffer
+	writeSimulation(&buf, p, steps, "csv")
+
+	expected := `Time,Count,Direction,Check,cpu,mem,Reason
+2024-01-01T00:00:00Z,4,up,cpu,100,,"scaling up, metric 100"
+2024-01-01T00:01:00Z,4,none,,,,policy in cooldown
+`
+	assert.Equal(t, expected, buf.String())
+}
@@ -30,6 +30,7 @@ policy {
   dir                         = "./policy-dir-from-file"
   default_cooldown            = "12s"
   default_evaluation_interval = "50m"
+  default_query_window_offset = "2m"
 
   source "file" {
     enabled = false
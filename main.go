@@ -21,6 +21,15 @@ func main() {
 		"agent": func() (cli.Command, error) {
 			return &command.AgentCommand{}, nil
 		},
+		"config validate": func() (cli.Command, error) {
+			return &command.ConfigValidateCommand{}, nil
+		},
+		"policy validate": func() (cli.Command, error) {
+			return &command.PolicyValidateCommand{}, nil
+		},
+		"simulate": func() (cli.Command, error) {
+			return &command.SimulateCommand{}, nil
+		},
 		"version": func() (cli.Command, error) {
 			return &command.VersionCommand{Version: versionString}, nil
 		},
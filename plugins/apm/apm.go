@@ -4,6 +4,11 @@
 package apm
 
 import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
 	"github.com/hashicorp/nomad-autoscaler/plugins/base"
 	"github.com/hashicorp/nomad-autoscaler/sdk"
 )
@@ -25,3 +30,123 @@ type APM interface {
 	// to gather the metrics desired by the feature.
 	QueryMultiple(query string, timeRange sdk.TimeRange) ([]sdk.TimestampedMetrics, error)
 }
+
+// LabeledAPM is an optional interface which APM plugins can implement when
+// the remote APM is able to identify each series returned by a query using a
+// set of labels or dimensions.
+type LabeledAPM interface {
+
+	// QueryLabeled performs the same query as Query, but includes the labels
+	// which identify the returned series.
+	QueryLabeled(query string, timeRange sdk.TimeRange) (sdk.LabeledTimestampedMetrics, error)
+
+	// QueryMultipleLabeled performs the same query as QueryMultiple, but
+	// includes the labels which identify each returned series.
+	QueryMultipleLabeled(query string, timeRange sdk.TimeRange) ([]sdk.LabeledTimestampedMetrics, error)
+}
+
+// StreamingAPM is an optional interface which APM plugins can implement to
+// return the result of a query in multiple chunks. This avoids building and
+// transferring a single large result when querying long time ranges or high
+// resolution metrics.
+type StreamingAPM interface {
+
+	// QueryStream performs the same query as Query, calling send for each
+	// chunk of metrics as they become available. Chunks must be sent in
+	// chronological order. If send returns an error, the query must stop and
+	// return the error.
+	QueryStream(query string, timeRange sdk.TimeRange, send func(sdk.TimestampedMetrics) error) error
+}
+
+// LabeledStreamingAPM is an optional interface which APM plugins implementing
+// both LabeledAPM and StreamingAPM can implement to include the labels which
+// identify the series in a streamed query result.
+type LabeledStreamingAPM interface {
+
+	// QueryStreamLabeled performs the same query as QueryStream, but each
+	// chunk includes the labels which identify the series.
+	QueryStreamLabeled(query string, timeRange sdk.TimeRange, send func(sdk.LabeledTimestampedMetrics) error) error
+}
+
+// ContextAPM is an optional interface implemented by APM plugins whose calls
+// can be bound to a context, so that calls still in progress are cancelled
+// once the context is done.
+type ContextAPM interface {
+
+	// WithContext returns a copy of the APM whose calls are made using ctx.
+	WithContext(ctx context.Context) APM
+}
+
+// WithContext returns the APM with its calls bound to ctx if it implements
+// ContextAPM, otherwise the APM is returned unmodified.
+func WithContext(ctx context.Context, a APM) APM {
+	if c, ok := a.(ContextAPM); ok {
+		return c.WithContext(ctx)
+	}
+	return a
+}
+
+// QueryOptionsAPM is an optional interface implemented by APM plugins which
+// accept options tuning how the queries of a single check are performed, such
+// as their resolution or timeout. The options are passed with each query, so
+// checks can set them without a new plugin instance being configured.
+type QueryOptionsAPM interface {
+
+	// WithQueryOptions returns a copy of the APM whose queries are performed
+	// using the options. Options the plugin does not support are rejected.
+	WithQueryOptions(options map[string]string) (APM, error)
+}
+
+// WithQueryOptions returns the APM with its queries performed using the
+// options. If options are set, the APM must implement QueryOptionsAPM.
+func WithQueryOptions(a APM, options map[string]string) (APM, error) {
+	if len(options) == 0 {
+		return a, nil
+	}
+	if q, ok := a.(QueryOptionsAPM); ok {
+		return q.WithQueryOptions(options)
+	}
+	return nil, fmt.Errorf("plugin does not support query options")
+}
+
+// SingleLabeledSeries returns the only series of a query result, with empty
+// metrics if the query returned no series. If the query returned multiple
+// labeled series, the error lists their labels so the query can be narrowed
+// down.
+func SingleLabeledSeries(series []sdk.LabeledTimestampedMetrics) (sdk.LabeledTimestampedMetrics, error) {
+	switch len(series) {
+	case 0:
+		return sdk.LabeledTimestampedMetrics{Metrics: sdk.TimestampedMetrics{}}, nil
+	case 1:
+		return series[0], nil
+	}
+
+	err := fmt.Errorf("query returned %d metric streams, only 1 is expected", len(series))
+
+	ids := make([]string, len(series))
+	labeled := false
+	for i, s := range series {
+		ids[i] = formatLabels(s.Labels)
+		labeled = labeled || len(s.Labels) > 0
+	}
+	if labeled {
+		err = fmt.Errorf("%v: %s", err, strings.Join(ids, ", "))
+	}
+	return sdk.LabeledTimestampedMetrics{}, err
+}
+
+// formatLabels returns the labels sorted by name, using the Prometheus series
+// notation.
+func formatLabels(labels map[string]string) string {
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, len(keys))
+	for i, k := range keys {
+		pairs[i] = fmt.Sprintf("%s=%q", k, labels[k])
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
@@ -4,14 +4,23 @@
 package apm
 
 import (
+	"context"
+	"net"
 	"os/exec"
 	"testing"
 	"time"
 
 	"github.com/hashicorp/go-plugin"
+	"github.com/hashicorp/nomad-autoscaler/plugins/apm/proto/v1"
+	"github.com/hashicorp/nomad-autoscaler/plugins/base"
 	"github.com/hashicorp/nomad-autoscaler/sdk"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
 )
 
 // TODO(luiz): there's an import cycle, so let's copy it here for now.
@@ -111,3 +120,233 @@ func TestAPMPluginRPCServerQueryMultiple(t *testing.T) {
 	assert.Len(t, result, 1)
 	assert.Len(t, result[0], 10)
 }
+
+func TestAPMPluginRPCServerQueryMultipleLabeled(t *testing.T) {
+	client := plugin.NewClient(&plugin.ClientConfig{
+		HandshakeConfig:  handshake,
+		Plugins:          map[string]plugin.Plugin{"apm": &PluginAPM{}},
+		Cmd:              exec.Command("../test/bin/noop-apm"),
+		AllowedProtocols: []plugin.Protocol{plugin.ProtocolGRPC},
+	})
+	defer client.Kill()
+
+	rpcClient, err := client.Client()
+	require.NoError(t, err)
+
+	raw, err := rpcClient.Dispense("apm")
+	require.NoError(t, err)
+	apmImpl := raw.(LabeledAPM)
+
+	now := time.Now()
+	r := sdk.TimeRange{From: now.Add(-10 * time.Second), To: now}
+
+	result, err := apmImpl.QueryMultipleLabeled("fixed:5", r)
+	require.NoError(t, err)
+	assert.Len(t, result, 1)
+	assert.Len(t, result[0].Metrics, 10)
+	assert.Equal(t, map[string]string{"query": "fixed:5"}, result[0].Labels)
+}
+
+func TestAPMPluginRPCServerQueryLabeled(t *testing.T) {
+	client := plugin.NewClient(&plugin.ClientConfig{
+		HandshakeConfig:  handshake,
+		Plugins:          map[string]plugin.Plugin{"apm": &PluginAPM{}},
+		Cmd:              exec.Command("../test/bin/noop-apm"),
+		AllowedProtocols: []plugin.Protocol{plugin.ProtocolGRPC},
+	})
+	defer client.Kill()
+
+	rpcClient, err := client.Client()
+	require.NoError(t, err)
+
+	raw, err := rpcClient.Dispense("apm")
+	require.NoError(t, err)
+	apmImpl := raw.(LabeledAPM)
+
+	now := time.Now()
+	r := sdk.TimeRange{From: now.Add(-10 * time.Second), To: now}
+
+	result, err := apmImpl.QueryLabeled("fixed:5", r)
+	require.NoError(t, err)
+	assert.Len(t, result.Metrics, 10)
+	assert.Equal(t, map[string]string{"query": "fixed:5"}, result.Labels)
+}
+
+func TestSingleLabeledSeries(t *testing.T) {
+	testCases := []struct {
+		name           string
+		input          []sdk.LabeledTimestampedMetrics
+		expectedOutput sdk.LabeledTimestampedMetrics
+		expectedErr    string
+	}{
+		{
+			name:           "no series",
+			expectedOutput: sdk.LabeledTimestampedMetrics{Metrics: sdk.TimestampedMetrics{}},
+		},
+		{
+			name: "single series",
+			input: []sdk.LabeledTimestampedMetrics{
+				{Labels: map[string]string{"job": "web"}, Metrics: sdk.TimestampedMetrics{{Value: 1}}},
+			},
+			expectedOutput: sdk.LabeledTimestampedMetrics{
+				Labels:  map[string]string{"job": "web"},
+				Metrics: sdk.TimestampedMetrics{{Value: 1}},
+			},
+		},
+		{
+			name: "multiple labeled series",
+			input: []sdk.LabeledTimestampedMetrics{
+				{Labels: map[string]string{"job": "web", "instance": "a"}},
+				{Labels: map[string]string{"job": "web", "instance": "b"}},
+			},
+			expectedErr: `query returned 2 metric streams, only 1 is expected: {instance="a",job="web"}, {instance="b",job="web"}`,
+		},
+		{
+			name:        "multiple series without labels",
+			input:       []sdk.LabeledTimestampedMetrics{{}, {}},
+			expectedErr: "query returned 2 metric streams, only 1 is expected",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			output, err := SingleLabeledSeries(tc.input)
+			if tc.expectedErr != "" {
+				assert.EqualError(t, err, tc.expectedErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedOutput, output)
+		})
+	}
+}
+
+// blockingAPMServer is an APM gRPC server whose queries block until the RPC
+// is cancelled by the client.
+type blockingAPMServer struct {
+	proto.UnimplementedAPMPluginServiceServer
+	cancelledCh chan struct{}
+}
+
+func (s *blockingAPMServer) QueryStream(_ *proto.QueryRequest, stream proto.APMPluginService_QueryStreamServer) error {
+	<-stream.Context().Done()
+	close(s.cancelledCh)
+	return stream.Context().Err()
+}
+
+// testPluginClient returns a client connected to srv over an in-memory gRPC
+// connection.
+func testPluginClient(t *testing.T, srv proto.APMPluginServiceServer) *pluginClient {
+	lis := bufconn.Listen(1024 * 1024)
+
+	grpcServer := grpc.NewServer()
+	proto.RegisterAPMPluginServiceServer(grpcServer, srv)
+	go func() { _ = grpcServer.Serve(lis) }()
+	t.Cleanup(grpcServer.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return &pluginClient{
+		PluginClient: &base.PluginClient{DoneCtx: context.Background()},
+		client:       proto.NewAPMPluginServiceClient(conn),
+		doneCtx:      context.Background(),
+	}
+}
+
+func TestAPMPluginClientWithContext(t *testing.T) {
+	srv := &blockingAPMServer{cancelledCh: make(chan struct{})}
+
+	// The plugin context is never cancelled, so the query is only cancelled
+	// by the context bound to the client.
+	client := testPluginClient(t, srv)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	_, err := WithContext(ctx, client).Query("query", sdk.TimeRange{From: time.Now().Add(-time.Minute), To: time.Now()})
+	assert.Equal(t, codes.DeadlineExceeded, status.Code(err))
+
+	select {
+	case <-srv.cancelledCh:
+	case <-time.After(5 * time.Second):
+		t.Fatal("plugin query RPC was not cancelled")
+	}
+}
+
+// labeledAPM is an APM which returns a single labeled series.
+type labeledAPM struct {
+	APM
+	series sdk.LabeledTimestampedMetrics
+}
+
+func (a *labeledAPM) QueryLabeled(string, sdk.TimeRange) (sdk.LabeledTimestampedMetrics, error) {
+	return a.series, nil
+}
+
+func (a *labeledAPM) QueryMultipleLabeled(string, sdk.TimeRange) ([]sdk.LabeledTimestampedMetrics, error) {
+	return []sdk.LabeledTimestampedMetrics{a.series}, nil
+}
+
+// streamOnlyAPMServer fails unary queries, so the client must use the
+// QueryStream RPC.
+type streamOnlyAPMServer struct {
+	*pluginServer
+}
+
+func (s *streamOnlyAPMServer) Query(context.Context, *proto.QueryRequest) (*proto.QueryResponse, error) {
+	return nil, status.Error(codes.Internal, "unary query used")
+}
+
+// unaryOnlyAPMServer is a plugin built before QueryStream was supported.
+type unaryOnlyAPMServer struct {
+	*pluginServer
+}
+
+func (s *unaryOnlyAPMServer) QueryStream(*proto.QueryRequest, proto.APMPluginService_QueryStreamServer) error {
+	return status.Error(codes.Unimplemented, "method QueryStream not implemented")
+}
+
+func TestAPMPluginClientQueryLabeled(t *testing.T) {
+	metrics := make(sdk.TimestampedMetrics, queryStreamChunkSize*2+10)
+	for i := range metrics {
+		metrics[i] = sdk.TimestampedMetric{Timestamp: time.Unix(int64(i), 0), Value: float64(i)}
+	}
+	series := sdk.LabeledTimestampedMetrics{Labels: map[string]string{"job": "web"}, Metrics: metrics}
+
+	testCases := []struct {
+		name   string
+		server func(*pluginServer) proto.APMPluginServiceServer
+	}{
+		{
+			name: "stream",
+			server: func(p *pluginServer) proto.APMPluginServiceServer {
+				return &streamOnlyAPMServer{pluginServer: p}
+			},
+		},
+		{
+			name: "unary fallback",
+			server: func(p *pluginServer) proto.APMPluginServiceServer {
+				return &unaryOnlyAPMServer{pluginServer: p}
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			client := testPluginClient(t, tc.server(&pluginServer{impl: &labeledAPM{series: series}}))
+
+			result, err := client.QueryLabeled("query", sdk.TimeRange{From: time.Unix(0, 0), To: time.Now()})
+			require.NoError(t, err)
+			assert.Equal(t, series.Labels, result.Labels)
+			assert.Len(t, result.Metrics, len(metrics))
+			assert.Equal(t, metrics[len(metrics)-1].Value, result.Metrics[len(metrics)-1].Value)
+		})
+	}
+}
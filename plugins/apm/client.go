@@ -5,11 +5,14 @@ package apm
 
 import (
 	"context"
+	"io"
 
 	"github.com/hashicorp/nomad-autoscaler/plugins/apm/proto/v1"
 	"github.com/hashicorp/nomad-autoscaler/plugins/base"
 	"github.com/hashicorp/nomad-autoscaler/plugins/shared"
 	"github.com/hashicorp/nomad-autoscaler/sdk"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 // pluginClient is the gRPC client implementation of the APM interface.
@@ -17,41 +20,187 @@ type pluginClient struct {
 	*base.PluginClient
 	client  proto.APMPluginServiceClient
 	doneCtx context.Context
+
+	// options are the query options sent with each query.
+	options map[string]string
+}
+
+// WithContext is the gRPC client implementation of the
+// ContextAPM.WithContext interface function. The returned client makes its
+// RPCs using ctx, so they are cancelled once ctx is done.
+func (p *pluginClient) WithContext(ctx context.Context) APM {
+	base := *p.PluginClient
+	base.DoneCtx = ctx
+	return &pluginClient{PluginClient: &base, client: p.client, doneCtx: ctx, options: p.options}
+}
+
+// WithQueryOptions is the gRPC client implementation of the
+// QueryOptionsAPM.WithQueryOptions interface function. The options are sent
+// with each query of the returned client and validated by the plugin, so
+// unsupported options are only reported once a query is performed.
+func (p *pluginClient) WithQueryOptions(options map[string]string) (APM, error) {
+	c := *p
+	c.options = options
+	return &c, nil
 }
 
 // Query is the gRPC client implementation of the APM.Query interface function.
+// The query is performed using the QueryStream RPC so large results are
+// received in multiple chunks, falling back to the unary Query RPC when the
+// plugin was built before streaming was supported.
 func (p *pluginClient) Query(query string, timeRange sdk.TimeRange) (sdk.TimestampedMetrics, error) {
 
 	protoTS, err := shared.TimeRangeToProto(timeRange)
 	if err != nil {
 		return nil, err
 	}
+	req := &proto.QueryRequest{Query: query, TimeRange: protoTS, Options: p.options}
+
+	var out sdk.TimestampedMetrics
+	err = p.queryStream(req, func(chunk *proto.QueryResponse) error {
+		out = append(out, shared.ProtoToTimestampedMetrics(chunk.GetTimestampedMetric())...)
+		return nil
+	})
+	if status.Code(err) != codes.Unimplemented {
+		if err == nil && out == nil {
+			out = sdk.TimestampedMetrics{}
+		}
+		return out, err
+	}
 
-	metrics, err := p.client.Query(p.DoneCtx, &proto.QueryRequest{Query: query, TimeRange: protoTS})
+	metrics, err := p.client.Query(p.DoneCtx, req)
 	if err != nil {
 		return nil, err
 	}
 	return shared.ProtoToTimestampedMetrics(metrics.GetTimestampedMetric()), nil
 }
 
+// QueryLabeled is the gRPC client implementation of the
+// LabeledAPM.QueryLabeled interface function. Like Query, it uses the
+// QueryStream RPC, taking the labels of the series from the first chunk which
+// has them, and falls back to the unary Query RPC when the plugin was built
+// before streaming was supported. Plugins which do not implement LabeledAPM
+// return a series without labels.
+func (p *pluginClient) QueryLabeled(query string, timeRange sdk.TimeRange) (sdk.LabeledTimestampedMetrics, error) {
+
+	protoTS, err := shared.TimeRangeToProto(timeRange)
+	if err != nil {
+		return sdk.LabeledTimestampedMetrics{}, err
+	}
+	req := &proto.QueryRequest{Query: query, TimeRange: protoTS, Options: p.options}
+
+	out := sdk.LabeledTimestampedMetrics{Metrics: sdk.TimestampedMetrics{}}
+	err = p.queryStream(req, func(chunk *proto.QueryResponse) error {
+		if out.Labels == nil {
+			out.Labels = chunk.GetLabels()
+		}
+		out.Metrics = append(out.Metrics, shared.ProtoToTimestampedMetrics(chunk.GetTimestampedMetric())...)
+		return nil
+	})
+	if status.Code(err) != codes.Unimplemented {
+		if err != nil {
+			return sdk.LabeledTimestampedMetrics{}, err
+		}
+		return out, nil
+	}
+
+	metrics, err := p.client.Query(p.DoneCtx, req)
+	if err != nil {
+		return sdk.LabeledTimestampedMetrics{}, err
+	}
+
+	return sdk.LabeledTimestampedMetrics{
+		Labels:  metrics.GetLabels(),
+		Metrics: shared.ProtoToTimestampedMetrics(metrics.GetTimestampedMetric()),
+	}, nil
+}
+
+// QueryStream is the gRPC client implementation of the
+// StreamingAPM.QueryStream interface function.
+func (p *pluginClient) QueryStream(query string, timeRange sdk.TimeRange, send func(sdk.TimestampedMetrics) error) error {
+
+	protoTS, err := shared.TimeRangeToProto(timeRange)
+	if err != nil {
+		return err
+	}
+	req := &proto.QueryRequest{Query: query, TimeRange: protoTS, Options: p.options}
+	return p.queryStream(req, func(chunk *proto.QueryResponse) error {
+		return send(shared.ProtoToTimestampedMetrics(chunk.GetTimestampedMetric()))
+	})
+}
+
+// queryStream performs the QueryStream RPC, calling send for each chunk
+// received.
+func (p *pluginClient) queryStream(req *proto.QueryRequest, send func(*proto.QueryResponse) error) error {
+
+	ctx, cancel := context.WithCancel(p.DoneCtx)
+	defer cancel()
+
+	stream, err := p.client.QueryStream(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	for {
+		chunk, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := send(chunk); err != nil {
+			return err
+		}
+	}
+}
+
 // QueryMultiple is the gRPC client implementation of the APM.QueryMultiple
 // interface function.
 func (p *pluginClient) QueryMultiple(query string, timeRange sdk.TimeRange) ([]sdk.TimestampedMetrics, error) {
 
-	protoTS, err := shared.TimeRangeToProto(timeRange)
+	metrics, err := p.queryMultiple(query, timeRange)
 	if err != nil {
 		return nil, err
 	}
 
-	metrics, err := p.client.QueryMultiple(p.DoneCtx, &proto.QueryMultipleRequest{Query: query, TimeRange: protoTS})
+	out := make([]sdk.TimestampedMetrics, len(metrics.TimestampedMetric))
+
+	for i, m := range metrics.TimestampedMetric {
+		out[i] = shared.ProtoToTimestampedMetrics(m.GetTimestampedMetric())
+	}
+	return out, nil
+}
+
+// QueryMultipleLabeled is the gRPC client implementation of the
+// LabeledAPM.QueryMultipleLabeled interface function. Plugins which do not
+// implement LabeledAPM return series without labels.
+func (p *pluginClient) QueryMultipleLabeled(query string, timeRange sdk.TimeRange) ([]sdk.LabeledTimestampedMetrics, error) {
+
+	metrics, err := p.queryMultiple(query, timeRange)
 	if err != nil {
 		return nil, err
 	}
 
-	out := make([]sdk.TimestampedMetrics, len(metrics.TimestampedMetric))
+	out := make([]sdk.LabeledTimestampedMetrics, len(metrics.TimestampedMetric))
 
 	for i, m := range metrics.TimestampedMetric {
-		out[i] = shared.ProtoToTimestampedMetrics(m.GetTimestampedMetric())
+		out[i] = sdk.LabeledTimestampedMetrics{
+			Labels:  m.GetLabels(),
+			Metrics: shared.ProtoToTimestampedMetrics(m.GetTimestampedMetric()),
+		}
 	}
 	return out, nil
 }
+
+func (p *pluginClient) queryMultiple(query string, timeRange sdk.TimeRange) (*proto.QueryMultipleResponse, error) {
+
+	protoTS, err := shared.TimeRangeToProto(timeRange)
+	if err != nil {
+		return nil, err
+	}
+
+	req := &proto.QueryMultipleRequest{Query: query, TimeRange: protoTS, Options: p.options}
+	return p.client.QueryMultiple(p.DoneCtx, req)
+}
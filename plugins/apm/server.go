@@ -9,8 +9,13 @@ import (
 	plugin "github.com/hashicorp/go-plugin"
 	"github.com/hashicorp/nomad-autoscaler/plugins/apm/proto/v1"
 	"github.com/hashicorp/nomad-autoscaler/plugins/shared"
+	"github.com/hashicorp/nomad-autoscaler/sdk"
 )
 
+// queryStreamChunkSize is the maximum number of metrics sent in each message
+// of a query stream when the plugin does not implement StreamingAPM.
+const queryStreamChunkSize = 1000
+
 // pluginServer is the gRPC server implementation of the APM interface.
 type pluginServer struct {
 	broker *plugin.GRPCBroker
@@ -25,7 +30,26 @@ func (p *pluginServer) Query(_ context.Context, req *proto.QueryRequest) (*proto
 		return nil, err
 	}
 
-	res, err := p.impl.Query(req.GetQuery(), *tr)
+	impl, err := WithQueryOptions(p.impl, req.GetOptions())
+	if err != nil {
+		return nil, err
+	}
+
+	// Include the labels of the series when the plugin is able to identify
+	// it, so label aware callers can use them.
+	if labeled, ok := impl.(LabeledAPM); ok {
+		res, err := labeled.QueryLabeled(req.GetQuery(), *tr)
+		if err != nil {
+			return nil, err
+		}
+
+		return &proto.QueryResponse{
+			TimestampedMetric: shared.TimestampedMetricsToProto(res.Metrics),
+			Labels:            res.Labels,
+		}, nil
+	}
+
+	res, err := impl.Query(req.GetQuery(), *tr)
 	if err != nil {
 		return nil, err
 	}
@@ -44,7 +68,34 @@ func (p *pluginServer) QueryMultiple(_ context.Context, req *proto.QueryMultiple
 		return nil, err
 	}
 
-	res, err := p.impl.QueryMultiple(req.GetQuery(), *tr)
+	impl, err := WithQueryOptions(p.impl, req.GetOptions())
+	if err != nil {
+		return nil, err
+	}
+
+	// If the plugin is able to identify the series it returns, include the
+	// labels in the response so label aware callers can use them.
+	if labeled, ok := impl.(LabeledAPM); ok {
+		res, err := labeled.QueryMultipleLabeled(req.GetQuery(), *tr)
+		if err != nil {
+			return nil, err
+		}
+
+		out := make([]*proto.QueryResponse, len(res))
+
+		for i, m := range res {
+			out[i] = &proto.QueryResponse{
+				TimestampedMetric: shared.TimestampedMetricsToProto(m.Metrics),
+				Labels:            m.Labels,
+			}
+		}
+
+		return &proto.QueryMultipleResponse{
+			TimestampedMetric: out,
+		}, nil
+	}
+
+	res, err := impl.QueryMultiple(req.GetQuery(), *tr)
 	if err != nil {
 		return nil, err
 	}
@@ -59,3 +110,59 @@ func (p *pluginServer) QueryMultiple(_ context.Context, req *proto.QueryMultiple
 		TimestampedMetric: out,
 	}, nil
 }
+
+// QueryStream is the gRPC server implementation of the APM.QueryStream
+// interface function. Plugins which do not implement StreamingAPM have the
+// result of Query, or QueryLabeled when available, split into chunks. The
+// labels of the series are only sent in the first chunk which has them, since
+// they are the same for every chunk of the stream.
+func (p *pluginServer) QueryStream(req *proto.QueryRequest, stream proto.APMPluginService_QueryStreamServer) error {
+
+	tr, err := shared.ProtoToTimeRange(req.GetTimeRange())
+	if err != nil {
+		return err
+	}
+
+	impl, err := WithQueryOptions(p.impl, req.GetOptions())
+	if err != nil {
+		return err
+	}
+
+	labelsSent := false
+	send := func(m sdk.LabeledTimestampedMetrics) error {
+		resp := &proto.QueryResponse{TimestampedMetric: shared.TimestampedMetricsToProto(m.Metrics)}
+		if !labelsSent && len(m.Labels) > 0 {
+			resp.Labels = m.Labels
+			labelsSent = true
+		}
+		return stream.Send(resp)
+	}
+
+	switch streaming := impl.(type) {
+	case LabeledStreamingAPM:
+		return streaming.QueryStreamLabeled(req.GetQuery(), *tr, send)
+	case StreamingAPM:
+		return streaming.QueryStream(req.GetQuery(), *tr, func(m sdk.TimestampedMetrics) error {
+			return send(sdk.LabeledTimestampedMetrics{Metrics: m})
+		})
+	}
+
+	var res sdk.LabeledTimestampedMetrics
+	if labeled, ok := impl.(LabeledAPM); ok {
+		res, err = labeled.QueryLabeled(req.GetQuery(), *tr)
+	} else {
+		res.Metrics, err = impl.Query(req.GetQuery(), *tr)
+	}
+	if err != nil {
+		return err
+	}
+
+	for len(res.Metrics) > queryStreamChunkSize {
+		chunk := sdk.LabeledTimestampedMetrics{Labels: res.Labels, Metrics: res.Metrics[:queryStreamChunkSize]}
+		if err := send(chunk); err != nil {
+			return err
+		}
+		res.Metrics = res.Metrics[queryStreamChunkSize:]
+	}
+	return send(res)
+}
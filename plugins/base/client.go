@@ -35,6 +35,8 @@ func (p *PluginClient) PluginInfo() (*PluginInfo, error) {
 		pType = sdk.PluginTypeStrategy
 	case proto.PluginType_PLUGIN_TYPE_TARGET:
 		pType = sdk.PluginTypeTarget
+	case proto.PluginType_PLUGIN_TYPE_EVENT_SINK:
+		pType = sdk.PluginTypeEventSink
 	default:
 		return nil, fmt.Errorf("plugin is of unknown type: %q", info.GetType().String())
 	}
@@ -34,6 +34,8 @@ func (p *pluginServer) PluginInfo(_ context.Context, _ *proto.PluginInfoRequest)
 		pType = proto.PluginType_PLUGIN_TYPE_STRATEGY
 	case sdk.PluginTypeTarget:
 		pType = proto.PluginType_PLUGIN_TYPE_TARGET
+	case sdk.PluginTypeEventSink:
+		pType = proto.PluginType_PLUGIN_TYPE_EVENT_SINK
 	default:
 		return nil, fmt.Errorf("plugin is of unknown type: %q", info.PluginType)
 	}
@@ -0,0 +1,194 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package plugin
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	hclog "github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/nomad-autoscaler/plugins"
+	"github.com/hashicorp/nomad-autoscaler/plugins/apm"
+	"github.com/hashicorp/nomad-autoscaler/plugins/base"
+	"github.com/hashicorp/nomad-autoscaler/sdk"
+	"github.com/hashicorp/nomad-autoscaler/sdk/helper/pluginconfig"
+)
+
+const (
+	// pluginName is the unique name of the this plugin amongst APM plugins.
+	pluginName = "consul"
+
+	// configKeys represents the known configuration parameters required at
+	// varying points throughout the plugins lifecycle.
+	configKeyAddress    = "address"
+	configKeyToken      = "token"
+	configKeyDatacenter = "datacenter"
+	configKeyTimeout    = "timeout"
+
+	// configValues are the default values used when a configuration key is not
+	// supplied by the operator that are specific to the plugin.
+	configValueAddressDefault = "http://127.0.0.1:8500"
+	configValueTimeoutDefault = "10s"
+)
+
+var (
+	PluginID = plugins.PluginID{
+		Name:       pluginName,
+		PluginType: sdk.PluginTypeAPM,
+	}
+
+	PluginConfig = &plugins.InternalPluginConfig{
+		Factory: func(l hclog.Logger) interface{} { return NewConsulPlugin(l) },
+	}
+
+	pluginInfo = &base.PluginInfo{
+		Name:       pluginName,
+		PluginType: sdk.PluginTypeAPM,
+	}
+)
+
+// Assert that APMPlugin meets the apm.APM interface.
+var _ apm.APM = (*APMPlugin)(nil)
+
+// APMPlugin is the Consul implementation of the apm.APM interface. Metrics
+// are read from the Consul health and agent metrics HTTP endpoints, so the
+// number of healthy service instances and the telemetry of the Consul agent,
+// such as the connections of the built-in service mesh proxy, can be used as
+// scaling inputs.
+type APMPlugin struct {
+	config map[string]string
+	logger hclog.Logger
+
+	client     *http.Client
+	address    string
+	token      string
+	datacenter string
+}
+
+// NewConsulPlugin returns the Consul implementation of the apm.APM interface.
+func NewConsulPlugin(log hclog.Logger) apm.APM {
+	return &APMPlugin{
+		logger: log,
+	}
+}
+
+// SetConfig satisfies the SetConfig function on the base.Base interface.
+func (a *APMPlugin) SetConfig(config map[string]string) error {
+
+	addr := pluginconfig.GetValue(config, configKeyAddress, configValueAddressDefault)
+	if _, err := url.ParseRequestURI(addr); err != nil {
+		return fmt.Errorf("failed to parse `%s`: %v", configKeyAddress, err)
+	}
+
+	timeout, err := time.ParseDuration(pluginconfig.GetValue(config, configKeyTimeout, configValueTimeoutDefault))
+	if err != nil {
+		return fmt.Errorf("failed to parse `%s`: %v", configKeyTimeout, err)
+	}
+
+	a.config = config
+	a.client = &http.Client{Timeout: timeout}
+	a.address = strings.TrimSuffix(addr, "/")
+	a.token = config[configKeyToken]
+	a.datacenter = config[configKeyDatacenter]
+
+	return nil
+}
+
+// PluginInfo satisfies the PluginInfo function on the base.Base interface.
+func (a *APMPlugin) PluginInfo() (*base.PluginInfo, error) {
+	return pluginInfo, nil
+}
+
+// Query satisfies the Query function on the apm.APM interface. Consul only
+// exposes the current value of the metrics, so a single data point is
+// returned with the end of the time range as its timestamp.
+func (a *APMPlugin) Query(q string, r sdk.TimeRange) (sdk.TimestampedMetrics, error) {
+	if a.client == nil {
+		return nil, fmt.Errorf("plugin is not configured")
+	}
+
+	query, err := parseQuery(q)
+	if err != nil {
+		return nil, err
+	}
+
+	var value float64
+
+	switch query.kind {
+	case queryKindHealth:
+		var entries []*healthEntry
+		if err := a.get(query.path(a.datacenter), &entries); err != nil {
+			return nil, err
+		}
+		value = countInstances(entries, query.status)
+
+	case queryKindMetrics:
+		var summary metricsSummary
+		if err := a.get(query.path(a.datacenter), &summary); err != nil {
+			return nil, err
+		}
+
+		var found bool
+		value, found, err = summary.value(query)
+		if err != nil {
+			return nil, err
+		}
+		if !found {
+			a.logger.Warn("metric not found in Consul agent metrics", "query", q)
+			return nil, nil
+		}
+	}
+
+	return sdk.TimestampedMetrics{{Timestamp: r.To, Value: value}}, nil
+}
+
+// QueryMultiple satisfies the QueryMultiple function on the apm.APM
+// interface. Queries always aggregate to a single value, so a single series
+// is returned.
+func (a *APMPlugin) QueryMultiple(q string, r sdk.TimeRange) ([]sdk.TimestampedMetrics, error) {
+	m, err := a.Query(q, r)
+	if err != nil {
+		return nil, err
+	}
+	return []sdk.TimestampedMetrics{m}, nil
+}
+
+// get performs a GET request against the Consul HTTP API and decodes the
+// JSON response into out.
+func (a *APMPlugin) get(path string, out interface{}) error {
+	req, err := http.NewRequest(http.MethodGet, a.address+path, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if a.token != "" {
+		req.Header.Set("X-Consul-Token", a.token)
+	}
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to query Consul: %v", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %v", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to query Consul: unexpected response code %d: %s",
+			resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("failed to parse response: %v", err)
+	}
+	return nil
+}
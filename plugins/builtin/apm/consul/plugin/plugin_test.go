@@ -0,0 +1,249 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package plugin
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	hclog "github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/nomad-autoscaler/sdk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPMPlugin_SetConfig(t *testing.T) {
+	testCases := []struct {
+		name          string
+		config        map[string]string
+		expectedError string
+	}{
+		{
+			name:   "defaults",
+			config: map[string]string{},
+		},
+		{
+			name:          "invalid address",
+			config:        map[string]string{"address": "not a url"},
+			expectedError: "failed to parse `address`",
+		},
+		{
+			name:          "invalid timeout",
+			config:        map[string]string{"timeout": "soon"},
+			expectedError: "failed to parse `timeout`",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			p := NewConsulPlugin(hclog.NewNullLogger())
+			err := p.SetConfig(tc.config)
+			if tc.expectedError != "" {
+				assert.ErrorContains(t, err, tc.expectedError)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func Test_parseQuery(t *testing.T) {
+	testCases := []struct {
+		name          string
+		query         string
+		expected      *query
+		expectedError string
+	}{
+		{
+			name:     "health passing",
+			query:    "health/web/passing",
+			expected: &query{kind: "health", service: "web", status: "passing"},
+		},
+		{
+			name:     "metrics gauge",
+			query:    "metrics/consul.proxy.web.inbound.conns",
+			expected: &query{kind: "metrics", name: "consul.proxy.web.inbound.conns"},
+		},
+		{
+			name:  "metrics with field and labels",
+			query: "metrics/consul.rpc.request/rate?datacenter=dc1",
+			expected: &query{
+				kind:   "metrics",
+				name:   "consul.rpc.request",
+				field:  "rate",
+				labels: map[string]string{"datacenter": "dc1"},
+			},
+		},
+		{
+			name:          "unsupported type",
+			query:         "kv/web/passing",
+			expectedError: `unsupported type "kv"`,
+		},
+		{
+			name:          "missing status",
+			query:         "health/web",
+			expectedError: "expected format health/<service>/<status>",
+		},
+		{
+			name:          "unsupported status",
+			query:         "health/web/healthy",
+			expectedError: `unsupported status "healthy"`,
+		},
+		{
+			name:          "unsupported field",
+			query:         "metrics/consul.rpc.request/p99",
+			expectedError: `unsupported field "p99"`,
+		},
+		{
+			name:          "repeated label",
+			query:         "metrics/consul.rpc.request?dc=a&dc=b",
+			expectedError: `label "dc" must have a single value`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual, err := parseQuery(tc.query)
+			if tc.expectedError != "" {
+				assert.ErrorContains(t, err, tc.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+}
+
+func TestAPMPlugin_Query(t *testing.T) {
+	to := time.Unix(1600000000, 0)
+	r := sdk.TimeRange{From: to.Add(-time.Minute), To: to}
+
+	healthResp := `[
+  {"Checks": [{"Status": "passing"}, {"Status": "passing"}]},
+  {"Checks": [{"Status": "passing"}, {"Status": "warning"}]},
+  {"Checks": [{"Status": "warning"}, {"Status": "critical"}]},
+  {"Checks": [{"Status": "passing"}]}
+]`
+
+	metricsResp := `{
+  "Gauges": [
+    {"Name": "consul.proxy.web.inbound.conns", "Value": 12, "Labels": {"dst": "a"}},
+    {"Name": "consul.proxy.web.inbound.conns", "Value": 8, "Labels": {"dst": "b"}}
+  ],
+  "Counters": [
+    {"Name": "consul.rpc.request", "Count": 10, "Rate": 1, "Sum": 10, "Min": 1, "Max": 1, "Mean": 1, "Labels": {}}
+  ],
+  "Samples": [
+    {"Name": "consul.http.GET.v1.health.service._", "Count": 2, "Rate": 0.2, "Sum": 30, "Min": 10, "Max": 20, "Mean": 15, "Labels": {"dc": "dc1"}},
+    {"Name": "consul.http.GET.v1.health.service._", "Count": 1, "Rate": 0.1, "Sum": 60, "Min": 60, "Max": 60, "Mean": 60, "Labels": {"dc": "dc2"}}
+  ]
+}`
+
+	testCases := []struct {
+		name           string
+		query          string
+		expectedResult sdk.TimestampedMetrics
+		expectedError  string
+	}{
+		{
+			name:           "passing instances",
+			query:          "health/web/passing",
+			expectedResult: sdk.TimestampedMetrics{{Timestamp: to, Value: 2}},
+		},
+		{
+			name:           "warning instances",
+			query:          "health/web/warning",
+			expectedResult: sdk.TimestampedMetrics{{Timestamp: to, Value: 1}},
+		},
+		{
+			name:           "all instances",
+			query:          "health/web/any",
+			expectedResult: sdk.TimestampedMetrics{{Timestamp: to, Value: 4}},
+		},
+		{
+			name:          "service error",
+			query:         "health/missing/passing",
+			expectedError: "unexpected response code 404",
+		},
+		{
+			name:           "gauge series are summed",
+			query:          "metrics/consul.proxy.web.inbound.conns",
+			expectedResult: sdk.TimestampedMetrics{{Timestamp: to, Value: 20}},
+		},
+		{
+			name:           "gauge with labels",
+			query:          "metrics/consul.proxy.web.inbound.conns?dst=b",
+			expectedResult: sdk.TimestampedMetrics{{Timestamp: to, Value: 8}},
+		},
+		{
+			name:           "counter defaults to count",
+			query:          "metrics/consul.rpc.request",
+			expectedResult: sdk.TimestampedMetrics{{Timestamp: to, Value: 10}},
+		},
+		{
+			name:           "sample mean of all series",
+			query:          "metrics/consul.http.GET.v1.health.service._",
+			expectedResult: sdk.TimestampedMetrics{{Timestamp: to, Value: 30}},
+		},
+		{
+			name:           "sample max",
+			query:          "metrics/consul.http.GET.v1.health.service._/max",
+			expectedResult: sdk.TimestampedMetrics{{Timestamp: to, Value: 60}},
+		},
+		{
+			name:           "sample min with labels",
+			query:          "metrics/consul.http.GET.v1.health.service._/min?dc=dc1",
+			expectedResult: sdk.TimestampedMetrics{{Timestamp: to, Value: 10}},
+		},
+		{
+			name:          "invalid field for gauge",
+			query:         "metrics/consul.proxy.web.inbound.conns/mean",
+			expectedError: "is a gauge",
+		},
+		{
+			name:           "metric not found",
+			query:          "metrics/consul.missing",
+			expectedResult: nil,
+		},
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Consul-Token") != "secret" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		switch r.URL.Path {
+		case "/v1/health/service/web":
+			assert.Equal(t, "dc1", r.URL.Query().Get("dc"))
+			_, _ = w.Write([]byte(healthResp))
+		case "/v1/agent/metrics":
+			_, _ = w.Write([]byte(metricsResp))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	p := NewConsulPlugin(hclog.NewNullLogger())
+	require.NoError(t, p.SetConfig(map[string]string{
+		"address":    server.URL,
+		"token":      "secret",
+		"datacenter": "dc1",
+	}))
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := p.Query(tc.query, r)
+			if tc.expectedError != "" {
+				assert.ErrorContains(t, err, tc.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedResult, result)
+		})
+	}
+}
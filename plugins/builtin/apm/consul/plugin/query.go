@@ -0,0 +1,292 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package plugin
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+const (
+	// The types of query supported by the plugin.
+	queryKindHealth  = "health"
+	queryKindMetrics = "metrics"
+
+	// The instance statuses that can be counted by health queries. Consul
+	// reports the worst status of the checks of an instance, so an instance
+	// is only passing when all of its checks are passing.
+	statusPassing  = "passing"
+	statusWarning  = "warning"
+	statusCritical = "critical"
+	statusAny      = "any"
+
+	// The fields of the agent metrics that can be queried. Gauges only have
+	// a value, while counters and samples are aggregated over the agent
+	// telemetry interval.
+	fieldValue = "value"
+	fieldCount = "count"
+	fieldRate  = "rate"
+	fieldSum   = "sum"
+	fieldMin   = "min"
+	fieldMax   = "max"
+	fieldMean  = "mean"
+)
+
+// query is the parsed representation of a Consul APM query. Queries use the
+// format health/<service>/<status> or metrics/<name>[/<field>][?<label>=<value>].
+type query struct {
+	kind string
+
+	// service and status are set for health queries.
+	service string
+	status  string
+
+	// name, field and labels are set for metrics queries.
+	name   string
+	field  string
+	labels map[string]string
+}
+
+// path returns the Consul HTTP API path used to run the query.
+func (q *query) path(datacenter string) string {
+	if q.kind == queryKindMetrics {
+		return "/v1/agent/metrics"
+	}
+
+	p := "/v1/health/service/" + url.PathEscape(q.service)
+	if datacenter != "" {
+		p += "?dc=" + url.QueryEscape(datacenter)
+	}
+	return p
+}
+
+// parseQuery parses and validates the input query.
+func parseQuery(q string) (*query, error) {
+	kind, rest, ok := strings.Cut(q, "/")
+	if !ok || rest == "" {
+		return nil, fmt.Errorf("invalid query %q, expected format %s/<service>/<status> or %s/<name>[/<field>]",
+			q, queryKindHealth, queryKindMetrics)
+	}
+
+	switch kind {
+	case queryKindHealth:
+		return parseHealthQuery(q, rest)
+	case queryKindMetrics:
+		return parseMetricsQuery(q, rest)
+	default:
+		return nil, fmt.Errorf("invalid query %q, unsupported type %q, must be %q or %q",
+			q, kind, queryKindHealth, queryKindMetrics)
+	}
+}
+
+func parseHealthQuery(q, rest string) (*query, error) {
+	service, status, ok := strings.Cut(rest, "/")
+	if !ok || service == "" {
+		return nil, fmt.Errorf("invalid query %q, expected format %s/<service>/<status>", q, queryKindHealth)
+	}
+
+	switch status {
+	case statusPassing, statusWarning, statusCritical, statusAny:
+	default:
+		return nil, fmt.Errorf("invalid query %q, unsupported status %q, must be one of %s, %s, %s or %s",
+			q, status, statusPassing, statusWarning, statusCritical, statusAny)
+	}
+
+	return &query{kind: queryKindHealth, service: service, status: status}, nil
+}
+
+func parseMetricsQuery(q, rest string) (*query, error) {
+	rest, rawLabels, _ := strings.Cut(rest, "?")
+
+	name, field, _ := strings.Cut(rest, "/")
+	if name == "" {
+		return nil, fmt.Errorf("invalid query %q, missing metric name", q)
+	}
+
+	switch field {
+	case "", fieldValue, fieldCount, fieldRate, fieldSum, fieldMin, fieldMax, fieldMean:
+	default:
+		return nil, fmt.Errorf("invalid query %q, unsupported field %q", q, field)
+	}
+
+	out := &query{kind: queryKindMetrics, name: name, field: field}
+
+	if rawLabels != "" {
+		values, err := url.ParseQuery(rawLabels)
+		if err != nil {
+			return nil, fmt.Errorf("invalid query %q, failed to parse labels: %v", q, err)
+		}
+
+		out.labels = make(map[string]string, len(values))
+		for k, v := range values {
+			if len(v) != 1 {
+				return nil, fmt.Errorf("invalid query %q, label %q must have a single value", q, k)
+			}
+			out.labels[k] = v[0]
+		}
+	}
+
+	return out, nil
+}
+
+// healthEntry holds the fields of the Consul health service response used by
+// the plugin.
+type healthEntry struct {
+	Checks []struct {
+		Status string
+	}
+}
+
+// status returns the aggregated status of the checks of the instance.
+func (e *healthEntry) status() string {
+	out := statusPassing
+	for _, c := range e.Checks {
+		switch c.Status {
+		case statusCritical:
+			return statusCritical
+		case statusPassing:
+		default:
+			out = statusWarning
+		}
+	}
+	return out
+}
+
+// countInstances returns the number of service instances with the status.
+func countInstances(entries []*healthEntry, status string) float64 {
+	var count float64
+	for _, e := range entries {
+		if status == statusAny || e.status() == status {
+			count++
+		}
+	}
+	return count
+}
+
+// metricsSummary holds the fields of the Consul agent metrics response used
+// by the plugin.
+type metricsSummary struct {
+	Gauges   []gaugeValue
+	Counters []sampledValue
+	Samples  []sampledValue
+}
+
+type gaugeValue struct {
+	Name   string
+	Value  float64
+	Labels map[string]string
+}
+
+type sampledValue struct {
+	Name   string
+	Count  float64
+	Rate   float64
+	Sum    float64
+	Min    float64
+	Max    float64
+	Mean   float64
+	Labels map[string]string
+}
+
+// value returns the value of the metric queried. The values of all the series
+// which match the query labels are aggregated. The boolean return indicates
+// whether any series matched.
+func (s *metricsSummary) value(q *query) (float64, bool, error) {
+	var gauges []gaugeValue
+	for _, g := range s.Gauges {
+		if g.Name == q.name && matchLabels(g.Labels, q.labels) {
+			gauges = append(gauges, g)
+		}
+	}
+
+	if len(gauges) > 0 {
+		if q.field != "" && q.field != fieldValue {
+			return 0, false, fmt.Errorf("metric %q is a gauge, field %q is not supported", q.name, q.field)
+		}
+
+		var sum float64
+		for _, g := range gauges {
+			sum += g.Value
+		}
+		return sum, true, nil
+	}
+
+	field := q.field
+	var series []sampledValue
+
+	for _, c := range s.Counters {
+		if c.Name == q.name && matchLabels(c.Labels, q.labels) {
+			series = append(series, c)
+		}
+	}
+	if len(series) > 0 && field == "" {
+		field = fieldCount
+	}
+
+	if len(series) == 0 {
+		for _, smp := range s.Samples {
+			if smp.Name == q.name && matchLabels(smp.Labels, q.labels) {
+				series = append(series, smp)
+			}
+		}
+		if field == "" {
+			field = fieldMean
+		}
+	}
+
+	if len(series) == 0 {
+		return 0, false, nil
+	}
+	if field == fieldValue {
+		return 0, false, fmt.Errorf("metric %q is not a gauge, field %q is not supported", q.name, field)
+	}
+
+	return aggregateSampled(series, field), true, nil
+}
+
+// aggregateSampled returns the field value of the combined series.
+func aggregateSampled(series []sampledValue, field string) float64 {
+	var out, count, sum float64
+
+	for i, v := range series {
+		count += v.Count
+		sum += v.Sum
+
+		switch field {
+		case fieldCount:
+			out += v.Count
+		case fieldRate:
+			out += v.Rate
+		case fieldSum:
+			out += v.Sum
+		case fieldMin:
+			if i == 0 || v.Min < out {
+				out = v.Min
+			}
+		case fieldMax:
+			if i == 0 || v.Max > out {
+				out = v.Max
+			}
+		}
+	}
+
+	if field == fieldMean {
+		if count == 0 {
+			return 0
+		}
+		return sum / count
+	}
+	return out
+}
+
+// matchLabels returns true if the series has all the expected labels.
+func matchLabels(labels, expected map[string]string) bool {
+	for k, v := range expected {
+		if labels[k] != v {
+			return false
+		}
+	}
+	return true
+}
@@ -7,7 +7,9 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"net/url"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/DataDog/datadog-api-client-go/api/v1/datadog"
@@ -22,9 +24,22 @@ const (
 	// pluginName is the name of the plugin
 	pluginName = "datadog"
 
-	// configKeySite is used to change the Datadog site
+	// configKeySite is used to change the Datadog site. It accepts the site
+	// domain, such as datadoghq.eu, or its short name, such as eu or us3.
 	configKeySite = "site"
 
+	// configKeySubdomain is the subdomain of the site which serves the API.
+	configKeySubdomain = "subdomain"
+
+	// configKeyAPIHost overrides the site and subdomain with a custom API
+	// address, such as a local relay. The scheme defaults to https.
+	configKeyAPIHost = "api_host"
+
+	// configKeyProxyURL is the URL of the HTTP(S) proxy used to reach the
+	// Datadog API. If not set, the proxy is read from the HTTP_PROXY,
+	// HTTPS_PROXY and NO_PROXY environment variables.
+	configKeyProxyURL = "proxy_url"
+
 	configKeyClientAPIKey = "dd_api_key"
 	configKeyClientAPPKey = "dd_app_key"
 
@@ -36,6 +51,24 @@ const (
 	datadogAuthAPPKey = "appKeyAuth"
 
 	ratelimitResetHdr = "X-Ratelimit-Reset"
+
+	// defaultSite and defaultSubdomain are the default location of the
+	// Datadog API.
+	defaultSite      = "datadoghq.com"
+	defaultSubdomain = "api"
+
+	// datadogServerIndexCustom is the index of the Datadog client server
+	// configuration which uses the protocol and full host name variables.
+	// It's used for every non-default site so sites that are not known by
+	// the client version can be used.
+	datadogServerIndexCustom = 1
+
+	// configKeyStreamWindow is the size of the time windows used to split
+	// streamed queries. Datadog reduces the resolution of the returned
+	// points as the queried range grows, so smaller windows return more
+	// detailed metrics at the cost of more API requests, which are rate
+	// limited. If not set, the range is queried at once.
+	configKeyStreamWindow = "stream_window"
 )
 
 var (
@@ -54,12 +87,33 @@ var (
 	}
 )
 
+// datadogSites maps the short names of the Datadog sites to their domains.
+var datadogSites = map[string]string{
+	"us1":     "datadoghq.com",
+	"us3":     "us3.datadoghq.com",
+	"us5":     "us5.datadoghq.com",
+	"eu":      "datadoghq.eu",
+	"eu1":     "datadoghq.eu",
+	"ap1":     "ap1.datadoghq.com",
+	"gov":     "ddog-gov.com",
+	"us1-fed": "ddog-gov.com",
+}
+
+var (
+	_ apm.LabeledAPM   = (*APMPlugin)(nil)
+	_ apm.StreamingAPM = (*APMPlugin)(nil)
+)
+
 type APMPlugin struct {
 	client    *datadog.APIClient
 	clientCtx context.Context
 	config    map[string]string
 	logger    hclog.Logger
 
+	// streamWindow is the size of the time windows used by QueryStream. A
+	// zero value indicates the range should not be split.
+	streamWindow time.Duration
+
 	// ddConfigCallback is used to customize the Datadog client for testing.
 	ddConfigCallback func(*datadog.Configuration)
 }
@@ -98,20 +152,43 @@ func (a *APMPlugin) SetConfig(config map[string]string) error {
 		},
 	)
 
-	// set Datadog site if provided
-	if a.config[configKeySite] != "" {
-		ctx = context.WithValue(ctx,
-			datadog.ContextServerVariables,
-			map[string]string{
-				"site": a.config[configKeySite],
-			})
+	// set the Datadog API location if provided
+	serverVars, err := serverVariables(a.config)
+	if err != nil {
+		return err
+	}
+	if serverVars != nil {
+		ctx = context.WithValue(ctx, datadog.ContextServerIndex, datadogServerIndexCustom)
+		ctx = context.WithValue(ctx, datadog.ContextServerVariables, serverVars)
 	}
 
-	a.clientCtx = ctx
+	a.streamWindow = 0
+	if w := a.config[configKeyStreamWindow]; w != "" {
+		window, err := time.ParseDuration(w)
+		if err != nil {
+			return fmt.Errorf("failed to parse %s value %s: %v", configKeyStreamWindow, w, err)
+		}
+		if window < time.Second {
+			return fmt.Errorf("%q config value must be at least 1s", configKeyStreamWindow)
+		}
+		a.streamWindow = window
+	}
 
 	// configure the Datadog API client.
 	// Call the ddConfigCallback if provided to setup test harness.
 	configuration := datadog.NewConfiguration()
+
+	if p := a.config[configKeyProxyURL]; p != "" {
+		proxyURL, err := url.Parse(p)
+		if err != nil || proxyURL.Host == "" {
+			return fmt.Errorf("failed to parse %s value %s: must be a valid URL", configKeyProxyURL, p)
+		}
+
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.Proxy = http.ProxyURL(proxyURL)
+		configuration.HTTPClient = &http.Client{Transport: transport}
+	}
+
 	if a.ddConfigCallback != nil {
 		a.ddConfigCallback(configuration)
 	}
@@ -119,35 +196,143 @@ func (a *APMPlugin) SetConfig(config map[string]string) error {
 	// store config and client in plugin instance
 	client := datadog.NewAPIClient(configuration)
 	a.client = client
+	a.clientCtx = ctx
 
 	return nil
 }
 
+// serverVariables returns the Datadog client server variables of the custom
+// server configuration, which uses the protocol and full host name of the API.
+// Nil is returned if the default API location should be used.
+func serverVariables(config map[string]string) (map[string]string, error) {
+	if host := config[configKeyAPIHost]; host != "" {
+		if !strings.Contains(host, "://") {
+			host = "https://" + host
+		}
+
+		u, err := url.Parse(host)
+		if err != nil || u.Host == "" || strings.Trim(u.Path, "/") != "" {
+			return nil, fmt.Errorf("failed to parse %s value %s: must be a host name with optional scheme and port",
+				configKeyAPIHost, config[configKeyAPIHost])
+		}
+		return map[string]string{"protocol": u.Scheme, "name": u.Host}, nil
+	}
+
+	site, subdomain := config[configKeySite], config[configKeySubdomain]
+	if site == "" && subdomain == "" {
+		return nil, nil
+	}
+
+	// Accept the site as copied from the Datadog UI address.
+	site = strings.TrimSuffix(strings.TrimPrefix(strings.TrimPrefix(site, "https://"), "app."), "/")
+	if domain, ok := datadogSites[strings.ToLower(site)]; ok {
+		site = domain
+	}
+	if site == "" {
+		site = defaultSite
+	}
+	if subdomain == "" {
+		subdomain = defaultSubdomain
+	}
+
+	return map[string]string{"protocol": "https", "name": subdomain + "." + site}, nil
+}
+
 func (a *APMPlugin) PluginInfo() (*base.PluginInfo, error) {
 	return pluginInfo, nil
 }
 
 func (a *APMPlugin) Query(q string, r sdk.TimeRange) (sdk.TimestampedMetrics, error) {
-	m, err := a.QueryMultiple(q, r)
+	m, err := a.QueryLabeled(q, r)
 	if err != nil {
 		return nil, err
 	}
+	return m.Metrics, nil
+}
 
-	switch len(m) {
-	case 0:
-		return sdk.TimestampedMetrics{}, nil
-	case 1:
-		return m[0], nil
-	default:
-		return nil, fmt.Errorf("query returned %d metric streams, only 1 is expected", len(m))
+func (a *APMPlugin) QueryLabeled(q string, r sdk.TimeRange) (sdk.LabeledTimestampedMetrics, error) {
+	m, err := a.QueryMultipleLabeled(q, r)
+	if err != nil {
+		return sdk.LabeledTimestampedMetrics{}, err
 	}
+	return apm.SingleLabeledSeries(m)
 }
 
 func (a *APMPlugin) QueryMultiple(q string, r sdk.TimeRange) ([]sdk.TimestampedMetrics, error) {
+	m, err := a.QueryMultipleLabeled(q, r)
+	if err != nil {
+		return nil, err
+	}
+
+	if m == nil {
+		return nil, nil
+	}
+
+	results := make([]sdk.TimestampedMetrics, len(m))
+	for i, series := range m {
+		results[i] = series.Metrics
+	}
+	return results, nil
+}
+
+func (a *APMPlugin) QueryMultipleLabeled(q string, r sdk.TimeRange) ([]sdk.LabeledTimestampedMetrics, error) {
+	return a.queryMetrics(q, r.From.Unix(), r.To.Unix())
+}
+
+// QueryStream satisfies the QueryStream function on the apm.StreamingAPM
+// interface. The time range is split into windows which are queried and sent
+// one at a time, so long ranges are returned with a higher resolution.
+func (a *APMPlugin) QueryStream(q string, r sdk.TimeRange, send func(sdk.TimestampedMetrics) error) error {
+	return a.QueryStreamLabeled(q, r, func(m sdk.LabeledTimestampedMetrics) error {
+		return send(m.Metrics)
+	})
+}
+
+// QueryStreamLabeled satisfies the QueryStreamLabeled function on the
+// apm.LabeledStreamingAPM interface, sending the labels of the series with
+// each window.
+func (a *APMPlugin) QueryStreamLabeled(q string, r sdk.TimeRange, send func(sdk.LabeledTimestampedMetrics) error) error {
+	end := r.To.Unix()
+
+	window := int64(a.streamWindow / time.Second)
+	if window == 0 {
+		window = end - r.From.Unix() + 1
+	}
+
+	// Datadog includes both the start and end time in the query, so the next
+	// window starts one second after the end of the previous one.
+	for from := r.From.Unix(); from <= end; from += window {
+		to := from + window - 1
+		if to > end {
+			to = end
+		}
+
+		m, err := a.queryMetrics(q, from, to)
+		if err != nil {
+			return err
+		}
+
+		switch len(m) {
+		case 0:
+			continue
+		case 1:
+			if err := send(m[0]); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("query returned %d metric streams, only 1 is expected", len(m))
+		}
+	}
+	return nil
+}
+
+// queryMetrics performs the query over the time range defined by the from
+// and to Unix timestamps.
+func (a *APMPlugin) queryMetrics(q string, from, to int64) ([]sdk.LabeledTimestampedMetrics, error) {
 	ctx, cancel := context.WithTimeout(a.clientCtx, 10*time.Second)
 	defer cancel()
 
-	queryResult, res, err := a.client.MetricsApi.QueryMetrics(ctx, r.From.Unix(), r.To.Unix(), q)
+	queryResult, res, err := a.client.MetricsApi.QueryMetrics(ctx, from, to, q)
 	if err != nil {
 		if res != nil && res.StatusCode == http.StatusTooManyRequests {
 			return nil,
@@ -163,7 +348,7 @@ func (a *APMPlugin) QueryMultiple(q string, r sdk.TimeRange) ([]sdk.TimestampedM
 		return nil, nil
 	}
 
-	var results []sdk.TimestampedMetrics
+	var results []sdk.LabeledTimestampedMetrics
 	for _, s := range series {
 		pl, ok := s.GetPointlistOk()
 		if !ok {
@@ -192,7 +377,10 @@ func (a *APMPlugin) QueryMultiple(q string, r sdk.TimeRange) ([]sdk.TimestampedM
 			result = append(result, tm)
 		}
 
-		results = append(results, result)
+		results = append(results, sdk.LabeledTimestampedMetrics{
+			Labels:  tagSetLabels(s.GetTagSet()),
+			Metrics: result,
+		})
 	}
 
 	if len(results) == 0 {
@@ -201,3 +389,19 @@ func (a *APMPlugin) QueryMultiple(q string, r sdk.TimeRange) ([]sdk.TimestampedM
 
 	return results, nil
 }
+
+// tagSetLabels converts the tags which identify a Datadog series into labels.
+// Tags are in the format <key>:<value>, tags without a value are added using
+// an empty value.
+func tagSetLabels(tags []string) map[string]string {
+	if len(tags) == 0 {
+		return nil
+	}
+
+	labels := make(map[string]string, len(tags))
+	for _, tag := range tags {
+		k, v, _ := strings.Cut(tag, ":")
+		labels[k] = v
+	}
+	return labels
+}
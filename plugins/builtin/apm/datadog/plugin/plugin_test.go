@@ -120,10 +120,50 @@ func TestAPMPlugin_SetConfig(t *testing.T) {
 			expectOutput:       nil,
 			expectedContextKey: datadog.ContextServerVariables,
 			expectedContextValue: map[string]string{
-				"site": "app.datadoghq.eu",
+				"protocol": "https",
+				"name":     "api.datadoghq.eu",
 			},
 			name: "site set by config map",
 		},
+		{
+			inputConfig:          map[string]string{"site": "us3"},
+			expectOutput:         nil,
+			expectedContextKey:   datadog.ContextServerIndex,
+			expectedContextValue: 1,
+			name:                 "site set by short name uses custom server",
+		},
+		{
+			inputConfig:        map[string]string{"site": "us5", "subdomain": "api-internal"},
+			expectOutput:       nil,
+			expectedContextKey: datadog.ContextServerVariables,
+			expectedContextValue: map[string]string{
+				"protocol": "https",
+				"name":     "api-internal.us5.datadoghq.com",
+			},
+			name: "site short name and subdomain set by config map",
+		},
+		{
+			inputConfig:        map[string]string{"site": "datadoghq.eu", "api_host": "http://dd-relay.internal:8080"},
+			expectOutput:       nil,
+			expectedContextKey: datadog.ContextServerVariables,
+			expectedContextValue: map[string]string{
+				"protocol": "http",
+				"name":     "dd-relay.internal:8080",
+			},
+			name: "api host overrides site",
+		},
+		{
+			inputConfig:          map[string]string{"api_host": "dd-relay.internal/api/v1"},
+			expectOutput:         errors.New("failed to parse api_host value dd-relay.internal/api/v1: must be a host name with optional scheme and port"),
+			expectedContextValue: nil,
+			name:                 "invalid api host",
+		},
+		{
+			inputConfig:          map[string]string{"proxy_url": "not a url"},
+			expectOutput:         errors.New("failed to parse proxy_url value not a url: must be a valid URL"),
+			expectedContextValue: nil,
+			name:                 "invalid proxy url",
+		},
 	}
 
 	for _, tc := range testCases {
@@ -244,3 +284,99 @@ func TestAPMPlugin_Query(t *testing.T) {
 		})
 	}
 }
+
+func TestAPMPlugin_Query_proxy(t *testing.T) {
+	// The test server acts as the proxy, so it receives the requests sent to
+	// the configured API host.
+	var proxied bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = r.Host == "datadog.example.com"
+		http.ServeFile(w, r, path.Join("./test-fixtures", "query_200.json"))
+	}))
+	defer srv.Close()
+
+	plugin := NewDatadogPlugin(hclog.NewNullLogger())
+	require.NoError(t, plugin.SetConfig(map[string]string{
+		configKeyClientAPPKey: "app",
+		configKeyClientAPIKey: "key",
+		configKeyAPIHost:      "http://datadog.example.com",
+		configKeyProxyURL:     srv.URL,
+	}))
+
+	m, err := plugin.Query("avg:nomad.client.allocated.memory", sdk.TimeRange{
+		From: time.Unix(1600000000, 0),
+		To:   time.Unix(1610000000, 0),
+	})
+	require.NoError(t, err)
+	assert.Len(t, m, 63)
+	assert.True(t, proxied)
+}
+
+func TestAPMPlugin_QueryStream(t *testing.T) {
+	var ranges [][]string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		qp := r.URL.Query()
+		ranges = append(ranges, []string{qp.Get("from"), qp.Get("to")})
+		http.ServeFile(w, r, path.Join("./test-fixtures", "query_200.json"))
+	}))
+	defer srv.Close()
+
+	srvURL, err := url.Parse(srv.URL)
+	require.NoError(t, err)
+
+	plugin := NewDatadogPlugin(hclog.NewNullLogger()).(*APMPlugin)
+	plugin.ddConfigCallback = func(config *datadog.Configuration) {
+		config.Host = srvURL.Host
+		config.Scheme = srvURL.Scheme
+	}
+	require.NoError(t, plugin.SetConfig(map[string]string{
+		configKeyClientAPPKey: "app",
+		configKeyClientAPIKey: "key",
+		configKeyStreamWindow: "30m",
+	}))
+
+	r := sdk.TimeRange{From: time.Unix(1600000000, 0), To: time.Unix(1600003600, 0)}
+
+	var chunks []sdk.TimestampedMetrics
+	err = plugin.QueryStream("avg:nomad.client.allocated.memory", r, func(m sdk.TimestampedMetrics) error {
+		chunks = append(chunks, m)
+		return nil
+	})
+	require.NoError(t, err)
+	require.Len(t, chunks, 3)
+	require.Equal(t, [][]string{
+		{"1600000000", "1600001799"},
+		{"1600001800", "1600003599"},
+		{"1600003600", "1600003600"},
+	}, ranges)
+}
+
+func Test_tagSetLabels(t *testing.T) {
+	testCases := []struct {
+		name           string
+		input          []string
+		expectedOutput map[string]string
+	}{
+		{
+			name:           "no tags",
+			input:          nil,
+			expectedOutput: nil,
+		},
+		{
+			name:           "key value tags",
+			input:          []string{"host:server-1", "env:prod"},
+			expectedOutput: map[string]string{"host": "server-1", "env": "prod"},
+		},
+		{
+			name:           "tag without value",
+			input:          []string{"canary", "image:nginx:1.25"},
+			expectedOutput: map[string]string{"canary": "", "image": "nginx:1.25"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expectedOutput, tagSetLabels(tc.input))
+		})
+	}
+}
@@ -0,0 +1,94 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package plugin
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// extractValue walks the decoded JSON document following the path and
+// returns the numeric value found. The path is a dot separated list of object
+// keys and array indexes, such as data.queues.0.depth. The special element #
+// returns the length of an array, such as data.pending.#. Keys which contain
+// dots can be escaped with a backslash.
+//
+// Numbers, numeric strings and booleans are accepted as values, where true is
+// converted to 1 and false to 0.
+func extractValue(doc interface{}, path string) (float64, error) {
+	current := doc
+
+	for _, elem := range splitPath(path) {
+		switch v := current.(type) {
+		case map[string]interface{}:
+			next, ok := v[elem]
+			if !ok {
+				return 0, fmt.Errorf("key %q not found", elem)
+			}
+			current = next
+
+		case []interface{}:
+			if elem == "#" {
+				current = float64(len(v))
+				continue
+			}
+
+			idx, err := strconv.Atoi(elem)
+			if err != nil {
+				return 0, fmt.Errorf("invalid array index %q", elem)
+			}
+			if idx < 0 || idx >= len(v) {
+				return 0, fmt.Errorf("array index %d out of range", idx)
+			}
+			current = v[idx]
+
+		default:
+			return 0, fmt.Errorf("cannot access %q on a non-container value", elem)
+		}
+	}
+
+	switch v := current.(type) {
+	case float64:
+		return v, nil
+	case string:
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return 0, fmt.Errorf("value %q is not a number", v)
+		}
+		return f, nil
+	case bool:
+		if v {
+			return 1, nil
+		}
+		return 0, nil
+	case nil:
+		return 0, fmt.Errorf("value is null")
+	default:
+		return 0, fmt.Errorf("value of type %T is not a number", v)
+	}
+}
+
+// splitPath splits the path into its elements, handling escaped dots.
+func splitPath(path string) []string {
+	var (
+		elems []string
+		b     strings.Builder
+	)
+
+	for i := 0; i < len(path); i++ {
+		switch {
+		case path[i] == '\\' && i+1 < len(path) && path[i+1] == '.':
+			b.WriteByte('.')
+			i++
+		case path[i] == '.':
+			elems = append(elems, b.String())
+			b.Reset()
+		default:
+			b.WriteByte(path[i])
+		}
+	}
+
+	return append(elems, b.String())
+}
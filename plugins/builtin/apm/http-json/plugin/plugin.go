@@ -0,0 +1,292 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package plugin
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	hclog "github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/nomad-autoscaler/plugins"
+	"github.com/hashicorp/nomad-autoscaler/plugins/apm"
+	"github.com/hashicorp/nomad-autoscaler/plugins/base"
+	"github.com/hashicorp/nomad-autoscaler/sdk"
+	"github.com/hashicorp/nomad-autoscaler/sdk/helper/pluginconfig"
+)
+
+const (
+	// pluginName is the unique name of the this plugin amongst APM plugins.
+	pluginName = "http-json"
+
+	// configKeyAddress is the base URL used to resolve relative query URLs.
+	configKeyAddress = "address"
+
+	// configKeyBasicAuthUser and configKeyBasicAuthPassword are the
+	// configuration keys used to set the HTTP client basic auth.
+	configKeyBasicAuthUser     = "basic_auth_user"
+	configKeyBasicAuthPassword = "basic_auth_password"
+
+	// configKeyHeadersPrefix is the prefix used to indicate that a
+	// configuration value should be set as an HTTP header.
+	configKeyHeadersPrefix = "header_"
+
+	// configKeyCACert is the path to the CA certificate the HTTP client
+	// should use.
+	configKeyCACert = "ca_cert"
+
+	// configKeyClientCert and configKeyClientKey are the paths to the
+	// certificate and key used for mutual TLS.
+	configKeyClientCert = "client_cert"
+	configKeyClientKey  = "client_key"
+
+	// configKeySkipVerify indicates that the HTTP client should not verify
+	// TLS certificates.
+	configKeySkipVerify = "skip_verify"
+
+	// configKeyTimeout is the time limit of each request.
+	configKeyTimeout = "timeout"
+
+	// configValueTimeoutDefault is the default time limit of each request.
+	configValueTimeoutDefault = "10s"
+)
+
+var (
+	PluginID = plugins.PluginID{
+		Name:       pluginName,
+		PluginType: sdk.PluginTypeAPM,
+	}
+
+	PluginConfig = &plugins.InternalPluginConfig{
+		Factory: func(l hclog.Logger) interface{} { return NewHTTPJSONPlugin(l) },
+	}
+
+	pluginInfo = &base.PluginInfo{
+		Name:       pluginName,
+		PluginType: sdk.PluginTypeAPM,
+	}
+)
+
+// Assert that APMPlugin meets the apm.APM interface.
+var _ apm.APM = (*APMPlugin)(nil)
+
+// APMPlugin is the generic HTTP/JSON implementation of the apm.APM interface.
+// Queries are URLs whose fragment is the path of the numeric value to extract
+// from the JSON response, such as https://example.com/stats#queue.depth.
+type APMPlugin struct {
+	config map[string]string
+	logger hclog.Logger
+
+	client  *http.Client
+	address *url.URL
+
+	headers           map[string]string
+	basicAuthUser     string
+	basicAuthPassword string
+}
+
+// NewHTTPJSONPlugin returns the HTTP/JSON implementation of the apm.APM
+// interface.
+func NewHTTPJSONPlugin(log hclog.Logger) apm.APM {
+	return &APMPlugin{
+		logger: log,
+	}
+}
+
+// SetConfig satisfies the SetConfig function on the base.Base interface.
+func (a *APMPlugin) SetConfig(config map[string]string) error {
+
+	var address *url.URL
+	if addr := config[configKeyAddress]; addr != "" {
+		u, err := url.ParseRequestURI(addr)
+		if err != nil {
+			return fmt.Errorf("failed to parse `%s`: %v", configKeyAddress, err)
+		}
+		address = u
+	}
+
+	timeout, err := time.ParseDuration(pluginconfig.GetValue(config, configKeyTimeout, configValueTimeoutDefault))
+	if err != nil {
+		return fmt.Errorf("failed to parse `%s`: %v", configKeyTimeout, err)
+	}
+
+	tlsConfig, err := generateTLSConfig(config)
+	if err != nil {
+		return fmt.Errorf("failed to parse TLS configuration: %v", err)
+	}
+
+	headers := make(map[string]string)
+	for k, v := range config {
+		if strings.HasPrefix(k, configKeyHeadersPrefix) {
+			headers[strings.TrimPrefix(k, configKeyHeadersPrefix)] = v
+		}
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+
+	a.config = config
+	a.address = address
+	a.client = &http.Client{Timeout: timeout, Transport: transport}
+	a.headers = headers
+	a.basicAuthUser = config[configKeyBasicAuthUser]
+	a.basicAuthPassword = config[configKeyBasicAuthPassword]
+
+	return nil
+}
+
+// PluginInfo satisfies the PluginInfo function on the base.Base interface.
+func (a *APMPlugin) PluginInfo() (*base.PluginInfo, error) {
+	return pluginInfo, nil
+}
+
+// Query satisfies the Query function on the apm.APM interface. The endpoint
+// only reports its current value, so a single data point is returned at the
+// end of the time range.
+func (a *APMPlugin) Query(q string, r sdk.TimeRange) (sdk.TimestampedMetrics, error) {
+	if a.client == nil {
+		return nil, fmt.Errorf("plugin is not configured")
+	}
+
+	endpoint, path, err := a.parseQuery(q)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := a.get(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query %s: %v", endpoint, err)
+	}
+
+	var doc interface{}
+	if err := json.Unmarshal(body, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse response from %s: %v", endpoint, err)
+	}
+
+	value, err := extractValue(doc, path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to extract %q from response: %v", path, err)
+	}
+
+	return sdk.TimestampedMetrics{{Timestamp: r.To, Value: value}}, nil
+}
+
+// QueryMultiple satisfies the QueryMultiple function on the apm.APM
+// interface. Queries always extract a single value, so a single series is
+// returned.
+func (a *APMPlugin) QueryMultiple(q string, r sdk.TimeRange) ([]sdk.TimestampedMetrics, error) {
+	m, err := a.Query(q, r)
+	if err != nil {
+		return nil, err
+	}
+	return []sdk.TimestampedMetrics{m}, nil
+}
+
+// parseQuery splits the query into the endpoint to request and the path of
+// the value to extract. Relative URLs are resolved against the configured
+// address.
+func (a *APMPlugin) parseQuery(q string) (string, string, error) {
+	rawURL, path, ok := strings.Cut(q, "#")
+	if !ok || path == "" {
+		return "", "", fmt.Errorf("invalid query %q, expected format <url>#<json_path>", q)
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid query %q, failed to parse URL: %v", q, err)
+	}
+
+	if !u.IsAbs() {
+		if a.address == nil {
+			return "", "", fmt.Errorf("invalid query %q, relative URLs require `%s` to be set", q, configKeyAddress)
+		}
+		u = a.address.ResolveReference(u)
+	}
+
+	return u.String(), path, nil
+}
+
+// get performs a GET request to the endpoint and returns the response body.
+func (a *APMPlugin) get(endpoint string) ([]byte, error) {
+	req, err := http.NewRequest(http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	for header, value := range a.headers {
+		req.Header.Set(header, value)
+	}
+
+	setAuth := (a.basicAuthUser != "" || a.basicAuthPassword != "") && req.Header.Get("Authorization") == ""
+	if setAuth {
+		req.SetBasicAuth(a.basicAuthUser, a.basicAuthPassword)
+	}
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %v", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected response code %d", resp.StatusCode)
+	}
+	return body, nil
+}
+
+// generateTLSConfig builds the TLS configuration of the HTTP client.
+func generateTLSConfig(config map[string]string) (*tls.Config, error) {
+	tlsConfig := tls.Config{}
+
+	// Load the CA certificate if present.
+	if caCertPath := config[configKeyCACert]; caCertPath != "" {
+		caCert, err := os.ReadFile(caCertPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load CA certificate %s: %v", caCertPath, err)
+		}
+
+		caCertPool := x509.NewCertPool()
+		if !caCertPool.AppendCertsFromPEM(caCert) {
+			return nil, fmt.Errorf("failed to decode PEM file %s", caCertPath)
+		}
+		tlsConfig.RootCAs = caCertPool
+	}
+
+	// Load the client certificate if present.
+	certPath, keyPath := config[configKeyClientCert], config[configKeyClientKey]
+	if certPath != "" || keyPath != "" {
+		if certPath == "" || keyPath == "" {
+			return nil, fmt.Errorf("both %s and %s must be set", configKeyClientCert, configKeyClientKey)
+		}
+
+		cert, err := tls.LoadX509KeyPair(certPath, keyPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %v", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	if skipVerify := config[configKeySkipVerify]; skipVerify != "" {
+		skipVerifyBool, err := strconv.ParseBool(skipVerify)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s value %s: %v", configKeySkipVerify, skipVerify, err)
+		}
+		tlsConfig.InsecureSkipVerify = skipVerifyBool
+	}
+
+	return &tlsConfig, nil
+}
@@ -0,0 +1,170 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package plugin
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/nomad-autoscaler/sdk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_extractValue(t *testing.T) {
+	doc := `{
+		"queue": {"depth": 12, "rate": "3.5", "paused": true, "owner": null},
+		"workers": [{"busy": 2}, {"busy": 5}],
+		"dotted.key": 7
+	}`
+
+	var parsed interface{}
+	require.NoError(t, json.Unmarshal([]byte(doc), &parsed))
+
+	testCases := []struct {
+		name          string
+		path          string
+		expectedValue float64
+		expectedErr   string
+	}{
+		{name: "nested number", path: "queue.depth", expectedValue: 12},
+		{name: "numeric string", path: "queue.rate", expectedValue: 3.5},
+		{name: "boolean", path: "queue.paused", expectedValue: 1},
+		{name: "array index", path: "workers.1.busy", expectedValue: 5},
+		{name: "array length", path: "workers.#", expectedValue: 2},
+		{name: "escaped dot", path: `dotted\.key`, expectedValue: 7},
+		{name: "missing key", path: "queue.size", expectedErr: `key "size" not found`},
+		{name: "index out of range", path: "workers.2.busy", expectedErr: "array index 2 out of range"},
+		{name: "invalid index", path: "workers.first", expectedErr: `invalid array index "first"`},
+		{name: "null value", path: "queue.owner", expectedErr: "value is null"},
+		{name: "non-numeric value", path: "workers.0", expectedErr: "is not a number"},
+		{name: "access on scalar", path: "queue.depth.value", expectedErr: "non-container value"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			value, err := extractValue(parsed, tc.path)
+			if tc.expectedErr != "" {
+				assert.ErrorContains(t, err, tc.expectedErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedValue, value)
+		})
+	}
+}
+
+func TestAPMPlugin_Query(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/stats":
+			if r.Header.Get("X-Api-Key") != "secret" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			_, _ = w.Write([]byte(`{"queue": {"depth": 42}}`))
+		case "/basic":
+			if user, pass, ok := r.BasicAuth(); !ok || user != "user" || pass != "pass" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			_, _ = w.Write([]byte(`{"value": 3}`))
+		case "/invalid":
+			_, _ = w.Write([]byte(`not json`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	testCases := []struct {
+		name          string
+		config        map[string]string
+		query         string
+		expectedValue float64
+		expectedErr   string
+	}{
+		{
+			name:          "relative URL with header",
+			config:        map[string]string{"address": ts.URL, "header_X-Api-Key": "secret"},
+			query:         "/stats#queue.depth",
+			expectedValue: 42,
+		},
+		{
+			name:          "absolute URL with basic auth",
+			config:        map[string]string{"basic_auth_user": "user", "basic_auth_password": "pass"},
+			query:         ts.URL + "/basic#value",
+			expectedValue: 3,
+		},
+		{
+			name:        "missing header",
+			config:      map[string]string{"address": ts.URL},
+			query:       "/stats#queue.depth",
+			expectedErr: "unexpected response code 401",
+		},
+		{
+			name:        "relative URL without address",
+			config:      map[string]string{},
+			query:       "/stats#queue.depth",
+			expectedErr: "relative URLs require `address` to be set",
+		},
+		{
+			name:        "missing path",
+			config:      map[string]string{"address": ts.URL},
+			query:       "/stats",
+			expectedErr: "expected format <url>#<json_path>",
+		},
+		{
+			name:        "invalid response",
+			config:      map[string]string{"address": ts.URL},
+			query:       "/invalid#value",
+			expectedErr: "failed to parse response",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			p := NewHTTPJSONPlugin(hclog.NewNullLogger())
+			require.NoError(t, p.SetConfig(tc.config))
+
+			to := time.Now()
+			metrics, err := p.Query(tc.query, sdk.TimeRange{From: to.Add(-time.Minute), To: to})
+			if tc.expectedErr != "" {
+				assert.ErrorContains(t, err, tc.expectedErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, sdk.TimestampedMetrics{{Timestamp: to, Value: tc.expectedValue}}, metrics)
+		})
+	}
+}
+
+func TestAPMPlugin_SetConfig(t *testing.T) {
+	testCases := []struct {
+		name        string
+		config      map[string]string
+		expectedErr string
+	}{
+		{name: "defaults", config: map[string]string{}},
+		{name: "invalid timeout", config: map[string]string{"timeout": "soon"}, expectedErr: "failed to parse `timeout`"},
+		{name: "invalid skip_verify", config: map[string]string{"skip_verify": "maybe"}, expectedErr: "failed to parse skip_verify"},
+		{name: "missing CA cert", config: map[string]string{"ca_cert": "/does/not/exist"}, expectedErr: "failed to load CA certificate"},
+		{name: "client cert without key", config: map[string]string{"client_cert": "cert.pem"}, expectedErr: "both client_cert and client_key must be set"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := NewHTTPJSONPlugin(hclog.NewNullLogger()).SetConfig(tc.config)
+			if tc.expectedErr != "" {
+				assert.ErrorContains(t, err, tc.expectedErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,206 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package plugin
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	hclog "github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/nomad-autoscaler/plugins"
+	"github.com/hashicorp/nomad-autoscaler/plugins/apm"
+	"github.com/hashicorp/nomad-autoscaler/plugins/base"
+	"github.com/hashicorp/nomad-autoscaler/plugins/builtin/internal/kafka"
+	"github.com/hashicorp/nomad-autoscaler/sdk"
+)
+
+const (
+	// pluginName is the unique name of the this plugin amongst APM plugins.
+	pluginName = "kafka-lag"
+
+	// configValueTimeoutDefault is the default timeout of each request sent
+	// to the Kafka brokers.
+	configValueTimeoutDefault = 10 * time.Second
+
+	// The metrics supported by the plugin.
+	metricTotalLag = "total_lag"
+	metricMaxLag   = "max_lag"
+)
+
+var (
+	PluginID = plugins.PluginID{
+		Name:       pluginName,
+		PluginType: sdk.PluginTypeAPM,
+	}
+
+	PluginConfig = &plugins.InternalPluginConfig{
+		Factory: func(l hclog.Logger) interface{} { return NewKafkaLagPlugin(l) },
+	}
+
+	pluginInfo = &base.PluginInfo{
+		Name:       pluginName,
+		PluginType: sdk.PluginTypeAPM,
+	}
+)
+
+// Assert that APMPlugin meets the apm.APM interface.
+var _ apm.APM = (*APMPlugin)(nil)
+
+// lagClient reads the lag of consumer groups.
+type lagClient interface {
+	GroupLag(group string) ([]kafka.PartitionLag, error)
+	Close()
+}
+
+// APMPlugin is the Kafka consumer lag implementation of the apm.APM
+// interface. The lag of consumer groups is computed from the offsets they
+// committed and the end offsets of the partitions, both read from the Kafka
+// brokers the same way the Kafka admin tools do.
+type APMPlugin struct {
+	config map[string]string
+	logger hclog.Logger
+
+	client     lagClient
+	clientLock sync.RWMutex
+}
+
+// query is the parsed representation of a Kafka lag query. Queries use the
+// format <consumer_group>/<metric> or <consumer_group>/<topic>/<metric>.
+type query struct {
+	group  string
+	topic  string
+	metric string
+}
+
+// NewKafkaLagPlugin returns the Kafka consumer lag implementation of the
+// apm.APM interface.
+func NewKafkaLagPlugin(log hclog.Logger) apm.APM {
+	return &APMPlugin{
+		logger: log,
+	}
+}
+
+// SetConfig satisfies the SetConfig function on the base.Base interface.
+func (a *APMPlugin) SetConfig(config map[string]string) error {
+
+	opts, err := kafka.ParseConfig(config, configValueTimeoutDefault)
+	if err != nil {
+		return err
+	}
+
+	a.clientLock.Lock()
+	defer a.clientLock.Unlock()
+
+	if a.client != nil {
+		a.client.Close()
+	}
+	a.config = config
+	a.client = kafka.NewClient(opts)
+
+	return nil
+}
+
+// PluginInfo satisfies the PluginInfo function on the base.Base interface.
+func (a *APMPlugin) PluginInfo() (*base.PluginInfo, error) {
+	return pluginInfo, nil
+}
+
+// Query satisfies the Query function on the apm.APM interface. Kafka only
+// exposes the current lag, so a single data point is returned at the end of
+// the time range.
+func (a *APMPlugin) Query(q string, r sdk.TimeRange) (sdk.TimestampedMetrics, error) {
+	a.clientLock.RLock()
+	client := a.client
+	a.clientLock.RUnlock()
+
+	if client == nil {
+		return nil, fmt.Errorf("plugin is not configured")
+	}
+
+	parsed, err := parseQuery(q)
+	if err != nil {
+		return nil, err
+	}
+
+	lags, err := client.GroupLag(parsed.group)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get lag of consumer group %s: %v", parsed.group, err)
+	}
+
+	value, found := calculateLag(lags, parsed)
+	if !found {
+		a.logger.Warn("no partitions found for consumer group", "query", q)
+		return sdk.TimestampedMetrics{}, nil
+	}
+
+	return sdk.TimestampedMetrics{{Timestamp: r.To, Value: value}}, nil
+}
+
+// QueryMultiple satisfies the QueryMultiple function on the apm.APM
+// interface. Queries always target a single consumer group, so a single
+// series is returned.
+func (a *APMPlugin) QueryMultiple(q string, r sdk.TimeRange) ([]sdk.TimestampedMetrics, error) {
+	m, err := a.Query(q, r)
+	if err != nil {
+		return nil, err
+	}
+	return []sdk.TimestampedMetrics{m}, nil
+}
+
+// parseQuery parses and validates the input query.
+func parseQuery(q string) (*query, error) {
+	parts := strings.Split(q, "/")
+
+	var out query
+	switch len(parts) {
+	case 2:
+		out = query{group: parts[0], metric: parts[1]}
+	case 3:
+		out = query{group: parts[0], topic: parts[1], metric: parts[2]}
+		if out.topic == "" {
+			return nil, fmt.Errorf("invalid query %q, topic must not be empty", q)
+		}
+	default:
+		return nil, fmt.Errorf("invalid query %q, expected format <consumer_group>/<metric> or <consumer_group>/<topic>/<metric>", q)
+	}
+
+	if out.group == "" {
+		return nil, fmt.Errorf("invalid query %q, consumer group must not be empty", q)
+	}
+
+	switch out.metric {
+	case metricTotalLag, metricMaxLag:
+	default:
+		return nil, fmt.Errorf("invalid query %q, unsupported metric %q, must be %q or %q",
+			q, out.metric, metricTotalLag, metricMaxLag)
+	}
+
+	return &out, nil
+}
+
+// calculateLag aggregates the partition lags which match the query. It
+// returns false if no partition matched.
+func calculateLag(lags []kafka.PartitionLag, q *query) (float64, bool) {
+	var total, maxLag int64
+	found := false
+
+	for _, l := range lags {
+		if q.topic != "" && l.Topic != q.topic {
+			continue
+		}
+		found = true
+
+		total += l.Lag
+		if l.Lag > maxLag {
+			maxLag = l.Lag
+		}
+	}
+
+	if q.metric == metricMaxLag {
+		return float64(maxLag), found
+	}
+	return float64(total), found
+}
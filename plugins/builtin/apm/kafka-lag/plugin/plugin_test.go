@@ -0,0 +1,149 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package plugin
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	hclog "github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/nomad-autoscaler/plugins/builtin/internal/kafka"
+	"github.com/hashicorp/nomad-autoscaler/sdk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_parseQuery(t *testing.T) {
+	testCases := []struct {
+		name          string
+		query         string
+		expected      *query
+		expectedError string
+	}{
+		{
+			name:     "group",
+			query:    "workers/total_lag",
+			expected: &query{group: "workers", metric: "total_lag"},
+		},
+		{
+			name:     "group and topic",
+			query:    "workers/orders/max_lag",
+			expected: &query{group: "workers", topic: "orders", metric: "max_lag"},
+		},
+		{
+			name:          "missing metric",
+			query:         "workers",
+			expectedError: "expected format",
+		},
+		{
+			name:          "empty group",
+			query:         "/total_lag",
+			expectedError: "consumer group must not be empty",
+		},
+		{
+			name:          "unsupported metric",
+			query:         "workers/lag",
+			expectedError: `unsupported metric "lag"`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			q, err := parseQuery(tc.query)
+			if tc.expectedError != "" {
+				assert.ErrorContains(t, err, tc.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, q)
+		})
+	}
+}
+
+// fakeLagClient is a lagClient returning fixed lags.
+type fakeLagClient struct {
+	lags map[string][]kafka.PartitionLag
+}
+
+func (f *fakeLagClient) GroupLag(group string) ([]kafka.PartitionLag, error) {
+	lags, ok := f.lags[group]
+	if !ok {
+		return nil, errors.New("GROUP_ID_NOT_FOUND")
+	}
+	return lags, nil
+}
+
+func (f *fakeLagClient) Close() {}
+
+func TestAPMPlugin_Query(t *testing.T) {
+	to := time.Unix(1600000000, 0)
+	r := sdk.TimeRange{From: to.Add(-time.Minute), To: to}
+
+	testCases := []struct {
+		name           string
+		query          string
+		expectedResult sdk.TimestampedMetrics
+		expectedError  string
+	}{
+		{
+			name:           "total lag",
+			query:          "workers/total_lag",
+			expectedResult: sdk.TimestampedMetrics{{Timestamp: to, Value: 45}},
+		},
+		{
+			name:           "max lag",
+			query:          "workers/max_lag",
+			expectedResult: sdk.TimestampedMetrics{{Timestamp: to, Value: 30}},
+		},
+		{
+			name:           "total lag of topic",
+			query:          "workers/payments/total_lag",
+			expectedResult: sdk.TimestampedMetrics{{Timestamp: to, Value: 5}},
+		},
+		{
+			name:           "unknown topic",
+			query:          "workers/unknown/total_lag",
+			expectedResult: sdk.TimestampedMetrics{},
+		},
+		{
+			name:          "unknown group",
+			query:         "unknown/total_lag",
+			expectedError: "failed to get lag of consumer group unknown: GROUP_ID_NOT_FOUND",
+		},
+	}
+
+	p := &APMPlugin{
+		logger: hclog.NewNullLogger(),
+		client: &fakeLagClient{lags: map[string][]kafka.PartitionLag{
+			"workers": {
+				{Topic: "orders", Partition: 0, Committed: 90, End: 100, Lag: 10},
+				{Topic: "orders", Partition: 1, Committed: 70, End: 100, Lag: 30},
+				{Topic: "payments", Partition: 0, Committed: 10, End: 15, Lag: 5},
+			},
+		}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := p.Query(tc.query, r)
+			if tc.expectedError != "" {
+				assert.ErrorContains(t, err, tc.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedResult, result)
+		})
+	}
+}
+
+func TestAPMPlugin_SetConfig(t *testing.T) {
+	p := NewKafkaLagPlugin(hclog.NewNullLogger())
+
+	_, err := p.Query("workers/total_lag", sdk.TimeRange{})
+	assert.ErrorContains(t, err, "plugin is not configured")
+
+	assert.ErrorContains(t, p.SetConfig(map[string]string{"brokers": "kafka"}), "invalid `brokers` entry")
+	require.NoError(t, p.SetConfig(map[string]string{"brokers": "kafka-1:9092,kafka-2:9092"}))
+}
@@ -34,7 +34,14 @@ func (a *APMPlugin) queryTaskGroup(q string) (sdk.TimestampedMetrics, error) {
 	}
 	a.logger.Debug("expanded query", "from", q, "to", fmt.Sprintf("%# v", query))
 
-	metrics, err := a.getTaskGroupResourceUsage(query)
+	var metrics []float64
+
+	switch query.metric {
+	case queryMetricAllocsPending, queryMetricAllocsQueued, queryMetricEvalsBlocked:
+		metrics, err = a.getTaskGroupSchedulerUsage(query)
+	default:
+		metrics, err = a.getTaskGroupResourceUsage(query)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -111,6 +118,15 @@ func (a *APMPlugin) getTaskGroupResourceUsage(query *taskGroupQuery) ([]float64,
 			usageMiB := ru.MemoryStats.Usage / 1024 / 1024
 			*m = append(*m, (float64(usageMiB)/float64(allocatedMem))*100)
 		}
+	case queryMetricGPU:
+
+		// Allocations without GPU devices do not contribute a data point, so
+		// they don't drag the result towards zero.
+		metricFunc = func(m *[]float64, ru *api.ResourceUsage) {
+			if util, ok := gpuUtilization(ru.DeviceStats); ok {
+				*m = append(*m, util)
+			}
+		}
 	}
 
 	for _, alloc := range allocs {
@@ -146,6 +162,126 @@ func (a *APMPlugin) getTaskGroupResourceUsage(query *taskGroupQuery) ([]float64,
 	return resp, nil
 }
 
+// getTaskGroupSchedulerUsage returns the scheduler pressure metric for the
+// task group. Unlike resource usage, these metrics describe the task group as
+// a whole and therefore always return a single data point.
+func (a *APMPlugin) getTaskGroupSchedulerUsage(query *taskGroupQuery) ([]float64, error) {
+	q := &api.QueryOptions{
+		Namespace: query.namespace,
+	}
+
+	var count int
+
+	switch query.metric {
+	case queryMetricAllocsPending:
+		allocs, _, err := a.client.Jobs().Allocations(query.job, false, q)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get alloc listing for job: %v", err)
+		}
+
+		for _, alloc := range allocs {
+			if alloc.TaskGroup == query.group &&
+				alloc.ClientStatus == api.AllocClientStatusPending &&
+				alloc.DesiredStatus == api.AllocDesiredStatusRun {
+				count++
+			}
+		}
+
+	case queryMetricAllocsQueued:
+		summary, _, err := a.client.Jobs().Summary(query.job, q)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get summary for job: %v", err)
+		}
+
+		tgSummary, ok := summary.Summary[query.group]
+		if !ok {
+			return nil, fmt.Errorf("task group %q not found in job %q summary", query.group, query.job)
+		}
+		count = tgSummary.Queued
+
+	case queryMetricEvalsBlocked:
+
+		// Blocked evaluations are tracked per job, so all task groups within
+		// the job will report the same value.
+		evals, _, err := a.client.Jobs().Evaluations(query.job, q)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get evaluations for job: %v", err)
+		}
+
+		for _, eval := range evals {
+			if eval.Status == api.EvalStatusBlocked {
+				count++
+			}
+		}
+	}
+
+	return []float64{float64(count)}, nil
+}
+
+// gpuUtilization calculates the average utilization percentage of all GPU
+// device instances found within the stats. The boolean return indicates
+// whether any GPU utilization data was found.
+func gpuUtilization(stats []*api.DeviceGroupStats) (float64, bool) {
+	var total float64
+	var num int
+
+	for _, group := range stats {
+		if group == nil || group.Type != deviceTypeGPU {
+			continue
+		}
+
+		for _, instance := range group.InstanceStats {
+			if instance == nil {
+				continue
+			}
+
+			// Prefer the explicit utilization attribute, as exposed by the
+			// Nvidia device plugin, over the device summary which may
+			// represent a different statistic such as memory usage.
+			val := instance.Summary
+			if instance.Stats != nil {
+				if util, ok := instance.Stats.Attributes[deviceStatGPUUtilization]; ok {
+					val = util
+				}
+			}
+
+			if pct, ok := statValuePercentage(val); ok {
+				total += pct
+				num++
+			}
+		}
+	}
+
+	if num == 0 {
+		return 0, false
+	}
+	return total / float64(num), true
+}
+
+// statValuePercentage converts a device StatValue into a percentage. If the
+// value is fractional, the percentage of the numerator over the denominator is
+// returned, otherwise the value is assumed to already be a percentage.
+func statValuePercentage(v *api.StatValue) (float64, bool) {
+	if v == nil {
+		return 0, false
+	}
+
+	switch {
+	case v.FloatNumeratorVal != nil:
+		if v.FloatDenominatorVal != nil && *v.FloatDenominatorVal != 0 {
+			return *v.FloatNumeratorVal / *v.FloatDenominatorVal * 100, true
+		}
+		return *v.FloatNumeratorVal, true
+	case v.IntNumeratorVal != nil:
+		if v.IntDenominatorVal != nil && *v.IntDenominatorVal != 0 {
+			return float64(*v.IntNumeratorVal) / float64(*v.IntDenominatorVal) * 100, true
+		}
+		return float64(*v.IntNumeratorVal), true
+	default:
+		return 0, false
+	}
+}
+
 // getAllocatedCPUForTaskGroup calculates the total allocated CPU in MHz for a taskgroup
 func (a *APMPlugin) getAllocatedCPUForTaskGroup(ns, job, taskgroup string) (int, error) {
 	taskGroupConfig, err := a.getTaskGroup(ns, job, taskgroup)
@@ -293,5 +429,8 @@ func parseTaskGroupQuery(q string) (*taskGroupQuery, error) {
 }
 
 func validateMetricTaskGroupQuery(metric string) error {
-	return validateMetric(metric, []string{queryMetricCPU, queryMetricCPUAllocated, queryMetricMem, queryMetricMemAllocated})
+	return validateMetric(metric, []string{
+		queryMetricCPU, queryMetricCPUAllocated, queryMetricMem, queryMetricMemAllocated, queryMetricGPU,
+		queryMetricAllocsPending, queryMetricAllocsQueued, queryMetricEvalsBlocked,
+	})
 }
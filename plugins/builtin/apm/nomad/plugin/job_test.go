@@ -6,6 +6,8 @@ package plugin
 import (
 	"testing"
 
+	"github.com/hashicorp/nomad-autoscaler/sdk/helper/ptr"
+	"github.com/hashicorp/nomad/api"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -105,6 +107,30 @@ func Test_parseTaskGroupQuery(t *testing.T) {
 			},
 			expectError: false,
 		},
+		{
+			name:  "max_gpu",
+			input: "taskgroup_max_gpu/group/job@dev",
+			expected: &taskGroupQuery{
+				metric:    "gpu",
+				namespace: "dev",
+				job:       "job",
+				group:     "group",
+				operation: "max",
+			},
+			expectError: false,
+		},
+		{
+			name:  "sum_allocs-queued",
+			input: "taskgroup_sum_allocs-queued/group/job@default",
+			expected: &taskGroupQuery{
+				metric:    "allocs-queued",
+				namespace: "default",
+				job:       "job",
+				group:     "group",
+				operation: "sum",
+			},
+			expectError: false,
+		},
 		{
 			name:  "job with fwd slashes",
 			input: "taskgroup_avg_cpu/group/my/super/job//@dev",
@@ -181,3 +207,72 @@ func Test_parseTaskGroupQuery(t *testing.T) {
 		})
 	}
 }
+
+func Test_gpuUtilization(t *testing.T) {
+	testCases := []struct {
+		name           string
+		input          []*api.DeviceGroupStats
+		expectedOutput float64
+		expectedOK     bool
+	}{
+		{
+			name:       "no device stats",
+			input:      nil,
+			expectedOK: false,
+		},
+		{
+			name: "non gpu devices ignored",
+			input: []*api.DeviceGroupStats{
+				{
+					Type: "fpga",
+					InstanceStats: map[string]*api.DeviceStats{
+						"dev-1": {Summary: &api.StatValue{FloatNumeratorVal: ptr.Of(50.0)}},
+					},
+				},
+			},
+			expectedOK: false,
+		},
+		{
+			name: "utilization attribute preferred over summary",
+			input: []*api.DeviceGroupStats{
+				{
+					Type: "gpu",
+					InstanceStats: map[string]*api.DeviceStats{
+						"gpu-1": {
+							Summary: &api.StatValue{IntNumeratorVal: ptr.Of(int64(1024)), IntDenominatorVal: ptr.Of(int64(4096))},
+							Stats: &api.StatObject{
+								Attributes: map[string]*api.StatValue{
+									"GPU utilization": {IntNumeratorVal: ptr.Of(int64(80))},
+								},
+							},
+						},
+					},
+				},
+			},
+			expectedOutput: 80,
+			expectedOK:     true,
+		},
+		{
+			name: "average across instances using summary",
+			input: []*api.DeviceGroupStats{
+				{
+					Type: "gpu",
+					InstanceStats: map[string]*api.DeviceStats{
+						"gpu-1": {Summary: &api.StatValue{IntNumeratorVal: ptr.Of(int64(1024)), IntDenominatorVal: ptr.Of(int64(4096))}},
+						"gpu-2": {Summary: &api.StatValue{FloatNumeratorVal: ptr.Of(75.0)}},
+					},
+				},
+			},
+			expectedOutput: 50,
+			expectedOK:     true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actualOutput, actualOK := gpuUtilization(tc.input)
+			assert.Equal(t, tc.expectedOutput, actualOutput)
+			assert.Equal(t, tc.expectedOK, actualOK)
+		})
+	}
+}
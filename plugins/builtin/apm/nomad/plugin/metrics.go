@@ -16,6 +16,7 @@ const (
 	// important this is included and validated on every query request.
 	QueryTypeTaskGroup = "taskgroup"
 	QueryTypeNode      = "node"
+	QueryTypeService   = "service"
 
 	// queryOps below are the supported operators for task group queries.
 	queryOpSum = "sum"
@@ -25,12 +26,40 @@ const (
 
 	// queryOps below are the supported operators for node pool queries.
 	queryOpPercentageAllocated = "percentage-allocated"
+	queryOpTotal               = "total"
 
 	// queryMetrics are the supported resources for querying.
 	queryMetricCPU          = "cpu"
 	queryMetricCPUAllocated = "cpu-allocated"
 	queryMetricMem          = "memory"
 	queryMetricMemAllocated = "memory-allocated"
+	queryMetricGPU          = "gpu"
+
+	// queryMetrics below are the supported scheduler pressure metrics. They
+	// describe work the Nomad scheduler has not yet been able to complete.
+	queryMetricAllocsPending = "allocs-pending"
+	queryMetricAllocsQueued  = "allocs-queued"
+	queryMetricEvalsBlocked  = "evals-blocked"
+
+	// queryMetricNodesRequired is the number of nodes the pool requires to
+	// place the allocations queued by the Nomad scheduler.
+	queryMetricNodesRequired = "nodes-required"
+
+	// queryMetrics below are the supported service health metrics. The check
+	// failure rates are the percentage of the health checks of each instance
+	// of the service that are failing, using Nomad native checks or Consul
+	// checks. The latency is reported by each instance in its Consul service
+	// meta.
+	queryMetricCheckFailureRate       = "check-failure-rate"
+	queryMetricConsulCheckFailureRate = "consul-check-failure-rate"
+	queryMetricLatency                = "latency"
+
+	// deviceTypeGPU is the Nomad device type used to identify GPUs.
+	deviceTypeGPU = "gpu"
+
+	// deviceStatGPUUtilization is the device statistic attribute which holds
+	// the GPU utilization percentage.
+	deviceStatGPUUtilization = "GPU utilization"
 )
 
 // Query satisfies the Query function on the apm.APM interface.
@@ -46,6 +75,8 @@ func (a *APMPlugin) Query(q string, _ sdk.TimeRange) (sdk.TimestampedMetrics, er
 		return a.queryTaskGroup(q)
 	case QueryTypeNode:
 		return a.queryNodePool(q)
+	case QueryTypeService:
+		return a.queryService(q)
 	default:
 		return nil, fmt.Errorf("unsupported query type %q", querySplit[0])
 	}
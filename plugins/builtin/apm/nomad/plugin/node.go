@@ -31,6 +31,7 @@ type nodePoolResources struct {
 type poolResources struct {
 	cpu int64
 	mem int64
+	gpu int64
 }
 
 // queryNodePool is the main entry point when performing a Nomad node pool APM
@@ -45,6 +46,10 @@ func (a *APMPlugin) queryNodePool(q string) (sdk.TimestampedMetrics, error) {
 	}
 	a.logger.Debug("performing node pool APM query", "query", q)
 
+	if query.operation == queryOpTotal {
+		return a.queryNodePoolTotal(query)
+	}
+
 	// Identify the resource available and consumed within the target pool.
 	resources, err := a.getPoolResources(query.poolIdentifier)
 	if err != nil {
@@ -52,7 +57,8 @@ func (a *APMPlugin) queryNodePool(q string) (sdk.TimestampedMetrics, error) {
 	}
 	a.logger.Debug("collected node pool resource data",
 		"allocated_cpu", resources.allocated.cpu, "allocated_memory", resources.allocated.mem,
-		"allocatable_cpu", resources.allocatable.cpu, "allocatable_memory", resources.allocatable.mem)
+		"allocated_gpu", resources.allocated.gpu, "allocatable_cpu", resources.allocatable.cpu,
+		"allocatable_memory", resources.allocatable.mem, "allocatable_gpu", resources.allocatable.gpu)
 
 	var result float64
 
@@ -69,6 +75,11 @@ func (a *APMPlugin) queryNodePool(q string) (sdk.TimestampedMetrics, error) {
 			return nil, errors.New("zero allocatable cpu found in pool")
 		}
 		result = calculateNodePoolResult(float64(resources.allocated.cpu), float64(resources.allocatable.cpu))
+	case queryMetricGPU:
+		if resources.allocatable.gpu == 0 {
+			return nil, errors.New("zero allocatable gpu found in pool")
+		}
+		result = calculateNodePoolResult(float64(resources.allocated.gpu), float64(resources.allocatable.gpu))
 	}
 
 	tm := sdk.TimestampedMetric{
@@ -78,11 +89,81 @@ func (a *APMPlugin) queryNodePool(q string) (sdk.TimestampedMetrics, error) {
 	return sdk.TimestampedMetrics{tm}, nil
 }
 
-// getPoolResources gathers the allocatable and allocated resources for the
-// specified node pool. Any error in calling the Nomad API for details will
-// result in an error. This is because with missing data, we cannot reliably
-// make calculations.
-func (a *APMPlugin) getPoolResources(id nodepool.ClusterNodePoolIdentifier) (*nodePoolResources, error) {
+// queryNodePoolTotal performs node pool queries which count scheduler
+// pressure. Pending allocations are scoped to the nodes within the pool,
+// whereas queued allocations and blocked evaluations have not yet been placed
+// on any node and therefore represent the whole cluster.
+func (a *APMPlugin) queryNodePoolTotal(query *nodePoolQuery) (sdk.TimestampedMetrics, error) {
+
+	var result int
+
+	// There is no need for a default catch all here as the metric has been
+	// validated during the query parsing.
+	switch query.metric {
+	case queryMetricAllocsPending:
+		nodes, err := a.getPoolNodes(query.poolIdentifier)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, node := range nodes {
+			allocs, _, err := a.client.Nodes().Allocations(node.ID, nil)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read Nomad node allocs on node %s: %v", node.ID, err)
+			}
+
+			for _, alloc := range allocs {
+				if alloc.ClientStatus == api.AllocClientStatusPending && !isServerTerminalStatus(alloc) {
+					result++
+				}
+			}
+		}
+
+	case queryMetricAllocsQueued:
+		jobs, _, err := a.client.Jobs().List(&api.QueryOptions{Namespace: api.AllNamespacesNamespace})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list Nomad jobs: %v", err)
+		}
+
+		for _, job := range jobs {
+			if job.JobSummary == nil {
+				continue
+			}
+			for _, tgSummary := range job.JobSummary.Summary {
+				result += tgSummary.Queued
+			}
+		}
+
+	case queryMetricEvalsBlocked:
+		evals, _, err := a.client.Evaluations().List(&api.QueryOptions{
+			Namespace: api.AllNamespacesNamespace,
+			Filter:    fmt.Sprintf("Status == %q", api.EvalStatusBlocked),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list Nomad evaluations: %v", err)
+		}
+		result = len(evals)
+
+	case queryMetricNodesRequired:
+		nodes, err := a.queryNodesRequired(query.poolIdentifier)
+		if err != nil {
+			return nil, err
+		}
+		result = nodes
+	}
+
+	a.logger.Debug("collected node pool scheduler data", "metric", query.metric, "value", result)
+
+	tm := sdk.TimestampedMetric{
+		Timestamp: time.Now(),
+		Value:     float64(result),
+	}
+	return sdk.TimestampedMetrics{tm}, nil
+}
+
+// getPoolNodes returns the list of nodes which form the specified node pool
+// and are in the correct state.
+func (a *APMPlugin) getPoolNodes(id nodepool.ClusterNodePoolIdentifier) ([]*api.NodeListStub, error) {
 
 	nodes, _, err := a.client.Nodes().List(nil)
 	if err != nil {
@@ -99,6 +180,20 @@ func (a *APMPlugin) getPoolResources(id nodepool.ClusterNodePoolIdentifier) (*no
 		return nil, errors.New("no nodes identified within pool")
 	}
 
+	return nodePoolList, nil
+}
+
+// getPoolResources gathers the allocatable and allocated resources for the
+// specified node pool. Any error in calling the Nomad API for details will
+// result in an error. This is because with missing data, we cannot reliably
+// make calculations.
+func (a *APMPlugin) getPoolResources(id nodepool.ClusterNodePoolIdentifier) (*nodePoolResources, error) {
+
+	nodePoolList, err := a.getPoolNodes(id)
+	if err != nil {
+		return nil, err
+	}
+
 	// Ensure we instantiate the whole object.
 	resp := nodePoolResources{
 		allocatable: &poolResources{},
@@ -135,6 +230,19 @@ func (a *APMPlugin) getNodeAllocatableResources(nodeID string, pool *poolResourc
 	pool.cpu += nodeInfo.NodeResources.Cpu.CpuShares - int64(nodeInfo.ReservedResources.Cpu.CpuShares)
 	pool.mem += nodeInfo.NodeResources.Memory.MemoryMB - int64(nodeInfo.ReservedResources.Memory.MemoryMB)
 
+	// Only healthy GPU instances can be allocated, so do not count unhealthy
+	// instances as allocatable.
+	for _, device := range nodeInfo.NodeResources.Devices {
+		if device.Type != deviceTypeGPU {
+			continue
+		}
+		for _, instance := range device.Instances {
+			if instance.Healthy {
+				pool.gpu++
+			}
+		}
+	}
+
 	return nil
 }
 
@@ -157,6 +265,17 @@ func (a *APMPlugin) getNodeAllocatedResources(nodeID string, pool *poolResources
 		// Update our tracking with the resources of the allocation.
 		pool.cpu += int64(*alloc.Resources.CPU)
 		pool.mem += int64(*alloc.Resources.MemoryMB)
+
+		if alloc.AllocatedResources == nil {
+			continue
+		}
+		for _, task := range alloc.AllocatedResources.Tasks {
+			for _, device := range task.Devices {
+				if device.Type == deviceTypeGPU {
+					pool.gpu += int64(len(device.DeviceIDs))
+				}
+			}
+		}
 	}
 
 	return nil
@@ -165,36 +284,114 @@ func (a *APMPlugin) getNodeAllocatedResources(nodeID string, pool *poolResources
 func parseNodePoolQuery(q string) (*nodePoolQuery, error) {
 
 	mainParts := strings.SplitN(q, "/", 3)
-	if len(mainParts) != 3 {
-		return nil, fmt.Errorf("expected <query>/<pool_identifier_value>/<pool_identifier_key>, received %s", q)
+	if len(mainParts) < 2 {
+		return nil, fmt.Errorf("expected <query>/<pool_identifier_value>/<pool_identifier_key> or <query>/<pool_selector>, received %s", q)
 	}
 
-	query := nodePoolQuery{
-		poolIdentifier: nodepool.NewNodeClassPoolIdentifier(mainParts[1]),
-	}
+	query := nodePoolQuery{}
 
 	opMetricParts := strings.SplitN(mainParts[0], "_", 3)
 	if len(opMetricParts) != 3 {
 		return nil, fmt.Errorf("expected node_<operation>_<metric>, received %s", mainParts[0])
 	}
 
-	if err := validateMetricNodeQuery(opMetricParts[2]); err != nil {
+	switch opMetricParts[1] {
+	case queryOpPercentageAllocated, queryOpTotal:
+		query.operation = opMetricParts[1]
+	default:
+		return nil, fmt.Errorf("invalid operation %q, allowed values are %s or %s",
+			opMetricParts[1], queryOpPercentageAllocated, queryOpTotal)
+	}
+
+	if err := validateMetricNodeQuery(query.operation, opMetricParts[2]); err != nil {
 		return nil, err
 	}
 	query.metric = opMetricParts[2]
 
-	switch opMetricParts[1] {
-	case queryOpPercentageAllocated:
-		query.operation = opMetricParts[1]
-	default:
-		return nil, fmt.Errorf("invalid operation %q, allowed value is %s",
-			opMetricParts[1], queryOpPercentageAllocated)
+	// The original query format identifies the pool using a single value and
+	// key, whereas the selector format allows multiple keys to be combined.
+	var poolCfg map[string]string
+
+	if len(mainParts) == 3 {
+		key, ok := poolSelectorKeys[mainParts[2]]
+		if !ok {
+			return nil, fmt.Errorf("invalid pool identifier key %q, allowed values are class, dc or pool", mainParts[2])
+		}
+		poolCfg = map[string]string{key: mainParts[1]}
+	} else {
+		cfg, err := parsePoolSelector(mainParts[1])
+		if err != nil {
+			return nil, err
+		}
+		poolCfg = cfg
+	}
+
+	poolIdentifier, err := nodepool.NewClusterNodePoolIdentifier(poolCfg)
+	if err != nil {
+		return nil, err
 	}
+	query.poolIdentifier = poolIdentifier
+
 	return &query, nil
 }
 
-func validateMetricNodeQuery(metric string) error {
-	return validateMetric(metric, []string{queryMetricCPU, queryMetricMem})
+const (
+	// poolSelectorSep separates the individual key/value pairs within a node
+	// pool selector.
+	poolSelectorSep = ","
+
+	// poolSelectorKeyValueSep separates the key and value of a single node
+	// pool selector pair.
+	poolSelectorKeyValueSep = ":"
+)
+
+// poolSelectorKeys maps the keys accepted within a node pool selector to the
+// target config key they represent. The short aliases make queries easier to
+// read and write.
+var poolSelectorKeys = map[string]string{
+	"class":                       sdk.TargetConfigKeyClass,
+	sdk.TargetConfigKeyClass:      sdk.TargetConfigKeyClass,
+	"dc":                          sdk.TargetConfigKeyDatacenter,
+	sdk.TargetConfigKeyDatacenter: sdk.TargetConfigKeyDatacenter,
+	"pool":                        sdk.TargetConfigKeyNodePool,
+	sdk.TargetConfigKeyNodePool:   sdk.TargetConfigKeyNodePool,
+}
+
+// parsePoolSelector parses a node pool selector in the format of
+// <key>:<value>[,<key>:<value>] into the target config representation used to
+// build a nodepool.ClusterNodePoolIdentifier. All selectors must match for a
+// node to be considered part of the pool.
+func parsePoolSelector(selector string) (map[string]string, error) {
+	cfg := make(map[string]string)
+
+	for _, pair := range strings.Split(selector, poolSelectorSep) {
+		kv := strings.SplitN(pair, poolSelectorKeyValueSep, 2)
+		if len(kv) != 2 || kv[1] == "" {
+			return nil, fmt.Errorf("expected pool selector in the format <key>:<value>, received %q", pair)
+		}
+
+		key, ok := poolSelectorKeys[kv[0]]
+		if !ok {
+			return nil, fmt.Errorf("invalid pool selector key %q, allowed values are class, dc or pool", kv[0])
+		}
+
+		if _, ok := cfg[key]; ok {
+			return nil, fmt.Errorf("duplicate pool selector key %q", kv[0])
+		}
+		cfg[key] = kv[1]
+	}
+
+	return cfg, nil
+}
+
+// validateMetricNodeQuery ensures the metric is supported by the node query
+// operation.
+func validateMetricNodeQuery(op, metric string) error {
+	if op == queryOpTotal {
+		return validateMetric(metric, []string{
+			queryMetricAllocsPending, queryMetricAllocsQueued, queryMetricEvalsBlocked, queryMetricNodesRequired})
+	}
+	return validateMetric(metric, []string{queryMetricCPU, queryMetricMem, queryMetricGPU})
 }
 
 // calculateNodePoolResult returns the current usage percentage of the node
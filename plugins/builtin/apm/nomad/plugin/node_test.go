@@ -39,41 +39,131 @@ func Test_parseNodePoolQuery(t *testing.T) {
 			expectError: nil,
 			name:        "node percentage-allocated cpu",
 		},
+		{
+			inputQuery: "node_percentage-allocated_gpu/gpu-pool/class",
+			expectedOutputQuery: &nodePoolQuery{
+				metric:         "gpu",
+				poolIdentifier: nodepool.NewNodeClassPoolIdentifier("gpu-pool"),
+				operation:      "percentage-allocated",
+			},
+			expectError: nil,
+			name:        "node percentage-allocated gpu",
+		},
+		{
+			inputQuery: "node_percentage-allocated_memory/pool:gpu-pool",
+			expectedOutputQuery: &nodePoolQuery{
+				metric:         "memory",
+				poolIdentifier: nodepool.NewNodePoolClusterPoolIdentifier("gpu-pool"),
+				operation:      "percentage-allocated",
+			},
+			expectError: nil,
+			name:        "node pool selector",
+		},
+		{
+			inputQuery: "node_percentage-allocated_cpu/dc1/datacenter",
+			expectedOutputQuery: &nodePoolQuery{
+				metric:         "cpu",
+				poolIdentifier: nodepool.NewNodeDatacenterPoolIdentifier("dc1"),
+				operation:      "percentage-allocated",
+			},
+			expectError: nil,
+			name:        "datacenter identifier",
+		},
+		{
+			inputQuery: "node_percentage-allocated_cpu/class:high-compute,dc:dc1",
+			expectedOutputQuery: &nodePoolQuery{
+				metric: "cpu",
+				poolIdentifier: nodepool.NewCombinedClusterPoolIdentifier(
+					[]nodepool.ClusterNodePoolIdentifier{
+						nodepool.NewNodeClassPoolIdentifier("high-compute"),
+						nodepool.NewNodeDatacenterPoolIdentifier("dc1"),
+					},
+					nodepool.CombinedClusterPoolIdentifierAnd,
+				),
+				operation: "percentage-allocated",
+			},
+			expectError: nil,
+			name:        "combined class and datacenter selector",
+		},
+		{
+			inputQuery: "node_total_evals-blocked/high-compute/class",
+			expectedOutputQuery: &nodePoolQuery{
+				metric:         "evals-blocked",
+				poolIdentifier: nodepool.NewNodeClassPoolIdentifier("high-compute"),
+				operation:      "total",
+			},
+			expectError: nil,
+			name:        "node total evals-blocked",
+		},
+		{
+			inputQuery: "node_total_nodes-required/pool:batch",
+			expectedOutputQuery: &nodePoolQuery{
+				metric:         "nodes-required",
+				poolIdentifier: nodepool.NewNodePoolClusterPoolIdentifier("batch"),
+				operation:      "total",
+			},
+			expectError: nil,
+			name:        "node total nodes-required",
+		},
 
 		{
 			inputQuery:          "",
 			expectedOutputQuery: nil,
-			expectError:         errors.New("expected <query>/<pool_identifier_value>/<pool_identifier_key>, received "),
+			expectError:         errors.New("expected <query>/<pool_identifier_value>/<pool_identifier_key> or <query>/<pool_selector>, received "),
 			name:                "empty input query",
 		},
 		{
 			inputQuery:          "invalid",
 			expectedOutputQuery: nil,
-			expectError:         errors.New("expected <query>/<pool_identifier_value>/<pool_identifier_key>, received invalid"),
+			expectError:         errors.New("expected <query>/<pool_identifier_value>/<pool_identifier_key> or <query>/<pool_selector>, received invalid"),
 			name:                "invalid input query format",
 		},
 		{
 			inputQuery:          "node_percentage-allocated_cpu/class",
 			expectedOutputQuery: nil,
-			expectError:         errors.New("expected <query>/<pool_identifier_value>/<pool_identifier_key>, received node_percentage-allocated_cpu/class"),
+			expectError:         errors.New("expected pool selector in the format <key>:<value>, received \"class\""),
 			name:                "missing node pool identifier value",
 		},
+		{
+			inputQuery:          "node_percentage-allocated_cpu/high-compute/rack",
+			expectedOutputQuery: nil,
+			expectError:         errors.New("invalid pool identifier key \"rack\", allowed values are class, dc or pool"),
+			name:                "invalid node pool identifier key",
+		},
+		{
+			inputQuery:          "node_percentage-allocated_cpu/pool:gpu,rack:r1",
+			expectedOutputQuery: nil,
+			expectError:         errors.New("invalid pool selector key \"rack\", allowed values are class, dc or pool"),
+			name:                "invalid node pool selector key",
+		},
+		{
+			inputQuery:          "node_percentage-allocated_cpu/pool:gpu,node_pool:cpu",
+			expectedOutputQuery: nil,
+			expectError:         errors.New("duplicate pool selector key \"node_pool\""),
+			name:                "duplicate node pool selector key",
+		},
 		{
 			inputQuery:          "node_percentage-allocated_invalid/class/high-compute",
 			expectedOutputQuery: nil,
-			expectError:         errors.New("invalid metric \"invalid\", allowed values are: cpu, memory"),
+			expectError:         errors.New("invalid metric \"invalid\", allowed values are: cpu, memory, gpu"),
 			name:                "invalid metric",
 		},
 		{
 			inputQuery:          "node_percentage-allocated_cpu-allocated/class/high-compute",
 			expectedOutputQuery: nil,
-			expectError:         errors.New("invalid metric \"cpu-allocated\", allowed values are: cpu, memory"),
+			expectError:         errors.New("invalid metric \"cpu-allocated\", allowed values are: cpu, memory, gpu"),
 			name:                "metric for task group queries only",
 		},
+		{
+			inputQuery:          "node_total_cpu/class/high-compute",
+			expectedOutputQuery: nil,
+			expectError:         errors.New("invalid metric \"cpu\", allowed values are: allocs-pending, allocs-queued, evals-blocked, nodes-required"),
+			name:                "resource metric for total operation",
+		},
 		{
 			inputQuery:          "node_invalid_cpu/class/high-compute",
 			expectedOutputQuery: nil,
-			expectError:         errors.New("invalid operation \"invalid\", allowed value is percentage-allocated"),
+			expectError:         errors.New("invalid operation \"invalid\", allowed values are percentage-allocated or total"),
 			name:                "invalid operation",
 		},
 	}
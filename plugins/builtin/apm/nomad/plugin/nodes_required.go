@@ -0,0 +1,154 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package plugin
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/hashicorp/nomad-autoscaler/sdk"
+	"github.com/hashicorp/nomad-autoscaler/sdk/helper/scaleutils/nodepool"
+	"github.com/hashicorp/nomad/api"
+)
+
+// queryNodesRequired calculates the number of nodes the pool requires to
+// place the allocations the Nomad scheduler has queued. It is the number of
+// nodes currently in the pool, plus the number of nodes with the average
+// allocatable resources of the pool needed to fit the queued allocations.
+//
+// The result is intended to be used with the pass-through strategy and
+// max_scale_down set to zero, providing fast scale-out which reacts to
+// scheduling failures rather than utilization averages.
+func (a *APMPlugin) queryNodesRequired(id nodepool.ClusterNodePoolIdentifier) (int, error) {
+
+	nodes, err := a.getPoolNodes(id)
+	if err != nil {
+		return 0, err
+	}
+
+	allocatable := &poolResources{}
+	for _, node := range nodes {
+		if err := a.getNodeAllocatableResources(node.ID, allocatable); err != nil {
+			return 0, fmt.Errorf("failed to get allocatable resources on node %s: %v", node.ID, err)
+		}
+	}
+
+	perNode := poolResources{
+		cpu: allocatable.cpu / int64(len(nodes)),
+		mem: allocatable.mem / int64(len(nodes)),
+	}
+
+	queued, err := a.getQueuedResources(id)
+	if err != nil {
+		return 0, err
+	}
+
+	additional, err := calculateNodesRequired(queued, perNode)
+	if err != nil {
+		return 0, err
+	}
+
+	a.logger.Debug("calculated nodes required for queued allocations",
+		"pool_nodes", len(nodes), "additional_nodes", additional,
+		"queued_cpu", queued.cpu, "queued_memory", queued.mem,
+		"node_cpu", perNode.cpu, "node_memory", perNode.mem)
+
+	return len(nodes) + additional, nil
+}
+
+// getQueuedResources sums the resources requested by the allocations which
+// the Nomad scheduler has not been able to place. When the pool is identified
+// by a Nomad node pool, jobs which target a different node pool are ignored
+// as new nodes would not help placing them.
+func (a *APMPlugin) getQueuedResources(id nodepool.ClusterNodePoolIdentifier) (*poolResources, error) {
+
+	jobs, _, err := a.client.Jobs().List(&api.QueryOptions{Namespace: api.AllNamespacesNamespace})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list Nomad jobs: %v", err)
+	}
+
+	queued := &poolResources{}
+
+	for _, stub := range jobs {
+		if stub.JobSummary == nil || !hasQueuedAllocs(stub.JobSummary) {
+			continue
+		}
+
+		job, _, err := a.client.Jobs().Info(stub.ID, &api.QueryOptions{Namespace: stub.Namespace})
+		if err != nil {
+			return nil, fmt.Errorf("failed to read Nomad job %s: %v", stub.ID, err)
+		}
+
+		if id.Key() == sdk.TargetConfigKeyNodePool && job.NodePool != nil && *job.NodePool != id.Value() {
+			continue
+		}
+
+		for _, tg := range job.TaskGroups {
+			if tg.Name == nil {
+				continue
+			}
+
+			summary, ok := stub.JobSummary.Summary[*tg.Name]
+			if !ok || summary.Queued == 0 {
+				continue
+			}
+
+			cpu, mem := taskGroupResources(tg)
+			queued.cpu += cpu * int64(summary.Queued)
+			queued.mem += mem * int64(summary.Queued)
+		}
+	}
+
+	return queued, nil
+}
+
+// hasQueuedAllocs returns whether any of the job groups has queued
+// allocations.
+func hasQueuedAllocs(summary *api.JobSummary) bool {
+	for _, tg := range summary.Summary {
+		if tg.Queued > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// taskGroupResources returns the CPU and memory requested by a single
+// allocation of the task group.
+func taskGroupResources(tg *api.TaskGroup) (int64, int64) {
+	var cpu, mem int64
+
+	for _, task := range tg.Tasks {
+		if task.Resources == nil {
+			continue
+		}
+		if task.Resources.CPU != nil {
+			cpu += int64(*task.Resources.CPU)
+		}
+		if task.Resources.MemoryMB != nil {
+			mem += int64(*task.Resources.MemoryMB)
+		}
+	}
+
+	return cpu, mem
+}
+
+// calculateNodesRequired returns the number of nodes, each with the perNode
+// resources, needed to fit the queued resources. The most constrained
+// resource dictates the result.
+func calculateNodesRequired(queued *poolResources, perNode poolResources) (int, error) {
+	if queued.cpu == 0 && queued.mem == 0 {
+		return 0, nil
+	}
+
+	if perNode.cpu <= 0 || perNode.mem <= 0 {
+		return 0, errors.New("zero allocatable resources found in pool")
+	}
+
+	cpuNodes := math.Ceil(float64(queued.cpu) / float64(perNode.cpu))
+	memNodes := math.Ceil(float64(queued.mem) / float64(perNode.mem))
+
+	return int(math.Max(cpuNodes, memNodes)), nil
+}
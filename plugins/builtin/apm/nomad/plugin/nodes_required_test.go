@@ -0,0 +1,70 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package plugin
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/hashicorp/nomad-autoscaler/sdk/helper/ptr"
+	"github.com/hashicorp/nomad/api"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_calculateNodesRequired(t *testing.T) {
+	testCases := []struct {
+		inputQueued    *poolResources
+		inputPerNode   poolResources
+		expectedOutput int
+		expectedError  error
+		name           string
+	}{
+		{
+			inputQueued:    &poolResources{},
+			inputPerNode:   poolResources{cpu: 4000, mem: 8192},
+			expectedOutput: 0,
+			name:           "nothing queued",
+		},
+		{
+			inputQueued:    &poolResources{cpu: 6000, mem: 1024},
+			inputPerNode:   poolResources{cpu: 4000, mem: 8192},
+			expectedOutput: 2,
+			name:           "cpu constrained",
+		},
+		{
+			inputQueued:    &poolResources{cpu: 1000, mem: 20000},
+			inputPerNode:   poolResources{cpu: 4000, mem: 8192},
+			expectedOutput: 3,
+			name:           "memory constrained",
+		},
+		{
+			inputQueued:   &poolResources{cpu: 1000, mem: 1024},
+			inputPerNode:  poolResources{},
+			expectedError: errors.New("zero allocatable resources found in pool"),
+			name:          "empty node shape",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actualOutput, actualError := calculateNodesRequired(tc.inputQueued, tc.inputPerNode)
+			assert.Equal(t, tc.expectedOutput, actualOutput, tc.name)
+			assert.Equal(t, tc.expectedError, actualError, tc.name)
+		})
+	}
+}
+
+func Test_taskGroupResources(t *testing.T) {
+	tg := &api.TaskGroup{
+		Tasks: []*api.Task{
+			{Resources: &api.Resources{CPU: ptr.Of(500), MemoryMB: ptr.Of(256)}},
+			{Resources: &api.Resources{CPU: ptr.Of(100)}},
+			{},
+		},
+	}
+
+	cpu, mem := taskGroupResources(tg)
+	assert.Equal(t, int64(600), cpu)
+	assert.Equal(t, int64(256), mem)
+}
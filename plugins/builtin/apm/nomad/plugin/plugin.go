@@ -39,6 +39,10 @@ var (
 type APMPlugin struct {
 	client *api.Client
 	logger hclog.Logger
+
+	// consul is the configuration used by service queries to access the
+	// Consul HTTP API.
+	consul *consulConfig
 }
 
 func NewNomadPlugin(log hclog.Logger) apm.APM {
@@ -56,6 +60,7 @@ func (a *APMPlugin) SetConfig(config map[string]string) error {
 		return fmt.Errorf("failed to instantiate Nomad client: %v", err)
 	}
 	a.client = client
+	a.consul = consulConfigFromMap(config)
 
 	return nil
 }
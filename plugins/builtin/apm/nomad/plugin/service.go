@@ -0,0 +1,354 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package plugin
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/nomad-autoscaler/sdk"
+	"github.com/hashicorp/nomad/api"
+)
+
+const (
+	// configKeyConsulAddress and configKeyConsulToken are the plugin config
+	// keys used to access the Consul HTTP API for service queries.
+	configKeyConsulAddress = "consul_address"
+	configKeyConsulToken   = "consul_token"
+
+	// configKeyConsulLatencyMetaKey is the plugin config key that defines the
+	// Consul service meta key holding the request latency of each instance.
+	configKeyConsulLatencyMetaKey = "consul_latency_meta_key"
+
+	defaultConsulAddress        = "http://127.0.0.1:8500"
+	defaultConsulLatencyMetaKey = "latency_ms"
+	defaultConsulRequestTimeout = 10 * time.Second
+
+	// consulServiceIDPrefix is the prefix of the ID of the services
+	// registered in Consul by Nomad tasks.
+	consulServiceIDPrefix = "_nomad-task-"
+
+	// consulCheckStatusPassing, nomadCheckStatusSuccess and
+	// nomadCheckStatusFailure are the health check statuses used by the
+	// service queries.
+	consulCheckStatusPassing = "passing"
+	nomadCheckStatusSuccess  = "success"
+	nomadCheckStatusFailure  = "failure"
+)
+
+// serviceQuery is the plugins internal representation of a query and contains
+// all the information needed to perform a Nomad APM query for a service.
+type serviceQuery struct {
+	metric    string
+	namespace string
+	job       string
+	service   string
+	operation string
+}
+
+// consulConfig holds the configuration used to access the Consul HTTP API.
+type consulConfig struct {
+	address        string
+	token          string
+	latencyMetaKey string
+}
+
+// consulConfigFromMap reads the Consul configuration from the plugin config.
+func consulConfigFromMap(config map[string]string) *consulConfig {
+	cfg := &consulConfig{
+		address:        defaultConsulAddress,
+		token:          config[configKeyConsulToken],
+		latencyMetaKey: defaultConsulLatencyMetaKey,
+	}
+	if addr := config[configKeyConsulAddress]; addr != "" {
+		cfg.address = strings.TrimSuffix(addr, "/")
+	}
+	if key := config[configKeyConsulLatencyMetaKey]; key != "" {
+		cfg.latencyMetaKey = key
+	}
+	return cfg
+}
+
+// consulServiceEntry is the subset of the Consul health service response used
+// by the plugin.
+type consulServiceEntry struct {
+	Service struct {
+		ID   string
+		Meta map[string]string
+	}
+	Checks []struct {
+		CheckID string
+		Status  string
+	}
+}
+
+// queryService is the main entry point when performing a Nomad service APM
+// query.
+func (a *APMPlugin) queryService(q string) (sdk.TimestampedMetrics, error) {
+
+	// Parse our query to ensure we have all the information required to
+	// continue.
+	query, err := parseServiceQuery(q)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse query: %v", err)
+	}
+	a.logger.Debug("expanded query", "from", q, "to", fmt.Sprintf("%# v", query))
+
+	// All metrics are limited to the running allocations of the job, so
+	// instances of the service registered by other jobs are ignored.
+	allocIDs, err := a.getRunningAllocIDs(query)
+	if err != nil {
+		return nil, err
+	}
+
+	var metrics []float64
+
+	switch query.metric {
+	case queryMetricCheckFailureRate:
+		metrics, err = a.getServiceCheckFailureRates(query, allocIDs)
+	default:
+		var entries []*consulServiceEntry
+		entries, err = a.getConsulServiceEntries(query.service, allocIDs)
+		if err == nil {
+			metrics, err = consulServiceMetrics(query.metric, a.consul.latencyMetaKey, entries)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if len(metrics) == 0 {
+		return nil, fmt.Errorf("metric not found: %s", q)
+	}
+	a.logger.Debug("metrics found", "num_data_points", len(metrics), "query", q)
+
+	return calculateTaskGroupResult(query.operation, metrics), nil
+}
+
+// getRunningAllocIDs returns the IDs of the running allocations of the job.
+func (a *APMPlugin) getRunningAllocIDs(query *serviceQuery) (map[string]bool, error) {
+	allocs, _, err := a.client.Jobs().Allocations(query.job, false, &api.QueryOptions{Namespace: query.namespace})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get alloc listing for job: %v", err)
+	}
+
+	ids := make(map[string]bool, len(allocs))
+	for _, alloc := range allocs {
+		if alloc.ClientStatus == api.AllocClientStatusRunning {
+			ids[alloc.ID] = true
+		}
+	}
+	return ids, nil
+}
+
+// getServiceCheckFailureRates returns the percentage of failing Nomad checks
+// of the service for each allocation.
+func (a *APMPlugin) getServiceCheckFailureRates(query *serviceQuery, allocIDs map[string]bool) ([]float64, error) {
+	var resp []float64
+
+	for allocID := range allocIDs {
+		checks, err := a.client.Allocations().Checks(allocID, &api.QueryOptions{Namespace: query.namespace})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get alloc checks: %v", err)
+		}
+
+		if rate, ok := checkFailureRate(query.service, checks); ok {
+			resp = append(resp, rate)
+		}
+	}
+	return resp, nil
+}
+
+// checkFailureRate returns the percentage of the checks of the service that
+// are failing. Pending checks are ignored. The boolean return indicates
+// whether any check results were found.
+func checkFailureRate(service string, checks api.AllocCheckStatuses) (float64, bool) {
+	var total, failed int
+
+	for _, check := range checks {
+		if check.Service != service {
+			continue
+		}
+
+		switch check.Status {
+		case nomadCheckStatusFailure:
+			failed++
+			total++
+		case nomadCheckStatusSuccess:
+			total++
+		}
+	}
+
+	if total == 0 {
+		return 0, false
+	}
+	return float64(failed) / float64(total) * 100, true
+}
+
+// getConsulServiceEntries returns the Consul health entries of the service
+// instances registered by the allocations.
+func (a *APMPlugin) getConsulServiceEntries(service string, allocIDs map[string]bool) ([]*consulServiceEntry, error) {
+	if a.consul == nil {
+		return nil, fmt.Errorf("consul is not configured")
+	}
+
+	u := fmt.Sprintf("%s/v1/health/service/%s", a.consul.address, url.PathEscape(service))
+	req, err := http.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Consul request: %v", err)
+	}
+	if a.consul.token != "" {
+		req.Header.Set("X-Consul-Token", a.consul.token)
+	}
+
+	client := &http.Client{Timeout: defaultConsulRequestTimeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query Consul service health: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to query Consul service health: unexpected status code %d", resp.StatusCode)
+	}
+
+	var entries []*consulServiceEntry
+	if err := json.NewDecoder(resp.Body).Decode(&entries); err != nil {
+		return nil, fmt.Errorf("failed to decode Consul service health: %v", err)
+	}
+
+	// Nomad registers services in Consul with an ID that includes the
+	// allocation ID, which is used to identify the instances of the job.
+	var out []*consulServiceEntry
+	for _, entry := range entries {
+		if allocIDs[consulServiceAllocID(entry.Service.ID)] {
+			out = append(out, entry)
+		}
+	}
+	return out, nil
+}
+
+// consulServiceAllocID returns the ID of the allocation which registered the
+// Consul service, or an empty string if it wasn't registered by Nomad. Nomad
+// service IDs have the format _nomad-task-<alloc_id>-<task>-<service>-<port>.
+func consulServiceAllocID(serviceID string) string {
+	if !strings.HasPrefix(serviceID, consulServiceIDPrefix) {
+		return ""
+	}
+
+	// Allocation IDs are UUIDs, so they have a fixed length.
+	id := strings.TrimPrefix(serviceID, consulServiceIDPrefix)
+	if len(id) < 36 {
+		return ""
+	}
+	return id[:36]
+}
+
+// consulServiceMetrics returns the metric for each Consul service instance.
+// Instances without a valid value for the metric are skipped.
+func consulServiceMetrics(metric, latencyMetaKey string, entries []*consulServiceEntry) ([]float64, error) {
+	var resp []float64
+
+	for _, entry := range entries {
+		switch metric {
+		case queryMetricConsulCheckFailureRate:
+			if len(entry.Checks) == 0 {
+				continue
+			}
+
+			var failed int
+			for _, check := range entry.Checks {
+				if check.Status != consulCheckStatusPassing {
+					failed++
+				}
+			}
+			resp = append(resp, float64(failed)/float64(len(entry.Checks))*100)
+
+		case queryMetricLatency:
+			raw, ok := entry.Service.Meta[latencyMetaKey]
+			if !ok {
+				continue
+			}
+
+			latency, err := strconv.ParseFloat(raw, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid value %q for service meta %q of instance %s",
+					raw, latencyMetaKey, entry.Service.ID)
+			}
+			resp = append(resp, latency)
+		}
+	}
+	return resp, nil
+}
+
+// parseServiceQuery takes the query string and transforms it into our
+// internal query representation. Parsing validates that the returned query is
+// usable by all subsequent calls but cannot ensure the service or job will
+// actually be found on the cluster.
+func parseServiceQuery(q string) (*serviceQuery, error) {
+	mainParts := strings.SplitN(q, "/", 3)
+	if len(mainParts) != 3 {
+		return nil, fmt.Errorf("expected <query>/<service>/<job>@<namespace>, received %s", q)
+	}
+
+	nsJob := mainParts[2]
+	nsJobSepIdx := strings.LastIndex(nsJob, "@")
+	if nsJobSepIdx == -1 {
+		return nil, fmt.Errorf("missing namespace from query %s", q)
+	}
+
+	ns := nsJob[nsJobSepIdx+1:]
+	if len(ns) == 0 {
+		return nil, fmt.Errorf("missing namespace from query %s", q)
+	}
+
+	job := nsJob[:nsJobSepIdx]
+	if len(job) == 0 {
+		return nil, fmt.Errorf("missing job from query %s", q)
+	}
+
+	if len(mainParts[1]) == 0 {
+		return nil, fmt.Errorf("missing service from query %s", q)
+	}
+
+	query := &serviceQuery{
+		service:   mainParts[1],
+		job:       job,
+		namespace: ns,
+	}
+
+	opMetricParts := strings.SplitN(mainParts[0], "_", 3)
+	if len(opMetricParts) != 3 {
+		return nil, fmt.Errorf(`expected service_<operation>_<metric>, received "%s"`, mainParts[0])
+	}
+
+	op := opMetricParts[1]
+	metric := opMetricParts[2]
+
+	if err := validateMetricServiceQuery(metric); err != nil {
+		return nil, err
+	}
+	query.metric = metric
+
+	switch op {
+	case queryOpSum, queryOpAvg, queryOpMin, queryOpMax:
+		query.operation = op
+	default:
+		return nil, fmt.Errorf(`invalid operation %q, allowed values are %s, %s, %s or %s`,
+			op, queryOpSum, queryOpAvg, queryOpMin, queryOpMax)
+	}
+
+	return query, nil
+}
+
+func validateMetricServiceQuery(metric string) error {
+	return validateMetric(metric, []string{
+		queryMetricCheckFailureRate, queryMetricConsulCheckFailureRate, queryMetricLatency,
+	})
+}
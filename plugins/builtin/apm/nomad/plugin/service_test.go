@@ -0,0 +1,168 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package plugin
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	hclog "github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/nomad/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_parseServiceQuery(t *testing.T) {
+	testCases := []struct {
+		name        string
+		input       string
+		expected    *serviceQuery
+		expectError bool
+	}{
+		{
+			name:  "avg_check-failure-rate",
+			input: "service_avg_check-failure-rate/web/job@default",
+			expected: &serviceQuery{
+				metric:    "check-failure-rate",
+				namespace: "default",
+				job:       "job",
+				service:   "web",
+				operation: "avg",
+			},
+		},
+		{
+			name:  "max_consul-check-failure-rate",
+			input: "service_max_consul-check-failure-rate/web/job@dev",
+			expected: &serviceQuery{
+				metric:    "consul-check-failure-rate",
+				namespace: "dev",
+				job:       "job",
+				service:   "web",
+				operation: "max",
+			},
+		},
+		{
+			name:  "avg_latency",
+			input: "service_avg_latency/web/job@dev",
+			expected: &serviceQuery{
+				metric:    "latency",
+				namespace: "dev",
+				job:       "job",
+				service:   "web",
+				operation: "avg",
+			},
+		},
+		{
+			name:        "missing service",
+			input:       "service_avg_latency//job@default",
+			expectError: true,
+		},
+		{
+			name:        "missing namespace",
+			input:       "service_avg_latency/web/job",
+			expectError: true,
+		},
+		{
+			name:        "invalid metric",
+			input:       "service_avg_cpu/web/job@default",
+			expectError: true,
+		},
+		{
+			name:        "invalid operation",
+			input:       "service_op_latency/web/job@default",
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual, err := parseServiceQuery(tc.input)
+
+			assert.Equal(t, tc.expected, actual)
+
+			if tc.expectError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func Test_checkFailureRate(t *testing.T) {
+	checks := api.AllocCheckStatuses{
+		"1": {Service: "web", Status: "success"},
+		"2": {Service: "web", Status: "failure"},
+		"3": {Service: "web", Status: "success"},
+		"4": {Service: "web", Status: "failure"},
+		"5": {Service: "web", Status: "pending"},
+		"6": {Service: "db", Status: "failure"},
+	}
+
+	rate, ok := checkFailureRate("web", checks)
+	assert.True(t, ok)
+	assert.Equal(t, 50.0, rate)
+
+	_, ok = checkFailureRate("cache", checks)
+	assert.False(t, ok)
+}
+
+func Test_consulServiceAllocID(t *testing.T) {
+	allocID := "0b9b7d70-0b47-7c8d-7a40-6c4bc6b1a6fa"
+	assert.Equal(t, allocID, consulServiceAllocID("_nomad-task-"+allocID+"-web-web-http"))
+	assert.Empty(t, consulServiceAllocID("web-1"))
+	assert.Empty(t, consulServiceAllocID("_nomad-task-short"))
+}
+
+func TestAPMPlugin_queryConsulService(t *testing.T) {
+	allocID := "0b9b7d70-0b47-7c8d-7a40-6c4bc6b1a6fa"
+	otherAllocID := "9f1c2e53-4c57-40a9-8ef6-2b2b0f4e2d11"
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/health/service/web", r.URL.Path)
+		assert.Equal(t, "secret", r.Header.Get("X-Consul-Token"))
+		_, _ = w.Write([]byte(`[
+  {
+    "Service": {"ID": "_nomad-task-` + allocID + `-web-web-http", "Meta": {"latency_ms": "120.5"}},
+    "Checks": [{"CheckID": "serfHealth", "Status": "passing"}, {"CheckID": "http", "Status": "critical"}]
+  },
+  {
+    "Service": {"ID": "_nomad-task-` + otherAllocID + `-web-web-http", "Meta": {"latency_ms": "900"}},
+    "Checks": [{"CheckID": "http", "Status": "critical"}]
+  },
+  {
+    "Service": {"ID": "web-external", "Meta": {"latency_ms": "10"}},
+    "Checks": [{"CheckID": "http", "Status": "passing"}]
+  }
+]`))
+	}))
+	defer srv.Close()
+
+	a := &APMPlugin{
+		logger: hclog.NewNullLogger(),
+		consul: consulConfigFromMap(map[string]string{
+			"consul_address": srv.URL + "/",
+			"consul_token":   "secret",
+		}),
+	}
+
+	// Only the instances of the job allocations are used.
+	entries, err := a.getConsulServiceEntries("web", map[string]bool{allocID: true})
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+
+	latency, err := consulServiceMetrics(queryMetricLatency, a.consul.latencyMetaKey, entries)
+	require.NoError(t, err)
+	assert.Equal(t, []float64{120.5}, latency)
+
+	failures, err := consulServiceMetrics(queryMetricConsulCheckFailureRate, a.consul.latencyMetaKey, entries)
+	require.NoError(t, err)
+	assert.Equal(t, []float64{50}, failures)
+
+	// Instances without the meta key are skipped.
+	noMeta, err := consulServiceMetrics(queryMetricLatency, "p99_ms", entries)
+	require.NoError(t, err)
+	assert.Empty(t, noMeta)
+}
@@ -0,0 +1,19 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package main
+
+import (
+	hclog "github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/nomad-autoscaler/plugins"
+	opencost "github.com/hashicorp/nomad-autoscaler/plugins/builtin/apm/opencost/plugin"
+)
+
+func main() {
+	plugins.Serve(factory)
+}
+
+// factory returns a new instance of the OpenCost APM plugin.
+func factory(log hclog.Logger) interface{} {
+	return opencost.NewOpenCostPlugin(log)
+}
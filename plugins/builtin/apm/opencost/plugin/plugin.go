@@ -0,0 +1,283 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package plugin
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	hclog "github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/nomad-autoscaler/plugins"
+	"github.com/hashicorp/nomad-autoscaler/plugins/apm"
+	"github.com/hashicorp/nomad-autoscaler/plugins/base"
+	"github.com/hashicorp/nomad-autoscaler/sdk"
+	"github.com/hashicorp/nomad-autoscaler/sdk/helper/pluginconfig"
+)
+
+const (
+	// pluginName is the unique name of the this plugin amongst APM plugins.
+	pluginName = "opencost"
+
+	// configKeys represents the known configuration parameters required at
+	// varying points throughout the plugins lifecycle.
+	configKeyAddress   = "address"
+	configKeyToken     = "token"
+	configKeyTimeout   = "timeout"
+	configKeyStep      = "step"
+	configKeyMinWindow = "min_window"
+
+	// configValues are the default values used when a configuration key is not
+	// supplied by the operator that are specific to the plugin.
+	configValueAddressDefault   = "http://127.0.0.1:9003"
+	configValueTimeoutDefault   = "30s"
+	configValueStepDefault      = "1h"
+	configValueMinWindowDefault = "1h"
+)
+
+var (
+	PluginID = plugins.PluginID{
+		Name:       pluginName,
+		PluginType: sdk.PluginTypeAPM,
+	}
+
+	PluginConfig = &plugins.InternalPluginConfig{
+		Factory: func(l hclog.Logger) interface{} { return NewOpenCostPlugin(l) },
+	}
+
+	pluginInfo = &base.PluginInfo{
+		Name:       pluginName,
+		PluginType: sdk.PluginTypeAPM,
+	}
+)
+
+// Assert that APMPlugin meets the apm.APM interface.
+var _ apm.APM = (*APMPlugin)(nil)
+
+// APMPlugin is the OpenCost implementation of the apm.APM interface. Costs
+// are read from the OpenCost allocation, assets and cloud cost APIs, so the
+// spend of a namespace or node, or the spend reported by the cloud provider
+// billing exports, can be used by policies alongside utilization metrics.
+// Costs are returned as hourly rates.
+type APMPlugin struct {
+	config map[string]string
+	logger hclog.Logger
+
+	client    *http.Client
+	address   string
+	token     string
+	step      time.Duration
+	minWindow time.Duration
+}
+
+// NewOpenCostPlugin returns the OpenCost implementation of the apm.APM
+// interface.
+func NewOpenCostPlugin(log hclog.Logger) apm.APM {
+	return &APMPlugin{
+		logger: log,
+	}
+}
+
+// SetConfig satisfies the SetConfig function on the base.Base interface.
+func (a *APMPlugin) SetConfig(config map[string]string) error {
+
+	addr := pluginconfig.GetValue(config, configKeyAddress, configValueAddressDefault)
+	if _, err := url.ParseRequestURI(addr); err != nil {
+		return fmt.Errorf("failed to parse `%s`: %v", configKeyAddress, err)
+	}
+
+	timeout, err := time.ParseDuration(pluginconfig.GetValue(config, configKeyTimeout, configValueTimeoutDefault))
+	if err != nil {
+		return fmt.Errorf("failed to parse `%s`: %v", configKeyTimeout, err)
+	}
+
+	step, err := time.ParseDuration(pluginconfig.GetValue(config, configKeyStep, configValueStepDefault))
+	if err != nil {
+		return fmt.Errorf("failed to parse `%s`: %v", configKeyStep, err)
+	}
+	if step < time.Minute {
+		return fmt.Errorf("`%s` must be at least 1m", configKeyStep)
+	}
+
+	minWindow, err := time.ParseDuration(pluginconfig.GetValue(config, configKeyMinWindow, configValueMinWindowDefault))
+	if err != nil {
+		return fmt.Errorf("failed to parse `%s`: %v", configKeyMinWindow, err)
+	}
+	if minWindow < 0 {
+		return fmt.Errorf("`%s` must not be negative", configKeyMinWindow)
+	}
+
+	a.config = config
+	a.client = &http.Client{Timeout: timeout}
+	a.address = strings.TrimSuffix(addr, "/")
+	a.token = config[configKeyToken]
+	a.step = step
+	a.minWindow = minWindow
+
+	return nil
+}
+
+// PluginInfo satisfies the PluginInfo function on the base.Base interface.
+func (a *APMPlugin) PluginInfo() (*base.PluginInfo, error) {
+	return pluginInfo, nil
+}
+
+// Query satisfies the Query function on the apm.APM interface. A data point
+// is returned for each window reported by OpenCost, timestamped with the end
+// of the window. OpenCost computes costs at a coarse resolution, so time
+// ranges shorter than the min_window config are extended back in time.
+func (a *APMPlugin) Query(q string, r sdk.TimeRange) (sdk.TimestampedMetrics, error) {
+	if a.client == nil {
+		return nil, fmt.Errorf("plugin is not configured")
+	}
+
+	query, err := parseQuery(q)
+	if err != nil {
+		return nil, err
+	}
+
+	from := r.From
+	if r.To.Sub(from) < a.minWindow {
+		from = r.To.Add(-a.minWindow)
+	}
+
+	sets, err := a.getSets(query, from, r.To)
+	if err != nil {
+		return nil, err
+	}
+
+	var result sdk.TimestampedMetrics
+	for _, set := range sets {
+		item, ok := set[query.name]
+		if !ok || item == nil {
+			continue
+		}
+
+		value, ok := query.metric.extract(item)
+		if !ok {
+			continue
+		}
+
+		ts := item.Window.End
+		if ts.IsZero() {
+			ts = r.To
+		}
+		result = append(result, sdk.TimestampedMetric{Timestamp: ts, Value: value})
+	}
+	sort.Sort(result)
+
+	if len(result) == 0 {
+		a.logger.Warn("no costs found in OpenCost response", "query", q)
+	}
+	return result, nil
+}
+
+// QueryMultiple satisfies the QueryMultiple function on the apm.APM
+// interface. Queries always target a single aggregated item, so a single
+// series is returned.
+func (a *APMPlugin) QueryMultiple(q string, r sdk.TimeRange) ([]sdk.TimestampedMetrics, error) {
+	m, err := a.Query(q, r)
+	if err != nil {
+		return nil, err
+	}
+	return []sdk.TimestampedMetrics{m}, nil
+}
+
+// response is the envelope of the OpenCost API responses.
+type response struct {
+	Code    int             `json:"code"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data"`
+}
+
+// cloudCostData is the data returned by the cloud cost API.
+type cloudCostData struct {
+	Sets []struct {
+		CloudCosts costSet `json:"cloudCosts"`
+	} `json:"sets"`
+}
+
+// getSets reads the cost sets of the window from the API targeted by the
+// query.
+func (a *APMPlugin) getSets(q *query, from, to time.Time) ([]costSet, error) {
+	data, err := a.get(q.path(from, to, a.step))
+	if err != nil {
+		return nil, err
+	}
+
+	switch q.kind {
+	case queryKindCloudCost:
+		var cc cloudCostData
+		if err := json.Unmarshal(data, &cc); err != nil {
+			return nil, fmt.Errorf("failed to parse response: %v", err)
+		}
+		sets := make([]costSet, 0, len(cc.Sets))
+		for _, s := range cc.Sets {
+			sets = append(sets, s.CloudCosts)
+		}
+		return sets, nil
+
+	default:
+		// Accumulated responses hold a single set instead of one per step.
+		if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '{' {
+			var set costSet
+			if err := json.Unmarshal(trimmed, &set); err != nil {
+				return nil, fmt.Errorf("failed to parse response: %v", err)
+			}
+			return []costSet{set}, nil
+		}
+
+		var sets []costSet
+		if err := json.Unmarshal(data, &sets); err != nil {
+			return nil, fmt.Errorf("failed to parse response: %v", err)
+		}
+		return sets, nil
+	}
+}
+
+// get performs a GET request against the OpenCost API and returns the data
+// of the response.
+func (a *APMPlugin) get(path string) (json.RawMessage, error) {
+	req, err := http.NewRequest(http.MethodGet, a.address+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if a.token != "" {
+		req.Header.Set("Authorization", "Bearer "+a.token)
+	}
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query OpenCost: %v", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %v", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to query OpenCost: unexpected response code %d: %s",
+			resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var out response
+	if err := json.Unmarshal(body, &out); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %v", err)
+	}
+
+	// OpenCost reports some errors in the response body.
+	if out.Code != 0 && out.Code != http.StatusOK {
+		return nil, fmt.Errorf("failed to query OpenCost: unexpected response code %d: %s", out.Code, out.Message)
+	}
+	return out.Data, nil
+}
@@ -0,0 +1,255 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package plugin
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	hclog "github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/nomad-autoscaler/sdk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPMPlugin_SetConfig(t *testing.T) {
+	testCases := []struct {
+		name          string
+		config        map[string]string
+		expectedError string
+	}{
+		{
+			name:   "defaults",
+			config: map[string]string{},
+		},
+		{
+			name:          "invalid address",
+			config:        map[string]string{"address": "not a url"},
+			expectedError: "failed to parse `address`",
+		},
+		{
+			name:          "invalid timeout",
+			config:        map[string]string{"timeout": "soon"},
+			expectedError: "failed to parse `timeout`",
+		},
+		{
+			name:          "step too small",
+			config:        map[string]string{"step": "30s"},
+			expectedError: "`step` must be at least 1m",
+		},
+		{
+			name:          "negative min window",
+			config:        map[string]string{"min_window": "-1h"},
+			expectedError: "`min_window` must not be negative",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			p := NewOpenCostPlugin(hclog.NewNullLogger())
+			err := p.SetConfig(tc.config)
+			if tc.expectedError != "" {
+				assert.ErrorContains(t, err, tc.expectedError)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func Test_parseQuery(t *testing.T) {
+	testCases := []struct {
+		name              string
+		query             string
+		expectedKind      string
+		expectedAggregate string
+		expectedName      string
+		expectedError     string
+	}{
+		{
+			name:              "allocation",
+			query:             "allocation/namespace/default/total_cost",
+			expectedKind:      "allocation",
+			expectedAggregate: "namespace",
+			expectedName:      "default",
+		},
+		{
+			name:              "name with slashes",
+			query:             "allocation/cluster,namespace/cluster-1/default/cpu_efficiency",
+			expectedKind:      "allocation",
+			expectedAggregate: "cluster,namespace",
+			expectedName:      "cluster-1/default",
+		},
+		{
+			name:              "assets",
+			query:             "assets/node/client-1/ram_cost",
+			expectedKind:      "assets",
+			expectedAggregate: "node",
+			expectedName:      "client-1",
+		},
+		{
+			name:              "cloud cost",
+			query:             "cloudcost/service/AmazonEC2/net_cost",
+			expectedKind:      "cloudcost",
+			expectedAggregate: "service",
+			expectedName:      "AmazonEC2",
+		},
+		{
+			name:          "unsupported type",
+			query:         "budget/namespace/default/total_cost",
+			expectedError: `unsupported type "budget"`,
+		},
+		{
+			name:          "missing metric",
+			query:         "allocation/namespace/default",
+			expectedError: "expected format <kind>/<aggregate>/<name>/<metric>",
+		},
+		{
+			name:          "missing aggregate",
+			query:         "allocation//default/total_cost",
+			expectedError: "missing aggregate",
+		},
+		{
+			name:          "unsupported metric",
+			query:         "assets/node/client-1/cpu_efficiency",
+			expectedError: `unsupported assets metric "cpu_efficiency"`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual, err := parseQuery(tc.query)
+			if tc.expectedError != "" {
+				assert.ErrorContains(t, err, tc.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedKind, actual.kind)
+			assert.Equal(t, tc.expectedAggregate, actual.aggregate)
+			assert.Equal(t, tc.expectedName, actual.name)
+		})
+	}
+}
+
+func TestAPMPlugin_Query(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	responses := map[string]string{
+		"/allocation": `{"code": 200, "data": [
+			{"default": {"window": {"start": "2024-06-01T10:00:00Z", "end": "2024-06-01T11:00:00Z"}, "totalCost": 2, "cpuEfficiency": 0.5}},
+			{"default": {"window": {"start": "2024-06-01T11:00:00Z", "end": "2024-06-01T12:00:00Z"}, "totalCost": 3, "cpuEfficiency": 0.75}},
+			{"other": {"window": {"start": "2024-06-01T11:00:00Z", "end": "2024-06-01T12:00:00Z"}, "totalCost": 10}}
+		]}`,
+		"/assets": `{"code": 200, "data": {
+			"client-1": {"type": "Node", "window": {"start": "2024-06-01T10:00:00Z", "end": "2024-06-01T12:00:00Z"}, "cpuCost": 1, "ramCost": 0.5, "totalCost": 1.5}
+		}}`,
+		"/cloudCost": `{"code": 200, "data": {"sets": [
+			{"cloudCosts": {"AmazonEC2": {"window": {"start": "2024-05-31T00:00:00Z", "end": "2024-06-01T00:00:00Z"}, "netCost": {"cost": 48}}}}
+		]}}`,
+	}
+
+	var requests []*http.Request
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r)
+		if r.URL.Query().Get("aggregate") == "error" {
+			_, _ = w.Write([]byte(`{"code": 400, "message": "invalid aggregation"}`))
+			return
+		}
+		body, ok := responses[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(body))
+	}))
+	defer srv.Close()
+
+	testCases := []struct {
+		name           string
+		query          string
+		timeRange      sdk.TimeRange
+		expected       sdk.TimestampedMetrics
+		expectedParams url.Values
+		expectedError  string
+	}{
+		{
+			name:      "allocation costs per step",
+			query:     "allocation/namespace/default/total_cost",
+			timeRange: sdk.TimeRange{From: now.Add(-2 * time.Hour), To: now},
+			expected: sdk.TimestampedMetrics{
+				{Timestamp: now.Add(-time.Hour), Value: 2},
+				{Timestamp: now, Value: 3},
+			},
+			expectedParams: url.Values{
+				"window":     {"2024-06-01T10:00:00Z,2024-06-01T12:00:00Z"},
+				"aggregate":  {"namespace"},
+				"step":       {"1h0m0s"},
+				"accumulate": {"false"},
+			},
+		},
+		{
+			name:      "allocation efficiency is not converted",
+			query:     "allocation/namespace/default/cpu_efficiency",
+			timeRange: sdk.TimeRange{From: now.Add(-2 * time.Hour), To: now},
+			expected: sdk.TimestampedMetrics{
+				{Timestamp: now.Add(-time.Hour), Value: 0.5},
+				{Timestamp: now, Value: 0.75},
+			},
+		},
+		{
+			name:      "asset costs are hourly",
+			query:     "assets/node/client-1/total_cost",
+			timeRange: sdk.TimeRange{From: now.Add(-2 * time.Hour), To: now},
+			expected:  sdk.TimestampedMetrics{{Timestamp: now, Value: 0.75}},
+		},
+		{
+			name:      "short ranges use the min window",
+			query:     "cloudcost/service/AmazonEC2/net_cost",
+			timeRange: sdk.TimeRange{From: now.Add(-time.Minute), To: now},
+			expected:  sdk.TimestampedMetrics{{Timestamp: now.Add(-12 * time.Hour), Value: 2}},
+			expectedParams: url.Values{
+				"window":    {"2024-06-01T11:00:00Z,2024-06-01T12:00:00Z"},
+				"aggregate": {"service"},
+			},
+		},
+		{
+			name:      "missing item",
+			query:     "assets/node/client-2/total_cost",
+			timeRange: sdk.TimeRange{From: now.Add(-2 * time.Hour), To: now},
+			expected:  nil,
+		},
+		{
+			name:          "error in body",
+			query:         "allocation/error/default/total_cost",
+			timeRange:     sdk.TimeRange{From: now.Add(-2 * time.Hour), To: now},
+			expectedError: "unexpected response code 400: invalid aggregation",
+		},
+	}
+
+	p := NewOpenCostPlugin(hclog.NewNullLogger())
+	require.NoError(t, p.SetConfig(map[string]string{"address": srv.URL, "token": "secret"}))
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			requests = nil
+
+			actual, err := p.Query(tc.query, tc.timeRange)
+			if tc.expectedError != "" {
+				assert.ErrorContains(t, err, tc.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, actual)
+
+			require.Len(t, requests, 1)
+			assert.Equal(t, "Bearer secret", requests[0].Header.Get("Authorization"))
+			if tc.expectedParams != nil {
+				assert.Equal(t, tc.expectedParams, requests[0].URL.Query())
+			}
+		})
+	}
+}
@@ -0,0 +1,209 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package plugin
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	// The OpenCost APIs that can be queried.
+	queryKindAllocation = "allocation"
+	queryKindAssets     = "assets"
+	queryKindCloudCost  = "cloudcost"
+)
+
+// query is the parsed representation of an OpenCost APM query. Queries use
+// the format <kind>/<aggregate>/<name>/<metric>, for example
+// allocation/namespace/default/total_cost or assets/node/client-1/total_cost.
+// The name is the key OpenCost uses for the aggregated item, which may
+// contain slashes when aggregating by multiple properties.
+type query struct {
+	kind      string
+	aggregate string
+	name      string
+	metric    *metric
+}
+
+// parseQuery parses and validates the input query.
+func parseQuery(q string) (*query, error) {
+	parts := strings.SplitN(q, "/", 3)
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("invalid query %q, expected format <kind>/<aggregate>/<name>/<metric>", q)
+	}
+	kind, aggregate, rest := parts[0], parts[1], parts[2]
+
+	metrics, ok := kindMetrics[kind]
+	if !ok {
+		return nil, fmt.Errorf("invalid query %q, unsupported type %q, must be %q, %q or %q",
+			q, kind, queryKindAllocation, queryKindAssets, queryKindCloudCost)
+	}
+	if aggregate == "" {
+		return nil, fmt.Errorf("invalid query %q, missing aggregate", q)
+	}
+
+	// The metric is always the last element, which allows names to contain
+	// slashes.
+	idx := strings.LastIndex(rest, "/")
+	if idx <= 0 {
+		return nil, fmt.Errorf("invalid query %q, expected format <kind>/<aggregate>/<name>/<metric>", q)
+	}
+	name, metricName := rest[:idx], rest[idx+1:]
+
+	m, ok := metrics[metricName]
+	if !ok {
+		return nil, fmt.Errorf("invalid query %q, unsupported %s metric %q", q, kind, metricName)
+	}
+
+	return &query{
+		kind:      kind,
+		aggregate: aggregate,
+		name:      name,
+		metric:    m,
+	}, nil
+}
+
+// path returns the OpenCost API path and parameters used to read the costs
+// of the window. Allocations are split into steps so a data point is
+// returned for each of them.
+func (q *query) path(from, to time.Time, step time.Duration) string {
+	params := url.Values{}
+	params.Set("window", from.UTC().Format(time.RFC3339)+","+to.UTC().Format(time.RFC3339))
+	params.Set("aggregate", q.aggregate)
+
+	var path string
+	switch q.kind {
+	case queryKindAllocation:
+		path = "/allocation"
+		params.Set("step", step.String())
+		params.Set("accumulate", "false")
+	case queryKindAssets:
+		path = "/assets"
+		params.Set("accumulate", "true")
+	case queryKindCloudCost:
+		path = "/cloudCost"
+	}
+	return path + "?" + params.Encode()
+}
+
+// window is the time period an OpenCost item covers.
+type window struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+// hours returns the duration of the window in hours, or zero if the window
+// is open.
+func (w window) hours() float64 {
+	if w.Start.IsZero() || w.End.IsZero() || !w.End.After(w.Start) {
+		return 0
+	}
+	return w.End.Sub(w.Start).Hours()
+}
+
+// costItem holds the fields of the allocation, asset and cloud cost items
+// returned by the OpenCost API that are used by the plugin. Each kind of
+// item only sets a subset of them.
+type costItem struct {
+	Window window `json:"window"`
+
+	// Allocation and asset costs.
+	CPUCost     float64 `json:"cpuCost"`
+	GPUCost     float64 `json:"gpuCost"`
+	RAMCost     float64 `json:"ramCost"`
+	PVCost      float64 `json:"pvCost"`
+	NetworkCost float64 `json:"networkCost"`
+	SharedCost  float64 `json:"sharedCost"`
+	TotalCost   float64 `json:"totalCost"`
+
+	// Allocation efficiency ratios.
+	CPUEfficiency   float64 `json:"cpuEfficiency"`
+	RAMEfficiency   float64 `json:"ramEfficiency"`
+	TotalEfficiency float64 `json:"totalEfficiency"`
+
+	// Cloud costs, read from the cloud provider billing exports.
+	ListCost         costValue `json:"listCost"`
+	NetCost          costValue `json:"netCost"`
+	AmortizedNetCost costValue `json:"amortizedNetCost"`
+	InvoicedCost     costValue `json:"invoicedCost"`
+	AmortizedCost    costValue `json:"amortizedCost"`
+}
+
+// costValue is a cost reported by the cloud cost API.
+type costValue struct {
+	Cost float64 `json:"cost"`
+}
+
+// costSet is the set of items returned for a window, keyed by their
+// aggregated name.
+type costSet map[string]*costItem
+
+// metric describes how to extract a queryable metric from a costItem.
+type metric struct {
+	value func(*costItem) float64
+
+	// ratio indicates the metric is not a cost, so it's returned as is
+	// instead of being converted to an hourly rate.
+	ratio bool
+}
+
+// extract returns the value of the metric for the item. Costs are divided by
+// the length of the window of the item so they are reported as hourly rates
+// regardless of the query window and OpenCost resolution.
+func (m *metric) extract(item *costItem) (float64, bool) {
+	v := m.value(item)
+	if m.ratio {
+		return v, true
+	}
+
+	hours := item.Window.hours()
+	if hours == 0 {
+		return 0, false
+	}
+	return v / hours, true
+}
+
+// allocationAndAssetMetrics are the costs supported by both the allocation
+// and assets APIs.
+var allocationAndAssetMetrics = map[string]*metric{
+	"cpu_cost":   {value: func(i *costItem) float64 { return i.CPUCost }},
+	"gpu_cost":   {value: func(i *costItem) float64 { return i.GPUCost }},
+	"ram_cost":   {value: func(i *costItem) float64 { return i.RAMCost }},
+	"total_cost": {value: func(i *costItem) float64 { return i.TotalCost }},
+}
+
+// kindMetrics are the metrics supported by each kind of query, keyed by their
+// name in the query.
+var kindMetrics = map[string]map[string]*metric{
+	queryKindAllocation: withMetrics(allocationAndAssetMetrics, map[string]*metric{
+		"pv_cost":          {value: func(i *costItem) float64 { return i.PVCost }},
+		"network_cost":     {value: func(i *costItem) float64 { return i.NetworkCost }},
+		"shared_cost":      {value: func(i *costItem) float64 { return i.SharedCost }},
+		"cpu_efficiency":   {value: func(i *costItem) float64 { return i.CPUEfficiency }, ratio: true},
+		"ram_efficiency":   {value: func(i *costItem) float64 { return i.RAMEfficiency }, ratio: true},
+		"total_efficiency": {value: func(i *costItem) float64 { return i.TotalEfficiency }, ratio: true},
+	}),
+	queryKindAssets: allocationAndAssetMetrics,
+	queryKindCloudCost: {
+		"list_cost":          {value: func(i *costItem) float64 { return i.ListCost.Cost }},
+		"net_cost":           {value: func(i *costItem) float64 { return i.NetCost.Cost }},
+		"amortized_net_cost": {value: func(i *costItem) float64 { return i.AmortizedNetCost.Cost }},
+		"invoiced_cost":      {value: func(i *costItem) float64 { return i.InvoicedCost.Cost }},
+		"amortized_cost":     {value: func(i *costItem) float64 { return i.AmortizedCost.Cost }},
+	},
+}
+
+// withMetrics returns a new map holding the metrics of all the maps.
+func withMetrics(maps ...map[string]*metric) map[string]*metric {
+	out := make(map[string]*metric)
+	for _, m := range maps {
+		for k, v := range m {
+			out[k] = v
+		}
+	}
+	return out
+}
@@ -12,7 +12,9 @@ import (
 	"fmt"
 	"math"
 	"os"
+	"slices"
 	"strconv"
+	"strings"
 	"time"
 
 	hclog "github.com/hashicorp/go-hclog"
@@ -38,6 +40,13 @@ const (
 	configKeyBasicAuthUser     = "basic_auth_user"
 	configKeyBasicAuthPassword = "basic_auth_password"
 
+	// configKeyBearerToken and configKeyBearerTokenFile are the
+	// configuration keys used to set the bearer token sent in the
+	// Authorization header. The token file is read on every request, so
+	// rotated tokens are picked up without reloading the agent.
+	configKeyBearerToken     = "bearer_token"
+	configKeyBearerTokenFile = "bearer_token_file"
+
 	// configKeyHeadersPrefix is the prefix used to indicate that a
 	// configuration value should be set as an HTTP header.
 	configKeyHeadersPrefix = "header_"
@@ -46,9 +55,49 @@ const (
 	// should use.
 	configKeyCACert = "ca_cert"
 
+	// configKeyClientCert and configKeyClientKey are the paths to the client
+	// certificate and key used by the Prometheus client for mutual TLS.
+	configKeyClientCert = "client_cert"
+	configKeyClientKey  = "client_key"
+
 	// configKeySkipVerify indicates that the Prometheus client should not
-	// verify TLS certificates.
-	configKeySkipVerify = "skip_verify"
+	// verify TLS certificates. configKeyInsecureSkipVerify is accepted as an
+	// alias, matching the Prometheus configuration naming.
+	configKeySkipVerify         = "skip_verify"
+	configKeyInsecureSkipVerify = "insecure_skip_verify"
+
+	// configKeyQueryStep is the resolution step width used when performing
+	// range queries. If not set, the step is calculated from the query window
+	// so that the number of returned samples stays within the Prometheus
+	// limit.
+	//
+	// The query keys can also be set for a single check within its
+	// source_config, in which case they are passed with the queries of the
+	// check and fall back to the plugin config otherwise.
+	configKeyQueryStep = "query_step"
+
+	// configKeyQueryTimeout is the maximum time a query is allowed to run,
+	// both within the Prometheus server and the plugin client.
+	configKeyQueryTimeout = "query_timeout"
+
+	// configKeyQueryLookbackDelta overrides the Prometheus server lookback
+	// delta used when evaluating the query.
+	configKeyQueryLookbackDelta = "query_lookback_delta"
+)
+
+const (
+	// defaultQueryStep is the minimum resolution step width used when
+	// performing range queries.
+	defaultQueryStep = time.Second
+
+	// defaultQueryTimeout is the default time limit used when performing
+	// queries.
+	defaultQueryTimeout = 10 * time.Second
+
+	// maxQueryPoints is the maximum number of points per time series that
+	// Prometheus will return for a range query. Queries exceeding this limit
+	// are rejected by the server.
+	maxQueryPoints = 11000
 )
 
 var (
@@ -58,7 +107,8 @@ var (
 	}
 
 	PluginConfig = &plugins.InternalPluginConfig{
-		Factory: func(l hclog.Logger) interface{} { return NewPrometheusPlugin(l) },
+		Factory:      func(l hclog.Logger) interface{} { return NewPrometheusPlugin(l) },
+		QueryOptions: []string{configKeyQueryStep, configKeyQueryTimeout, configKeyQueryLookbackDelta},
 	}
 
 	pluginInfo = &base.PluginInfo{
@@ -67,10 +117,34 @@ var (
 	}
 )
 
+var (
+	_ apm.LabeledAPM      = (*APMPlugin)(nil)
+	_ apm.StreamingAPM    = (*APMPlugin)(nil)
+	_ apm.QueryOptionsAPM = (*APMPlugin)(nil)
+)
+
 type APMPlugin struct {
 	client api.Client
 	config map[string]string
 	logger hclog.Logger
+
+	// query holds the options used to perform queries.
+	query queryOptions
+}
+
+// queryOptions tune how queries are performed. They are read from the plugin
+// config, and can be overridden for the queries of a single check.
+type queryOptions struct {
+
+	// step is the operator configured range query step. A zero value
+	// indicates the step should be calculated from the query time range.
+	step time.Duration
+
+	// timeout is the time limit applied to each query.
+	timeout time.Duration
+
+	// lookbackDelta overrides the Prometheus server lookback delta if set.
+	lookbackDelta time.Duration
 }
 
 func NewPrometheusPlugin(log hclog.Logger) apm.APM {
@@ -96,6 +170,15 @@ func (a *APMPlugin) SetConfig(config map[string]string) error {
 		return fmt.Errorf("failed to parse TLS configuration: %v", err)
 	}
 
+	if err := validateAuthConfig(config); err != nil {
+		return err
+	}
+
+	query, err := parseQueryOptions(config, queryOptions{timeout: defaultQueryTimeout})
+	if err != nil {
+		return err
+	}
+
 	promCfg := api.Config{
 		Address:      addr,
 		RoundTripper: newPluginRoudTripper(a.config, tlsConfig),
@@ -109,39 +192,132 @@ func (a *APMPlugin) SetConfig(config map[string]string) error {
 
 	// store config and client in plugin instance
 	a.client = client
+	a.query = query
 
 	return nil
 }
 
+// WithQueryOptions satisfies the WithQueryOptions function on the
+// apm.QueryOptionsAPM interface. The options are the query keys of the
+// plugin config, and override it for the queries of the returned copy.
+func (a *APMPlugin) WithQueryOptions(options map[string]string) (apm.APM, error) {
+	for k := range options {
+		if !slices.Contains(PluginConfig.QueryOptions, k) {
+			return nil, fmt.Errorf("unsupported query option %q", k)
+		}
+	}
+
+	query, err := parseQueryOptions(options, a.query)
+	if err != nil {
+		return nil, err
+	}
+
+	c := *a
+	c.query = query
+	return &c, nil
+}
+
 func (a *APMPlugin) PluginInfo() (*base.PluginInfo, error) {
 	return pluginInfo, nil
 }
 
 func (a *APMPlugin) Query(q string, r sdk.TimeRange) (sdk.TimestampedMetrics, error) {
-	m, err := a.QueryMultiple(q, r)
+	m, err := a.QueryLabeled(q, r)
 	if err != nil {
 		return nil, err
 	}
+	return m.Metrics, nil
+}
 
-	switch len(m) {
-	case 0:
-		return sdk.TimestampedMetrics{}, nil
-	case 1:
-		return m[0], nil
-	default:
-		return nil, fmt.Errorf("query returned %d metric streams, only 1 is expected", len(m))
+func (a *APMPlugin) QueryLabeled(q string, r sdk.TimeRange) (sdk.LabeledTimestampedMetrics, error) {
+	m, err := a.QueryMultipleLabeled(q, r)
+	if err != nil {
+		return sdk.LabeledTimestampedMetrics{}, err
 	}
+	return apm.SingleLabeledSeries(m)
 }
 
 func (a *APMPlugin) QueryMultiple(q string, r sdk.TimeRange) ([]sdk.TimestampedMetrics, error) {
+	m, err := a.QueryMultipleLabeled(q, r)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]sdk.TimestampedMetrics, len(m))
+	for i, series := range m {
+		result[i] = series.Metrics
+	}
+	return result, nil
+}
+
+func (a *APMPlugin) QueryMultipleLabeled(q string, r sdk.TimeRange) ([]sdk.LabeledTimestampedMetrics, error) {
 	a.logger.Debug("querying Prometheus", "query", q, "range", r)
 
+	// Always use a range query, even for short windows, so that the full set
+	// of samples within the window is returned rather than a single instant
+	// value.
+	return a.queryRange(q, v1.Range{Start: r.From, End: r.To, Step: a.rangeStep(r)})
+}
+
+// QueryStream satisfies the QueryStream function on the apm.StreamingAPM
+// interface. Instead of increasing the step to fit long time ranges within
+// the Prometheus limit of points per series, the range is split into windows
+// which are queried and sent one at a time using the minimum step.
+func (a *APMPlugin) QueryStream(q string, r sdk.TimeRange, send func(sdk.TimestampedMetrics) error) error {
+	return a.QueryStreamLabeled(q, r, func(m sdk.LabeledTimestampedMetrics) error {
+		return send(m.Metrics)
+	})
+}
+
+// QueryStreamLabeled satisfies the QueryStreamLabeled function on the
+// apm.LabeledStreamingAPM interface, sending the labels of the series with
+// each window.
+func (a *APMPlugin) QueryStreamLabeled(q string, r sdk.TimeRange, send func(sdk.LabeledTimestampedMetrics) error) error {
+	a.logger.Debug("streaming Prometheus query", "query", q, "range", r)
+
+	step := a.query.step
+	if step == 0 {
+		step = defaultQueryStep
+	}
+
+	// Range queries include both the start and end time, so each window
+	// holds at most maxQueryPoints points and the next window starts one
+	// step after the end of the previous one.
+	window := step * (maxQueryPoints - 1)
+
+	for from := r.From; !from.After(r.To); from = from.Add(window + step) {
+		to := from.Add(window)
+		if to.After(r.To) {
+			to = r.To
+		}
+
+		m, err := a.queryRange(q, v1.Range{Start: from, End: to, Step: step})
+		if err != nil {
+			return err
+		}
+
+		switch len(m) {
+		case 0:
+			continue
+		case 1:
+			if err := send(m[0]); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("query returned %d metric streams, only 1 is expected", len(m))
+		}
+	}
+	return nil
+}
+
+// queryRange performs the range query and parses the result.
+func (a *APMPlugin) queryRange(q string, promRange v1.Range) ([]sdk.LabeledTimestampedMetrics, error) {
 	v1api := v1.NewAPI(a.client)
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), a.query.timeout)
 	defer cancel()
 
-	promRange := v1.Range{Start: r.From, End: r.To, Step: time.Second}
-	result, warnings, err := v1api.QueryRange(ctx, q, promRange)
+	ctx = withLookbackDelta(ctx, a.query.lookbackDelta)
+	result, warnings, err := v1api.QueryRange(ctx, q, promRange, v1.WithTimeout(a.query.timeout))
 	if err != nil {
 		return nil, fmt.Errorf("failed to query: %v", err)
 	}
@@ -166,6 +342,67 @@ func (a *APMPlugin) QueryMultiple(q string, r sdk.TimeRange) ([]sdk.TimestampedM
 	}
 }
 
+// rangeStep returns the step width to use for a range query over the passed
+// time range. An operator configured step is always honoured, otherwise the
+// step is the smallest whole second that keeps the result within the maximum
+// number of points Prometheus allows.
+func (a *APMPlugin) rangeStep(r sdk.TimeRange) time.Duration {
+	if a.query.step != 0 {
+		return a.query.step
+	}
+
+	step := defaultQueryStep
+	if window := r.To.Sub(r.From); window/step > maxQueryPoints {
+		step = (window / maxQueryPoints).Truncate(time.Second) + time.Second
+	}
+	return step
+}
+
+// parseQueryOptions parses the query keys found in the config map. Keys which
+// are not set keep the value of defaults.
+func parseQueryOptions(config map[string]string, defaults queryOptions) (queryOptions, error) {
+	step, err := parseDurationConfig(config, configKeyQueryStep, defaults.step)
+	if err != nil {
+		return queryOptions{}, err
+	}
+	if step != 0 && step < time.Millisecond {
+		return queryOptions{}, fmt.Errorf("%q config value must be at least 1ms", configKeyQueryStep)
+	}
+
+	timeout, err := parseDurationConfig(config, configKeyQueryTimeout, defaults.timeout)
+	if err != nil {
+		return queryOptions{}, err
+	}
+	if timeout <= 0 {
+		return queryOptions{}, fmt.Errorf("%q config value must be positive", configKeyQueryTimeout)
+	}
+
+	lookbackDelta, err := parseDurationConfig(config, configKeyQueryLookbackDelta, defaults.lookbackDelta)
+	if err != nil {
+		return queryOptions{}, err
+	}
+	if lookbackDelta < 0 {
+		return queryOptions{}, fmt.Errorf("%q config value must not be negative", configKeyQueryLookbackDelta)
+	}
+
+	return queryOptions{step: step, timeout: timeout, lookbackDelta: lookbackDelta}, nil
+}
+
+// parseDurationConfig parses the duration found in the config map under the
+// passed key. If the key is not set or is empty, the default is returned.
+func parseDurationConfig(config map[string]string, key string, def time.Duration) (time.Duration, error) {
+	val := config[key]
+	if val == "" {
+		return def, nil
+	}
+
+	d, err := time.ParseDuration(val)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse %s value %s: %v", key, val, err)
+	}
+	return d, nil
+}
+
 func generateTLSConfig(config map[string]string) (*tls.Config, error) {
 	tlsConfig := tls.Config{}
 
@@ -187,20 +424,65 @@ func generateTLSConfig(config map[string]string) (*tls.Config, error) {
 		tlsConfig.RootCAs = caCertPool
 	}
 
-	skipVerify := config[configKeySkipVerify]
-	if skipVerify != "" {
+	// Load the client certificate if present.
+	certPath, keyPath := config[configKeyClientCert], config[configKeyClientKey]
+	if certPath != "" || keyPath != "" {
+		if certPath == "" || keyPath == "" {
+			return nil, fmt.Errorf("both %s and %s must be set", configKeyClientCert, configKeyClientKey)
+		}
+
+		cert, err := tls.LoadX509KeyPair(certPath, keyPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %v", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	for _, key := range []string{configKeySkipVerify, configKeyInsecureSkipVerify} {
+		skipVerify := config[key]
+		if skipVerify == "" {
+			continue
+		}
+
 		skipVerifyBool, err := strconv.ParseBool(skipVerify)
 		if err != nil {
-			return nil, fmt.Errorf("failed to parse %s value %s: %v", configKeySkipVerify, skipVerify, err)
+			return nil, fmt.Errorf("failed to parse %s value %s: %v", key, skipVerify, err)
 		}
 
-		tlsConfig.InsecureSkipVerify = skipVerifyBool
+		tlsConfig.InsecureSkipVerify = tlsConfig.InsecureSkipVerify || skipVerifyBool
 	}
 
 	return &tlsConfig, nil
 }
 
-func parseScalar(s *model.Scalar) ([]sdk.TimestampedMetrics, error) {
+// validateAuthConfig ensures at most one authentication method is configured
+// and that the bearer token file can be read.
+func validateAuthConfig(config map[string]string) error {
+	var methods []string
+
+	if config[configKeyBasicAuthUser] != "" || config[configKeyBasicAuthPassword] != "" {
+		methods = append(methods, "basic_auth")
+	}
+	if config[configKeyBearerToken] != "" {
+		methods = append(methods, configKeyBearerToken)
+	}
+	if config[configKeyBearerTokenFile] != "" {
+		methods = append(methods, configKeyBearerTokenFile)
+	}
+
+	if len(methods) > 1 {
+		return fmt.Errorf("only one authentication method can be configured, found %s", strings.Join(methods, ", "))
+	}
+
+	if path := config[configKeyBearerTokenFile]; path != "" {
+		if _, err := readBearerTokenFile(path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func parseScalar(s *model.Scalar) ([]sdk.LabeledTimestampedMetrics, error) {
 	if s == nil {
 		return nil, nil
 	}
@@ -210,10 +492,12 @@ func parseScalar(s *model.Scalar) ([]sdk.TimestampedMetrics, error) {
 		return nil, err
 	}
 
-	return []sdk.TimestampedMetrics{{tm}}, nil
+	return []sdk.LabeledTimestampedMetrics{{Metrics: sdk.TimestampedMetrics{tm}}}, nil
 }
 
-func parseVector(v model.Vector) ([]sdk.TimestampedMetrics, error) {
+// parseVector returns all samples of the vector as a single series, so the
+// resulting labels are the ones that are shared by every sample.
+func parseVector(v model.Vector) ([]sdk.LabeledTimestampedMetrics, error) {
 	var result sdk.TimestampedMetrics
 	for _, s := range v {
 		tm, err := parseSample(*s)
@@ -224,13 +508,26 @@ func parseVector(v model.Vector) ([]sdk.TimestampedMetrics, error) {
 		result = append(result, tm)
 	}
 
-	return []sdk.TimestampedMetrics{result}, nil
+	var labels map[string]string
+	for i, s := range v {
+		if i == 0 {
+			labels = metricLabels(s.Metric)
+			continue
+		}
+		for k, val := range labels {
+			if string(s.Metric[model.LabelName(k)]) != val {
+				delete(labels, k)
+			}
+		}
+	}
+
+	return []sdk.LabeledTimestampedMetrics{{Labels: labels, Metrics: result}}, nil
 }
 
-func parseMatrix(m model.Matrix) ([]sdk.TimestampedMetrics, error) {
+func parseMatrix(m model.Matrix) ([]sdk.LabeledTimestampedMetrics, error) {
 	// Cast matrix to a list of sample streams so we can iterate over it.
 	ssList := []*model.SampleStream(m)
-	result := make([]sdk.TimestampedMetrics, len(ssList))
+	result := make([]sdk.LabeledTimestampedMetrics, len(ssList))
 	for i, ss := range ssList {
 		var metrics sdk.TimestampedMetrics
 		for _, sp := range ss.Values {
@@ -242,12 +539,29 @@ func parseMatrix(m model.Matrix) ([]sdk.TimestampedMetrics, error) {
 			metrics = append(metrics, tm)
 		}
 
-		result[i] = metrics
+		result[i] = sdk.LabeledTimestampedMetrics{
+			Labels:  metricLabels(ss.Metric),
+			Metrics: metrics,
+		}
 	}
 
 	return result, nil
 }
 
+// metricLabels converts the Prometheus label set of a series into a plain
+// map. Nil is returned when the series has no labels.
+func metricLabels(m model.Metric) map[string]string {
+	if len(m) == 0 {
+		return nil
+	}
+
+	labels := make(map[string]string, len(m))
+	for k, v := range m {
+		labels[string(k)] = string(v)
+	}
+	return labels
+}
+
 func parseSample(s interface{}) (sdk.TimestampedMetric, error) {
 	var ts model.Time
 	var val model.SampleValue
@@ -7,12 +7,14 @@ import (
 	"errors"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"path"
 	"testing"
 	"time"
 
 	hclog "github.com/hashicorp/go-hclog"
 	"github.com/hashicorp/nomad-autoscaler/sdk"
+	"github.com/prometheus/common/model"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -38,6 +40,16 @@ func TestAPMPlugin_SetConfig(t *testing.T) {
 			expectOutput: nil,
 			name:         "required and valid config parameters set",
 		},
+		{
+			inputConfig:  map[string]string{"address": "http://127.0.0.1:9090", "query_step": "fast"},
+			expectOutput: errors.New(`failed to parse query_step value fast: time: invalid duration "fast"`),
+			name:         "malformed query step",
+		},
+		{
+			inputConfig:  map[string]string{"address": "http://127.0.0.1:9090", "query_timeout": "0s"},
+			expectOutput: errors.New(`"query_timeout" config value must be positive`),
+			name:         "zero query timeout",
+		},
 	}
 
 	for _, tc := range testCases {
@@ -102,6 +114,30 @@ func TestAPMPlugin_Query(t *testing.T) {
 				require.Len(t, m, 31)
 			},
 		},
+		{
+			name:    "custom query controls",
+			fixture: "query_range_200.json",
+			pluginConfig: map[string]string{
+				configKeyQueryStep:          "30s",
+				configKeyQueryTimeout:       "5s",
+				configKeyQueryLookbackDelta: "2m",
+			},
+			query: "nomad_client_allocated_memory",
+			timeRange: sdk.TimeRange{
+				From: time.Unix(1600000000, 0),
+				To:   time.Unix(1600003600, 0),
+			},
+			validateRequest: func(t *testing.T, r *http.Request) {
+				r.ParseForm()
+				require.Equal(t, "30", r.FormValue("step"))
+				require.Equal(t, "5s", r.FormValue("timeout"))
+				require.Equal(t, "2m0s", r.FormValue("lookback_delta"))
+			},
+			validateMetrics: func(t *testing.T, m sdk.TimestampedMetrics, err error) {
+				require.NoError(t, err)
+				require.Len(t, m, 31)
+			},
+		},
 	}
 
 	for _, tc := range testCases {
@@ -128,3 +164,171 @@ func TestAPMPlugin_Query(t *testing.T) {
 		})
 	}
 }
+
+func TestAPMPlugin_WithQueryOptions(t *testing.T) {
+	var form url.Values
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		form = r.Form
+		http.ServeFile(w, r, path.Join("./test-fixtures", "query_range_200.json"))
+	}))
+	defer srv.Close()
+
+	plugin := &APMPlugin{logger: hclog.NewNullLogger()}
+	require.NoError(t, plugin.SetConfig(map[string]string{
+		configKeyAddress:      srv.URL,
+		configKeyQueryStep:    "30s",
+		configKeyQueryTimeout: "5s",
+	}))
+
+	r := sdk.TimeRange{From: time.Unix(1600000000, 0), To: time.Unix(1600003600, 0)}
+
+	// The options override the plugin config for the queries of the copy.
+	check, err := plugin.WithQueryOptions(map[string]string{
+		configKeyQueryStep:          "1m",
+		configKeyQueryLookbackDelta: "2m",
+	})
+	require.NoError(t, err)
+	_, err = check.Query("nomad_client_allocated_memory", r)
+	require.NoError(t, err)
+	require.Equal(t, "60", form.Get("step"))
+	require.Equal(t, "5s", form.Get("timeout"))
+	require.Equal(t, "2m0s", form.Get("lookback_delta"))
+
+	// The plugin itself is not modified.
+	_, err = plugin.Query("nomad_client_allocated_memory", r)
+	require.NoError(t, err)
+	require.Equal(t, "30", form.Get("step"))
+	require.Empty(t, form.Get("lookback_delta"))
+
+	// Only the query keys can be set, and they are validated.
+	_, err = plugin.WithQueryOptions(map[string]string{configKeyAddress: "http://other.example.com"})
+	require.ErrorContains(t, err, `unsupported query option "address"`)
+	_, err = plugin.WithQueryOptions(map[string]string{configKeyQueryTimeout: "-1s"})
+	require.ErrorContains(t, err, "must be positive")
+}
+
+func TestAPMPlugin_QueryMultipleLabeled(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.ServeFile(w, r, path.Join("./test-fixtures", "query_range_200.json"))
+	}))
+	defer srv.Close()
+
+	plugin := &APMPlugin{logger: hclog.NewNullLogger()}
+	require.NoError(t, plugin.SetConfig(map[string]string{configKeyAddress: srv.URL}))
+
+	r := sdk.TimeRange{From: time.Unix(1600000000, 0), To: time.Unix(1600003600, 0)}
+	series, err := plugin.QueryMultipleLabeled("nomad_client_allocated_memory", r)
+	require.NoError(t, err)
+	require.Len(t, series, 1)
+	require.Len(t, series[0].Metrics, 31)
+	require.Equal(t, "nomad_client_allocated_memory", series[0].Labels["__name__"])
+	require.Equal(t, "dc1", series[0].Labels["datacenter"])
+}
+
+func TestAPMPlugin_QueryStream(t *testing.T) {
+	var ranges [][]string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		require.Equal(t, "1", r.FormValue("step"))
+		ranges = append(ranges, []string{r.FormValue("start"), r.FormValue("end")})
+		http.ServeFile(w, r, path.Join("./test-fixtures", "query_range_200.json"))
+	}))
+	defer srv.Close()
+
+	plugin := &APMPlugin{logger: hclog.NewNullLogger()}
+	require.NoError(t, plugin.SetConfig(map[string]string{configKeyAddress: srv.URL}))
+
+	// A 6 hour range at 1s resolution exceeds the Prometheus limit of points
+	// per series, so it must be split into two windows.
+	r := sdk.TimeRange{From: time.Unix(1600000000, 0), To: time.Unix(1600021600, 0)}
+
+	var chunks []sdk.TimestampedMetrics
+	err := plugin.QueryStream("nomad_client_allocated_memory", r, func(m sdk.TimestampedMetrics) error {
+		chunks = append(chunks, m)
+		return nil
+	})
+	require.NoError(t, err)
+	require.Len(t, chunks, 2)
+	require.Equal(t, [][]string{
+		{"1600000000", "1600010999"},
+		{"1600011000", "1600021600"},
+	}, ranges)
+
+	// Errors returned when sending a chunk stop the query.
+	ranges = nil
+	err = plugin.QueryStream("nomad_client_allocated_memory", r, func(m sdk.TimestampedMetrics) error {
+		return errors.New("stream closed")
+	})
+	require.EqualError(t, err, "stream closed")
+	require.Len(t, ranges, 1)
+
+	// Labeled streams include the labels of the series with each chunk.
+	err = plugin.QueryStreamLabeled("nomad_client_allocated_memory", r, func(m sdk.LabeledTimestampedMetrics) error {
+		require.Equal(t, "dc1", m.Labels["datacenter"])
+		return nil
+	})
+	require.NoError(t, err)
+}
+
+func Test_parseVector(t *testing.T) {
+	input := model.Vector{
+		{
+			Metric:    model.Metric{"__name__": "up", "instance": "a", "job": "nomad"},
+			Value:     1,
+			Timestamp: model.TimeFromUnix(1600000000),
+		},
+		{
+			Metric:    model.Metric{"__name__": "up", "instance": "b", "job": "nomad"},
+			Value:     0,
+			Timestamp: model.TimeFromUnix(1600000000),
+		},
+	}
+
+	result, err := parseVector(input)
+	require.NoError(t, err)
+	require.Len(t, result, 1)
+	require.Len(t, result[0].Metrics, 2)
+	require.Equal(t, map[string]string{"__name__": "up", "job": "nomad"}, result[0].Labels)
+}
+
+func TestAPMPlugin_rangeStep(t *testing.T) {
+	testCases := []struct {
+		name         string
+		queryStep    time.Duration
+		window       time.Duration
+		expectedStep time.Duration
+	}{
+		{
+			name:         "short window uses default step",
+			window:       5 * time.Minute,
+			expectedStep: defaultQueryStep,
+		},
+		{
+			name:         "window at point limit uses default step",
+			window:       maxQueryPoints * time.Second,
+			expectedStep: defaultQueryStep,
+		},
+		{
+			name:         "long window increases step",
+			window:       24 * time.Hour,
+			expectedStep: 8 * time.Second,
+		},
+		{
+			name:         "configured step is always used",
+			queryStep:    time.Minute,
+			window:       5 * time.Minute,
+			expectedStep: time.Minute,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			apmPlugin := APMPlugin{query: queryOptions{step: tc.queryStep}}
+
+			now := time.Now()
+			r := sdk.TimeRange{From: now.Add(-tc.window), To: now}
+			assert.Equal(t, tc.expectedStep, apmPlugin.rangeStep(r))
+		})
+	}
+}
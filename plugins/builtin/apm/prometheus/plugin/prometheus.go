@@ -4,9 +4,13 @@
 package plugin
 
 import (
+	"context"
 	"crypto/tls"
+	"fmt"
 	"net/http"
+	"os"
 	"strings"
+	"time"
 
 	"github.com/prometheus/client_golang/api"
 )
@@ -17,9 +21,23 @@ type pluginRoundTripper struct {
 	basicAuthUser     string
 	basicAuthPassword string
 
+	// bearerToken and bearerTokenFile set the Authorization header. Only
+	// one of them, or the basic auth values, is expected to be set.
+	bearerToken     string
+	bearerTokenFile string
+
 	rt http.RoundTripper
 }
 
+// lookbackDeltaKey is the context key of the lookback delta of a query,
+// which is added as a query parameter to the query request if set.
+type lookbackDeltaKey struct{}
+
+// withLookbackDelta returns a copy of ctx holding the lookback delta.
+func withLookbackDelta(ctx context.Context, d time.Duration) context.Context {
+	return context.WithValue(ctx, lookbackDeltaKey{}, d)
+}
+
 // newPluginRoudTripper returns a new pluginRoundTripper configured based on
 // configuration values set for the plugin.
 func newPluginRoudTripper(config map[string]string, tlsConfig *tls.Config) *pluginRoundTripper {
@@ -34,14 +52,18 @@ func newPluginRoudTripper(config map[string]string, tlsConfig *tls.Config) *plug
 		}
 	}
 
-	defaultRoudTripper := api.DefaultRoundTripper.(*http.Transport)
-	defaultRoudTripper.TLSClientConfig = tlsConfig
+	// Clone the default transport so each plugin instance uses its own TLS
+	// configuration.
+	transport := api.DefaultRoundTripper.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
 
 	return &pluginRoundTripper{
 		headers:           headers,
 		basicAuthUser:     username,
 		basicAuthPassword: password,
-		rt:                defaultRoudTripper,
+		bearerToken:       config[configKeyBearerToken],
+		bearerTokenFile:   config[configKeyBearerTokenFile],
+		rt:                transport,
 	}
 }
 
@@ -50,10 +72,52 @@ func (rt *pluginRoundTripper) RoundTrip(req *http.Request) (*http.Response, erro
 		req.Header.Add(header, value)
 	}
 
-	setAuth := (rt.basicAuthUser != "" || rt.basicAuthPassword != "") && req.Header.Get("Authorization") == ""
-	if setAuth {
-		req.SetBasicAuth(rt.basicAuthUser, rt.basicAuthPassword)
+	// Headers set explicitly by the operator take precedence over the
+	// authentication configuration.
+	if req.Header.Get("Authorization") == "" {
+		switch {
+		case rt.bearerTokenFile != "":
+			token, err := readBearerTokenFile(rt.bearerTokenFile)
+			if err != nil {
+				return nil, err
+			}
+			req.Header.Set("Authorization", "Bearer "+token)
+		case rt.bearerToken != "":
+			req.Header.Set("Authorization", "Bearer "+rt.bearerToken)
+		case rt.basicAuthUser != "" || rt.basicAuthPassword != "":
+			req.SetBasicAuth(rt.basicAuthUser, rt.basicAuthPassword)
+		}
+	}
+
+	// The Prometheus API merges URL and form parameters, so setting the
+	// lookback delta on the URL works for both GET and POST queries.
+	d, _ := req.Context().Value(lookbackDeltaKey{}).(time.Duration)
+	if d > 0 && isQueryPath(req.URL.Path) {
+		q := req.URL.Query()
+		q.Set("lookback_delta", d.String())
+		req.URL.RawQuery = q.Encode()
 	}
 
 	return rt.rt.RoundTrip(req)
 }
+
+// isQueryPath identifies whether the request path is a Prometheus query
+// endpoint.
+func isQueryPath(p string) bool {
+	return strings.HasSuffix(p, "/api/v1/query") || strings.HasSuffix(p, "/api/v1/query_range")
+}
+
+// readBearerTokenFile returns the bearer token stored in the file, without
+// surrounding whitespace.
+func readBearerTokenFile(path string) (string, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s %s: %v", configKeyBearerTokenFile, path, err)
+	}
+
+	token := strings.TrimSpace(string(b))
+	if token == "" {
+		return "", fmt.Errorf("%s %s is empty", configKeyBearerTokenFile, path)
+	}
+	return token, nil
+}
@@ -4,11 +4,20 @@
 package plugin
 
 import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
 	"encoding/pem"
+	"math/big"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -44,6 +53,106 @@ func TestAPMPlugin_roundTripper(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestAPMPlugin_roundTripperAuth(t *testing.T) {
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	require.NoError(t, os.WriteFile(tokenFile, []byte("file-token\n"), 0600))
+
+	testCases := []struct {
+		name         string
+		cfg          map[string]string
+		expectedAuth string
+	}{
+		{
+			name:         "no auth",
+			cfg:          map[string]string{},
+			expectedAuth: "",
+		},
+		{
+			name:         "bearer token",
+			cfg:          map[string]string{"bearer_token": "my-token"},
+			expectedAuth: "Bearer my-token",
+		},
+		{
+			name:         "bearer token file",
+			cfg:          map[string]string{"bearer_token_file": tokenFile},
+			expectedAuth: "Bearer file-token",
+		},
+		{
+			name:         "basic auth",
+			cfg:          map[string]string{"basic_auth_user": "user", "basic_auth_password": "secret"},
+			expectedAuth: "Basic dXNlcjpzZWNyZXQ=",
+		},
+		{
+			name:         "header takes precedence",
+			cfg:          map[string]string{"bearer_token": "my-token", "header_Authorization": "Custom value"},
+			expectedAuth: "Custom value",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var auth string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				auth = r.Header.Get("Authorization")
+			}))
+			defer server.Close()
+
+			client := &http.Client{Transport: newPluginRoudTripper(tc.cfg, nil)}
+			_, err := client.Get(server.URL)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedAuth, auth)
+		})
+	}
+
+	// Rotated tokens are read on the next request.
+	require.NoError(t, os.WriteFile(tokenFile, []byte("rotated-token"), 0600))
+
+	var auth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: newPluginRoudTripper(map[string]string{"bearer_token_file": tokenFile}, nil)}
+	_, err := client.Get(server.URL)
+	require.NoError(t, err)
+	assert.Equal(t, "Bearer rotated-token", auth)
+}
+
+func TestAPMPlugin_validateAuthConfig(t *testing.T) {
+	testCases := []struct {
+		name        string
+		cfg         map[string]string
+		expectedErr string
+	}{
+		{
+			name: "single method",
+			cfg:  map[string]string{"bearer_token": "my-token"},
+		},
+		{
+			name:        "multiple methods",
+			cfg:         map[string]string{"bearer_token": "my-token", "basic_auth_user": "user"},
+			expectedErr: "only one authentication method can be configured, found basic_auth, bearer_token",
+		},
+		{
+			name:        "missing token file",
+			cfg:         map[string]string{"bearer_token_file": "/does/not/exist"},
+			expectedErr: "failed to read bearer_token_file /does/not/exist",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := validateAuthConfig(tc.cfg)
+			if tc.expectedErr != "" {
+				assert.ErrorContains(t, err, tc.expectedErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
 func TestAPMPlugin_roundTripperTLS(t *testing.T) {
 	// Setup test HTTPS server.
 	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -86,6 +195,14 @@ func TestAPMPlugin_roundTripperTLS(t *testing.T) {
 			expectConnError:       false,
 			expectValidationError: false,
 		},
+		{
+			name: "insecure skip verify",
+			cfg: map[string]string{
+				"insecure_skip_verify": "true",
+			},
+			expectConnError:       false,
+			expectValidationError: false,
+		},
 		{
 			name: "set CA cert",
 			cfg: map[string]string{
@@ -115,6 +232,13 @@ func TestAPMPlugin_roundTripperTLS(t *testing.T) {
 			},
 			expectValidationError: true,
 		},
+		{
+			name: "client cert without key",
+			cfg: map[string]string{
+				"client_cert": caCertFile.Name(),
+			},
+			expectValidationError: true,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -141,3 +265,67 @@ func TestAPMPlugin_roundTripperTLS(t *testing.T) {
 		})
 	}
 }
+
+func TestAPMPlugin_roundTripperMutualTLS(t *testing.T) {
+	// Setup test HTTPS server which requires a client certificate.
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("hello"))
+	}))
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAnyClientCert}
+	server.StartTLS()
+	defer server.Close()
+
+	// Generate a self-signed client certificate.
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "nomad-autoscaler"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	certPath, keyPath := filepath.Join(dir, "client.crt"), filepath.Join(dir, "client.key")
+	require.NoError(t, os.WriteFile(certPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600))
+	require.NoError(t, os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600))
+
+	testCases := []struct {
+		name            string
+		cfg             map[string]string
+		expectConnError bool
+	}{
+		{
+			name:            "no client cert",
+			cfg:             map[string]string{"skip_verify": "true"},
+			expectConnError: true,
+		},
+		{
+			name:            "client cert",
+			cfg:             map[string]string{"skip_verify": "true", "client_cert": certPath, "client_key": keyPath},
+			expectConnError: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tlsConfig, err := generateTLSConfig(tc.cfg)
+			require.NoError(t, err)
+
+			client := &http.Client{Transport: newPluginRoudTripper(tc.cfg, tlsConfig)}
+			_, err = client.Get(server.URL)
+			if tc.expectConnError {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,205 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package plugin
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	hclog "github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/nomad-autoscaler/plugins"
+	"github.com/hashicorp/nomad-autoscaler/plugins/apm"
+	"github.com/hashicorp/nomad-autoscaler/plugins/base"
+	"github.com/hashicorp/nomad-autoscaler/sdk"
+	"github.com/hashicorp/nomad-autoscaler/sdk/helper/pluginconfig"
+)
+
+const (
+	// pluginName is the unique name of the this plugin amongst APM plugins.
+	pluginName = "rabbitmq"
+
+	// configKeys represents the known configuration parameters required at
+	// varying points throughout the plugins lifecycle.
+	configKeyAddress        = "address"
+	configKeyUsername       = "username"
+	configKeyPassword       = "password"
+	configKeyTimeout        = "timeout"
+	configKeySampleInterval = "sample_interval"
+
+	// configValues are the default values used when a configuration key is not
+	// supplied by the operator that are specific to the plugin.
+	configValueAddressDefault        = "http://127.0.0.1:15672"
+	configValueUsernameDefault       = "guest"
+	configValuePasswordDefault       = "guest"
+	configValueTimeoutDefault        = "10s"
+	configValueSampleIntervalDefault = "10s"
+)
+
+var (
+	PluginID = plugins.PluginID{
+		Name:       pluginName,
+		PluginType: sdk.PluginTypeAPM,
+	}
+
+	PluginConfig = &plugins.InternalPluginConfig{
+		Factory: func(l hclog.Logger) interface{} { return NewRabbitMQPlugin(l) },
+	}
+
+	pluginInfo = &base.PluginInfo{
+		Name:       pluginName,
+		PluginType: sdk.PluginTypeAPM,
+	}
+)
+
+// Assert that APMPlugin meets the apm.APM interface.
+var _ apm.APM = (*APMPlugin)(nil)
+
+// APMPlugin is the RabbitMQ implementation of the apm.APM interface. Metrics
+// are read directly from the RabbitMQ management HTTP API, using the samples
+// it keeps for queue lengths and message rates.
+type APMPlugin struct {
+	config map[string]string
+	logger hclog.Logger
+
+	client         *http.Client
+	address        string
+	username       string
+	password       string
+	sampleInterval time.Duration
+}
+
+// NewRabbitMQPlugin returns the RabbitMQ implementation of the apm.APM
+// interface.
+func NewRabbitMQPlugin(log hclog.Logger) apm.APM {
+	return &APMPlugin{
+		logger: log,
+	}
+}
+
+// SetConfig satisfies the SetConfig function on the base.Base interface.
+func (a *APMPlugin) SetConfig(config map[string]string) error {
+
+	addr := pluginconfig.GetValue(config, configKeyAddress, configValueAddressDefault)
+	if _, err := url.ParseRequestURI(addr); err != nil {
+		return fmt.Errorf("failed to parse `%s`: %v", configKeyAddress, err)
+	}
+
+	timeout, err := time.ParseDuration(pluginconfig.GetValue(config, configKeyTimeout, configValueTimeoutDefault))
+	if err != nil {
+		return fmt.Errorf("failed to parse `%s`: %v", configKeyTimeout, err)
+	}
+
+	interval, err := time.ParseDuration(pluginconfig.GetValue(config, configKeySampleInterval, configValueSampleIntervalDefault))
+	if err != nil {
+		return fmt.Errorf("failed to parse `%s`: %v", configKeySampleInterval, err)
+	}
+	if interval < time.Second {
+		return fmt.Errorf("`%s` must be at least 1s", configKeySampleInterval)
+	}
+
+	a.config = config
+	a.client = &http.Client{Timeout: timeout}
+	a.address = strings.TrimSuffix(addr, "/")
+	a.username = pluginconfig.GetValue(config, configKeyUsername, configValueUsernameDefault)
+	a.password = pluginconfig.GetValue(config, configKeyPassword, configValuePasswordDefault)
+	a.sampleInterval = interval
+
+	return nil
+}
+
+// PluginInfo satisfies the PluginInfo function on the base.Base interface.
+func (a *APMPlugin) PluginInfo() (*base.PluginInfo, error) {
+	return pluginInfo, nil
+}
+
+// Query satisfies the Query function on the apm.APM interface.
+func (a *APMPlugin) Query(q string, r sdk.TimeRange) (sdk.TimestampedMetrics, error) {
+	if a.client == nil {
+		return nil, fmt.Errorf("plugin is not configured")
+	}
+
+	query, err := parseQuery(q)
+	if err != nil {
+		return nil, err
+	}
+
+	obj, err := a.getObject(query, r)
+	if err != nil {
+		return nil, err
+	}
+
+	result := query.metric.extract(obj, r)
+	if len(result) == 0 {
+		a.logger.Warn("no data points found in RabbitMQ response", "query", q)
+	}
+	return result, nil
+}
+
+// QueryMultiple satisfies the QueryMultiple function on the apm.APM
+// interface. Queries always target a single queue or vhost, so a single
+// series is returned.
+func (a *APMPlugin) QueryMultiple(q string, r sdk.TimeRange) ([]sdk.TimestampedMetrics, error) {
+	m, err := a.Query(q, r)
+	if err != nil {
+		return nil, err
+	}
+	return []sdk.TimestampedMetrics{m}, nil
+}
+
+// getObject reads the queue or vhost targeted by the query from the
+// management API, requesting the samples which cover the time range.
+func (a *APMPlugin) getObject(q *query, r sdk.TimeRange) (*statsObject, error) {
+	age := int64(r.To.Sub(r.From).Seconds())
+	if age < 1 {
+		age = 1
+	}
+	incr := int64(a.sampleInterval.Seconds())
+
+	params := url.Values{}
+	params.Set("lengths_age", strconv.FormatInt(age, 10))
+	params.Set("lengths_incr", strconv.FormatInt(incr, 10))
+	params.Set("msg_rates_age", strconv.FormatInt(age, 10))
+	params.Set("msg_rates_incr", strconv.FormatInt(incr, 10))
+
+	endpoint := a.address + q.path() + "?" + params.Encode()
+
+	req, err := http.NewRequest(http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	req.SetBasicAuth(a.username, a.password)
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query RabbitMQ: %v", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %v", err)
+	}
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return nil, fmt.Errorf("%s %q not found in vhost %q", q.kind, q.name(), q.vhost)
+	default:
+		return nil, fmt.Errorf("failed to query RabbitMQ: unexpected response code %d: %s",
+			resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var obj statsObject
+	if err := json.Unmarshal(body, &obj); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %v", err)
+	}
+	return &obj, nil
+}
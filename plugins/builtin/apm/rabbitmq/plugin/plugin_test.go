@@ -0,0 +1,244 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package plugin
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	hclog "github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/nomad-autoscaler/sdk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPMPlugin_SetConfig(t *testing.T) {
+	testCases := []struct {
+		name          string
+		config        map[string]string
+		expectedError string
+	}{
+		{
+			name:   "defaults",
+			config: map[string]string{},
+		},
+		{
+			name:          "invalid address",
+			config:        map[string]string{"address": "not a url"},
+			expectedError: "failed to parse `address`",
+		},
+		{
+			name:          "invalid timeout",
+			config:        map[string]string{"timeout": "soon"},
+			expectedError: "failed to parse `timeout`",
+		},
+		{
+			name:          "sample interval too small",
+			config:        map[string]string{"sample_interval": "500ms"},
+			expectedError: "`sample_interval` must be at least 1s",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			p := NewRabbitMQPlugin(hclog.NewNullLogger())
+			err := p.SetConfig(tc.config)
+			if tc.expectedError != "" {
+				assert.ErrorContains(t, err, tc.expectedError)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func Test_parseQuery(t *testing.T) {
+	testCases := []struct {
+		name          string
+		query         string
+		expectedKind  string
+		expectedVhost string
+		expectedQueue string
+		expectedPath  string
+		expectedError string
+	}{
+		{
+			name:          "queue in default vhost",
+			query:         "queue/%2F/orders/messages_ready",
+			expectedKind:  "queue",
+			expectedVhost: "/",
+			expectedQueue: "orders",
+			expectedPath:  "/api/queues/%2F/orders",
+		},
+		{
+			name:          "queue name with slash",
+			query:         "queue/prod/orders/eu/publish_rate",
+			expectedKind:  "queue",
+			expectedVhost: "prod",
+			expectedQueue: "orders/eu",
+			expectedPath:  "/api/queues/prod/orders%2Feu",
+		},
+		{
+			name:          "vhost",
+			query:         "vhost/prod/messages",
+			expectedKind:  "vhost",
+			expectedVhost: "prod",
+			expectedPath:  "/api/vhosts/prod",
+		},
+		{
+			name:          "missing metric",
+			query:         "queue",
+			expectedError: "expected format",
+		},
+		{
+			name:          "missing queue",
+			query:         "queue/prod/messages",
+			expectedError: "expected format queue/<vhost>/<queue>/<metric>",
+		},
+		{
+			name:          "unsupported type",
+			query:         "exchange/prod/orders/messages",
+			expectedError: `unsupported type "exchange"`,
+		},
+		{
+			name:          "unsupported metric",
+			query:         "queue/prod/orders/bytes",
+			expectedError: `unsupported metric "bytes"`,
+		},
+		{
+			name:          "queue only metric",
+			query:         "vhost/prod/consumers",
+			expectedError: `metric "consumers" is only available for queues`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			q, err := parseQuery(tc.query)
+			if tc.expectedError != "" {
+				assert.ErrorContains(t, err, tc.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedKind, q.kind)
+			assert.Equal(t, tc.expectedVhost, q.vhost)
+			assert.Equal(t, tc.expectedQueue, q.queue)
+			assert.Equal(t, tc.expectedPath, q.path())
+		})
+	}
+}
+
+func TestAPMPlugin_Query(t *testing.T) {
+	from := time.UnixMilli(1600000000000)
+	to := from.Add(30 * time.Second)
+	r := sdk.TimeRange{From: from, To: to}
+
+	queueResp := `{
+  "messages": 12,
+  "messages_ready": 10,
+  "messages_ready_details": {
+    "rate": 1.0,
+    "samples": [
+      {"sample": 10, "timestamp": 1600000030000},
+      {"sample": 8, "timestamp": 1600000020000},
+      {"sample": 6, "timestamp": 1600000010000},
+      {"sample": 4, "timestamp": 1599999990000}
+    ]
+  },
+  "consumers": 3,
+  "message_stats": {
+    "publish_details": {
+      "rate": 5.0,
+      "samples": [
+        {"sample": 200, "timestamp": 1600000030000},
+        {"sample": 100, "timestamp": 1600000020000},
+        {"sample": 50, "timestamp": 1600000010000}
+      ]
+    }
+  }
+}`
+
+	testCases := []struct {
+		name           string
+		query          string
+		expectedResult sdk.TimestampedMetrics
+		expectedError  string
+	}{
+		{
+			name:  "length samples within range",
+			query: "queue/%2F/orders/messages_ready",
+			expectedResult: sdk.TimestampedMetrics{
+				{Timestamp: time.UnixMilli(1600000010000), Value: 6},
+				{Timestamp: time.UnixMilli(1600000020000), Value: 8},
+				{Timestamp: time.UnixMilli(1600000030000), Value: 10},
+			},
+		},
+		{
+			name:  "rate calculated from samples",
+			query: "queue/%2F/orders/publish_rate",
+			expectedResult: sdk.TimestampedMetrics{
+				{Timestamp: time.UnixMilli(1600000020000), Value: 5},
+				{Timestamp: time.UnixMilli(1600000030000), Value: 10},
+			},
+		},
+		{
+			name:  "current value without samples",
+			query: "queue/%2F/orders/consumers",
+			expectedResult: sdk.TimestampedMetrics{
+				{Timestamp: to, Value: 3},
+			},
+		},
+		{
+			name:  "current rate without samples",
+			query: "queue/%2F/orders/ack_rate",
+			expectedResult: sdk.TimestampedMetrics{
+				{Timestamp: to, Value: 0},
+			},
+		},
+		{
+			name:          "queue not found",
+			query:         "queue/%2F/missing/messages",
+			expectedError: `queue "missing" not found in vhost "/"`,
+		},
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, pass, _ := r.BasicAuth()
+		if user != "autoscaler" || pass != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		switch r.URL.EscapedPath() {
+		case "/api/queues/%2F/orders":
+			assert.Equal(t, "30", r.URL.Query().Get("lengths_age"))
+			assert.Equal(t, "10", r.URL.Query().Get("lengths_incr"))
+			_, _ = w.Write([]byte(queueResp))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	p := NewRabbitMQPlugin(hclog.NewNullLogger())
+	require.NoError(t, p.SetConfig(map[string]string{
+		"address":  server.URL,
+		"username": "autoscaler",
+		"password": "secret",
+	}))
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := p.Query(tc.query, r)
+			if tc.expectedError != "" {
+				assert.ErrorContains(t, err, tc.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedResult, result)
+		})
+	}
+}
@@ -0,0 +1,237 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package plugin
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/nomad-autoscaler/sdk"
+)
+
+const (
+	// The types of object that can be queried.
+	queryKindQueue = "queue"
+	queryKindVhost = "vhost"
+)
+
+// query is the parsed representation of a RabbitMQ APM query. Queries use the
+// format queue/<vhost>/<queue>/<metric> or vhost/<vhost>/<metric>. The vhost
+// must be URL path encoded, so the default vhost is written as %2F.
+type query struct {
+	kind   string
+	vhost  string
+	queue  string
+	metric *metric
+}
+
+// name returns the name of the queried object, for use in error messages.
+func (q *query) name() string {
+	if q.kind == queryKindQueue {
+		return q.queue
+	}
+	return q.vhost
+}
+
+// path returns the management API path of the queried object.
+func (q *query) path() string {
+	if q.kind == queryKindQueue {
+		return "/api/queues/" + url.PathEscape(q.vhost) + "/" + url.PathEscape(q.queue)
+	}
+	return "/api/vhosts/" + url.PathEscape(q.vhost)
+}
+
+// parseQuery parses and validates the input query.
+func parseQuery(q string) (*query, error) {
+	kind, rest, ok := strings.Cut(q, "/")
+	if !ok {
+		return nil, fmt.Errorf("invalid query %q, expected format %s/<vhost>/<queue>/<metric> or %s/<vhost>/<metric>",
+			q, queryKindQueue, queryKindVhost)
+	}
+
+	// The metric is always the last element, which allows queue names to
+	// contain slashes.
+	idx := strings.LastIndex(rest, "/")
+	if idx < 0 {
+		return nil, fmt.Errorf("invalid query %q, missing metric", q)
+	}
+	target, metricName := rest[:idx], rest[idx+1:]
+
+	out := &query{kind: kind}
+
+	var rawVhost string
+	switch kind {
+	case queryKindQueue:
+		rawVhost, out.queue, ok = strings.Cut(target, "/")
+		if !ok || out.queue == "" {
+			return nil, fmt.Errorf("invalid query %q, expected format %s/<vhost>/<queue>/<metric>", q, queryKindQueue)
+		}
+	case queryKindVhost:
+		rawVhost = target
+	default:
+		return nil, fmt.Errorf("invalid query %q, unsupported type %q, must be %q or %q",
+			q, kind, queryKindQueue, queryKindVhost)
+	}
+
+	if rawVhost == "" {
+		return nil, fmt.Errorf("invalid query %q, missing vhost", q)
+	}
+
+	vhost, err := url.PathUnescape(rawVhost)
+	if err != nil {
+		return nil, fmt.Errorf("invalid query %q, failed to decode vhost: %v", q, err)
+	}
+	out.vhost = vhost
+
+	m, ok := metrics[metricName]
+	if !ok {
+		return nil, fmt.Errorf("invalid query %q, unsupported metric %q", q, metricName)
+	}
+	if m.queueOnly && kind != queryKindQueue {
+		return nil, fmt.Errorf("invalid query %q, metric %q is only available for queues", q, metricName)
+	}
+	out.metric = m
+
+	return out, nil
+}
+
+// statsObject holds the fields of the queue and vhost objects returned by the
+// management API that are used by the plugin.
+type statsObject struct {
+	Messages                      float64      `json:"messages"`
+	MessagesDetails               details      `json:"messages_details"`
+	MessagesReady                 float64      `json:"messages_ready"`
+	MessagesReadyDetails          details      `json:"messages_ready_details"`
+	MessagesUnacknowledged        float64      `json:"messages_unacknowledged"`
+	MessagesUnacknowledgedDetails details      `json:"messages_unacknowledged_details"`
+	Consumers                     float64      `json:"consumers"`
+	MessageStats                  messageStats `json:"message_stats"`
+}
+
+// messageStats holds the message rate details of an object.
+type messageStats struct {
+	PublishDetails    details `json:"publish_details"`
+	DeliverGetDetails details `json:"deliver_get_details"`
+	AckDetails        details `json:"ack_details"`
+	RedeliverDetails  details `json:"redeliver_details"`
+}
+
+// details holds the current rate of change of a value and, when requested,
+// the samples of the value over time.
+type details struct {
+	Rate    float64  `json:"rate"`
+	Samples []sample `json:"samples"`
+}
+
+// sample is a single value reported by the management API. Timestamps are in
+// milliseconds.
+type sample struct {
+	Sample    float64 `json:"sample"`
+	Timestamp int64   `json:"timestamp"`
+}
+
+// metric describes how to extract a queryable metric from a statsObject.
+type metric struct {
+	// current returns the current value of a gauge metric.
+	current func(*statsObject) float64
+
+	// details returns the samples of the metric. For rate metrics the
+	// samples are cumulative counters.
+	details func(*statsObject) details
+
+	// rate indicates the metric is the rate of change of the samples.
+	rate bool
+
+	// queueOnly indicates the metric is not available for vhosts.
+	queueOnly bool
+}
+
+// metrics are the metrics supported by the plugin, keyed by their name in
+// the query.
+var metrics = map[string]*metric{
+	"messages": {
+		current: func(o *statsObject) float64 { return o.Messages },
+		details: func(o *statsObject) details { return o.MessagesDetails },
+	},
+	"messages_ready": {
+		current: func(o *statsObject) float64 { return o.MessagesReady },
+		details: func(o *statsObject) details { return o.MessagesReadyDetails },
+	},
+	"messages_unacknowledged": {
+		current: func(o *statsObject) float64 { return o.MessagesUnacknowledged },
+		details: func(o *statsObject) details { return o.MessagesUnacknowledgedDetails },
+	},
+	"consumers": {
+		current:   func(o *statsObject) float64 { return o.Consumers },
+		details:   func(o *statsObject) details { return details{} },
+		queueOnly: true,
+	},
+	"publish_rate": {
+		details: func(o *statsObject) details { return o.MessageStats.PublishDetails },
+		rate:    true,
+	},
+	"deliver_rate": {
+		details: func(o *statsObject) details { return o.MessageStats.DeliverGetDetails },
+		rate:    true,
+	},
+	"ack_rate": {
+		details: func(o *statsObject) details { return o.MessageStats.AckDetails },
+		rate:    true,
+	},
+	"redeliver_rate": {
+		details: func(o *statsObject) details { return o.MessageStats.RedeliverDetails },
+		rate:    true,
+	},
+}
+
+// extract returns the data points of the metric within the time range, in
+// chronological order. When the management API doesn't return samples, a
+// single data point with the current value is returned.
+func (m *metric) extract(o *statsObject, r sdk.TimeRange) sdk.TimestampedMetrics {
+	d := m.details(o)
+
+	samples := make([]sample, len(d.Samples))
+	copy(samples, d.Samples)
+	sort.Slice(samples, func(i, j int) bool { return samples[i].Timestamp < samples[j].Timestamp })
+
+	var out sdk.TimestampedMetrics
+
+	if m.rate {
+		// Rates are calculated from the difference between consecutive
+		// cumulative samples.
+		for i := 1; i < len(samples); i++ {
+			prev, cur := samples[i-1], samples[i]
+			elapsed := float64(cur.Timestamp-prev.Timestamp) / 1000
+			if elapsed <= 0 {
+				continue
+			}
+			out = appendInRange(out, r, cur.Timestamp, (cur.Sample-prev.Sample)/elapsed)
+		}
+		if len(out) == 0 && len(samples) < 2 {
+			out = append(out, sdk.TimestampedMetric{Timestamp: r.To, Value: d.Rate})
+		}
+		return out
+	}
+
+	for _, s := range samples {
+		out = appendInRange(out, r, s.Timestamp, s.Sample)
+	}
+	if len(out) == 0 && len(samples) == 0 {
+		out = append(out, sdk.TimestampedMetric{Timestamp: r.To, Value: m.current(o)})
+	}
+	return out
+}
+
+// appendInRange appends the data point to the metrics if its millisecond
+// timestamp is within the time range.
+func appendInRange(m sdk.TimestampedMetrics, r sdk.TimeRange, ts int64, v float64) sdk.TimestampedMetrics {
+	t := time.UnixMilli(ts)
+	if t.Before(r.From) || t.After(r.To) {
+		return m
+	}
+	return append(m, sdk.TimestampedMetric{Timestamp: t, Value: v})
+}
@@ -0,0 +1,279 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package plugin
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	hclog "github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/nomad-autoscaler/plugins"
+	"github.com/hashicorp/nomad-autoscaler/plugins/apm"
+	"github.com/hashicorp/nomad-autoscaler/plugins/base"
+	"github.com/hashicorp/nomad-autoscaler/sdk"
+	"github.com/hashicorp/nomad-autoscaler/sdk/helper/pluginconfig"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// pluginName is the unique name of the this plugin amongst APM plugins.
+	pluginName = "redis"
+
+	// configKeys represents the known configuration parameters required at
+	// varying points throughout the plugins lifecycle.
+	configKeyAddress    = "address"
+	configKeyUsername   = "username"
+	configKeyPassword   = "password"
+	configKeyDB         = "db"
+	configKeyTLSEnabled = "tls_enabled"
+	configKeyCACert     = "ca_cert"
+	configKeySkipVerify = "skip_verify"
+	configKeyTimeout    = "timeout"
+
+	// configValues are the default values used when a configuration key is not
+	// supplied by the operator that are specific to the plugin.
+	configValueAddressDefault = "127.0.0.1:6379"
+	configValueDBDefault      = "0"
+	configValueTimeoutDefault = "5s"
+
+	// The commands supported in queries.
+	commandGet   = "get"
+	commandLLen  = "llen"
+	commandXLen  = "xlen"
+	commandZCard = "zcard"
+)
+
+var (
+	PluginID = plugins.PluginID{
+		Name:       pluginName,
+		PluginType: sdk.PluginTypeAPM,
+	}
+
+	PluginConfig = &plugins.InternalPluginConfig{
+		Factory: func(l hclog.Logger) interface{} { return NewRedisPlugin(l) },
+	}
+
+	pluginInfo = &base.PluginInfo{
+		Name:       pluginName,
+		PluginType: sdk.PluginTypeAPM,
+	}
+)
+
+// Assert that APMPlugin meets the apm.APM interface.
+var _ apm.APM = (*APMPlugin)(nil)
+
+// redisClient is the subset of the Redis client used by the plugin.
+type redisClient interface {
+	Get(ctx context.Context, key string) *redis.StringCmd
+	LLen(ctx context.Context, key string) *redis.IntCmd
+	XLen(ctx context.Context, stream string) *redis.IntCmd
+	ZCard(ctx context.Context, key string) *redis.IntCmd
+	Close() error
+}
+
+// APMPlugin is the Redis implementation of the apm.APM interface. It reads
+// the value or length of a key, so work queues stored in Redis lists,
+// streams and sorted sets can be used to scale the workers which consume
+// them.
+type APMPlugin struct {
+	config map[string]string
+	logger hclog.Logger
+
+	client  redisClient
+	timeout time.Duration
+}
+
+// query is the parsed representation of a Redis query. Queries use the
+// format <command>/<key>.
+type query struct {
+	command string
+	key     string
+}
+
+// NewRedisPlugin returns the Redis implementation of the apm.APM interface.
+func NewRedisPlugin(log hclog.Logger) apm.APM {
+	return &APMPlugin{
+		logger: log,
+	}
+}
+
+// SetConfig satisfies the SetConfig function on the base.Base interface.
+func (a *APMPlugin) SetConfig(config map[string]string) error {
+
+	db, err := strconv.Atoi(pluginconfig.GetValue(config, configKeyDB, configValueDBDefault))
+	if err != nil || db < 0 {
+		return fmt.Errorf("failed to parse `%s`: must be a non-negative integer", configKeyDB)
+	}
+
+	timeout, err := time.ParseDuration(pluginconfig.GetValue(config, configKeyTimeout, configValueTimeoutDefault))
+	if err != nil {
+		return fmt.Errorf("failed to parse `%s`: %v", configKeyTimeout, err)
+	}
+
+	tlsConfig, err := generateTLSConfig(config)
+	if err != nil {
+		return fmt.Errorf("failed to parse TLS configuration: %v", err)
+	}
+
+	client := redis.NewClient(&redis.Options{
+		Addr:         pluginconfig.GetValue(config, configKeyAddress, configValueAddressDefault),
+		Username:     config[configKeyUsername],
+		Password:     config[configKeyPassword],
+		DB:           db,
+		DialTimeout:  timeout,
+		ReadTimeout:  timeout,
+		WriteTimeout: timeout,
+		TLSConfig:    tlsConfig,
+	})
+
+	if a.client != nil {
+		_ = a.client.Close()
+	}
+
+	a.config = config
+	a.client = client
+	a.timeout = timeout
+
+	return nil
+}
+
+// PluginInfo satisfies the PluginInfo function on the base.Base interface.
+func (a *APMPlugin) PluginInfo() (*base.PluginInfo, error) {
+	return pluginInfo, nil
+}
+
+// Query satisfies the Query function on the apm.APM interface. Redis only
+// exposes the current value of keys, so a single data point is returned at
+// the end of the time range. Missing keys are reported as 0, matching the
+// behaviour of the Redis length commands.
+func (a *APMPlugin) Query(q string, r sdk.TimeRange) (sdk.TimestampedMetrics, error) {
+	if a.client == nil {
+		return nil, fmt.Errorf("plugin is not configured")
+	}
+
+	parsed, err := parseQuery(q)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), a.timeout)
+	defer cancel()
+
+	value, err := a.read(ctx, parsed)
+	if err != nil {
+		return nil, fmt.Errorf("failed to run %s on key %q: %v", strings.ToUpper(parsed.command), parsed.key, err)
+	}
+
+	return sdk.TimestampedMetrics{{Timestamp: r.To, Value: value}}, nil
+}
+
+// QueryMultiple satisfies the QueryMultiple function on the apm.APM
+// interface. Queries always target a single key, so a single series is
+// returned.
+func (a *APMPlugin) QueryMultiple(q string, r sdk.TimeRange) ([]sdk.TimestampedMetrics, error) {
+	m, err := a.Query(q, r)
+	if err != nil {
+		return nil, err
+	}
+	return []sdk.TimestampedMetrics{m}, nil
+}
+
+// read runs the query command and returns the numeric result.
+func (a *APMPlugin) read(ctx context.Context, q *query) (float64, error) {
+	var cmd *redis.IntCmd
+
+	switch q.command {
+	case commandGet:
+		val, err := a.client.Get(ctx, q.key).Result()
+		if errors.Is(err, redis.Nil) {
+			a.logger.Debug("key not found, using 0 as value", "key", q.key)
+			return 0, nil
+		}
+		if err != nil {
+			return 0, err
+		}
+
+		f, err := strconv.ParseFloat(val, 64)
+		if err != nil {
+			return 0, fmt.Errorf("value %q is not a number", val)
+		}
+		return f, nil
+	case commandLLen:
+		cmd = a.client.LLen(ctx, q.key)
+	case commandXLen:
+		cmd = a.client.XLen(ctx, q.key)
+	case commandZCard:
+		cmd = a.client.ZCard(ctx, q.key)
+	}
+
+	n, err := cmd.Result()
+	if err != nil {
+		return 0, err
+	}
+	return float64(n), nil
+}
+
+// parseQuery parses and validates the input query. The command is case
+// insensitive and the key may contain slashes.
+func parseQuery(q string) (*query, error) {
+	command, key, ok := strings.Cut(q, "/")
+	if !ok || key == "" {
+		return nil, fmt.Errorf("invalid query %q, expected format <command>/<key>", q)
+	}
+
+	command = strings.ToLower(command)
+	switch command {
+	case commandGet, commandLLen, commandXLen, commandZCard:
+	default:
+		return nil, fmt.Errorf("invalid query %q, unsupported command %q, must be one of %s, %s, %s or %s",
+			q, command, commandGet, commandLLen, commandXLen, commandZCard)
+	}
+
+	return &query{command: command, key: key}, nil
+}
+
+// generateTLSConfig builds the TLS configuration of the Redis client. It
+// returns nil if TLS is not enabled.
+func generateTLSConfig(config map[string]string) (*tls.Config, error) {
+	enabled, err := strconv.ParseBool(pluginconfig.GetValue(config, configKeyTLSEnabled, "false"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %v", configKeyTLSEnabled, err)
+	}
+	if !enabled {
+		return nil, nil
+	}
+
+	tlsConfig := tls.Config{MinVersion: tls.VersionTLS12}
+
+	// Load the CA certificate if present.
+	if caCertPath := config[configKeyCACert]; caCertPath != "" {
+		caCert, err := os.ReadFile(caCertPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load CA certificate %s: %v", caCertPath, err)
+		}
+
+		caCertPool := x509.NewCertPool()
+		if !caCertPool.AppendCertsFromPEM(caCert) {
+			return nil, fmt.Errorf("failed to decode PEM file %s", caCertPath)
+		}
+		tlsConfig.RootCAs = caCertPool
+	}
+
+	if skipVerify := config[configKeySkipVerify]; skipVerify != "" {
+		skipVerifyBool, err := strconv.ParseBool(skipVerify)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s value %s: %v", configKeySkipVerify, skipVerify, err)
+		}
+		tlsConfig.InsecureSkipVerify = skipVerifyBool
+	}
+
+	return &tlsConfig, nil
+}
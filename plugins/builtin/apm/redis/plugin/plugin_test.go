@@ -0,0 +1,166 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package plugin
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/nomad-autoscaler/sdk"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeClient is a redisClient that returns values from memory.
+type fakeClient struct {
+	strings map[string]string
+	lengths map[string]int64
+	err     error
+}
+
+func (f *fakeClient) Get(_ context.Context, key string) *redis.StringCmd {
+	if f.err != nil {
+		return redis.NewStringResult("", f.err)
+	}
+	v, ok := f.strings[key]
+	if !ok {
+		return redis.NewStringResult("", redis.Nil)
+	}
+	return redis.NewStringResult(v, nil)
+}
+
+func (f *fakeClient) LLen(_ context.Context, key string) *redis.IntCmd  { return f.length(key) }
+func (f *fakeClient) XLen(_ context.Context, key string) *redis.IntCmd  { return f.length(key) }
+func (f *fakeClient) ZCard(_ context.Context, key string) *redis.IntCmd { return f.length(key) }
+func (f *fakeClient) Close() error                                      { return nil }
+
+func (f *fakeClient) length(key string) *redis.IntCmd {
+	return redis.NewIntResult(f.lengths[key], f.err)
+}
+
+func Test_parseQuery(t *testing.T) {
+	testCases := []struct {
+		name        string
+		query       string
+		expected    *query
+		expectedErr string
+	}{
+		{
+			name:     "llen",
+			query:    "llen/jobs",
+			expected: &query{command: commandLLen, key: "jobs"},
+		},
+		{
+			name:     "uppercase command and key with slashes",
+			query:    "XLEN/app/events",
+			expected: &query{command: commandXLen, key: "app/events"},
+		},
+		{
+			name:        "missing key",
+			query:       "get",
+			expectedErr: "expected format <command>/<key>",
+		},
+		{
+			name:        "unsupported command",
+			query:       "hlen/jobs",
+			expectedErr: `unsupported command "hlen"`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			q, err := parseQuery(tc.query)
+			if tc.expectedErr != "" {
+				assert.ErrorContains(t, err, tc.expectedErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, q)
+		})
+	}
+}
+
+func TestAPMPlugin_Query(t *testing.T) {
+	client := &fakeClient{
+		strings: map[string]string{"backlog": "12.5", "name": "worker"},
+		lengths: map[string]int64{"jobs": 7, "events": 3, "scheduled": 2},
+	}
+
+	testCases := []struct {
+		name          string
+		client        *fakeClient
+		query         string
+		expectedValue float64
+		expectedErr   string
+	}{
+		{name: "get", client: client, query: "get/backlog", expectedValue: 12.5},
+		{name: "get missing key", client: client, query: "get/missing", expectedValue: 0},
+		{name: "llen", client: client, query: "llen/jobs", expectedValue: 7},
+		{name: "xlen", client: client, query: "xlen/events", expectedValue: 3},
+		{name: "zcard", client: client, query: "zcard/scheduled", expectedValue: 2},
+		{name: "llen missing key", client: client, query: "llen/missing", expectedValue: 0},
+		{
+			name:        "non-numeric value",
+			client:      client,
+			query:       "get/name",
+			expectedErr: `value "worker" is not a number`,
+		},
+		{
+			name:        "client error",
+			client:      &fakeClient{err: errors.New("WRONGTYPE Operation against a key holding the wrong kind of value")},
+			query:       "llen/backlog",
+			expectedErr: `failed to run LLEN on key "backlog": WRONGTYPE`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			p := &APMPlugin{logger: hclog.NewNullLogger(), client: tc.client, timeout: time.Second}
+
+			to := time.Now()
+			metrics, err := p.Query(tc.query, sdk.TimeRange{From: to.Add(-time.Minute), To: to})
+			if tc.expectedErr != "" {
+				assert.ErrorContains(t, err, tc.expectedErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, sdk.TimestampedMetrics{{Timestamp: to, Value: tc.expectedValue}}, metrics)
+		})
+	}
+}
+
+func TestAPMPlugin_SetConfig(t *testing.T) {
+	testCases := []struct {
+		name        string
+		config      map[string]string
+		expectedErr string
+	}{
+		{name: "defaults", config: map[string]string{}},
+		{name: "tls", config: map[string]string{"tls_enabled": "true", "skip_verify": "true"}},
+		{name: "invalid db", config: map[string]string{"db": "-1"}, expectedErr: "failed to parse `db`"},
+		{name: "invalid timeout", config: map[string]string{"timeout": "soon"}, expectedErr: "failed to parse `timeout`"},
+		{name: "invalid tls_enabled", config: map[string]string{"tls_enabled": "maybe"}, expectedErr: "failed to parse tls_enabled"},
+		{
+			name:        "missing CA cert",
+			config:      map[string]string{"tls_enabled": "true", "ca_cert": "/does/not/exist"},
+			expectedErr: "failed to load CA certificate",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			p := NewRedisPlugin(hclog.NewNullLogger())
+			err := p.SetConfig(tc.config)
+			if tc.expectedErr != "" {
+				assert.ErrorContains(t, err, tc.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
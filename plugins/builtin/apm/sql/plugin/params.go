@@ -0,0 +1,142 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package plugin
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/nomad-autoscaler/sdk"
+)
+
+const (
+	// paramFrom and paramTo are the query parameters replaced with the start
+	// and end of the queried time range.
+	paramFrom = "from"
+	paramTo   = "to"
+)
+
+// timestampLayouts are the layouts accepted when the timestamp column is
+// returned as text, which is the case for MySQL unless parseTime is enabled
+// in the DSN.
+var timestampLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05.999999999-07:00",
+	"2006-01-02 15:04:05.999999999",
+}
+
+// bindTimeRange replaces the :from and :to parameters in the query with the
+// placeholders of the driver, returning the query and the arguments to run
+// it with. Parameters within quoted strings and identifiers, and Postgres
+// type casts such as ::text, are left untouched.
+func bindTimeRange(driver, q string, r sdk.TimeRange) (string, []interface{}) {
+	var (
+		out   strings.Builder
+		args  []interface{}
+		quote byte
+	)
+
+	for i := 0; i < len(q); i++ {
+		c := q[i]
+
+		// Copy quoted sections as-is, tracking when they end.
+		if quote != 0 {
+			out.WriteByte(c)
+			if c == quote {
+				quote = 0
+			}
+			continue
+		}
+		if c == '\'' || c == '"' || c == '`' {
+			quote = c
+			out.WriteByte(c)
+			continue
+		}
+
+		if c == ':' {
+			// Skip type casts, writing both colons.
+			if i+1 < len(q) && q[i+1] == ':' {
+				out.WriteString("::")
+				i++
+				continue
+			}
+
+			name := paramName(q[i+1:])
+			var value time.Time
+			switch name {
+			case paramFrom:
+				value = r.From
+			case paramTo:
+				value = r.To
+			default:
+				out.WriteByte(c)
+				continue
+			}
+
+			args = append(args, value)
+			if driver == driverPostgres {
+				out.WriteString("$" + strconv.Itoa(len(args)))
+			} else {
+				out.WriteByte('?')
+			}
+			i += len(name)
+			continue
+		}
+
+		out.WriteByte(c)
+	}
+
+	return out.String(), args
+}
+
+// paramName returns the identifier at the start of s.
+func paramName(s string) string {
+	end := 0
+	for end < len(s) {
+		c := s[end]
+		if c != '_' && (c < 'a' || c > 'z') && (c < 'A' || c > 'Z') && (c < '0' || c > '9') {
+			break
+		}
+		end++
+	}
+	return s[:end]
+}
+
+// parseTimestamp converts the value of the timestamp column into a time.
+// Integer values are interpreted as Unix timestamps in seconds.
+func parseTimestamp(v interface{}) (time.Time, error) {
+	switch ts := v.(type) {
+	case time.Time:
+		return ts, nil
+	case int64:
+		return time.Unix(ts, 0), nil
+	case float64:
+		return time.Unix(0, int64(ts*float64(time.Second))), nil
+	case []byte:
+		return parseTimestampString(string(ts))
+	case string:
+		return parseTimestampString(ts)
+	case nil:
+		return time.Time{}, fmt.Errorf("timestamp is null")
+	default:
+		return time.Time{}, fmt.Errorf("unsupported timestamp type %T", v)
+	}
+}
+
+// parseTimestampString parses a textual timestamp, which may also be a Unix
+// timestamp in seconds.
+func parseTimestampString(s string) (time.Time, error) {
+	if sec, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return time.Unix(sec, 0), nil
+	}
+
+	for _, layout := range timestampLayouts {
+		if ts, err := time.Parse(layout, s); err == nil {
+			return ts, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unsupported timestamp format %q", s)
+}
@@ -0,0 +1,223 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package plugin
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	_ "github.com/go-sql-driver/mysql"
+	hclog "github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/nomad-autoscaler/plugins"
+	"github.com/hashicorp/nomad-autoscaler/plugins/apm"
+	"github.com/hashicorp/nomad-autoscaler/plugins/base"
+	"github.com/hashicorp/nomad-autoscaler/sdk"
+	"github.com/hashicorp/nomad-autoscaler/sdk/helper/pluginconfig"
+	_ "github.com/lib/pq"
+)
+
+const (
+	// pluginName is the unique name of the this plugin amongst APM plugins.
+	pluginName = "sql"
+
+	// configKeys represents the known configuration parameters required at
+	// varying points throughout the plugins lifecycle.
+	configKeyDriver       = "driver"
+	configKeyDSN          = "dsn"
+	configKeyMaxOpenConns = "max_open_conns"
+	configKeyTimeout      = "timeout"
+
+	// configValues are the default values used when a configuration key is not
+	// supplied by the operator that are specific to the plugin.
+	configValueMaxOpenConnsDefault = "2"
+	configValueTimeoutDefault      = "10s"
+
+	// The database drivers supported by the plugin.
+	driverPostgres = "postgres"
+	driverMySQL    = "mysql"
+)
+
+var (
+	PluginID = plugins.PluginID{
+		Name:       pluginName,
+		PluginType: sdk.PluginTypeAPM,
+	}
+
+	PluginConfig = &plugins.InternalPluginConfig{
+		Factory: func(l hclog.Logger) interface{} { return NewSQLPlugin(l) },
+	}
+
+	pluginInfo = &base.PluginInfo{
+		Name:       pluginName,
+		PluginType: sdk.PluginTypeAPM,
+	}
+)
+
+// Assert that APMPlugin meets the apm.APM interface.
+var _ apm.APM = (*APMPlugin)(nil)
+
+// APMPlugin is the SQL implementation of the apm.APM interface. It runs the
+// query against a PostgreSQL or MySQL database, so scaling signals stored in
+// application databases, such as the backlog of a jobs table, can be used
+// without exporting them to a metrics store first.
+type APMPlugin struct {
+	config map[string]string
+	logger hclog.Logger
+
+	db      *sql.DB
+	dbLock  sync.RWMutex
+	driver  string
+	timeout time.Duration
+}
+
+// NewSQLPlugin returns the SQL implementation of the apm.APM interface.
+func NewSQLPlugin(log hclog.Logger) apm.APM {
+	return &APMPlugin{
+		logger: log,
+	}
+}
+
+// SetConfig satisfies the SetConfig function on the base.Base interface.
+func (a *APMPlugin) SetConfig(config map[string]string) error {
+
+	driver := config[configKeyDriver]
+	switch driver {
+	case driverPostgres, driverMySQL:
+	case "":
+		return fmt.Errorf("missing required configuration `%s`", configKeyDriver)
+	default:
+		return fmt.Errorf("unsupported `%s` %q, must be %q or %q", configKeyDriver, driver, driverPostgres, driverMySQL)
+	}
+
+	dsn := config[configKeyDSN]
+	if dsn == "" {
+		return fmt.Errorf("missing required configuration `%s`", configKeyDSN)
+	}
+
+	maxOpenConns, err := strconv.Atoi(pluginconfig.GetValue(config, configKeyMaxOpenConns, configValueMaxOpenConnsDefault))
+	if err != nil || maxOpenConns < 1 {
+		return fmt.Errorf("failed to parse `%s`: must be a positive integer", configKeyMaxOpenConns)
+	}
+
+	timeout, err := time.ParseDuration(pluginconfig.GetValue(config, configKeyTimeout, configValueTimeoutDefault))
+	if err != nil {
+		return fmt.Errorf("failed to parse `%s`: %v", configKeyTimeout, err)
+	}
+
+	// Opening the database only validates the arguments, connections are
+	// established lazily so the agent can start while the database is
+	// unavailable.
+	db, err := sql.Open(driver, dsn)
+	if err != nil {
+		return fmt.Errorf("failed to open %s database: %v", driver, err)
+	}
+	db.SetMaxOpenConns(maxOpenConns)
+	db.SetMaxIdleConns(maxOpenConns)
+
+	a.dbLock.Lock()
+	defer a.dbLock.Unlock()
+
+	if a.db != nil {
+		_ = a.db.Close()
+	}
+
+	a.config = config
+	a.db = db
+	a.driver = driver
+	a.timeout = timeout
+
+	return nil
+}
+
+// PluginInfo satisfies the PluginInfo function on the base.Base interface.
+func (a *APMPlugin) PluginInfo() (*base.PluginInfo, error) {
+	return pluginInfo, nil
+}
+
+// Query satisfies the Query function on the apm.APM interface. The query can
+// reference the start and end of the time range using the :from and :to
+// parameters. It must return rows of (timestamp, value) or rows with a single
+// value column, which are assigned the end of the time range as timestamp.
+func (a *APMPlugin) Query(q string, r sdk.TimeRange) (sdk.TimestampedMetrics, error) {
+	a.dbLock.RLock()
+	defer a.dbLock.RUnlock()
+
+	if a.db == nil {
+		return nil, fmt.Errorf("plugin is not configured")
+	}
+
+	stmt, args := bindTimeRange(a.driver, q, r)
+
+	ctx, cancel := context.WithTimeout(context.Background(), a.timeout)
+	defer cancel()
+
+	rows, err := a.db.QueryContext(ctx, stmt, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to run query: %v", err)
+	}
+	defer rows.Close()
+
+	cols, err := rows.Columns()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read query columns: %v", err)
+	}
+	if len(cols) != 1 && len(cols) != 2 {
+		return nil, fmt.Errorf("query must return 1 or 2 columns, got %d", len(cols))
+	}
+
+	var result sdk.TimestampedMetrics
+	for rows.Next() {
+		var (
+			rawTS interface{}
+			value sql.NullFloat64
+		)
+
+		if len(cols) == 1 {
+			err = rows.Scan(&value)
+		} else {
+			err = rows.Scan(&rawTS, &value)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read query row: %v", err)
+		}
+
+		if !value.Valid {
+			a.logger.Debug("skipping row with null value", "query", q)
+			continue
+		}
+
+		ts := r.To
+		if len(cols) == 2 {
+			if ts, err = parseTimestamp(rawTS); err != nil {
+				return nil, fmt.Errorf("failed to parse timestamp column: %v", err)
+			}
+		}
+
+		result = append(result, sdk.TimestampedMetric{Timestamp: ts, Value: value.Float64})
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read query rows: %v", err)
+	}
+
+	if len(result) == 0 {
+		a.logger.Warn("empty time series response from SQL, try a wider query window", "query", q)
+	}
+
+	return result, nil
+}
+
+// QueryMultiple satisfies the QueryMultiple function on the apm.APM
+// interface. Queries always return a single series.
+func (a *APMPlugin) QueryMultiple(q string, r sdk.TimeRange) ([]sdk.TimestampedMetrics, error) {
+	m, err := a.Query(q, r)
+	if err != nil {
+		return nil, err
+	}
+	return []sdk.TimestampedMetrics{m}, nil
+}
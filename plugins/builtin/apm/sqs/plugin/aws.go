@@ -0,0 +1,153 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package plugin
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+)
+
+const (
+	// sqsAPIVersion is the version of the SQS query API used to read queue
+	// attributes.
+	sqsAPIVersion = "2012-11-05"
+
+	// sqsServiceName is the name of the service used to sign requests.
+	sqsServiceName = "sqs"
+)
+
+// getQueueAttributesResponse is the body returned by the SQS query API for
+// GetQueueAttributes requests.
+type getQueueAttributesResponse struct {
+	Result struct {
+		Attributes []struct {
+			Name  string `xml:"Name"`
+			Value string `xml:"Value"`
+		} `xml:"Attribute"`
+	} `xml:"GetQueueAttributesResult"`
+}
+
+// errorResponse is the body returned by the SQS query API on errors.
+type errorResponse struct {
+	Error struct {
+		Code    string `xml:"Code"`
+		Message string `xml:"Message"`
+	} `xml:"Error"`
+}
+
+// setupAWSConfig takes the passed config mapping and loads the region and
+// credentials used to sign requests to the SQS API. The credentials are
+// resolved using the default AWS chain, which includes IAM instance and task
+// roles, unless static credentials are configured.
+func (a *APMPlugin) setupAWSConfig(config map[string]string) error {
+
+	// Load our default AWS config. This handles pulling configuration from
+	// default profiles and environment variables.
+	cfg, err := awsconfig.LoadDefaultConfig(context.TODO())
+	if err != nil {
+		return fmt.Errorf("failed to load default AWS config: %v", err)
+	}
+
+	// The region can be set by the operator, otherwise it is read from the
+	// queue URL on each query.
+	if region, ok := config[configKeyRegion]; ok && region != "" {
+		cfg.Region = region
+	}
+
+	// Attempt to pull access credentials for the AWS client from the user
+	// supplied configuration. In order to use these static credentials both
+	// the access key and secret key need to be present; the session token is
+	// optional.
+	keyID := config[configKeyAccessID]
+	secretKey := config[configKeySecretKey]
+	session := config[configKeySessionToken]
+
+	if keyID != "" && secretKey != "" {
+		a.logger.Trace("setting AWS access credentials from config map")
+		cfg.Credentials = credentials.NewStaticCredentialsProvider(keyID, secretKey, session)
+	} else {
+		a.logger.Trace("Using default AWS credential chain")
+	}
+
+	if cfg.Credentials == nil {
+		return fmt.Errorf("failed to find AWS credentials")
+	}
+
+	a.region = cfg.Region
+	a.credentials = cfg.Credentials
+	return nil
+}
+
+// getQueueAttributes reads the attributes of the queue using a signed
+// request to the SQS query API.
+func (a *APMPlugin) getQueueAttributes(ctx context.Context, queueURL, region string, names []string) (map[string]string, error) {
+
+	form := url.Values{}
+	form.Set("Action", "GetQueueAttributes")
+	form.Set("Version", sqsAPIVersion)
+	for i, name := range names {
+		form.Set("AttributeName."+strconv.Itoa(i+1), name)
+	}
+	body := form.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, queueURL, strings.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded; charset=utf-8")
+
+	creds, err := a.credentials.Retrieve(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve AWS credentials: %v", err)
+	}
+
+	hash := sha256.Sum256([]byte(body))
+	err = v4.NewSigner().SignHTTP(ctx, creds, req, hex.EncodeToString(hash[:]), sqsServiceName, region, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign request: %v", err)
+	}
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %v", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		var errResp errorResponse
+		if err := xml.Unmarshal(respBody, &errResp); err == nil && errResp.Error.Code != "" {
+			return nil, fmt.Errorf("%s: %s", errResp.Error.Code, errResp.Error.Message)
+		}
+		return nil, fmt.Errorf("unexpected response code %d", resp.StatusCode)
+	}
+
+	var attrResp getQueueAttributesResponse
+	if err := xml.Unmarshal(respBody, &attrResp); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %v", err)
+	}
+
+	attrs := make(map[string]string, len(attrResp.Result.Attributes))
+	for _, attr := range attrResp.Result.Attributes {
+		attrs[attr.Name] = attr.Value
+	}
+	return attrs, nil
+}
@@ -0,0 +1,201 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package plugin
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	hclog "github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/nomad-autoscaler/plugins"
+	"github.com/hashicorp/nomad-autoscaler/plugins/apm"
+	"github.com/hashicorp/nomad-autoscaler/plugins/base"
+	"github.com/hashicorp/nomad-autoscaler/sdk"
+	"github.com/hashicorp/nomad-autoscaler/sdk/helper/pluginconfig"
+)
+
+const (
+	// pluginName is the unique name of the this plugin amongst APM plugins.
+	pluginName = "sqs"
+
+	// configKeys represents the known configuration parameters required at
+	// varying points throughout the plugins lifecycle.
+	configKeyRegion       = "aws_region"
+	configKeyAccessID     = "aws_access_key_id"
+	configKeySecretKey    = "aws_secret_access_key"
+	configKeySessionToken = "aws_session_token"
+	configKeyTimeout      = "timeout"
+
+	// configValues are the default values used when a configuration key is not
+	// supplied by the operator that are specific to the plugin.
+	configValueTimeoutDefault = "10s"
+)
+
+var (
+	PluginID = plugins.PluginID{
+		Name:       pluginName,
+		PluginType: sdk.PluginTypeAPM,
+	}
+
+	PluginConfig = &plugins.InternalPluginConfig{
+		Factory: func(l hclog.Logger) interface{} { return NewSQSPlugin(l) },
+	}
+
+	pluginInfo = &base.PluginInfo{
+		Name:       pluginName,
+		PluginType: sdk.PluginTypeAPM,
+	}
+
+	// metricAttributes maps the metrics supported in queries to the SQS queue
+	// attributes that are summed to calculate them.
+	metricAttributes = map[string][]string{
+		"messages":             {"ApproximateNumberOfMessages"},
+		"messages_not_visible": {"ApproximateNumberOfMessagesNotVisible"},
+		"messages_delayed":     {"ApproximateNumberOfMessagesDelayed"},
+		"messages_total":       {"ApproximateNumberOfMessages", "ApproximateNumberOfMessagesNotVisible"},
+	}
+)
+
+// Assert that APMPlugin meets the apm.APM interface.
+var _ apm.APM = (*APMPlugin)(nil)
+
+// APMPlugin is the AWS SQS implementation of the apm.APM interface. It reads
+// the approximate number of messages in a queue using the SQS query API, so
+// the queue depth can be used to scale the workers which consume it.
+type APMPlugin struct {
+	config map[string]string
+	logger hclog.Logger
+
+	client      *http.Client
+	credentials aws.CredentialsProvider
+	region      string
+	timeout     time.Duration
+}
+
+// query is the parsed representation of an SQS query. Queries use the format
+// <metric>/<queue_url>.
+type query struct {
+	metric   string
+	queueURL *url.URL
+}
+
+// NewSQSPlugin returns the AWS SQS implementation of the apm.APM interface.
+func NewSQSPlugin(log hclog.Logger) apm.APM {
+	return &APMPlugin{
+		logger: log,
+	}
+}
+
+// SetConfig satisfies the SetConfig function on the base.Base interface.
+func (a *APMPlugin) SetConfig(config map[string]string) error {
+
+	timeout, err := time.ParseDuration(pluginconfig.GetValue(config, configKeyTimeout, configValueTimeoutDefault))
+	if err != nil {
+		return fmt.Errorf("failed to parse `%s`: %v", configKeyTimeout, err)
+	}
+
+	a.config = config
+	a.timeout = timeout
+	a.client = &http.Client{Timeout: timeout}
+
+	return a.setupAWSConfig(config)
+}
+
+// PluginInfo satisfies the PluginInfo function on the base.Base interface.
+func (a *APMPlugin) PluginInfo() (*base.PluginInfo, error) {
+	return pluginInfo, nil
+}
+
+// Query satisfies the Query function on the apm.APM interface. SQS only
+// exposes the current queue attributes, so a single data point is returned
+// at the end of the time range.
+func (a *APMPlugin) Query(q string, r sdk.TimeRange) (sdk.TimestampedMetrics, error) {
+	if a.client == nil {
+		return nil, fmt.Errorf("plugin is not configured")
+	}
+
+	parsed, err := parseQuery(q)
+	if err != nil {
+		return nil, err
+	}
+
+	region := a.region
+	if region == "" {
+		region = queueRegion(parsed.queueURL)
+	}
+	if region == "" {
+		return nil, fmt.Errorf("failed to determine AWS region of queue %s, set `%s`", parsed.queueURL, configKeyRegion)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), a.timeout)
+	defer cancel()
+
+	attrNames := metricAttributes[parsed.metric]
+	attrs, err := a.getQueueAttributes(ctx, parsed.queueURL.String(), region, attrNames)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get attributes of queue %s: %v", parsed.queueURL, err)
+	}
+
+	var value float64
+	for _, name := range attrNames {
+		raw, ok := attrs[name]
+		if !ok {
+			return nil, fmt.Errorf("attribute %s missing from response", name)
+		}
+		v, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value for attribute %s: %v", name, err)
+		}
+		value += v
+	}
+
+	return sdk.TimestampedMetrics{{Timestamp: r.To, Value: value}}, nil
+}
+
+// QueryMultiple satisfies the QueryMultiple function on the apm.APM
+// interface. Queries always target a single queue, so a single series is
+// returned.
+func (a *APMPlugin) QueryMultiple(q string, r sdk.TimeRange) ([]sdk.TimestampedMetrics, error) {
+	m, err := a.Query(q, r)
+	if err != nil {
+		return nil, err
+	}
+	return []sdk.TimestampedMetrics{m}, nil
+}
+
+// parseQuery parses and validates the input query.
+func parseQuery(q string) (*query, error) {
+	metric, rawURL, ok := strings.Cut(q, "/")
+	if !ok || rawURL == "" {
+		return nil, fmt.Errorf("invalid query %q, expected format <metric>/<queue_url>", q)
+	}
+
+	if _, ok := metricAttributes[metric]; !ok {
+		return nil, fmt.Errorf("invalid query %q, unsupported metric %q", q, metric)
+	}
+
+	u, err := url.ParseRequestURI(rawURL)
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("invalid query %q, failed to parse queue URL", q)
+	}
+
+	return &query{metric: metric, queueURL: u}, nil
+}
+
+// queueRegion returns the AWS region from the queue URL host, which has the
+// format sqs.<region>.amazonaws.com. An empty string is returned if the host
+// doesn't match the format.
+func queueRegion(u *url.URL) string {
+	parts := strings.Split(u.Hostname(), ".")
+	if len(parts) >= 3 && parts[0] == "sqs" {
+		return parts[1]
+	}
+	return ""
+}
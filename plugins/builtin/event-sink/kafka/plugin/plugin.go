@@ -0,0 +1,125 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package plugin
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	hclog "github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/nomad-autoscaler/plugins"
+	"github.com/hashicorp/nomad-autoscaler/plugins/base"
+	"github.com/hashicorp/nomad-autoscaler/plugins/builtin/internal/kafka"
+	"github.com/hashicorp/nomad-autoscaler/plugins/eventsink"
+	"github.com/hashicorp/nomad-autoscaler/sdk"
+)
+
+const (
+	// pluginName is the unique name of the this plugin amongst EventSink
+	// plugins.
+	pluginName = "kafka"
+
+	// configKeyTopic is the topic the events are produced to.
+	configKeyTopic = "topic"
+
+	// configValueTimeoutDefault is the default timeout of each request sent
+	// to the Kafka brokers.
+	configValueTimeoutDefault = 5 * time.Second
+)
+
+var (
+	PluginConfig = &plugins.InternalPluginConfig{
+		Factory: func(l hclog.Logger) interface{} { return NewKafkaPlugin(l) },
+	}
+
+	pluginInfo = &base.PluginInfo{
+		Name:       pluginName,
+		PluginType: sdk.PluginTypeEventSink,
+	}
+)
+
+// Assert that EventSinkPlugin meets the eventsink.EventSink interface.
+var _ eventsink.EventSink = (*EventSinkPlugin)(nil)
+
+// producer writes records to Kafka.
+type producer interface {
+	Produce(topic string, key, value []byte) error
+	Close()
+}
+
+// EventSinkPlugin is the Kafka implementation of the eventsink.EventSink
+// interface. Events are produced as JSON records to the configured topic,
+// talking to the Kafka brokers directly. The policy ID is used as the record
+// key so events of the same policy are kept in order within a partition.
+type EventSinkPlugin struct {
+	config map[string]string
+	logger hclog.Logger
+
+	clientLock sync.RWMutex
+	client     producer
+	topic      string
+}
+
+// NewKafkaPlugin returns the Kafka implementation of the
+// eventsink.EventSink interface.
+func NewKafkaPlugin(log hclog.Logger) *EventSinkPlugin {
+	return &EventSinkPlugin{
+		logger: log,
+	}
+}
+
+// SetConfig satisfies the SetConfig function on the base.Base interface.
+func (s *EventSinkPlugin) SetConfig(config map[string]string) error {
+
+	topic := config[configKeyTopic]
+	if topic == "" {
+		return fmt.Errorf("missing required field `%s`", configKeyTopic)
+	}
+
+	opts, err := kafka.ParseConfig(config, configValueTimeoutDefault)
+	if err != nil {
+		return err
+	}
+
+	s.clientLock.Lock()
+	defer s.clientLock.Unlock()
+
+	if s.client != nil {
+		s.client.Close()
+	}
+	s.config = config
+	s.client = kafka.NewClient(opts)
+	s.topic = topic
+
+	return nil
+}
+
+// PluginInfo satisfies the PluginInfo function on the base.Base interface.
+func (s *EventSinkPlugin) PluginInfo() (*base.PluginInfo, error) {
+	return pluginInfo, nil
+}
+
+// Send satisfies the Send function on the eventsink.EventSink interface.
+func (s *EventSinkPlugin) Send(event *sdk.ScalingEvent) error {
+	s.clientLock.RLock()
+	client, topic := s.client, s.topic
+	s.clientLock.RUnlock()
+
+	if client == nil {
+		return fmt.Errorf("plugin is not configured")
+	}
+
+	value, err := eventsink.EncodeJSON(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode event: %v", err)
+	}
+
+	if err := client.Produce(topic, []byte(event.PolicyID), value); err != nil {
+		return fmt.Errorf("failed to produce event: %v", err)
+	}
+
+	s.logger.Trace("produced event", "topic", topic, "event_id", event.ID)
+	return nil
+}
@@ -0,0 +1,95 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package plugin
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/nomad-autoscaler/sdk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeProducer is a producer recording the records it receives.
+type fakeProducer struct {
+	err     error
+	topic   string
+	key     string
+	value   string
+	records int
+}
+
+func (f *fakeProducer) Produce(topic string, key, value []byte) error {
+	f.topic, f.key, f.value = topic, string(key), string(value)
+	f.records++
+	return f.err
+}
+
+func (f *fakeProducer) Close() {}
+
+func TestEventSinkPlugin_Send(t *testing.T) {
+	testCases := []struct {
+		name        string
+		produceErr  error
+		expectedErr string
+	}{
+		{
+			name: "success",
+		},
+		{
+			name:        "produce error",
+			produceErr:  errors.New("NOT_ENOUGH_REPLICAS"),
+			expectedErr: "failed to produce event: NOT_ENOUGH_REPLICAS",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			producer := &fakeProducer{err: tc.produceErr}
+			p := &EventSinkPlugin{
+				logger: hclog.NewNullLogger(),
+				client: producer,
+				topic:  "autoscaler-events",
+			}
+
+			err := p.Send(&sdk.ScalingEvent{
+				ID:       "event-1",
+				PolicyID: "policy-1",
+				Action:   sdk.ScalingAction{Count: 2, Direction: sdk.ScaleDirectionUp},
+			})
+			if tc.expectedErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.expectedErr)
+			} else {
+				require.NoError(t, err)
+			}
+
+			require.Equal(t, 1, producer.records)
+			assert.Equal(t, "autoscaler-events", producer.topic)
+			assert.Equal(t, "policy-1", producer.key)
+			assert.Contains(t, producer.value, `"id":"event-1"`)
+		})
+	}
+}
+
+func TestEventSinkPlugin_SetConfig(t *testing.T) {
+	p := NewKafkaPlugin(hclog.NewNullLogger())
+
+	err := p.Send(&sdk.ScalingEvent{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "plugin is not configured")
+
+	err = p.SetConfig(map[string]string{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "missing required field `topic`")
+
+	err = p.SetConfig(map[string]string{"topic": "events", "timeout": "soon"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to parse `timeout`")
+
+	require.NoError(t, p.SetConfig(map[string]string{"topic": "events", "brokers": "kafka-1:9092"}))
+	assert.Equal(t, "events", p.topic)
+}
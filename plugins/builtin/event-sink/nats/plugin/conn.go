@@ -0,0 +1,182 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package plugin
+
+import (
+	"bufio"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+)
+
+// connOptions are the options used to connect to the NATS server.
+type connOptions struct {
+	address       string
+	tls           bool
+	tlsSkipVerify bool
+	token         string
+	username      string
+	password      string
+	timeout       time.Duration
+}
+
+// conn is a minimal NATS client connection which only supports publishing
+// messages. Each publish is followed by a PING so the server acknowledges
+// it has processed the message, or returns an error. Reconnecting is left to
+// the caller, which dials a new connection after any failure.
+type conn struct {
+	netConn net.Conn
+	reader  *bufio.Reader
+	timeout time.Duration
+}
+
+// serverInfo is the subset of the NATS INFO message used by the client.
+type serverInfo struct {
+	TLSRequired  bool `json:"tls_required"`
+	AuthRequired bool `json:"auth_required"`
+}
+
+// connectRequest is the NATS CONNECT message sent by the client.
+type connectRequest struct {
+	Verbose   bool   `json:"verbose"`
+	Pedantic  bool   `json:"pedantic"`
+	Name      string `json:"name"`
+	Lang      string `json:"lang"`
+	Protocol  int    `json:"protocol"`
+	AuthToken string `json:"auth_token,omitempty"`
+	User      string `json:"user,omitempty"`
+	Pass      string `json:"pass,omitempty"`
+}
+
+// dial connects to the NATS server and performs the connection handshake.
+func dial(opts *connOptions) (*conn, error) {
+	netConn, err := net.DialTimeout("tcp", opts.address, opts.timeout)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &conn{
+		netConn: netConn,
+		reader:  bufio.NewReader(netConn),
+		timeout: opts.timeout,
+	}
+
+	if err := c.handshake(opts); err != nil {
+		c.close()
+		return nil, err
+	}
+	return c, nil
+}
+
+// handshake reads the server INFO message, upgrades the connection to TLS if
+// needed and sends the CONNECT message.
+func (c *conn) handshake(opts *connOptions) error {
+	_ = c.netConn.SetDeadline(time.Now().Add(c.timeout))
+
+	line, err := c.readLine()
+	if err != nil {
+		return err
+	}
+	if !strings.HasPrefix(line, "INFO ") {
+		return fmt.Errorf("unexpected message from server: %q", line)
+	}
+
+	var info serverInfo
+	if err := json.Unmarshal([]byte(strings.TrimPrefix(line, "INFO ")), &info); err != nil {
+		return fmt.Errorf("failed to parse server info: %v", err)
+	}
+
+	if info.TLSRequired && !opts.tls {
+		return errors.New("server requires TLS, use the tls:// address scheme")
+	}
+
+	if opts.tls {
+		host, _, err := net.SplitHostPort(opts.address)
+		if err != nil {
+			return err
+		}
+		tlsConn := tls.Client(c.netConn, &tls.Config{
+			ServerName:         host,
+			InsecureSkipVerify: opts.tlsSkipVerify,
+			MinVersion:         tls.VersionTLS12,
+		})
+		if err := tlsConn.Handshake(); err != nil {
+			return fmt.Errorf("failed to perform TLS handshake: %v", err)
+		}
+		c.netConn = tlsConn
+		c.reader = bufio.NewReader(tlsConn)
+	}
+
+	req, err := json.Marshal(connectRequest{
+		Name:      "nomad-autoscaler",
+		Lang:      "go",
+		Protocol:  1,
+		AuthToken: opts.token,
+		User:      opts.username,
+		Pass:      opts.password,
+	})
+	if err != nil {
+		return err
+	}
+
+	if _, err := fmt.Fprintf(c.netConn, "CONNECT %s\r\nPING\r\n", req); err != nil {
+		return err
+	}
+	return c.waitPong()
+}
+
+// publish sends the payload to the subject and waits for the server to
+// acknowledge it.
+func (c *conn) publish(subject string, payload []byte) error {
+	_ = c.netConn.SetDeadline(time.Now().Add(c.timeout))
+
+	msg := fmt.Sprintf("PUB %s %d\r\n%s\r\nPING\r\n", subject, len(payload), payload)
+	if _, err := c.netConn.Write([]byte(msg)); err != nil {
+		return err
+	}
+	return c.waitPong()
+}
+
+// waitPong reads messages from the server until a PONG is received. Server
+// PINGs are answered while waiting and errors are returned.
+func (c *conn) waitPong() error {
+	for {
+		line, err := c.readLine()
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case line == "PONG":
+			return nil
+		case line == "PING":
+			if _, err := c.netConn.Write([]byte("PONG\r\n")); err != nil {
+				return err
+			}
+		case line == "+OK", strings.HasPrefix(line, "INFO "):
+		case strings.HasPrefix(line, "-ERR"):
+			return fmt.Errorf("server error: %s", strings.Trim(strings.TrimPrefix(line, "-ERR"), " '"))
+		default:
+			return fmt.Errorf("unexpected message from server: %q", line)
+		}
+	}
+}
+
+// readLine reads a single protocol line from the server.
+func (c *conn) readLine() (string, error) {
+	line, err := c.reader.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// close closes the connection to the server.
+func (c *conn) close() {
+	_ = c.netConn.Close()
+}
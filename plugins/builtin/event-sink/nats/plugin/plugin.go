@@ -0,0 +1,176 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package plugin
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	hclog "github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/nomad-autoscaler/plugins"
+	"github.com/hashicorp/nomad-autoscaler/plugins/base"
+	"github.com/hashicorp/nomad-autoscaler/plugins/eventsink"
+	"github.com/hashicorp/nomad-autoscaler/sdk"
+	"github.com/hashicorp/nomad-autoscaler/sdk/helper/pluginconfig"
+)
+
+const (
+	// pluginName is the unique name of the this plugin amongst EventSink
+	// plugins.
+	pluginName = "nats"
+
+	// configKeys represents the known configuration parameters required at
+	// varying points throughout the plugins lifecycle.
+	configKeyAddress       = "address"
+	configKeySubject       = "subject"
+	configKeyToken         = "token"
+	configKeyUsername      = "username"
+	configKeyPassword      = "password"
+	configKeyTimeout       = "timeout"
+	configKeyTLSSkipVerify = "tls_skip_verify"
+
+	// configValues are the default values used when a configuration key is not
+	// supplied by the operator that are specific to the plugin.
+	configValueAddressDefault = "nats://127.0.0.1:4222"
+	configValueTimeoutDefault = "5s"
+)
+
+var (
+	PluginConfig = &plugins.InternalPluginConfig{
+		Factory: func(l hclog.Logger) interface{} { return NewNATSPlugin(l) },
+	}
+
+	pluginInfo = &base.PluginInfo{
+		Name:       pluginName,
+		PluginType: sdk.PluginTypeEventSink,
+	}
+)
+
+// Assert that EventSinkPlugin meets the eventsink.EventSink interface.
+var _ eventsink.EventSink = (*EventSinkPlugin)(nil)
+
+// EventSinkPlugin is the NATS implementation of the eventsink.EventSink
+// interface. Events are published as JSON messages to the configured subject.
+//
+// The plugin uses a minimal client which only implements core NATS
+// publishing to a single server, authenticating with a token or a username
+// and password. Cluster discovery, NKey and JWT credentials, and JetStream
+// acknowledgements are not supported, so servers requiring them can't be used
+// with this plugin.
+type EventSinkPlugin struct {
+	logger hclog.Logger
+
+	// connLock protects the connection, which is created on the first event
+	// sent and recreated after any failure.
+	connLock sync.Mutex
+	conn     *conn
+	opts     *connOptions
+	subject  string
+}
+
+// NewNATSPlugin returns the NATS implementation of the eventsink.EventSink
+// interface.
+func NewNATSPlugin(log hclog.Logger) *EventSinkPlugin {
+	return &EventSinkPlugin{
+		logger: log,
+	}
+}
+
+// SetConfig satisfies the SetConfig function on the base.Base interface.
+func (s *EventSinkPlugin) SetConfig(config map[string]string) error {
+
+	subject := config[configKeySubject]
+	if subject == "" {
+		return fmt.Errorf("missing required field `%s`", configKeySubject)
+	}
+	if strings.ContainsAny(subject, " \t\r\n*>") {
+		return fmt.Errorf("invalid `%s` %q, must not contain whitespace or wildcards", configKeySubject, subject)
+	}
+
+	addr, err := url.Parse(pluginconfig.GetValue(config, configKeyAddress, configValueAddressDefault))
+	if err != nil {
+		return fmt.Errorf("failed to parse `%s`: %v", configKeyAddress, err)
+	}
+	if addr.Scheme != "nats" && addr.Scheme != "tls" {
+		return fmt.Errorf("invalid `%s` scheme %q, must be nats or tls", configKeyAddress, addr.Scheme)
+	}
+
+	timeout, err := time.ParseDuration(pluginconfig.GetValue(config, configKeyTimeout, configValueTimeoutDefault))
+	if err != nil {
+		return fmt.Errorf("failed to parse `%s`: %v", configKeyTimeout, err)
+	}
+
+	opts := &connOptions{
+		address:       addr.Host,
+		tls:           addr.Scheme == "tls",
+		tlsSkipVerify: config[configKeyTLSSkipVerify] == "true",
+		token:         config[configKeyToken],
+		username:      config[configKeyUsername],
+		password:      config[configKeyPassword],
+		timeout:       timeout,
+	}
+
+	// Credentials can also be provided as part of the address.
+	if addr.User != nil && opts.username == "" {
+		opts.username = addr.User.Username()
+		opts.password, _ = addr.User.Password()
+	}
+
+	s.connLock.Lock()
+	defer s.connLock.Unlock()
+
+	// Close any existing connection so the new config is used on the next
+	// event.
+	if s.conn != nil {
+		s.conn.close()
+		s.conn = nil
+	}
+	s.opts = opts
+	s.subject = subject
+
+	return nil
+}
+
+// PluginInfo satisfies the PluginInfo function on the base.Base interface.
+func (s *EventSinkPlugin) PluginInfo() (*base.PluginInfo, error) {
+	return pluginInfo, nil
+}
+
+// Send satisfies the Send function on the eventsink.EventSink interface.
+func (s *EventSinkPlugin) Send(event *sdk.ScalingEvent) error {
+
+	payload, err := eventsink.EncodeJSON(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode event: %v", err)
+	}
+
+	s.connLock.Lock()
+	defer s.connLock.Unlock()
+
+	if s.opts == nil {
+		return fmt.Errorf("plugin is not configured")
+	}
+
+	if s.conn == nil {
+		c, err := dial(s.opts)
+		if err != nil {
+			return fmt.Errorf("failed to connect to NATS: %v", err)
+		}
+		s.conn = c
+	}
+
+	if err := s.conn.publish(s.subject, payload); err != nil {
+		// The connection state is unknown after a failure, so close it and
+		// reconnect on the next event.
+		s.conn.close()
+		s.conn = nil
+		return fmt.Errorf("failed to publish event: %v", err)
+	}
+
+	s.logger.Trace("published event", "subject", s.subject, "event_id", event.ID)
+	return nil
+}
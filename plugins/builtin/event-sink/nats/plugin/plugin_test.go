@@ -0,0 +1,150 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package plugin
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/nomad-autoscaler/sdk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEventSinkPlugin_SetConfig(t *testing.T) {
+	testCases := []struct {
+		name        string
+		config      map[string]string
+		expectedErr string
+	}{
+		{
+			name:   "valid",
+			config: map[string]string{"subject": "autoscaler.events"},
+		},
+		{
+			name:        "missing subject",
+			config:      map[string]string{},
+			expectedErr: "missing required field `subject`",
+		},
+		{
+			name:        "subject with whitespace",
+			config:      map[string]string{"subject": "events 10\r\nPUB other"},
+			expectedErr: "invalid `subject`",
+		},
+		{
+			name:        "subject with wildcard",
+			config:      map[string]string{"subject": "events.*"},
+			expectedErr: "invalid `subject`",
+		},
+		{
+			name:        "invalid scheme",
+			config:      map[string]string{"subject": "events", "address": "http://localhost:4222"},
+			expectedErr: "invalid `address` scheme",
+		},
+		{
+			name:        "invalid timeout",
+			config:      map[string]string{"subject": "events", "timeout": "soon"},
+			expectedErr: "failed to parse `timeout`",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			p := NewNATSPlugin(hclog.NewNullLogger())
+			err := p.SetConfig(tc.config)
+			if tc.expectedErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.expectedErr)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestEventSinkPlugin_Send(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close()
+
+	msgCh := make(chan string, 1)
+	go fakeNATSServer(ln, "secret", msgCh)
+
+	p := NewNATSPlugin(hclog.NewNullLogger())
+	require.NoError(t, p.SetConfig(map[string]string{
+		"address": "nats://" + ln.Addr().String(),
+		"subject": "autoscaler.events",
+		"token":   "secret",
+	}))
+
+	event := &sdk.ScalingEvent{
+		ID:       "event-1",
+		PolicyID: "policy-1",
+		Action:   sdk.ScalingAction{Count: 2, Direction: sdk.ScaleDirectionUp},
+	}
+	require.NoError(t, p.Send(event))
+
+	msg := <-msgCh
+	assert.Contains(t, msg, `"id":"event-1"`)
+	assert.Contains(t, msg, `"direction":"up"`)
+
+	// Invalid credentials are reported by the server.
+	require.NoError(t, p.SetConfig(map[string]string{
+		"address": "nats://" + ln.Addr().String(),
+		"subject": "autoscaler.events",
+		"token":   "wrong",
+	}))
+	err = p.Send(event)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Authorization Violation")
+}
+
+// fakeNATSServer implements enough of the NATS protocol to accept published
+// messages, which are sent to msgCh.
+func fakeNATSServer(ln net.Listener, token string, msgCh chan<- string) {
+	for {
+		c, err := ln.Accept()
+		if err != nil {
+			return
+		}
+
+		go func(c net.Conn) {
+			defer c.Close()
+			r := bufio.NewReader(c)
+
+			fmt.Fprint(c, "INFO {\"auth_required\":true}\r\n")
+			for {
+				line, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				line = strings.TrimRight(line, "\r\n")
+
+				switch {
+				case strings.HasPrefix(line, "CONNECT "):
+					if !strings.Contains(line, fmt.Sprintf(`"auth_token":%q`, token)) {
+						fmt.Fprint(c, "-ERR 'Authorization Violation'\r\n")
+						return
+					}
+				case line == "PING":
+					fmt.Fprint(c, "PONG\r\n")
+				case strings.HasPrefix(line, "PUB "):
+					parts := strings.Fields(line)
+					size, _ := strconv.Atoi(parts[len(parts)-1])
+					payload := make([]byte, size+2)
+					if _, err := io.ReadFull(r, payload); err != nil {
+						return
+					}
+					msgCh <- string(payload[:size])
+				}
+			}
+		}(c)
+	}
+}
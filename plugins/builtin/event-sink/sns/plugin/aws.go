@@ -0,0 +1,142 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package plugin
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+)
+
+const (
+	// snsAPIVersion is the version of the SNS query API used to publish
+	// messages.
+	snsAPIVersion = "2010-03-31"
+
+	// snsServiceName is the name of the service used to sign requests.
+	snsServiceName = "sns"
+)
+
+// errorResponse is the body returned by the SNS query API on errors.
+type errorResponse struct {
+	Error struct {
+		Code    string `xml:"Code"`
+		Message string `xml:"Message"`
+	} `xml:"Error"`
+}
+
+// setupAWSConfig takes the passed config mapping and loads the region and
+// credentials used to sign requests to the SNS API.
+func (s *EventSinkPlugin) setupAWSConfig(config map[string]string) error {
+
+	// Load our default AWS config. This handles pulling configuration from
+	// default profiles and environment variables.
+	cfg, err := awsconfig.LoadDefaultConfig(context.TODO())
+	if err != nil {
+		return fmt.Errorf("failed to load default AWS config: %v", err)
+	}
+
+	// The region can be set by the operator, otherwise it is read from the
+	// topic ARN, which has the format arn:aws:sns:<region>:<account>:<name>.
+	if region, ok := config[configKeyRegion]; ok && region != "" {
+		cfg.Region = region
+	} else if parts := strings.Split(s.topicARN, ":"); len(parts) == 6 {
+		cfg.Region = parts[3]
+	}
+
+	if cfg.Region == "" {
+		return fmt.Errorf("failed to determine AWS region, set `%s`", configKeyRegion)
+	}
+
+	// Attempt to pull access credentials for the AWS client from the user
+	// supplied configuration. In order to use these static credentials both
+	// the access key and secret key need to be present; the session token is
+	// optional.
+	keyID := config[configKeyAccessID]
+	secretKey := config[configKeySecretKey]
+	session := config[configKeySessionToken]
+
+	if keyID != "" && secretKey != "" {
+		s.logger.Trace("setting AWS access credentials from config map")
+		cfg.Credentials = credentials.NewStaticCredentialsProvider(keyID, secretKey, session)
+	} else {
+		s.logger.Trace("Using default AWS credential chain")
+	}
+
+	if cfg.Credentials == nil {
+		return fmt.Errorf("failed to find AWS credentials")
+	}
+
+	s.region = cfg.Region
+	s.credentials = cfg.Credentials
+	return nil
+}
+
+// publish sends the message to the SNS topic using a signed request to the
+// SNS query API. The event type is set as the type message attribute, so
+// subscriptions can use filter policies to receive specific events.
+func (s *EventSinkPlugin) publish(ctx context.Context, msg, eventType, groupID, dedupID string) error {
+
+	form := url.Values{}
+	form.Set("Action", "Publish")
+	form.Set("Version", snsAPIVersion)
+	form.Set("TopicArn", s.topicARN)
+	form.Set("Message", msg)
+	if eventType != "" {
+		form.Set("MessageAttributes.entry.1.Name", "type")
+		form.Set("MessageAttributes.entry.1.Value.DataType", "String")
+		form.Set("MessageAttributes.entry.1.Value.StringValue", eventType)
+	}
+	if groupID != "" {
+		form.Set("MessageGroupId", groupID)
+		form.Set("MessageDeduplicationId", dedupID)
+	}
+	body := form.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.endpoint, strings.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded; charset=utf-8")
+
+	creds, err := s.credentials.Retrieve(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to retrieve AWS credentials: %v", err)
+	}
+
+	hash := sha256.Sum256([]byte(body))
+	err = v4.NewSigner().SignHTTP(ctx, creds, req, hex.EncodeToString(hash[:]), snsServiceName, s.region, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to sign request: %v", err)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusOK {
+		return nil
+	}
+
+	respBody, _ := io.ReadAll(resp.Body)
+
+	var errResp errorResponse
+	if err := xml.Unmarshal(respBody, &errResp); err == nil && errResp.Error.Code != "" {
+		return fmt.Errorf("%s: %s", errResp.Error.Code, errResp.Error.Message)
+	}
+	return fmt.Errorf("unexpected response code %d", resp.StatusCode)
+}
@@ -0,0 +1,138 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package plugin
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	hclog "github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/nomad-autoscaler/plugins"
+	"github.com/hashicorp/nomad-autoscaler/plugins/base"
+	"github.com/hashicorp/nomad-autoscaler/plugins/eventsink"
+	"github.com/hashicorp/nomad-autoscaler/sdk"
+	"github.com/hashicorp/nomad-autoscaler/sdk/helper/pluginconfig"
+)
+
+const (
+	// pluginName is the unique name of the this plugin amongst EventSink
+	// plugins.
+	pluginName = "sns"
+
+	// configKeys represents the known configuration parameters required at
+	// varying points throughout the plugins lifecycle.
+	configKeyRegion       = "aws_region"
+	configKeyAccessID     = "aws_access_key_id"
+	configKeySecretKey    = "aws_secret_access_key"
+	configKeySessionToken = "aws_session_token"
+	configKeyTopicARN     = "topic_arn"
+	configKeyEndpoint     = "endpoint"
+	configKeyTimeout      = "timeout"
+
+	// configValues are the default values used when a configuration key is not
+	// supplied by the operator that are specific to the plugin.
+	configValueTimeoutDefault = "10s"
+)
+
+var (
+	PluginConfig = &plugins.InternalPluginConfig{
+		Factory: func(l hclog.Logger) interface{} { return NewSNSPlugin(l) },
+	}
+
+	pluginInfo = &base.PluginInfo{
+		Name:       pluginName,
+		PluginType: sdk.PluginTypeEventSink,
+	}
+)
+
+// Assert that EventSinkPlugin meets the eventsink.EventSink interface.
+var _ eventsink.EventSink = (*EventSinkPlugin)(nil)
+
+// EventSinkPlugin is the AWS SNS implementation of the eventsink.EventSink
+// interface. Events are published as JSON messages to the configured topic.
+type EventSinkPlugin struct {
+	config map[string]string
+	logger hclog.Logger
+
+	client      *http.Client
+	credentials aws.CredentialsProvider
+	region      string
+	endpoint    string
+	topicARN    string
+	timeout     time.Duration
+}
+
+// NewSNSPlugin returns the AWS SNS implementation of the eventsink.EventSink
+// interface.
+func NewSNSPlugin(log hclog.Logger) *EventSinkPlugin {
+	return &EventSinkPlugin{
+		logger: log,
+	}
+}
+
+// SetConfig satisfies the SetConfig function on the base.Base interface.
+func (s *EventSinkPlugin) SetConfig(config map[string]string) error {
+
+	topicARN := config[configKeyTopicARN]
+	if topicARN == "" {
+		return fmt.Errorf("missing required field `%s`", configKeyTopicARN)
+	}
+
+	timeout, err := time.ParseDuration(pluginconfig.GetValue(config, configKeyTimeout, configValueTimeoutDefault))
+	if err != nil {
+		return fmt.Errorf("failed to parse `%s`: %v", configKeyTimeout, err)
+	}
+
+	s.config = config
+	s.topicARN = topicARN
+	s.timeout = timeout
+	s.client = &http.Client{Timeout: timeout}
+
+	if err := s.setupAWSConfig(config); err != nil {
+		return err
+	}
+
+	s.endpoint = pluginconfig.GetValue(config, configKeyEndpoint, fmt.Sprintf("https://sns.%s.amazonaws.com/", s.region))
+	return nil
+}
+
+// PluginInfo satisfies the PluginInfo function on the base.Base interface.
+func (s *EventSinkPlugin) PluginInfo() (*base.PluginInfo, error) {
+	return pluginInfo, nil
+}
+
+// Send satisfies the Send function on the eventsink.EventSink interface.
+func (s *EventSinkPlugin) Send(event *sdk.ScalingEvent) error {
+
+	if s.client == nil {
+		return fmt.Errorf("plugin is not configured")
+	}
+
+	msg, err := eventsink.EncodeJSON(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode event: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+
+	// FIFO topics require a message group, use the policy ID so events of
+	// the same policy are delivered in order.
+	var groupID, dedupID string
+	if strings.HasSuffix(s.topicARN, ".fifo") {
+		groupID = event.PolicyID
+		dedupID = event.ID
+	}
+
+	if err := s.publish(ctx, string(msg), string(event.Type), groupID, dedupID); err != nil {
+		return fmt.Errorf("failed to publish event: %v", err)
+	}
+
+	s.logger.Trace("published event", "topic_arn", s.topicARN, "event_id", event.ID)
+	return nil
+}
@@ -0,0 +1,108 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package kafka
+
+import (
+	"encoding/binary"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/shoenig/test/must"
+	"github.com/twmb/franz-go/pkg/kmsg"
+)
+
+// fakeBroker is a single node Kafka cluster answering requests with the
+// handler, used to test the client without a real broker.
+type fakeBroker struct {
+	t        *testing.T
+	listener net.Listener
+	handler  func(kmsg.Request) kmsg.Response
+
+	lock     sync.Mutex
+	received []kmsg.Request
+}
+
+func newFakeBroker(t *testing.T, handler func(kmsg.Request) kmsg.Response) *fakeBroker {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	must.NoError(t, err)
+
+	b := &fakeBroker{t: t, listener: l, handler: handler}
+	t.Cleanup(func() { _ = l.Close() })
+
+	go func() {
+		for {
+			c, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go b.serve(c)
+		}
+	}()
+	return b
+}
+
+func (b *fakeBroker) addr() string { return b.listener.Addr().String() }
+
+// hostPort returns the host and port of the broker as advertised in the
+// cluster metadata.
+func (b *fakeBroker) hostPort() (string, int32) {
+	host, port, _ := net.SplitHostPort(b.addr())
+	p, _ := strconv.Atoi(port)
+	return host, int32(p)
+}
+
+func (b *fakeBroker) requests() []kmsg.Request {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return append([]kmsg.Request(nil), b.received...)
+}
+
+func (b *fakeBroker) serve(c net.Conn) {
+	defer c.Close()
+
+	for {
+		var size [4]byte
+		if _, err := io.ReadFull(c, size[:]); err != nil {
+			return
+		}
+		buf := make([]byte, binary.BigEndian.Uint32(size[:]))
+		if _, err := io.ReadFull(c, buf); err != nil {
+			return
+		}
+
+		// Request header v1: key, version, correlation ID and the
+		// nullable client ID.
+		key := int16(binary.BigEndian.Uint16(buf[0:]))
+		version := int16(binary.BigEndian.Uint16(buf[2:]))
+		corrID := buf[4:8]
+		body := buf[10:]
+		if n := int16(binary.BigEndian.Uint16(buf[8:])); n > 0 {
+			body = body[n:]
+		}
+
+		req := kmsg.RequestForKey(key)
+		req.SetVersion(version)
+		if err := req.ReadFrom(body); err != nil {
+			b.t.Errorf("failed to parse %s request: %v", kmsg.NameForKey(key), err)
+			return
+		}
+
+		b.lock.Lock()
+		b.received = append(b.received, req)
+		b.lock.Unlock()
+
+		resp := b.handler(req)
+		resp.SetVersion(version)
+
+		out := append(make([]byte, 4), corrID...)
+		out = resp.AppendTo(out)
+		binary.BigEndian.PutUint32(out, uint32(len(out)-4))
+		if _, err := c.Write(out); err != nil {
+			return
+		}
+	}
+}
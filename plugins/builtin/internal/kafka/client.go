@@ -0,0 +1,209 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+// Package kafka is a minimal Kafka client used by the builtin Kafka plugins.
+// It talks to the brokers directly using the Kafka protocol, encoding the
+// requests and responses with the franz-go kmsg package, and only implements
+// the requests needed by the plugins: reading the lag of consumer groups and
+// producing records.
+//
+// Requests use fixed protocol versions supported by Kafka 1.0 and later.
+// Connections can use TLS and SASL/PLAIN authentication; other SASL
+// mechanisms are not supported.
+package kafka
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/twmb/franz-go/pkg/kmsg"
+)
+
+const (
+	// ConfigKeys are the configuration parameters shared by the plugins
+	// using the client.
+	ConfigKeyBrokers       = "brokers"
+	ConfigKeyTLS           = "tls"
+	ConfigKeyTLSSkipVerify = "tls_skip_verify"
+	ConfigKeySASLUsername  = "sasl_username"
+	ConfigKeySASLPassword  = "sasl_password"
+	ConfigKeyTimeout       = "timeout"
+
+	// configValueBrokersDefault is the broker used when none are configured.
+	configValueBrokersDefault = "127.0.0.1:9092"
+
+	// clientID identifies the client in the broker logs and metrics.
+	clientID = "nomad-autoscaler"
+)
+
+// Options are the options used to connect to the Kafka brokers.
+type Options struct {
+
+	// Brokers are the addresses of the brokers used to discover the cluster.
+	Brokers []string
+
+	// TLS enables TLS, and TLSSkipVerify disables the verification of the
+	// broker certificates.
+	TLS           bool
+	TLSSkipVerify bool
+
+	// SASLUsername and SASLPassword enable SASL/PLAIN authentication.
+	SASLUsername string
+	SASLPassword string
+
+	// Timeout is the maximum duration of each request, including
+	// connecting to the broker.
+	Timeout time.Duration
+}
+
+// ParseConfig parses the client options from the plugin config, using
+// defaultTimeout if the timeout is not set.
+func ParseConfig(config map[string]string, defaultTimeout time.Duration) (*Options, error) {
+	opts := &Options{
+		TLS:           config[ConfigKeyTLS] == "true",
+		TLSSkipVerify: config[ConfigKeyTLSSkipVerify] == "true",
+		SASLUsername:  config[ConfigKeySASLUsername],
+		SASLPassword:  config[ConfigKeySASLPassword],
+		Timeout:       defaultTimeout,
+	}
+
+	brokers := config[ConfigKeyBrokers]
+	if brokers == "" {
+		brokers = configValueBrokersDefault
+	}
+	for _, b := range strings.Split(brokers, ",") {
+		b = strings.TrimSpace(b)
+		if _, _, err := net.SplitHostPort(b); err != nil {
+			return nil, fmt.Errorf("invalid `%s` entry %q: %v", ConfigKeyBrokers, b, err)
+		}
+		opts.Brokers = append(opts.Brokers, b)
+	}
+
+	if v := config[ConfigKeyTimeout]; v != "" {
+		timeout, err := time.ParseDuration(v)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse `%s`: %v", ConfigKeyTimeout, err)
+		}
+		opts.Timeout = timeout
+	}
+
+	return opts, nil
+}
+
+// Client sends requests to the brokers of a Kafka cluster. Connections are
+// opened when first needed and reused, and are closed after any failure so
+// the next request reconnects. Requests are sent one at a time.
+type Client struct {
+	opts *Options
+
+	lock  sync.Mutex
+	conns map[string]*conn
+}
+
+// NewClient returns a client which connects to the cluster using opts.
+func NewClient(opts *Options) *Client {
+	return &Client{
+		opts:  opts,
+		conns: make(map[string]*conn),
+	}
+}
+
+// Close closes all the connections of the client.
+func (c *Client) Close() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	for addr, conn := range c.conns {
+		conn.close()
+		delete(c.conns, addr)
+	}
+}
+
+// request sends the request to the broker at addr. The lock must be held.
+func (c *Client) request(addr string, req kmsg.Request) (kmsg.Response, error) {
+	conn, ok := c.conns[addr]
+	if !ok {
+		var err error
+		if conn, err = dial(addr, c.opts); err != nil {
+			return nil, fmt.Errorf("failed to connect to broker %s: %v", addr, err)
+		}
+		c.conns[addr] = conn
+	}
+
+	resp, err := conn.request(req)
+	if err != nil {
+		conn.close()
+		delete(c.conns, addr)
+		return nil, fmt.Errorf("failed to send %s request to broker %s: %v", kmsg.NameForKey(req.Key()), addr, err)
+	}
+	return resp, nil
+}
+
+// requestAny sends the request to the first bootstrap broker which answers.
+// The lock must be held.
+func (c *Client) requestAny(req kmsg.Request) (kmsg.Response, error) {
+	var errs []error
+	for _, addr := range c.opts.Brokers {
+		resp, err := c.request(addr, req)
+		if err == nil {
+			return resp, nil
+		}
+		errs = append(errs, err)
+	}
+	return nil, errors.Join(errs...)
+}
+
+// metadata returns the address of each broker of the cluster, keyed by node
+// ID, and the leader of each partition of the topics. The lock must be held.
+func (c *Client) metadata(topics []string) (map[int32]string, map[string]map[int32]int32, error) {
+	req := kmsg.NewPtrMetadataRequest()
+	req.SetVersion(4)
+	for _, t := range topics {
+		rt := kmsg.NewMetadataRequestTopic()
+		rt.Topic = kmsg.StringPtr(t)
+		req.Topics = append(req.Topics, rt)
+	}
+
+	raw, err := c.requestAny(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	resp := raw.(*kmsg.MetadataResponse)
+
+	brokers := make(map[int32]string, len(resp.Brokers))
+	for _, b := range resp.Brokers {
+		brokers[b.NodeID] = net.JoinHostPort(b.Host, strconv.Itoa(int(b.Port)))
+	}
+
+	leaders := make(map[string]map[int32]int32, len(resp.Topics))
+	for _, t := range resp.Topics {
+		if t.Topic == nil {
+			continue
+		}
+		if err := errorForCode(t.ErrorCode); err != nil {
+			return nil, nil, fmt.Errorf("failed to read metadata of topic %s: %v", *t.Topic, err)
+		}
+
+		partitions := make(map[int32]int32, len(t.Partitions))
+		for _, p := range t.Partitions {
+			partitions[p.Partition] = p.Leader
+		}
+		leaders[*t.Topic] = partitions
+	}
+
+	return brokers, leaders, nil
+}
+
+// brokerAddr returns the address of the broker with the node ID.
+func brokerAddr(brokers map[int32]string, nodeID int32) (string, error) {
+	addr, ok := brokers[nodeID]
+	if !ok {
+		return "", fmt.Errorf("broker %d not found in cluster metadata", nodeID)
+	}
+	return addr, nil
+}
@@ -0,0 +1,144 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package kafka
+
+import (
+	"crypto/tls"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"time"
+
+	"github.com/twmb/franz-go/pkg/kerr"
+	"github.com/twmb/franz-go/pkg/kmsg"
+)
+
+// maxResponseSize is the largest response accepted from a broker, which
+// protects the client from allocating huge buffers when talking to something
+// other than a Kafka broker.
+const maxResponseSize = 100 << 20
+
+// conn is a connection to a single Kafka broker.
+type conn struct {
+	netConn       net.Conn
+	timeout       time.Duration
+	formatter     *kmsg.RequestFormatter
+	correlationID int32
+}
+
+// dial connects to the broker at addr, performing the TLS handshake and SASL
+// authentication if configured.
+func dial(addr string, opts *Options) (*conn, error) {
+	dialer := &net.Dialer{Timeout: opts.Timeout}
+
+	var netConn net.Conn
+	var err error
+	if opts.TLS {
+		host, _, _ := net.SplitHostPort(addr)
+		netConn, err = tls.DialWithDialer(dialer, "tcp", addr, &tls.Config{
+			ServerName:         host,
+			InsecureSkipVerify: opts.TLSSkipVerify,
+			MinVersion:         tls.VersionTLS12,
+		})
+	} else {
+		netConn, err = dialer.Dial("tcp", addr)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	c := &conn{
+		netConn:   netConn,
+		timeout:   opts.Timeout,
+		formatter: kmsg.NewRequestFormatter(kmsg.FormatterClientID(clientID)),
+	}
+
+	if opts.SASLUsername != "" {
+		if err := c.authenticate(opts.SASLUsername, opts.SASLPassword); err != nil {
+			c.close()
+			return nil, fmt.Errorf("failed to authenticate: %v", err)
+		}
+	}
+	return c, nil
+}
+
+// authenticate performs SASL/PLAIN authentication.
+func (c *conn) authenticate(username, password string) error {
+	handshake := kmsg.NewPtrSASLHandshakeRequest()
+	handshake.SetVersion(1)
+	handshake.Mechanism = "PLAIN"
+
+	raw, err := c.request(handshake)
+	if err != nil {
+		return err
+	}
+	if err := errorForCode(raw.(*kmsg.SASLHandshakeResponse).ErrorCode); err != nil {
+		return err
+	}
+
+	auth := kmsg.NewPtrSASLAuthenticateRequest()
+	auth.SetVersion(0)
+	auth.SASLAuthBytes = []byte("\x00" + username + "\x00" + password)
+
+	raw, err = c.request(auth)
+	if err != nil {
+		return err
+	}
+	resp := raw.(*kmsg.SASLAuthenticateResponse)
+	if err := errorForCode(resp.ErrorCode); err != nil {
+		if resp.ErrorMessage != nil {
+			return fmt.Errorf("%v: %s", err, *resp.ErrorMessage)
+		}
+		return err
+	}
+	return nil
+}
+
+// request sends the request to the broker and reads its response. The
+// requests used by the client are never flexible, so the response header
+// only has the correlation ID.
+func (c *conn) request(req kmsg.Request) (kmsg.Response, error) {
+	_ = c.netConn.SetDeadline(time.Now().Add(c.timeout))
+
+	c.correlationID++
+	if _, err := c.netConn.Write(c.formatter.AppendRequest(nil, req, c.correlationID)); err != nil {
+		return nil, err
+	}
+
+	var size [4]byte
+	if _, err := io.ReadFull(c.netConn, size[:]); err != nil {
+		return nil, err
+	}
+	n := int32(binary.BigEndian.Uint32(size[:]))
+	if n < 4 || n > maxResponseSize {
+		return nil, fmt.Errorf("invalid response size %d", n)
+	}
+
+	buf := make([]byte, n)
+	if _, err := io.ReadFull(c.netConn, buf); err != nil {
+		return nil, err
+	}
+	if id := int32(binary.BigEndian.Uint32(buf)); id != c.correlationID {
+		return nil, fmt.Errorf("unexpected correlation ID %d, expected %d", id, c.correlationID)
+	}
+
+	resp := req.ResponseKind()
+	resp.SetVersion(req.GetVersion())
+	if err := resp.ReadFrom(buf[4:]); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %v", err)
+	}
+	return resp, nil
+}
+
+// close closes the connection to the broker.
+func (c *conn) close() {
+	_ = c.netConn.Close()
+}
+
+// errorForCode returns the Kafka error with the code, or nil if the code
+// doesn't represent an error.
+func errorForCode(code int16) error {
+	return kerr.ErrorForCode(code)
+}
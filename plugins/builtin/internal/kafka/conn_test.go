@@ -0,0 +1,62 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package kafka
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shoenig/test/must"
+	"github.com/twmb/franz-go/pkg/kerr"
+	"github.com/twmb/franz-go/pkg/kmsg"
+)
+
+func Test_dial_sasl(t *testing.T) {
+	testCases := []struct {
+		name      string
+		password  string
+		expectErr string
+	}{
+		{
+			name:     "valid credentials",
+			password: "secret",
+		},
+		{
+			name:      "invalid credentials",
+			password:  "wrong",
+			expectErr: "SASL_AUTHENTICATION_FAILED",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			b := newFakeBroker(t, func(req kmsg.Request) kmsg.Response {
+				switch r := req.(type) {
+				case *kmsg.SASLHandshakeRequest:
+					must.Eq(t, "PLAIN", r.Mechanism)
+				case *kmsg.SASLAuthenticateRequest:
+					if string(r.SASLAuthBytes) != "\x00user\x00secret" {
+						resp := kmsg.NewPtrSASLAuthenticateResponse()
+						resp.ErrorCode = kerr.SaslAuthenticationFailed.Code
+						return resp
+					}
+				}
+				return req.ResponseKind()
+			})
+
+			c, err := dial(b.addr(), &Options{
+				SASLUsername: "user",
+				SASLPassword: tc.password,
+				Timeout:      time.Second,
+			})
+			if tc.expectErr != "" {
+				must.ErrorContains(t, err, tc.expectErr)
+				return
+			}
+			must.NoError(t, err)
+			c.close()
+			must.Len(t, 2, b.requests())
+		})
+	}
+}
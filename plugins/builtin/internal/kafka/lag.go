@@ -0,0 +1,216 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package kafka
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+
+	"github.com/twmb/franz-go/pkg/kmsg"
+)
+
+// coordinatorTypeGroup is the FindCoordinator key type of consumer groups.
+const coordinatorTypeGroup = 0
+
+// PartitionLag is the lag of a consumer group on a single partition.
+type PartitionLag struct {
+	Topic     string
+	Partition int32
+
+	// Committed is the offset committed by the consumer group and End is
+	// the offset of the next record written to the partition.
+	Committed int64
+	End       int64
+
+	// Lag is the number of records written to the partition which have not
+	// been committed by the consumer group.
+	Lag int64
+}
+
+// GroupLag returns the lag of the consumer group on each partition it has
+// committed offsets for, sorted by topic and partition. The committed offsets
+// are read from the group coordinator and the end offsets from the leader of
+// each partition, the same way the Kafka admin tools compute lag.
+func (c *Client) GroupLag(group string) ([]PartitionLag, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	coordinator, err := c.findCoordinator(group)
+	if err != nil {
+		return nil, err
+	}
+
+	committed, err := c.committedOffsets(coordinator, group)
+	if err != nil {
+		return nil, err
+	}
+	if len(committed) == 0 {
+		return nil, nil
+	}
+
+	topics := make([]string, 0, len(committed))
+	for t := range committed {
+		topics = append(topics, t)
+	}
+
+	brokers, leaders, err := c.metadata(topics)
+	if err != nil {
+		return nil, err
+	}
+
+	end, err := c.endOffsets(brokers, leaders, committed)
+	if err != nil {
+		return nil, err
+	}
+
+	var lags []PartitionLag
+	for topic, partitions := range committed {
+		for partition, offset := range partitions {
+			l := PartitionLag{
+				Topic:     topic,
+				Partition: partition,
+				Committed: offset,
+				End:       end[topic][partition],
+			}
+
+			// The committed offset can be ahead of the end offset read
+			// from a lagging leader.
+			if l.End > l.Committed {
+				l.Lag = l.End - l.Committed
+			}
+			lags = append(lags, l)
+		}
+	}
+
+	sort.Slice(lags, func(i, j int) bool {
+		if lags[i].Topic != lags[j].Topic {
+			return lags[i].Topic < lags[j].Topic
+		}
+		return lags[i].Partition < lags[j].Partition
+	})
+	return lags, nil
+}
+
+// findCoordinator returns the address of the coordinator of the consumer
+// group. The lock must be held.
+func (c *Client) findCoordinator(group string) (string, error) {
+	req := kmsg.NewPtrFindCoordinatorRequest()
+	req.SetVersion(1)
+	req.CoordinatorKey = group
+	req.CoordinatorType = coordinatorTypeGroup
+
+	raw, err := c.requestAny(req)
+	if err != nil {
+		return "", err
+	}
+	resp := raw.(*kmsg.FindCoordinatorResponse)
+
+	if err := errorForCode(resp.ErrorCode); err != nil {
+		return "", fmt.Errorf("failed to find coordinator of consumer group %s: %v", group, err)
+	}
+	return net.JoinHostPort(resp.Host, strconv.Itoa(int(resp.Port))), nil
+}
+
+// committedOffsets returns the offsets committed by the consumer group on
+// each partition. Partitions without a committed offset are not included.
+// The lock must be held.
+func (c *Client) committedOffsets(coordinator, group string) (map[string]map[int32]int64, error) {
+	req := kmsg.NewPtrOffsetFetchRequest()
+	req.SetVersion(3)
+	req.Group = group
+
+	// Leaving the topics unset returns the offsets of all the topics.
+	raw, err := c.request(coordinator, req)
+	if err != nil {
+		return nil, err
+	}
+	resp := raw.(*kmsg.OffsetFetchResponse)
+
+	if err := errorForCode(resp.ErrorCode); err != nil {
+		return nil, fmt.Errorf("failed to fetch offsets of consumer group %s: %v", group, err)
+	}
+
+	out := make(map[string]map[int32]int64)
+	for _, t := range resp.Topics {
+		for _, p := range t.Partitions {
+			if err := errorForCode(p.ErrorCode); err != nil {
+				return nil, fmt.Errorf("failed to fetch offset of consumer group %s on %s/%d: %v",
+					group, t.Topic, p.Partition, err)
+			}
+			if p.Offset < 0 {
+				continue
+			}
+			if out[t.Topic] == nil {
+				out[t.Topic] = make(map[int32]int64)
+			}
+			out[t.Topic][p.Partition] = p.Offset
+		}
+	}
+	return out, nil
+}
+
+// endOffsets returns the end offset of each partition, reading them from the
+// partition leaders. The lock must be held.
+func (c *Client) endOffsets(brokers map[int32]string, leaders map[string]map[int32]int32,
+	partitions map[string]map[int32]int64) (map[string]map[int32]int64, error) {
+
+	// Group the partitions by leader so each broker receives one request.
+	reqs := make(map[int32]*kmsg.ListOffsetsRequest)
+	for topic, ps := range partitions {
+		for partition := range ps {
+			leader, ok := leaders[topic][partition]
+			if !ok || leader < 0 {
+				return nil, fmt.Errorf("partition %s/%d has no leader", topic, partition)
+			}
+
+			req, ok := reqs[leader]
+			if !ok {
+				req = kmsg.NewPtrListOffsetsRequest()
+				req.SetVersion(1)
+				reqs[leader] = req
+			}
+
+			rp := kmsg.NewListOffsetsRequestTopicPartition()
+			rp.Partition = partition
+			rp.Timestamp = -1 // The latest offset.
+
+			i := len(req.Topics) - 1
+			if i < 0 || req.Topics[i].Topic != topic {
+				rt := kmsg.NewListOffsetsRequestTopic()
+				rt.Topic = topic
+				req.Topics = append(req.Topics, rt)
+				i++
+			}
+			req.Topics[i].Partitions = append(req.Topics[i].Partitions, rp)
+		}
+	}
+
+	out := make(map[string]map[int32]int64)
+	for leader, req := range reqs {
+		addr, err := brokerAddr(brokers, leader)
+		if err != nil {
+			return nil, err
+		}
+
+		raw, err := c.request(addr, req)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, t := range raw.(*kmsg.ListOffsetsResponse).Topics {
+			for _, p := range t.Partitions {
+				if err := errorForCode(p.ErrorCode); err != nil {
+					return nil, fmt.Errorf("failed to list end offset of %s/%d: %v", t.Topic, p.Partition, err)
+				}
+				if out[t.Topic] == nil {
+					out[t.Topic] = make(map[int32]int64)
+				}
+				out[t.Topic][p.Partition] = p.Offset
+			}
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,204 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package kafka
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shoenig/test/must"
+	"github.com/twmb/franz-go/pkg/kerr"
+	"github.com/twmb/franz-go/pkg/kmsg"
+)
+
+// lagBroker returns a handler for a cluster with the topics "orders", with
+// partitions 0 and 1, and "payments", with partition 0. The group "web" has
+// committed offsets on all of them, except payments/0 which has no committed
+// offset.
+func lagBroker(b **fakeBroker, groupErr int16) func(kmsg.Request) kmsg.Response {
+	committed := map[string]map[int32]int64{
+		"orders":   {0: 90, 1: 100},
+		"payments": {0: -1},
+	}
+	end := map[string]map[int32]int64{
+		"orders":   {0: 100, 1: 95},
+		"payments": {0: 50},
+	}
+
+	return func(req kmsg.Request) kmsg.Response {
+		host, port := (*b).hostPort()
+
+		switch r := req.(type) {
+		case *kmsg.FindCoordinatorRequest:
+			resp := kmsg.NewPtrFindCoordinatorResponse()
+			resp.Host, resp.Port = host, port
+			return resp
+
+		case *kmsg.OffsetFetchRequest:
+			resp := kmsg.NewPtrOffsetFetchResponse()
+			resp.ErrorCode = groupErr
+			for topic, partitions := range committed {
+				rt := kmsg.NewOffsetFetchResponseTopic()
+				rt.Topic = topic
+				for partition, offset := range partitions {
+					rp := kmsg.NewOffsetFetchResponseTopicPartition()
+					rp.Partition, rp.Offset = partition, offset
+					rt.Partitions = append(rt.Partitions, rp)
+				}
+				resp.Topics = append(resp.Topics, rt)
+			}
+			return resp
+
+		case *kmsg.MetadataRequest:
+			resp := kmsg.NewPtrMetadataResponse()
+			broker := kmsg.NewMetadataResponseBroker()
+			broker.NodeID, broker.Host, broker.Port = 1, host, port
+			resp.Brokers = append(resp.Brokers, broker)
+			for _, t := range r.Topics {
+				rt := kmsg.NewMetadataResponseTopic()
+				rt.Topic = t.Topic
+				for partition := range end[*t.Topic] {
+					rp := kmsg.NewMetadataResponseTopicPartition()
+					rp.Partition, rp.Leader = partition, 1
+					rt.Partitions = append(rt.Partitions, rp)
+				}
+				resp.Topics = append(resp.Topics, rt)
+			}
+			return resp
+
+		case *kmsg.ListOffsetsRequest:
+			resp := kmsg.NewPtrListOffsetsResponse()
+			for _, t := range r.Topics {
+				rt := kmsg.NewListOffsetsResponseTopic()
+				rt.Topic = t.Topic
+				for _, p := range t.Partitions {
+					rp := kmsg.NewListOffsetsResponseTopicPartition()
+					rp.Partition, rp.Offset = p.Partition, end[t.Topic][p.Partition]
+					rt.Partitions = append(rt.Partitions, rp)
+				}
+				resp.Topics = append(resp.Topics, rt)
+			}
+			return resp
+		}
+		return req.ResponseKind()
+	}
+}
+
+func TestClient_GroupLag(t *testing.T) {
+	var b *fakeBroker
+	b = newFakeBroker(t, lagBroker(&b, 0))
+
+	c := NewClient(&Options{Brokers: []string{b.addr()}, Timeout: time.Second})
+	defer c.Close()
+
+	lags, err := c.GroupLag("web")
+	must.NoError(t, err)
+	must.Eq(t, []PartitionLag{
+		{Topic: "orders", Partition: 0, Committed: 90, End: 100, Lag: 10},
+		{Topic: "orders", Partition: 1, Committed: 100, End: 95, Lag: 0},
+	}, lags)
+
+	// Only the topics with committed offsets are looked up, and the end
+	// offsets are the latest ones.
+	var listOffsets int
+	for _, req := range b.requests() {
+		switch r := req.(type) {
+		case *kmsg.MetadataRequest:
+			must.Len(t, 1, r.Topics)
+			must.Eq(t, "orders", *r.Topics[0].Topic)
+		case *kmsg.ListOffsetsRequest:
+			listOffsets++
+			for _, p := range r.Topics[0].Partitions {
+				must.Eq(t, -1, p.Timestamp)
+			}
+		case *kmsg.OffsetFetchRequest:
+			must.Eq(t, "web", r.Group)
+		}
+	}
+	must.Eq(t, 1, listOffsets)
+
+	// The connection is reused by the next calls.
+	_, err = c.GroupLag("web")
+	must.NoError(t, err)
+	must.MapLen(t, 1, c.conns)
+}
+
+func TestClient_GroupLag_error(t *testing.T) {
+	var b *fakeBroker
+	b = newFakeBroker(t, lagBroker(&b, kerr.GroupAuthorizationFailed.Code))
+
+	c := NewClient(&Options{Brokers: []string{b.addr()}, Timeout: time.Second})
+	defer c.Close()
+
+	_, err := c.GroupLag("web")
+	must.ErrorContains(t, err, "failed to fetch offsets of consumer group web")
+	must.ErrorContains(t, err, "GROUP_AUTHORIZATION_FAILED")
+}
+
+func TestClient_unreachable(t *testing.T) {
+	c := NewClient(&Options{Brokers: []string{"127.0.0.1:1"}, Timeout: time.Second})
+	defer c.Close()
+
+	_, err := c.GroupLag("web")
+	must.ErrorContains(t, err, "failed to connect to broker 127.0.0.1:1")
+}
+
+func TestParseConfig(t *testing.T) {
+	testCases := []struct {
+		name      string
+		config    map[string]string
+		expected  *Options
+		expectErr string
+	}{
+		{
+			name:   "defaults",
+			config: map[string]string{},
+			expected: &Options{
+				Brokers: []string{"127.0.0.1:9092"},
+				Timeout: 5 * time.Second,
+			},
+		},
+		{
+			name: "all set",
+			config: map[string]string{
+				"brokers":         "kafka-1:9093, kafka-2:9093",
+				"tls":             "true",
+				"tls_skip_verify": "true",
+				"sasl_username":   "user",
+				"sasl_password":   "secret",
+				"timeout":         "1s",
+			},
+			expected: &Options{
+				Brokers:       []string{"kafka-1:9093", "kafka-2:9093"},
+				TLS:           true,
+				TLSSkipVerify: true,
+				SASLUsername:  "user",
+				SASLPassword:  "secret",
+				Timeout:       time.Second,
+			},
+		},
+		{
+			name:      "broker without port",
+			config:    map[string]string{"brokers": "kafka-1"},
+			expectErr: "invalid `brokers` entry",
+		},
+		{
+			name:      "invalid timeout",
+			config:    map[string]string{"timeout": "soon"},
+			expectErr: "failed to parse `timeout`",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			opts, err := ParseConfig(tc.config, 5*time.Second)
+			if tc.expectErr != "" {
+				must.ErrorContains(t, err, tc.expectErr)
+				return
+			}
+			must.NoError(t, err)
+			must.Eq(t, tc.expected, opts)
+		})
+	}
+}
@@ -0,0 +1,146 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package kafka
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"time"
+
+	"github.com/twmb/franz-go/pkg/kmsg"
+)
+
+// crc32c is the CRC table used to checksum record batches.
+var crc32c = crc32.MakeTable(crc32.Castagnoli)
+
+// Produce writes a record with the key and value to the topic, waiting for
+// all the in-sync replicas to acknowledge it. The partition is chosen from
+// the key the same way the default Kafka partitioner does, so records with
+// the same key are kept in order within a partition.
+func (c *Client) Produce(topic string, key, value []byte) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	brokers, leaders, err := c.metadata([]string{topic})
+	if err != nil {
+		return err
+	}
+
+	partitions, ok := leaders[topic]
+	if !ok || len(partitions) == 0 {
+		return fmt.Errorf("topic %s has no partitions", topic)
+	}
+	partition := partitionForKey(key, int32(len(partitions)))
+
+	leader, ok := partitions[partition]
+	if !ok || leader < 0 {
+		return fmt.Errorf("partition %s/%d has no leader", topic, partition)
+	}
+	addr, err := brokerAddr(brokers, leader)
+	if err != nil {
+		return err
+	}
+
+	req := kmsg.NewPtrProduceRequest()
+	req.SetVersion(3)
+	req.Acks = -1
+	req.TimeoutMillis = int32(c.opts.Timeout.Milliseconds())
+
+	rp := kmsg.NewProduceRequestTopicPartition()
+	rp.Partition = partition
+	rp.Records = recordBatch(key, value, time.Now())
+
+	rt := kmsg.NewProduceRequestTopic()
+	rt.Topic = topic
+	rt.Partitions = append(rt.Partitions, rp)
+	req.Topics = append(req.Topics, rt)
+
+	raw, err := c.request(addr, req)
+	if err != nil {
+		return err
+	}
+
+	for _, t := range raw.(*kmsg.ProduceResponse).Topics {
+		for _, p := range t.Partitions {
+			if err := errorForCode(p.ErrorCode); err != nil {
+				return fmt.Errorf("failed to produce to %s/%d: %v", t.Topic, p.Partition, err)
+			}
+		}
+	}
+	return nil
+}
+
+// recordBatch encodes a record batch holding a single uncompressed record.
+func recordBatch(key, value []byte, now time.Time) []byte {
+	record := kmsg.Record{Key: key, Value: value}
+
+	// The length prefix of the record doesn't count itself. It is zero, and
+	// so a single byte long, when first encoded.
+	record.Length = int32(len(record.AppendTo(nil)) - 1)
+
+	batch := kmsg.RecordBatch{
+		PartitionLeaderEpoch: -1,
+		Magic:                2,
+		FirstTimestamp:       now.UnixMilli(),
+		MaxTimestamp:         now.UnixMilli(),
+		ProducerID:           -1,
+		ProducerEpoch:        -1,
+		FirstSequence:        -1,
+		NumRecords:           1,
+		Records:              record.AppendTo(nil),
+	}
+	out := batch.AppendTo(nil)
+
+	// The batch length excludes the first offset and the length itself, and
+	// the CRC covers everything after it.
+	binary.BigEndian.PutUint32(out[8:], uint32(len(out)-12))
+	binary.BigEndian.PutUint32(out[17:], crc32.Checksum(out[21:], crc32c))
+	return out
+}
+
+// partitionForKey returns the partition of the key, using the murmur2 hash
+// of the default Kafka partitioner.
+func partitionForKey(key []byte, partitions int32) int32 {
+	return (murmur2(key) & 0x7fffffff) % partitions
+}
+
+// murmur2 is the variant of the murmur2 hash used by the Kafka partitioner.
+func murmur2(data []byte) int32 {
+	const (
+		seed uint32 = 0x9747b28c
+		m    uint32 = 0x5bd1e995
+		r           = 24
+	)
+
+	length := len(data)
+	h := seed ^ uint32(length)
+
+	for i := 0; i+4 <= length; i += 4 {
+		k := binary.LittleEndian.Uint32(data[i:])
+		k *= m
+		k ^= k >> r
+		k *= m
+		h *= m
+		h ^= k
+	}
+
+	tail := data[length&^3:]
+	switch len(tail) {
+	case 3:
+		h ^= uint32(tail[2]) << 16
+		fallthrough
+	case 2:
+		h ^= uint32(tail[1]) << 8
+		fallthrough
+	case 1:
+		h ^= uint32(tail[0])
+		h *= m
+	}
+
+	h ^= h >> 13
+	h *= m
+	h ^= h >> 15
+	return int32(h)
+}
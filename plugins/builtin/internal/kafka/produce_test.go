@@ -0,0 +1,129 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package kafka
+
+import (
+	"hash/crc32"
+	"testing"
+	"time"
+
+	"github.com/shoenig/test/must"
+	"github.com/twmb/franz-go/pkg/kerr"
+	"github.com/twmb/franz-go/pkg/kmsg"
+)
+
+func Test_murmur2(t *testing.T) {
+	// Values computed by org.apache.kafka.common.utils.Utils.murmur2.
+	testCases := []struct {
+		input    string
+		expected int32
+	}{
+		{input: "21", expected: -973932308},
+		{input: "foobar", expected: -790332482},
+		{input: "abc", expected: 479470107},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			must.Eq(t, tc.expected, murmur2([]byte(tc.input)))
+		})
+	}
+}
+
+func Test_recordBatch(t *testing.T) {
+	now := time.UnixMilli(1600000000000)
+	out := recordBatch([]byte("policy-1"), []byte(`{"id":"event-1"}`), now)
+
+	var batch kmsg.RecordBatch
+	must.NoError(t, batch.ReadFrom(out))
+	must.Eq(t, len(out)-12, int(batch.Length))
+	must.Eq(t, 2, batch.Magic)
+	must.Eq(t, 1, batch.NumRecords)
+	must.Eq(t, now.UnixMilli(), batch.FirstTimestamp)
+	must.Eq(t, int32(crc32.Checksum(out[21:], crc32c)), batch.CRC)
+
+	var record kmsg.Record
+	must.NoError(t, record.ReadFrom(batch.Records))
+	must.Eq(t, "policy-1", string(record.Key))
+	must.Eq(t, `{"id":"event-1"}`, string(record.Value))
+}
+
+func TestClient_Produce(t *testing.T) {
+	testCases := []struct {
+		name      string
+		errorCode int16
+		expectErr string
+	}{
+		{
+			name: "success",
+		},
+		{
+			name:      "broker error",
+			errorCode: kerr.NotEnoughReplicas.Code,
+			expectErr: "failed to produce to events/2: NOT_ENOUGH_REPLICAS",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var b *fakeBroker
+			b = newFakeBroker(t, func(req kmsg.Request) kmsg.Response {
+				host, port := b.hostPort()
+
+				switch r := req.(type) {
+				case *kmsg.MetadataRequest:
+					resp := kmsg.NewPtrMetadataResponse()
+					broker := kmsg.NewMetadataResponseBroker()
+					broker.NodeID, broker.Host, broker.Port = 1, host, port
+					resp.Brokers = append(resp.Brokers, broker)
+
+					rt := kmsg.NewMetadataResponseTopic()
+					rt.Topic = r.Topics[0].Topic
+					for i := int32(0); i < 4; i++ {
+						rp := kmsg.NewMetadataResponseTopicPartition()
+						rp.Partition, rp.Leader = i, 1
+						rt.Partitions = append(rt.Partitions, rp)
+					}
+					resp.Topics = append(resp.Topics, rt)
+					return resp
+
+				case *kmsg.ProduceRequest:
+					resp := kmsg.NewPtrProduceResponse()
+					for _, t := range r.Topics {
+						rt := kmsg.NewProduceResponseTopic()
+						rt.Topic = t.Topic
+						for _, p := range t.Partitions {
+							rp := kmsg.NewProduceResponseTopicPartition()
+							rp.Partition, rp.ErrorCode = p.Partition, tc.errorCode
+							rt.Partitions = append(rt.Partitions, rp)
+						}
+						resp.Topics = append(resp.Topics, rt)
+					}
+					return resp
+				}
+				return req.ResponseKind()
+			})
+
+			c := NewClient(&Options{Brokers: []string{b.addr()}, Timeout: time.Second})
+			defer c.Close()
+
+			err := c.Produce("events", []byte("foobar"), []byte("value"))
+			if tc.expectErr != "" {
+				must.ErrorContains(t, err, tc.expectErr)
+			} else {
+				must.NoError(t, err)
+			}
+
+			reqs := b.requests()
+			must.Len(t, 2, reqs)
+			produce := reqs[1].(*kmsg.ProduceRequest)
+			must.Eq(t, -1, produce.Acks)
+			must.Eq(t, 1000, produce.TimeoutMillis)
+
+			// (-790332482 & 0x7fffffff) % 4 == 2
+			must.Eq(t, "events", produce.Topics[0].Topic)
+			must.Eq(t, 2, produce.Topics[0].Partitions[0].Partition)
+		})
+	}
+}
@@ -6,6 +6,8 @@ package plugin
 import (
 	"fmt"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/hashicorp/go-hclog"
 	"github.com/hashicorp/nomad-autoscaler/plugins"
@@ -20,7 +22,14 @@ const (
 	pluginName = "fixed-value"
 
 	// These are the keys read from the RunRequest.Config map.
-	runConfigKeyValue = "value"
+	runConfigKeyValue        = "value"
+	runConfigKeyValueWeekday = "value_weekday"
+	runConfigKeyValueWeekend = "value_weekend"
+	runConfigKeyTimezone     = "timezone"
+
+	// runConfigKeyValueDayPrefix is the prefix of the keys used to set the
+	// value for a specific day, such as value_monday.
+	runConfigKeyValueDayPrefix = "value_"
 )
 
 var (
@@ -47,6 +56,10 @@ var _ strategy.Strategy = (*StrategyPlugin)(nil)
 type StrategyPlugin struct {
 	config map[string]string
 	logger hclog.Logger
+
+	// now returns the current time and is used to select the value of the
+	// current day. It is overridden in tests.
+	now func() time.Time
 }
 
 // NewFixedValuePlugin returns the FixedValue implementation of the
@@ -54,6 +67,7 @@ type StrategyPlugin struct {
 func NewFixedValuePlugin(log hclog.Logger) strategy.Strategy {
 	return &StrategyPlugin{
 		logger: log,
+		now:    time.Now,
 	}
 }
 
@@ -71,15 +85,15 @@ func (s *StrategyPlugin) PluginInfo() (*base.PluginInfo, error) {
 // Run satisfies the Run function on the strategy.Strategy interface.
 func (s *StrategyPlugin) Run(eval *sdk.ScalingCheckEvaluation, count int64) (*sdk.ScalingCheckEvaluation, error) {
 
-	// Read and parse fixed value from req.Config.
-	t := eval.Check.Strategy.Config[runConfigKeyValue]
-	if t == "" {
-		return nil, fmt.Errorf("missing required field `%s`", runConfigKeyValue)
+	now := time.Now
+	if s.now != nil {
+		now = s.now
 	}
 
-	value, err := strconv.ParseInt(t, 10, 64)
+	// Read and parse fixed value for the current day from req.Config.
+	value, key, err := scheduledValue(eval.Check.Strategy.Config, now())
 	if err != nil {
-		return nil, fmt.Errorf("invalid value for `%s`: %v (%T)", runConfigKeyValue, t, t)
+		return nil, err
 	}
 
 	// Identify the direction of scaling, if any.
@@ -93,7 +107,7 @@ func (s *StrategyPlugin) Run(eval *sdk.ScalingCheckEvaluation, count int64) (*sd
 	// all the calculations made.
 	s.logger.Trace("calculated scaling strategy results",
 		"check_name", eval.Check.Name, "current_count", count, "new_count", value,
-		"direction", eval.Action.Direction)
+		"value_key", key, "direction", eval.Action.Direction)
 
 	eval.Action.Count = value
 	eval.Action.Reason = fmt.Sprintf("scaling %s because fixed value is %d", eval.Action.Direction, value)
@@ -101,6 +115,60 @@ func (s *StrategyPlugin) Run(eval *sdk.ScalingCheckEvaluation, count int64) (*sd
 	return eval, nil
 }
 
+// scheduledValue returns the fixed value that applies at the given time, along
+// with the config key it was read from. The most specific key takes
+// precedence, so a per-day value such as value_saturday overrides
+// value_weekend, which overrides the default value. All the configured values
+// are validated, not only the one that applies, so errors are reported as
+// soon as the policy is evaluated.
+func scheduledValue(config map[string]string, now time.Time) (int64, string, error) {
+	if tz := config[runConfigKeyTimezone]; tz != "" {
+		loc, err := time.LoadLocation(tz)
+		if err != nil {
+			return 0, "", fmt.Errorf("invalid value for `%s`: %v", runConfigKeyTimezone, err)
+		}
+		now = now.In(loc)
+	}
+
+	dayKey := runConfigKeyValueDayPrefix + strings.ToLower(now.Weekday().String())
+
+	periodKey := runConfigKeyValueWeekday
+	if now.Weekday() == time.Saturday || now.Weekday() == time.Sunday {
+		periodKey = runConfigKeyValueWeekend
+	}
+
+	keys := []string{runConfigKeyValue, runConfigKeyValueWeekday, runConfigKeyValueWeekend}
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		keys = append(keys, runConfigKeyValueDayPrefix+strings.ToLower(d.String()))
+	}
+
+	values := make(map[string]int64)
+	for _, k := range keys {
+		v := config[k]
+		if v == "" {
+			continue
+		}
+
+		parsed, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return 0, "", fmt.Errorf("invalid value for `%s`: %v (%T)", k, v, v)
+		}
+		values[k] = parsed
+	}
+
+	for _, k := range []string{dayKey, periodKey, runConfigKeyValue} {
+		if v, ok := values[k]; ok {
+			return v, k, nil
+		}
+	}
+
+	if len(values) > 0 {
+		return 0, "", fmt.Errorf("missing required field `%s`, no value set for %s",
+			runConfigKeyValue, now.Weekday())
+	}
+	return 0, "", fmt.Errorf("missing required field `%s`", runConfigKeyValue)
+}
+
 // calculateDirection is used to calculate the direction of scaling that should
 // occur, if any at all.
 func (s *StrategyPlugin) calculateDirection(count, fixed int64) sdk.ScaleDirection {
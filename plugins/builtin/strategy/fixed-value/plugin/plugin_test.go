@@ -6,6 +6,7 @@ package plugin
 import (
 	"errors"
 	"testing"
+	"time"
 
 	hclog "github.com/hashicorp/go-hclog"
 	"github.com/hashicorp/nomad-autoscaler/plugins/base"
@@ -167,3 +168,90 @@ func TestStrategyPlugin_calculateDirection(t *testing.T) {
 		assert.Equal(t, tc.expectedOutput, s.calculateDirection(tc.inputCount, tc.fixedCount))
 	}
 }
+
+func Test_scheduledValue(t *testing.T) {
+	// 2021-01-02 is a Saturday and 2021-01-04 is a Monday.
+	saturday := time.Date(2021, 1, 2, 12, 0, 0, 0, time.UTC)
+	monday := time.Date(2021, 1, 4, 12, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name          string
+		config        map[string]string
+		now           time.Time
+		expectedValue int64
+		expectedKey   string
+		expectedError string
+	}{
+		{
+			name:          "default value",
+			config:        map[string]string{"value": "3"},
+			now:           saturday,
+			expectedValue: 3,
+			expectedKey:   "value",
+		},
+		{
+			name:          "weekend value",
+			config:        map[string]string{"value": "3", "value_weekend": "1"},
+			now:           saturday,
+			expectedValue: 1,
+			expectedKey:   "value_weekend",
+		},
+		{
+			name:          "weekday value",
+			config:        map[string]string{"value_weekday": "5", "value_weekend": "1"},
+			now:           monday,
+			expectedValue: 5,
+			expectedKey:   "value_weekday",
+		},
+		{
+			name:          "day value overrides weekend",
+			config:        map[string]string{"value_weekend": "1", "value_saturday": "2"},
+			now:           saturday,
+			expectedValue: 2,
+			expectedKey:   "value_saturday",
+		},
+		{
+			name: "timezone",
+			config: map[string]string{
+				"value_weekday": "5",
+				"value_weekend": "1",
+				"timezone":      "America/Los_Angeles",
+			},
+			// Monday 02:00 UTC is still Sunday in Los Angeles.
+			now:           time.Date(2021, 1, 4, 2, 0, 0, 0, time.UTC),
+			expectedValue: 1,
+			expectedKey:   "value_weekend",
+		},
+		{
+			name:          "no value for day",
+			config:        map[string]string{"value_weekday": "5"},
+			now:           saturday,
+			expectedError: "missing required field `value`, no value set for Saturday",
+		},
+		{
+			name:          "invalid day value",
+			config:        map[string]string{"value": "3", "value_friday": "many"},
+			now:           saturday,
+			expectedError: "invalid value for `value_friday`: many (string)",
+		},
+		{
+			name:          "invalid timezone",
+			config:        map[string]string{"value": "3", "timezone": "Mars/Olympus"},
+			now:           saturday,
+			expectedError: "invalid value for `timezone`",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			value, key, err := scheduledValue(tc.config, tc.now)
+			if tc.expectedError != "" {
+				assert.ErrorContains(t, err, tc.expectedError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedValue, value)
+			assert.Equal(t, tc.expectedKey, key)
+		})
+	}
+}
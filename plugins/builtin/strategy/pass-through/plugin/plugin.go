@@ -5,6 +5,7 @@ package plugin
 
 import (
 	"fmt"
+	"math"
 	"strconv"
 
 	"github.com/hashicorp/go-hclog"
@@ -22,6 +23,8 @@ const (
 	// These are the keys read from the RunRequest.Config map.
 	runConfigKeyMaxScaleUp   = "max_scale_up"
 	runConfigKeyMaxScaleDown = "max_scale_down"
+	runConfigKeyMultiplier   = "multiplier"
+	runConfigKeyOffset       = "offset"
 )
 
 var (
@@ -95,6 +98,28 @@ func (s *StrategyPlugin) Run(eval *sdk.ScalingCheckEvaluation, count int64) (*sd
 		maxScaleDownStr = "-Inf"
 	}
 
+	// Read and parse multiplier from req.Config.
+	multiplier := 1.0
+	multiplierStr := eval.Check.Strategy.Config[runConfigKeyMultiplier]
+	if multiplierStr != "" {
+		m, err := strconv.ParseFloat(multiplierStr, 64)
+		if err != nil || m <= 0 {
+			return nil, fmt.Errorf("invalid value for `multiplier`: %v (%T)", multiplierStr, multiplierStr)
+		}
+		multiplier = m
+	}
+
+	// Read and parse offset from req.Config.
+	var offset int64
+	offsetStr := eval.Check.Strategy.Config[runConfigKeyOffset]
+	if offsetStr != "" {
+		o, err := strconv.ParseInt(offsetStr, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value for `offset`: %v (%T)", offsetStr, offsetStr)
+		}
+		offset = o
+	}
+
 	if len(eval.Metrics) == 0 {
 		return nil, nil
 	}
@@ -102,13 +127,21 @@ func (s *StrategyPlugin) Run(eval *sdk.ScalingCheckEvaluation, count int64) (*sd
 	// Use only the latest value for now.
 	metric := eval.Metrics[len(eval.Metrics)-1]
 
+	// Adjust the metric value with the multiplier and offset. The adjusted
+	// value is rounded up so any headroom added by the multiplier is not lost.
+	value := metric.Value
+	adjusted := multiplierStr != "" || offsetStr != ""
+	if adjusted {
+		value = math.Max(0, math.Ceil(metric.Value*multiplier)+float64(offset))
+	}
+
 	// Identify the direction of scaling, if any.
-	eval.Action.Direction = s.calculateDirection(count, metric.Value)
+	eval.Action.Direction = s.calculateDirection(count, value)
 	if eval.Action.Direction == sdk.ScaleDirectionNone {
 		return eval, nil
 	}
 
-	newCount := int64(metric.Value)
+	newCount := int64(value)
 
 	switch eval.Action.Direction {
 	case sdk.ScaleDirectionUp:
@@ -124,11 +157,15 @@ func (s *StrategyPlugin) Run(eval *sdk.ScalingCheckEvaluation, count int64) (*sd
 	// Log at trace level the details of the strategy calculation.
 	s.logger.Trace("calculated scaling strategy results",
 		"check_name", eval.Check.Name, "current_count", count, "new_count", newCount,
-		"metric_value", metric.Value, "metric_time", metric.Timestamp,
+		"metric_value", metric.Value, "metric_time", metric.Timestamp, "adjusted_value", value,
 		"direction", eval.Action.Direction, "max_scale_up", maxScaleUpStr, "max_scale_down", maxScaleDownStr)
 
 	eval.Action.Count = newCount
 	eval.Action.Reason = fmt.Sprintf("scaling %s because metric is %d", eval.Action.Direction, int64(metric.Value))
+	if adjusted {
+		eval.Action.Reason = fmt.Sprintf("scaling %s because metric is %d, adjusted to %d by multiplier %g and offset %d",
+			eval.Action.Direction, int64(metric.Value), int64(value), multiplier, offset)
+	}
 
 	return eval, nil
 }
@@ -210,6 +210,84 @@ func TestStrategyPlugin_Run(t *testing.T) {
 			expectedError: nil,
 			name:          "pass-through scale down, but max_scale_down is set",
 		},
+		{
+			inputEval: &sdk.ScalingCheckEvaluation{
+				Metrics: sdk.TimestampedMetrics{sdk.TimestampedMetric{Value: 10}},
+				Check: &sdk.ScalingPolicyCheck{
+					Strategy: &sdk.ScalingPolicyStrategy{
+						Config: map[string]string{"multiplier": "1.25", "offset": "1"},
+					},
+				},
+				Action: &sdk.ScalingAction{},
+			},
+			inputCount: 2,
+			expectedResp: &sdk.ScalingCheckEvaluation{
+				Metrics: sdk.TimestampedMetrics{sdk.TimestampedMetric{Value: 10}},
+				Check: &sdk.ScalingPolicyCheck{
+					Strategy: &sdk.ScalingPolicyStrategy{
+						Config: map[string]string{"multiplier": "1.25", "offset": "1"},
+					},
+				},
+				Action: &sdk.ScalingAction{
+					Count:     14,
+					Direction: sdk.ScaleDirectionUp,
+					Reason:    "scaling up because metric is 10, adjusted to 14 by multiplier 1.25 and offset 1",
+				},
+			},
+			expectedError: nil,
+			name:          "pass-through scale up with multiplier and offset",
+		},
+		{
+			inputEval: &sdk.ScalingCheckEvaluation{
+				Metrics: sdk.TimestampedMetrics{sdk.TimestampedMetric{Value: 1}},
+				Check: &sdk.ScalingPolicyCheck{
+					Strategy: &sdk.ScalingPolicyStrategy{
+						Config: map[string]string{"offset": "-3"},
+					},
+				},
+				Action: &sdk.ScalingAction{},
+			},
+			inputCount: 2,
+			expectedResp: &sdk.ScalingCheckEvaluation{
+				Metrics: sdk.TimestampedMetrics{sdk.TimestampedMetric{Value: 1}},
+				Check: &sdk.ScalingPolicyCheck{
+					Strategy: &sdk.ScalingPolicyStrategy{
+						Config: map[string]string{"offset": "-3"},
+					},
+				},
+				Action: &sdk.ScalingAction{
+					Count:     0,
+					Direction: sdk.ScaleDirectionDown,
+					Reason:    "scaling down because metric is 1, adjusted to 0 by multiplier 1 and offset -3",
+				},
+			},
+			expectedError: nil,
+			name:          "pass-through negative offset doesn't go below zero",
+		},
+		{
+			inputEval: &sdk.ScalingCheckEvaluation{
+				Check: &sdk.ScalingPolicyCheck{
+					Strategy: &sdk.ScalingPolicyStrategy{
+						Config: map[string]string{"multiplier": "0"},
+					},
+				},
+			},
+			expectedResp:  nil,
+			expectedError: errors.New("invalid value for `multiplier`: 0 (string)"),
+			name:          "incorrect input strategy config multiplier value",
+		},
+		{
+			inputEval: &sdk.ScalingCheckEvaluation{
+				Check: &sdk.ScalingPolicyCheck{
+					Strategy: &sdk.ScalingPolicyStrategy{
+						Config: map[string]string{"offset": "1.5"},
+					},
+				},
+			},
+			expectedResp:  nil,
+			expectedError: errors.New("invalid value for `offset`: 1.5 (string)"),
+			name:          "incorrect input strategy config offset value",
+		},
 	}
 
 	for _, tc := range testCases {
@@ -0,0 +1,181 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package plugin
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/nomad-autoscaler/plugins"
+	"github.com/hashicorp/nomad-autoscaler/plugins/base"
+	"github.com/hashicorp/nomad-autoscaler/plugins/strategy"
+	"github.com/hashicorp/nomad-autoscaler/sdk"
+)
+
+const (
+	// pluginName is the unique name of the this plugin amongst strategy
+	// plugins.
+	pluginName = "percentile"
+
+	// These are the keys read from the RunRequest.Config map.
+	runConfigKeyPercentile = "percentile"
+	runConfigKeyMargin     = "margin"
+	runConfigKeyThreshold  = "threshold"
+
+	// defaultPercentile is the percentile of the metrics used to size the
+	// target when not configured.
+	defaultPercentile = "95"
+
+	// defaultMargin is the fraction added to the percentile value to leave
+	// headroom above the observed usage.
+	defaultMargin = "0"
+
+	// defaultThreshold is the minimum relative change from the current value
+	// required to scale. Resizing task resources replaces the allocations, so
+	// small changes are ignored.
+	defaultThreshold = "0.05"
+)
+
+var (
+	PluginID = plugins.PluginID{
+		Name:       pluginName,
+		PluginType: sdk.PluginTypeStrategy,
+	}
+
+	PluginConfig = &plugins.InternalPluginConfig{
+		Factory: func(l hclog.Logger) interface{} { return NewPercentilePlugin(l) },
+	}
+
+	pluginInfo = &base.PluginInfo{
+		Name:       pluginName,
+		PluginType: sdk.PluginTypeStrategy,
+	}
+)
+
+// Assert that StrategyPlugin meets the strategy.Strategy interface.
+var _ strategy.Strategy = (*StrategyPlugin)(nil)
+
+// StrategyPlugin is the Percentile implementation of the strategy.Strategy
+// interface. It sets the target to a percentile of the metric values returned
+// over the check query window, and is intended for the rightsizing of task
+// resources using vertical policies. The metric must therefore be expressed
+// in the unit of the resource, MHz for CPU and MB for memory.
+type StrategyPlugin struct {
+	config map[string]string
+	logger hclog.Logger
+}
+
+// NewPercentilePlugin returns the Percentile implementation of the
+// strategy.Strategy interface.
+func NewPercentilePlugin(log hclog.Logger) strategy.Strategy {
+	return &StrategyPlugin{
+		logger: log,
+	}
+}
+
+// SetConfig satisfies the SetConfig function on the base.Base interface.
+func (s *StrategyPlugin) SetConfig(config map[string]string) error {
+	s.config = config
+	return nil
+}
+
+// PluginInfo satisfies the PluginInfo function on the base.Base interface.
+func (s *StrategyPlugin) PluginInfo() (*base.PluginInfo, error) {
+	return pluginInfo, nil
+}
+
+// Run satisfies the Run function on the strategy.Strategy interface.
+func (s *StrategyPlugin) Run(eval *sdk.ScalingCheckEvaluation, count int64) (*sdk.ScalingCheckEvaluation, error) {
+	if len(eval.Metrics) == 0 {
+		return nil, nil
+	}
+
+	p, err := parseFloatConfig(eval.Check.Strategy.Config, runConfigKeyPercentile, defaultPercentile)
+	if err != nil {
+		return nil, err
+	}
+	if p <= 0 || p > 100 {
+		return nil, fmt.Errorf("invalid value for `%s`: must be greater than 0 and at most 100", runConfigKeyPercentile)
+	}
+
+	margin, err := parseFloatConfig(eval.Check.Strategy.Config, runConfigKeyMargin, defaultMargin)
+	if err != nil {
+		return nil, err
+	}
+	if margin < 0 {
+		return nil, fmt.Errorf("invalid value for `%s`: must not be negative", runConfigKeyMargin)
+	}
+
+	threshold, err := parseFloatConfig(eval.Check.Strategy.Config, runConfigKeyThreshold, defaultThreshold)
+	if err != nil {
+		return nil, err
+	}
+
+	value := percentile(eval.Metrics, p)
+	newCount := int64(math.Ceil(value * (1 + margin)))
+
+	// Log at trace level the details of the strategy calculation. This is
+	// helpful in ultra-debugging situations when there is a need to understand
+	// all the calculations made.
+	s.logger.Trace("calculated scaling strategy results",
+		"check_name", eval.Check.Name, "current_count", count, "new_count", newCount,
+		"percentile", p, "percentile_value", value, "margin", margin, "metrics", len(eval.Metrics))
+
+	// Ignore changes smaller than the threshold to avoid resizing the target
+	// on every small variation of the metrics.
+	if newCount == count || (count > 0 && math.Abs(float64(newCount-count))/float64(count) < threshold) {
+		eval.Action.Direction = sdk.ScaleDirectionNone
+		return eval, nil
+	}
+
+	if newCount > count {
+		eval.Action.Direction = sdk.ScaleDirectionUp
+	} else {
+		eval.Action.Direction = sdk.ScaleDirectionDown
+	}
+
+	eval.Action.Count = newCount
+	eval.Action.Reason = fmt.Sprintf("scaling %s because p%s of the metric is %f",
+		eval.Action.Direction, strconv.FormatFloat(p, 'f', -1, 64), value)
+	eval.Action.SetExplanation(sdk.ScalingActionExplanation{
+		Samples: len(eval.Metrics),
+		Details: map[string]interface{}{"percentile": p, "percentile_value": value},
+	})
+
+	return eval, nil
+}
+
+// percentile returns the p percentile of the metric values using the
+// nearest-rank method.
+func percentile(metrics sdk.TimestampedMetrics, p float64) float64 {
+	values := make([]float64, len(metrics))
+	for i, m := range metrics {
+		values[i] = m.Value
+	}
+	sort.Float64s(values)
+
+	rank := int(math.Ceil(p / 100 * float64(len(values))))
+	if rank < 1 {
+		rank = 1
+	}
+	return values[rank-1]
+}
+
+// parseFloatConfig reads and parses the float value of key from the strategy
+// config, returning the default value if the key is not set.
+func parseFloatConfig(config map[string]string, key, defaultValue string) (float64, error) {
+	v := config[key]
+	if v == "" {
+		v = defaultValue
+	}
+
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value for `%s`: %v (%T)", key, v, v)
+	}
+	return f, nil
+}
@@ -0,0 +1,132 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package plugin
+
+import (
+	"testing"
+
+	hclog "github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/nomad-autoscaler/plugins/base"
+	"github.com/hashicorp/nomad-autoscaler/sdk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStrategyPlugin_PluginInfo(t *testing.T) {
+	s := &StrategyPlugin{}
+	expectedOutput := &base.PluginInfo{Name: "percentile", PluginType: "strategy"}
+	actualOutput, err := s.PluginInfo()
+	assert.Nil(t, err)
+	assert.Equal(t, expectedOutput, actualOutput)
+}
+
+func TestStrategyPlugin_Run(t *testing.T) {
+	// Metric values 10, 20, ..., 100.
+	metrics := sdk.TimestampedMetrics{}
+	for i := 1; i <= 10; i++ {
+		metrics = append(metrics, sdk.TimestampedMetric{Value: float64(i * 10)})
+	}
+
+	testCases := []struct {
+		name              string
+		config            map[string]string
+		metrics           sdk.TimestampedMetrics
+		count             int64
+		expectedNil       bool
+		expectedDirection sdk.ScaleDirection
+		expectedCount     int64
+		expectedErr       string
+	}{
+		{
+			name:        "no metrics",
+			metrics:     nil,
+			count:       100,
+			expectedNil: true,
+		},
+		{
+			name:              "default percentile scale down",
+			metrics:           metrics,
+			count:             200,
+			expectedDirection: sdk.ScaleDirectionDown,
+			expectedCount:     100,
+		},
+		{
+			name:              "percentile with margin scale up",
+			config:            map[string]string{"percentile": "50", "margin": "0.5"},
+			metrics:           metrics,
+			count:             40,
+			expectedDirection: sdk.ScaleDirectionUp,
+			expectedCount:     75,
+		},
+		{
+			name:              "change below threshold",
+			config:            map[string]string{"percentile": "90"},
+			metrics:           metrics,
+			count:             88,
+			expectedDirection: sdk.ScaleDirectionNone,
+		},
+		{
+			name:              "scale from zero",
+			config:            map[string]string{"percentile": "10"},
+			metrics:           metrics,
+			count:             0,
+			expectedDirection: sdk.ScaleDirectionUp,
+			expectedCount:     10,
+		},
+		{
+			name:        "invalid percentile",
+			config:      map[string]string{"percentile": "101"},
+			metrics:     metrics,
+			expectedErr: "invalid value for `percentile`: must be greater than 0 and at most 100",
+		},
+		{
+			name:        "invalid margin",
+			config:      map[string]string{"margin": "lots"},
+			metrics:     metrics,
+			expectedErr: "invalid value for `margin`: lots (string)",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := NewPercentilePlugin(hclog.NewNullLogger())
+
+			eval := &sdk.ScalingCheckEvaluation{
+				Check: &sdk.ScalingPolicyCheck{
+					Strategy: &sdk.ScalingPolicyStrategy{Config: tc.config},
+				},
+				Metrics: tc.metrics,
+				Action:  &sdk.ScalingAction{},
+			}
+
+			got, err := s.Run(eval, tc.count)
+			if tc.expectedErr != "" {
+				assert.EqualError(t, err, tc.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+
+			if tc.expectedNil {
+				assert.Nil(t, got)
+				return
+			}
+
+			assert.Equal(t, tc.expectedDirection, got.Action.Direction)
+			if tc.expectedDirection != sdk.ScaleDirectionNone {
+				assert.Equal(t, tc.expectedCount, got.Action.Count)
+				require.NotNil(t, got.Action.Explanation())
+				assert.Equal(t, len(tc.metrics), got.Action.Explanation().Samples)
+			}
+		})
+	}
+}
+
+func Test_percentile(t *testing.T) {
+	metrics := sdk.TimestampedMetrics{{Value: 3}, {Value: 1}, {Value: 2}, {Value: 4}}
+
+	assert.Equal(t, 1.0, percentile(metrics, 1))
+	assert.Equal(t, 2.0, percentile(metrics, 50))
+	assert.Equal(t, 4.0, percentile(metrics, 95))
+	assert.Equal(t, 4.0, percentile(metrics, 100))
+}
@@ -27,9 +27,19 @@ const (
 	runConfigKeyMaxScaleUp   = "max_scale_up"
 	runConfigKeyMaxScaleDown = "max_scale_down"
 
+	runConfigKeyToleranceUpper = "tolerance_upper"
+	runConfigKeyToleranceLower = "tolerance_lower"
+	runConfigKeyRounding       = "rounding"
+
 	// defaultThreshold controls how significant is a change in the input
 	// metric value.
 	defaultThreshold = "0.01"
+
+	// The supported rounding modes used to convert the calculated count into
+	// an integer.
+	roundingCeil    = "ceil"
+	roundingFloor   = "floor"
+	roundingNearest = "nearest"
 )
 
 var (
@@ -105,6 +115,28 @@ func (s *StrategyPlugin) Run(eval *sdk.ScalingCheckEvaluation, count int64) (*sd
 		return nil, fmt.Errorf("invalid value for `threshold`: %v (%T)", th, th)
 	}
 
+	// Read and parse the tolerance band from req.Config. Each side of the
+	// band defaults to the threshold value.
+	toleranceUpper, err := parseTolerance(runConfigKeyToleranceUpper, eval.Check.Strategy.Config[runConfigKeyToleranceUpper], threshold)
+	if err != nil {
+		return nil, err
+	}
+	toleranceLower, err := parseTolerance(runConfigKeyToleranceLower, eval.Check.Strategy.Config[runConfigKeyToleranceLower], threshold)
+	if err != nil {
+		return nil, err
+	}
+
+	// Read and validate the rounding mode from req.Config.
+	rounding := eval.Check.Strategy.Config[runConfigKeyRounding]
+	switch rounding {
+	case "":
+		rounding = roundingCeil
+	case roundingCeil, roundingFloor, roundingNearest:
+	default:
+		return nil, fmt.Errorf("invalid value for `rounding`: %v, must be one of %q, %q or %q",
+			rounding, roundingCeil, roundingFloor, roundingNearest)
+	}
+
 	// Read and parse max_scale_up from req.Config.
 	var maxScaleUp *int64
 	maxScaleUpStr := eval.Check.Strategy.Config[runConfigKeyMaxScaleUp]
@@ -147,7 +179,7 @@ func (s *StrategyPlugin) Run(eval *sdk.ScalingCheckEvaluation, count int64) (*sd
 	}
 
 	// Identify the direction of scaling, if any.
-	eval.Action.Direction = s.calculateDirection(count, factor, threshold)
+	eval.Action.Direction = s.calculateDirection(count, factor, toleranceLower, toleranceUpper)
 	if eval.Action.Direction == sdk.ScaleDirectionNone {
 		return eval, nil
 	}
@@ -159,9 +191,9 @@ func (s *StrategyPlugin) Run(eval *sdk.ScalingCheckEvaluation, count int64) (*sd
 	// standard calculation.
 	switch count {
 	case 0:
-		newCount = int64(math.Ceil(factor))
+		newCount = round(rounding, factor)
 	default:
-		newCount = int64(math.Ceil(float64(count) * factor))
+		newCount = round(rounding, float64(count)*factor)
 	}
 
 	// Limit the increase or decrease with the values specified in max_scale_up and max_scale_down.
@@ -178,7 +210,8 @@ func (s *StrategyPlugin) Run(eval *sdk.ScalingCheckEvaluation, count int64) (*sd
 	s.logger.Trace("calculated scaling strategy results",
 		"check_name", eval.Check.Name, "current_count", count, "new_count", newCount,
 		"metric_value", metric.Value, "metric_time", metric.Timestamp, "factor", factor,
-		"direction", eval.Action.Direction, "max_scale_up", maxScaleUpStr, "max_scale_down", maxScaleDownStr)
+		"direction", eval.Action.Direction, "max_scale_up", maxScaleUpStr, "max_scale_down", maxScaleDownStr,
+		"tolerance_lower", toleranceLower, "tolerance_upper", toleranceUpper, "rounding", rounding)
 
 	// If the calculated newCount is the same as the current count, we do not
 	// need to scale so return an empty response.
@@ -189,17 +222,45 @@ func (s *StrategyPlugin) Run(eval *sdk.ScalingCheckEvaluation, count int64) (*sd
 
 	eval.Action.Count = newCount
 	eval.Action.Reason = fmt.Sprintf("scaling %s because factor is %f", eval.Action.Direction, factor)
+	eval.Action.SetExplanation(sdk.ScalingActionExplanation{Ratio: factor, Samples: 1})
 
 	return eval, nil
 }
 
+// parseTolerance parses and validates the value for one side of the tolerance
+// band.
+func parseTolerance(key, input string, defaultValue float64) (float64, error) {
+	if input == "" {
+		return defaultValue, nil
+	}
+
+	v, err := strconv.ParseFloat(input, 64)
+	if err != nil || v < 0 {
+		return 0, fmt.Errorf("invalid value for `%s`: %v (%T)", key, input, input)
+	}
+	return v, nil
+}
+
+// round converts the calculated count into an integer using the rounding
+// mode.
+func round(mode string, v float64) int64 {
+	switch mode {
+	case roundingFloor:
+		return int64(math.Floor(v))
+	case roundingNearest:
+		return int64(math.Round(v))
+	default:
+		return int64(math.Ceil(v))
+	}
+}
+
 // calculateDirection is used to calculate the direction of scaling that should
 // occur, if any at all. It takes into account the current task group count in
 // order to correctly account for 0 counts.
 //
-// The input factor value is padded by e, such that no action will be taken if
-// factor is within [1-e; 1+e].
-func (s *StrategyPlugin) calculateDirection(count int64, factor, e float64) sdk.ScaleDirection {
+// The input factor value is padded by the lower and upper tolerances, such
+// that no action will be taken if factor is within [1-lower; 1+upper].
+func (s *StrategyPlugin) calculateDirection(count int64, factor, lower, upper float64) sdk.ScaleDirection {
 	switch count {
 	case 0:
 		if factor > 0 {
@@ -207,9 +268,9 @@ func (s *StrategyPlugin) calculateDirection(count int64, factor, e float64) sdk.
 		}
 		return sdk.ScaleDirectionNone
 	default:
-		if factor < (1 - e) {
+		if factor < (1 - lower) {
 			return sdk.ScaleDirectionDown
-		} else if factor > (1 + e) {
+		} else if factor > (1 + upper) {
 			return sdk.ScaleDirectionUp
 		} else {
 			return sdk.ScaleDirectionNone
@@ -165,6 +165,7 @@ func TestStrategyPlugin_Run(t *testing.T) {
 					Count:     4,
 					Reason:    "scaling up because factor is 2.000000",
 					Direction: sdk.ScaleDirectionUp,
+					Meta:      explanationMeta(26, 13),
 				},
 			},
 			expectedError: nil,
@@ -192,6 +193,7 @@ func TestStrategyPlugin_Run(t *testing.T) {
 					Count:     2,
 					Reason:    "scaling up because factor is 2.000000",
 					Direction: sdk.ScaleDirectionUp,
+					Meta:      explanationMeta(20, 10),
 				},
 			},
 			expectedError: nil,
@@ -244,6 +246,7 @@ func TestStrategyPlugin_Run(t *testing.T) {
 					Count:     1,
 					Reason:    "scaling up because factor is 0.100000",
 					Direction: sdk.ScaleDirectionUp,
+					Meta:      explanationMeta(1, 10),
 				},
 			},
 			expectedError: nil,
@@ -271,6 +274,7 @@ func TestStrategyPlugin_Run(t *testing.T) {
 					Count:     0,
 					Direction: sdk.ScaleDirectionDown,
 					Reason:    "scaling down because factor is 0.000000",
+					Meta:      explanationMeta(0, 1),
 				},
 			},
 			expectedError: nil,
@@ -323,6 +327,7 @@ func TestStrategyPlugin_Run(t *testing.T) {
 					Count:     9,
 					Reason:    "scaling up because factor is 1.000002",
 					Direction: sdk.ScaleDirectionUp,
+					Meta:      explanationMeta(5.00001, 5),
 				},
 			},
 			expectedError: nil,
@@ -385,6 +390,7 @@ func TestStrategyPlugin_Run(t *testing.T) {
 					Count:     3,
 					Reason:    "scaling up because factor is 4.200000",
 					Direction: sdk.ScaleDirectionUp,
+					Meta:      explanationMeta(210, 50),
 				},
 			},
 			expectedError: nil,
@@ -412,11 +418,92 @@ func TestStrategyPlugin_Run(t *testing.T) {
 					Count:     4,
 					Reason:    "scaling down because factor is 0.047619",
 					Direction: sdk.ScaleDirectionDown,
+					Meta:      explanationMeta(10, 210),
 				},
 			},
 			expectedError: nil,
 			name:          "scale down limited to max_scale_down",
 		},
+		{
+			inputEval: &sdk.ScalingCheckEvaluation{
+				Metrics: sdk.TimestampedMetrics{sdk.TimestampedMetric{Value: 9}},
+				Check: &sdk.ScalingPolicyCheck{
+					Strategy: &sdk.ScalingPolicyStrategy{
+						Config: map[string]string{"target": "10", "tolerance_lower": "0.15"},
+					},
+				},
+				Action: &sdk.ScalingAction{},
+			},
+			inputCount: 10,
+			expectedResp: &sdk.ScalingCheckEvaluation{
+				Metrics: sdk.TimestampedMetrics{sdk.TimestampedMetric{Value: 9}},
+				Check: &sdk.ScalingPolicyCheck{
+					Strategy: &sdk.ScalingPolicyStrategy{
+						Config: map[string]string{"target": "10", "tolerance_lower": "0.15"},
+					},
+				},
+				Action: &sdk.ScalingAction{
+					Direction: sdk.ScaleDirectionNone,
+				},
+			},
+			expectedError: nil,
+			name:          "don't scale down within tolerance_lower",
+		},
+		{
+			inputEval: &sdk.ScalingCheckEvaluation{
+				Metrics: sdk.TimestampedMetrics{sdk.TimestampedMetric{Value: 11.5}},
+				Check: &sdk.ScalingPolicyCheck{
+					Strategy: &sdk.ScalingPolicyStrategy{
+						Config: map[string]string{"target": "10", "rounding": "floor"},
+					},
+				},
+				Action: &sdk.ScalingAction{},
+			},
+			inputCount: 3,
+			expectedResp: &sdk.ScalingCheckEvaluation{
+				Metrics: sdk.TimestampedMetrics{sdk.TimestampedMetric{Value: 11.5}},
+				Check: &sdk.ScalingPolicyCheck{
+					Strategy: &sdk.ScalingPolicyStrategy{
+						Config: map[string]string{"target": "10", "rounding": "floor"},
+					},
+				},
+				Action: &sdk.ScalingAction{
+					Direction: sdk.ScaleDirectionNone,
+				},
+			},
+			expectedError: nil,
+			name:          "floor rounding avoids scaling up by one",
+		},
+		{
+			inputEval: &sdk.ScalingCheckEvaluation{
+				Metrics: sdk.TimestampedMetrics{sdk.TimestampedMetric{Value: 13}},
+				Check: &sdk.ScalingPolicyCheck{
+					Strategy: &sdk.ScalingPolicyStrategy{
+						Config: map[string]string{"target": "10", "tolerance_upper": "-1"},
+					},
+				},
+				Action: &sdk.ScalingAction{},
+			},
+			inputCount:    3,
+			expectedResp:  nil,
+			expectedError: errors.New("invalid value for `tolerance_upper`: -1 (string)"),
+			name:          "invalid tolerance_upper",
+		},
+		{
+			inputEval: &sdk.ScalingCheckEvaluation{
+				Metrics: sdk.TimestampedMetrics{sdk.TimestampedMetric{Value: 13}},
+				Check: &sdk.ScalingPolicyCheck{
+					Strategy: &sdk.ScalingPolicyStrategy{
+						Config: map[string]string{"target": "10", "rounding": "up"},
+					},
+				},
+				Action: &sdk.ScalingAction{},
+			},
+			inputCount:    3,
+			expectedResp:  nil,
+			expectedError: errors.New("invalid value for `rounding`: up, must be one of \"ceil\", \"floor\" or \"nearest\""),
+			name:          "invalid rounding",
+		},
 	}
 
 	for _, tc := range testCases {
@@ -449,6 +536,46 @@ func TestStrategyPlugin_calculateDirection(t *testing.T) {
 	s := &StrategyPlugin{}
 
 	for _, tc := range testCases {
-		assert.Equal(t, tc.expectedOutput, s.calculateDirection(tc.inputCount, tc.inputFactor, tc.threshold))
+		assert.Equal(t, tc.expectedOutput, s.calculateDirection(tc.inputCount, tc.inputFactor, tc.threshold, tc.threshold))
+	}
+
+	// Asymmetric tolerance band.
+	asymmetricCases := []struct {
+		inputFactor    float64
+		expectedOutput sdk.ScaleDirection
+	}{
+		{inputFactor: 0.85, expectedOutput: sdk.ScaleDirectionNone},
+		{inputFactor: 1.1, expectedOutput: sdk.ScaleDirectionUp},
+		{inputFactor: 0.75, expectedOutput: sdk.ScaleDirectionDown},
+	}
+
+	for _, tc := range asymmetricCases {
+		assert.Equal(t, tc.expectedOutput, s.calculateDirection(5, tc.inputFactor, 0.2, 0.05))
+	}
+}
+
+func Test_round(t *testing.T) {
+	testCases := []struct {
+		mode     string
+		input    float64
+		expected int64
+	}{
+		{mode: "ceil", input: 4.2, expected: 5},
+		{mode: "floor", input: 4.8, expected: 4},
+		{mode: "nearest", input: 4.4, expected: 4},
+		{mode: "nearest", input: 4.5, expected: 5},
+		{mode: "", input: 4.2, expected: 5},
+	}
+
+	for _, tc := range testCases {
+		assert.Equal(t, tc.expected, round(tc.mode, tc.input), "%s(%v)", tc.mode, tc.input)
+	}
+}
+
+// explanationMeta returns the action Meta holding the explanation of a
+// target-value calculation. The target value 0 uses the metric as the ratio.
+func explanationMeta(metric, target float64) map[string]interface{} {
+	return map[string]interface{}{
+		"nomad_autoscaler.explanation": sdk.ScalingActionExplanation{Ratio: metric / target, Samples: 1},
 	}
 }
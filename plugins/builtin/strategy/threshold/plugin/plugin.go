@@ -6,6 +6,7 @@ package plugin
 import (
 	"fmt"
 	"math"
+	"sort"
 	"strconv"
 
 	"github.com/hashicorp/go-hclog"
@@ -28,6 +29,10 @@ const (
 	runConfigKeyValue               = "value"
 	runConfigKeyWithinBoundsTrigger = "within_bounds_trigger"
 
+	runConfigKeyWithinBoundsTriggerUpper = "within_bounds_trigger_upper"
+	runConfigKeyWithinBoundsTriggerLower = "within_bounds_trigger_lower"
+	runConfigKeyGraceSamples             = "grace_samples"
+
 	// defaultWithinBoundsTrigger is the default value for the
 	// within_bounds_trigger check run config.
 	defaultWithinBoundsTrigger = 5
@@ -56,6 +61,16 @@ type thresholdPluginRunConfig struct {
 	actionType          string
 	actionValue         float64
 	withinboundsTrigger int
+
+	// upperTrigger and lowerTrigger are the number of data points that must
+	// be below the upper bound and above the lower bound respectively. They
+	// are only set when the user configured per bound triggers.
+	upperTrigger int
+	lowerTrigger int
+	perBound     bool
+
+	// graceSamples is the number of most recent data points to ignore.
+	graceSamples int
 }
 
 // Assert that StrategyPlugin meets the strategy.Strategy interface.
@@ -173,6 +188,24 @@ func parseConfig(config map[string]string) (*thresholdPluginRunConfig, error) {
 	}
 	c.withinboundsTrigger = trigger
 
+	// Read and parse the per bound triggers, which default to the
+	// within_bounds_trigger value.
+	upperTriggerStr := config[runConfigKeyWithinBoundsTriggerUpper]
+	lowerTriggerStr := config[runConfigKeyWithinBoundsTriggerLower]
+
+	c.perBound = upperTriggerStr != "" || lowerTriggerStr != ""
+	if c.upperTrigger, err = parseCount(runConfigKeyWithinBoundsTriggerUpper, upperTriggerStr, trigger); err != nil {
+		return nil, err
+	}
+	if c.lowerTrigger, err = parseCount(runConfigKeyWithinBoundsTriggerLower, lowerTriggerStr, trigger); err != nil {
+		return nil, err
+	}
+
+	// Read and parse the number of recent data points to ignore.
+	if c.graceSamples, err = parseCount(runConfigKeyGraceSamples, config[runConfigKeyGraceSamples], 0); err != nil {
+		return nil, err
+	}
+
 	// Read and validate action type from check config.
 	deltaStr := config[runConfigKeyDelta]
 	percentageStr := config[runConfigKeyPercentage]
@@ -253,17 +286,65 @@ func parseBound(bound string, input string) (float64, error) {
 	return value, nil
 }
 
+// parseCount parses and validates a non-negative integer config value.
+func parseCount(key string, input string, defaultValue int) (int, error) {
+	if input == "" {
+		return defaultValue, nil
+	}
+
+	v, err := strconv.ParseInt(input, 10, 0)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value for %q: %v (%T)", key, input, input)
+	}
+	if v < 0 {
+		return 0, fmt.Errorf("%q value %v is negative", key, input)
+	}
+
+	return int(v), nil
+}
+
 // withinBounds returns true if the metric result is considered within bounds.
 func withinBounds(logger hclog.Logger, metrics sdk.TimestampedMetrics, config *thresholdPluginRunConfig) bool {
 	logger.Trace("checking how many data points are within bounds")
 
-	withinBoundsCounter := 0
+	// Drop the most recent data points, which may have been partially
+	// collected by the APM.
+	if config.graceSamples > 0 {
+		if config.graceSamples >= len(metrics) {
+			logger.Trace("all data points are within the grace samples")
+			return false
+		}
+
+		sorted := make(sdk.TimestampedMetrics, len(metrics))
+		copy(sorted, metrics)
+		sort.Stable(sorted)
+		metrics = sorted[:len(sorted)-config.graceSamples]
+	}
+
+	withinBoundsCounter, belowUpperCounter, aboveLowerCounter := 0, 0, 0
 	for _, metric := range metrics {
-		if metric.Value >= config.lowerBound && metric.Value < config.upperBound {
+		aboveLower := metric.Value >= config.lowerBound
+		belowUpper := metric.Value < config.upperBound
+
+		if aboveLower {
+			aboveLowerCounter++
+		}
+		if belowUpper {
+			belowUpperCounter++
+		}
+		if aboveLower && belowUpper {
 			withinBoundsCounter++
 		}
 	}
 
+	// When per bound triggers are configured, each bound is evaluated
+	// independently against its own trigger.
+	if config.perBound {
+		logger.Trace(fmt.Sprintf("found %d data points below upper bound and %d above lower bound",
+			belowUpperCounter, aboveLowerCounter))
+		return belowUpperCounter >= config.upperTrigger && aboveLowerCounter >= config.lowerTrigger
+	}
+
 	logger.Trace(fmt.Sprintf("found %d data points within bounds", withinBoundsCounter))
 	return withinBoundsCounter >= config.withinboundsTrigger
 }
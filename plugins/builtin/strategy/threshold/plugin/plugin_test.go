@@ -5,6 +5,7 @@ package plugin
 
 import (
 	"testing"
+	"time"
 
 	"github.com/hashicorp/go-hclog"
 	"github.com/hashicorp/nomad-autoscaler/sdk"
@@ -279,15 +280,132 @@ func TestThresholdPlugin(t *testing.T) {
 			},
 			expectedErr: `invalid value for "within_bounds_trigger"`,
 		},
+		{
+			name:    "per bound triggers",
+			count:   1,
+			metrics: []float64{30, 30, 0, 0, 0},
+			config: map[string]string{
+				"lower_bound":                 "5",
+				"upper_bound":                 "20",
+				"delta":                       "1",
+				"within_bounds_trigger_lower": "2",
+				"within_bounds_trigger_upper": "3",
+			},
+			expectedAction: &sdk.ScalingAction{
+				Count:     2,
+				Reason:    "scaling up because metric is within bounds",
+				Direction: sdk.ScaleDirectionUp,
+			},
+		},
+		{
+			name:    "per bound trigger not met",
+			count:   1,
+			metrics: []float64{30, 30, 0, 0, 0},
+			config: map[string]string{
+				"lower_bound":                 "5",
+				"upper_bound":                 "20",
+				"delta":                       "1",
+				"within_bounds_trigger_lower": "3",
+				"within_bounds_trigger_upper": "3",
+			},
+			expectedAction: &sdk.ScalingAction{
+				Direction: sdk.ScaleDirectionNone,
+			},
+		},
+		{
+			name:    "per bound trigger defaults to within_bounds_trigger",
+			count:   1,
+			metrics: []float64{30, 30, 0, 0, 0},
+			config: map[string]string{
+				"lower_bound":                 "5",
+				"upper_bound":                 "20",
+				"delta":                       "1",
+				"within_bounds_trigger":       "3",
+				"within_bounds_trigger_lower": "2",
+			},
+			expectedAction: &sdk.ScalingAction{
+				Count:     2,
+				Reason:    "scaling up because metric is within bounds",
+				Direction: sdk.ScaleDirectionUp,
+			},
+		},
+		{
+			name:    "grace samples ignore most recent data points",
+			count:   1,
+			metrics: []float64{10, 10, 10, 0, 0},
+			config: map[string]string{
+				"lower_bound":           "5",
+				"delta":                 "1",
+				"within_bounds_trigger": "3",
+				"grace_samples":         "2",
+			},
+			expectedAction: &sdk.ScalingAction{
+				Count:     2,
+				Reason:    "scaling up because metric is within bounds",
+				Direction: sdk.ScaleDirectionUp,
+			},
+		},
+		{
+			name:    "grace samples reduce data points within bounds",
+			count:   1,
+			metrics: []float64{0, 0, 10, 10, 10},
+			config: map[string]string{
+				"lower_bound":           "5",
+				"delta":                 "1",
+				"within_bounds_trigger": "3",
+				"grace_samples":         "1",
+			},
+			expectedAction: &sdk.ScalingAction{
+				Direction: sdk.ScaleDirectionNone,
+			},
+		},
+		{
+			name:    "grace samples cover all data points",
+			count:   1,
+			metrics: []float64{10, 10},
+			config: map[string]string{
+				"lower_bound":           "5",
+				"delta":                 "1",
+				"within_bounds_trigger": "0",
+				"grace_samples":         "2",
+			},
+			expectedAction: &sdk.ScalingAction{
+				Direction: sdk.ScaleDirectionNone,
+			},
+		},
+		{
+			name:    "invalid per bound trigger",
+			count:   1,
+			metrics: []float64{0},
+			config: map[string]string{
+				"lower_bound":                 "5",
+				"within_bounds_trigger_upper": "-1",
+			},
+			expectedErr: `"within_bounds_trigger_upper" value -1 is negative`,
+		},
+		{
+			name:    "invalid grace samples",
+			count:   1,
+			metrics: []float64{0},
+			config: map[string]string{
+				"lower_bound":   "5",
+				"grace_samples": "some",
+			},
+			expectedErr: `invalid value for "grace_samples"`,
+		},
 	}
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
 			p := NewThresholdPlugin(hclog.NewNullLogger())
 
+			// Metrics are ordered from oldest to most recent.
 			var metrics sdk.TimestampedMetrics
-			for _, m := range tc.metrics {
-				metrics = append(metrics, sdk.TimestampedMetric{Value: m})
+			for i, m := range tc.metrics {
+				metrics = append(metrics, sdk.TimestampedMetric{
+					Timestamp: time.Unix(int64(i), 0),
+					Value:     m,
+				})
 			}
 
 			eval := &sdk.ScalingCheckEvaluation{
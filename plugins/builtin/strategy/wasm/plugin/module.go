@@ -0,0 +1,172 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/nomad-autoscaler/sdk"
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/api"
+)
+
+// The functions a module must export to be used as a strategy.
+//
+// Data is exchanged as JSON documents written to the module memory. The
+// plugin calls allocate with the size of the input document and writes it at
+// the returned offset. It then calls scale with the offset and size of the
+// input, which returns the offset of the output document in the upper 32 bits
+// and its size in the lower 32 bits.
+const (
+	exportMemory   = "memory"
+	exportAllocate = "allocate"
+	exportScale    = "scale"
+)
+
+// moduleInput is the document passed to the module scale function.
+type moduleInput struct {
+	CheckName string            `json:"check_name"`
+	Count     int64             `json:"count"`
+	Config    map[string]string `json:"config"`
+	Metrics   []moduleMetric    `json:"metrics"`
+}
+
+// moduleMetric is a single data point of the check metrics.
+type moduleMetric struct {
+	Timestamp time.Time `json:"timestamp"`
+	Value     float64   `json:"value"`
+}
+
+// moduleOutput is the document returned by the module scale function. The
+// scaling direction is derived from the returned count, so modules return the
+// current count when no scaling is required. Modules can optionally explain
+// how the count was calculated.
+type moduleOutput struct {
+	Count       int64                         `json:"count"`
+	Reason      string                        `json:"reason"`
+	Error       string                        `json:"error"`
+	Explanation *sdk.ScalingActionExplanation `json:"explanation"`
+}
+
+// newModuleInput returns the encoded module input for the check evaluation.
+func newModuleInput(eval *sdk.ScalingCheckEvaluation, count int64) ([]byte, error) {
+	in := moduleInput{
+		CheckName: eval.Check.Name,
+		Count:     count,
+		Config:    eval.Check.Strategy.Config,
+		Metrics:   make([]moduleMetric, 0, len(eval.Metrics)),
+	}
+
+	for _, m := range eval.Metrics {
+		in.Metrics = append(in.Metrics, moduleMetric{Timestamp: m.Timestamp, Value: m.Value})
+	}
+
+	out, err := json.Marshal(in)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode module input: %v", err)
+	}
+	return out, nil
+}
+
+// validateExports checks the compiled module exports the functions used by
+// the plugin with the expected signatures.
+func validateExports(m wazero.CompiledModule) error {
+	if _, ok := m.ExportedMemories()[exportMemory]; !ok {
+		return fmt.Errorf("missing exported memory %q", exportMemory)
+	}
+
+	expected := map[string]struct {
+		params  []api.ValueType
+		results []api.ValueType
+	}{
+		exportAllocate: {
+			params:  []api.ValueType{api.ValueTypeI32},
+			results: []api.ValueType{api.ValueTypeI32},
+		},
+		exportScale: {
+			params:  []api.ValueType{api.ValueTypeI32, api.ValueTypeI32},
+			results: []api.ValueType{api.ValueTypeI64},
+		},
+	}
+
+	funcs := m.ExportedFunctions()
+	for name, sig := range expected {
+		f, ok := funcs[name]
+		if !ok {
+			return fmt.Errorf("missing exported function %q", name)
+		}
+		if !equalTypes(f.ParamTypes(), sig.params) || !equalTypes(f.ResultTypes(), sig.results) {
+			return fmt.Errorf("exported function %q has an invalid signature", name)
+		}
+	}
+	return nil
+}
+
+// runModule instantiates the compiled module and calls its scale function
+// with the input. A new instance is used for each run, so runs are isolated
+// from each other.
+func (s *StrategyPlugin) runModule(
+	ctx context.Context, logger hclog.Logger, compiled wazero.CompiledModule, input []byte) (*moduleOutput, error) {
+
+	// Output written by the module to stderr is logged to help module
+	// authors debug their strategies.
+	stderr := logger.StandardWriter(&hclog.StandardLoggerOptions{ForceLevel: hclog.Debug})
+
+	// An empty name allows the same module to be instantiated concurrently.
+	// Reactor modules built for WASI are initialized by _initialize, which is
+	// skipped when not exported.
+	cfg := wazero.NewModuleConfig().
+		WithName("").
+		WithStderr(stderr).
+		WithStartFunctions("_initialize")
+
+	mod, err := s.runtime.InstantiateModule(ctx, compiled, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to instantiate module: %v", err)
+	}
+	defer mod.Close(context.Background())
+
+	res, err := mod.ExportedFunction(exportAllocate).Call(ctx, uint64(len(input)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to allocate input: %v", err)
+	}
+	inPtr := uint32(res[0])
+
+	if !mod.Memory().Write(inPtr, input) {
+		return nil, fmt.Errorf("failed to write input: offset %d out of range", inPtr)
+	}
+
+	res, err = mod.ExportedFunction(exportScale).Call(ctx, uint64(inPtr), uint64(len(input)))
+	if err != nil {
+		return nil, err
+	}
+	outPtr, outLen := uint32(res[0]>>32), uint32(res[0])
+
+	raw, ok := mod.Memory().Read(outPtr, outLen)
+	if !ok {
+		return nil, fmt.Errorf("failed to read output: offset %d and size %d out of range", outPtr, outLen)
+	}
+
+	var out moduleOutput
+	if err := json.Unmarshal(raw, &out); err != nil {
+		return nil, fmt.Errorf("failed to decode output: %v", err)
+	}
+	return &out, nil
+}
+
+func equalTypes(a, b []api.ValueType) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,256 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package plugin
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/nomad-autoscaler/plugins"
+	"github.com/hashicorp/nomad-autoscaler/plugins/base"
+	"github.com/hashicorp/nomad-autoscaler/plugins/strategy"
+	"github.com/hashicorp/nomad-autoscaler/sdk"
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/imports/wasi_snapshot_preview1"
+)
+
+const (
+	// pluginName is the unique name of the this plugin amongst strategy
+	// plugins.
+	pluginName = "wasm"
+
+	// These are the keys read from the RunRequest.Config map.
+	runConfigKeyModule  = "module"
+	runConfigKeyTimeout = "timeout"
+
+	// defaultTimeout is the default value for the timeout check run config.
+	defaultTimeout = time.Second
+)
+
+var (
+	PluginID = plugins.PluginID{
+		Name:       pluginName,
+		PluginType: sdk.PluginTypeStrategy,
+	}
+
+	PluginConfig = &plugins.InternalPluginConfig{
+		Factory: func(l hclog.Logger) interface{} { return NewWASMPlugin(l) },
+	}
+
+	pluginInfo = &base.PluginInfo{
+		Name:       pluginName,
+		PluginType: sdk.PluginTypeStrategy,
+	}
+)
+
+// Assert that StrategyPlugin meets the strategy.Strategy interface.
+var _ strategy.Strategy = (*StrategyPlugin)(nil)
+
+// StrategyPlugin is the WASM implementation of the strategy.Strategy
+// interface. The scaling logic is loaded from the WebAssembly module
+// configured in each check, so custom strategies can be written in any
+// language that compiles to WASM without building a plugin binary for each
+// architecture.
+type StrategyPlugin struct {
+	logger hclog.Logger
+
+	runtime wazero.Runtime
+
+	// modules caches the compiled modules, keyed by their path.
+	modules     map[string]*compiledModule
+	modulesLock sync.Mutex
+}
+
+// compiledModule is a compiled WASM module along with the file information
+// used to detect when the module must be compiled again.
+type compiledModule struct {
+	module  wazero.CompiledModule
+	modTime time.Time
+	size    int64
+}
+
+// wasmPluginRunConfig are the parsed values for a WASM plugin run.
+type wasmPluginRunConfig struct {
+	module  string
+	timeout time.Duration
+}
+
+// NewWASMPlugin returns the WASM implementation of the strategy.Strategy
+// interface.
+func NewWASMPlugin(log hclog.Logger) strategy.Strategy {
+	ctx := context.Background()
+
+	// Closing the module on context cancellation allows the check timeout to
+	// interrupt modules that never return.
+	r := wazero.NewRuntimeWithConfig(ctx, wazero.NewRuntimeConfig().WithCloseOnContextDone(true))
+
+	// Modules compiled for WASI, such as the ones built by TinyGo, import
+	// the system interface functions.
+	wasi_snapshot_preview1.MustInstantiate(ctx, r)
+
+	return &StrategyPlugin{
+		logger:  log,
+		runtime: r,
+		modules: make(map[string]*compiledModule),
+	}
+}
+
+// SetConfig satisfies the SetConfig function on the base.Base interface.
+func (s *StrategyPlugin) SetConfig(_ map[string]string) error {
+	return nil
+}
+
+// PluginInfo satisfies the PluginInfo function on the base.Base interface.
+func (s *StrategyPlugin) PluginInfo() (*base.PluginInfo, error) {
+	return pluginInfo, nil
+}
+
+// Run satisfies the Run function on the strategy.Strategy interface.
+func (s *StrategyPlugin) Run(eval *sdk.ScalingCheckEvaluation, count int64) (*sdk.ScalingCheckEvaluation, error) {
+	if len(eval.Metrics) == 0 {
+		s.logger.Trace("no metrics available")
+		return nil, nil
+	}
+
+	// Parse check config.
+	config, err := parseConfig(eval.Check.Strategy.Config)
+	if err != nil {
+		return nil, err
+	}
+
+	logger := s.logger.With("check_name", eval.Check.Name, "current_count", count, "module", config.module)
+
+	compiled, err := s.compile(config.module)
+	if err != nil {
+		return nil, err
+	}
+
+	input, err := newModuleInput(eval, count)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), config.timeout)
+	defer cancel()
+
+	output, err := s.runModule(ctx, logger, compiled, input)
+	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return nil, fmt.Errorf("module %s did not complete within %s", config.module, config.timeout)
+		}
+		return nil, fmt.Errorf("failed to run module %s: %v", config.module, err)
+	}
+
+	if output.Error != "" {
+		return nil, fmt.Errorf("module %s returned an error: %s", config.module, output.Error)
+	}
+	if output.Count < 0 {
+		return nil, fmt.Errorf("module %s returned a negative count %d", config.module, output.Count)
+	}
+
+	// Identify the direction of scaling, and exit early if none.
+	eval.Action.Direction = calculateDirection(count, output.Count)
+	if eval.Action.Direction == sdk.ScaleDirectionNone {
+		return eval, nil
+	}
+
+	logger.Trace("calculated scaling strategy results",
+		"new_count", output.Count, "direction", eval.Action.Direction)
+
+	eval.Action.Count = output.Count
+	eval.Action.Reason = output.Reason
+	if eval.Action.Reason == "" {
+		eval.Action.Reason = fmt.Sprintf("scaling %s because module %s returned %d",
+			eval.Action.Direction, config.module, output.Count)
+	}
+	if output.Explanation != nil {
+		eval.Action.SetExplanation(*output.Explanation)
+	}
+
+	return eval, nil
+}
+
+// compile returns the compiled module for the path. Modules are compiled
+// once and only compiled again when the file changes.
+func (s *StrategyPlugin) compile(path string) (wazero.CompiledModule, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read module: %v", err)
+	}
+
+	s.modulesLock.Lock()
+	defer s.modulesLock.Unlock()
+
+	if cached, ok := s.modules[path]; ok {
+		if cached.modTime.Equal(info.ModTime()) && cached.size == info.Size() {
+			return cached.module, nil
+		}
+
+		// Modules still running hold a reference to the compiled module, so
+		// closing it only releases the cache entry.
+		_ = cached.module.Close(context.Background())
+		delete(s.modules, path)
+	}
+
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read module: %v", err)
+	}
+
+	compiled, err := s.runtime.CompileModule(context.Background(), src)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile module %s: %v", path, err)
+	}
+
+	if err := validateExports(compiled); err != nil {
+		_ = compiled.Close(context.Background())
+		return nil, fmt.Errorf("invalid module %s: %v", path, err)
+	}
+
+	s.logger.Debug("compiled module", "module", path)
+	s.modules[path] = &compiledModule{module: compiled, modTime: info.ModTime(), size: info.Size()}
+	return compiled, nil
+}
+
+// parseConfig parses and validates the policy check config.
+func parseConfig(config map[string]string) (*wasmPluginRunConfig, error) {
+	c := &wasmPluginRunConfig{
+		module:  config[runConfigKeyModule],
+		timeout: defaultTimeout,
+	}
+
+	if c.module == "" {
+		return nil, fmt.Errorf("missing required field %q", runConfigKeyModule)
+	}
+
+	if timeoutStr := config[runConfigKeyTimeout]; timeoutStr != "" {
+		t, err := time.ParseDuration(timeoutStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value for %q: %v", runConfigKeyTimeout, err)
+		}
+		if t <= 0 {
+			return nil, fmt.Errorf("invalid value for %q: must be greater than zero", runConfigKeyTimeout)
+		}
+		c.timeout = t
+	}
+
+	return c, nil
+}
+
+// calculateDirection is used to calculate the direction of scaling that should
+// occur, if any at all.
+func calculateDirection(count, newCount int64) sdk.ScaleDirection {
+	switch {
+	case newCount > count:
+		return sdk.ScaleDirectionUp
+	case newCount < count:
+		return sdk.ScaleDirectionDown
+	default:
+		return sdk.ScaleDirectionNone
+	}
+}
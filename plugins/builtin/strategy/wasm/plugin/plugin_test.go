@@ -0,0 +1,190 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package plugin
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	hclog "github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/nomad-autoscaler/plugins/base"
+	"github.com/hashicorp/nomad-autoscaler/sdk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStrategyPlugin_PluginInfo(t *testing.T) {
+	s := &StrategyPlugin{}
+	expectedOutput := &base.PluginInfo{Name: "wasm", PluginType: "strategy"}
+	actualOutput, err := s.PluginInfo()
+	assert.Nil(t, err)
+	assert.Equal(t, expectedOutput, actualOutput)
+}
+
+func TestStrategyPlugin_Run(t *testing.T) {
+	metrics := sdk.TimestampedMetrics{{Value: 10}, {Value: 20}}
+
+	testCases := []struct {
+		name              string
+		config            map[string]string
+		metrics           sdk.TimestampedMetrics
+		count             int64
+		expectedNil       bool
+		expectedDirection sdk.ScaleDirection
+		expectedCount     int64
+		expectedReason    string
+		expectedErr       string
+	}{
+		{
+			name:        "no metrics",
+			config:      map[string]string{"module": "test-fixtures/scale-to-five.wasm"},
+			metrics:     nil,
+			count:       1,
+			expectedNil: true,
+		},
+		{
+			name:              "scale up",
+			config:            map[string]string{"module": "test-fixtures/scale-to-five.wasm"},
+			metrics:           metrics,
+			count:             1,
+			expectedDirection: sdk.ScaleDirectionUp,
+			expectedCount:     5,
+			expectedReason:    "scaling to five",
+		},
+		{
+			name:              "scale down",
+			config:            map[string]string{"module": "test-fixtures/scale-to-five.wasm"},
+			metrics:           metrics,
+			count:             10,
+			expectedDirection: sdk.ScaleDirectionDown,
+			expectedCount:     5,
+			expectedReason:    "scaling to five",
+		},
+		{
+			name:              "no change",
+			config:            map[string]string{"module": "test-fixtures/scale-to-five.wasm"},
+			metrics:           metrics,
+			count:             5,
+			expectedDirection: sdk.ScaleDirectionNone,
+		},
+		{
+			name:        "module error",
+			config:      map[string]string{"module": "test-fixtures/error.wasm"},
+			metrics:     metrics,
+			expectedErr: "module test-fixtures/error.wasm returned an error: not enough data",
+		},
+		{
+			name:        "module timeout",
+			config:      map[string]string{"module": "test-fixtures/loop.wasm", "timeout": "100ms"},
+			metrics:     metrics,
+			expectedErr: "module test-fixtures/loop.wasm did not complete within 100ms",
+		},
+		{
+			name:        "missing module",
+			config:      map[string]string{"module": "test-fixtures/missing.wasm"},
+			metrics:     metrics,
+			expectedErr: "failed to read module: stat test-fixtures/missing.wasm: no such file or directory",
+		},
+	}
+
+	s := NewWASMPlugin(hclog.NewNullLogger())
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			eval := &sdk.ScalingCheckEvaluation{
+				Check: &sdk.ScalingPolicyCheck{
+					Strategy: &sdk.ScalingPolicyStrategy{Config: tc.config},
+				},
+				Metrics: tc.metrics,
+				Action:  &sdk.ScalingAction{},
+			}
+
+			got, err := s.Run(eval, tc.count)
+			if tc.expectedErr != "" {
+				assert.EqualError(t, err, tc.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+
+			if tc.expectedNil {
+				assert.Nil(t, got)
+				return
+			}
+
+			assert.Equal(t, tc.expectedDirection, got.Action.Direction)
+			if tc.expectedDirection != sdk.ScaleDirectionNone {
+				assert.Equal(t, tc.expectedCount, got.Action.Count)
+				assert.Equal(t, tc.expectedReason, got.Action.Reason)
+			}
+		})
+	}
+}
+
+func Test_parseConfig(t *testing.T) {
+	testCases := []struct {
+		name        string
+		config      map[string]string
+		expected    *wasmPluginRunConfig
+		expectedErr string
+	}{
+		{
+			name:     "defaults",
+			config:   map[string]string{"module": "strategy.wasm"},
+			expected: &wasmPluginRunConfig{module: "strategy.wasm", timeout: time.Second},
+		},
+		{
+			name:     "timeout",
+			config:   map[string]string{"module": "strategy.wasm", "timeout": "5s"},
+			expected: &wasmPluginRunConfig{module: "strategy.wasm", timeout: 5 * time.Second},
+		},
+		{
+			name:        "missing module",
+			config:      map[string]string{},
+			expectedErr: `missing required field "module"`,
+		},
+		{
+			name:        "invalid timeout",
+			config:      map[string]string{"module": "strategy.wasm", "timeout": "-1s"},
+			expectedErr: `invalid value for "timeout": must be greater than zero`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual, err := parseConfig(tc.config)
+			if tc.expectedErr != "" {
+				assert.EqualError(t, err, tc.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+}
+
+func Test_newModuleInput(t *testing.T) {
+	ts := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	eval := &sdk.ScalingCheckEvaluation{
+		Check: &sdk.ScalingPolicyCheck{
+			Name:     "cpu",
+			Strategy: &sdk.ScalingPolicyStrategy{Config: map[string]string{"target": "70"}},
+		},
+		Metrics: sdk.TimestampedMetrics{{Timestamp: ts, Value: 42}},
+	}
+
+	raw, err := newModuleInput(eval, 3)
+	require.NoError(t, err)
+
+	var actual map[string]interface{}
+	require.NoError(t, json.Unmarshal(raw, &actual))
+	assert.Equal(t, map[string]interface{}{
+		"check_name": "cpu",
+		"count":      float64(3),
+		"config":     map[string]interface{}{"target": "70"},
+		"metrics": []interface{}{
+			map[string]interface{}{"timestamp": "2024-01-01T00:00:00Z", "value": float64(42)},
+		},
+	}, actual)
+}
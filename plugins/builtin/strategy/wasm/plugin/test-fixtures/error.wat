@@ -0,0 +1,18 @@
+;; Copyright (c) HashiCorp, Inc.
+;; SPDX-License-Identifier: MPL-2.0
+
+;; error is a strategy module which always returns an error.
+;; Build with `make wasm-fixtures` from the repository root.
+(module
+  (memory (export "memory") 1)
+
+  ;; The input is written at offset 1024. It is ignored by this module.
+  (func (export "allocate") (param $size i32) (result i32)
+    (i32.const 1024))
+
+  ;; The result packs the offset of the output in the upper 32 bits and its
+  ;; length in the lower 32 bits: (2048 << 32) | 27.
+  (func (export "scale") (param $ptr i32) (param $len i32) (result i64)
+    (i64.const 8796093022235))
+
+  (data (i32.const 2048) "{\"error\":\"not enough data\"}"))
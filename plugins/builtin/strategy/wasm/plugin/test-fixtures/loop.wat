@@ -0,0 +1,16 @@
+;; Copyright (c) HashiCorp, Inc.
+;; SPDX-License-Identifier: MPL-2.0
+
+;; loop is a strategy module which never returns, used to test timeouts.
+;; Build with `make wasm-fixtures` from the repository root.
+(module
+  (memory (export "memory") 1)
+
+  ;; The input is written at offset 1024. It is ignored by this module.
+  (func (export "allocate") (param $size i32) (result i32)
+    (i32.const 1024))
+
+  (func (export "scale") (param $ptr i32) (param $len i32) (result i64)
+    (loop $forever
+      (br $forever))
+    (i64.const 0)))
@@ -123,12 +123,24 @@ func (t *TargetPlugin) scaleIn(ctx context.Context, asg *types.AutoScalingGroup,
 		scaleInProtection = b
 	}
 
+	// Check if policy overrides the plugin configuration for
+	// scale_in_detach.
+	scaleInDetach := t.scaleInDetachEnabled
+	if str, ok := config[configKeyScaleInDetach]; ok {
+		b, err := strconv.ParseBool(str)
+		if err != nil {
+			return fmt.Errorf("failed to parse %s value from policy: %w", configKeyScaleInDetach, err)
+		}
+		scaleInDetach = b
+	}
+
 	// Create a logger for this action to pre-populate useful information we
 	// would like on all log lines.
 	log := t.logger.With(
 		"action", "scale_in",
 		"asg_name", *asg.AutoScalingGroupName,
 		"scale_in_protection", scaleInProtection,
+		"scale_in_detach", scaleInDetach,
 	)
 
 	// Find instance IDs in the target ASG and perform pre-scale tasks.
@@ -165,8 +177,16 @@ func (t *TargetPlugin) scaleIn(ctx context.Context, asg *types.AutoScalingGroup,
 	eWriter := newEventWriter(t.logger, t.asg, selectedRemoteIDs, *asg.AutoScalingGroupName)
 	eWriter.write(ctx, scalingEventDrain)
 
-	// Run the termination and log the results.
-	result := t.terminateInstancesInASG(ctx, ids)
+	// Run the termination, or the detachment when the instances are
+	// terminated by an external system, and log the results.
+	removeEvent, remove := scalingEventTerminate, t.terminateInstance
+	if scaleInDetach {
+		removeEvent = scalingEventDetach
+		remove = func(ctx context.Context, id string) (*string, error) {
+			return t.detachInstance(ctx, *asg.AutoScalingGroupName, id)
+		}
+	}
+	result := t.removeInstancesFromASG(ctx, ids, remove)
 	result.logResults(log)
 
 	// Capture any post-termination task errors.
@@ -191,7 +211,7 @@ func (t *TargetPlugin) scaleIn(ctx context.Context, asg *types.AutoScalingGroup,
 		} else {
 			t.logger.Debug("confirmed AWS ASG activities completed")
 		}
-		eWriter.write(ctx, scalingEventTerminate)
+		eWriter.write(ctx, removeEvent)
 
 		// Run any post scale in tasks that are desired.
 		successTaskErr = t.clusterUtils.RunPostScaleInTasks(ctx, config, result.successfulIDs())
@@ -214,14 +234,16 @@ func (t *TargetPlugin) scaleIn(ctx context.Context, asg *types.AutoScalingGroup,
 	return result.errorOrNil()
 }
 
-// terminateInstancesInASG handles terminating all instances passed and returns
+// removeInstancesFromASG handles removing all instances passed using the
+// remove function, which terminates or detaches a single instance, and returns
 // an object detailing the complete status of the performed action.
-func (t *TargetPlugin) terminateInstancesInASG(ctx context.Context, ids []scaleutils.NodeResourceID) instanceTerminationResult {
+func (t *TargetPlugin) removeInstancesFromASG(ctx context.Context, ids []scaleutils.NodeResourceID,
+	remove func(context.Context, string) (*string, error)) instanceTerminationResult {
 
 	var status instanceTerminationResult
 
 	for _, id := range ids {
-		activityID, err := t.terminateInstance(ctx, id.RemoteResourceID)
+		activityID, err := remove(ctx, id.RemoteResourceID)
 		if err != nil {
 			status.appendFailure(instanceFailure{instance: id, err: err})
 			continue
@@ -260,6 +282,31 @@ func (t *TargetPlugin) terminateInstance(ctx context.Context, id string) (*strin
 	return resp.Activity.ActivityId, nil
 }
 
+// detachInstance detaches a single instance from an AWS AutoScaling Group,
+// decrementing its desired capacity, without terminating the instance. It
+// returns any error from the API, along with the activity ID from the scaling
+// event.
+func (t *TargetPlugin) detachInstance(ctx context.Context, asgName, id string) (*string, error) {
+
+	input := autoscaling.DetachInstancesInput{
+		AutoScalingGroupName:           aws.String(asgName),
+		InstanceIds:                    []string{id},
+		ShouldDecrementDesiredCapacity: aws.Bool(true),
+	}
+
+	resp, err := t.asg.DetachInstances(ctx, &input)
+	if err != nil {
+		return nil, err
+	}
+
+	// A single instance is detached per request, so a single activity is
+	// expected in the response.
+	if len(resp.Activities) != 1 {
+		return nil, fmt.Errorf("expected 1 activity, got %v", len(resp.Activities))
+	}
+	return resp.Activities[0].ActivityId, nil
+}
+
 func (t *TargetPlugin) describeASG(ctx context.Context, asgName string) (*types.AutoScalingGroup, error) {
 
 	input := autoscaling.DescribeAutoScalingGroupsInput{AutoScalingGroupNames: []string{asgName}}
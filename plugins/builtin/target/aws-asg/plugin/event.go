@@ -21,6 +21,7 @@ type scalingEvent string
 const (
 	scalingEventDrain     scalingEvent = "drain"
 	scalingEventTerminate scalingEvent = "terminate"
+	scalingEventDetach    scalingEvent = "detach"
 )
 
 const (
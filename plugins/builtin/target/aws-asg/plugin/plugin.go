@@ -31,9 +31,11 @@ const (
 	configKeySecretKey          = "aws_secret_access_key"
 	configKeySessionToken       = "aws_session_token"
 	configKeyASGName            = "aws_asg_name"
+	configKeyASGWeights         = "aws_asg_weights"
 	configKeyCredentialProvider = "aws_credential_provider"
 	configKeyRetryAttempts      = "retry_attempts"
 	configKeyScaleInProtection  = "scale_in_protection"
+	configKeyScaleInDetach      = "scale_in_detach"
 
 	// EXPERIMENTAL
 	// The configKeys below are considered experimental and should not be used.
@@ -47,6 +49,10 @@ const (
 	// credentialProvider are the valid options for the aws_credential_provider
 	// configuration key.
 	credentialProviderEC2Role = "ec2_role"
+
+	// metaValueInProgressActivity is the sdk.TargetStatusMetaKeyInProgress
+	// value used when the last scaling activity of an ASG is not complete.
+	metaValueInProgressActivity = "scaling_activity"
 )
 
 var (
@@ -60,8 +66,12 @@ var (
 	}
 )
 
-// Assert that TargetPlugin meets the target.Target interface.
-var _ target.Target = (*TargetPlugin)(nil)
+// Assert that TargetPlugin meets the target.Target and
+// target.CapacityReporter interfaces.
+var (
+	_ target.Target           = (*TargetPlugin)(nil)
+	_ target.CapacityReporter = (*TargetPlugin)(nil)
+)
 
 // TargetPlugin is the AWS ASG implementation of the target.Target interface.
 type TargetPlugin struct {
@@ -77,6 +87,10 @@ type TargetPlugin struct {
 	// should be applied.
 	scaleInProtectionEnabled bool
 
+	// scaleInDetachEnabled is true when instances should be detached from
+	// the ASG on scale in, leaving their termination to an external system.
+	scaleInDetachEnabled bool
+
 	// clusterUtils provides general cluster scaling utilities for querying the
 	// state of nodes pools and performing scaling tasks.
 	clusterUtils *scaleutils.ClusterScaleUtils
@@ -120,6 +134,12 @@ func (t *TargetPlugin) SetConfig(config map[string]string) error {
 	}
 	t.scaleInProtectionEnabled = scaleInProtection
 
+	scaleInDetach, err := strconv.ParseBool(getConfigValue(config, configKeyScaleInDetach, "false"))
+	if err != nil {
+		return err
+	}
+	t.scaleInDetachEnabled = scaleInDetach
+
 	return nil
 }
 
@@ -136,24 +156,83 @@ func (t *TargetPlugin) Scale(action sdk.ScalingAction, config map[string]string)
 		return nil
 	}
 
-	// We cannot scale an ASG without knowing the ASG name.
-	asgName, ok := config[configKeyASGName]
-	if !ok {
-		return fmt.Errorf("required config param %s not found", configKeyASGName)
+	// We cannot scale without knowing the ASGs and the weights used to
+	// distribute the count between them.
+	weights, err := asgWeightsFromConfig(config)
+	if err != nil {
+		return err
 	}
 	ctx := context.Background()
 
-	// Describe the ASG. This serves to both validate the config value is
+	// Describe the ASGs. This serves to both validate the config value is
 	// correct and ensure the AWS client is configured correctly. The response
 	// can also be used when performing the scaling, meaning we only need to
 	// call it once.
-	curASG, err := t.describeASG(ctx, asgName)
-	if err != nil {
-		return fmt.Errorf("failed to describe AWS Autoscaling Group: %v", err)
+	asgs := make([]*types.AutoScalingGroup, len(weights))
+	for i, w := range weights {
+		curASG, err := t.describeASG(ctx, w.name)
+		if err != nil {
+			return fmt.Errorf("failed to describe AWS Autoscaling Group: %v", err)
+		}
+
+		refreshing, err := t.instanceRefreshActive(ctx, w.name)
+		if err != nil {
+			return err
+		}
+		if refreshing {
+			return nil
+		}
+		asgs[i] = curASG
 	}
 
-	// Autoscaling can interfere with a running instance refresh so we
-	// prevent any scaling action while a refresh is Pending or InProgress
+	// Distribute the desired count across the ASGs. The whole distribution
+	// is calculated on each scaling action, so ASGs which drifted from their
+	// share are reconciled along with the change in count.
+	counts := distributeCount(action.Count, weights)
+
+	// The AWS ASG target requires different details depending on which
+	// direction we want to scale. Therefore calculate the direction and the
+	// relevant number so we can correctly perform the AWS work. ASGs are
+	// scaled out before others are scaled in, so rebalancing between ASGs
+	// doesn't reduce the capacity of the cluster.
+	var scaled bool
+	for _, want := range []string{"out", "in"} {
+		for i, curASG := range asgs {
+			num, direction := t.calculateDirection(int64(*curASG.DesiredCapacity), counts[i])
+			if direction != want {
+				continue
+			}
+			scaled = true
+
+			switch direction {
+			case "in":
+				err = t.scaleIn(ctx, curASG, num, config)
+			case "out":
+				err = t.scaleOut(ctx, curASG, num)
+			}
+
+			// If we received an error while scaling, format this with an
+			// outer message so its nice for the operators and then return any
+			// error to the caller.
+			if err != nil {
+				return fmt.Errorf("failed to perform scaling action: %v", err)
+			}
+		}
+	}
+
+	if !scaled {
+		for i, curASG := range asgs {
+			t.logger.Info("scaling not required", "asg_name", weights[i].name,
+				"current_count", *curASG.DesiredCapacity, "strategy_count", counts[i])
+		}
+	}
+	return nil
+}
+
+// instanceRefreshActive returns whether the ASG has an InstanceRefresh which
+// is Pending or InProgress. Autoscaling can interfere with a running instance
+// refresh so we prevent any scaling action while one is active.
+func (t *TargetPlugin) instanceRefreshActive(ctx context.Context, asgName string) (bool, error) {
 	input := autoscaling.DescribeInstanceRefreshesInput{
 		AutoScalingGroupName: &asgName,
 		MaxRecords:           ptr.Of(int32(1)),
@@ -161,7 +240,7 @@ func (t *TargetPlugin) Scale(action sdk.ScalingAction, config map[string]string)
 
 	refreshes, err := t.asg.DescribeInstanceRefreshes(ctx, &input)
 	if err != nil {
-		return fmt.Errorf("failed to describe AWS InstanceRefresh: %v", err)
+		return false, fmt.Errorf("failed to describe AWS InstanceRefresh: %v", err)
 	}
 
 	for _, refresh := range refreshes.InstanceRefreshes {
@@ -173,32 +252,10 @@ func (t *TargetPlugin) Scale(action sdk.ScalingAction, config map[string]string)
 				"asg_name", asgName,
 				"refresh_id", refresh.InstanceRefreshId,
 				"refresh_status", refresh.Status)
-			return nil
+			return true, nil
 		}
 	}
-
-	// The AWS ASG target requires different details depending on which
-	// direction we want to scale. Therefore calculate the direction and the
-	// relevant number so we can correctly perform the AWS work.
-	num, direction := t.calculateDirection(int64(*curASG.DesiredCapacity), action.Count)
-
-	switch direction {
-	case "in":
-		err = t.scaleIn(ctx, curASG, num, config)
-	case "out":
-		err = t.scaleOut(ctx, curASG, num)
-	default:
-		t.logger.Info("scaling not required", "asg_name", asgName,
-			"current_count", *curASG.DesiredCapacity, "strategy_count", action.Count)
-		return nil
-	}
-
-	// If we received an error while scaling, format this with an outer message
-	// so its nice for the operators and then return any error to the caller.
-	if err != nil {
-		err = fmt.Errorf("failed to perform scaling action: %v", err)
-	}
-	return err
+	return false, nil
 }
 
 // Status satisfies the Status function on the target.Target interface.
@@ -215,50 +272,91 @@ func (t *TargetPlugin) Status(config map[string]string) (*sdk.TargetStatus, erro
 		return &sdk.TargetStatus{Ready: ready}, nil
 	}
 
-	// We cannot get the status of an ASG if we don't know its name.
-	asgName, ok := config[configKeyASGName]
-	if !ok {
-		return nil, fmt.Errorf("required config param %s not found", configKeyASGName)
-	}
-	ctx := context.Background()
-
-	asg, err := t.describeASG(ctx, asgName)
+	// We cannot get the status of the ASGs if we don't know their names.
+	weights, err := asgWeightsFromConfig(config)
 	if err != nil {
-		return nil, fmt.Errorf("failed to describe AWS Autoscaling Group: %v", err)
+		return nil, err
 	}
 
-	events, err := t.describeActivities(ctx, asgName, nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to describe AWS Autoscaling Group activities: %v", err)
+	ignoreEvents := false
+	if str, ok := config[xConfigKeyIgnoreASGEvents]; ok {
+		ignoreEvents, err = strconv.ParseBool(str)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse config %s: %v", xConfigKeyIgnoreASGEvents, err)
+		}
 	}
+	ctx := context.Background()
 
-	// Set our initial status. The asg.Status field is only set when the ASG is
-	// being deleted.
+	// The count of the target is the sum of the desired capacity of all the
+	// ASGs, which are only ready once all of them are.
 	resp := sdk.TargetStatus{
-		Ready: asg.Status == nil,
-		Count: int64(*asg.DesiredCapacity),
+		Ready: true,
 		Meta:  make(map[string]string),
 	}
 
-	// Return early if policy is configured to ignore ASG events.
-	if str, ok := config[xConfigKeyIgnoreASGEvents]; ok {
-		ignoreEvents, err := strconv.ParseBool(str)
+	var unhealthy int
+	for _, w := range weights {
+		asg, err := t.describeASG(ctx, w.name)
 		if err != nil {
-			return nil, fmt.Errorf("failed to parse config %s: %v", xConfigKeyIgnoreASGEvents, err)
+			return nil, fmt.Errorf("failed to describe AWS Autoscaling Group: %v", err)
+		}
+
+		// The asg.Status field is only set when the ASG is being deleted.
+		resp.Ready = resp.Ready && asg.Status == nil
+		resp.Count += int64(*asg.DesiredCapacity)
+		unhealthy += countUnhealthyInstances(asg)
+
+		// The version is only meaningful when targeting a single ASG.
+		if version := launchTemplateVersion(asg); version != "" && len(weights) == 1 {
+			resp.Meta[sdk.TargetStatusMetaKeyVersion] = version
 		}
+
+		// Skip the activities if policy is configured to ignore ASG events.
 		if ignoreEvents {
-			return &resp, nil
+			continue
+		}
+
+		events, err := t.describeActivities(ctx, w.name, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to describe AWS Autoscaling Group activities: %v", err)
+		}
+
+		// If we have previous activities then process the last.
+		if len(events) > 0 {
+			processLastActivity(events[0], &resp)
 		}
 	}
 
-	// If we have previous activities then process the last.
-	if len(events) > 0 {
-		processLastActivity(events[0], &resp)
+	if unhealthy > 0 {
+		resp.Meta[sdk.TargetStatusMetaKeyUnhealthy] = strconv.Itoa(unhealthy)
 	}
 
 	return &resp, nil
 }
 
+// Capacity satisfies the Capacity function on the target.CapacityReporter
+// interface. The max count of the target is the sum of the max size of all
+// the ASGs.
+func (t *TargetPlugin) Capacity(config map[string]string) (*sdk.TargetCapacity, error) {
+	weights, err := asgWeightsFromConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx := context.Background()
+	asgs := make([]*types.AutoScalingGroup, 0, len(weights))
+
+	for _, w := range weights {
+		asg, err := t.describeASG(ctx, w.name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to describe AWS Autoscaling Group: %v", err)
+		}
+		asgs = append(asgs, asg)
+	}
+
+	return asgsCapacity(asgs), nil
+}
+
 func (t *TargetPlugin) calculateDirection(asgDesired, strategyDesired int64) (int64, string) {
 
 	if strategyDesired < asgDesired {
@@ -279,13 +377,57 @@ func processLastActivity(activity types.Activity, status *sdk.TargetStatus) {
 	// set ready to false so the autoscaler will not perform any actions.
 	if activity.Progress == nil || *activity.Progress != 100 {
 		status.Ready = false
+		status.Meta[sdk.TargetStatusMetaKeyInProgress] = metaValueInProgressActivity
 	}
 
 	// EndTime isn't always populated, especially if the activity has not yet
-	// finished :).
+	// finished :). When the target spans multiple ASGs, keep the most recent
+	// event across all of them.
 	if activity.EndTime != nil {
-		status.Meta[sdk.TargetStatusMetaKeyLastEvent] = strconv.FormatInt(activity.EndTime.UnixNano(), 10)
+		last, _ := strconv.ParseInt(status.Meta[sdk.TargetStatusMetaKeyLastEvent], 10, 64)
+		if end := activity.EndTime.UnixNano(); end > last {
+			status.Meta[sdk.TargetStatusMetaKeyLastEvent] = strconv.FormatInt(end, 10)
+		}
+	}
+}
+
+// countUnhealthyInstances returns the number of instances of the ASG which
+// are not reported as healthy.
+func countUnhealthyInstances(asg *types.AutoScalingGroup) int {
+	var n int
+	for _, inst := range asg.Instances {
+		if inst.HealthStatus == nil || *inst.HealthStatus != "Healthy" {
+			n++
+		}
+	}
+	return n
+}
+
+// asgsCapacity returns the capacity of a target made of the ASGs, or nil if
+// the max size of any of them is unknown.
+func asgsCapacity(asgs []*types.AutoScalingGroup) *sdk.TargetCapacity {
+	var maxCount int64
+	for _, asg := range asgs {
+		if asg.MaxSize == nil {
+			return nil
+		}
+		maxCount += int64(*asg.MaxSize)
+	}
+	return &sdk.TargetCapacity{MaxCount: &maxCount}
+}
+
+// launchTemplateVersion returns the version of the launch template used by
+// the ASG, either directly or through its mixed instances policy. It returns
+// an empty string when the ASG doesn't use a launch template.
+func launchTemplateVersion(asg *types.AutoScalingGroup) string {
+	lt := asg.LaunchTemplate
+	if lt == nil && asg.MixedInstancesPolicy != nil && asg.MixedInstancesPolicy.LaunchTemplate != nil {
+		lt = asg.MixedInstancesPolicy.LaunchTemplate.LaunchTemplateSpecification
+	}
+	if lt == nil || lt.Version == nil {
+		return ""
 	}
+	return *lt.Version
 }
 
 func getConfigValue(config map[string]string, key string, defaultValue string) string {
@@ -77,7 +77,9 @@ func Test_processLastActivity(t *testing.T) {
 			expectedStatus: &sdk.TargetStatus{
 				Ready: false,
 				Count: 1,
-				Meta:  map[string]string{},
+				Meta: map[string]string{
+					"nomad_autoscaler.in_progress": "scaling_activity",
+				},
 			},
 			name: "latest activity still in progress",
 		},
@@ -100,6 +102,27 @@ func Test_processLastActivity(t *testing.T) {
 			},
 			name: "latest activity completed",
 		},
+		{
+			inputActivity: types.Activity{
+				Progress: ptr.Of(int32(100)),
+				EndTime:  &testTime,
+			},
+			inputStatus: &sdk.TargetStatus{
+				Ready: true,
+				Count: 1,
+				Meta: map[string]string{
+					"nomad_autoscaler.last_event": "1586765050000000000",
+				},
+			},
+			expectedStatus: &sdk.TargetStatus{
+				Ready: true,
+				Count: 1,
+				Meta: map[string]string{
+					"nomad_autoscaler.last_event": "1586765050000000000",
+				},
+			},
+			name: "more recent activity of another ASG kept",
+		},
 		{
 			inputActivity: types.Activity{},
 			inputStatus: &sdk.TargetStatus{
@@ -110,7 +133,9 @@ func Test_processLastActivity(t *testing.T) {
 			expectedStatus: &sdk.TargetStatus{
 				Ready: false,
 				Count: 1,
-				Meta:  map[string]string{},
+				Meta: map[string]string{
+					"nomad_autoscaler.in_progress": "scaling_activity",
+				},
 			},
 			name: "latest activity all nils",
 		},
@@ -123,3 +148,88 @@ func Test_processLastActivity(t *testing.T) {
 		})
 	}
 }
+
+func Test_launchTemplateVersion(t *testing.T) {
+	testCases := []struct {
+		inputASG       *types.AutoScalingGroup
+		expectedOutput string
+		name           string
+	}{
+		{
+			inputASG:       &types.AutoScalingGroup{},
+			expectedOutput: "",
+			name:           "no launch template",
+		},
+		{
+			inputASG: &types.AutoScalingGroup{
+				LaunchTemplate: &types.LaunchTemplateSpecification{Version: ptr.Of("7")},
+			},
+			expectedOutput: "7",
+			name:           "launch template",
+		},
+		{
+			inputASG: &types.AutoScalingGroup{
+				MixedInstancesPolicy: &types.MixedInstancesPolicy{
+					LaunchTemplate: &types.LaunchTemplate{
+						LaunchTemplateSpecification: &types.LaunchTemplateSpecification{Version: ptr.Of("$Latest")},
+					},
+				},
+			},
+			expectedOutput: "$Latest",
+			name:           "mixed instances policy launch template",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expectedOutput, launchTemplateVersion(tc.inputASG), tc.name)
+		})
+	}
+}
+
+func Test_countUnhealthyInstances(t *testing.T) {
+	asg := &types.AutoScalingGroup{
+		Instances: []types.Instance{
+			{HealthStatus: ptr.Of("Healthy")},
+			{HealthStatus: ptr.Of("Unhealthy")},
+			{},
+		},
+	}
+	assert.Equal(t, 2, countUnhealthyInstances(asg))
+}
+
+func Test_asgsCapacity(t *testing.T) {
+	testCases := []struct {
+		inputASGs      []*types.AutoScalingGroup
+		expectedOutput *sdk.TargetCapacity
+		name           string
+	}{
+		{
+			inputASGs:      []*types.AutoScalingGroup{{MaxSize: ptr.Of(int32(10))}},
+			expectedOutput: &sdk.TargetCapacity{MaxCount: ptr.Of(int64(10))},
+			name:           "single ASG",
+		},
+		{
+			inputASGs: []*types.AutoScalingGroup{
+				{MaxSize: ptr.Of(int32(10))},
+				{MaxSize: ptr.Of(int32(5))},
+			},
+			expectedOutput: &sdk.TargetCapacity{MaxCount: ptr.Of(int64(15))},
+			name:           "multiple ASGs",
+		},
+		{
+			inputASGs: []*types.AutoScalingGroup{
+				{MaxSize: ptr.Of(int32(10))},
+				{},
+			},
+			expectedOutput: nil,
+			name:           "unknown max size",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expectedOutput, asgsCapacity(tc.inputASGs), tc.name)
+		})
+	}
+}
@@ -0,0 +1,100 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package plugin
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// asgWeight is an AWS AutoScaling Group targeted by a policy along with its
+// share of the desired count.
+type asgWeight struct {
+	name   string
+	weight int64
+}
+
+// asgWeightsFromConfig returns the AutoScaling Groups targeted by the policy.
+// A policy either targets a single ASG using aws_asg_name, or distributes its
+// count across multiple ASGs using aws_asg_weights.
+func asgWeightsFromConfig(config map[string]string) ([]asgWeight, error) {
+	name, hasName := config[configKeyASGName]
+	weights, hasWeights := config[configKeyASGWeights]
+
+	switch {
+	case hasName && hasWeights:
+		return nil, fmt.Errorf("only one of config params %s and %s can be set", configKeyASGName, configKeyASGWeights)
+	case hasWeights:
+		return parseASGWeights(weights)
+	case hasName:
+		return []asgWeight{{name: name, weight: 1}}, nil
+	default:
+		return nil, fmt.Errorf("required config param %s not found", configKeyASGName)
+	}
+}
+
+// parseASGWeights parses the aws_asg_weights config value, which is a comma
+// separated list of ASG names and weights such as "on-demand=30,spot=70".
+func parseASGWeights(s string) ([]asgWeight, error) {
+	var weights []asgWeight
+	seen := make(map[string]struct{})
+
+	for _, entry := range strings.Split(s, ",") {
+		name, value, ok := strings.Cut(strings.TrimSpace(entry), "=")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid %s entry %q, must be in the form <asg name>=<weight>", configKeyASGWeights, entry)
+		}
+
+		weight, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+		if err != nil || weight <= 0 {
+			return nil, fmt.Errorf("invalid %s weight %q for %s, must be a positive integer", configKeyASGWeights, value, name)
+		}
+
+		if _, ok := seen[name]; ok {
+			return nil, fmt.Errorf("duplicate %s entry for %s", configKeyASGWeights, name)
+		}
+		seen[name] = struct{}{}
+
+		weights = append(weights, asgWeight{name: name, weight: weight})
+	}
+
+	return weights, nil
+}
+
+// distributeCount splits the total count across the ASGs in proportion to
+// their weights. Counts are rounded down and the remainder is handed out to
+// the ASGs with the largest fractional share, with ties going to the ASG
+// listed first, so the result is stable across evaluations.
+func distributeCount(total int64, weights []asgWeight) []int64 {
+	var sum int64
+	for _, w := range weights {
+		sum += w.weight
+	}
+
+	counts := make([]int64, len(weights))
+	order := make([]int, len(weights))
+	var assigned int64
+
+	for i, w := range weights {
+		counts[i] = total * w.weight / sum
+		assigned += counts[i]
+		order[i] = i
+	}
+
+	sort.SliceStable(order, func(a, b int) bool {
+		ra := total * weights[order[a]].weight % sum
+		rb := total * weights[order[b]].weight % sum
+		return ra > rb
+	})
+
+	for i := 0; assigned < total; i++ {
+		counts[order[i]]++
+		assigned++
+	}
+
+	return counts
+}
@@ -0,0 +1,134 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package plugin
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_asgWeightsFromConfig(t *testing.T) {
+	testCases := []struct {
+		inputConfig     map[string]string
+		expectedWeights []asgWeight
+		expectedError   string
+		name            string
+	}{
+		{
+			inputConfig:     map[string]string{"aws_asg_name": "nomad-clients"},
+			expectedWeights: []asgWeight{{name: "nomad-clients", weight: 1}},
+			name:            "single ASG",
+		},
+		{
+			inputConfig: map[string]string{"aws_asg_weights": "on-demand = 30, spot=70"},
+			expectedWeights: []asgWeight{
+				{name: "on-demand", weight: 30},
+				{name: "spot", weight: 70},
+			},
+			name: "weighted ASGs",
+		},
+		{
+			inputConfig: map[string]string{
+				"aws_asg_name":    "nomad-clients",
+				"aws_asg_weights": "on-demand=30,spot=70",
+			},
+			expectedError: "only one of config params aws_asg_name and aws_asg_weights can be set",
+			name:          "both set",
+		},
+		{
+			inputConfig:   map[string]string{},
+			expectedError: "required config param aws_asg_name not found",
+			name:          "none set",
+		},
+		{
+			inputConfig:   map[string]string{"aws_asg_weights": "on-demand"},
+			expectedError: `invalid aws_asg_weights entry "on-demand"`,
+			name:          "missing weight",
+		},
+		{
+			inputConfig:   map[string]string{"aws_asg_weights": "=30"},
+			expectedError: `invalid aws_asg_weights entry "=30"`,
+			name:          "missing name",
+		},
+		{
+			inputConfig:   map[string]string{"aws_asg_weights": "on-demand=30,spot=0"},
+			expectedError: `invalid aws_asg_weights weight "0" for spot, must be a positive integer`,
+			name:          "zero weight",
+		},
+		{
+			inputConfig:   map[string]string{"aws_asg_weights": "on-demand=thirty"},
+			expectedError: `invalid aws_asg_weights weight "thirty" for on-demand`,
+			name:          "non-numeric weight",
+		},
+		{
+			inputConfig:   map[string]string{"aws_asg_weights": "spot=30,spot=70"},
+			expectedError: "duplicate aws_asg_weights entry for spot",
+			name:          "duplicate ASG",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actualWeights, err := asgWeightsFromConfig(tc.inputConfig)
+			if tc.expectedError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedWeights, actualWeights)
+		})
+	}
+}
+
+func Test_distributeCount(t *testing.T) {
+	onDemandSpot := []asgWeight{{name: "on-demand", weight: 30}, {name: "spot", weight: 70}}
+	even := []asgWeight{{name: "a", weight: 1}, {name: "b", weight: 1}, {name: "c", weight: 1}}
+
+	testCases := []struct {
+		inputTotal     int64
+		inputWeights   []asgWeight
+		expectedCounts []int64
+		name           string
+	}{
+		{
+			inputTotal:     10,
+			inputWeights:   onDemandSpot,
+			expectedCounts: []int64{3, 7},
+			name:           "exact split",
+		},
+		{
+			inputTotal:     6,
+			inputWeights:   onDemandSpot,
+			expectedCounts: []int64{2, 4},
+			name:           "remainder to largest fraction",
+		},
+		{
+			inputTotal:     0,
+			inputWeights:   onDemandSpot,
+			expectedCounts: []int64{0, 0},
+			name:           "zero total",
+		},
+		{
+			inputTotal:     4,
+			inputWeights:   even,
+			expectedCounts: []int64{2, 1, 1},
+			name:           "ties to first listed",
+		},
+		{
+			inputTotal:     7,
+			inputWeights:   []asgWeight{{name: "nomad-clients", weight: 1}},
+			expectedCounts: []int64{7},
+			name:           "single ASG",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expectedCounts, distributeCount(tc.inputTotal, tc.inputWeights))
+		})
+	}
+}
@@ -8,12 +8,16 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2020-06-01/compute"
 	"github.com/Azure/go-autorest/autorest"
 	"github.com/Azure/go-autorest/autorest/azure/auth"
+	"github.com/hashicorp/nomad-autoscaler/sdk"
 	"github.com/hashicorp/nomad-autoscaler/sdk/helper/ptr"
+	"github.com/hashicorp/nomad-autoscaler/sdk/helper/scaleutils"
 	"github.com/hashicorp/nomad/api"
 )
 
@@ -96,11 +100,16 @@ func (t *TargetPlugin) scaleOut(ctx context.Context, resourceGroup string, vmSca
 }
 
 // scaleIn drain and delete Scale Set instances to match the Autoscaler has deemed required.
-func (t *TargetPlugin) scaleIn(ctx context.Context, resourceGroup string, vmScaleSet string, num int64, config map[string]string) error {
+func (t *TargetPlugin) scaleIn(ctx context.Context, vmss compute.VirtualMachineScaleSet, resourceGroup string, vmScaleSet string, num int64, config map[string]string) error {
 	// Create a logger for this action to pre-populate useful information we
 	// would like on all log lines.
 	log := t.logger.With("action", "scale_in", "resource_group", resourceGroup, "vmss_name", vmScaleSet)
 
+	opts, err := newScaleInOptions(vmss, config)
+	if err != nil {
+		return err
+	}
+
 	// Find instance IDs in the target VMSS and perform pre-scale tasks.
 	pager, err := t.vmssVMs.List(ctx, resourceGroup, vmScaleSet,
 		"startswith(instanceView/statuses/code, 'PowerState') eq true",
@@ -109,14 +118,18 @@ func (t *TargetPlugin) scaleIn(ctx context.Context, resourceGroup string, vmScal
 		return fmt.Errorf("failed to query VMSS instances: %v", err)
 	}
 
-	remoteIDs := []string{}
+	instances := []vmssInstance{}
 	for pager.NotDone() {
 		for _, vm := range pager.Values() {
 			for _, s := range *vm.VirtualMachineScaleSetVMProperties.InstanceView.Statuses {
 				if strings.HasPrefix(*s.Code, "PowerState/") {
 					if *s.Code == "PowerState/running" {
-						log.Debug("found healthy instance", "id", *vm.ID, "instance_id", *vm.InstanceID)
-						remoteIDs = append(remoteIDs, fmt.Sprintf("%s_%s", vmScaleSet, *vm.InstanceID))
+						zone := instanceZone(vm)
+						log.Debug("found healthy instance", "id", *vm.ID, "instance_id", *vm.InstanceID, "zone", zone)
+						instances = append(instances, vmssInstance{
+							remoteID: fmt.Sprintf("%s_%s", vmScaleSet, *vm.InstanceID),
+							zone:     zone,
+						})
 					} else {
 						log.Debug("skipping instance", "id", *vm.ID, "instance_id", *vm.InstanceID, "code", *s.Code)
 					}
@@ -131,7 +144,7 @@ func (t *TargetPlugin) scaleIn(ctx context.Context, resourceGroup string, vmScal
 		}
 	}
 
-	ids, err := t.clusterUtils.RunPreScaleInTasksWithRemoteCheck(ctx, config, remoteIDs, int(num))
+	ids, err := t.drainScaleInInstances(ctx, opts, instances, num)
 	if err != nil {
 		return fmt.Errorf("failed to perform pre-scale Nomad scale in tasks: %v", err)
 	}
@@ -176,6 +189,213 @@ func (t *TargetPlugin) scaleIn(ctx context.Context, resourceGroup string, vmScal
 	return nil
 }
 
+// drainScaleInInstances selects and drains the Nomad nodes of the instances
+// to remove. The node selector strategy picks the nodes within the instances
+// of the zones allowed by the scale-in options. When zone balance is enabled,
+// nodes are selected zone by zone, so the instances removed keep the Scale
+// Set balanced like Azure would.
+func (t *TargetPlugin) drainScaleInInstances(ctx context.Context, opts *scaleInOptions, instances []vmssInstance, num int64) ([]scaleutils.NodeResourceID, error) {
+	candidates := opts.candidates(instances)
+	if len(candidates) == 0 {
+		return nil, fmt.Errorf("no instances found in zones %s", strings.Join(opts.zoneList(), ", "))
+	}
+
+	if !opts.zoneBalance {
+		return t.clusterUtils.RunPreScaleInTasksWithRemoteCheck(ctx, opts.config, remoteIDs(candidates), int(num))
+	}
+
+	removals := zoneBalancedRemovals(instances, candidates, num)
+
+	zones := make([]string, 0, len(removals))
+	for zone := range removals {
+		zones = append(zones, zone)
+	}
+	sort.Strings(zones)
+
+	var ids []scaleutils.NodeResourceID
+	for _, zone := range zones {
+		t.logger.Debug("selecting instances to remove from zone", "zone", zone, "count", removals[zone])
+
+		var zoneInstances []vmssInstance
+		for _, inst := range candidates {
+			if inst.zone == zone {
+				zoneInstances = append(zoneInstances, inst)
+			}
+		}
+
+		zoneIDs, err := t.clusterUtils.RunPreScaleInTasksWithRemoteCheck(ctx, opts.config, remoteIDs(zoneInstances), removals[zone])
+		if err != nil {
+			// Make the nodes drained in other zones eligible again, as their
+			// instances won't be removed.
+			if len(ids) > 0 {
+				if failErr := t.clusterUtils.RunPostScaleInTasksOnFailure(ids); failErr != nil {
+					t.logger.Error("failed to run post scale-in tasks on drained nodes", "error", failErr)
+				}
+			}
+			return nil, fmt.Errorf("failed to select instances in zone %q: %v", zone, err)
+		}
+		ids = append(ids, zoneIDs...)
+	}
+
+	return ids, nil
+}
+
+// vmssInstance is a running instance of the Scale Set.
+type vmssInstance struct {
+	remoteID string
+	zone     string
+}
+
+// instanceZone returns the availability zone of the Scale Set instance, or an
+// empty string if the Scale Set is not zonal.
+func instanceZone(vm compute.VirtualMachineScaleSetVM) string {
+	if vm.Zones == nil || len(*vm.Zones) == 0 {
+		return ""
+	}
+	return (*vm.Zones)[0]
+}
+
+// remoteIDs returns the remote IDs of the instances.
+func remoteIDs(instances []vmssInstance) []string {
+	out := make([]string, len(instances))
+	for i, inst := range instances {
+		out[i] = inst.remoteID
+	}
+	return out
+}
+
+// scaleInOptions describes how instances are selected for removal when
+// scaling in the Scale Set.
+type scaleInOptions struct {
+
+	// zones are the availability zones instances can be removed from. Empty
+	// means all zones.
+	zones map[string]struct{}
+
+	// zoneBalance indicates whether the remaining instances must stay
+	// balanced across the availability zones.
+	zoneBalance bool
+
+	// config is the target config used to select and drain nodes.
+	config map[string]string
+}
+
+// newScaleInOptions returns the scale-in options of the target config. The
+// settings of the Scale Set are used as defaults, so the instances removed
+// match the ones Azure would remove:
+//
+//   - zone balance follows the zoneBalance property of the Scale Set
+//   - the NewestVM and OldestVM scale-in policy rules set the node selector
+//     strategy to the equivalent Nomad node ordering
+func newScaleInOptions(vmss compute.VirtualMachineScaleSet, config map[string]string) (*scaleInOptions, error) {
+	opts := &scaleInOptions{
+		zones:  make(map[string]struct{}),
+		config: make(map[string]string, len(config)+1),
+	}
+	for k, v := range config {
+		opts.config[k] = v
+	}
+
+	if zones, ok := config[configKeyScaleInZones]; ok {
+		for _, zone := range strings.Split(zones, ",") {
+			if zone = strings.TrimSpace(zone); zone != "" {
+				opts.zones[zone] = struct{}{}
+			}
+		}
+	}
+
+	props := vmss.VirtualMachineScaleSetProperties
+
+	if balance, ok := config[configKeyScaleInZoneBalance]; ok {
+		b, err := strconv.ParseBool(balance)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value for %s: %v", configKeyScaleInZoneBalance, err)
+		}
+		opts.zoneBalance = b
+	} else if props != nil && props.ZoneBalance != nil {
+		opts.zoneBalance = *props.ZoneBalance
+	}
+
+	if _, ok := config[sdk.TargetConfigNodeSelectorStrategy]; !ok && props != nil &&
+		props.ScaleInPolicy != nil && props.ScaleInPolicy.Rules != nil {
+		for _, rule := range *props.ScaleInPolicy.Rules {
+			switch rule {
+			case compute.NewestVM:
+				opts.config[sdk.TargetConfigNodeSelectorStrategy] = sdk.TargetNodeSelectorStrategyNewestCreateIndex
+			case compute.OldestVM:
+				opts.config[sdk.TargetConfigNodeSelectorStrategy] = sdk.TargetNodeSelectorStrategyOldestCreateIndex
+			}
+		}
+	}
+
+	return opts, nil
+}
+
+// candidates returns the instances that can be removed.
+func (o *scaleInOptions) candidates(instances []vmssInstance) []vmssInstance {
+	if len(o.zones) == 0 {
+		return instances
+	}
+
+	var out []vmssInstance
+	for _, inst := range instances {
+		if _, ok := o.zones[inst.zone]; ok {
+			out = append(out, inst)
+		}
+	}
+	return out
+}
+
+// zoneList returns the sorted zones instances can be removed from.
+func (o *scaleInOptions) zoneList() []string {
+	out := make([]string, 0, len(o.zones))
+	for zone := range o.zones {
+		out = append(out, zone)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// zoneBalancedRemovals returns the number of instances to remove from each
+// zone, so the remaining instances are as balanced as possible across the
+// zones of the Scale Set. Instances are removed one at a time from the
+// candidate zone with the most instances, breaking ties by zone name.
+func zoneBalancedRemovals(instances, candidates []vmssInstance, num int64) map[string]int {
+	counts := make(map[string]int)
+	for _, inst := range instances {
+		counts[inst.zone]++
+	}
+
+	available := make(map[string]int)
+	for _, inst := range candidates {
+		available[inst.zone]++
+	}
+
+	zones := make([]string, 0, len(available))
+	for zone := range available {
+		zones = append(zones, zone)
+	}
+	sort.Strings(zones)
+
+	removals := make(map[string]int)
+	for i := int64(0); i < num; i++ {
+		best, found := "", false
+		for _, zone := range zones {
+			if removals[zone] >= available[zone] {
+				continue
+			}
+			if !found || counts[zone]-removals[zone] > counts[best]-removals[best] {
+				best, found = zone, true
+			}
+		}
+		if !found {
+			break
+		}
+		removals[best]++
+	}
+	return removals
+}
+
 // azureNodeIDMap is used to identify the Azure InstanceID of a Nomad node using
 // the relevant attribute value.
 func azureNodeIDMap(n *api.Node) (string, error) {
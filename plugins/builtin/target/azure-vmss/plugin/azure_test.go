@@ -7,6 +7,8 @@ import (
 	"errors"
 	"testing"
 
+	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2020-06-01/compute"
+	"github.com/hashicorp/nomad-autoscaler/sdk/helper/ptr"
 	"github.com/hashicorp/nomad/api"
 	"github.com/stretchr/testify/assert"
 )
@@ -53,3 +55,147 @@ func Test_azureNodeIDMap(t *testing.T) {
 		})
 	}
 }
+
+func Test_newScaleInOptions(t *testing.T) {
+	testCases := []struct {
+		inputVMSS      compute.VirtualMachineScaleSet
+		inputConfig    map[string]string
+		expectedOutput *scaleInOptions
+		expectedError  string
+		name           string
+	}{
+		{
+			inputVMSS:   compute.VirtualMachineScaleSet{},
+			inputConfig: map[string]string{},
+			expectedOutput: &scaleInOptions{
+				zones:  map[string]struct{}{},
+				config: map[string]string{},
+			},
+			name: "defaults",
+		},
+		{
+			inputVMSS: compute.VirtualMachineScaleSet{
+				VirtualMachineScaleSetProperties: &compute.VirtualMachineScaleSetProperties{
+					ZoneBalance: ptr.Of(true),
+					ScaleInPolicy: &compute.ScaleInPolicy{
+						Rules: &[]compute.VirtualMachineScaleSetScaleInRules{compute.OldestVM},
+					},
+				},
+			},
+			inputConfig: map[string]string{},
+			expectedOutput: &scaleInOptions{
+				zones:       map[string]struct{}{},
+				zoneBalance: true,
+				config:      map[string]string{"node_selector_strategy": "oldest_create_index"},
+			},
+			name: "scale set settings",
+		},
+		{
+			inputVMSS: compute.VirtualMachineScaleSet{
+				VirtualMachineScaleSetProperties: &compute.VirtualMachineScaleSetProperties{
+					ZoneBalance: ptr.Of(true),
+					ScaleInPolicy: &compute.ScaleInPolicy{
+						Rules: &[]compute.VirtualMachineScaleSetScaleInRules{compute.NewestVM},
+					},
+				},
+			},
+			inputConfig: map[string]string{
+				"scale_in_zones":         "1, 3",
+				"scale_in_zone_balance":  "false",
+				"node_selector_strategy": "least_busy",
+			},
+			expectedOutput: &scaleInOptions{
+				zones: map[string]struct{}{"1": {}, "3": {}},
+				config: map[string]string{
+					"scale_in_zones":         "1, 3",
+					"scale_in_zone_balance":  "false",
+					"node_selector_strategy": "least_busy",
+				},
+			},
+			name: "config overrides",
+		},
+		{
+			inputVMSS:     compute.VirtualMachineScaleSet{},
+			inputConfig:   map[string]string{"scale_in_zone_balance": "maybe"},
+			expectedError: `invalid value for scale_in_zone_balance: strconv.ParseBool: parsing "maybe": invalid syntax`,
+			name:          "invalid zone balance",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actualOutput, actualErr := newScaleInOptions(tc.inputVMSS, tc.inputConfig)
+			if tc.expectedError != "" {
+				assert.EqualError(t, actualErr, tc.expectedError, tc.name)
+				return
+			}
+			assert.NoError(t, actualErr, tc.name)
+			assert.Equal(t, tc.expectedOutput, actualOutput, tc.name)
+		})
+	}
+}
+
+func Test_zoneBalancedRemovals(t *testing.T) {
+	instances := []vmssInstance{
+		{remoteID: "vmss_0", zone: "1"},
+		{remoteID: "vmss_1", zone: "1"},
+		{remoteID: "vmss_2", zone: "1"},
+		{remoteID: "vmss_3", zone: "2"},
+		{remoteID: "vmss_4", zone: "2"},
+		{remoteID: "vmss_5", zone: "3"},
+	}
+
+	testCases := []struct {
+		inputCandidates []vmssInstance
+		inputNum        int64
+		expectedOutput  map[string]int
+		name            string
+	}{
+		{
+			inputCandidates: instances,
+			inputNum:        1,
+			expectedOutput:  map[string]int{"1": 1},
+			name:            "largest zone first",
+		},
+		{
+			inputCandidates: instances,
+			inputNum:        3,
+			expectedOutput:  map[string]int{"1": 2, "2": 1},
+			name:            "ties broken by zone name",
+		},
+		{
+			inputCandidates: instances[3:],
+			inputNum:        3,
+			expectedOutput:  map[string]int{"2": 2, "3": 1},
+			name:            "only candidate zones",
+		},
+		{
+			inputCandidates: instances[5:],
+			inputNum:        3,
+			expectedOutput:  map[string]int{"3": 1},
+			name:            "not enough candidates",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actualOutput := zoneBalancedRemovals(instances, tc.inputCandidates, tc.inputNum)
+			assert.Equal(t, tc.expectedOutput, actualOutput, tc.name)
+		})
+	}
+}
+
+func Test_scaleInOptions_candidates(t *testing.T) {
+	instances := []vmssInstance{
+		{remoteID: "vmss_0", zone: "1"},
+		{remoteID: "vmss_1", zone: "2"},
+		{remoteID: "vmss_2", zone: "3"},
+	}
+
+	opts := &scaleInOptions{}
+	assert.Equal(t, instances, opts.candidates(instances))
+
+	opts.zones = map[string]struct{}{"1": {}, "3": {}}
+	assert.Equal(t, []vmssInstance{instances[0], instances[2]}, opts.candidates(instances))
+	assert.Equal(t, []string{"1", "3"}, opts.zoneList())
+}
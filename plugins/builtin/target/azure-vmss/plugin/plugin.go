@@ -31,6 +31,19 @@ const (
 	configKeySecretKey      = "secret_access_key"
 	configKeyResoureGroup   = "resource_group"
 	configKeyVMSS           = "vm_scale_set"
+
+	// configKeyScaleInZones is the optional comma separated list of
+	// availability zones instances are removed from when scaling in.
+	configKeyScaleInZones = "scale_in_zones"
+
+	// configKeyScaleInZoneBalance controls whether instances are removed so
+	// the remaining instances stay balanced across the availability zones of
+	// the Scale Set. It defaults to the zone balance setting of the Scale Set.
+	configKeyScaleInZoneBalance = "scale_in_zone_balance"
+
+	// metaValueInProgressProvisioning is the sdk.TargetStatusMetaKeyInProgress
+	// value used when instances of the ScaleSet are still being provisioned.
+	metaValueInProgressProvisioning = "provisioning"
 )
 
 var (
@@ -44,8 +57,12 @@ var (
 	}
 )
 
-// Assert that TargetPlugin meets the target.Target interface.
-var _ target.Target = (*TargetPlugin)(nil)
+// Assert that TargetPlugin meets the target.Target and
+// target.CapacityReporter interfaces.
+var (
+	_ target.Target           = (*TargetPlugin)(nil)
+	_ target.CapacityReporter = (*TargetPlugin)(nil)
+)
 
 // TargetPlugin is the Azure VMSS implementation of the target.Target interface.
 type TargetPlugin struct {
@@ -125,7 +142,7 @@ func (t *TargetPlugin) Scale(action sdk.ScalingAction, config map[string]string)
 
 	switch direction {
 	case "in":
-		err = t.scaleIn(ctx, resourceGroup, vmScaleSet, num, config)
+		err = t.scaleIn(ctx, currVMSS, resourceGroup, vmScaleSet, num, config)
 	case "out":
 		err = t.scaleOut(ctx, resourceGroup, vmScaleSet, num)
 	default:
@@ -190,6 +207,45 @@ func (t *TargetPlugin) Status(config map[string]string) (*sdk.TargetStatus, erro
 	return &resp, nil
 }
 
+// Capacity satisfies the Capacity function on the target.CapacityReporter
+// interface. The max count of the target is the maximum capacity Azure
+// reports for the SKU of the Scale Set.
+func (t *TargetPlugin) Capacity(config map[string]string) (*sdk.TargetCapacity, error) {
+
+	// We cannot read the capacity of a vmss without knowing the vmss resource
+	// group and name.
+	resourceGroup, ok := config[configKeyResoureGroup]
+	if !ok {
+		return nil, fmt.Errorf("required config param %s not found", configKeyResoureGroup)
+	}
+	vmScaleSet, ok := config[configKeyVMSS]
+	if !ok {
+		return nil, fmt.Errorf("required config param %s not found", configKeyVMSS)
+	}
+
+	ctx := context.Background()
+
+	vmss, err := t.vmss.Get(ctx, resourceGroup, vmScaleSet)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get Azure ScaleSet: %v", err)
+	}
+
+	var skus []compute.VirtualMachineScaleSetSku
+	iter, err := t.vmss.ListSkusComplete(ctx, resourceGroup, vmScaleSet)
+	for ; err == nil && iter.NotDone(); err = iter.NextWithContext(ctx) {
+		skus = append(skus, iter.Value())
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list Azure ScaleSet SKUs: %v", err)
+	}
+
+	maxCount := skuMaxCapacity(vmss.Sku, skus)
+	if maxCount == nil {
+		return nil, nil
+	}
+	return &sdk.TargetCapacity{MaxCount: maxCount}, nil
+}
+
 func (t *TargetPlugin) calculateDirection(vmssDesired, strategyDesired int64) (int64, string) {
 
 	if strategyDesired < vmssDesired {
@@ -208,6 +264,7 @@ func processInstanceView(instanceView compute.VirtualMachineScaleSetInstanceView
 	for _, instanceStatus := range *instanceView.VirtualMachine.StatusesSummary {
 		if *instanceStatus.Code != "ProvisioningState/succeeded" {
 			status.Ready = false
+			status.Meta[sdk.TargetStatusMetaKeyInProgress] = metaValueInProgressProvisioning
 		}
 	}
 
@@ -228,3 +285,24 @@ func processInstanceView(instanceView compute.VirtualMachineScaleSetInstanceView
 		}
 	}
 }
+
+// skuMaxCapacity returns the maximum capacity of the SKU used by the Scale
+// Set, or nil if it's not found within the SKUs available to the Scale Set.
+func skuMaxCapacity(current *compute.Sku, skus []compute.VirtualMachineScaleSetSku) *int64 {
+	if current == nil || current.Name == nil {
+		return nil
+	}
+
+	for _, sku := range skus {
+		if sku.Sku == nil || sku.Sku.Name == nil || *sku.Sku.Name != *current.Name {
+			continue
+		}
+		if current.Tier != nil && sku.Sku.Tier != nil && *sku.Sku.Tier != *current.Tier {
+			continue
+		}
+		if sku.Capacity != nil {
+			return sku.Capacity.Maximum
+		}
+	}
+	return nil
+}
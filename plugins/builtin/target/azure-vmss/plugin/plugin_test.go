@@ -10,6 +10,7 @@ import (
 	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2020-06-01/compute"
 	"github.com/Azure/go-autorest/autorest/date"
 	"github.com/hashicorp/nomad-autoscaler/sdk"
+	"github.com/hashicorp/nomad-autoscaler/sdk/helper/ptr"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -90,7 +91,9 @@ func Test_processInstanceView(t *testing.T) {
 			expectedStatus: &sdk.TargetStatus{
 				Ready: false,
 				Count: 1,
-				Meta:  map[string]string{},
+				Meta: map[string]string{
+					"nomad_autoscaler.in_progress": "provisioning",
+				},
 			},
 			name: "InstanceView still in progress",
 		},
@@ -127,7 +130,9 @@ func Test_processInstanceView(t *testing.T) {
 			expectedStatus: &sdk.TargetStatus{
 				Ready: false,
 				Count: 2,
-				Meta:  map[string]string{},
+				Meta: map[string]string{
+					"nomad_autoscaler.in_progress": "provisioning",
+				},
 			},
 			name: "InstanceView still in progress",
 		},
@@ -208,3 +213,62 @@ func int32ToPtr(v int32) *int32 {
 func stringToPtr(v string) *string {
 	return &v
 }
+
+func Test_skuMaxCapacity(t *testing.T) {
+	skus := []compute.VirtualMachineScaleSetSku{
+		{
+			Sku:      &compute.Sku{Name: ptr.Of("Standard_D2s_v3"), Tier: ptr.Of("Standard")},
+			Capacity: &compute.VirtualMachineScaleSetSkuCapacity{Maximum: ptr.Of(int64(1000))},
+		},
+		{
+			Sku:      &compute.Sku{Name: ptr.Of("Standard_D4s_v3"), Tier: ptr.Of("Standard")},
+			Capacity: &compute.VirtualMachineScaleSetSkuCapacity{Maximum: ptr.Of(int64(600))},
+		},
+		{
+			Sku: &compute.Sku{Name: ptr.Of("Standard_D8s_v3"), Tier: ptr.Of("Standard")},
+		},
+	}
+
+	testCases := []struct {
+		inputSku       *compute.Sku
+		expectedOutput *int64
+		name           string
+	}{
+		{
+			inputSku:       &compute.Sku{Name: ptr.Of("Standard_D4s_v3"), Tier: ptr.Of("Standard")},
+			expectedOutput: ptr.Of(int64(600)),
+			name:           "matching sku",
+		},
+		{
+			inputSku:       &compute.Sku{Name: ptr.Of("Standard_D2s_v3")},
+			expectedOutput: ptr.Of(int64(1000)),
+			name:           "matching sku without tier",
+		},
+		{
+			inputSku:       &compute.Sku{Name: ptr.Of("Standard_D2s_v3"), Tier: ptr.Of("Basic")},
+			expectedOutput: nil,
+			name:           "different tier",
+		},
+		{
+			inputSku:       &compute.Sku{Name: ptr.Of("Standard_D8s_v3")},
+			expectedOutput: nil,
+			name:           "sku without capacity",
+		},
+		{
+			inputSku:       &compute.Sku{Name: ptr.Of("Standard_F2s_v2")},
+			expectedOutput: nil,
+			name:           "sku not available",
+		},
+		{
+			inputSku:       nil,
+			expectedOutput: nil,
+			name:           "unknown sku",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expectedOutput, skuMaxCapacity(tc.inputSku, skus), tc.name)
+		})
+	}
+}
@@ -28,6 +28,10 @@ const (
 	// nodeAttrGCEZone is the node attribute to use when identifying the GCE
 	// zone of a node.
 	nodeAttrGCEZone = "platform.gce.zone"
+
+	// quotaMetricInstances is the metric of the regional quota limiting the
+	// number of instances.
+	quotaMetricInstances = "INSTANCES"
 )
 
 func (t *TargetPlugin) setupGCEClients(config map[string]string) error {
@@ -55,8 +59,17 @@ func (t *TargetPlugin) setupGCEClients(config map[string]string) error {
 	return nil
 }
 
-func (t *TargetPlugin) status(ctx context.Context, ig instanceGroup) (bool, int64, error) {
-	return ig.status(ctx, t.service)
+// status returns whether the instance group is stable and its count. When
+// suspend is true, suspended instances are not included in the count.
+func (t *TargetPlugin) status(ctx context.Context, ig instanceGroup, suspend bool) (bool, int64, error) {
+	status, err := ig.status(ctx, t.service)
+	if err != nil {
+		return false, -1, err
+	}
+	if suspend {
+		return status.stable, status.runningSize(), nil
+	}
+	return status.stable, status.targetSize, nil
 }
 
 func (t *TargetPlugin) scaleOut(ctx context.Context, ig instanceGroup, num int64) error {
@@ -71,7 +84,57 @@ func (t *TargetPlugin) scaleOut(ctx context.Context, ig instanceGroup, num int64
 	return nil
 }
 
-func (t *TargetPlugin) scaleIn(ctx context.Context, group instanceGroup, num int64, config map[string]string) error {
+// scaleOutResume resumes up to num suspended instances of the instance group,
+// creating new instances for the remainder. The Nomad nodes of the resumed
+// instances are made eligible again, since they were drained when the
+// instances were suspended.
+func (t *TargetPlugin) scaleOutResume(ctx context.Context, group instanceGroup, num int64, config map[string]string) error {
+	log := t.logger.With("action", "scale_out", "instance_group", group.getName())
+
+	instances, err := group.listInstances(ctx, t.service)
+	if err != nil {
+		return fmt.Errorf("failed to list GCE MIG instances: %v", err)
+	}
+
+	resumeIDs := suspendedInstances(instances, num)
+	if len(resumeIDs) > 0 {
+		log.Debug("resuming GCE MIG instances", "instances", resumeIDs)
+		if err := group.resumeInstances(ctx, t.service, resumeIDs); err != nil {
+			return fmt.Errorf("failed to resume instances: %v", err)
+		}
+	}
+
+	// Resuming instances doesn't change the target size of the instance
+	// group, so new instances are added on top of it.
+	if remaining := num - int64(len(resumeIDs)); remaining > 0 {
+		status, err := group.status(ctx, t.service)
+		if err != nil {
+			return fmt.Errorf("failed to describe GCE Managed Instance Group: %v", err)
+		}
+
+		log.Debug("creating GCE MIG instances", "count", remaining)
+		if err := group.resize(ctx, t.service, status.targetSize+remaining); err != nil {
+			return fmt.Errorf("failed to scale out GCE Instance Group: %v", err)
+		}
+	}
+
+	if err := t.ensureInstanceGroupIsStable(ctx, group); err != nil {
+		return fmt.Errorf("failed to confirm scale out GCE Instance Group: %v", err)
+	}
+	log.Info("scale out GCE MIG confirmed", "resumed", len(resumeIDs))
+
+	if len(resumeIDs) > 0 {
+		if err := t.clusterUtils.RunPostScaleOutTasks(ctx, config, resumeIDs); err != nil {
+			return fmt.Errorf("failed to perform post-scale Nomad scale out tasks: %v", err)
+		}
+	}
+	return nil
+}
+
+// scaleIn drains and removes num instances from the instance group. When
+// suspend is true, the instances are suspended instead of deleted so they can
+// be resumed quickly by later scale out actions.
+func (t *TargetPlugin) scaleIn(ctx context.Context, group instanceGroup, num int64, suspend bool, config map[string]string) error {
 	// Create a logger for this action to pre-populate useful information we
 	// would like on all log lines.
 	log := t.logger.With("action", "scale_in", "instance_group", group.getName())
@@ -88,8 +151,7 @@ func (t *TargetPlugin) scaleIn(ctx context.Context, group instanceGroup, num int
 			log.Debug("found healthy instance", "instance_id", inst.Id, "instance", inst.Instance)
 
 			// Use the partial URL since that's what gceNodeIDMap returns.
-			idx := strings.Index(inst.Instance, "/zones/")
-			remoteIDs = append(remoteIDs, inst.Instance[idx+1:])
+			remoteIDs = append(remoteIDs, instancePartialURL(inst.Instance))
 		} else {
 			log.Debug("skipping instance", "instance_id", inst.Id, "instance", inst.Instance, "instance_status", inst.InstanceStatus, "current_action", inst.CurrentAction)
 		}
@@ -107,15 +169,28 @@ func (t *TargetPlugin) scaleIn(ctx context.Context, group instanceGroup, num int
 		instanceIDs = append(instanceIDs, node.RemoteResourceID)
 	}
 
-	// Delete the instances from the Managed Instance Groups. The targetSize of the MIG is will be reduced by the
-	// number of instances that are deleted.
-	log.Debug("deleting GCE MIG instances", "instances", ids)
+	if suspend {
+		// Suspend the instances within the Managed Instance Group. The
+		// targetSize of the MIG is unchanged, while its targetSuspendedSize
+		// is increased by the number of instances that are suspended.
+		log.Debug("suspending GCE MIG instances", "instances", ids)
 
-	if err := group.deleteInstance(ctx, t.service, instanceIDs); err != nil {
-		return fmt.Errorf("failed to delete instances: %v", err)
-	}
+		if err := group.suspendInstances(ctx, t.service, instanceIDs); err != nil {
+			return fmt.Errorf("failed to suspend instances: %v", err)
+		}
 
-	log.Info("successfully deleted GCE MIG instances")
+		log.Info("successfully suspended GCE MIG instances")
+	} else {
+		// Delete the instances from the Managed Instance Groups. The targetSize of the MIG is will be reduced by the
+		// number of instances that are deleted.
+		log.Debug("deleting GCE MIG instances", "instances", ids)
+
+		if err := group.deleteInstance(ctx, t.service, instanceIDs); err != nil {
+			return fmt.Errorf("failed to delete instances: %v", err)
+		}
+
+		log.Info("successfully deleted GCE MIG instances")
+	}
 
 	if err := t.ensureInstanceGroupIsStable(ctx, group); err != nil {
 		return fmt.Errorf("failed to confirm scale in GCE MIG: %v", err)
@@ -134,9 +209,12 @@ func (t *TargetPlugin) scaleIn(ctx context.Context, group instanceGroup, num int
 func (t *TargetPlugin) ensureInstanceGroupIsStable(ctx context.Context, group instanceGroup) error {
 
 	f := func(ctx context.Context) (bool, error) {
-		stable, _, err := group.status(ctx, t.service)
-		if stable || err != nil {
+		status, err := group.status(ctx, t.service)
+		if err != nil {
 			return true, err
+		}
+		if status.stable {
+			return true, nil
 		} else {
 			return false, errors.New("waiting for instance group to become stable")
 		}
@@ -145,6 +223,45 @@ func (t *TargetPlugin) ensureInstanceGroupIsStable(ctx context.Context, group in
 	return retry(ctx, defaultRetryInterval, defaultRetryLimit, f)
 }
 
+// suspendedInstances returns the partial URLs of up to num suspended instances
+// which can be resumed.
+func suspendedInstances(instances []*compute.ManagedInstance, num int64) []string {
+	var out []string
+	for _, inst := range instances {
+		if int64(len(out)) >= num {
+			break
+		}
+		if inst.InstanceStatus == "SUSPENDED" && inst.CurrentAction == "NONE" {
+			out = append(out, instancePartialURL(inst.Instance))
+		}
+	}
+	return out
+}
+
+// quotaRemaining returns the number of units of the quota metric which are
+// still available, or nil if the metric is not found in the quotas.
+func quotaRemaining(quotas []*compute.Quota, metric string) *int64 {
+	for _, q := range quotas {
+		if q == nil || q.Metric != metric {
+			continue
+		}
+
+		remaining := int64(q.Limit - q.Usage)
+		if remaining < 0 {
+			remaining = 0
+		}
+		return &remaining
+	}
+	return nil
+}
+
+// instancePartialURL returns the partial URL of the instance, in the format
+// zones/{zone}/instances/{name}, from its full URL.
+func instancePartialURL(url string) string {
+	idx := strings.Index(url, "/zones/")
+	return url[idx+1:]
+}
+
 func pathOrContents(poc string) (string, error) {
 	if len(poc) == 0 {
 		return poc, nil
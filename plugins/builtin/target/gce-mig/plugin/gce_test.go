@@ -7,8 +7,10 @@ import (
 	"errors"
 	"testing"
 
+	"github.com/hashicorp/nomad-autoscaler/sdk/helper/ptr"
 	"github.com/hashicorp/nomad/api"
 	"github.com/stretchr/testify/assert"
+	"google.golang.org/api/compute/v1"
 )
 
 func Test_gceNodeIDMap(t *testing.T) {
@@ -68,3 +70,95 @@ func Test_gceNodeIDMap(t *testing.T) {
 		})
 	}
 }
+
+func Test_suspendedInstances(t *testing.T) {
+	instances := []*compute.ManagedInstance{
+		{
+			Instance:       "https://www.googleapis.com/compute/v1/projects/p/zones/us-central1-a/instances/instance-1",
+			InstanceStatus: "RUNNING",
+			CurrentAction:  "NONE",
+		},
+		{
+			Instance:       "https://www.googleapis.com/compute/v1/projects/p/zones/us-central1-a/instances/instance-2",
+			InstanceStatus: "SUSPENDED",
+			CurrentAction:  "NONE",
+		},
+		{
+			Instance:       "https://www.googleapis.com/compute/v1/projects/p/zones/us-central1-b/instances/instance-3",
+			InstanceStatus: "SUSPENDING",
+			CurrentAction:  "SUSPENDING",
+		},
+		{
+			Instance:       "https://www.googleapis.com/compute/v1/projects/p/zones/us-central1-b/instances/instance-4",
+			InstanceStatus: "SUSPENDED",
+			CurrentAction:  "NONE",
+		},
+	}
+
+	testCases := []struct {
+		inputNum       int64
+		expectedOutput []string
+		name           string
+	}{
+		{
+			inputNum:       1,
+			expectedOutput: []string{"zones/us-central1-a/instances/instance-2"},
+			name:           "limited by num",
+		},
+		{
+			inputNum: 3,
+			expectedOutput: []string{
+				"zones/us-central1-a/instances/instance-2",
+				"zones/us-central1-b/instances/instance-4",
+			},
+			name: "limited by suspended instances",
+		},
+		{
+			inputNum:       0,
+			expectedOutput: nil,
+			name:           "none requested",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expectedOutput, suspendedInstances(instances, tc.inputNum), tc.name)
+		})
+	}
+}
+
+func Test_quotaRemaining(t *testing.T) {
+	quotas := []*compute.Quota{
+		{Metric: "CPUS", Limit: 24, Usage: 8},
+		{Metric: "INSTANCES", Limit: 100, Usage: 42},
+		{Metric: "IN_USE_ADDRESSES", Limit: 8, Usage: 10},
+	}
+
+	testCases := []struct {
+		inputMetric    string
+		expectedOutput *int64
+		name           string
+	}{
+		{
+			inputMetric:    "INSTANCES",
+			expectedOutput: ptr.Of(int64(58)),
+			name:           "quota available",
+		},
+		{
+			inputMetric:    "IN_USE_ADDRESSES",
+			expectedOutput: ptr.Of(int64(0)),
+			name:           "quota exceeded",
+		},
+		{
+			inputMetric:    "GPUS",
+			expectedOutput: nil,
+			name:           "quota not found",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expectedOutput, quotaRemaining(quotas, tc.inputMetric), tc.name)
+		})
+	}
+}
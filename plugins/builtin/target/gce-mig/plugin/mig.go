@@ -5,16 +5,46 @@ package plugin
 
 import (
 	"context"
+	"path"
 
 	"google.golang.org/api/compute/v1"
 )
 
 type instanceGroup interface {
 	getName() string
-	status(ctx context.Context, service *compute.Service) (bool, int64, error)
+	status(ctx context.Context, service *compute.Service) (*migStatus, error)
 	listInstances(ctx context.Context, service *compute.Service) ([]*compute.ManagedInstance, error)
 	resize(ctx context.Context, service *compute.Service, num int64) error
 	deleteInstance(ctx context.Context, service *compute.Service, instanceIDs []string) error
+	suspendInstances(ctx context.Context, service *compute.Service, instanceIDs []string) error
+	resumeInstances(ctx context.Context, service *compute.Service, instanceIDs []string) error
+	quotas(ctx context.Context, service *compute.Service) ([]*compute.Quota, error)
+}
+
+// migStatus is the state of a Managed Instance Group.
+type migStatus struct {
+	stable bool
+
+	// targetSize is the number of instances of the group, including the
+	// suspended instances.
+	targetSize int64
+
+	// targetSuspendedSize is the number of suspended instances of the group.
+	targetSuspendedSize int64
+}
+
+// runningSize returns the number of instances of the group which are not
+// suspended.
+func (s *migStatus) runningSize() int64 {
+	return s.targetSize - s.targetSuspendedSize
+}
+
+func newMIGStatus(mig *compute.InstanceGroupManager) *migStatus {
+	return &migStatus{
+		stable:              mig.Status.IsStable,
+		targetSize:          mig.TargetSize,
+		targetSuspendedSize: mig.TargetSuspendedSize,
+	}
 }
 
 type regionalInstanceGroup struct {
@@ -33,12 +63,12 @@ func (z *zonalInstanceGroup) getName() string {
 	return z.name
 }
 
-func (z *zonalInstanceGroup) status(ctx context.Context, service *compute.Service) (bool, int64, error) {
+func (z *zonalInstanceGroup) status(ctx context.Context, service *compute.Service) (*migStatus, error) {
 	mig, err := service.InstanceGroupManagers.Get(z.project, z.zone, z.name).Context(ctx).Do()
 	if err != nil {
-		return false, -1, err
+		return nil, err
 	}
-	return mig.Status.IsStable, mig.TargetSize, nil
+	return newMIGStatus(mig), nil
 }
 
 func (z *zonalInstanceGroup) listInstances(ctx context.Context, service *compute.Service) ([]*compute.ManagedInstance, error) {
@@ -63,16 +93,50 @@ func (z *zonalInstanceGroup) deleteInstance(ctx context.Context, service *comput
 	return err
 }
 
+func (z *zonalInstanceGroup) suspendInstances(ctx context.Context, service *compute.Service, instanceIDs []string) error {
+	request := &compute.InstanceGroupManagersSuspendInstancesRequest{
+		Instances: instanceIDs,
+	}
+
+	_, err := service.InstanceGroupManagers.SuspendInstances(z.project, z.zone, z.name, request).Context(ctx).Do()
+	return err
+}
+
+func (z *zonalInstanceGroup) resumeInstances(ctx context.Context, service *compute.Service, instanceIDs []string) error {
+	request := &compute.InstanceGroupManagersResumeInstancesRequest{
+		Instances: instanceIDs,
+	}
+
+	_, err := service.InstanceGroupManagers.ResumeInstances(z.project, z.zone, z.name, request).Context(ctx).Do()
+	return err
+}
+
+// quotas returns the quotas of the region of the zone, as Compute Engine
+// instance quotas are enforced per region.
+func (z *zonalInstanceGroup) quotas(ctx context.Context, service *compute.Service) ([]*compute.Quota, error) {
+	zone, err := service.Zones.Get(z.project, z.zone).Context(ctx).Do()
+	if err != nil {
+		return nil, err
+	}
+
+	// The region of the zone is returned as its full URL.
+	region, err := service.Regions.Get(z.project, path.Base(zone.Region)).Context(ctx).Do()
+	if err != nil {
+		return nil, err
+	}
+	return region.Quotas, nil
+}
+
 func (r *regionalInstanceGroup) getName() string {
 	return r.name
 }
 
-func (r *regionalInstanceGroup) status(ctx context.Context, service *compute.Service) (bool, int64, error) {
+func (r *regionalInstanceGroup) status(ctx context.Context, service *compute.Service) (*migStatus, error) {
 	mig, err := service.RegionInstanceGroupManagers.Get(r.project, r.region, r.name).Context(ctx).Do()
 	if err != nil {
-		return false, -1, err
+		return nil, err
 	}
-	return mig.Status.IsStable, mig.TargetSize, nil
+	return newMIGStatus(mig), nil
 }
 
 func (r *regionalInstanceGroup) listInstances(ctx context.Context, service *compute.Service) ([]*compute.ManagedInstance, error) {
@@ -96,3 +160,29 @@ func (r *regionalInstanceGroup) deleteInstance(ctx context.Context, service *com
 	_, err := service.RegionInstanceGroupManagers.DeleteInstances(r.project, r.region, r.name, request).Context(ctx).Do()
 	return err
 }
+
+func (r *regionalInstanceGroup) suspendInstances(ctx context.Context, service *compute.Service, instanceIDs []string) error {
+	request := &compute.RegionInstanceGroupManagersSuspendInstancesRequest{
+		Instances: instanceIDs,
+	}
+
+	_, err := service.RegionInstanceGroupManagers.SuspendInstances(r.project, r.region, r.name, request).Context(ctx).Do()
+	return err
+}
+
+func (r *regionalInstanceGroup) resumeInstances(ctx context.Context, service *compute.Service, instanceIDs []string) error {
+	request := &compute.RegionInstanceGroupManagersResumeInstancesRequest{
+		Instances: instanceIDs,
+	}
+
+	_, err := service.RegionInstanceGroupManagers.ResumeInstances(r.project, r.region, r.name, request).Context(ctx).Do()
+	return err
+}
+
+func (r *regionalInstanceGroup) quotas(ctx context.Context, service *compute.Service) ([]*compute.Quota, error) {
+	region, err := service.Regions.Get(r.project, r.region).Context(ctx).Do()
+	if err != nil {
+		return nil, err
+	}
+	return region.Quotas, nil
+}
@@ -6,6 +6,7 @@ package plugin
 import (
 	"context"
 	"fmt"
+	"strconv"
 
 	"github.com/hashicorp/go-hclog"
 	"github.com/hashicorp/nomad-autoscaler/plugins"
@@ -26,6 +27,14 @@ const (
 	configKeyRegion      = "region"
 	configKeyZone        = "zone"
 	configKeyMIGName     = "mig_name"
+
+	// configKeySuspendInstances enables suspending instances on scale in and
+	// resuming them on scale out, instead of deleting and creating them.
+	configKeySuspendInstances = "suspend_instances"
+
+	// metaValueInProgressActions is the sdk.TargetStatusMetaKeyInProgress
+	// value used when the MIG is performing actions on its instances.
+	metaValueInProgressActions = "instance_actions"
 )
 
 var (
@@ -39,8 +48,12 @@ var (
 	}
 )
 
-// Assert that TargetPlugin meets the target.Target interface.
-var _ target.Target = (*TargetPlugin)(nil)
+// Assert that TargetPlugin meets the target.Target and
+// target.CapacityReporter interfaces.
+var (
+	_ target.Target           = (*TargetPlugin)(nil)
+	_ target.CapacityReporter = (*TargetPlugin)(nil)
+)
 
 // TargetPlugin is the CGE MIG implementation of the target.Target interface.
 type TargetPlugin struct {
@@ -100,9 +113,14 @@ func (t *TargetPlugin) Scale(action sdk.ScalingAction, config map[string]string)
 		return err
 	}
 
+	suspend, err := t.suspendEnabled(config)
+	if err != nil {
+		return err
+	}
+
 	ctx := context.Background()
 
-	_, currentCount, err := t.status(ctx, migRef)
+	_, currentCount, err := t.status(ctx, migRef, suspend)
 	if err != nil {
 		return fmt.Errorf("failed to describe GCE Managed Instance Group: %v", err)
 	}
@@ -111,9 +129,13 @@ func (t *TargetPlugin) Scale(action sdk.ScalingAction, config map[string]string)
 
 	switch direction {
 	case "in":
-		err = t.scaleIn(ctx, migRef, num, config)
+		err = t.scaleIn(ctx, migRef, num, suspend, config)
 	case "out":
-		err = t.scaleOut(ctx, migRef, num)
+		if suspend {
+			err = t.scaleOutResume(ctx, migRef, num-currentCount, config)
+		} else {
+			err = t.scaleOut(ctx, migRef, num)
+		}
 	default:
 		t.logger.Info("scaling not required", "mig_name", migRef.getName(),
 			"current_count", currentCount, "strategy_count", action.Count)
@@ -147,9 +169,14 @@ func (t *TargetPlugin) Status(config map[string]string) (*sdk.TargetStatus, erro
 		return nil, err
 	}
 
+	suspend, err := t.suspendEnabled(config)
+	if err != nil {
+		return nil, err
+	}
+
 	ctx := context.Background()
 
-	stable, currentCount, err := t.status(ctx, group)
+	stable, currentCount, err := t.status(ctx, group, suspend)
 	if err != nil {
 		return nil, fmt.Errorf("failed to describe GCE Managed Instance Group: %v", err)
 	}
@@ -160,9 +187,53 @@ func (t *TargetPlugin) Status(config map[string]string) (*sdk.TargetStatus, erro
 		Meta:  make(map[string]string),
 	}
 
+	// A MIG which isn't stable has actions in progress on its instances.
+	if !stable {
+		resp.Meta[sdk.TargetStatusMetaKeyInProgress] = metaValueInProgressActions
+	}
+
 	return &resp, nil
 }
 
+// Capacity satisfies the Capacity function on the target.CapacityReporter
+// interface. Managed Instance Groups don't have a max size, so the capacity
+// is limited by the instances quota of their region.
+func (t *TargetPlugin) Capacity(config map[string]string) (*sdk.TargetCapacity, error) {
+	group, err := t.calculateMIG(config)
+	if err != nil {
+		return nil, err
+	}
+
+	suspend, err := t.suspendEnabled(config)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx := context.Background()
+
+	quotas, err := group.quotas(ctx, t.service)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get GCE region quotas: %v", err)
+	}
+
+	remaining := quotaRemaining(quotas, quotaMetricInstances)
+	if remaining == nil {
+		return nil, nil
+	}
+
+	// Suspended instances already count towards the quota, so resuming them
+	// doesn't use any of the remaining quota.
+	if suspend {
+		status, err := group.status(ctx, t.service)
+		if err != nil {
+			return nil, fmt.Errorf("failed to describe GCE Managed Instance Group: %v", err)
+		}
+		*remaining += status.targetSuspendedSize
+	}
+
+	return &sdk.TargetCapacity{Remaining: remaining}, nil
+}
+
 func (t *TargetPlugin) calculateDirection(migTarget, strategyDesired int64) (int64, string) {
 	if strategyDesired < migTarget {
 		return migTarget - strategyDesired, "in"
@@ -209,6 +280,21 @@ func (t *TargetPlugin) calculateMIG(config map[string]string) (instanceGroup, er
 	}
 }
 
+// suspendEnabled returns whether instances are suspended and resumed instead
+// of deleted and created when scaling.
+func (t *TargetPlugin) suspendEnabled(config map[string]string) (bool, error) {
+	v, ok := t.getValue(config, configKeySuspendInstances)
+	if !ok {
+		return false, nil
+	}
+
+	suspend, err := strconv.ParseBool(v)
+	if err != nil {
+		return false, fmt.Errorf("invalid value for %s: %v", configKeySuspendInstances, err)
+	}
+	return suspend, nil
+}
+
 func (t *TargetPlugin) getValue(config map[string]string, name string) (string, bool) {
 	v, ok := config[name]
 	if ok {
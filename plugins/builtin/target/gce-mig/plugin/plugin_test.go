@@ -50,3 +50,51 @@ func TestTargetPlugin_calculateDirection(t *testing.T) {
 		})
 	}
 }
+
+func TestTargetPlugin_suspendEnabled(t *testing.T) {
+	testCases := []struct {
+		inputPluginConfig map[string]string
+		inputConfig       map[string]string
+		expectedOutput    bool
+		expectedError     string
+		name              string
+	}{
+		{
+			inputPluginConfig: map[string]string{},
+			inputConfig:       map[string]string{},
+			expectedOutput:    false,
+			name:              "disabled by default",
+		},
+		{
+			inputPluginConfig: map[string]string{"suspend_instances": "true"},
+			inputConfig:       map[string]string{},
+			expectedOutput:    true,
+			name:              "plugin config",
+		},
+		{
+			inputPluginConfig: map[string]string{"suspend_instances": "true"},
+			inputConfig:       map[string]string{"suspend_instances": "false"},
+			expectedOutput:    false,
+			name:              "target config overrides plugin config",
+		},
+		{
+			inputPluginConfig: map[string]string{},
+			inputConfig:       map[string]string{"suspend_instances": "maybe"},
+			expectedError:     `invalid value for suspend_instances: strconv.ParseBool: parsing "maybe": invalid syntax`,
+			name:              "invalid value",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tp := TargetPlugin{config: tc.inputPluginConfig}
+			actualOutput, actualErr := tp.suspendEnabled(tc.inputConfig)
+			if tc.expectedError != "" {
+				assert.EqualError(t, actualErr, tc.expectedError, tc.name)
+				return
+			}
+			assert.NoError(t, actualErr, tc.name)
+			assert.Equal(t, tc.expectedOutput, actualOutput, tc.name)
+		})
+	}
+}
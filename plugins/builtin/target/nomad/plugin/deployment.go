@@ -0,0 +1,183 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package nomad
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/hashicorp/nomad/api"
+)
+
+const (
+	// configKeyWaitForDeployment, when set to true, makes Scale block until
+	// the deployment triggered by the scaling action has finished. This
+	// respects the health checks defined in the group update and migrate
+	// blocks and delays the start of the policy cooldown until the new
+	// count is healthy.
+	configKeyWaitForDeployment = "wait_for_deployment"
+
+	// configKeyWaitForDeploymentTimeout is the maximum amount of time to wait
+	// for the deployment to finish.
+	configKeyWaitForDeploymentTimeout = "wait_for_deployment_timeout"
+
+	// configKeyDeferScaleInDuringDeployment, when set to true, makes Scale
+	// skip scale in actions while the job has an active deployment, so
+	// allocations are not stopped in the middle of a rolling update. The
+	// action is deferred to the next policy evaluations until the deployment
+	// completes.
+	configKeyDeferScaleInDuringDeployment = "defer_scale_in_during_deployment"
+
+	defaultWaitForDeploymentTimeout = 10 * time.Minute
+
+	// deploymentPollInterval is the interval at which the evaluation and
+	// deployment are read while waiting.
+	deploymentPollInterval = 5 * time.Second
+)
+
+// deploymentWaitConfig parses the deployment wait options from the target
+// config. A zero timeout indicates that waiting is disabled.
+func deploymentWaitConfig(config map[string]string) (time.Duration, error) {
+	raw, ok := config[configKeyWaitForDeployment]
+	if !ok || raw == "" {
+		return 0, nil
+	}
+
+	wait, err := strconv.ParseBool(raw)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value for %s: %v", configKeyWaitForDeployment, err)
+	}
+	if !wait {
+		return 0, nil
+	}
+
+	timeout := defaultWaitForDeploymentTimeout
+	if raw, ok := config[configKeyWaitForDeploymentTimeout]; ok && raw != "" {
+		timeout, err = time.ParseDuration(raw)
+		if err != nil {
+			return 0, fmt.Errorf("invalid value for %s: %v", configKeyWaitForDeploymentTimeout, err)
+		}
+		if timeout <= 0 {
+			return 0, fmt.Errorf("invalid value for %s: must be positive", configKeyWaitForDeploymentTimeout)
+		}
+	}
+
+	return timeout, nil
+}
+
+// deferScaleInConfig parses the option to defer scale in actions during
+// deployments from the target config.
+func deferScaleInConfig(config map[string]string) (bool, error) {
+	raw, ok := config[configKeyDeferScaleInDuringDeployment]
+	if !ok || raw == "" {
+		return false, nil
+	}
+
+	deferScaleIn, err := strconv.ParseBool(raw)
+	if err != nil {
+		return false, fmt.Errorf("invalid value for %s: %v", configKeyDeferScaleInDuringDeployment, err)
+	}
+	return deferScaleIn, nil
+}
+
+// activeDeployment returns the latest deployment of the job if it's still in
+// progress, or nil otherwise.
+func (t *TargetPlugin) activeDeployment(jobID, namespace string) (*api.Deployment, error) {
+	deployment, _, err := t.client.Jobs().LatestDeployment(jobID, &api.QueryOptions{Namespace: namespace})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read latest deployment of job %s: %v", jobID, err)
+	}
+	if deployment == nil {
+		return nil, nil
+	}
+
+	switch deployment.Status {
+	case api.DeploymentStatusRunning, api.DeploymentStatusPaused, api.DeploymentStatusPending,
+		api.DeploymentStatusBlocked, api.DeploymentStatusUnblocking:
+		return deployment, nil
+	default:
+		return nil, nil
+	}
+}
+
+// waitForDeployment blocks until the deployment created by the evaluation
+// has finished. Evaluations which do not create a deployment, such as scaling
+// a batch job, return as soon as the evaluation has been processed. Failed or
+// cancelled deployments are returned as errors so they are reported as
+// scaling failures.
+func (t *TargetPlugin) waitForDeployment(evalID, namespace string, timeout time.Duration) error {
+	if evalID == "" {
+		return nil
+	}
+
+	q := &api.QueryOptions{Namespace: namespace}
+	deadline := time.Now().Add(timeout)
+
+	// Wait for the scheduler to process the evaluation so we know whether a
+	// deployment was created.
+	var deploymentID string
+	for {
+		eval, _, err := t.client.Evaluations().Info(evalID, q)
+		if err != nil {
+			return fmt.Errorf("failed to read evaluation %s: %v", evalID, err)
+		}
+
+		switch eval.Status {
+		case api.EvalStatusComplete:
+		case api.EvalStatusFailed, api.EvalStatusCancelled:
+			return fmt.Errorf("evaluation %s %s: %s", evalID, eval.Status, eval.StatusDescription)
+		default:
+			if err := t.pollWait(deadline, "evaluation", evalID); err != nil {
+				return err
+			}
+			continue
+		}
+
+		deploymentID = eval.DeploymentID
+		break
+	}
+
+	if deploymentID == "" {
+		t.logger.Debug("scaling action did not create a deployment", "eval_id", evalID)
+		return nil
+	}
+
+	t.logger.Debug("waiting for deployment", "deployment_id", deploymentID, "timeout", timeout)
+
+	for {
+		deployment, _, err := t.client.Deployments().Info(deploymentID, q)
+		if err != nil {
+			return fmt.Errorf("failed to read deployment %s: %v", deploymentID, err)
+		}
+
+		switch deployment.Status {
+		case api.DeploymentStatusSuccessful:
+			t.logger.Debug("deployment successful", "deployment_id", deploymentID)
+			return nil
+		case api.DeploymentStatusFailed, api.DeploymentStatusCancelled:
+			return fmt.Errorf("deployment %s %s: %s",
+				deploymentID, deployment.Status, deployment.StatusDescription)
+		}
+
+		if err := t.pollWait(deadline, "deployment", deploymentID); err != nil {
+			return err
+		}
+	}
+}
+
+// pollWait sleeps until the next poll, returning an error if the deadline
+// would be exceeded.
+func (t *TargetPlugin) pollWait(deadline time.Time, kind, id string) error {
+	interval := t.deploymentPollInterval
+	if interval == 0 {
+		interval = deploymentPollInterval
+	}
+
+	if time.Now().Add(interval).After(deadline) {
+		return fmt.Errorf("timeout waiting for %s %s to complete", kind, id)
+	}
+	time.Sleep(interval)
+	return nil
+}
@@ -0,0 +1,241 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package nomad
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	hclog "github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/nomad-autoscaler/sdk"
+	"github.com/hashicorp/nomad/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_deploymentWaitConfig(t *testing.T) {
+	testCases := []struct {
+		name            string
+		config          map[string]string
+		expectedTimeout time.Duration
+		expectedError   string
+	}{
+		{
+			name:            "not set",
+			config:          map[string]string{},
+			expectedTimeout: 0,
+		},
+		{
+			name:            "disabled",
+			config:          map[string]string{"wait_for_deployment": "false"},
+			expectedTimeout: 0,
+		},
+		{
+			name:            "default timeout",
+			config:          map[string]string{"wait_for_deployment": "true"},
+			expectedTimeout: defaultWaitForDeploymentTimeout,
+		},
+		{
+			name: "custom timeout",
+			config: map[string]string{
+				"wait_for_deployment":         "true",
+				"wait_for_deployment_timeout": "2m",
+			},
+			expectedTimeout: 2 * time.Minute,
+		},
+		{
+			name:          "invalid bool",
+			config:        map[string]string{"wait_for_deployment": "maybe"},
+			expectedError: "invalid value for wait_for_deployment",
+		},
+		{
+			name: "negative timeout",
+			config: map[string]string{
+				"wait_for_deployment":         "true",
+				"wait_for_deployment_timeout": "-1m",
+			},
+			expectedError: "must be positive",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			timeout, err := deploymentWaitConfig(tc.config)
+			if tc.expectedError != "" {
+				assert.ErrorContains(t, err, tc.expectedError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedTimeout, timeout)
+		})
+	}
+}
+
+func TestTargetPlugin_scaleWaitForDeployment(t *testing.T) {
+	testCases := []struct {
+		name             string
+		deploymentID     string
+		deploymentStatus []string
+		expectedError    string
+	}{
+		{
+			name:         "no deployment",
+			deploymentID: "",
+		},
+		{
+			name:             "successful deployment",
+			deploymentID:     "d1",
+			deploymentStatus: []string{api.DeploymentStatusRunning, api.DeploymentStatusSuccessful},
+		},
+		{
+			name:             "failed deployment",
+			deploymentID:     "d1",
+			deploymentStatus: []string{api.DeploymentStatusRunning, api.DeploymentStatusFailed},
+			expectedError:    "deployment d1 failed",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			evalReads, deploymentReads := 0, 0
+
+			nomadMock := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch r.URL.Path {
+				case "/v1/job/example/scale":
+					_ = json.NewEncoder(w).Encode(api.JobRegisterResponse{EvalID: "e1"})
+				case "/v1/evaluation/e1":
+					// Report the evaluation as pending on the first read.
+					status := api.EvalStatusComplete
+					if evalReads == 0 {
+						status = api.EvalStatusPending
+					}
+					evalReads++
+					_ = json.NewEncoder(w).Encode(api.Evaluation{
+						ID:           "e1",
+						Status:       status,
+						DeploymentID: tc.deploymentID,
+					})
+				case "/v1/deployment/d1":
+					status := tc.deploymentStatus[deploymentReads]
+					deploymentReads++
+					_ = json.NewEncoder(w).Encode(api.Deployment{ID: "d1", Status: status})
+				default:
+					t.Errorf("unexpected request to %s", r.URL.Path)
+				}
+			}))
+			defer nomadMock.Close()
+
+			plugin := PluginConfig.Factory(hclog.NewNullLogger()).(*TargetPlugin)
+			plugin.deploymentPollInterval = time.Millisecond
+			require.NoError(t, plugin.SetConfig(map[string]string{"nomad_address": nomadMock.URL}))
+
+			config := map[string]string{"Job": "example", "Group": "cache", "wait_for_deployment": "true"}
+			err := plugin.Scale(sdk.ScalingAction{Count: 2, Meta: map[string]interface{}{}}, config)
+			if tc.expectedError != "" {
+				assert.ErrorContains(t, err, tc.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, 2, evalReads)
+			assert.Equal(t, len(tc.deploymentStatus), deploymentReads)
+		})
+	}
+}
+
+func TestTargetPlugin_scaleDeferScaleInDuringDeployment(t *testing.T) {
+	testCases := []struct {
+		name          string
+		direction     sdk.ScaleDirection
+		deployment    *api.Deployment
+		config        map[string]string
+		expectedScale bool
+		expectedNoOp  bool
+		expectedError string
+	}{
+		{
+			name:          "scale in during deployment",
+			direction:     sdk.ScaleDirectionDown,
+			deployment:    &api.Deployment{ID: "d1", Status: api.DeploymentStatusRunning},
+			config:        map[string]string{"defer_scale_in_during_deployment": "true"},
+			expectedNoOp:  true,
+			expectedError: "deferring scale in of group example/cache until deployment d1 completes",
+		},
+		{
+			name:          "scale in after deployment",
+			direction:     sdk.ScaleDirectionDown,
+			deployment:    &api.Deployment{ID: "d1", Status: api.DeploymentStatusSuccessful},
+			config:        map[string]string{"defer_scale_in_during_deployment": "true"},
+			expectedScale: true,
+		},
+		{
+			name:          "scale in without deployments",
+			direction:     sdk.ScaleDirectionDown,
+			config:        map[string]string{"defer_scale_in_during_deployment": "true"},
+			expectedScale: true,
+		},
+		{
+			name:          "scale out during deployment",
+			direction:     sdk.ScaleDirectionUp,
+			deployment:    &api.Deployment{ID: "d1", Status: api.DeploymentStatusRunning},
+			config:        map[string]string{"defer_scale_in_during_deployment": "true"},
+			expectedScale: true,
+		},
+		{
+			name:          "disabled",
+			direction:     sdk.ScaleDirectionDown,
+			deployment:    &api.Deployment{ID: "d1", Status: api.DeploymentStatusRunning},
+			config:        map[string]string{},
+			expectedScale: true,
+		},
+		{
+			name:          "invalid config",
+			direction:     sdk.ScaleDirectionDown,
+			config:        map[string]string{"defer_scale_in_during_deployment": "maybe"},
+			expectedError: "invalid value for defer_scale_in_during_deployment",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			scaled := false
+
+			nomadMock := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				// Query responses must include the index headers.
+				w.Header().Set("X-Nomad-Index", "1")
+				w.Header().Set("X-Nomad-LastContact", "0")
+
+				switch r.URL.Path {
+				case "/v1/job/example/deployment":
+					_ = json.NewEncoder(w).Encode(tc.deployment)
+				case "/v1/job/example/scale":
+					scaled = true
+					_ = json.NewEncoder(w).Encode(api.JobRegisterResponse{EvalID: "e1"})
+				default:
+					t.Errorf("unexpected request to %s", r.URL.Path)
+				}
+			}))
+			defer nomadMock.Close()
+
+			plugin := PluginConfig.Factory(hclog.NewNullLogger()).(*TargetPlugin)
+			require.NoError(t, plugin.SetConfig(map[string]string{"nomad_address": nomadMock.URL}))
+
+			tc.config["Job"] = "example"
+			tc.config["Group"] = "cache"
+			action := sdk.ScalingAction{Count: 2, Direction: tc.direction, Meta: map[string]interface{}{}}
+
+			err := plugin.Scale(action, tc.config)
+			if tc.expectedError != "" {
+				assert.ErrorContains(t, err, tc.expectedError)
+				_, noOp := err.(*sdk.TargetScalingNoOpError)
+				assert.Equal(t, tc.expectedNoOp, noOp)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tc.expectedScale, scaled)
+		})
+	}
+}
@@ -0,0 +1,228 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package nomad
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/nomad-autoscaler/sdk"
+	"github.com/hashicorp/nomad/api"
+)
+
+const (
+	// configKeyDispatch, when set to true, makes the target control the
+	// number of running dispatched children of the parameterized job
+	// identified by Job, instead of the count of a group. Scaling out
+	// dispatches new children and scaling in stops running ones, which
+	// allows building queue workers on top of dispatch jobs.
+	configKeyDispatch = "dispatch"
+
+	// configKeyDispatchMeta is a comma separated list of key=value pairs
+	// used as the meta of dispatched children.
+	configKeyDispatchMeta = "dispatch_meta"
+
+	// configKeyDispatchPayload is the payload of dispatched children.
+	configKeyDispatchPayload = "dispatch_payload"
+
+	// jobStatusPending and jobStatusDead are the Nomad job statuses used to
+	// identify running children.
+	jobStatusPending = "pending"
+	jobStatusDead    = "dead"
+)
+
+// dispatchConfig parses the option to scale the dispatched children of a
+// parameterized job from the target config.
+func dispatchConfig(config map[string]string) (bool, error) {
+	raw, ok := config[configKeyDispatch]
+	if !ok || raw == "" {
+		return false, nil
+	}
+
+	dispatch, err := strconv.ParseBool(raw)
+	if err != nil {
+		return false, fmt.Errorf("invalid value for %s: %v", configKeyDispatch, err)
+	}
+	return dispatch, nil
+}
+
+// dispatchStatus returns the number of running dispatched children of the
+// parameterized job as the target count.
+func (t *TargetPlugin) dispatchStatus(config map[string]string) (*sdk.TargetStatus, error) {
+	jobID := config[configKeyJobID]
+	if jobID == "" {
+		return nil, fmt.Errorf("required config key %q not found", configKeyJobID)
+	}
+
+	q := api.QueryOptions{Namespace: config[configKeyNamespace]}
+
+	job, _, err := t.client.Jobs().Info(jobID, &q)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read job %s: %v", jobID, err)
+	}
+	if job.ParameterizedJob == nil {
+		return nil, fmt.Errorf("job %s is not a parameterized job", jobID)
+	}
+
+	children, err := t.dispatchedChildren(jobID, &q)
+	if err != nil {
+		return nil, err
+	}
+
+	stopped := job.Stop != nil && *job.Stop
+
+	resp := sdk.TargetStatus{
+		Ready: !stopped,
+		Meta: map[string]string{
+			metaKeyPrefix + jobID + metaKeyJobStoppedSuffix: strconv.FormatBool(stopped),
+		},
+	}
+
+	// Dispatching and stopping children both update their submit time, so
+	// use the latest one to enforce the cooldown.
+	var lastEvent int64
+	for _, child := range children {
+		if child.SubmitTime > lastEvent {
+			lastEvent = child.SubmitTime
+		}
+		if !isRunningChild(child) {
+			continue
+		}
+		resp.Count++
+		if child.Status == jobStatusPending {
+			resp.Meta[sdk.TargetStatusMetaKeyInProgress] = "dispatch"
+		}
+	}
+	if lastEvent > 0 {
+		resp.Meta[sdk.TargetStatusMetaKeyLastEvent] = strconv.FormatInt(lastEvent, 10)
+	}
+
+	return &resp, nil
+}
+
+// scaleDispatch dispatches or stops children of the parameterized job until
+// the number of running children matches the action count. Pending children
+// are stopped first, followed by the most recently dispatched ones, so the
+// children which have made the most progress are kept.
+func (t *TargetPlugin) scaleDispatch(action sdk.ScalingAction, config map[string]string) error {
+	jobID := config[configKeyJobID]
+	if jobID == "" {
+		return fmt.Errorf("required config key %q not found", configKeyJobID)
+	}
+
+	if action.Count == sdk.StrategyActionMetaValueDryRunCount {
+		t.logger.Info("skipping job dispatch in dry-run mode", "job", jobID)
+		return nil
+	}
+
+	meta, err := parseDispatchMeta(config[configKeyDispatchMeta])
+	if err != nil {
+		return err
+	}
+
+	var payload []byte
+	if p := config[configKeyDispatchPayload]; p != "" {
+		payload = []byte(p)
+	}
+
+	q := api.QueryOptions{Namespace: config[configKeyNamespace]}
+	w := api.WriteOptions{Namespace: config[configKeyNamespace]}
+
+	children, err := t.dispatchedChildren(jobID, &q)
+	if err != nil {
+		return err
+	}
+
+	var running []*api.JobListStub
+	for _, child := range children {
+		if isRunningChild(child) {
+			running = append(running, child)
+		}
+	}
+
+	switch diff := action.Count - int64(len(running)); {
+	case diff > 0:
+		for i := int64(0); i < diff; i++ {
+			resp, _, err := t.client.Jobs().Dispatch(jobID, meta, payload, "", &w)
+			if err != nil {
+				return fmt.Errorf("failed to dispatch job %s: %v", jobID, err)
+			}
+			t.logger.Debug("dispatched job", "job", jobID, "child", resp.DispatchedJobID)
+		}
+		t.logger.Info("dispatched jobs", "job", jobID, "count", diff, "reason", action.Reason)
+
+	case diff < 0:
+		sortChildrenForStop(running)
+		for _, child := range running[:-diff] {
+			if _, _, err := t.client.Jobs().Deregister(child.ID, false, &w); err != nil {
+				return fmt.Errorf("failed to stop dispatched job %s: %v", child.ID, err)
+			}
+			t.logger.Debug("stopped dispatched job", "job", jobID, "child", child.ID)
+		}
+		t.logger.Info("stopped dispatched jobs", "job", jobID, "count", -diff, "reason", action.Reason)
+	}
+
+	return nil
+}
+
+// dispatchedChildren lists the jobs dispatched from the parameterized job,
+// including stopped and completed ones.
+func (t *TargetPlugin) dispatchedChildren(jobID string, q *api.QueryOptions) ([]*api.JobListStub, error) {
+	opts := *q
+	opts.Prefix = jobID + api.JobDispatchLaunchSuffix
+
+	jobs, _, err := t.client.Jobs().List(&opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list dispatched jobs of %s: %v", jobID, err)
+	}
+
+	// The prefix may match jobs which are not children of the job, such as
+	// children of another job with a similar ID.
+	children := make([]*api.JobListStub, 0, len(jobs))
+	for _, j := range jobs {
+		if j.ParentID == jobID {
+			children = append(children, j)
+		}
+	}
+	return children, nil
+}
+
+// isRunningChild identifies whether the dispatched job is pending or running.
+func isRunningChild(j *api.JobListStub) bool {
+	return !j.Stop && j.Status != jobStatusDead
+}
+
+// sortChildrenForStop sorts the children in the order they should be
+// stopped: pending children first, then the most recently dispatched.
+func sortChildrenForStop(children []*api.JobListStub) {
+	sort.SliceStable(children, func(i, j int) bool {
+		iPending := children[i].Status == jobStatusPending
+		jPending := children[j].Status == jobStatusPending
+		if iPending != jPending {
+			return iPending
+		}
+		return children[i].SubmitTime > children[j].SubmitTime
+	})
+}
+
+// parseDispatchMeta parses the dispatch_meta config value, which is a comma
+// separated list of key=value pairs such as "queue=emails,priority=high".
+func parseDispatchMeta(s string) (map[string]string, error) {
+	if strings.TrimSpace(s) == "" {
+		return nil, nil
+	}
+
+	meta := make(map[string]string)
+	for _, entry := range strings.Split(s, ",") {
+		k, v, ok := strings.Cut(entry, "=")
+		k = strings.TrimSpace(k)
+		if !ok || k == "" {
+			return nil, fmt.Errorf("invalid %s entry %q, must be in the form <key>=<value>", configKeyDispatchMeta, entry)
+		}
+		meta[k] = strings.TrimSpace(v)
+	}
+	return meta, nil
+}
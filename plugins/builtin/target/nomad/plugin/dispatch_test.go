@@ -0,0 +1,145 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package nomad
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	hclog "github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/nomad-autoscaler/sdk"
+	"github.com/hashicorp/nomad-autoscaler/sdk/helper/ptr"
+	"github.com/hashicorp/nomad/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testDispatchNomad returns a Nomad API mock serving the parameterized job
+// "worker" and its dispatched children. It records the dispatched and
+// stopped jobs.
+func testDispatchNomad(t *testing.T) (*httptest.Server, *[]string, *[]string) {
+	var lock sync.Mutex
+	var dispatched, stopped []string
+
+	children := []*api.JobListStub{
+		{ID: "worker/dispatch-1", ParentID: "worker", Status: "running", SubmitTime: 100},
+		{ID: "worker/dispatch-2", ParentID: "worker", Status: "running", SubmitTime: 300},
+		{ID: "worker/dispatch-3", ParentID: "worker", Status: "pending", SubmitTime: 200},
+		{ID: "worker/dispatch-4", ParentID: "worker", Status: "dead", SubmitTime: 400},
+		{ID: "worker/dispatch-5", ParentID: "worker", Status: "running", Stop: true, SubmitTime: 50},
+		{ID: "worker/dispatch-other", ParentID: "worker/dispatch", Status: "running", SubmitTime: 500},
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lock.Lock()
+		defer lock.Unlock()
+
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/v1/job/worker":
+			_ = json.NewEncoder(w).Encode(&api.Job{
+				ID:               ptr.Of("worker"),
+				ParameterizedJob: &api.ParameterizedJobConfig{},
+			})
+		case r.Method == http.MethodGet && r.URL.Path == "/v1/jobs":
+			assert.Equal(t, "worker/dispatch-", r.URL.Query().Get("prefix"))
+			_ = json.NewEncoder(w).Encode(children)
+		case r.URL.Path == "/v1/job/worker/dispatch":
+			var req api.JobDispatchRequest
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+			assert.Equal(t, map[string]string{"queue": "emails"}, req.Meta)
+			dispatched = append(dispatched, req.JobID)
+			_ = json.NewEncoder(w).Encode(api.JobDispatchResponse{DispatchedJobID: "worker/dispatch-new"})
+		case r.Method == http.MethodDelete:
+			stopped = append(stopped, r.URL.Path)
+			_ = json.NewEncoder(w).Encode(api.JobDeregisterResponse{})
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}))
+
+	return srv, &dispatched, &stopped
+}
+
+func TestTargetPlugin_dispatchStatus(t *testing.T) {
+	nomadMock, _, _ := testDispatchNomad(t)
+	defer nomadMock.Close()
+
+	plugin := PluginConfig.Factory(hclog.NewNullLogger()).(*TargetPlugin)
+	require.NoError(t, plugin.SetConfig(map[string]string{"nomad_address": nomadMock.URL}))
+
+	status, err := plugin.Status(map[string]string{"Job": "worker", "dispatch": "true"})
+	require.NoError(t, err)
+	assert.Equal(t, &sdk.TargetStatus{
+		Ready: true,
+		Count: 3,
+		Meta: map[string]string{
+			"nomad_autoscaler.target.nomad.worker.stopped": "false",
+			sdk.TargetStatusMetaKeyLastEvent:               "400",
+			sdk.TargetStatusMetaKeyInProgress:              "dispatch",
+		},
+	}, status)
+
+	_, err = plugin.Status(map[string]string{"Job": "worker", "dispatch": "maybe"})
+	assert.ErrorContains(t, err, "invalid value for dispatch")
+}
+
+func TestTargetPlugin_dispatchScale(t *testing.T) {
+	testCases := []struct {
+		name               string
+		count              int64
+		expectedDispatched int
+		expectedStopped    []string
+	}{
+		{
+			name:               "scale out",
+			count:              5,
+			expectedDispatched: 2,
+		},
+		{
+			name:            "scale in",
+			count:           1,
+			expectedStopped: []string{"/v1/job/worker/dispatch-3", "/v1/job/worker/dispatch-2"},
+		},
+		{
+			name:  "no change",
+			count: 3,
+		},
+		{
+			name:  "dry-run",
+			count: sdk.StrategyActionMetaValueDryRunCount,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			nomadMock, dispatched, stopped := testDispatchNomad(t)
+			defer nomadMock.Close()
+
+			plugin := PluginConfig.Factory(hclog.NewNullLogger()).(*TargetPlugin)
+			require.NoError(t, plugin.SetConfig(map[string]string{"nomad_address": nomadMock.URL}))
+
+			config := map[string]string{"Job": "worker", "dispatch": "true", "dispatch_meta": "queue=emails"}
+			require.NoError(t, plugin.Scale(sdk.ScalingAction{Count: tc.count}, config))
+
+			assert.Len(t, *dispatched, tc.expectedDispatched)
+			assert.Equal(t, tc.expectedStopped, *stopped)
+		})
+	}
+}
+
+func Test_parseDispatchMeta(t *testing.T) {
+	meta, err := parseDispatchMeta("queue = emails, priority=high")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"queue": "emails", "priority": "high"}, meta)
+
+	meta, err = parseDispatchMeta("")
+	require.NoError(t, err)
+	assert.Nil(t, meta)
+
+	_, err = parseDispatchMeta("queue")
+	assert.ErrorContains(t, err, `invalid dispatch_meta entry "queue"`)
+}
@@ -71,6 +71,10 @@ type TargetPlugin struct {
 	// gcRunning indicates whether the GC loop is running or not.
 	gcRunning     bool
 	gcRunningLock sync.RWMutex
+
+	// deploymentPollInterval overrides the interval used when waiting for
+	// deployments. It is only expected to be set in tests.
+	deploymentPollInterval time.Duration
 }
 
 // namespacedJobID encapsulates the namespace and jobID, which together make a
@@ -124,6 +128,28 @@ func (t *TargetPlugin) PluginInfo() (*base.PluginInfo, error) {
 
 // Scale satisfies the Scale function on the target.Target interface.
 func (t *TargetPlugin) Scale(action sdk.ScalingAction, config map[string]string) error {
+	if isTaskResourceTarget(config) {
+		return t.scaleTaskResource(action, config)
+	}
+
+	dispatch, err := dispatchConfig(config)
+	if err != nil {
+		return err
+	}
+	if dispatch {
+		return t.scaleDispatch(action, config)
+	}
+
+	waitTimeout, err := deploymentWaitConfig(config)
+	if err != nil {
+		return err
+	}
+
+	deferScaleIn, err := deferScaleInConfig(config)
+	if err != nil {
+		return err
+	}
+
 	var countIntPtr *int
 	if action.Count != sdk.StrategyActionMetaValueDryRunCount {
 		countInt := int(action.Count)
@@ -139,10 +165,24 @@ func (t *TargetPlugin) Scale(action sdk.ScalingAction, config map[string]string)
 		q.Namespace = namespace
 	}
 
-	_, _, err := t.client.Jobs().Scale(config[configKeyJobID],
+	// Scaling in during a rolling update stops allocations the deployment
+	// may be relying on, so wait for the deployment to complete. Dry-run
+	// actions don't change the group so they are not deferred.
+	if deferScaleIn && action.Direction == sdk.ScaleDirectionDown && countIntPtr != nil {
+		deployment, err := t.activeDeployment(config[configKeyJobID], q.Namespace)
+		if err != nil {
+			return fmt.Errorf("failed to scale group %s/%s: %v", config[configKeyJobID], config[configKeyGroup], err)
+		}
+		if deployment != nil {
+			return sdk.NewTargetScalingNoOpError("deferring scale in of group %s/%s until deployment %s completes",
+				config[configKeyJobID], config[configKeyGroup], deployment.ID)
+		}
+	}
+
+	resp, _, err := t.client.Jobs().Scale(config[configKeyJobID],
 		config[configKeyGroup],
 		countIntPtr,
-		action.Reason,
+		scaleMessage(action),
 		action.Error,
 		action.Meta,
 		&q)
@@ -156,11 +196,40 @@ func (t *TargetPlugin) Scale(action sdk.ScalingAction, config map[string]string)
 		}
 		return fmt.Errorf("failed to scale group %s/%s: %v", config[configKeyJobID], config[configKeyGroup], err)
 	}
+
+	// Dry-run actions don't change the group so there is nothing to wait for.
+	if waitTimeout > 0 && countIntPtr != nil && resp != nil {
+		if err := t.waitForDeployment(resp.EvalID, q.Namespace, waitTimeout); err != nil {
+			return fmt.Errorf("failed to scale group %s/%s: %v", config[configKeyJobID], config[configKeyGroup], err)
+		}
+	}
 	return nil
 }
 
+// scaleMessage returns the message of the Nomad scaling event for the action.
+// It includes the check and strategy which produced the action when known, so
+// the job scaling events explain why the count changed.
+func scaleMessage(action sdk.ScalingAction) string {
+	check, strategy := action.CheckDetails()
+	if check == "" {
+		return action.Reason
+	}
+	return fmt.Sprintf("%s (check: %s, strategy: %s)", action.Reason, check, strategy)
+}
+
 // Status satisfies the Status function on the target.Target interface.
 func (t *TargetPlugin) Status(config map[string]string) (*sdk.TargetStatus, error) {
+	if isTaskResourceTarget(config) {
+		return t.taskResourceStatus(config)
+	}
+
+	dispatch, err := dispatchConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	if dispatch {
+		return t.dispatchStatus(config)
+	}
 
 	// Get the JobID from the config map. This is a required param and results
 	// in an error if not found or is an empty string.
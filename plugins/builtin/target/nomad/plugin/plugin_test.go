@@ -145,3 +145,11 @@ func scaleStatusHandler(w http.ResponseWriter, r *http.Request) {
 func scaleStatusErrorHandler(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusInternalServerError)
 }
+
+func Test_scaleMessage(t *testing.T) {
+	action := sdk.ScalingAction{Reason: "scaling up because factor is 1.5"}
+	assert.Equal(t, "scaling up because factor is 1.5", scaleMessage(action))
+
+	action.SetCheckDetails("cpu", "target-value", nil)
+	assert.Equal(t, "scaling up because factor is 1.5 (check: cpu, strategy: target-value)", scaleMessage(action))
+}
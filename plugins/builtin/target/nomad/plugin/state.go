@@ -25,6 +25,10 @@ const (
 	// metaKeyJobStoppedSuffix is the key suffix used when adding a meta item
 	// to the status response detailing the jobs current stopped status.
 	metaKeyJobStoppedSuffix = ".stopped"
+
+	// metaValueInProgressPlacement is the sdk.TargetStatusMetaKeyInProgress
+	// value used when allocations of the task group are still to be placed.
+	metaValueInProgressPlacement = "allocation_placement"
 )
 
 var (
@@ -152,6 +156,15 @@ func (jsh *jobScaleStatusHandler) status(group string) (*sdk.TargetStatus, error
 		resp.Meta[sdk.TargetStatusMetaKeyLastEvent] = strconv.FormatUint(status.Events[0].Time, 10)
 	}
 
+	// Surface the allocations that are still being placed and those which
+	// are unhealthy, so operators have the context of the job.
+	if status.Placed < status.Desired {
+		resp.Meta[sdk.TargetStatusMetaKeyInProgress] = metaValueInProgressPlacement
+	}
+	if status.Unhealthy > 0 {
+		resp.Meta[sdk.TargetStatusMetaKeyUnhealthy] = strconv.Itoa(status.Unhealthy)
+	}
+
 	return &resp, nil
 }
 
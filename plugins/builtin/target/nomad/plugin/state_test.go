@@ -138,6 +138,28 @@ func Test_jobStateHandler_status(t *testing.T) {
 			expectedError: nil,
 			name:          "job group found within scale status task groups and job is not running",
 		},
+		{
+			inputJSH: &jobScaleStatusHandler{
+				jobID: "cant-think-of-a-funny-name",
+				scaleStatus: &api.JobScaleStatusResponse{
+					TaskGroups: map[string]api.TaskGroupScaleStatus{
+						"this-does-exist": {Desired: 5, Placed: 4, Running: 4, Unhealthy: 1},
+					},
+				},
+			},
+			inputGroup: "this-does-exist",
+			expectedReturn: &sdk.TargetStatus{
+				Ready: true,
+				Count: 4,
+				Meta: map[string]string{
+					"nomad_autoscaler.target.nomad.cant-think-of-a-funny-name.stopped": "false",
+					"nomad_autoscaler.in_progress":                                     "allocation_placement",
+					"nomad_autoscaler.unhealthy":                                       "1",
+				},
+			},
+			expectedError: nil,
+			name:          "job group with allocations being placed and unhealthy",
+		},
 	}
 
 	for _, tc := range testCases {
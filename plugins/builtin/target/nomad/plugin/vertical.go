@@ -0,0 +1,155 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package nomad
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/hashicorp/nomad-autoscaler/sdk"
+	"github.com/hashicorp/nomad/api"
+)
+
+// isTaskResourceTarget identifies whether the target config relates to the
+// resources of a task, as used by vertical policies.
+func isTaskResourceTarget(config map[string]string) bool {
+	return config[sdk.TargetConfigKeyResource] != ""
+}
+
+// taskResourceStatus returns the current value of the task resource targeted
+// by a vertical policy as the target count.
+func (t *TargetPlugin) taskResourceStatus(config map[string]string) (*sdk.TargetStatus, error) {
+	job, task, err := t.readTask(config)
+	if err != nil {
+		return nil, err
+	}
+
+	value, err := taskResource(task, config[sdk.TargetConfigKeyResource])
+	if err != nil {
+		return nil, err
+	}
+
+	stopped := job.Stop != nil && *job.Stop
+
+	resp := sdk.TargetStatus{
+		Ready: !stopped,
+		Count: int64(value),
+		Meta: map[string]string{
+			metaKeyPrefix + *job.ID + metaKeyJobStoppedSuffix: strconv.FormatBool(stopped),
+		},
+	}
+
+	// Any job update replaces the allocations, so use the last submission of
+	// the job to enforce the cooldown.
+	if job.SubmitTime != nil {
+		resp.Meta[sdk.TargetStatusMetaKeyLastEvent] = strconv.FormatInt(*job.SubmitTime, 10)
+	}
+	if job.Version != nil {
+		resp.Meta[sdk.TargetStatusMetaKeyVersion] = strconv.FormatUint(*job.Version, 10)
+	}
+
+	return &resp, nil
+}
+
+// scaleTaskResource updates the task resource targeted by a vertical policy
+// to the action count and registers the updated job.
+func (t *TargetPlugin) scaleTaskResource(action sdk.ScalingAction, config map[string]string) error {
+	if action.Count == sdk.StrategyActionMetaValueDryRunCount {
+		t.logger.Info("skipping task resource update in dry-run mode",
+			"job", config[configKeyJobID], "group", config[configKeyGroup], "task", config[sdk.TargetConfigKeyTask])
+		return nil
+	}
+
+	job, task, err := t.readTask(config)
+	if err != nil {
+		return err
+	}
+
+	if task.Resources == nil {
+		task.Resources = &api.Resources{}
+	}
+
+	value := int(action.Count)
+	switch config[sdk.TargetConfigKeyResource] {
+	case sdk.TargetResourceCPU:
+		task.Resources.CPU = &value
+	case sdk.TargetResourceMemory:
+		task.Resources.MemoryMB = &value
+	default:
+		return fmt.Errorf("unsupported task resource %q", config[sdk.TargetConfigKeyResource])
+	}
+
+	// Enforce the index of the job we read, so concurrent updates of the job
+	// are not overwritten.
+	opts := &api.RegisterOptions{EnforceIndex: true}
+	if job.JobModifyIndex != nil {
+		opts.ModifyIndex = *job.JobModifyIndex
+	}
+
+	q := api.WriteOptions{Namespace: config[configKeyNamespace]}
+
+	if _, _, err := t.client.Jobs().RegisterOpts(job, opts, &q); err != nil {
+		return fmt.Errorf("failed to update task %s/%s/%s: %v",
+			config[configKeyJobID], config[configKeyGroup], config[sdk.TargetConfigKeyTask], err)
+	}
+
+	t.logger.Info("updated task resource", "job", config[configKeyJobID], "group", config[configKeyGroup],
+		"task", config[sdk.TargetConfigKeyTask], "resource", config[sdk.TargetConfigKeyResource],
+		"value", value, "reason", action.Reason)
+	return nil
+}
+
+// readTask reads the job identified by the target config and returns it along
+// with the targeted task.
+func (t *TargetPlugin) readTask(config map[string]string) (*api.Job, *api.Task, error) {
+	for _, k := range []string{configKeyJobID, configKeyGroup, sdk.TargetConfigKeyTask} {
+		if config[k] == "" {
+			return nil, nil, fmt.Errorf("required config key %q not found", k)
+		}
+	}
+
+	q := api.QueryOptions{Namespace: config[configKeyNamespace]}
+
+	job, _, err := t.client.Jobs().Info(config[configKeyJobID], &q)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read job %s: %v", config[configKeyJobID], err)
+	}
+
+	for _, tg := range job.TaskGroups {
+		if tg.Name == nil || *tg.Name != config[configKeyGroup] {
+			continue
+		}
+		for _, task := range tg.Tasks {
+			if task.Name == config[sdk.TargetConfigKeyTask] {
+				return job, task, nil
+			}
+		}
+		return nil, nil, fmt.Errorf("task %q not found in group %q", config[sdk.TargetConfigKeyTask], config[configKeyGroup])
+	}
+
+	return nil, nil, fmt.Errorf("task group %q not found", config[configKeyGroup])
+}
+
+// taskResource returns the value of the named task resource.
+func taskResource(task *api.Task, resource string) (int, error) {
+	var value *int
+
+	switch resource {
+	case sdk.TargetResourceCPU:
+		if task.Resources != nil {
+			value = task.Resources.CPU
+		}
+	case sdk.TargetResourceMemory:
+		if task.Resources != nil {
+			value = task.Resources.MemoryMB
+		}
+	default:
+		return 0, fmt.Errorf("unsupported task resource %q", resource)
+	}
+
+	if value == nil {
+		return 0, fmt.Errorf("task %q does not define resource %q", task.Name, resource)
+	}
+	return *value, nil
+}
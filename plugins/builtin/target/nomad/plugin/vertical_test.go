@@ -0,0 +1,92 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package nomad
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	hclog "github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/nomad-autoscaler/sdk"
+	"github.com/hashicorp/nomad-autoscaler/sdk/helper/ptr"
+	"github.com/hashicorp/nomad/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testVerticalJob() *api.Job {
+	cpu, mem := 500, 256
+	return &api.Job{
+		ID:             ptr.Of("example"),
+		SubmitTime:     ptr.Of(int64(1700000000000000000)),
+		Version:        ptr.Of(uint64(3)),
+		JobModifyIndex: ptr.Of(uint64(42)),
+		TaskGroups: []*api.TaskGroup{
+			{
+				Name: ptr.Of("cache"),
+				Tasks: []*api.Task{
+					{Name: "redis", Resources: &api.Resources{CPU: &cpu, MemoryMB: &mem}},
+				},
+			},
+		},
+	}
+}
+
+func TestTargetPlugin_verticalStatus(t *testing.T) {
+	nomadMock := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/job/example", r.URL.Path)
+		_ = json.NewEncoder(w).Encode(testVerticalJob())
+	}))
+	defer nomadMock.Close()
+
+	plugin := PluginConfig.Factory(hclog.NewNullLogger()).(*TargetPlugin)
+	require.NoError(t, plugin.SetConfig(map[string]string{"nomad_address": nomadMock.URL}))
+
+	config := map[string]string{"Job": "example", "Group": "cache", "Task": "redis", "Resource": "memory"}
+	status, err := plugin.Status(config)
+	require.NoError(t, err)
+	assert.Equal(t, &sdk.TargetStatus{
+		Ready: true,
+		Count: 256,
+		Meta: map[string]string{
+			"nomad_autoscaler.target.nomad.example.stopped": "false",
+			sdk.TargetStatusMetaKeyLastEvent:                "1700000000000000000",
+			sdk.TargetStatusMetaKeyVersion:                  "3",
+		},
+	}, status)
+
+	config["Task"] = "missing"
+	_, err = plugin.Status(config)
+	assert.ErrorContains(t, err, `task "missing" not found in group "cache"`)
+}
+
+func TestTargetPlugin_verticalScale(t *testing.T) {
+	var registered api.JobRegisterRequest
+
+	nomadMock := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			_ = json.NewEncoder(w).Encode(testVerticalJob())
+		case http.MethodPut, http.MethodPost:
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&registered))
+			_ = json.NewEncoder(w).Encode(api.JobRegisterResponse{})
+		}
+	}))
+	defer nomadMock.Close()
+
+	plugin := PluginConfig.Factory(hclog.NewNullLogger()).(*TargetPlugin)
+	require.NoError(t, plugin.SetConfig(map[string]string{"nomad_address": nomadMock.URL}))
+
+	config := map[string]string{"Job": "example", "Group": "cache", "Task": "redis", "Resource": "cpu"}
+	err := plugin.Scale(sdk.ScalingAction{Count: 750, Direction: sdk.ScaleDirectionUp}, config)
+	require.NoError(t, err)
+
+	require.NotNil(t, registered.Job)
+	assert.True(t, registered.EnforceIndex)
+	assert.Equal(t, uint64(42), registered.JobModifyIndex)
+	assert.Equal(t, 750, *registered.Job.TaskGroups[0].Tasks[0].Resources.CPU)
+	assert.Equal(t, 256, *registered.Job.TaskGroups[0].Tasks[0].Resources.MemoryMB)
+}
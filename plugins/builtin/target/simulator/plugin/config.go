@@ -0,0 +1,134 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package plugin
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+const (
+	// configKeyID identifies the simulated target. Policies using the same
+	// ID scale the same simulated target.
+	configKeyID = "id"
+
+	// configKeyInitialCount is the count of the simulated target when it is
+	// first used.
+	configKeyInitialCount = "initial_count"
+
+	// configKeyScaleLatency is the time the simulated target takes to reach
+	// the count of a scaling action. The count changes linearly during this
+	// time and the target is not ready.
+	configKeyScaleLatency = "scale_latency"
+
+	// configKeyFailureRate is the probability, between 0 and 1, of a scaling
+	// action failing without changing the count.
+	configKeyFailureRate = "failure_rate"
+
+	// configKeyPartialFailureRate is the probability, between 0 and 1, of a
+	// scaling action failing after applying half of the count change.
+	configKeyPartialFailureRate = "partial_failure_rate"
+
+	// configKeyMaxCapacity is the maximum count the simulated target can
+	// reach. Scaling actions above it are limited to it. A zero value
+	// doesn't limit the count.
+	configKeyMaxCapacity = "max_capacity"
+
+	// configKeyOutOfBandRate is the probability, between 0 and 1, of the
+	// count of the simulated target changing outside of the autoscaler each
+	// time its status is read.
+	configKeyOutOfBandRate = "out_of_band_rate"
+
+	// configKeyOutOfBandMax is the maximum amount by which out-of-band
+	// changes modify the count.
+	configKeyOutOfBandMax = "out_of_band_max"
+
+	// configKeySeed is the seed of the random source used to simulate
+	// failures and out-of-band changes. It can only be set in the plugin
+	// config and allows reproducing simulations.
+	configKeySeed = "seed"
+
+	defaultOutOfBandMax = 1
+)
+
+// simulatorConfig is the behaviour of a simulated target.
+type simulatorConfig struct {
+	id                 string
+	initialCount       int64
+	scaleLatency       time.Duration
+	failureRate        float64
+	partialFailureRate float64
+	maxCapacity        int64
+	outOfBandRate      float64
+	outOfBandMax       int64
+}
+
+// parseSimulatorConfig parses the simulated target behaviour from the
+// policy target config. Values not set by the policy default to the plugin
+// config.
+func parseSimulatorConfig(pluginConfig, config map[string]string) (*simulatorConfig, error) {
+	get := func(key string) string {
+		if v, ok := config[key]; ok {
+			return v
+		}
+		return pluginConfig[key]
+	}
+
+	cfg := simulatorConfig{
+		id:           get(configKeyID),
+		outOfBandMax: defaultOutOfBandMax,
+	}
+	if cfg.id == "" {
+		return nil, fmt.Errorf("required config param %s not found", configKeyID)
+	}
+
+	for _, v := range []struct {
+		key string
+		dst *int64
+	}{
+		{configKeyInitialCount, &cfg.initialCount},
+		{configKeyMaxCapacity, &cfg.maxCapacity},
+		{configKeyOutOfBandMax, &cfg.outOfBandMax},
+	} {
+		raw := get(v.key)
+		if raw == "" {
+			continue
+		}
+		i, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || i < 0 {
+			return nil, fmt.Errorf("invalid value for %s: must be a non-negative integer", v.key)
+		}
+		*v.dst = i
+	}
+
+	for _, v := range []struct {
+		key string
+		dst *float64
+	}{
+		{configKeyFailureRate, &cfg.failureRate},
+		{configKeyPartialFailureRate, &cfg.partialFailureRate},
+		{configKeyOutOfBandRate, &cfg.outOfBandRate},
+	} {
+		raw := get(v.key)
+		if raw == "" {
+			continue
+		}
+		f, err := strconv.ParseFloat(raw, 64)
+		if err != nil || f < 0 || f > 1 {
+			return nil, fmt.Errorf("invalid value for %s: must be a number between 0 and 1", v.key)
+		}
+		*v.dst = f
+	}
+
+	if raw := get(configKeyScaleLatency); raw != "" {
+		d, err := time.ParseDuration(raw)
+		if err != nil || d < 0 {
+			return nil, fmt.Errorf("invalid value for %s: must be a non-negative duration", configKeyScaleLatency)
+		}
+		cfg.scaleLatency = d
+	}
+
+	return &cfg, nil
+}
@@ -0,0 +1,262 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package plugin
+
+import (
+	"fmt"
+	"math/rand"
+	"strconv"
+	"sync"
+	"time"
+
+	hclog "github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/nomad-autoscaler/plugins"
+	"github.com/hashicorp/nomad-autoscaler/plugins/base"
+	"github.com/hashicorp/nomad-autoscaler/plugins/target"
+	"github.com/hashicorp/nomad-autoscaler/sdk"
+)
+
+const (
+	// pluginName is the unique name of the this plugin amongst Target plugins.
+	pluginName = "simulator"
+
+	// metaValueInProgressScaling is the sdk.TargetStatusMetaKeyInProgress
+	// value used while the simulated target is reaching the count of a
+	// scaling action.
+	metaValueInProgressScaling = "scaling"
+)
+
+var (
+	PluginConfig = &plugins.InternalPluginConfig{
+		Factory: func(l hclog.Logger) interface{} { return NewSimulatorPlugin(l) },
+	}
+
+	pluginInfo = &base.PluginInfo{
+		Name:       pluginName,
+		PluginType: sdk.PluginTypeTarget,
+	}
+)
+
+// Assert that TargetPlugin meets the target.Target and
+// target.CapacityReporter interfaces.
+var (
+	_ target.Target           = (*TargetPlugin)(nil)
+	_ target.CapacityReporter = (*TargetPlugin)(nil)
+)
+
+// TargetPlugin is a target.Target implementation which scales in-memory
+// simulated targets. It models scaling latency, failures, capacity limits
+// and out-of-band changes, so policies can be exercised end-to-end without
+// touching real infrastructure.
+type TargetPlugin struct {
+	config map[string]string
+	logger hclog.Logger
+
+	// lock protects the fields below.
+	lock sync.Mutex
+
+	// targets are the simulated targets keyed by their ID.
+	targets map[string]*simulatedTarget
+
+	// rand is the random source used to simulate failures and out-of-band
+	// changes.
+	rand *rand.Rand
+
+	// now returns the current time. It can be overwritten in tests.
+	now func() time.Time
+}
+
+// simulatedTarget is the state of a single simulated target. While a
+// scaling action is in progress, the count changes linearly from the count
+// at the start of the action to the count of the action.
+type simulatedTarget struct {
+	from, count int64
+	start, end  time.Time
+	lastEvent   time.Time
+}
+
+// NewSimulatorPlugin returns the simulator implementation of the
+// target.Target interface.
+func NewSimulatorPlugin(log hclog.Logger) *TargetPlugin {
+	return &TargetPlugin{
+		logger:  log,
+		targets: make(map[string]*simulatedTarget),
+		rand:    rand.New(rand.NewSource(time.Now().UnixNano())),
+		now:     time.Now,
+	}
+}
+
+// SetConfig satisfies the SetConfig function on the base.Base interface.
+func (t *TargetPlugin) SetConfig(config map[string]string) error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	t.config = config
+
+	if raw, ok := config[configKeySeed]; ok {
+		seed, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid value for %s: %v", configKeySeed, err)
+		}
+		t.rand = rand.New(rand.NewSource(seed))
+	}
+
+	return nil
+}
+
+// PluginInfo satisfies the PluginInfo function on the base.Base interface.
+func (t *TargetPlugin) PluginInfo() (*base.PluginInfo, error) {
+	return pluginInfo, nil
+}
+
+// Scale satisfies the Scale function on the target.Target interface.
+func (t *TargetPlugin) Scale(action sdk.ScalingAction, config map[string]string) error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	cfg, err := parseSimulatorConfig(t.config, config)
+	if err != nil {
+		return err
+	}
+
+	if action.Count == sdk.StrategyActionMetaValueDryRunCount {
+		t.logger.Info("skipping scaling of simulated target in dry-run mode", "id", cfg.id)
+		return nil
+	}
+
+	now := t.now()
+	st := t.target(cfg, now)
+	current, _ := st.current(now)
+
+	desired := action.Count
+	if cfg.maxCapacity > 0 && desired > cfg.maxCapacity {
+		if current >= cfg.maxCapacity {
+			return sdk.NewTargetScalingNoOpError("%w: simulated target %s is at its max capacity of %d",
+				sdk.ErrTargetCapacityReached, cfg.id, cfg.maxCapacity)
+		}
+		t.logger.Warn("scaling action limited by simulated capacity",
+			"id", cfg.id, "desired_count", desired, "max_capacity", cfg.maxCapacity)
+		desired = cfg.maxCapacity
+	}
+
+	if t.chance(cfg.failureRate) {
+		return fmt.Errorf("simulated failure scaling target %s", cfg.id)
+	}
+
+	var partialErr error
+	if t.chance(cfg.partialFailureRate) {
+		partial := current + (desired-current)/2
+		partialErr = fmt.Errorf("simulated partial failure scaling target %s: scaled to %d instead of %d",
+			cfg.id, partial, desired)
+		desired = partial
+	}
+
+	st.scale(current, desired, now, cfg.scaleLatency)
+	t.logger.Info("scaled simulated target", "id", cfg.id, "from", current, "to", desired,
+		"latency", cfg.scaleLatency, "reason", action.Reason)
+
+	return partialErr
+}
+
+// Status satisfies the Status function on the target.Target interface.
+func (t *TargetPlugin) Status(config map[string]string) (*sdk.TargetStatus, error) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	cfg, err := parseSimulatorConfig(t.config, config)
+	if err != nil {
+		return nil, err
+	}
+
+	now := t.now()
+	st := t.target(cfg, now)
+	count, ready := st.current(now)
+
+	// Out-of-band changes are only simulated once scaling actions complete,
+	// like operators changing the count of an idle target.
+	if ready && cfg.outOfBandMax > 0 && t.chance(cfg.outOfBandRate) {
+		delta := 1 + t.rand.Int63n(cfg.outOfBandMax)
+		if t.rand.Intn(2) == 0 {
+			delta = -delta
+		}
+
+		next := count + delta
+		if next < 0 {
+			next = 0
+		}
+		if cfg.maxCapacity > 0 && next > cfg.maxCapacity {
+			next = cfg.maxCapacity
+		}
+
+		t.logger.Info("simulating out-of-band change of target", "id", cfg.id, "from", count, "to", next)
+		st.scale(count, next, now, 0)
+		count = next
+	}
+
+	resp := sdk.TargetStatus{
+		Ready: ready,
+		Count: count,
+		Meta:  make(map[string]string),
+	}
+	if !st.lastEvent.IsZero() {
+		resp.Meta[sdk.TargetStatusMetaKeyLastEvent] = strconv.FormatInt(st.lastEvent.UnixNano(), 10)
+	}
+	if !ready {
+		resp.Meta[sdk.TargetStatusMetaKeyInProgress] = metaValueInProgressScaling
+	}
+
+	return &resp, nil
+}
+
+// Capacity satisfies the Capacity function on the target.CapacityReporter
+// interface.
+func (t *TargetPlugin) Capacity(config map[string]string) (*sdk.TargetCapacity, error) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	cfg, err := parseSimulatorConfig(t.config, config)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.maxCapacity <= 0 {
+		return nil, nil
+	}
+	return &sdk.TargetCapacity{MaxCount: &cfg.maxCapacity}, nil
+}
+
+// target returns the simulated target, creating it with its initial count
+// if it doesn't exist. The lock must be held.
+func (t *TargetPlugin) target(cfg *simulatorConfig, now time.Time) *simulatedTarget {
+	st, ok := t.targets[cfg.id]
+	if !ok {
+		st = &simulatedTarget{from: cfg.initialCount, count: cfg.initialCount, start: now, end: now}
+		t.targets[cfg.id] = st
+	}
+	return st
+}
+
+// chance returns true with the probability p. The lock must be held.
+func (t *TargetPlugin) chance(p float64) bool {
+	return p > 0 && t.rand.Float64() < p
+}
+
+// current returns the count of the simulated target and whether it has
+// reached the count of the last scaling action.
+func (s *simulatedTarget) current(now time.Time) (int64, bool) {
+	if !now.Before(s.end) {
+		return s.count, true
+	}
+
+	elapsed, total := now.Sub(s.start), s.end.Sub(s.start)
+	return s.from + (s.count-s.from)*int64(elapsed)/int64(total), false
+}
+
+// scale starts changing the count of the simulated target to count, which
+// is reached after the latency.
+func (s *simulatedTarget) scale(from, count int64, now time.Time, latency time.Duration) {
+	s.from, s.count = from, count
+	s.start, s.end = now, now.Add(latency)
+	s.lastEvent = now
+}
@@ -0,0 +1,226 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package plugin
+
+import (
+	"testing"
+	"time"
+
+	hclog "github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/nomad-autoscaler/sdk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testSimulatorPlugin(t *testing.T, config map[string]string) (*TargetPlugin, *time.Time) {
+	now := time.Date(2024, 6, 1, 14, 30, 0, 0, time.UTC)
+
+	p := NewSimulatorPlugin(hclog.NewNullLogger())
+	p.now = func() time.Time { return now }
+	require.NoError(t, p.SetConfig(config))
+
+	return p, &now
+}
+
+func TestTargetPlugin_latency(t *testing.T) {
+	p, now := testSimulatorPlugin(t, map[string]string{"scale_latency": "10m"})
+	config := map[string]string{"id": "web", "initial_count": "2"}
+
+	status, err := p.Status(config)
+	require.NoError(t, err)
+	assert.Equal(t, &sdk.TargetStatus{Ready: true, Count: 2, Meta: map[string]string{}}, status)
+
+	require.NoError(t, p.Scale(sdk.ScalingAction{Count: 12}, config))
+	lastEvent := "1717252200000000000"
+
+	*now = now.Add(5 * time.Minute)
+	status, err = p.Status(config)
+	require.NoError(t, err)
+	assert.Equal(t, &sdk.TargetStatus{
+		Ready: false,
+		Count: 7,
+		Meta: map[string]string{
+			sdk.TargetStatusMetaKeyLastEvent:  lastEvent,
+			sdk.TargetStatusMetaKeyInProgress: "scaling",
+		},
+	}, status)
+
+	*now = now.Add(5 * time.Minute)
+	status, err = p.Status(config)
+	require.NoError(t, err)
+	assert.Equal(t, &sdk.TargetStatus{
+		Ready: true,
+		Count: 12,
+		Meta:  map[string]string{sdk.TargetStatusMetaKeyLastEvent: lastEvent},
+	}, status)
+
+	// Other IDs are independent targets.
+	status, err = p.Status(map[string]string{"id": "api"})
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), status.Count)
+}
+
+func TestTargetPlugin_Scale(t *testing.T) {
+	testCases := []struct {
+		name          string
+		config        map[string]string
+		count         int64
+		expectedCount int64
+		expectedError string
+		expectedNoOp  bool
+	}{
+		{
+			name:          "scale out",
+			config:        map[string]string{"id": "web", "initial_count": "2"},
+			count:         6,
+			expectedCount: 6,
+		},
+		{
+			name:          "dry-run",
+			config:        map[string]string{"id": "web", "initial_count": "2"},
+			count:         sdk.StrategyActionMetaValueDryRunCount,
+			expectedCount: 2,
+		},
+		{
+			name:          "failure",
+			config:        map[string]string{"id": "web", "initial_count": "2", "failure_rate": "1"},
+			count:         6,
+			expectedCount: 2,
+			expectedError: "simulated failure scaling target web",
+		},
+		{
+			name:          "partial failure",
+			config:        map[string]string{"id": "web", "initial_count": "2", "partial_failure_rate": "1"},
+			count:         6,
+			expectedCount: 4,
+			expectedError: "scaled to 4 instead of 6",
+		},
+		{
+			name:          "capacity limit",
+			config:        map[string]string{"id": "web", "initial_count": "2", "max_capacity": "5"},
+			count:         6,
+			expectedCount: 5,
+		},
+		{
+			name:          "at capacity",
+			config:        map[string]string{"id": "web", "initial_count": "5", "max_capacity": "5"},
+			count:         6,
+			expectedCount: 5,
+			expectedNoOp:  true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			p, _ := testSimulatorPlugin(t, nil)
+
+			err := p.Scale(sdk.ScalingAction{Count: tc.count}, tc.config)
+			switch {
+			case tc.expectedNoOp:
+				assert.IsType(t, &sdk.TargetScalingNoOpError{}, err)
+				assert.ErrorIs(t, err, sdk.ErrTargetCapacityReached)
+			case tc.expectedError != "":
+				assert.ErrorContains(t, err, tc.expectedError)
+			default:
+				assert.NoError(t, err)
+			}
+
+			status, err := p.Status(tc.config)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedCount, status.Count)
+		})
+	}
+}
+
+func TestTargetPlugin_Capacity(t *testing.T) {
+	p, _ := testSimulatorPlugin(t, nil)
+
+	capacity, err := p.Capacity(map[string]string{"id": "web", "max_capacity": "5"})
+	require.NoError(t, err)
+	require.NotNil(t, capacity.MaxCount)
+	assert.Equal(t, int64(5), *capacity.MaxCount)
+	assert.Nil(t, capacity.Remaining)
+
+	// Targets without a max capacity don't report any limit.
+	capacity, err = p.Capacity(map[string]string{"id": "web"})
+	require.NoError(t, err)
+	assert.Nil(t, capacity)
+}
+
+func TestTargetPlugin_outOfBand(t *testing.T) {
+	p, _ := testSimulatorPlugin(t, map[string]string{"seed": "42"})
+	config := map[string]string{
+		"id":               "web",
+		"initial_count":    "10",
+		"max_capacity":     "12",
+		"out_of_band_rate": "1",
+		"out_of_band_max":  "3",
+	}
+
+	prev := int64(10)
+	for i := 0; i < 20; i++ {
+		status, err := p.Status(config)
+		require.NoError(t, err)
+		assert.InDelta(t, prev, status.Count, 3)
+		assert.LessOrEqual(t, status.Count, int64(12))
+		assert.GreaterOrEqual(t, status.Count, int64(0))
+		assert.Contains(t, status.Meta, sdk.TargetStatusMetaKeyLastEvent)
+		prev = status.Count
+	}
+}
+
+func Test_parseSimulatorConfig(t *testing.T) {
+	testCases := []struct {
+		name           string
+		pluginConfig   map[string]string
+		config         map[string]string
+		expectedConfig *simulatorConfig
+		expectedError  string
+	}{
+		{
+			name:         "policy overrides plugin config",
+			pluginConfig: map[string]string{"scale_latency": "1m", "failure_rate": "0.1"},
+			config:       map[string]string{"id": "web", "failure_rate": "0.5", "max_capacity": "10"},
+			expectedConfig: &simulatorConfig{
+				id:           "web",
+				scaleLatency: time.Minute,
+				failureRate:  0.5,
+				maxCapacity:  10,
+				outOfBandMax: 1,
+			},
+		},
+		{
+			name:          "missing id",
+			config:        map[string]string{},
+			expectedError: "required config param id not found",
+		},
+		{
+			name:          "invalid rate",
+			config:        map[string]string{"id": "web", "out_of_band_rate": "2"},
+			expectedError: "invalid value for out_of_band_rate",
+		},
+		{
+			name:          "invalid count",
+			config:        map[string]string{"id": "web", "initial_count": "-1"},
+			expectedError: "invalid value for initial_count",
+		},
+		{
+			name:          "invalid latency",
+			config:        map[string]string{"id": "web", "scale_latency": "soon"},
+			expectedError: "invalid value for scale_latency",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg, err := parseSimulatorConfig(tc.pluginConfig, tc.config)
+			if tc.expectedError != "" {
+				assert.ErrorContains(t, err, tc.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedConfig, cfg)
+		})
+	}
+}
@@ -0,0 +1,63 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package eventsink
+
+import (
+	"context"
+
+	"github.com/golang/protobuf/ptypes"
+	"github.com/hashicorp/nomad-autoscaler/plugins/base"
+	"github.com/hashicorp/nomad-autoscaler/plugins/eventsink/proto/v1"
+	"github.com/hashicorp/nomad-autoscaler/plugins/shared"
+	"github.com/hashicorp/nomad-autoscaler/sdk"
+)
+
+// pluginClient is the gRPC client implementation of the EventSink interface.
+type pluginClient struct {
+
+	// Embed the base plugin client so that the EventSink plugin implements
+	// the base interface.
+	*base.PluginClient
+
+	client  proto.EventSinkPluginServiceClient
+	doneCTX context.Context
+}
+
+// Send is the gRPC client implementation of the EventSink.Send interface
+// function.
+func (p *pluginClient) Send(event *sdk.ScalingEvent) error {
+	req, err := eventToProto(event)
+	if err != nil {
+		return err
+	}
+	ctx := shared.ContextWithCorrelationID(p.doneCTX, event.CorrelationID)
+	_, err = p.client.Send(ctx, &proto.SendRequest{Event: req})
+	return err
+}
+
+// eventToProto converts the input scaling event to the proto equivalent.
+func eventToProto(input *sdk.ScalingEvent) (*proto.ScalingEvent, error) {
+
+	action, err := shared.ScalingActionToProto(input.Action)
+	if err != nil {
+		return nil, err
+	}
+
+	ts, err := ptypes.TimestampProto(input.Timestamp)
+	if err != nil {
+		return nil, err
+	}
+
+	return &proto.ScalingEvent{
+		Id:        input.ID,
+		Type:      string(input.Type),
+		Timestamp: ts,
+		PolicyId:  input.PolicyID,
+		Target:    input.Target,
+		Check:     input.Check,
+		Count:     input.Count,
+		Action:    action,
+		Error:     input.Error,
+	}, nil
+}
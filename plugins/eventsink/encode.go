@@ -0,0 +1,63 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package eventsink
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/hashicorp/nomad-autoscaler/sdk"
+)
+
+// jsonEvent is the JSON representation of a scaling event published by the
+// builtin event sink plugins.
+type jsonEvent struct {
+	ID            string            `json:"id"`
+	Type          string            `json:"type"`
+	CorrelationID string            `json:"correlation_id,omitempty"`
+	Timestamp     time.Time         `json:"timestamp"`
+	PolicyID      string            `json:"policy_id"`
+	Target        string            `json:"target"`
+	PolicyMeta    map[string]string `json:"policy_meta,omitempty"`
+	Check         string            `json:"check,omitempty"`
+	Count         int64             `json:"count"`
+	TargetMeta    map[string]string `json:"target_meta,omitempty"`
+	Action        jsonAction        `json:"action"`
+	Error         string            `json:"error,omitempty"`
+}
+
+// jsonAction is the JSON representation of the scaling action of an event.
+type jsonAction struct {
+	Count     int64                  `json:"count"`
+	Direction string                 `json:"direction"`
+	Reason    string                 `json:"reason,omitempty"`
+	Error     bool                   `json:"error"`
+	Meta      map[string]interface{} `json:"meta,omitempty"`
+}
+
+// EncodeJSON returns the JSON representation of the scaling event. It should
+// be used by event sink plugins that don't require a specific format, so
+// consumers can rely on the same payload regardless of the sink used.
+func EncodeJSON(event *sdk.ScalingEvent) ([]byte, error) {
+	return json.Marshal(jsonEvent{
+		ID:            event.ID,
+		Type:          string(event.Type),
+		CorrelationID: event.CorrelationID,
+		Timestamp:     event.Timestamp,
+		PolicyID:      event.PolicyID,
+		Target:        event.Target,
+		PolicyMeta:    event.PolicyMeta,
+		Check:         event.Check,
+		Count:         event.Count,
+		TargetMeta:    event.TargetMeta,
+		Action: jsonAction{
+			Count:     event.Action.Count,
+			Direction: event.Action.Direction.String(),
+			Reason:    event.Action.Reason,
+			Error:     event.Action.Error,
+			Meta:      event.Action.Meta,
+		},
+		Error: event.Error,
+	})
+}
@@ -0,0 +1,24 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package eventsink
+
+import (
+	"github.com/hashicorp/nomad-autoscaler/plugins/base"
+	"github.com/hashicorp/nomad-autoscaler/sdk"
+)
+
+// EventSink is the interface that all EventSink plugins are required to
+// implement. The EventSink plugin receives every scaling decision taken by
+// the Autoscaler and is responsible for publishing it to an external system,
+// such as a message broker, so it can be consumed by downstream automation
+// and analytics pipelines.
+type EventSink interface {
+
+	// Embed base.Base ensuring that event sink plugins implement this
+	// interface.
+	base.Base
+
+	// Send publishes the scaling event to the remote system.
+	Send(event *sdk.ScalingEvent) error
+}
@@ -0,0 +1,90 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package eventsink
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hashicorp/nomad-autoscaler/sdk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_eventProtoRoundTrip(t *testing.T) {
+	event := &sdk.ScalingEvent{
+		ID:            "7f3b5d8e-0d3c-4d2a-9f7b-1c0f7f4b9a21",
+		Type:          sdk.ScalingEventTypeQuotaReached,
+		CorrelationID: "eval-1",
+		Timestamp:     time.Date(2020, 11, 5, 10, 0, 0, 0, time.UTC),
+		PolicyID:      "policy-1",
+		Target:        "nomad-target",
+		Check:         "cpu",
+		Count:         3,
+		Action: sdk.ScalingAction{
+			Count:     5,
+			Reason:    "scaling up because factor is 1.5",
+			Direction: sdk.ScaleDirectionUp,
+			Meta: map[string]interface{}{
+				"factor":                          1.5,
+				"nomad_autoscaler.correlation_id": "eval-1",
+			},
+		},
+		Error: "failed to scale target",
+	}
+
+	protoEvent, err := eventToProto(event)
+	require.NoError(t, err)
+
+	actual, err := protoToEvent(protoEvent)
+	require.NoError(t, err)
+	assert.Equal(t, event, actual)
+}
+
+func TestEncodeJSON(t *testing.T) {
+	event := &sdk.ScalingEvent{
+		ID:            "7f3b5d8e-0d3c-4d2a-9f7b-1c0f7f4b9a21",
+		Type:          sdk.ScalingEventTypeScaling,
+		CorrelationID: "eval-1",
+		Timestamp:     time.Date(2020, 11, 5, 10, 0, 0, 0, time.UTC),
+		PolicyID:      "policy-1",
+		Target:        "nomad-target",
+		PolicyMeta:    map[string]string{"team": "platform"},
+		Count:         3,
+		TargetMeta: map[string]string{
+			sdk.TargetStatusMetaKeyInProgress: "deployment",
+		},
+		Action: sdk.ScalingAction{
+			Count:     1,
+			Reason:    "scaling down",
+			Direction: sdk.ScaleDirectionDown,
+		},
+	}
+
+	expected := `{
+  "id": "7f3b5d8e-0d3c-4d2a-9f7b-1c0f7f4b9a21",
+  "type": "scaling",
+  "correlation_id": "eval-1",
+  "timestamp": "2020-11-05T10:00:00Z",
+  "policy_id": "policy-1",
+  "target": "nomad-target",
+  "policy_meta": {
+    "team": "platform"
+  },
+  "count": 3,
+  "target_meta": {
+    "nomad_autoscaler.in_progress": "deployment"
+  },
+  "action": {
+    "count": 1,
+    "direction": "down",
+    "reason": "scaling down",
+    "error": false
+  }
+}`
+
+	actual, err := EncodeJSON(event)
+	require.NoError(t, err)
+	assert.JSONEq(t, expected, string(actual))
+}
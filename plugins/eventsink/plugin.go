@@ -0,0 +1,45 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package eventsink
+
+import (
+	"context"
+
+	plugin "github.com/hashicorp/go-plugin"
+	"github.com/hashicorp/nomad-autoscaler/plugins/base"
+	baseproto "github.com/hashicorp/nomad-autoscaler/plugins/base/proto/v1"
+	"github.com/hashicorp/nomad-autoscaler/plugins/eventsink/proto/v1"
+	"google.golang.org/grpc"
+)
+
+// PluginEventSink is the EventSink implementation of the go-plugin GRPCPlugin
+// interface.
+type PluginEventSink struct {
+
+	// Embedded so we disable support for net/rpc based plugins.
+	plugin.NetRPCUnsupportedPlugin
+
+	// Impl is the EventSink interface implementation that the plugin serves.
+	Impl EventSink
+}
+
+// GRPCServer is the EventSink implementation of the go-plugin
+// GRPCPlugin.GRPCServer interface function.
+func (p *PluginEventSink) GRPCServer(broker *plugin.GRPCBroker, s *grpc.Server) error {
+	proto.RegisterEventSinkPluginServiceServer(s, &pluginServer{impl: p.Impl, broker: broker})
+	return nil
+}
+
+// GRPCClient is the EventSink implementation of the go-plugin
+// GRPCPlugin.GRPCClient interface function.
+func (p *PluginEventSink) GRPCClient(ctx context.Context, _ *plugin.GRPCBroker, c *grpc.ClientConn) (interface{}, error) {
+	return &pluginClient{
+		PluginClient: &base.PluginClient{
+			DoneCtx: ctx,
+			Client:  baseproto.NewBasePluginServiceClient(c),
+		},
+		client:  proto.NewEventSinkPluginServiceClient(c),
+		doneCTX: ctx,
+	}, nil
+}
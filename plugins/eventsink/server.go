@@ -0,0 +1,69 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package eventsink
+
+import (
+	"context"
+
+	"github.com/golang/protobuf/ptypes"
+	plugin "github.com/hashicorp/go-plugin"
+	"github.com/hashicorp/nomad-autoscaler/plugins/eventsink/proto/v1"
+	"github.com/hashicorp/nomad-autoscaler/plugins/shared"
+	"github.com/hashicorp/nomad-autoscaler/sdk"
+)
+
+// pluginServer is the gRPC server implementation of the EventSink interface.
+type pluginServer struct {
+	broker *plugin.GRPCBroker
+	impl   EventSink
+}
+
+// Send is the gRPC server implementation of the EventSink.Send interface
+// function.
+func (p *pluginServer) Send(ctx context.Context, req *proto.SendRequest) (*proto.SendResponse, error) {
+
+	event, err := protoToEvent(req.GetEvent())
+	if err != nil {
+		return nil, err
+	}
+
+	if event.CorrelationID == "" {
+		event.CorrelationID = shared.CorrelationIDFromContext(ctx)
+	}
+
+	if err := p.impl.Send(event); err != nil {
+		return nil, err
+	}
+	return &proto.SendResponse{}, nil
+}
+
+// protoToEvent converts the input proto scaling event and returns the
+// Autoscaler equivalent.
+func protoToEvent(input *proto.ScalingEvent) (*sdk.ScalingEvent, error) {
+
+	action, err := shared.ProtoToScalingAction(input.GetAction())
+	if err != nil {
+		return nil, err
+	}
+
+	ts, err := ptypes.Timestamp(input.GetTimestamp())
+	if err != nil {
+		return nil, err
+	}
+
+	// The correlation ID is not part of the proto event as it is carried in
+	// the action Meta.
+	return &sdk.ScalingEvent{
+		ID:            input.GetId(),
+		Type:          sdk.ScalingEventType(input.GetType()),
+		CorrelationID: action.CorrelationID(),
+		Timestamp:     ts,
+		PolicyID:      input.GetPolicyId(),
+		Target:        input.GetTarget(),
+		Check:         input.GetCheck(),
+		Count:         input.GetCount(),
+		Action:        action,
+		Error:         input.GetError(),
+	}, nil
+}
@@ -0,0 +1,224 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package manager
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	metrics "github.com/armon/go-metrics"
+	"github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/nomad-autoscaler/plugins"
+	"google.golang.org/grpc"
+)
+
+const (
+	// defaultStartupTimeout is the default maximum duration to wait for an
+	// external plugin to start.
+	defaultStartupTimeout = time.Minute
+
+	// defaultSlowCallThreshold is the default duration above which calls to
+	// external plugins are reported as slow.
+	defaultSlowCallThreshold = 5 * time.Second
+
+	// pluginMethodPrefix is the prefix of the gRPC methods served by the
+	// Nomad Autoscaler plugins. Calls to other services, such as the go-plugin
+	// health and stdio services, are not limited.
+	pluginMethodPrefix = "/hashicorp.nomad_autoscaler.plugins."
+)
+
+// callOptions controls the calls made to an external plugin.
+type callOptions struct {
+
+	// timeout is the maximum duration of each call. Zero means no timeout.
+	timeout time.Duration
+
+	// maxConcurrent is the maximum number of calls in progress at once. Zero
+	// means no limit.
+	maxConcurrent int
+
+	// slowThreshold is the duration above which calls are reported as slow.
+	slowThreshold time.Duration
+}
+
+// callLimiter applies the callOptions to the gRPC calls made to a single
+// external plugin instance and emits telemetry about each call.
+type callLimiter struct {
+	logger hclog.Logger
+	opts   callOptions
+	labels []metrics.Label
+
+	// slots is used as a semaphore to limit the number of concurrent calls.
+	// It is nil when concurrency is not limited.
+	slots chan struct{}
+
+	// inFlight is the number of calls in progress and lastDone the time, in
+	// Unix nanoseconds, at which the last call finished. They are used to
+	// avoid shutting down plugins which are still in use.
+	inFlight atomic.Int64
+	lastDone atomic.Int64
+}
+
+func newCallLimiter(log hclog.Logger, id plugins.PluginID, opts callOptions) *callLimiter {
+	l := &callLimiter{
+		logger: log.With("plugin_name", id.Name, "plugin_type", id.PluginType),
+		opts:   opts,
+		labels: []metrics.Label{
+			{Name: "plugin_name", Value: id.Name},
+			{Name: "plugin_type", Value: id.PluginType},
+		},
+	}
+	if opts.maxConcurrent > 0 {
+		l.slots = make(chan struct{}, opts.maxConcurrent)
+	}
+	return l
+}
+
+// idle returns whether the plugin has no calls in progress and hasn't
+// finished a call within timeout of now.
+func (l *callLimiter) idle(now time.Time, timeout time.Duration) bool {
+	if l.inFlight.Load() > 0 {
+		return false
+	}
+	return now.Sub(time.Unix(0, l.lastDone.Load())) >= timeout
+}
+
+// dialOptions returns the gRPC dial options which install the limiter on the
+// plugin client connection.
+func (l *callLimiter) dialOptions() []grpc.DialOption {
+	return []grpc.DialOption{
+		grpc.WithUnaryInterceptor(l.unaryInterceptor),
+		grpc.WithStreamInterceptor(l.streamInterceptor),
+	}
+}
+
+func (l *callLimiter) unaryInterceptor(ctx context.Context, method string, req, reply interface{},
+	cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+
+	if !strings.HasPrefix(method, pluginMethodPrefix) {
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+
+	ctx, done, err := l.start(ctx, method)
+	if err != nil {
+		return err
+	}
+
+	err = invoker(ctx, method, req, reply, cc, opts...)
+	done(err)
+	return err
+}
+
+func (l *callLimiter) streamInterceptor(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn,
+	method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+
+	if !strings.HasPrefix(method, pluginMethodPrefix) {
+		return streamer(ctx, desc, cc, method, opts...)
+	}
+
+	ctx, done, err := l.start(ctx, method)
+	if err != nil {
+		return nil, err
+	}
+
+	stream, err := streamer(ctx, desc, cc, method, opts...)
+	if err != nil {
+		done(err)
+		return nil, err
+	}
+
+	// Release the slot if the stream is abandoned before being read to the
+	// end. The context is cancelled once done is called, so this does not
+	// outlive the stream.
+	go func() {
+		<-ctx.Done()
+		done(ctx.Err())
+	}()
+
+	return &limitedStream{ClientStream: stream, done: done}, nil
+}
+
+// start waits for a call slot to be available and applies the call timeout
+// to ctx. The returned function must be called once the call finishes to
+// release the slot and emit the call telemetry.
+func (l *callLimiter) start(ctx context.Context, method string) (context.Context, func(error), error) {
+	labels := append([]metrics.Label{{Name: "method", Value: methodName(method)}}, l.labels...)
+
+	if l.slots != nil {
+		queueStart := time.Now()
+		select {
+		case l.slots <- struct{}{}:
+		case <-ctx.Done():
+			return nil, nil, ctx.Err()
+		}
+		metrics.MeasureSinceWithLabels([]string{"plugin", "call", "queue_ms"}, queueStart, labels)
+	}
+
+	var cancel context.CancelFunc
+	if l.opts.timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, l.opts.timeout)
+	} else {
+		ctx, cancel = context.WithCancel(ctx)
+	}
+
+	start := time.Now()
+	var once sync.Once
+	l.inFlight.Add(1)
+
+	done := func(err error) {
+		once.Do(func() {
+			timedOut := errors.Is(ctx.Err(), context.DeadlineExceeded)
+			cancel()
+			if l.slots != nil {
+				<-l.slots
+			}
+			l.lastDone.Store(time.Now().UnixNano())
+			l.inFlight.Add(-1)
+
+			elapsed := time.Since(start)
+			metrics.MeasureSinceWithLabels([]string{"plugin", "call", "invoke_ms"}, start, labels)
+
+			if err != nil && timedOut {
+				metrics.IncrCounterWithLabels([]string{"plugin", "call", "timeout_count"}, 1, labels)
+				l.logger.Warn("plugin call timed out", "method", methodName(method), "duration", elapsed)
+			} else if l.opts.slowThreshold > 0 && elapsed >= l.opts.slowThreshold {
+				metrics.IncrCounterWithLabels([]string{"plugin", "call", "slow_count"}, 1, labels)
+				l.logger.Warn("plugin call was slow", "method", methodName(method), "duration", elapsed)
+			}
+		})
+	}
+
+	return ctx, done, nil
+}
+
+// limitedStream wraps a client stream so the call slot is released once the
+// stream finishes.
+type limitedStream struct {
+	grpc.ClientStream
+	done func(error)
+}
+
+func (s *limitedStream) RecvMsg(m interface{}) error {
+	err := s.ClientStream.RecvMsg(m)
+	if err != nil {
+		// io.EOF signals the stream finished successfully.
+		if errors.Is(err, io.EOF) {
+			s.done(nil)
+		} else {
+			s.done(err)
+		}
+	}
+	return err
+}
+
+// methodName returns the method name of the full gRPC method, which has the
+// format /<package>.<service>/<method>.
+func methodName(fullMethod string) string {
+	return fullMethod[strings.LastIndex(fullMethod, "/")+1:]
+}
@@ -0,0 +1,130 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package manager
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/nomad-autoscaler/plugins"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+)
+
+const testMethod = "/hashicorp.nomad_autoscaler.plugins.apm.proto.v1.APMPluginService/Query"
+
+// blockingInvoker is a grpc.UnaryInvoker which blocks until the context is
+// done or release is closed.
+func blockingInvoker(release chan struct{}) grpc.UnaryInvoker {
+	return func(ctx context.Context, _ string, _, _ interface{}, _ *grpc.ClientConn, _ ...grpc.CallOption) error {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-release:
+			return nil
+		}
+	}
+}
+
+func TestCallLimiter_unaryInterceptor(t *testing.T) {
+	id := plugins.PluginID{Name: "prometheus", PluginType: "apm"}
+
+	t.Run("timeout", func(t *testing.T) {
+		l := newCallLimiter(hclog.NewNullLogger(), id, callOptions{timeout: 10 * time.Millisecond})
+
+		err := l.unaryInterceptor(context.Background(), testMethod, nil, nil, nil, blockingInvoker(nil))
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+
+	t.Run("non plugin methods are not limited", func(t *testing.T) {
+		l := newCallLimiter(hclog.NewNullLogger(), id, callOptions{timeout: 10 * time.Millisecond})
+
+		release := make(chan struct{})
+		time.AfterFunc(50*time.Millisecond, func() { close(release) })
+
+		err := l.unaryInterceptor(context.Background(), "/grpc.health.v1.Health/Check", nil, nil, nil, blockingInvoker(release))
+		assert.NoError(t, err)
+	})
+
+	t.Run("max concurrent calls", func(t *testing.T) {
+		l := newCallLimiter(hclog.NewNullLogger(), id, callOptions{maxConcurrent: 1})
+
+		release := make(chan struct{})
+		firstDone := make(chan error)
+		go func() {
+			firstDone <- l.unaryInterceptor(context.Background(), testMethod, nil, nil, nil, blockingInvoker(release))
+		}()
+
+		// Wait for the first call to take the only slot.
+		require.Eventually(t, func() bool { return len(l.slots) == 1 }, time.Second, time.Millisecond)
+
+		// The second call can't start before its context expires.
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		err := l.unaryInterceptor(ctx, testMethod, nil, nil, nil, blockingInvoker(release))
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+		close(release)
+		assert.NoError(t, <-firstDone)
+		assert.Len(t, l.slots, 0)
+	})
+}
+
+// fakeClientStream is a grpc.ClientStream which ends after the first message.
+type fakeClientStream struct {
+	grpc.ClientStream
+	received bool
+}
+
+func (s *fakeClientStream) RecvMsg(_ interface{}) error {
+	if s.received {
+		return io.EOF
+	}
+	s.received = true
+	return nil
+}
+
+func TestCallLimiter_streamInterceptor(t *testing.T) {
+	id := plugins.PluginID{Name: "prometheus", PluginType: "apm"}
+	l := newCallLimiter(hclog.NewNullLogger(), id, callOptions{maxConcurrent: 1})
+
+	streamer := func(_ context.Context, _ *grpc.StreamDesc, _ *grpc.ClientConn, _ string, _ ...grpc.CallOption) (grpc.ClientStream, error) {
+		return &fakeClientStream{}, nil
+	}
+
+	stream, err := l.streamInterceptor(context.Background(), &grpc.StreamDesc{ServerStreams: true}, nil, testMethod, streamer)
+	require.NoError(t, err)
+	assert.Len(t, l.slots, 1)
+
+	// The slot is released once the stream has been read to the end.
+	assert.NoError(t, stream.RecvMsg(nil))
+	assert.Len(t, l.slots, 1)
+	assert.ErrorIs(t, stream.RecvMsg(nil), io.EOF)
+	assert.Len(t, l.slots, 0)
+}
+
+func TestCallLimiter_idle(t *testing.T) {
+	id := plugins.PluginID{Name: "prometheus", PluginType: "apm"}
+	l := newCallLimiter(hclog.NewNullLogger(), id, callOptions{})
+	assert.True(t, l.idle(time.Now(), time.Minute))
+
+	// Calls in progress are never idle.
+	_, done, err := l.start(context.Background(), testMethod)
+	require.NoError(t, err)
+	assert.False(t, l.idle(time.Now().Add(time.Hour), time.Minute))
+
+	// The idle time is measured from the end of the last call.
+	done(nil)
+	assert.False(t, l.idle(time.Now(), time.Minute))
+	assert.True(t, l.idle(time.Now().Add(time.Minute), time.Minute))
+}
+
+func Test_methodName(t *testing.T) {
+	assert.Equal(t, "Query", methodName(testMethod))
+	assert.Equal(t, "Check", methodName("/grpc.health.v1.Health/Check"))
+}
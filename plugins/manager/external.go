@@ -16,10 +16,25 @@ import (
 func (pm *PluginManager) loadExternalPlugin(cfg *config.Plugin, pluginType string) {
 
 	info := &pluginInfo{
-		args:    cfg.Args,
-		config:  cfg.Config,
-		driver:  cfg.Driver,
-		exePath: filepath.Join(pm.pluginDir, cleanPluginExecutable(cfg.Driver)),
+		args:            cfg.Args,
+		config:          cfg.Config,
+		driver:          cfg.Driver,
+		exePath:         filepath.Join(pm.pluginDir, cleanPluginExecutable(cfg.Driver)),
+		policyOverrides: cfg.PolicyOverrides,
+		queryOptions:    builtinQueryOptions(cfg.Driver),
+		startupTimeout:  defaultStartupTimeout,
+		calls: callOptions{
+			timeout:       cfg.CallTimeout,
+			maxConcurrent: cfg.MaxConcurrentCalls,
+			slowThreshold: defaultSlowCallThreshold,
+		},
+	}
+
+	if cfg.StartupTimeout > 0 {
+		info.startupTimeout = cfg.StartupTimeout
+	}
+	if cfg.SlowCallThreshold > 0 {
+		info.calls.slowThreshold = cfg.SlowCallThreshold
 	}
 
 	// Add the plugin.
@@ -0,0 +1,163 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package manager
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	hclog "github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/nomad-autoscaler/agent/config"
+	"github.com/hashicorp/nomad-autoscaler/plugins/apm"
+	targetpkg "github.com/hashicorp/nomad-autoscaler/plugins/target"
+	"github.com/hashicorp/nomad-autoscaler/sdk"
+)
+
+// faultInjector injects faults into the plugins dispensed by the manager so
+// the resilience of the agent can be tested in development environments. A
+// nil faultInjector doesn't inject any fault.
+type faultInjector struct {
+	cfg    config.FaultInjection
+	logger hclog.Logger
+
+	lock sync.Mutex
+	rand *rand.Rand
+}
+
+// EnableFaultInjection makes the manager inject faults into the APM, target
+// and strategy plugins it returns, at the rates of the passed config. It must
+// be called before the plugins are used.
+func (pm *PluginManager) EnableFaultInjection(cfg *config.FaultInjection) {
+	if cfg == nil {
+		return
+	}
+
+	pm.logger.Warn("fault injection is enabled, this must not be used in production",
+		"apm_timeout", cfg.APMTimeout, "target_error", cfg.TargetError, "plugin_crash", cfg.PluginCrash)
+
+	pm.faults = &faultInjector{
+		cfg:    *cfg,
+		logger: pm.logger.Named("fault_injection"),
+		rand:   rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+// chance returns true with the probability p.
+func (f *faultInjector) chance(p float64) bool {
+	if p <= 0 {
+		return false
+	}
+
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	return f.rand.Float64() < p
+}
+
+// crash kills the plugin instance as if it had crashed, and returns an error
+// when the fault is injected.
+func (f *faultInjector) crash(inst PluginInstance, name string) error {
+	if f == nil || !f.chance(f.cfg.PluginCrash) {
+		return nil
+	}
+
+	f.logger.Warn("injecting plugin crash", "plugin_name", name)
+	inst.Kill()
+	return fmt.Errorf("injected fault: plugin %q crashed", name)
+}
+
+// wrapAPM returns the APM with timeouts injected into its queries.
+func (f *faultInjector) wrapAPM(a apm.APM, name string) apm.APM {
+	if f == nil || f.cfg.APMTimeout <= 0 {
+		return a
+	}
+	return &faultyAPM{APM: a, faults: f, name: name}
+}
+
+// wrapTarget returns the target with errors injected into its calls.
+func (f *faultInjector) wrapTarget(t targetpkg.Target, name string) targetpkg.Target {
+	if f == nil || f.cfg.TargetError <= 0 {
+		return t
+	}
+	return &faultyTarget{Target: t, faults: f, name: name}
+}
+
+// faultyAPM is an apm.APM whose queries time out at the configured rate.
+type faultyAPM struct {
+	apm.APM
+	faults *faultInjector
+	name   string
+}
+
+func (a *faultyAPM) timeout() error {
+	if !a.faults.chance(a.faults.cfg.APMTimeout) {
+		return nil
+	}
+	a.faults.logger.Warn("injecting APM query timeout", "plugin_name", a.name)
+	return fmt.Errorf("injected fault: APM %q query timed out: %w", a.name, context.DeadlineExceeded)
+}
+
+func (a *faultyAPM) Query(q string, r sdk.TimeRange) (sdk.TimestampedMetrics, error) {
+	if err := a.timeout(); err != nil {
+		return nil, err
+	}
+	return a.APM.Query(q, r)
+}
+
+func (a *faultyAPM) QueryMultiple(q string, r sdk.TimeRange) ([]sdk.TimestampedMetrics, error) {
+	if err := a.timeout(); err != nil {
+		return nil, err
+	}
+	return a.APM.QueryMultiple(q, r)
+}
+
+// WithContext forwards the context to the wrapped APM, keeping the injected
+// faults.
+func (a *faultyAPM) WithContext(ctx context.Context) apm.APM {
+	return &faultyAPM{APM: apm.WithContext(ctx, a.APM), faults: a.faults, name: a.name}
+}
+
+// faultyTarget is a targetpkg.Target whose calls fail at the configured rate.
+type faultyTarget struct {
+	targetpkg.Target
+	faults *faultInjector
+	name   string
+}
+
+func (t *faultyTarget) fail(call string) error {
+	if !t.faults.chance(t.faults.cfg.TargetError) {
+		return nil
+	}
+	t.faults.logger.Warn("injecting target error", "plugin_name", t.name, "call", call)
+	return fmt.Errorf("injected fault: target %q %s failed", t.name, call)
+}
+
+func (t *faultyTarget) Scale(action sdk.ScalingAction, config map[string]string) error {
+	if err := t.fail("scale"); err != nil {
+		return err
+	}
+	return t.Target.Scale(action, config)
+}
+
+func (t *faultyTarget) Status(config map[string]string) (*sdk.TargetStatus, error) {
+	if err := t.fail("status"); err != nil {
+		return nil, err
+	}
+	return t.Target.Status(config)
+}
+
+// Capacity delegates to the wrapped target so the fault injection doesn't hide
+// its capacity limits.
+func (t *faultyTarget) Capacity(config map[string]string) (*sdk.TargetCapacity, error) {
+	impl, ok := t.Target.(targetpkg.CapacityReporter)
+	if !ok {
+		return nil, nil
+	}
+	if err := t.fail("capacity"); err != nil {
+		return nil, err
+	}
+	return impl.Capacity(config)
+}
@@ -0,0 +1,70 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package manager
+
+import (
+	"context"
+	"testing"
+
+	hclog "github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/nomad-autoscaler/agent/config"
+	"github.com/hashicorp/nomad-autoscaler/plugins/apm"
+	"github.com/hashicorp/nomad-autoscaler/plugins/base"
+	targetpkg "github.com/hashicorp/nomad-autoscaler/plugins/target"
+	"github.com/hashicorp/nomad-autoscaler/sdk"
+	"github.com/stretchr/testify/assert"
+)
+
+type testFaultsPlugin struct{}
+
+func (testFaultsPlugin) SetConfig(map[string]string) error                { return nil }
+func (testFaultsPlugin) PluginInfo() (*base.PluginInfo, error)            { return &base.PluginInfo{}, nil }
+func (testFaultsPlugin) Scale(sdk.ScalingAction, map[string]string) error { return nil }
+func (testFaultsPlugin) Status(map[string]string) (*sdk.TargetStatus, error) {
+	return &sdk.TargetStatus{Count: 1}, nil
+}
+func (testFaultsPlugin) Query(string, sdk.TimeRange) (sdk.TimestampedMetrics, error) {
+	return sdk.TimestampedMetrics{}, nil
+}
+func (testFaultsPlugin) QueryMultiple(string, sdk.TimeRange) ([]sdk.TimestampedMetrics, error) {
+	return nil, nil
+}
+
+var (
+	_ apm.APM          = testFaultsPlugin{}
+	_ targetpkg.Target = testFaultsPlugin{}
+)
+
+func TestPluginManager_EnableFaultInjection(t *testing.T) {
+	pm := NewPluginManager(hclog.NewNullLogger(), "this/doesnt/exist", config.PermissionChecksWarn, 0, nil)
+
+	// Fault injection is disabled by default.
+	pm.EnableFaultInjection(nil)
+	assert.Nil(t, pm.faults)
+	assert.Equal(t, testFaultsPlugin{}, pm.faults.wrapTarget(testFaultsPlugin{}, "test"))
+	assert.Equal(t, testFaultsPlugin{}, pm.faults.wrapAPM(testFaultsPlugin{}, "test"))
+	assert.NoError(t, pm.faults.crash(&internalPluginInstance{}, "test"))
+
+	// Faults are always injected with a rate of 1.
+	pm.EnableFaultInjection(&config.FaultInjection{APMTimeout: 1, TargetError: 1, PluginCrash: 1})
+
+	target := pm.faults.wrapTarget(testFaultsPlugin{}, "test")
+	assert.ErrorContains(t, target.Scale(sdk.ScalingAction{}, nil), `injected fault: target "test" scale failed`)
+	_, err := target.Status(nil)
+	assert.ErrorContains(t, err, `injected fault: target "test" status failed`)
+
+	_, err = pm.faults.wrapAPM(testFaultsPlugin{}, "test").Query("", sdk.TimeRange{})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	assert.ErrorContains(t, pm.faults.crash(&internalPluginInstance{}, "test"), `injected fault: plugin "test" crashed`)
+
+	// Faults are never injected with a rate of 0.
+	pm.EnableFaultInjection(&config.FaultInjection{})
+
+	target = pm.faults.wrapTarget(testFaultsPlugin{}, "test")
+	assert.NoError(t, target.Scale(sdk.ScalingAction{}, nil))
+	_, err = pm.faults.wrapAPM(testFaultsPlugin{}, "test").Query("", sdk.TimeRange{})
+	assert.NoError(t, err)
+	assert.NoError(t, pm.faults.crash(&internalPluginInstance{}, "test"))
+}
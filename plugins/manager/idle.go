@@ -0,0 +1,145 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package manager
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/nomad-autoscaler/plugins"
+)
+
+// minReapInterval is the minimum interval at which idle plugins are checked.
+const minReapInterval = time.Second
+
+// dispenseLazy launches the plugin identified by pID if it is not already
+// running and returns the instance. It is only used when lazy dispensing is
+// enabled.
+func (pm *PluginManager) dispenseLazy(pID plugins.PluginID) (PluginInstance, error) {
+	pm.launchLock.Lock()
+	defer pm.launchLock.Unlock()
+
+	// Another caller may have launched the plugin while we waited for the
+	// lock. Instances whose process has exited are replaced.
+	pm.pluginInstancesLock.Lock()
+	if inst, ok := pm.pluginInstances[pID]; ok {
+		if !pm.exited(inst) {
+			pm.markUsed(pID)
+			pm.pluginInstancesLock.Unlock()
+			return inst, nil
+		}
+		pm.logger.Warn("plugin has exited, relaunching", "plugin_name", pID.Name)
+		pm.killPluginLocked(pID)
+	}
+	pm.pluginInstancesLock.Unlock()
+
+	pm.pluginsLock.RLock()
+	info, ok := pm.plugins[pID]
+	pm.pluginsLock.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("failed to dispense plugin: %q of type %q is not stored", pID.Name, pID.PluginType)
+	}
+
+	inst, err := pm.launchPlugin(pID, info)
+	if err != nil {
+		return nil, err
+	}
+
+	pm.pluginInstancesLock.Lock()
+	pm.pluginInstances[pID] = inst
+	pm.markUsed(pID)
+	pm.pluginInstancesLock.Unlock()
+
+	pm.logger.Info("successfully launched and dispensed plugin", "plugin_name", pID.Name)
+	return inst, nil
+}
+
+// exited returns whether the plugin instance is external and its process has
+// exited. Exited instances are only relaunched when lazy dispensing is
+// enabled.
+func (pm *PluginManager) exited(inst PluginInstance) bool {
+	if pm.idleTimeout == 0 {
+		return false
+	}
+	ext, ok := inst.(*externalPluginInstance)
+	return ok && ext.client.Exited()
+}
+
+// markUsed records the plugin as being used now.
+func (pm *PluginManager) markUsed(pID plugins.PluginID) {
+	if pm.idleTimeout == 0 {
+		return
+	}
+
+	pm.lastUsedLock.Lock()
+	pm.lastUsed[pID] = time.Now()
+	pm.lastUsedLock.Unlock()
+}
+
+// reapIdlePlugins periodically kills external plugins which have not been
+// dispensed or called within the idle timeout. It runs until KillPlugins is
+// called.
+func (pm *PluginManager) reapIdlePlugins() {
+	interval := pm.idleTimeout / 2
+	if interval < minReapInterval {
+		interval = minReapInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-pm.shutdownCh:
+			return
+		case now := <-ticker.C:
+			pm.killIdlePlugins(now)
+		}
+	}
+}
+
+// killIdlePlugins kills all the external plugins, including those launched
+// using policy config overrides, which have been unused for longer than the
+// idle timeout. A plugin is unused when it has neither been dispensed nor
+// called within the timeout, and plugins with calls in progress are never
+// killed. They will be launched again the next time they are dispensed.
+// Internal plugins run within the agent and are never killed.
+func (pm *PluginManager) killIdlePlugins(now time.Time) {
+	pm.pluginInstancesLock.Lock()
+	for pID, inst := range pm.pluginInstances {
+		ext, ok := inst.(*externalPluginInstance)
+		if !ok {
+			continue
+		}
+
+		pm.lastUsedLock.Lock()
+		lastUsed := pm.lastUsed[pID]
+		pm.lastUsedLock.Unlock()
+
+		if now.Sub(lastUsed) < pm.idleTimeout || !ext.idle(now, pm.idleTimeout) {
+			continue
+		}
+
+		pm.logger.Info("shutting down idle plugin", "plugin_name", pID.Name, "idle_timeout", pm.idleTimeout)
+		pm.killPluginLocked(pID)
+	}
+	pm.pluginInstancesLock.Unlock()
+
+	pm.scopedInstancesLock.Lock()
+	for sID, inst := range pm.scopedInstances {
+		ext, ok := inst.(*externalPluginInstance)
+		if !ok {
+			continue
+		}
+		if now.Sub(pm.scopedLastUsed[sID]) < pm.idleTimeout || !ext.idle(now, pm.idleTimeout) {
+			continue
+		}
+
+		pm.logger.Info("shutting down idle scoped plugin", "plugin_name", sID.Name, "idle_timeout", pm.idleTimeout)
+		inst.Kill()
+		delete(pm.scopedInstances, sID)
+		delete(pm.scopedLastUsed, sID)
+	}
+	pm.scopedInstancesLock.Unlock()
+}
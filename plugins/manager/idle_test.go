@@ -0,0 +1,119 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package manager
+
+import (
+	"context"
+	"os/exec"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/go-plugin"
+	"github.com/hashicorp/nomad-autoscaler/agent/config"
+	"github.com/hashicorp/nomad-autoscaler/plugins"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPluginManager_lazyDispense(t *testing.T) {
+	cfg := map[string][]*config.Plugin{
+		"strategy": {
+			&config.Plugin{Name: "target-value", Driver: "target-value"},
+		},
+		"apm": {
+			&config.Plugin{Name: "missing", Driver: "missing-apm"},
+		},
+	}
+
+	pm := NewPluginManager(hclog.NewNullLogger(), "this/doesnt/exist", config.PermissionChecksWarn, time.Minute, cfg)
+	defer pm.KillPlugins()
+
+	// Loading does not launch external plugins, so the missing binary is not
+	// reported.
+	require.NoError(t, pm.Load())
+	assert.Contains(t, pm.pluginInstances, plugins.PluginID{Name: "target-value", PluginType: "strategy"})
+	assert.NotContains(t, pm.pluginInstances, plugins.PluginID{Name: "missing", PluginType: "apm"})
+
+	// The external plugin is launched when dispensed.
+	_, err := pm.Dispense("missing", "apm")
+	assert.ErrorContains(t, err, "failed to dispense plugin missing")
+
+	_, err = pm.Dispense("unknown", "apm")
+	assert.ErrorContains(t, err, "is not stored")
+
+	_, err = pm.Dispense("target-value", "strategy")
+	assert.NoError(t, err)
+}
+
+func TestPluginManager_killIdlePlugins(t *testing.T) {
+	pm := NewPluginManager(hclog.NewNullLogger(), "this/doesnt/exist", config.PermissionChecksWarn, time.Minute, nil)
+
+	newExternal := func() PluginInstance {
+		return &externalPluginInstance{
+			client: plugin.NewClient(&plugin.ClientConfig{
+				HandshakeConfig: plugins.Handshake,
+				Cmd:             exec.Command("true"),
+			}),
+		}
+	}
+
+	now := time.Now()
+	idleID := plugins.PluginID{Name: "idle", PluginType: "apm"}
+	activeID := plugins.PluginID{Name: "active", PluginType: "apm"}
+	busyID := plugins.PluginID{Name: "busy", PluginType: "apm"}
+	calledID := plugins.PluginID{Name: "called", PluginType: "target"}
+	internalID := plugins.PluginID{Name: "internal", PluginType: "strategy"}
+	idleScopedID := scopedPluginID{PluginID: idleID, overrides: `"address"="a";`}
+	activeScopedID := scopedPluginID{PluginID: activeID, overrides: `"address"="b";`}
+	busyScopedID := scopedPluginID{PluginID: busyID, overrides: `"address"="c";`}
+
+	// The busy plugins were dispensed long ago but still have a call in
+	// progress, and the called plugin finished a call recently.
+	busy := newExternal().(*externalPluginInstance)
+	busy.calls = newCallLimiter(hclog.NewNullLogger(), busyID, callOptions{})
+	_, busyDone, err := busy.calls.start(context.Background(), testMethod)
+	require.NoError(t, err)
+	defer busyDone(nil)
+
+	called := newExternal().(*externalPluginInstance)
+	called.calls = newCallLimiter(hclog.NewNullLogger(), calledID, callOptions{})
+	called.calls.lastDone.Store(now.Add(-30 * time.Second).UnixNano())
+
+	pm.pluginInstances[idleID] = newExternal()
+	pm.pluginInstances[activeID] = newExternal()
+	pm.pluginInstances[busyID] = busy
+	pm.pluginInstances[calledID] = called
+	pm.pluginInstances[internalID] = &internalPluginInstance{}
+	pm.lastUsed[idleID] = now.Add(-2 * time.Minute)
+	pm.lastUsed[activeID] = now.Add(-30 * time.Second)
+	pm.lastUsed[busyID] = now.Add(-2 * time.Minute)
+	pm.lastUsed[calledID] = now.Add(-2 * time.Minute)
+
+	pm.scopedInstances[idleScopedID] = newExternal()
+	pm.scopedInstances[activeScopedID] = newExternal()
+	pm.scopedInstances[busyScopedID] = busy
+	pm.scopedLastUsed[idleScopedID] = now.Add(-2 * time.Minute)
+	pm.scopedLastUsed[activeScopedID] = now
+	pm.scopedLastUsed[busyScopedID] = now.Add(-2 * time.Minute)
+
+	pm.killIdlePlugins(now)
+
+	assert.NotContains(t, pm.pluginInstances, idleID)
+	assert.NotContains(t, pm.lastUsed, idleID)
+	assert.Contains(t, pm.pluginInstances, activeID)
+	assert.Contains(t, pm.pluginInstances, busyID)
+	assert.Contains(t, pm.pluginInstances, calledID)
+	assert.Contains(t, pm.pluginInstances, internalID)
+
+	assert.NotContains(t, pm.scopedInstances, idleScopedID)
+	assert.Contains(t, pm.scopedInstances, activeScopedID)
+	assert.Contains(t, pm.scopedInstances, busyScopedID)
+
+	// Once the call finishes the plugin is killed after the idle timeout.
+	busyDone(nil)
+	pm.killIdlePlugins(now.Add(2 * time.Minute))
+	assert.NotContains(t, pm.pluginInstances, busyID)
+	assert.NotContains(t, pm.scopedInstances, busyScopedID)
+}
@@ -3,7 +3,11 @@
 
 package manager
 
-import plugin "github.com/hashicorp/go-plugin"
+import (
+	"time"
+
+	plugin "github.com/hashicorp/go-plugin"
+)
 
 // PluginInstance is a wrapper of a plugin and provides a common interface
 // whether the plugin is internal or running externally via a binary.
@@ -29,7 +33,16 @@ func (p *internalPluginInstance) Plugin() interface{} { return p.instance }
 type externalPluginInstance struct {
 	client   *plugin.Client
 	instance interface{}
+
+	// calls limits and tracks the calls made to the plugin.
+	calls *callLimiter
 }
 
 func (p *externalPluginInstance) Kill()               { p.client.Kill() }
 func (p *externalPluginInstance) Plugin() interface{} { return p.instance }
+
+// idle returns whether the plugin has no calls in progress and hasn't been
+// called within timeout of now.
+func (p *externalPluginInstance) idle(now time.Time, timeout time.Duration) bool {
+	return p.calls == nil || p.calls.idle(now, timeout)
+}
@@ -9,24 +9,42 @@ import (
 
 	"github.com/hashicorp/nomad-autoscaler/agent/config"
 	"github.com/hashicorp/nomad-autoscaler/plugins"
+	consulAPM "github.com/hashicorp/nomad-autoscaler/plugins/builtin/apm/consul/plugin"
 	datadog "github.com/hashicorp/nomad-autoscaler/plugins/builtin/apm/datadog/plugin"
+	httpJSON "github.com/hashicorp/nomad-autoscaler/plugins/builtin/apm/http-json/plugin"
+	kafkaLag "github.com/hashicorp/nomad-autoscaler/plugins/builtin/apm/kafka-lag/plugin"
 	nomadAPM "github.com/hashicorp/nomad-autoscaler/plugins/builtin/apm/nomad/plugin"
+	opencost "github.com/hashicorp/nomad-autoscaler/plugins/builtin/apm/opencost/plugin"
 	prometheus "github.com/hashicorp/nomad-autoscaler/plugins/builtin/apm/prometheus/plugin"
+	rabbitmq "github.com/hashicorp/nomad-autoscaler/plugins/builtin/apm/rabbitmq/plugin"
+	redisAPM "github.com/hashicorp/nomad-autoscaler/plugins/builtin/apm/redis/plugin"
+	sqlAPM "github.com/hashicorp/nomad-autoscaler/plugins/builtin/apm/sql/plugin"
+	sqs "github.com/hashicorp/nomad-autoscaler/plugins/builtin/apm/sqs/plugin"
+	kafkaEventSink "github.com/hashicorp/nomad-autoscaler/plugins/builtin/event-sink/kafka/plugin"
+	nats "github.com/hashicorp/nomad-autoscaler/plugins/builtin/event-sink/nats/plugin"
+	sns "github.com/hashicorp/nomad-autoscaler/plugins/builtin/event-sink/sns/plugin"
 	fixedValue "github.com/hashicorp/nomad-autoscaler/plugins/builtin/strategy/fixed-value/plugin"
 	passthrough "github.com/hashicorp/nomad-autoscaler/plugins/builtin/strategy/pass-through/plugin"
+	percentile "github.com/hashicorp/nomad-autoscaler/plugins/builtin/strategy/percentile/plugin"
 	targetValue "github.com/hashicorp/nomad-autoscaler/plugins/builtin/strategy/target-value/plugin"
 	threshold "github.com/hashicorp/nomad-autoscaler/plugins/builtin/strategy/threshold/plugin"
+	wasm "github.com/hashicorp/nomad-autoscaler/plugins/builtin/strategy/wasm/plugin"
 	awsASG "github.com/hashicorp/nomad-autoscaler/plugins/builtin/target/aws-asg/plugin"
 	azureVMSS "github.com/hashicorp/nomad-autoscaler/plugins/builtin/target/azure-vmss/plugin"
 	gceMIG "github.com/hashicorp/nomad-autoscaler/plugins/builtin/target/gce-mig/plugin"
 	nomadTarget "github.com/hashicorp/nomad-autoscaler/plugins/builtin/target/nomad/plugin"
+	simulator "github.com/hashicorp/nomad-autoscaler/plugins/builtin/target/simulator/plugin"
 )
 
 // loadInternalPlugin takes the plugin configuration and attempts to load it
 // from internally to the plugin store.
 func (pm *PluginManager) loadInternalPlugin(cfg *config.Plugin, pluginType string) {
 
-	info := &pluginInfo{config: cfg.Config}
+	info := &pluginInfo{
+		config:          cfg.Config,
+		policyOverrides: cfg.PolicyOverrides,
+		queryOptions:    builtinQueryOptions(cfg.Driver),
+	}
 
 	switch cfg.Driver {
 	case plugins.InternalAPMNomad:
@@ -47,6 +65,12 @@ func (pm *PluginManager) loadInternalPlugin(cfg *config.Plugin, pluginType strin
 	case plugins.InternalStrategyFixedValue:
 		info.factory = fixedValue.PluginConfig.Factory
 		info.driver = "fixed-value"
+	case plugins.InternalStrategyPercentile:
+		info.factory = percentile.PluginConfig.Factory
+		info.driver = "percentile"
+	case plugins.InternalStrategyWASM:
+		info.factory = wasm.PluginConfig.Factory
+		info.driver = "wasm"
 	case plugins.InternalAPMPrometheus:
 		info.factory = prometheus.PluginConfig.Factory
 		info.driver = "prometheus"
@@ -59,9 +83,45 @@ func (pm *PluginManager) loadInternalPlugin(cfg *config.Plugin, pluginType strin
 	case plugins.InternalTargetGCEMIG:
 		info.factory = gceMIG.PluginConfig.Factory
 		info.driver = "gce-mig"
+	case plugins.InternalTargetSimulator:
+		info.factory = simulator.PluginConfig.Factory
+		info.driver = "simulator"
 	case plugins.InternalAPMDatadog:
 		info.factory = datadog.PluginConfig.Factory
 		info.driver = "datadog"
+	case plugins.InternalAPMRabbitMQ:
+		info.factory = rabbitmq.PluginConfig.Factory
+		info.driver = "rabbitmq"
+	case plugins.InternalAPMKafkaLag:
+		info.factory = kafkaLag.PluginConfig.Factory
+		info.driver = "kafka-lag"
+	case plugins.InternalAPMSQS:
+		info.factory = sqs.PluginConfig.Factory
+		info.driver = "sqs"
+	case plugins.InternalAPMHTTPJSON:
+		info.factory = httpJSON.PluginConfig.Factory
+		info.driver = "http-json"
+	case plugins.InternalAPMSQL:
+		info.factory = sqlAPM.PluginConfig.Factory
+		info.driver = "sql"
+	case plugins.InternalAPMRedis:
+		info.factory = redisAPM.PluginConfig.Factory
+		info.driver = "redis"
+	case plugins.InternalAPMConsul:
+		info.factory = consulAPM.PluginConfig.Factory
+		info.driver = "consul"
+	case plugins.InternalAPMOpenCost:
+		info.factory = opencost.PluginConfig.Factory
+		info.driver = "opencost"
+	case plugins.InternalEventSinkKafka:
+		info.factory = kafkaEventSink.PluginConfig.Factory
+		info.driver = "kafka"
+	case plugins.InternalEventSinkNATS:
+		info.factory = nats.PluginConfig.Factory
+		info.driver = "nats"
+	case plugins.InternalEventSinkSNS:
+		info.factory = sns.PluginConfig.Factory
+		info.driver = "sns"
 	default:
 		pm.logger.Error("unsupported internal plugin", "plugin", cfg.Driver)
 		return
@@ -73,6 +133,31 @@ func (pm *PluginManager) loadInternalPlugin(cfg *config.Plugin, pluginType strin
 
 }
 
+// RegisterInternalPlugin registers a plugin which runs in-process using the
+// passed factory, in addition to the plugins of the agent config. The plugin
+// must report name as its PluginInfo name. It must be called before Load and
+// is mostly useful in tests, to run the evaluation pipeline against plugin
+// fixtures such as the ones provided by the sdk/testutil package.
+func (pm *PluginManager) RegisterInternalPlugin(name, pluginType string, factory plugins.PluginFactory, cfg map[string]string) {
+	info := &pluginInfo{config: cfg, factory: factory, driver: name}
+
+	pm.pluginsLock.Lock()
+	pm.plugins[plugins.PluginID{Name: name, PluginType: pluginType}] = info
+	pm.pluginsLock.Unlock()
+}
+
+// builtinQueryOptions returns the query options of the builtin APM plugin
+// implementing the driver. They apply whether the plugin runs internally or
+// as the binary built from the builtin plugin.
+func builtinQueryOptions(driver string) []string {
+	switch driver {
+	case plugins.InternalAPMPrometheus:
+		return prometheus.PluginConfig.QueryOptions
+	default:
+		return nil
+	}
+}
+
 // useInternal decides whether we should use the internal implementation of the
 // plugin. The preference is to use externally found plugins over the internal
 // plugin.
@@ -105,10 +190,24 @@ func (pm *PluginManager) useInternal(plugin string) bool {
 		plugins.InternalStrategyTargetValue,
 		plugins.InternalStrategyThreshold,
 		plugins.InternalStrategyFixedValue,
+		plugins.InternalStrategyPercentile,
+		plugins.InternalStrategyWASM,
 		plugins.InternalTargetAWSASG,
 		plugins.InternalTargetAzureVMSS,
 		plugins.InternalTargetGCEMIG,
-		plugins.InternalAPMDatadog:
+		plugins.InternalTargetSimulator,
+		plugins.InternalAPMDatadog,
+		plugins.InternalAPMRabbitMQ,
+		plugins.InternalAPMKafkaLag,
+		plugins.InternalAPMSQS,
+		plugins.InternalAPMHTTPJSON,
+		plugins.InternalAPMSQL,
+		plugins.InternalAPMRedis,
+		plugins.InternalAPMConsul,
+		plugins.InternalAPMOpenCost,
+		plugins.InternalEventSinkKafka,
+		plugins.InternalEventSinkNATS,
+		plugins.InternalEventSinkSNS:
 		return true
 	default:
 		return false
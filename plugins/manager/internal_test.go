@@ -7,6 +7,7 @@ import (
 	"testing"
 
 	"github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/nomad-autoscaler/agent/config"
 	"github.com/hashicorp/nomad-autoscaler/plugins"
 	"github.com/stretchr/testify/assert"
 )
@@ -20,27 +21,27 @@ func TestPluginManager_useInternal(t *testing.T) {
 		expectedOutput bool
 	}{
 		{
-			inputPM:        NewPluginManager(l, "this/doesnt/exist", nil),
+			inputPM:        NewPluginManager(l, "this/doesnt/exist", config.PermissionChecksWarn, 0, nil),
 			inputPlugin:    plugins.InternalAPMNomad,
 			expectedOutput: true,
 		},
 		{
-			inputPM:        NewPluginManager(l, "this/doesnt/exist", nil),
+			inputPM:        NewPluginManager(l, "this/doesnt/exist", config.PermissionChecksWarn, 0, nil),
 			inputPlugin:    plugins.InternalTargetNomad,
 			expectedOutput: true,
 		},
 		{
-			inputPM:        NewPluginManager(l, "this/doesnt/exist", nil),
+			inputPM:        NewPluginManager(l, "this/doesnt/exist", config.PermissionChecksWarn, 0, nil),
 			inputPlugin:    plugins.InternalAPMPrometheus,
 			expectedOutput: true,
 		},
 		{
-			inputPM:        NewPluginManager(l, "this/doesnt/exist", nil),
+			inputPM:        NewPluginManager(l, "this/doesnt/exist", config.PermissionChecksWarn, 0, nil),
 			inputPlugin:    plugins.InternalStrategyTargetValue,
 			expectedOutput: true,
 		},
 		{
-			inputPM:        NewPluginManager(l, "this/doesnt/exist", nil),
+			inputPM:        NewPluginManager(l, "this/doesnt/exist", config.PermissionChecksWarn, 0, nil),
 			inputPlugin:    "this-plugin-doesnt-exist-either",
 			expectedOutput: false,
 		},
@@ -17,6 +17,7 @@ import (
 	"github.com/hashicorp/nomad-autoscaler/plugins"
 	"github.com/hashicorp/nomad-autoscaler/plugins/apm"
 	"github.com/hashicorp/nomad-autoscaler/plugins/base"
+	"github.com/hashicorp/nomad-autoscaler/plugins/eventsink"
 	"github.com/hashicorp/nomad-autoscaler/plugins/strategy"
 	targetpkg "github.com/hashicorp/nomad-autoscaler/plugins/target"
 	"github.com/hashicorp/nomad-autoscaler/sdk"
@@ -33,6 +34,31 @@ type PluginManager struct {
 	logger    hclog.Logger
 	pluginDir string
 
+	// permissionChecks controls the checks performed on the ownership and
+	// permissions of external plugin binaries before they are launched.
+	permissionChecks string
+
+	// idleTimeout enables lazy dispensing of external plugins. When set,
+	// external plugins are launched the first time they are dispensed and
+	// killed once unused for this period. When zero, all plugins are launched
+	// by Load and kept running.
+	idleTimeout time.Duration
+
+	// launchLock serializes the lazy launching of plugins so concurrent
+	// dispenses of the same plugin do not launch duplicate instances.
+	launchLock sync.Mutex
+
+	// lastUsed tracks when each plugin instance was last dispensed, and is
+	// used to find idle external plugins.
+	lastUsedLock sync.Mutex
+	lastUsed     map[plugins.PluginID]time.Time
+
+	// reaperOnce ensures the idle plugin reaper is only started once, and
+	// shutdownCh is closed to stop it.
+	reaperOnce   sync.Once
+	shutdownOnce sync.Once
+	shutdownCh   chan struct{}
+
 	// pluginInstances are our dispensed plugins held as PluginInstance
 	// wrappers.
 	pluginInstancesLock sync.RWMutex
@@ -42,6 +68,18 @@ type PluginManager struct {
 	// Nomad Autoscaler plugins.
 	pluginsLock sync.RWMutex
 	plugins     map[plugins.PluginID]*pluginInfo
+
+	// scopedInstances are plugins dispensed using config overrides defined
+	// within scaling policies. scopedUsers tracks the IDs of the policies
+	// using each of them, so they can be stopped once no policy uses them.
+	scopedInstancesLock sync.Mutex
+	scopedInstances     map[scopedPluginID]PluginInstance
+	scopedLastUsed      map[scopedPluginID]time.Time
+	scopedUsers         map[scopedPluginID]map[string]struct{}
+
+	// faults injects faults into the dispensed plugins when fault injection
+	// is enabled. It is nil otherwise.
+	faults *faultInjector
 }
 
 // pluginInfo contains all the required information to launch an Autoscaler
@@ -51,23 +89,47 @@ type pluginInfo struct {
 	baseInfo *base.PluginInfo
 	config   map[string]string
 
+	// policyOverrides are the config keys policies are allowed to override.
+	policyOverrides []string
+
+	// queryOptions are the config keys of APM plugins which checks can set
+	// to tune their queries. They are passed with the queries instead of
+	// being handled as overrides.
+	queryOptions []string
+
 	// args and exePath are required to execute the external plugin command.
 	driver  string
 	args    []string
 	exePath string
 
+	// calls controls the timeouts and concurrency of calls made to the
+	// external plugin, and startupTimeout limits the time taken to launch it.
+	calls          callOptions
+	startupTimeout time.Duration
+
 	// factory is only populated when the plugin is internal.
 	factory plugins.PluginFactory
+
+	// launchErr is the error of the last failed attempt to launch the
+	// plugin. It is cleared once the plugin is launched successfully.
+	launchErr error
 }
 
 // NewPluginManager sets up a new PluginManager for use.
-func NewPluginManager(log hclog.Logger, dir string, cfg map[string][]*config.Plugin) *PluginManager {
+func NewPluginManager(log hclog.Logger, dir, permChecks string, idleTimeout time.Duration, cfg map[string][]*config.Plugin) *PluginManager {
 	return &PluginManager{
-		cfg:             cfg,
-		logger:          log.Named("plugin_manager"),
-		pluginDir:       dir,
-		pluginInstances: make(map[plugins.PluginID]PluginInstance),
-		plugins:         make(map[plugins.PluginID]*pluginInfo),
+		cfg:              cfg,
+		logger:           log.ResetNamed("plugin_manager"),
+		pluginDir:        dir,
+		permissionChecks: permChecks,
+		idleTimeout:      idleTimeout,
+		lastUsed:         make(map[plugins.PluginID]time.Time),
+		shutdownCh:       make(chan struct{}),
+		pluginInstances:  make(map[plugins.PluginID]PluginInstance),
+		plugins:          make(map[plugins.PluginID]*pluginInfo),
+		scopedInstances:  make(map[scopedPluginID]PluginInstance),
+		scopedLastUsed:   make(map[scopedPluginID]time.Time),
+		scopedUsers:      make(map[scopedPluginID]map[string]struct{}),
 	}
 }
 
@@ -90,10 +152,20 @@ func (pm *PluginManager) Load() error {
 		}
 	}
 
+	// When lazy dispensing is enabled, external plugins are only launched
+	// once used, so start the reaper which shuts them down when idle.
+	if pm.idleTimeout > 0 {
+		pm.reaperOnce.Do(func() { go pm.reapIdlePlugins() })
+	}
+
 	return pm.dispensePlugins()
 }
 
 func (pm *PluginManager) Reload(newCfg map[string][]*config.Plugin) error {
+	// Stop plugins dispensed with policy overrides so they are launched again
+	// using the new agent config.
+	pm.killScopedPlugins()
+
 	// Find plugins that are no longer in the new config and stop them.
 	pluginsToStop := []plugins.PluginID{}
 
@@ -163,6 +235,9 @@ func (pm *PluginManager) Reload(newCfg map[string][]*config.Plugin) error {
 
 // KillPlugins calls Kill on all plugins currently dispensed.
 func (pm *PluginManager) KillPlugins() {
+	pm.shutdownOnce.Do(func() { close(pm.shutdownCh) })
+	pm.killScopedPlugins()
+
 	pm.pluginInstancesLock.Lock()
 	defer pm.pluginInstancesLock.Unlock()
 
@@ -182,6 +257,10 @@ func (pm *PluginManager) killPluginLocked(pID plugins.PluginID) {
 	pm.logger.Info("shutting down plugin", "plugin_name", pID.Name)
 	p.Kill()
 	delete(pm.pluginInstances, pID)
+
+	pm.lastUsedLock.Lock()
+	delete(pm.lastUsed, pID)
+	pm.lastUsedLock.Unlock()
 }
 
 // Dispense returns a PluginInstance for use by safely obtaining the
@@ -193,20 +272,26 @@ func (pm *PluginManager) Dispense(name, pluginType string) (PluginInstance, erro
 	labels := []metrics.Label{{Name: "plugin_name", Value: name}, {Name: "plugin_type", Value: pluginType}}
 	defer metrics.MeasureSinceWithLabels([]string{"plugin", "manager", "access_ms"}, time.Now(), labels)
 
-	pm.pluginInstancesLock.RLock()
-	defer pm.pluginInstancesLock.RUnlock()
+	pID := plugins.PluginID{Name: name, PluginType: pluginType}
 
 	// Attempt to pull our plugin instance from the store and pass this to the
-	// caller.
-	//
-	// TODO(jrasell) if we do not find the instance, we should probably try and
-	//  dispense the plugin. We should also check the plugin instance has not
-	//  exited.
-	inst, ok := pm.pluginInstances[plugins.PluginID{Name: name, PluginType: pluginType}]
-	if !ok {
+	// caller. The usage is recorded while holding the lock, so the idle
+	// reaper cannot kill an instance which has just been handed out.
+	pm.pluginInstancesLock.RLock()
+	inst, ok := pm.pluginInstances[pID]
+	if ok && !pm.exited(inst) {
+		pm.markUsed(pID)
+		pm.pluginInstancesLock.RUnlock()
+		return inst, nil
+	}
+	pm.pluginInstancesLock.RUnlock()
+
+	// Plugins are only launched on demand when lazy dispensing is enabled,
+	// otherwise all plugins have been launched by Load.
+	if pm.idleTimeout == 0 {
 		return nil, fmt.Errorf("failed to dispense plugin: %q of type %q is not stored", name, pluginType)
 	}
-	return inst, nil
+	return pm.dispenseLazy(pID)
 }
 
 // dispensePlugins launches all configured plugins. It is responsible for
@@ -225,35 +310,15 @@ func (pm *PluginManager) dispensePlugins() error {
 			continue
 		}
 
-		var (
-			inst PluginInstance
-			info *base.PluginInfo
-			err  error
-		)
-		if pInfo.factory != nil {
-			inst, info, err = pm.launchInternalPlugin(pID, pInfo)
-		} else {
-			inst, info, err = pm.launchExternalPlugin(pID, pInfo)
-		}
-
-		// If we got an error dispensing the plugin, add this to the muilterror
-		// and continue the loop.
-		if err != nil {
-			_ = multierror.Append(&mErr, fmt.Errorf("failed to dispense plugin %s: %v", pID.Name, err))
+		// External plugins are launched the first time they are dispensed
+		// when lazy dispensing is enabled.
+		if pm.idleTimeout > 0 && pInfo.factory == nil {
 			continue
 		}
 
-		// Update our tracking to detail the plugin base information returned
-		// from the plugin itself.
-		pm.pluginsLock.Lock()
-		pm.plugins[pID].baseInfo = info
-		pm.pluginsLock.Unlock()
-
-		// Perform the SetConfig on the plugin to ensure its state is as the
-		// operator desires.
-		if err := inst.Plugin().(base.Base).SetConfig(pInfo.config); err != nil {
-			inst.Kill()
-			_ = multierror.Append(&mErr, fmt.Errorf("failed to set config on plugin %s: %v", pID.Name, err))
+		inst, err := pm.launchPlugin(pID, pInfo)
+		if err != nil {
+			_ = multierror.Append(&mErr, err)
 			continue
 		}
 
@@ -270,6 +335,49 @@ func (pm *PluginManager) dispensePlugins() error {
 	return mErr.ErrorOrNil()
 }
 
+// launchPlugin launches the plugin and sets its config so it is in a ready
+// state.
+func (pm *PluginManager) launchPlugin(pID plugins.PluginID, pInfo *pluginInfo) (PluginInstance, error) {
+	var (
+		inst PluginInstance
+		info *base.PluginInfo
+		err  error
+	)
+	if pInfo.factory != nil {
+		inst, info, err = pm.launchInternalPlugin(pID, pInfo)
+	} else {
+		inst, info, err = pm.launchExternalPlugin(pID, pInfo)
+	}
+	if err != nil {
+		return nil, pm.setLaunchErr(pInfo, fmt.Errorf("failed to dispense plugin %s: %v", pID.Name, err))
+	}
+
+	// Update our tracking to detail the plugin base information returned
+	// from the plugin itself.
+	pm.pluginsLock.Lock()
+	pInfo.baseInfo = info
+	pm.pluginsLock.Unlock()
+
+	// Perform the SetConfig on the plugin to ensure its state is as the
+	// operator desires.
+	if err := inst.Plugin().(base.Base).SetConfig(pInfo.config); err != nil {
+		inst.Kill()
+		return nil, pm.setLaunchErr(pInfo, fmt.Errorf("failed to set config on plugin %s: %v", pID.Name, err))
+	}
+
+	pm.setLaunchErr(pInfo, nil)
+	return inst, nil
+}
+
+// setLaunchErr records the result of the last attempt to launch the plugin,
+// so it can be reported by PluginStatuses, and returns err.
+func (pm *PluginManager) setLaunchErr(pInfo *pluginInfo, err error) error {
+	pm.pluginsLock.Lock()
+	pInfo.launchErr = err
+	pm.pluginsLock.Unlock()
+	return err
+}
+
 // launchInternalPlugin is used to dispense internal plugins.
 func (pm *PluginManager) launchInternalPlugin(id plugins.PluginID, info *pluginInfo) (PluginInstance, *base.PluginInfo, error) {
 
@@ -287,15 +395,22 @@ func (pm *PluginManager) launchInternalPlugin(id plugins.PluginID, info *pluginI
 // ones.
 func (pm *PluginManager) launchExternalPlugin(id plugins.PluginID, info *pluginInfo) (PluginInstance, *base.PluginInfo, error) {
 
+	if err := pm.checkPluginPermissions(info.exePath); err != nil {
+		return nil, nil, fmt.Errorf("refusing to launch plugin %s: %v", id.Name, err)
+	}
+
 	// Create a new client for the external plugin. This includes items such as
 	// the command to execute and also the logger to use. The loggers name is
 	// reset to avoid confusion that the log line is from within the agent.
+	calls := newCallLimiter(pm.logger, id, info.calls)
 	client := plugin.NewClient(&plugin.ClientConfig{
 		HandshakeConfig:  plugins.Handshake,
 		Plugins:          getPluginMap(id.PluginType),
 		Cmd:              exec.Command(info.exePath, info.args...),
 		AllowedProtocols: []plugin.Protocol{plugin.ProtocolGRPC},
 		Logger:           pm.logger.ResetNamed("external_plugin"),
+		StartTimeout:     info.startupTimeout,
+		GRPCDialOptions:  calls.dialOptions(),
 	})
 
 	// Connect via RPC.
@@ -318,7 +433,7 @@ func (pm *PluginManager) launchExternalPlugin(id plugins.PluginID, info *pluginI
 		return nil, nil, err
 	}
 
-	return &externalPluginInstance{instance: raw, client: client}, pInfo, nil
+	return &externalPluginInstance{instance: raw, client: client, calls: calls}, pInfo, nil
 }
 
 func (pm *PluginManager) pluginLaunchCheck(id plugins.PluginID, info *pluginInfo, raw interface{}) (*base.PluginInfo, error) {
@@ -347,12 +462,17 @@ func (pm *PluginManager) pluginLaunchCheck(id plugins.PluginID, info *pluginInfo
 	return pluginInfo, nil
 }
 
-func (pm *PluginManager) GetTarget(target *sdk.ScalingPolicyTarget) (targetpkg.Target, error) {
+// GetTarget returns the target plugin configured with the config overrides
+// of the policy identified by policyID.
+func (pm *PluginManager) GetTarget(policyID string, target *sdk.ScalingPolicyTarget) (targetpkg.Target, error) {
 	// Dispense an instance of target plugin used by the policy.
-	targetPlugin, err := pm.Dispense(target.Name, sdk.PluginTypeTarget)
+	targetPlugin, err := pm.DispenseScoped(policyID, target.Name, sdk.PluginTypeTarget, target.PluginConfig)
 	if err != nil {
 		return nil, err
 	}
+	if err := pm.faults.crash(targetPlugin, target.Name); err != nil {
+		return nil, err
+	}
 
 	targetInst, ok := targetPlugin.Plugin().(targetpkg.Target)
 	if !ok {
@@ -360,20 +480,38 @@ func (pm *PluginManager) GetTarget(target *sdk.ScalingPolicyTarget) (targetpkg.T
 		return nil, err
 	}
 
-	return targetInst, nil
+	return pm.faults.wrapTarget(targetInst, target.Name), nil
 }
 
 func (pm *PluginManager) GetAPM(source string) (apm.APM, error) {
+	return pm.GetScopedAPM("", source, nil)
+}
+
+// GetScopedAPM returns the APM plugin configured with the config overrides of
+// the policy identified by policyID. Overrides which are query options of the
+// plugin are passed with its queries instead, so they never cause a scoped
+// instance to be launched.
+func (pm *PluginManager) GetScopedAPM(policyID, source string, overrides map[string]string) (apm.APM, error) {
+	overrides, queryOptions := pm.splitQueryOptions(source, overrides)
+
 	// Dispense plugins.
-	apmPlugin, err := pm.Dispense(source, sdk.PluginTypeAPM)
+	apmPlugin, err := pm.DispenseScoped(policyID, source, sdk.PluginTypeAPM, overrides)
 	if err != nil {
 		return nil, fmt.Errorf(`apm plugin "%s" not initialized: %v`, source, err)
 	}
+	if err := pm.faults.crash(apmPlugin, source); err != nil {
+		return nil, err
+	}
 	apmInst, ok := apmPlugin.Plugin().(apm.APM)
 	if !ok {
 		return nil, fmt.Errorf(`"%s" is not an APM plugin`, source)
 	}
-	return apmInst, nil
+
+	apmInst, err = apm.WithQueryOptions(apmInst, queryOptions)
+	if err != nil {
+		return nil, fmt.Errorf(`invalid query options for apm plugin "%s": %v`, source, err)
+	}
+	return pm.faults.wrapAPM(apmInst, source), nil
 }
 
 func (pm *PluginManager) GetStrategy(name string) (strategy.Strategy, error) {
@@ -381,6 +519,9 @@ func (pm *PluginManager) GetStrategy(name string) (strategy.Strategy, error) {
 	if err != nil {
 		return nil, fmt.Errorf(`strategy plugin "%s" not initialized: %v`, name, err)
 	}
+	if err := pm.faults.crash(strategyPlugin, name); err != nil {
+		return nil, err
+	}
 
 	strategyInst, ok := strategyPlugin.Plugin().(strategy.Strategy)
 	if !ok {
@@ -388,3 +529,30 @@ func (pm *PluginManager) GetStrategy(name string) (strategy.Strategy, error) {
 	}
 	return strategyInst, nil
 }
+
+// GetEventSinks returns all the configured event sink plugins. Event sinks
+// that fail to dispense are logged and omitted.
+func (pm *PluginManager) GetEventSinks() map[string]eventsink.EventSink {
+	var names []string
+
+	pm.pluginsLock.RLock()
+	for id := range pm.plugins {
+		if id.PluginType == sdk.PluginTypeEventSink {
+			names = append(names, id.Name)
+		}
+	}
+	pm.pluginsLock.RUnlock()
+
+	sinks := make(map[string]eventsink.EventSink, len(names))
+	for _, name := range names {
+		inst, err := pm.Dispense(name, sdk.PluginTypeEventSink)
+		if err != nil {
+			pm.logger.Warn("failed to dispense event sink", "plugin_name", name, "error", err)
+			continue
+		}
+		if sink, ok := inst.Plugin().(eventsink.EventSink); ok {
+			sinks[name] = sink
+		}
+	}
+	return sinks
+}
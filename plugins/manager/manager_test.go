@@ -12,6 +12,7 @@ import (
 	"github.com/hashicorp/nomad-autoscaler/plugins/strategy"
 	"github.com/hashicorp/nomad-autoscaler/plugins/target"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestLoad(t *testing.T) {
@@ -98,7 +99,7 @@ func TestLoad(t *testing.T) {
 
 	for _, tc := range cases {
 		t.Run(tc.name, func(t *testing.T) {
-			pm := NewPluginManager(logger, tc.pluginDir, tc.cfg)
+			pm := NewPluginManager(logger, tc.pluginDir, config.PermissionChecksWarn, 0, tc.cfg)
 			err := pm.Load()
 			defer pm.KillPlugins()
 
@@ -178,7 +179,7 @@ func TestDispense(t *testing.T) {
 
 	for _, tc := range cases {
 		t.Run(tc.name, func(t *testing.T) {
-			pm := NewPluginManager(logger, tc.pluginDir, tc.cfg)
+			pm := NewPluginManager(logger, tc.pluginDir, config.PermissionChecksWarn, 0, tc.cfg)
 			defer pm.KillPlugins()
 
 			err := pm.Load()
@@ -205,3 +206,171 @@ func TestDispense(t *testing.T) {
 		})
 	}
 }
+
+func TestDispenseScoped(t *testing.T) {
+	cfg := map[string][]*config.Plugin{
+		"apm": {
+			&config.Plugin{
+				Name:            "prometheus",
+				Driver:          "prometheus",
+				Config:          map[string]string{"address": "http://example.com"},
+				PolicyOverrides: []string{"address"},
+			},
+		},
+	}
+
+	pm := NewPluginManager(hclog.NewNullLogger(), "../test/bin", config.PermissionChecksWarn, 0, cfg)
+	defer pm.KillPlugins()
+	require.NoError(t, pm.Load())
+
+	base, err := pm.Dispense("prometheus", "apm")
+	require.NoError(t, err)
+
+	// No overrides returns the agent configured instance.
+	p, err := pm.DispenseScoped("policy1", "prometheus", "apm", nil)
+	require.NoError(t, err)
+	assert.Same(t, base, p)
+
+	// Allowed overrides return a new instance which is reused.
+	overrides := map[string]string{"address": "http://tenant.example.com"}
+	scoped, err := pm.DispenseScoped("policy1", "prometheus", "apm", overrides)
+	require.NoError(t, err)
+	assert.NotSame(t, base, scoped)
+
+	scopedAgain, err := pm.DispenseScoped("policy1", "prometheus", "apm", map[string]string{"address": "http://tenant.example.com"})
+	require.NoError(t, err)
+	assert.Same(t, scoped, scopedAgain)
+
+	// Overrides not allowed by the agent config are rejected.
+	_, err = pm.DispenseScoped("policy1", "prometheus", "apm", map[string]string{"basic_auth_password": "secret"})
+	assert.ErrorContains(t, err, "basic_auth_password")
+
+	// Reloading the plugins stops scoped instances.
+	require.NoError(t, pm.Reload(cfg))
+	assert.Empty(t, pm.scopedInstances)
+}
+
+func TestGetScopedAPM_queryOptions(t *testing.T) {
+	cfg := map[string][]*config.Plugin{
+		"apm": {
+			&config.Plugin{
+				Name:            "prometheus",
+				Driver:          "prometheus",
+				Config:          map[string]string{"address": "http://example.com"},
+				PolicyOverrides: []string{"address"},
+			},
+		},
+	}
+
+	pm := NewPluginManager(hclog.NewNullLogger(), "../test/bin", config.PermissionChecksWarn, 0, cfg)
+	defer pm.KillPlugins()
+	require.NoError(t, pm.Load())
+
+	// Query options don't need to be allowed by the agent config, and are
+	// passed with the queries instead of launching a scoped instance.
+	queryOptions := map[string]string{"query_step": "30s", "query_timeout": "1m"}
+	_, err := pm.GetScopedAPM("policy1", "prometheus", queryOptions)
+	require.NoError(t, err)
+	assert.Empty(t, pm.scopedInstances)
+
+	// Config overrides set along with query options still use a scoped
+	// instance, shared with the policies using other query options.
+	_, err = pm.GetScopedAPM("policy1", "prometheus", map[string]string{"address": "http://a.example.com", "query_step": "30s"})
+	require.NoError(t, err)
+	_, err = pm.GetScopedAPM("policy2", "prometheus", map[string]string{"address": "http://a.example.com", "query_step": "1m"})
+	require.NoError(t, err)
+	assert.Len(t, pm.scopedInstances, 1)
+
+	// Invalid query options are reported by the plugin.
+	_, err = pm.GetScopedAPM("policy1", "prometheus", map[string]string{"query_step": "soon"})
+	assert.ErrorContains(t, err, `invalid query options for apm plugin "prometheus"`)
+}
+
+func TestReleaseScoped(t *testing.T) {
+	cfg := map[string][]*config.Plugin{
+		"apm": {
+			&config.Plugin{
+				Name:            "prometheus",
+				Driver:          "prometheus",
+				Config:          map[string]string{"address": "http://example.com"},
+				PolicyOverrides: []string{"address"},
+			},
+		},
+	}
+
+	pm := NewPluginManager(hclog.NewNullLogger(), "../test/bin", config.PermissionChecksWarn, 0, cfg)
+	defer pm.KillPlugins()
+	require.NoError(t, pm.Load())
+
+	tenantA := map[string]string{"address": "http://a.example.com"}
+	tenantB := map[string]string{"address": "http://b.example.com"}
+
+	// Two policies share the instance of tenant A.
+	instA, err := pm.DispenseScoped("policy1", "prometheus", "apm", tenantA)
+	require.NoError(t, err)
+	_, err = pm.DispenseScoped("policy2", "prometheus", "apm", tenantA)
+	require.NoError(t, err)
+	_, err = pm.DispenseScoped("policy2", "prometheus", "apm", tenantB)
+	require.NoError(t, err)
+	require.Len(t, pm.scopedInstances, 2)
+
+	// Releasing a policy only stops the instances no other policy uses.
+	pm.ReleaseScoped("policy2")
+	require.Len(t, pm.scopedInstances, 1)
+
+	inst, err := pm.DispenseScoped("policy1", "prometheus", "apm", tenantA)
+	require.NoError(t, err)
+	assert.Same(t, instA, inst)
+
+	pm.ReleaseScoped("policy1")
+	assert.Empty(t, pm.scopedInstances)
+	assert.Empty(t, pm.scopedUsers)
+
+	// Releasing unknown policies is a no-op.
+	pm.ReleaseScoped("policy3")
+}
+
+func Test_validateOverrides(t *testing.T) {
+	testCases := []struct {
+		name        string
+		allowed     []string
+		overrides   map[string]string
+		expectedErr string
+	}{
+		{
+			name:      "all allowed",
+			allowed:   []string{"address", "index"},
+			overrides: map[string]string{"address": "http://example.com"},
+		},
+		{
+			name:        "none allowed",
+			overrides:   map[string]string{"address": "http://example.com"},
+			expectedErr: "keys address are not allowed by the plugin policy_overrides",
+		},
+		{
+			name:        "some denied",
+			allowed:     []string{"address"},
+			overrides:   map[string]string{"address": "a", "token": "b", "password": "c"},
+			expectedErr: "keys password, token are not allowed by the plugin policy_overrides",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := validateOverrides(tc.allowed, tc.overrides)
+			if tc.expectedErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tc.expectedErr)
+			}
+		})
+	}
+}
+
+func Test_canonicalOverrides(t *testing.T) {
+	a := canonicalOverrides(map[string]string{"b": "2", "a": "1"})
+	b := canonicalOverrides(map[string]string{"a": "1", "b": "2"})
+	assert.Equal(t, a, b)
+	assert.Equal(t, `"a"="1";"b"="2";`, a)
+	assert.NotEqual(t, a, canonicalOverrides(map[string]string{"a": "1", "b": "3"}))
+}
@@ -0,0 +1,45 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package manager
+
+import (
+	"errors"
+	"path/filepath"
+
+	multierror "github.com/hashicorp/go-multierror"
+	"github.com/hashicorp/nomad-autoscaler/agent/config"
+	"github.com/hashicorp/nomad-autoscaler/sdk/helper/file"
+)
+
+// checkPluginPermissions verifies the ownership and permissions of an external
+// plugin binary, and of the directory holding it, before it is launched since
+// plugins run with the agent credentials. World-writable plugin binaries are
+// always refused unless checks are disabled, while other issues are only
+// refused if checks are enforced.
+func (pm *PluginManager) checkPluginPermissions(exePath string) error {
+	if pm.permissionChecks == config.PermissionChecksDisabled {
+		return nil
+	}
+
+	var issues []file.PermissionIssue
+	for _, path := range []string{filepath.Dir(exePath), exePath} {
+		pathIssues, err := file.CheckPermissions(path)
+		if err != nil {
+			// Missing binaries are reported when launching the plugin.
+			continue
+		}
+		issues = append(issues, pathIssues...)
+	}
+
+	var mErr *multierror.Error
+	for _, issue := range issues {
+		if pm.permissionChecks == config.PermissionChecksEnforce || (issue.WorldWritable && issue.Path == exePath) {
+			mErr = multierror.Append(mErr, errors.New(issue.String()))
+			continue
+		}
+		pm.logger.Warn("plugin has unsafe permissions", "path", issue.Path, "issue", issue.Reason)
+	}
+
+	return mErr.ErrorOrNil()
+}
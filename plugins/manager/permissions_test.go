@@ -0,0 +1,74 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+//go:build !windows
+// +build !windows
+
+package manager
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/nomad-autoscaler/agent/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPluginManager_checkPluginPermissions(t *testing.T) {
+	testCases := []struct {
+		name        string
+		mode        os.FileMode
+		checks      string
+		expectError bool
+	}{
+		{
+			name:   "safe binary",
+			mode:   0755,
+			checks: config.PermissionChecksEnforce,
+		},
+		{
+			name:   "group writable binary warns",
+			mode:   0775,
+			checks: config.PermissionChecksWarn,
+		},
+		{
+			name:        "group writable binary enforced",
+			mode:        0775,
+			checks:      config.PermissionChecksEnforce,
+			expectError: true,
+		},
+		{
+			name:        "world writable binary is always refused",
+			mode:        0777,
+			checks:      config.PermissionChecksWarn,
+			expectError: true,
+		},
+		{
+			name:   "checks disabled",
+			mode:   0777,
+			checks: config.PermissionChecksDisabled,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			require.NoError(t, os.Chmod(dir, 0755))
+
+			exePath := filepath.Join(dir, "plugin")
+			require.NoError(t, os.WriteFile(exePath, nil, 0700))
+			require.NoError(t, os.Chmod(exePath, tc.mode))
+
+			pm := NewPluginManager(hclog.NewNullLogger(), dir, tc.checks, 0, nil)
+			err := pm.checkPluginPermissions(exePath)
+			if tc.expectError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
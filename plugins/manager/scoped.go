@@ -0,0 +1,220 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package manager
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/nomad-autoscaler/plugins"
+	"github.com/hashicorp/nomad-autoscaler/plugins/base"
+	"github.com/hashicorp/nomad-autoscaler/sdk"
+)
+
+// scopedPluginID identifies a plugin instance launched using config overrides
+// defined within a scaling policy. Policies using the same plugin and
+// overrides share the same instance.
+type scopedPluginID struct {
+	plugins.PluginID
+
+	// overrides is the canonical representation of the config overrides.
+	overrides string
+}
+
+// DispenseScoped returns a PluginInstance of the named plugin which has been
+// configured using the agent config of the plugin merged with the passed
+// overrides of the policy identified by policyID. If no overrides are passed,
+// the agent configured instance is returned.
+//
+// The instance is stopped once ReleaseScoped has been called for all the
+// policies it was dispensed to.
+func (pm *PluginManager) DispenseScoped(policyID, name, pluginType string, overrides map[string]string) (PluginInstance, error) {
+	if len(overrides) == 0 {
+		return pm.Dispense(name, pluginType)
+	}
+
+	pID := plugins.PluginID{Name: name, PluginType: pluginType}
+
+	pm.pluginsLock.RLock()
+	info, ok := pm.plugins[pID]
+	pm.pluginsLock.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("failed to dispense plugin: %q of type %q is not stored", name, pluginType)
+	}
+
+	if err := validateOverrides(info.policyOverrides, overrides); err != nil {
+		return nil, fmt.Errorf("invalid config overrides for plugin %q: %v", name, err)
+	}
+
+	sID := scopedPluginID{PluginID: pID, overrides: canonicalOverrides(overrides)}
+
+	// Hold the lock while launching the plugin so concurrent evaluations of
+	// policies with the same overrides do not launch duplicate instances.
+	pm.scopedInstancesLock.Lock()
+	defer pm.scopedInstancesLock.Unlock()
+
+	if inst, ok := pm.scopedInstances[sID]; ok {
+		if !pm.exited(inst) {
+			pm.scopedLastUsed[sID] = time.Now()
+			pm.addScopedUser(sID, policyID)
+			return inst, nil
+		}
+		pm.logger.Warn("scoped plugin has exited, relaunching", "plugin_name", name)
+		inst.Kill()
+		delete(pm.scopedInstances, sID)
+	}
+
+	scopedInfo := *info
+	scopedInfo.config = make(map[string]string, len(info.config)+len(overrides))
+	for k, v := range info.config {
+		scopedInfo.config[k] = v
+	}
+	for k, v := range overrides {
+		scopedInfo.config[k] = v
+	}
+
+	var (
+		inst PluginInstance
+		err  error
+	)
+	if scopedInfo.factory != nil {
+		inst, _, err = pm.launchInternalPlugin(pID, &scopedInfo)
+	} else {
+		inst, _, err = pm.launchExternalPlugin(pID, &scopedInfo)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to dispense plugin %s: %v", name, err)
+	}
+
+	if err := inst.Plugin().(base.Base).SetConfig(scopedInfo.config); err != nil {
+		inst.Kill()
+		return nil, fmt.Errorf("failed to set config on plugin %s: %v", name, err)
+	}
+
+	pm.scopedInstances[sID] = inst
+	pm.scopedLastUsed[sID] = time.Now()
+	pm.addScopedUser(sID, policyID)
+	pm.logger.Info("successfully launched and dispensed scoped plugin",
+		"plugin_name", name, "overrides", sortedKeys(overrides))
+
+	return inst, nil
+}
+
+// splitQueryOptions splits the overrides of the named APM plugin into the
+// config overrides and the query options of the plugin.
+func (pm *PluginManager) splitQueryOptions(name string, overrides map[string]string) (map[string]string, map[string]string) {
+	pm.pluginsLock.RLock()
+	info, ok := pm.plugins[plugins.PluginID{Name: name, PluginType: sdk.PluginTypeAPM}]
+	pm.pluginsLock.RUnlock()
+	if !ok || len(info.queryOptions) == 0 || len(overrides) == 0 {
+		return overrides, nil
+	}
+
+	config := make(map[string]string)
+	query := make(map[string]string)
+	for k, v := range overrides {
+		if slices.Contains(info.queryOptions, k) {
+			query[k] = v
+		} else {
+			config[k] = v
+		}
+	}
+	return config, query
+}
+
+// ReleaseScoped records that the policy identified by policyID no longer uses
+// the plugin instances dispensed to it, such as when the policy is removed or
+// its overrides change. Instances no longer used by any policy are stopped.
+func (pm *PluginManager) ReleaseScoped(policyID string) {
+	pm.scopedInstancesLock.Lock()
+	defer pm.scopedInstancesLock.Unlock()
+
+	for sID, users := range pm.scopedUsers {
+		if _, ok := users[policyID]; !ok {
+			continue
+		}
+
+		delete(users, policyID)
+		if len(users) > 0 {
+			continue
+		}
+		delete(pm.scopedUsers, sID)
+
+		if inst, ok := pm.scopedInstances[sID]; ok {
+			pm.logger.Info("shutting down scoped plugin no longer used by any policy", "plugin_name", sID.Name)
+			inst.Kill()
+			delete(pm.scopedInstances, sID)
+			delete(pm.scopedLastUsed, sID)
+		}
+	}
+}
+
+// addScopedUser records the policy as a user of the scoped instance. The
+// scopedInstancesLock must be held when calling it.
+func (pm *PluginManager) addScopedUser(sID scopedPluginID, policyID string) {
+	users, ok := pm.scopedUsers[sID]
+	if !ok {
+		users = make(map[string]struct{})
+		pm.scopedUsers[sID] = users
+	}
+	users[policyID] = struct{}{}
+}
+
+// killScopedPlugins stops all plugin instances launched using policy config
+// overrides. They will be launched again the next time they are dispensed.
+func (pm *PluginManager) killScopedPlugins() {
+	pm.scopedInstancesLock.Lock()
+	defer pm.scopedInstancesLock.Unlock()
+
+	for sID, inst := range pm.scopedInstances {
+		pm.logger.Info("shutting down scoped plugin", "plugin_name", sID.Name)
+		inst.Kill()
+		delete(pm.scopedInstances, sID)
+		delete(pm.scopedLastUsed, sID)
+		delete(pm.scopedUsers, sID)
+	}
+}
+
+// validateOverrides ensures all the passed overrides are allowed by the agent
+// plugin configuration.
+func validateOverrides(allowed []string, overrides map[string]string) error {
+	allowedSet := make(map[string]struct{}, len(allowed))
+	for _, k := range allowed {
+		allowedSet[k] = struct{}{}
+	}
+
+	var denied []string
+	for _, k := range sortedKeys(overrides) {
+		if _, ok := allowedSet[k]; !ok {
+			denied = append(denied, k)
+		}
+	}
+
+	if len(denied) > 0 {
+		return fmt.Errorf("keys %s are not allowed by the plugin policy_overrides", strings.Join(denied, ", "))
+	}
+	return nil
+}
+
+// canonicalOverrides returns a string representation of the overrides which
+// is the same regardless of the map iteration order.
+func canonicalOverrides(overrides map[string]string) string {
+	var b strings.Builder
+	for _, k := range sortedKeys(overrides) {
+		fmt.Fprintf(&b, "%q=%q;", k, overrides[k])
+	}
+	return b.String()
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,142 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package manager
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+
+	"github.com/hashicorp/nomad-autoscaler/plugins"
+	"github.com/hashicorp/nomad-autoscaler/version"
+)
+
+const (
+	// PluginStatusRunning indicates the plugin is launched and ready to be
+	// dispensed.
+	PluginStatusRunning = "running"
+
+	// PluginStatusNotLaunched indicates the external plugin has not been
+	// launched yet, or was shut down while idle, because lazy dispensing is
+	// enabled.
+	PluginStatusNotLaunched = "not_launched"
+
+	// PluginStatusFailed indicates the last attempt to launch the plugin
+	// failed.
+	PluginStatusFailed = "failed"
+
+	// PluginStatusExited indicates the process of the external plugin has
+	// exited unexpectedly.
+	PluginStatusExited = "exited"
+)
+
+// PluginStatus describes a plugin configured in the agent.
+type PluginStatus struct {
+	Name   string
+	Type   string
+	Driver string
+
+	// External indicates the plugin runs as a separate binary instead of
+	// within the agent.
+	External bool
+
+	// Version is the version of the agent for internal plugins. External
+	// plugins don't report their version, so ProtocolVersion holds the plugin
+	// protocol version negotiated when they were launched.
+	Version         string
+	ProtocolVersion int
+
+	// ConfigChecksum is the SHA256 checksum of the plugin config, which can
+	// be used to compare the config of agents without exposing its values.
+	ConfigChecksum string
+
+	// Status is one of the PluginStatus constants and Healthy indicates if
+	// the plugin can be dispensed.
+	Status  string
+	Healthy bool
+
+	// Error is the error of the last failed attempt to launch the plugin.
+	Error string
+}
+
+// PluginStatuses returns the status of all the plugins configured in the
+// agent, sorted by type and name.
+func (pm *PluginManager) PluginStatuses() []PluginStatus {
+	pm.pluginsLock.RLock()
+	defer pm.pluginsLock.RUnlock()
+
+	pm.pluginInstancesLock.RLock()
+	defer pm.pluginInstancesLock.RUnlock()
+
+	out := make([]PluginStatus, 0, len(pm.plugins))
+	for pID, info := range pm.plugins {
+		out = append(out, pm.pluginStatusLocked(pID, info))
+	}
+
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Type != out[j].Type {
+			return out[i].Type < out[j].Type
+		}
+		return out[i].Name < out[j].Name
+	})
+	return out
+}
+
+// pluginStatusLocked returns the status of the plugin. The pluginsLock and
+// pluginInstancesLock must be held by the caller.
+func (pm *PluginManager) pluginStatusLocked(pID plugins.PluginID, info *pluginInfo) PluginStatus {
+	s := PluginStatus{
+		Name:           pID.Name,
+		Type:           pID.PluginType,
+		Driver:         info.driver,
+		External:       info.factory == nil,
+		ConfigChecksum: configChecksum(info.config),
+	}
+	if !s.External {
+		s.Version = version.GetHumanVersion()
+	}
+
+	inst, ok := pm.pluginInstances[pID]
+	switch {
+	case ok:
+		s.Status = PluginStatusRunning
+		if ext, isExt := inst.(*externalPluginInstance); isExt {
+			s.ProtocolVersion = ext.client.NegotiatedVersion()
+			if ext.client.Exited() {
+				s.Status = PluginStatusExited
+			}
+		}
+	case info.launchErr != nil:
+		s.Status = PluginStatusFailed
+	default:
+		s.Status = PluginStatusNotLaunched
+	}
+
+	if info.launchErr != nil {
+		s.Error = info.launchErr.Error()
+	}
+	s.Healthy = s.Status == PluginStatusRunning || s.Status == PluginStatusNotLaunched
+	return s
+}
+
+// configChecksum returns the hex encoded SHA256 checksum of the config keys
+// and values, in sorted order.
+func configChecksum(cfg map[string]string) string {
+	keys := make([]string, 0, len(cfg))
+	for k := range cfg {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	h := sha256.New()
+	for _, k := range keys {
+		// Separate keys and values with a NUL byte so different configs
+		// can't produce the same input.
+		h.Write([]byte(k))
+		h.Write([]byte{0})
+		h.Write([]byte(cfg[k]))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
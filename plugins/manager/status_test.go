@@ -0,0 +1,63 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package manager
+
+import (
+	"testing"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/nomad-autoscaler/agent/config"
+	"github.com/hashicorp/nomad-autoscaler/version"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPluginManager_PluginStatuses(t *testing.T) {
+	cfg := map[string][]*config.Plugin{
+		"apm": {
+			&config.Plugin{
+				Name:   "prometheus",
+				Driver: "prometheus",
+				Config: map[string]string{"address": "http://example.com"},
+			},
+		},
+		"strategy": {
+			&config.Plugin{
+				Name:   "noop",
+				Driver: "noop-fake-strategy",
+			},
+		},
+	}
+
+	pm := NewPluginManager(hclog.NewNullLogger(), "../test/bin", config.PermissionChecksWarn, 0, cfg)
+	defer pm.KillPlugins()
+	require.Error(t, pm.Load())
+
+	out := pm.PluginStatuses()
+	require.Len(t, out, 2)
+
+	assert.Equal(t, "prometheus", out[0].Name)
+	assert.Equal(t, "apm", out[0].Type)
+	assert.False(t, out[0].External)
+	assert.Equal(t, version.GetHumanVersion(), out[0].Version)
+	assert.Equal(t, PluginStatusRunning, out[0].Status)
+	assert.True(t, out[0].Healthy)
+	assert.Empty(t, out[0].Error)
+	assert.Equal(t, configChecksum(map[string]string{"address": "http://example.com"}), out[0].ConfigChecksum)
+
+	assert.Equal(t, "noop", out[1].Name)
+	assert.Equal(t, "strategy", out[1].Type)
+	assert.True(t, out[1].External)
+	assert.Equal(t, PluginStatusFailed, out[1].Status)
+	assert.False(t, out[1].Healthy)
+	assert.Contains(t, out[1].Error, "failed to dispense plugin noop")
+}
+
+func Test_configChecksum(t *testing.T) {
+	a := configChecksum(map[string]string{"a": "b", "c": "d"})
+	assert.Len(t, a, 64)
+	assert.Equal(t, a, configChecksum(map[string]string{"c": "d", "a": "b"}))
+	assert.NotEqual(t, a, configChecksum(map[string]string{"a": "bc", "": "d"}))
+	assert.NotEqual(t, a, configChecksum(nil))
+}
@@ -7,6 +7,7 @@ import (
 	plugin "github.com/hashicorp/go-plugin"
 	"github.com/hashicorp/nomad-autoscaler/plugins/apm"
 	"github.com/hashicorp/nomad-autoscaler/plugins/base"
+	"github.com/hashicorp/nomad-autoscaler/plugins/eventsink"
 	"github.com/hashicorp/nomad-autoscaler/plugins/strategy"
 	"github.com/hashicorp/nomad-autoscaler/plugins/target"
 	"github.com/hashicorp/nomad-autoscaler/sdk"
@@ -27,6 +28,8 @@ func getPluginMap(pluginType string) map[string]plugin.Plugin {
 		m[pluginType] = &target.PluginTarget{}
 	case sdk.PluginTypeStrategy:
 		m[pluginType] = &strategy.PluginStrategy{}
+	case sdk.PluginTypeEventSink:
+		m[pluginType] = &eventsink.PluginEventSink{}
 	}
 	return m
 }
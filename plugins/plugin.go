@@ -10,6 +10,7 @@ import (
 	plugin "github.com/hashicorp/go-plugin"
 	"github.com/hashicorp/nomad-autoscaler/plugins/apm"
 	"github.com/hashicorp/nomad-autoscaler/plugins/base"
+	"github.com/hashicorp/nomad-autoscaler/plugins/eventsink"
 	"github.com/hashicorp/nomad-autoscaler/plugins/strategy"
 	"github.com/hashicorp/nomad-autoscaler/plugins/target"
 	"github.com/hashicorp/nomad-autoscaler/sdk"
@@ -39,6 +40,13 @@ const (
 	// InternalStrategyFixedValue is the Fixed Value Strategy internal plugin name.
 	InternalStrategyFixedValue = "fixed-value"
 
+	// InternalStrategyPercentile is the Percentile Strategy internal plugin
+	// name.
+	InternalStrategyPercentile = "percentile"
+
+	// InternalStrategyWASM is the WASM Strategy internal plugin name.
+	InternalStrategyWASM = "wasm"
+
 	// InternalTargetAWSASG is the Amazon Web Services AutoScaling Group target
 	// plugin.
 	InternalTargetAWSASG = "aws-asg"
@@ -51,8 +59,46 @@ const (
 	// plugin.
 	InternalTargetGCEMIG = "gce-mig"
 
+	// InternalTargetSimulator is the simulated target plugin, used to
+	// exercise policies without real infrastructure.
+	InternalTargetSimulator = "simulator"
+
 	// InternalAPMDatadog is the Datadog APM plugin name.
 	InternalAPMDatadog = "datadog"
+
+	// InternalAPMRabbitMQ is the RabbitMQ APM plugin name.
+	InternalAPMRabbitMQ = "rabbitmq"
+
+	// InternalAPMKafkaLag is the Kafka consumer lag APM plugin name.
+	InternalAPMKafkaLag = "kafka-lag"
+
+	// InternalAPMSQS is the Amazon Simple Queue Service APM plugin name.
+	InternalAPMSQS = "sqs"
+
+	// InternalAPMHTTPJSON is the generic HTTP/JSON APM plugin name.
+	InternalAPMHTTPJSON = "http-json"
+
+	// InternalAPMSQL is the PostgreSQL and MySQL APM plugin name.
+	InternalAPMSQL = "sql"
+
+	// InternalAPMRedis is the Redis APM plugin name.
+	InternalAPMRedis = "redis"
+
+	// InternalAPMConsul is the Consul APM plugin name.
+	InternalAPMConsul = "consul"
+
+	// InternalAPMOpenCost is the OpenCost APM plugin name.
+	InternalAPMOpenCost = "opencost"
+
+	// InternalEventSinkKafka is the Kafka event sink plugin name.
+	InternalEventSinkKafka = "kafka"
+
+	// InternalEventSinkNATS is the NATS event sink plugin name.
+	InternalEventSinkNATS = "nats"
+
+	// InternalEventSinkSNS is the Amazon Simple Notification Service event
+	// sink plugin name.
+	InternalEventSinkSNS = "sns"
 )
 
 // ConfigKeyNomadConfigInherit is a generic plugin config map key that supports
@@ -81,7 +127,16 @@ type PluginFactory func(log hclog.Logger) interface{}
 
 // InternalPluginConfig is a struct that internal plugins must implement in
 // order to provide critical information about the plugin and launching it.
-type InternalPluginConfig struct{ Factory PluginFactory }
+type InternalPluginConfig struct {
+	Factory PluginFactory
+
+	// QueryOptions are the config keys of an APM plugin which checks can set
+	// within their source_config to tune how their queries are performed,
+	// such as query timeouts. They are passed with the queries of the check
+	// instead of configuring a new plugin instance, so they don't need to be
+	// allowed by the agent plugin policy_overrides.
+	QueryOptions []string
+}
 
 // PluginID contains plugin metadata and identifies a plugin. It is used as a
 // key to aid plugin lookups. The information held here is also reflected
@@ -134,6 +189,11 @@ func Serve(f PluginFactory) {
 			sdk.PluginTypeStrategy: &strategy.PluginStrategy{Impl: p.(strategy.Strategy)},
 			sdk.PluginTypeBase:     &base.PluginBase{Impl: p.(strategy.Strategy)},
 		}
+	case eventsink.EventSink:
+		pCfg.Plugins = map[string]plugin.Plugin{
+			sdk.PluginTypeEventSink: &eventsink.PluginEventSink{Impl: p.(eventsink.EventSink)},
+			sdk.PluginTypeBase:      &base.PluginBase{Impl: p.(eventsink.EventSink)},
+		}
 	default:
 		logger.Error("unsupported plugin type %q", pType)
 		return
@@ -4,12 +4,14 @@
 package shared
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 
 	"github.com/golang/protobuf/ptypes"
 	"github.com/hashicorp/nomad-autoscaler/plugins/shared/proto/v1"
 	"github.com/hashicorp/nomad-autoscaler/sdk"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/protobuf/types/known/anypb"
 )
 
@@ -17,8 +19,34 @@ const (
 	// PluginConfigKeyGRPCTimeout is the config key used internaly to provide a
 	// timeout value for GRPC calls
 	PluginConfigKeyGRPCTimeout = "_nomad_autoscaler_grpc_timeout"
+
+	// GRPCMetadataKeyCorrelationID is the gRPC metadata key used to send the
+	// correlation ID of a scaling decision to plugins.
+	GRPCMetadataKeyCorrelationID = "nomad-autoscaler-correlation-id"
 )
 
+// ContextWithCorrelationID returns a context which sends the correlation ID
+// as gRPC metadata. The input context is returned unmodified if id is empty.
+func ContextWithCorrelationID(ctx context.Context, id string) context.Context {
+	if id == "" {
+		return ctx
+	}
+	return metadata.AppendToOutgoingContext(ctx, GRPCMetadataKeyCorrelationID, id)
+}
+
+// CorrelationIDFromContext returns the correlation ID received as gRPC
+// metadata, or an empty string if none was sent.
+func CorrelationIDFromContext(ctx context.Context) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+	if vals := md.Get(GRPCMetadataKeyCorrelationID); len(vals) > 0 {
+		return vals[0]
+	}
+	return ""
+}
+
 // ScalingDirectionToProto converts the input scale direction to the proto
 // equivalent.
 func ScalingDirectionToProto(input sdk.ScaleDirection) (proto.ScalingDirection, error) {
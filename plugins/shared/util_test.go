@@ -4,6 +4,7 @@
 package shared
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"testing"
@@ -13,10 +14,25 @@ import (
 	"github.com/hashicorp/nomad-autoscaler/plugins/shared/proto/v1"
 	"github.com/hashicorp/nomad-autoscaler/sdk"
 	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/protobuf/types/known/anypb"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
+func Test_CorrelationIDContext(t *testing.T) {
+	assert.Empty(t, CorrelationIDFromContext(context.Background()))
+
+	ctx := ContextWithCorrelationID(context.Background(), "")
+	_, ok := metadata.FromOutgoingContext(ctx)
+	assert.False(t, ok)
+
+	// Simulate the metadata being sent over the wire.
+	ctx = ContextWithCorrelationID(context.Background(), "eval-1")
+	md, ok := metadata.FromOutgoingContext(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, "eval-1", CorrelationIDFromContext(metadata.NewIncomingContext(context.Background(), md)))
+}
+
 func Test_ScalingDirectionToProto(t *testing.T) {
 	testCases := []struct {
 		input                   sdk.ScaleDirection
@@ -5,12 +5,16 @@ package strategy
 
 import (
 	"context"
+	"time"
 
+	"github.com/golang/protobuf/ptypes"
 	"github.com/hashicorp/nomad-autoscaler/plugins/base"
 	"github.com/hashicorp/nomad-autoscaler/plugins/shared"
 	sharedProto "github.com/hashicorp/nomad-autoscaler/plugins/shared/proto/v1"
 	"github.com/hashicorp/nomad-autoscaler/plugins/strategy/proto/v1"
 	"github.com/hashicorp/nomad-autoscaler/sdk"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 // pluginClient is the gRPC client implementation of the Strategy interface.
@@ -24,15 +28,30 @@ type pluginClient struct {
 	doneCTX context.Context
 }
 
+// WithContext is the gRPC client implementation of the
+// ContextStrategy.WithContext interface function. The returned client makes
+// its RPCs using ctx, so they are cancelled once ctx is done.
+func (p *pluginClient) WithContext(ctx context.Context) Strategy {
+	base := *p.PluginClient
+	base.DoneCtx = ctx
+	return &pluginClient{PluginClient: &base, client: p.client, doneCTX: ctx}
+}
+
 // Run is the gRPC client implementation of the Strategy.Run interface
 // function.
 func (p *pluginClient) Run(eval *sdk.ScalingCheckEvaluation, count int64) (*sdk.ScalingCheckEvaluation, error) {
 
+	history, err := historyToProto(eval.History)
+	if err != nil {
+		return nil, err
+	}
+
 	resp, err := p.client.Run(p.doneCTX, &proto.RunRequest{
 		Action:            &sharedProto.ScalingAction{},
 		Count:             count,
 		Check:             shared.ScalingPolicyCheckToProto(eval.Check),
 		TimestampedMetric: shared.TimestampedMetricsToProto(eval.Metrics),
+		History:           history,
 	})
 	if err != nil {
 		return nil, err
@@ -47,3 +66,51 @@ func (p *pluginClient) Run(eval *sdk.ScalingCheckEvaluation, count int64) (*sdk.
 	eval.Action = &action
 	return eval, nil
 }
+
+// historyToProto converts the input check history to the proto equivalent.
+// Lookback implements the LookbackStrategy interface. Plugins built before the
+// RPC was introduced don't implement it, in which case they don't require any
+// additional history.
+func (p *pluginClient) Lookback(check *sdk.ScalingPolicyCheck) (time.Duration, error) {
+	resp, err := p.client.Lookback(p.doneCTX, &proto.LookbackRequest{
+		Check: shared.ScalingPolicyCheckToProto(check),
+	})
+	if err != nil {
+		if status.Code(err) == codes.Unimplemented {
+			return 0, nil
+		}
+		return 0, err
+	}
+	if resp.GetLookback() == nil {
+		return 0, nil
+	}
+	return ptypes.Duration(resp.GetLookback())
+}
+
+func historyToProto(input []sdk.ScalingCheckHistoryEntry) ([]*proto.ScalingCheckHistoryEntry, error) {
+	if len(input) == 0 {
+		return nil, nil
+	}
+
+	out := make([]*proto.ScalingCheckHistoryEntry, len(input))
+
+	for i, h := range input {
+		action, err := shared.ScalingActionToProto(h.Action)
+		if err != nil {
+			return nil, err
+		}
+
+		ts, err := ptypes.TimestampProto(h.Timestamp)
+		if err != nil {
+			return nil, err
+		}
+
+		out[i] = &proto.ScalingCheckHistoryEntry{
+			Timestamp: ts,
+			Count:     h.Count,
+			Metric:    h.Metric,
+			Action:    action,
+		}
+	}
+	return out, nil
+}
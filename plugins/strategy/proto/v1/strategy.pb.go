@@ -12,6 +12,8 @@ package proto
 import (
 	context "context"
 	proto "github.com/golang/protobuf/proto"
+	duration "github.com/golang/protobuf/ptypes/duration"
+	timestamp "github.com/golang/protobuf/ptypes/timestamp"
 	v1 "github.com/hashicorp/nomad-autoscaler/plugins/shared/proto/v1"
 	grpc "google.golang.org/grpc"
 	codes "google.golang.org/grpc/codes"
@@ -38,10 +40,11 @@ type RunRequest struct {
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Action            *v1.ScalingAction       `protobuf:"bytes,1,opt,name=action,proto3" json:"action,omitempty"`
-	Check             *v1.ScalingPolicyCheck  `protobuf:"bytes,2,opt,name=check,proto3" json:"check,omitempty"`
-	TimestampedMetric []*v1.TimestampedMetric `protobuf:"bytes,3,rep,name=timestamped_metric,json=timestampedMetric,proto3" json:"timestamped_metric,omitempty"`
-	Count             int64                   `protobuf:"varint,4,opt,name=count,proto3" json:"count,omitempty"`
+	Action            *v1.ScalingAction           `protobuf:"bytes,1,opt,name=action,proto3" json:"action,omitempty"`
+	Check             *v1.ScalingPolicyCheck      `protobuf:"bytes,2,opt,name=check,proto3" json:"check,omitempty"`
+	TimestampedMetric []*v1.TimestampedMetric     `protobuf:"bytes,3,rep,name=timestamped_metric,json=timestampedMetric,proto3" json:"timestamped_metric,omitempty"`
+	Count             int64                       `protobuf:"varint,4,opt,name=count,proto3" json:"count,omitempty"`
+	History           []*ScalingCheckHistoryEntry `protobuf:"bytes,5,rep,name=history,proto3" json:"history,omitempty"`
 }
 
 func (x *RunRequest) Reset() {
@@ -104,6 +107,13 @@ func (x *RunRequest) GetCount() int64 {
 	return 0
 }
 
+func (x *RunRequest) GetHistory() []*ScalingCheckHistoryEntry {
+	if x != nil {
+		return x.History
+	}
+	return nil
+}
+
 type RunResponse struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -167,6 +177,171 @@ func (x *RunResponse) GetTimestampedMetric() []*v1.TimestampedMetric {
 	return nil
 }
 
+type ScalingCheckHistoryEntry struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Timestamp *timestamp.Timestamp `protobuf:"bytes,1,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	Count     int64                `protobuf:"varint,2,opt,name=count,proto3" json:"count,omitempty"`
+	Metric    float64              `protobuf:"fixed64,3,opt,name=metric,proto3" json:"metric,omitempty"`
+	Action    *v1.ScalingAction    `protobuf:"bytes,4,opt,name=action,proto3" json:"action,omitempty"`
+}
+
+func (x *ScalingCheckHistoryEntry) Reset() {
+	*x = ScalingCheckHistoryEntry{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_plugins_strategy_proto_v1_strategy_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ScalingCheckHistoryEntry) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ScalingCheckHistoryEntry) ProtoMessage() {}
+
+func (x *ScalingCheckHistoryEntry) ProtoReflect() protoreflect.Message {
+	mi := &file_plugins_strategy_proto_v1_strategy_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ScalingCheckHistoryEntry.ProtoReflect.Descriptor instead.
+func (*ScalingCheckHistoryEntry) Descriptor() ([]byte, []int) {
+	return file_plugins_strategy_proto_v1_strategy_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ScalingCheckHistoryEntry) GetTimestamp() *timestamp.Timestamp {
+	if x != nil {
+		return x.Timestamp
+	}
+	return nil
+}
+
+func (x *ScalingCheckHistoryEntry) GetCount() int64 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+func (x *ScalingCheckHistoryEntry) GetMetric() float64 {
+	if x != nil {
+		return x.Metric
+	}
+	return 0
+}
+
+func (x *ScalingCheckHistoryEntry) GetAction() *v1.ScalingAction {
+	if x != nil {
+		return x.Action
+	}
+	return nil
+}
+
+type LookbackRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Check *v1.ScalingPolicyCheck `protobuf:"bytes,1,opt,name=check,proto3" json:"check,omitempty"`
+}
+
+func (x *LookbackRequest) Reset() {
+	*x = LookbackRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_plugins_strategy_proto_v1_strategy_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *LookbackRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LookbackRequest) ProtoMessage() {}
+
+func (x *LookbackRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_plugins_strategy_proto_v1_strategy_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LookbackRequest.ProtoReflect.Descriptor instead.
+func (*LookbackRequest) Descriptor() ([]byte, []int) {
+	return file_plugins_strategy_proto_v1_strategy_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *LookbackRequest) GetCheck() *v1.ScalingPolicyCheck {
+	if x != nil {
+		return x.Check
+	}
+	return nil
+}
+
+type LookbackResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Lookback *duration.Duration `protobuf:"bytes,1,opt,name=lookback,proto3" json:"lookback,omitempty"`
+}
+
+func (x *LookbackResponse) Reset() {
+	*x = LookbackResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_plugins_strategy_proto_v1_strategy_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *LookbackResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LookbackResponse) ProtoMessage() {}
+
+func (x *LookbackResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_plugins_strategy_proto_v1_strategy_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LookbackResponse.ProtoReflect.Descriptor instead.
+func (*LookbackResponse) Descriptor() ([]byte, []int) {
+	return file_plugins_strategy_proto_v1_strategy_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *LookbackResponse) GetLookback() *duration.Duration {
+	if x != nil {
+		return x.Lookback
+	}
+	return nil
+}
+
 var File_plugins_strategy_proto_v1_strategy_proto protoreflect.FileDescriptor
 
 var file_plugins_strategy_proto_v1_strategy_proto_rawDesc = []byte{
@@ -176,10 +351,42 @@ var file_plugins_strategy_proto_v1_strategy_proto_rawDesc = []byte{
 	0x69, 0x63, 0x6f, 0x72, 0x70, 0x2e, 0x6e, 0x6f, 0x6d, 0x61, 0x64, 0x5f, 0x61, 0x75, 0x74, 0x6f,
 	0x73, 0x63, 0x61, 0x6c, 0x65, 0x72, 0x2e, 0x70, 0x6c, 0x75, 0x67, 0x69, 0x6e, 0x73, 0x2e, 0x73,
 	0x74, 0x72, 0x61, 0x74, 0x65, 0x67, 0x79, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x76, 0x31,
-	0x1a, 0x24, 0x70, 0x6c, 0x75, 0x67, 0x69, 0x6e, 0x73, 0x2f, 0x73, 0x68, 0x61, 0x72, 0x65, 0x64,
-	0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2f, 0x76, 0x31, 0x2f, 0x73, 0x68, 0x61, 0x72, 0x65, 0x64,
-	0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0xd1, 0x02, 0x0a, 0x0a, 0x52, 0x75, 0x6e, 0x52, 0x65,
-	0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x59, 0x0a, 0x06, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x18,
+	0x1a, 0x1e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75,
+	0x66, 0x2f, 0x64, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x1a, 0x1f, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75,
+	0x66, 0x2f, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x2e, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x1a, 0x24, 0x70, 0x6c, 0x75, 0x67, 0x69, 0x6e, 0x73, 0x2f, 0x73, 0x68, 0x61, 0x72, 0x65,
+	0x64, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2f, 0x76, 0x31, 0x2f, 0x73, 0x68, 0x61, 0x72, 0x65,
+	0x64, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0xbb, 0x03, 0x0a, 0x0a, 0x52, 0x75, 0x6e, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x59, 0x0a, 0x06, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x41, 0x2e, 0x68, 0x61, 0x73, 0x68, 0x69, 0x63, 0x6f,
+	0x72, 0x70, 0x2e, 0x6e, 0x6f, 0x6d, 0x61, 0x64, 0x5f, 0x61, 0x75, 0x74, 0x6f, 0x73, 0x63, 0x61,
+	0x6c, 0x65, 0x72, 0x2e, 0x70, 0x6c, 0x75, 0x67, 0x69, 0x6e, 0x73, 0x2e, 0x73, 0x68, 0x61, 0x72,
+	0x65, 0x64, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x63, 0x61, 0x6c,
+	0x69, 0x6e, 0x67, 0x41, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x06, 0x61, 0x63, 0x74, 0x69, 0x6f,
+	0x6e, 0x12, 0x5c, 0x0a, 0x05, 0x63, 0x68, 0x65, 0x63, 0x6b, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x46, 0x2e, 0x68, 0x61, 0x73, 0x68, 0x69, 0x63, 0x6f, 0x72, 0x70, 0x2e, 0x6e, 0x6f, 0x6d,
+	0x61, 0x64, 0x5f, 0x61, 0x75, 0x74, 0x6f, 0x73, 0x63, 0x61, 0x6c, 0x65, 0x72, 0x2e, 0x70, 0x6c,
+	0x75, 0x67, 0x69, 0x6e, 0x73, 0x2e, 0x73, 0x68, 0x61, 0x72, 0x65, 0x64, 0x2e, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x63, 0x61, 0x6c, 0x69, 0x6e, 0x67, 0x50, 0x6f, 0x6c,
+	0x69, 0x63, 0x79, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x52, 0x05, 0x63, 0x68, 0x65, 0x63, 0x6b, 0x12,
+	0x74, 0x0a, 0x12, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x65, 0x64, 0x5f, 0x6d,
+	0x65, 0x74, 0x72, 0x69, 0x63, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x45, 0x2e, 0x68, 0x61,
+	0x73, 0x68, 0x69, 0x63, 0x6f, 0x72, 0x70, 0x2e, 0x6e, 0x6f, 0x6d, 0x61, 0x64, 0x5f, 0x61, 0x75,
+	0x74, 0x6f, 0x73, 0x63, 0x61, 0x6c, 0x65, 0x72, 0x2e, 0x70, 0x6c, 0x75, 0x67, 0x69, 0x6e, 0x73,
+	0x2e, 0x73, 0x68, 0x61, 0x72, 0x65, 0x64, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x76, 0x31,
+	0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x65, 0x64, 0x4d, 0x65, 0x74, 0x72,
+	0x69, 0x63, 0x52, 0x11, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x65, 0x64, 0x4d,
+	0x65, 0x74, 0x72, 0x69, 0x63, 0x12, 0x14, 0x0a, 0x05, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x04,
+	0x20, 0x01, 0x28, 0x03, 0x52, 0x05, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x12, 0x68, 0x0a, 0x07, 0x68,
+	0x69, 0x73, 0x74, 0x6f, 0x72, 0x79, 0x18, 0x05, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x4e, 0x2e, 0x68,
+	0x61, 0x73, 0x68, 0x69, 0x63, 0x6f, 0x72, 0x70, 0x2e, 0x6e, 0x6f, 0x6d, 0x61, 0x64, 0x5f, 0x61,
+	0x75, 0x74, 0x6f, 0x73, 0x63, 0x61, 0x6c, 0x65, 0x72, 0x2e, 0x70, 0x6c, 0x75, 0x67, 0x69, 0x6e,
+	0x73, 0x2e, 0x73, 0x74, 0x72, 0x61, 0x74, 0x65, 0x67, 0x79, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x2e, 0x76, 0x31, 0x2e, 0x53, 0x63, 0x61, 0x6c, 0x69, 0x6e, 0x67, 0x43, 0x68, 0x65, 0x63, 0x6b,
+	0x48, 0x69, 0x73, 0x74, 0x6f, 0x72, 0x79, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x07, 0x68, 0x69,
+	0x73, 0x74, 0x6f, 0x72, 0x79, 0x22, 0xbc, 0x02, 0x0a, 0x0b, 0x52, 0x75, 0x6e, 0x52, 0x65, 0x73,
+	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x59, 0x0a, 0x06, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x18,
 	0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x41, 0x2e, 0x68, 0x61, 0x73, 0x68, 0x69, 0x63, 0x6f, 0x72,
 	0x70, 0x2e, 0x6e, 0x6f, 0x6d, 0x61, 0x64, 0x5f, 0x61, 0x75, 0x74, 0x6f, 0x73, 0x63, 0x61, 0x6c,
 	0x65, 0x72, 0x2e, 0x70, 0x6c, 0x75, 0x67, 0x69, 0x6e, 0x73, 0x2e, 0x73, 0x68, 0x61, 0x72, 0x65,
@@ -198,40 +405,54 @@ var file_plugins_strategy_proto_v1_strategy_proto_rawDesc = []byte{
 	0x73, 0x68, 0x61, 0x72, 0x65, 0x64, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x76, 0x31, 0x2e,
 	0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x65, 0x64, 0x4d, 0x65, 0x74, 0x72, 0x69,
 	0x63, 0x52, 0x11, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x65, 0x64, 0x4d, 0x65,
-	0x74, 0x72, 0x69, 0x63, 0x12, 0x14, 0x0a, 0x05, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x04, 0x20,
-	0x01, 0x28, 0x03, 0x52, 0x05, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x22, 0xbc, 0x02, 0x0a, 0x0b, 0x52,
-	0x75, 0x6e, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x59, 0x0a, 0x06, 0x61, 0x63,
-	0x74, 0x69, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x41, 0x2e, 0x68, 0x61, 0x73,
-	0x68, 0x69, 0x63, 0x6f, 0x72, 0x70, 0x2e, 0x6e, 0x6f, 0x6d, 0x61, 0x64, 0x5f, 0x61, 0x75, 0x74,
-	0x6f, 0x73, 0x63, 0x61, 0x6c, 0x65, 0x72, 0x2e, 0x70, 0x6c, 0x75, 0x67, 0x69, 0x6e, 0x73, 0x2e,
-	0x73, 0x68, 0x61, 0x72, 0x65, 0x64, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x76, 0x31, 0x2e,
-	0x53, 0x63, 0x61, 0x6c, 0x69, 0x6e, 0x67, 0x41, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x06, 0x61,
-	0x63, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x5c, 0x0a, 0x05, 0x63, 0x68, 0x65, 0x63, 0x6b, 0x18, 0x02,
-	0x20, 0x01, 0x28, 0x0b, 0x32, 0x46, 0x2e, 0x68, 0x61, 0x73, 0x68, 0x69, 0x63, 0x6f, 0x72, 0x70,
+	0x74, 0x72, 0x69, 0x63, 0x22, 0xdd, 0x01, 0x0a, 0x18, 0x53, 0x63, 0x61, 0x6c, 0x69, 0x6e, 0x67,
+	0x43, 0x68, 0x65, 0x63, 0x6b, 0x48, 0x69, 0x73, 0x74, 0x6f, 0x72, 0x79, 0x45, 0x6e, 0x74, 0x72,
+	0x79, 0x12, 0x38, 0x0a, 0x09, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70,
+	0x52, 0x09, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x12, 0x14, 0x0a, 0x05, 0x63,
+	0x6f, 0x75, 0x6e, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x05, 0x63, 0x6f, 0x75, 0x6e,
+	0x74, 0x12, 0x16, 0x0a, 0x06, 0x6d, 0x65, 0x74, 0x72, 0x69, 0x63, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x01, 0x52, 0x06, 0x6d, 0x65, 0x74, 0x72, 0x69, 0x63, 0x12, 0x59, 0x0a, 0x06, 0x61, 0x63, 0x74,
+	0x69, 0x6f, 0x6e, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x41, 0x2e, 0x68, 0x61, 0x73, 0x68,
+	0x69, 0x63, 0x6f, 0x72, 0x70, 0x2e, 0x6e, 0x6f, 0x6d, 0x61, 0x64, 0x5f, 0x61, 0x75, 0x74, 0x6f,
+	0x73, 0x63, 0x61, 0x6c, 0x65, 0x72, 0x2e, 0x70, 0x6c, 0x75, 0x67, 0x69, 0x6e, 0x73, 0x2e, 0x73,
+	0x68, 0x61, 0x72, 0x65, 0x64, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x76, 0x31, 0x2e, 0x53,
+	0x63, 0x61, 0x6c, 0x69, 0x6e, 0x67, 0x41, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x06, 0x61, 0x63,
+	0x74, 0x69, 0x6f, 0x6e, 0x22, 0x6f, 0x0a, 0x0f, 0x4c, 0x6f, 0x6f, 0x6b, 0x62, 0x61, 0x63, 0x6b,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x5c, 0x0a, 0x05, 0x63, 0x68, 0x65, 0x63, 0x6b,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x46, 0x2e, 0x68, 0x61, 0x73, 0x68, 0x69, 0x63, 0x6f,
+	0x72, 0x70, 0x2e, 0x6e, 0x6f, 0x6d, 0x61, 0x64, 0x5f, 0x61, 0x75, 0x74, 0x6f, 0x73, 0x63, 0x61,
+	0x6c, 0x65, 0x72, 0x2e, 0x70, 0x6c, 0x75, 0x67, 0x69, 0x6e, 0x73, 0x2e, 0x73, 0x68, 0x61, 0x72,
+	0x65, 0x64, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x63, 0x61, 0x6c,
+	0x69, 0x6e, 0x67, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x52, 0x05,
+	0x63, 0x68, 0x65, 0x63, 0x6b, 0x22, 0x49, 0x0a, 0x10, 0x4c, 0x6f, 0x6f, 0x6b, 0x62, 0x61, 0x63,
+	0x6b, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x35, 0x0a, 0x08, 0x6c, 0x6f, 0x6f,
+	0x6b, 0x62, 0x61, 0x63, 0x6b, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x67, 0x6f,
+	0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x44, 0x75,
+	0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x08, 0x6c, 0x6f, 0x6f, 0x6b, 0x62, 0x61, 0x63, 0x6b,
+	0x32, 0xc4, 0x02, 0x0a, 0x15, 0x53, 0x74, 0x72, 0x61, 0x74, 0x65, 0x67, 0x79, 0x50, 0x6c, 0x75,
+	0x67, 0x69, 0x6e, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x8c, 0x01, 0x0a, 0x03, 0x52,
+	0x75, 0x6e, 0x12, 0x40, 0x2e, 0x68, 0x61, 0x73, 0x68, 0x69, 0x63, 0x6f, 0x72, 0x70, 0x2e, 0x6e,
+	0x6f, 0x6d, 0x61, 0x64, 0x5f, 0x61, 0x75, 0x74, 0x6f, 0x73, 0x63, 0x61, 0x6c, 0x65, 0x72, 0x2e,
+	0x70, 0x6c, 0x75, 0x67, 0x69, 0x6e, 0x73, 0x2e, 0x73, 0x74, 0x72, 0x61, 0x74, 0x65, 0x67, 0x79,
+	0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x76, 0x31, 0x2e, 0x52, 0x75, 0x6e, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x1a, 0x41, 0x2e, 0x68, 0x61, 0x73, 0x68, 0x69, 0x63, 0x6f, 0x72, 0x70,
 	0x2e, 0x6e, 0x6f, 0x6d, 0x61, 0x64, 0x5f, 0x61, 0x75, 0x74, 0x6f, 0x73, 0x63, 0x61, 0x6c, 0x65,
-	0x72, 0x2e, 0x70, 0x6c, 0x75, 0x67, 0x69, 0x6e, 0x73, 0x2e, 0x73, 0x68, 0x61, 0x72, 0x65, 0x64,
-	0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x63, 0x61, 0x6c, 0x69, 0x6e,
-	0x67, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x52, 0x05, 0x63, 0x68,
-	0x65, 0x63, 0x6b, 0x12, 0x74, 0x0a, 0x12, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70,
-	0x65, 0x64, 0x5f, 0x6d, 0x65, 0x74, 0x72, 0x69, 0x63, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32,
-	0x45, 0x2e, 0x68, 0x61, 0x73, 0x68, 0x69, 0x63, 0x6f, 0x72, 0x70, 0x2e, 0x6e, 0x6f, 0x6d, 0x61,
-	0x64, 0x5f, 0x61, 0x75, 0x74, 0x6f, 0x73, 0x63, 0x61, 0x6c, 0x65, 0x72, 0x2e, 0x70, 0x6c, 0x75,
-	0x67, 0x69, 0x6e, 0x73, 0x2e, 0x73, 0x68, 0x61, 0x72, 0x65, 0x64, 0x2e, 0x70, 0x72, 0x6f, 0x74,
-	0x6f, 0x2e, 0x76, 0x31, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x65, 0x64,
-	0x4d, 0x65, 0x74, 0x72, 0x69, 0x63, 0x52, 0x11, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d,
-	0x70, 0x65, 0x64, 0x4d, 0x65, 0x74, 0x72, 0x69, 0x63, 0x32, 0xa6, 0x01, 0x0a, 0x15, 0x53, 0x74,
-	0x72, 0x61, 0x74, 0x65, 0x67, 0x79, 0x50, 0x6c, 0x75, 0x67, 0x69, 0x6e, 0x53, 0x65, 0x72, 0x76,
-	0x69, 0x63, 0x65, 0x12, 0x8c, 0x01, 0x0a, 0x03, 0x52, 0x75, 0x6e, 0x12, 0x40, 0x2e, 0x68, 0x61,
-	0x73, 0x68, 0x69, 0x63, 0x6f, 0x72, 0x70, 0x2e, 0x6e, 0x6f, 0x6d, 0x61, 0x64, 0x5f, 0x61, 0x75,
-	0x74, 0x6f, 0x73, 0x63, 0x61, 0x6c, 0x65, 0x72, 0x2e, 0x70, 0x6c, 0x75, 0x67, 0x69, 0x6e, 0x73,
-	0x2e, 0x73, 0x74, 0x72, 0x61, 0x74, 0x65, 0x67, 0x79, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e,
-	0x76, 0x31, 0x2e, 0x52, 0x75, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x41, 0x2e,
+	0x72, 0x2e, 0x70, 0x6c, 0x75, 0x67, 0x69, 0x6e, 0x73, 0x2e, 0x73, 0x74, 0x72, 0x61, 0x74, 0x65,
+	0x67, 0x79, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x76, 0x31, 0x2e, 0x52, 0x75, 0x6e, 0x52,
+	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x12, 0x9b, 0x01, 0x0a, 0x08, 0x4c, 0x6f,
+	0x6f, 0x6b, 0x62, 0x61, 0x63, 0x6b, 0x12, 0x45, 0x2e, 0x68, 0x61, 0x73, 0x68, 0x69, 0x63, 0x6f,
+	0x72, 0x70, 0x2e, 0x6e, 0x6f, 0x6d, 0x61, 0x64, 0x5f, 0x61, 0x75, 0x74, 0x6f, 0x73, 0x63, 0x61,
+	0x6c, 0x65, 0x72, 0x2e, 0x70, 0x6c, 0x75, 0x67, 0x69, 0x6e, 0x73, 0x2e, 0x73, 0x74, 0x72, 0x61,
+	0x74, 0x65, 0x67, 0x79, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x76, 0x31, 0x2e, 0x4c, 0x6f,
+	0x6f, 0x6b, 0x62, 0x61, 0x63, 0x6b, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x46, 0x2e,
 	0x68, 0x61, 0x73, 0x68, 0x69, 0x63, 0x6f, 0x72, 0x70, 0x2e, 0x6e, 0x6f, 0x6d, 0x61, 0x64, 0x5f,
 	0x61, 0x75, 0x74, 0x6f, 0x73, 0x63, 0x61, 0x6c, 0x65, 0x72, 0x2e, 0x70, 0x6c, 0x75, 0x67, 0x69,
 	0x6e, 0x73, 0x2e, 0x73, 0x74, 0x72, 0x61, 0x74, 0x65, 0x67, 0x79, 0x2e, 0x70, 0x72, 0x6f, 0x74,
-	0x6f, 0x2e, 0x76, 0x31, 0x2e, 0x52, 0x75, 0x6e, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
-	0x22, 0x00, 0x42, 0x07, 0x5a, 0x05, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x06, 0x70, 0x72, 0x6f,
-	0x74, 0x6f, 0x33,
+	0x6f, 0x2e, 0x76, 0x31, 0x2e, 0x4c, 0x6f, 0x6f, 0x6b, 0x62, 0x61, 0x63, 0x6b, 0x52, 0x65, 0x73,
+	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x42, 0x07, 0x5a, 0x05, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
 }
 
 var (
@@ -246,28 +467,40 @@ func file_plugins_strategy_proto_v1_strategy_proto_rawDescGZIP() []byte {
 	return file_plugins_strategy_proto_v1_strategy_proto_rawDescData
 }
 
-var file_plugins_strategy_proto_v1_strategy_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_plugins_strategy_proto_v1_strategy_proto_msgTypes = make([]protoimpl.MessageInfo, 5)
 var file_plugins_strategy_proto_v1_strategy_proto_goTypes = []interface{}{
-	(*RunRequest)(nil),            // 0: hashicorp.nomad_autoscaler.plugins.strategy.proto.v1.RunRequest
-	(*RunResponse)(nil),           // 1: hashicorp.nomad_autoscaler.plugins.strategy.proto.v1.RunResponse
-	(*v1.ScalingAction)(nil),      // 2: hashicorp.nomad_autoscaler.plugins.shared.proto.v1.ScalingAction
-	(*v1.ScalingPolicyCheck)(nil), // 3: hashicorp.nomad_autoscaler.plugins.shared.proto.v1.ScalingPolicyCheck
-	(*v1.TimestampedMetric)(nil),  // 4: hashicorp.nomad_autoscaler.plugins.shared.proto.v1.TimestampedMetric
+	(*RunRequest)(nil),               // 0: hashicorp.nomad_autoscaler.plugins.strategy.proto.v1.RunRequest
+	(*RunResponse)(nil),              // 1: hashicorp.nomad_autoscaler.plugins.strategy.proto.v1.RunResponse
+	(*ScalingCheckHistoryEntry)(nil), // 2: hashicorp.nomad_autoscaler.plugins.strategy.proto.v1.ScalingCheckHistoryEntry
+	(*LookbackRequest)(nil),          // 3: hashicorp.nomad_autoscaler.plugins.strategy.proto.v1.LookbackRequest
+	(*LookbackResponse)(nil),         // 4: hashicorp.nomad_autoscaler.plugins.strategy.proto.v1.LookbackResponse
+	(*v1.ScalingAction)(nil),         // 5: hashicorp.nomad_autoscaler.plugins.shared.proto.v1.ScalingAction
+	(*v1.ScalingPolicyCheck)(nil),    // 6: hashicorp.nomad_autoscaler.plugins.shared.proto.v1.ScalingPolicyCheck
+	(*v1.TimestampedMetric)(nil),     // 7: hashicorp.nomad_autoscaler.plugins.shared.proto.v1.TimestampedMetric
+	(*timestamp.Timestamp)(nil),      // 8: google.protobuf.Timestamp
+	(*duration.Duration)(nil),        // 9: google.protobuf.Duration
 }
 var file_plugins_strategy_proto_v1_strategy_proto_depIdxs = []int32{
-	2, // 0: hashicorp.nomad_autoscaler.plugins.strategy.proto.v1.RunRequest.action:type_name -> hashicorp.nomad_autoscaler.plugins.shared.proto.v1.ScalingAction
-	3, // 1: hashicorp.nomad_autoscaler.plugins.strategy.proto.v1.RunRequest.check:type_name -> hashicorp.nomad_autoscaler.plugins.shared.proto.v1.ScalingPolicyCheck
-	4, // 2: hashicorp.nomad_autoscaler.plugins.strategy.proto.v1.RunRequest.timestamped_metric:type_name -> hashicorp.nomad_autoscaler.plugins.shared.proto.v1.TimestampedMetric
-	2, // 3: hashicorp.nomad_autoscaler.plugins.strategy.proto.v1.RunResponse.action:type_name -> hashicorp.nomad_autoscaler.plugins.shared.proto.v1.ScalingAction
-	3, // 4: hashicorp.nomad_autoscaler.plugins.strategy.proto.v1.RunResponse.check:type_name -> hashicorp.nomad_autoscaler.plugins.shared.proto.v1.ScalingPolicyCheck
-	4, // 5: hashicorp.nomad_autoscaler.plugins.strategy.proto.v1.RunResponse.timestamped_metric:type_name -> hashicorp.nomad_autoscaler.plugins.shared.proto.v1.TimestampedMetric
-	0, // 6: hashicorp.nomad_autoscaler.plugins.strategy.proto.v1.StrategyPluginService.Run:input_type -> hashicorp.nomad_autoscaler.plugins.strategy.proto.v1.RunRequest
-	1, // 7: hashicorp.nomad_autoscaler.plugins.strategy.proto.v1.StrategyPluginService.Run:output_type -> hashicorp.nomad_autoscaler.plugins.strategy.proto.v1.RunResponse
-	7, // [7:8] is the sub-list for method output_type
-	6, // [6:7] is the sub-list for method input_type
-	6, // [6:6] is the sub-list for extension type_name
-	6, // [6:6] is the sub-list for extension extendee
-	0, // [0:6] is the sub-list for field type_name
+	5,  // 0: hashicorp.nomad_autoscaler.plugins.strategy.proto.v1.RunRequest.action:type_name -> hashicorp.nomad_autoscaler.plugins.shared.proto.v1.ScalingAction
+	6,  // 1: hashicorp.nomad_autoscaler.plugins.strategy.proto.v1.RunRequest.check:type_name -> hashicorp.nomad_autoscaler.plugins.shared.proto.v1.ScalingPolicyCheck
+	7,  // 2: hashicorp.nomad_autoscaler.plugins.strategy.proto.v1.RunRequest.timestamped_metric:type_name -> hashicorp.nomad_autoscaler.plugins.shared.proto.v1.TimestampedMetric
+	2,  // 3: hashicorp.nomad_autoscaler.plugins.strategy.proto.v1.RunRequest.history:type_name -> hashicorp.nomad_autoscaler.plugins.strategy.proto.v1.ScalingCheckHistoryEntry
+	5,  // 4: hashicorp.nomad_autoscaler.plugins.strategy.proto.v1.RunResponse.action:type_name -> hashicorp.nomad_autoscaler.plugins.shared.proto.v1.ScalingAction
+	6,  // 5: hashicorp.nomad_autoscaler.plugins.strategy.proto.v1.RunResponse.check:type_name -> hashicorp.nomad_autoscaler.plugins.shared.proto.v1.ScalingPolicyCheck
+	7,  // 6: hashicorp.nomad_autoscaler.plugins.strategy.proto.v1.RunResponse.timestamped_metric:type_name -> hashicorp.nomad_autoscaler.plugins.shared.proto.v1.TimestampedMetric
+	8,  // 7: hashicorp.nomad_autoscaler.plugins.strategy.proto.v1.ScalingCheckHistoryEntry.timestamp:type_name -> google.protobuf.Timestamp
+	5,  // 8: hashicorp.nomad_autoscaler.plugins.strategy.proto.v1.ScalingCheckHistoryEntry.action:type_name -> hashicorp.nomad_autoscaler.plugins.shared.proto.v1.ScalingAction
+	6,  // 9: hashicorp.nomad_autoscaler.plugins.strategy.proto.v1.LookbackRequest.check:type_name -> hashicorp.nomad_autoscaler.plugins.shared.proto.v1.ScalingPolicyCheck
+	9,  // 10: hashicorp.nomad_autoscaler.plugins.strategy.proto.v1.LookbackResponse.lookback:type_name -> google.protobuf.Duration
+	0,  // 11: hashicorp.nomad_autoscaler.plugins.strategy.proto.v1.StrategyPluginService.Run:input_type -> hashicorp.nomad_autoscaler.plugins.strategy.proto.v1.RunRequest
+	3,  // 12: hashicorp.nomad_autoscaler.plugins.strategy.proto.v1.StrategyPluginService.Lookback:input_type -> hashicorp.nomad_autoscaler.plugins.strategy.proto.v1.LookbackRequest
+	1,  // 13: hashicorp.nomad_autoscaler.plugins.strategy.proto.v1.StrategyPluginService.Run:output_type -> hashicorp.nomad_autoscaler.plugins.strategy.proto.v1.RunResponse
+	4,  // 14: hashicorp.nomad_autoscaler.plugins.strategy.proto.v1.StrategyPluginService.Lookback:output_type -> hashicorp.nomad_autoscaler.plugins.strategy.proto.v1.LookbackResponse
+	13, // [13:15] is the sub-list for method output_type
+	11, // [11:13] is the sub-list for method input_type
+	11, // [11:11] is the sub-list for extension type_name
+	11, // [11:11] is the sub-list for extension extendee
+	0,  // [0:11] is the sub-list for field type_name
 }
 
 func init() { file_plugins_strategy_proto_v1_strategy_proto_init() }
@@ -300,6 +533,42 @@ func file_plugins_strategy_proto_v1_strategy_proto_init() {
 				return nil
 			}
 		}
+		file_plugins_strategy_proto_v1_strategy_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ScalingCheckHistoryEntry); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_plugins_strategy_proto_v1_strategy_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*LookbackRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_plugins_strategy_proto_v1_strategy_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*LookbackResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
 	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
@@ -307,7 +576,7 @@ func file_plugins_strategy_proto_v1_strategy_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_plugins_strategy_proto_v1_strategy_proto_rawDesc,
 			NumEnums:      0,
-			NumMessages:   2,
+			NumMessages:   5,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -334,6 +603,7 @@ const _ = grpc.SupportPackageIsVersion6
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
 type StrategyPluginServiceClient interface {
 	Run(ctx context.Context, in *RunRequest, opts ...grpc.CallOption) (*RunResponse, error)
+	Lookback(ctx context.Context, in *LookbackRequest, opts ...grpc.CallOption) (*LookbackResponse, error)
 }
 
 type strategyPluginServiceClient struct {
@@ -353,9 +623,19 @@ func (c *strategyPluginServiceClient) Run(ctx context.Context, in *RunRequest, o
 	return out, nil
 }
 
+func (c *strategyPluginServiceClient) Lookback(ctx context.Context, in *LookbackRequest, opts ...grpc.CallOption) (*LookbackResponse, error) {
+	out := new(LookbackResponse)
+	err := c.cc.Invoke(ctx, "/hashicorp.nomad_autoscaler.plugins.strategy.proto.v1.StrategyPluginService/Lookback", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // StrategyPluginServiceServer is the server API for StrategyPluginService service.
 type StrategyPluginServiceServer interface {
 	Run(context.Context, *RunRequest) (*RunResponse, error)
+	Lookback(context.Context, *LookbackRequest) (*LookbackResponse, error)
 }
 
 // UnimplementedStrategyPluginServiceServer can be embedded to have forward compatible implementations.
@@ -365,6 +645,9 @@ type UnimplementedStrategyPluginServiceServer struct {
 func (*UnimplementedStrategyPluginServiceServer) Run(context.Context, *RunRequest) (*RunResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Run not implemented")
 }
+func (*UnimplementedStrategyPluginServiceServer) Lookback(context.Context, *LookbackRequest) (*LookbackResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Lookback not implemented")
+}
 
 func RegisterStrategyPluginServiceServer(s *grpc.Server, srv StrategyPluginServiceServer) {
 	s.RegisterService(&_StrategyPluginService_serviceDesc, srv)
@@ -388,6 +671,24 @@ func _StrategyPluginService_Run_Handler(srv interface{}, ctx context.Context, de
 	return interceptor(ctx, in, info, handler)
 }
 
+func _StrategyPluginService_Lookback_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LookbackRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StrategyPluginServiceServer).Lookback(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/hashicorp.nomad_autoscaler.plugins.strategy.proto.v1.StrategyPluginService/Lookback",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StrategyPluginServiceServer).Lookback(ctx, req.(*LookbackRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var _StrategyPluginService_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "hashicorp.nomad_autoscaler.plugins.strategy.proto.v1.StrategyPluginService",
 	HandlerType: (*StrategyPluginServiceServer)(nil),
@@ -396,6 +697,10 @@ var _StrategyPluginService_serviceDesc = grpc.ServiceDesc{
 			MethodName: "Run",
 			Handler:    _StrategyPluginService_Run_Handler,
 		},
+		{
+			MethodName: "Lookback",
+			Handler:    _StrategyPluginService_Lookback_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "plugins/strategy/proto/v1/strategy.proto",
@@ -6,6 +6,7 @@ package strategy
 import (
 	"context"
 
+	"github.com/golang/protobuf/ptypes"
 	plugin "github.com/hashicorp/go-plugin"
 	"github.com/hashicorp/nomad-autoscaler/plugins/shared"
 	"github.com/hashicorp/nomad-autoscaler/plugins/strategy/proto/v1"
@@ -26,12 +27,19 @@ func (p *pluginServer) Run(_ context.Context, req *proto.RunRequest) (*proto.Run
 		return nil, err
 	}
 
+	history, err := protoToHistory(req.GetHistory())
+	if err != nil {
+		return nil, err
+	}
+
 	// Populate the eval. At this point of the evaluation flow we will only
-	// have Check and Metrics sections populated, so only translate this.
+	// have Check, Metrics and History sections populated, so only translate
+	// this.
 	eval := sdk.ScalingCheckEvaluation{
 		Action:  &sdk.ScalingAction{},
 		Check:   check,
 		Metrics: shared.ProtoToTimestampedMetrics(req.TimestampedMetric),
+		History: history,
 	}
 
 	resp, err := p.impl.Run(&eval, req.GetCount())
@@ -53,3 +61,53 @@ func (p *pluginServer) Run(_ context.Context, req *proto.RunRequest) (*proto.Run
 		TimestampedMetric: req.GetTimestampedMetric(),
 	}, nil
 }
+
+// protoToHistory converts the input proto check history and returns the
+// Autoscaler equivalent.
+// Lookback returns the history required by the plugin implementation if it
+// implements the LookbackStrategy interface, or zero otherwise.
+func (p *pluginServer) Lookback(_ context.Context, req *proto.LookbackRequest) (*proto.LookbackResponse, error) {
+	impl, ok := p.impl.(LookbackStrategy)
+	if !ok {
+		return &proto.LookbackResponse{}, nil
+	}
+
+	check, err := shared.ProtoToScalingPolicyCheck(req.GetCheck())
+	if err != nil {
+		return nil, err
+	}
+
+	lookback, err := impl.Lookback(check)
+	if err != nil {
+		return nil, err
+	}
+	return &proto.LookbackResponse{Lookback: ptypes.DurationProto(lookback)}, nil
+}
+
+func protoToHistory(input []*proto.ScalingCheckHistoryEntry) ([]sdk.ScalingCheckHistoryEntry, error) {
+	if len(input) == 0 {
+		return nil, nil
+	}
+
+	out := make([]sdk.ScalingCheckHistoryEntry, len(input))
+
+	for i, h := range input {
+		action, err := shared.ProtoToScalingAction(h.GetAction())
+		if err != nil {
+			return nil, err
+		}
+
+		ts, err := ptypes.Timestamp(h.GetTimestamp())
+		if err != nil {
+			return nil, err
+		}
+
+		out[i] = sdk.ScalingCheckHistoryEntry{
+			Timestamp: ts,
+			Count:     h.GetCount(),
+			Metric:    h.GetMetric(),
+			Action:    action,
+		}
+	}
+	return out, nil
+}
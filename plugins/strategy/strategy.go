@@ -4,6 +4,9 @@
 package strategy
 
 import (
+	"context"
+	"time"
+
 	"github.com/hashicorp/nomad-autoscaler/plugins/base"
 	"github.com/hashicorp/nomad-autoscaler/sdk"
 )
@@ -22,3 +25,33 @@ type Strategy interface {
 	// the current state of the scaling target.
 	Run(eval *sdk.ScalingCheckEvaluation, count int64) (*sdk.ScalingCheckEvaluation, error)
 }
+
+// LookbackStrategy is an optional interface implemented by Strategy plugins
+// that need more historical metrics than the query window of the check
+// provides, such as strategies that forecast the metric.
+type LookbackStrategy interface {
+
+	// Lookback returns how far back in time the metrics of the check must be
+	// read for the strategy to run. The Autoscaler uses the check query
+	// window when the returned value is smaller.
+	Lookback(check *sdk.ScalingPolicyCheck) (time.Duration, error)
+}
+
+// ContextStrategy is an optional interface implemented by Strategy plugins
+// whose calls can be bound to a context, so that calls still in progress are
+// cancelled once the context is done.
+type ContextStrategy interface {
+
+	// WithContext returns a copy of the Strategy whose calls are made using
+	// ctx.
+	WithContext(ctx context.Context) Strategy
+}
+
+// WithContext returns the Strategy with its calls bound to ctx if it
+// implements ContextStrategy, otherwise the Strategy is returned unmodified.
+func WithContext(ctx context.Context, s Strategy) Strategy {
+	if c, ok := s.(ContextStrategy); ok {
+		return c.WithContext(ctx)
+	}
+	return s
+}
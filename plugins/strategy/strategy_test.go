@@ -4,10 +4,14 @@
 package strategy
 
 import (
+	"context"
 	"os/exec"
 	"testing"
+	"time"
 
 	"github.com/hashicorp/go-plugin"
+	"github.com/hashicorp/nomad-autoscaler/plugins/shared"
+	"github.com/hashicorp/nomad-autoscaler/plugins/strategy/proto/v1"
 	"github.com/hashicorp/nomad-autoscaler/sdk"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -93,3 +97,94 @@ func TestStrategyPluginRPCServerRun(t *testing.T) {
 	assert.NotNil(t, resultEval)
 	assert.Equal(t, int64(5), resultEval.Action.Count)
 }
+
+func TestStrategyPluginRPCServerLookback(t *testing.T) {
+	client := plugin.NewClient(&plugin.ClientConfig{
+		HandshakeConfig:  handshake,
+		Plugins:          map[string]plugin.Plugin{"strategy": &PluginStrategy{}},
+		Cmd:              exec.Command("../test/bin/noop-strategy"),
+		AllowedProtocols: []plugin.Protocol{plugin.ProtocolGRPC},
+	})
+	defer client.Kill()
+
+	rpcClient, err := client.Client()
+	require.NoError(t, err)
+
+	raw, err := rpcClient.Dispense("strategy")
+	require.NoError(t, err)
+
+	// The client always implements the capability, but the noop plugin
+	// doesn't require any additional history.
+	lookbackImpl, ok := raw.(LookbackStrategy)
+	require.True(t, ok)
+
+	lookback, err := lookbackImpl.Lookback(&sdk.ScalingPolicyCheck{
+		Strategy: &sdk.ScalingPolicyStrategy{},
+	})
+	require.NoError(t, err)
+	assert.Zero(t, lookback)
+}
+
+// testLookbackStrategy is a Strategy that requires the history set in its
+// check config.
+type testLookbackStrategy struct {
+	Strategy
+}
+
+func (s *testLookbackStrategy) Lookback(check *sdk.ScalingPolicyCheck) (time.Duration, error) {
+	return time.ParseDuration(check.Strategy.Config["lookback"])
+}
+
+func Test_pluginServer_Lookback(t *testing.T) {
+	check := shared.ScalingPolicyCheckToProto(&sdk.ScalingPolicyCheck{
+		Strategy: &sdk.ScalingPolicyStrategy{
+			Name:   "forecast",
+			Config: map[string]string{"lookback": "24h"},
+		},
+	})
+
+	srv := &pluginServer{impl: &testLookbackStrategy{}}
+	resp, err := srv.Lookback(context.Background(), &proto.LookbackRequest{Check: check})
+	require.NoError(t, err)
+	assert.Equal(t, 24*time.Hour, resp.GetLookback().AsDuration())
+
+	// Errors from the implementation are returned.
+	check.Strategy.Config["lookback"] = "invalid"
+	_, err = srv.Lookback(context.Background(), &proto.LookbackRequest{Check: check})
+	assert.Error(t, err)
+
+	// Strategies without the capability don't require additional history.
+	srv = &pluginServer{}
+	resp, err = srv.Lookback(context.Background(), &proto.LookbackRequest{Check: check})
+	require.NoError(t, err)
+	assert.Nil(t, resp.GetLookback())
+}
+
+func Test_historyProtoRoundTrip(t *testing.T) {
+	input := []sdk.ScalingCheckHistoryEntry{
+		{
+			Timestamp: time.Unix(1600000000, 0).UTC(),
+			Count:     3,
+			Metric:    72.5,
+			Action: sdk.ScalingAction{
+				Count:     5,
+				Reason:    "scaling up",
+				Direction: sdk.ScaleDirectionUp,
+				Meta:      map[string]interface{}{"key": "value"},
+			},
+		},
+	}
+
+	protoHistory, err := historyToProto(input)
+	require.NoError(t, err)
+	require.Len(t, protoHistory, 1)
+
+	output, err := protoToHistory(protoHistory)
+	require.NoError(t, err)
+	assert.Equal(t, input, output)
+
+	// Empty history should not be sent over the wire.
+	protoHistory, err = historyToProto(nil)
+	require.NoError(t, err)
+	assert.Nil(t, protoHistory)
+}
@@ -11,6 +11,8 @@ import (
 	"github.com/hashicorp/nomad-autoscaler/plugins/shared"
 	"github.com/hashicorp/nomad-autoscaler/plugins/target/proto/v1"
 	"github.com/hashicorp/nomad-autoscaler/sdk"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 // pluginClient is the gRPC client implementation of the Target interface.
@@ -31,7 +33,8 @@ func (p *pluginClient) Scale(action sdk.ScalingAction, config map[string]string)
 	if err != nil {
 		return err
 	}
-	_, err = p.client.Scale(p.doneCTX, &proto.ScaleRequest{Action: req, Config: config})
+	ctx := shared.ContextWithCorrelationID(p.doneCTX, action.CorrelationID())
+	_, err = p.client.Scale(ctx, &proto.ScaleRequest{Action: req, Config: config})
 	return err
 }
 
@@ -61,3 +64,27 @@ func (p *pluginClient) Status(config map[string]string) (*sdk.TargetStatus, erro
 		Meta:  statusResp.Meta,
 	}, nil
 }
+
+// Capacity implements the CapacityReporter interface. Plugins built before
+// the RPC was introduced don't implement it, in which case the provider
+// capacity is unknown and no limit is reported.
+func (p *pluginClient) Capacity(config map[string]string) (*sdk.TargetCapacity, error) {
+	resp, err := p.client.Capacity(p.doneCTX, &proto.CapacityRequest{Config: config})
+	if err != nil {
+		if status.Code(err) == codes.Unimplemented {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	capacity := &sdk.TargetCapacity{}
+	if resp.GetMaxCount() != nil {
+		maxCount := resp.GetMaxCount().GetValue()
+		capacity.MaxCount = &maxCount
+	}
+	if resp.GetRemaining() != nil {
+		remaining := resp.GetRemaining().GetValue()
+		capacity.Remaining = &remaining
+	}
+	return capacity, nil
+}
@@ -12,6 +12,7 @@ package proto
 import (
 	context "context"
 	proto "github.com/golang/protobuf/proto"
+	wrappers "github.com/golang/protobuf/ptypes/wrappers"
 	v1 "github.com/hashicorp/nomad-autoscaler/plugins/shared/proto/v1"
 	grpc "google.golang.org/grpc"
 	codes "google.golang.org/grpc/codes"
@@ -236,6 +237,108 @@ func (x *StatusResponse) GetMeta() map[string]string {
 	return nil
 }
 
+type CapacityRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Config map[string]string `protobuf:"bytes,1,rep,name=config,proto3" json:"config,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+}
+
+func (x *CapacityRequest) Reset() {
+	*x = CapacityRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_plugins_target_proto_v1_target_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CapacityRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CapacityRequest) ProtoMessage() {}
+
+func (x *CapacityRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_plugins_target_proto_v1_target_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CapacityRequest.ProtoReflect.Descriptor instead.
+func (*CapacityRequest) Descriptor() ([]byte, []int) {
+	return file_plugins_target_proto_v1_target_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *CapacityRequest) GetConfig() map[string]string {
+	if x != nil {
+		return x.Config
+	}
+	return nil
+}
+
+type CapacityResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	MaxCount  *wrappers.Int64Value `protobuf:"bytes,1,opt,name=max_count,json=maxCount,proto3" json:"max_count,omitempty"`
+	Remaining *wrappers.Int64Value `protobuf:"bytes,2,opt,name=remaining,proto3" json:"remaining,omitempty"`
+}
+
+func (x *CapacityResponse) Reset() {
+	*x = CapacityResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_plugins_target_proto_v1_target_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CapacityResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CapacityResponse) ProtoMessage() {}
+
+func (x *CapacityResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_plugins_target_proto_v1_target_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CapacityResponse.ProtoReflect.Descriptor instead.
+func (*CapacityResponse) Descriptor() ([]byte, []int) {
+	return file_plugins_target_proto_v1_target_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *CapacityResponse) GetMaxCount() *wrappers.Int64Value {
+	if x != nil {
+		return x.MaxCount
+	}
+	return nil
+}
+
+func (x *CapacityResponse) GetRemaining() *wrappers.Int64Value {
+	if x != nil {
+		return x.Remaining
+	}
+	return nil
+}
+
 var File_plugins_target_proto_v1_target_proto protoreflect.FileDescriptor
 
 var file_plugins_target_proto_v1_target_proto_rawDesc = []byte{
@@ -244,7 +347,9 @@ var file_plugins_target_proto_v1_target_proto_rawDesc = []byte{
 	0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x32, 0x68, 0x61, 0x73, 0x68, 0x69, 0x63, 0x6f, 0x72,
 	0x70, 0x2e, 0x6e, 0x6f, 0x6d, 0x61, 0x64, 0x5f, 0x61, 0x75, 0x74, 0x6f, 0x73, 0x63, 0x61, 0x6c,
 	0x65, 0x72, 0x2e, 0x70, 0x6c, 0x75, 0x67, 0x69, 0x6e, 0x73, 0x2e, 0x74, 0x61, 0x72, 0x67, 0x65,
-	0x74, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x76, 0x31, 0x1a, 0x24, 0x70, 0x6c, 0x75, 0x67,
+	0x74, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x76, 0x31, 0x1a, 0x1e, 0x67, 0x6f, 0x6f, 0x67,
+	0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x77, 0x72, 0x61, 0x70,
+	0x70, 0x65, 0x72, 0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x24, 0x70, 0x6c, 0x75, 0x67,
 	0x69, 0x6e, 0x73, 0x2f, 0x73, 0x68, 0x61, 0x72, 0x65, 0x64, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f,
 	0x2f, 0x76, 0x31, 0x2f, 0x73, 0x68, 0x61, 0x72, 0x65, 0x64, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
 	0x22, 0x8a, 0x02, 0x0a, 0x0c, 0x53, 0x63, 0x61, 0x6c, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
@@ -289,28 +394,58 @@ var file_plugins_target_proto_v1_target_proto_rawDesc = []byte{
 	0x65, 0x74, 0x61, 0x1a, 0x37, 0x0a, 0x09, 0x4d, 0x65, 0x74, 0x61, 0x45, 0x6e, 0x74, 0x72, 0x79,
 	0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b,
 	0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28,
-	0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x32, 0xba, 0x02, 0x0a,
-	0x13, 0x54, 0x61, 0x72, 0x67, 0x65, 0x74, 0x50, 0x6c, 0x75, 0x67, 0x69, 0x6e, 0x53, 0x65, 0x72,
-	0x76, 0x69, 0x63, 0x65, 0x12, 0x8e, 0x01, 0x0a, 0x05, 0x53, 0x63, 0x61, 0x6c, 0x65, 0x12, 0x40,
-	0x2e, 0x68, 0x61, 0x73, 0x68, 0x69, 0x63, 0x6f, 0x72, 0x70, 0x2e, 0x6e, 0x6f, 0x6d, 0x61, 0x64,
-	0x5f, 0x61, 0x75, 0x74, 0x6f, 0x73, 0x63, 0x61, 0x6c, 0x65, 0x72, 0x2e, 0x70, 0x6c, 0x75, 0x67,
-	0x69, 0x6e, 0x73, 0x2e, 0x74, 0x61, 0x72, 0x67, 0x65, 0x74, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
-	0x2e, 0x76, 0x31, 0x2e, 0x53, 0x63, 0x61, 0x6c, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
-	0x1a, 0x41, 0x2e, 0x68, 0x61, 0x73, 0x68, 0x69, 0x63, 0x6f, 0x72, 0x70, 0x2e, 0x6e, 0x6f, 0x6d,
+	0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x22, 0xb5, 0x01, 0x0a,
+	0x0f, 0x43, 0x61, 0x70, 0x61, 0x63, 0x69, 0x74, 0x79, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x12, 0x67, 0x0a, 0x06, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b,
+	0x32, 0x4f, 0x2e, 0x68, 0x61, 0x73, 0x68, 0x69, 0x63, 0x6f, 0x72, 0x70, 0x2e, 0x6e, 0x6f, 0x6d,
 	0x61, 0x64, 0x5f, 0x61, 0x75, 0x74, 0x6f, 0x73, 0x63, 0x61, 0x6c, 0x65, 0x72, 0x2e, 0x70, 0x6c,
 	0x75, 0x67, 0x69, 0x6e, 0x73, 0x2e, 0x74, 0x61, 0x72, 0x67, 0x65, 0x74, 0x2e, 0x70, 0x72, 0x6f,
-	0x74, 0x6f, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x63, 0x61, 0x6c, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f,
-	0x6e, 0x73, 0x65, 0x22, 0x00, 0x12, 0x91, 0x01, 0x0a, 0x06, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73,
-	0x12, 0x41, 0x2e, 0x68, 0x61, 0x73, 0x68, 0x69, 0x63, 0x6f, 0x72, 0x70, 0x2e, 0x6e, 0x6f, 0x6d,
+	0x74, 0x6f, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x61, 0x70, 0x61, 0x63, 0x69, 0x74, 0x79, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x2e, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x45, 0x6e, 0x74, 0x72,
+	0x79, 0x52, 0x06, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x1a, 0x39, 0x0a, 0x0b, 0x43, 0x6f, 0x6e,
+	0x66, 0x69, 0x67, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61,
+	0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65,
+	0x3a, 0x02, 0x38, 0x01, 0x22, 0x87, 0x01, 0x0a, 0x10, 0x43, 0x61, 0x70, 0x61, 0x63, 0x69, 0x74,
+	0x79, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x38, 0x0a, 0x09, 0x6d, 0x61, 0x78,
+	0x5f, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1b, 0x2e, 0x67,
+	0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x49,
+	0x6e, 0x74, 0x36, 0x34, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x52, 0x08, 0x6d, 0x61, 0x78, 0x43, 0x6f,
+	0x75, 0x6e, 0x74, 0x12, 0x39, 0x0a, 0x09, 0x72, 0x65, 0x6d, 0x61, 0x69, 0x6e, 0x69, 0x6e, 0x67,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1b, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x49, 0x6e, 0x74, 0x36, 0x34, 0x56, 0x61,
+	0x6c, 0x75, 0x65, 0x52, 0x09, 0x72, 0x65, 0x6d, 0x61, 0x69, 0x6e, 0x69, 0x6e, 0x67, 0x32, 0xd4,
+	0x03, 0x0a, 0x13, 0x54, 0x61, 0x72, 0x67, 0x65, 0x74, 0x50, 0x6c, 0x75, 0x67, 0x69, 0x6e, 0x53,
+	0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x8e, 0x01, 0x0a, 0x05, 0x53, 0x63, 0x61, 0x6c, 0x65,
+	0x12, 0x40, 0x2e, 0x68, 0x61, 0x73, 0x68, 0x69, 0x63, 0x6f, 0x72, 0x70, 0x2e, 0x6e, 0x6f, 0x6d,
 	0x61, 0x64, 0x5f, 0x61, 0x75, 0x74, 0x6f, 0x73, 0x63, 0x61, 0x6c, 0x65, 0x72, 0x2e, 0x70, 0x6c,
 	0x75, 0x67, 0x69, 0x6e, 0x73, 0x2e, 0x74, 0x61, 0x72, 0x67, 0x65, 0x74, 0x2e, 0x70, 0x72, 0x6f,
-	0x74, 0x6f, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x65, 0x71, 0x75,
-	0x65, 0x73, 0x74, 0x1a, 0x42, 0x2e, 0x68, 0x61, 0x73, 0x68, 0x69, 0x63, 0x6f, 0x72, 0x70, 0x2e,
-	0x6e, 0x6f, 0x6d, 0x61, 0x64, 0x5f, 0x61, 0x75, 0x74, 0x6f, 0x73, 0x63, 0x61, 0x6c, 0x65, 0x72,
-	0x2e, 0x70, 0x6c, 0x75, 0x67, 0x69, 0x6e, 0x73, 0x2e, 0x74, 0x61, 0x72, 0x67, 0x65, 0x74, 0x2e,
-	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52,
-	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x42, 0x07, 0x5a, 0x05, 0x70, 0x72, 0x6f,
-	0x74, 0x6f, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x74, 0x6f, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x63, 0x61, 0x6c, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x1a, 0x41, 0x2e, 0x68, 0x61, 0x73, 0x68, 0x69, 0x63, 0x6f, 0x72, 0x70, 0x2e, 0x6e,
+	0x6f, 0x6d, 0x61, 0x64, 0x5f, 0x61, 0x75, 0x74, 0x6f, 0x73, 0x63, 0x61, 0x6c, 0x65, 0x72, 0x2e,
+	0x70, 0x6c, 0x75, 0x67, 0x69, 0x6e, 0x73, 0x2e, 0x74, 0x61, 0x72, 0x67, 0x65, 0x74, 0x2e, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x63, 0x61, 0x6c, 0x65, 0x52, 0x65, 0x73,
+	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x12, 0x91, 0x01, 0x0a, 0x06, 0x53, 0x74, 0x61, 0x74,
+	0x75, 0x73, 0x12, 0x41, 0x2e, 0x68, 0x61, 0x73, 0x68, 0x69, 0x63, 0x6f, 0x72, 0x70, 0x2e, 0x6e,
+	0x6f, 0x6d, 0x61, 0x64, 0x5f, 0x61, 0x75, 0x74, 0x6f, 0x73, 0x63, 0x61, 0x6c, 0x65, 0x72, 0x2e,
+	0x70, 0x6c, 0x75, 0x67, 0x69, 0x6e, 0x73, 0x2e, 0x74, 0x61, 0x72, 0x67, 0x65, 0x74, 0x2e, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x42, 0x2e, 0x68, 0x61, 0x73, 0x68, 0x69, 0x63, 0x6f, 0x72,
+	0x70, 0x2e, 0x6e, 0x6f, 0x6d, 0x61, 0x64, 0x5f, 0x61, 0x75, 0x74, 0x6f, 0x73, 0x63, 0x61, 0x6c,
+	0x65, 0x72, 0x2e, 0x70, 0x6c, 0x75, 0x67, 0x69, 0x6e, 0x73, 0x2e, 0x74, 0x61, 0x72, 0x67, 0x65,
+	0x74, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x74, 0x61, 0x74, 0x75,
+	0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x12, 0x97, 0x01, 0x0a, 0x08,
+	0x43, 0x61, 0x70, 0x61, 0x63, 0x69, 0x74, 0x79, 0x12, 0x43, 0x2e, 0x68, 0x61, 0x73, 0x68, 0x69,
+	0x63, 0x6f, 0x72, 0x70, 0x2e, 0x6e, 0x6f, 0x6d, 0x61, 0x64, 0x5f, 0x61, 0x75, 0x74, 0x6f, 0x73,
+	0x63, 0x61, 0x6c, 0x65, 0x72, 0x2e, 0x70, 0x6c, 0x75, 0x67, 0x69, 0x6e, 0x73, 0x2e, 0x74, 0x61,
+	0x72, 0x67, 0x65, 0x74, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x61,
+	0x70, 0x61, 0x63, 0x69, 0x74, 0x79, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x44, 0x2e,
+	0x68, 0x61, 0x73, 0x68, 0x69, 0x63, 0x6f, 0x72, 0x70, 0x2e, 0x6e, 0x6f, 0x6d, 0x61, 0x64, 0x5f,
+	0x61, 0x75, 0x74, 0x6f, 0x73, 0x63, 0x61, 0x6c, 0x65, 0x72, 0x2e, 0x70, 0x6c, 0x75, 0x67, 0x69,
+	0x6e, 0x73, 0x2e, 0x74, 0x61, 0x72, 0x67, 0x65, 0x74, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e,
+	0x76, 0x31, 0x2e, 0x43, 0x61, 0x70, 0x61, 0x63, 0x69, 0x74, 0x79, 0x52, 0x65, 0x73, 0x70, 0x6f,
+	0x6e, 0x73, 0x65, 0x22, 0x00, 0x42, 0x07, 0x5a, 0x05, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x06,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
 }
 
 var (
@@ -325,31 +460,40 @@ func file_plugins_target_proto_v1_target_proto_rawDescGZIP() []byte {
 	return file_plugins_target_proto_v1_target_proto_rawDescData
 }
 
-var file_plugins_target_proto_v1_target_proto_msgTypes = make([]protoimpl.MessageInfo, 7)
+var file_plugins_target_proto_v1_target_proto_msgTypes = make([]protoimpl.MessageInfo, 10)
 var file_plugins_target_proto_v1_target_proto_goTypes = []interface{}{
-	(*ScaleRequest)(nil),     // 0: hashicorp.nomad_autoscaler.plugins.target.proto.v1.ScaleRequest
-	(*ScaleResponse)(nil),    // 1: hashicorp.nomad_autoscaler.plugins.target.proto.v1.ScaleResponse
-	(*StatusRequest)(nil),    // 2: hashicorp.nomad_autoscaler.plugins.target.proto.v1.StatusRequest
-	(*StatusResponse)(nil),   // 3: hashicorp.nomad_autoscaler.plugins.target.proto.v1.StatusResponse
-	nil,                      // 4: hashicorp.nomad_autoscaler.plugins.target.proto.v1.ScaleRequest.ConfigEntry
-	nil,                      // 5: hashicorp.nomad_autoscaler.plugins.target.proto.v1.StatusRequest.ConfigEntry
-	nil,                      // 6: hashicorp.nomad_autoscaler.plugins.target.proto.v1.StatusResponse.MetaEntry
-	(*v1.ScalingAction)(nil), // 7: hashicorp.nomad_autoscaler.plugins.shared.proto.v1.ScalingAction
+	(*ScaleRequest)(nil),        // 0: hashicorp.nomad_autoscaler.plugins.target.proto.v1.ScaleRequest
+	(*ScaleResponse)(nil),       // 1: hashicorp.nomad_autoscaler.plugins.target.proto.v1.ScaleResponse
+	(*StatusRequest)(nil),       // 2: hashicorp.nomad_autoscaler.plugins.target.proto.v1.StatusRequest
+	(*StatusResponse)(nil),      // 3: hashicorp.nomad_autoscaler.plugins.target.proto.v1.StatusResponse
+	(*CapacityRequest)(nil),     // 4: hashicorp.nomad_autoscaler.plugins.target.proto.v1.CapacityRequest
+	(*CapacityResponse)(nil),    // 5: hashicorp.nomad_autoscaler.plugins.target.proto.v1.CapacityResponse
+	nil,                         // 6: hashicorp.nomad_autoscaler.plugins.target.proto.v1.ScaleRequest.ConfigEntry
+	nil,                         // 7: hashicorp.nomad_autoscaler.plugins.target.proto.v1.StatusRequest.ConfigEntry
+	nil,                         // 8: hashicorp.nomad_autoscaler.plugins.target.proto.v1.StatusResponse.MetaEntry
+	nil,                         // 9: hashicorp.nomad_autoscaler.plugins.target.proto.v1.CapacityRequest.ConfigEntry
+	(*v1.ScalingAction)(nil),    // 10: hashicorp.nomad_autoscaler.plugins.shared.proto.v1.ScalingAction
+	(*wrappers.Int64Value)(nil), // 11: google.protobuf.Int64Value
 }
 var file_plugins_target_proto_v1_target_proto_depIdxs = []int32{
-	7, // 0: hashicorp.nomad_autoscaler.plugins.target.proto.v1.ScaleRequest.action:type_name -> hashicorp.nomad_autoscaler.plugins.shared.proto.v1.ScalingAction
-	4, // 1: hashicorp.nomad_autoscaler.plugins.target.proto.v1.ScaleRequest.config:type_name -> hashicorp.nomad_autoscaler.plugins.target.proto.v1.ScaleRequest.ConfigEntry
-	5, // 2: hashicorp.nomad_autoscaler.plugins.target.proto.v1.StatusRequest.config:type_name -> hashicorp.nomad_autoscaler.plugins.target.proto.v1.StatusRequest.ConfigEntry
-	6, // 3: hashicorp.nomad_autoscaler.plugins.target.proto.v1.StatusResponse.meta:type_name -> hashicorp.nomad_autoscaler.plugins.target.proto.v1.StatusResponse.MetaEntry
-	0, // 4: hashicorp.nomad_autoscaler.plugins.target.proto.v1.TargetPluginService.Scale:input_type -> hashicorp.nomad_autoscaler.plugins.target.proto.v1.ScaleRequest
-	2, // 5: hashicorp.nomad_autoscaler.plugins.target.proto.v1.TargetPluginService.Status:input_type -> hashicorp.nomad_autoscaler.plugins.target.proto.v1.StatusRequest
-	1, // 6: hashicorp.nomad_autoscaler.plugins.target.proto.v1.TargetPluginService.Scale:output_type -> hashicorp.nomad_autoscaler.plugins.target.proto.v1.ScaleResponse
-	3, // 7: hashicorp.nomad_autoscaler.plugins.target.proto.v1.TargetPluginService.Status:output_type -> hashicorp.nomad_autoscaler.plugins.target.proto.v1.StatusResponse
-	6, // [6:8] is the sub-list for method output_type
-	4, // [4:6] is the sub-list for method input_type
-	4, // [4:4] is the sub-list for extension type_name
-	4, // [4:4] is the sub-list for extension extendee
-	0, // [0:4] is the sub-list for field type_name
+	10, // 0: hashicorp.nomad_autoscaler.plugins.target.proto.v1.ScaleRequest.action:type_name -> hashicorp.nomad_autoscaler.plugins.shared.proto.v1.ScalingAction
+	6,  // 1: hashicorp.nomad_autoscaler.plugins.target.proto.v1.ScaleRequest.config:type_name -> hashicorp.nomad_autoscaler.plugins.target.proto.v1.ScaleRequest.ConfigEntry
+	7,  // 2: hashicorp.nomad_autoscaler.plugins.target.proto.v1.StatusRequest.config:type_name -> hashicorp.nomad_autoscaler.plugins.target.proto.v1.StatusRequest.ConfigEntry
+	8,  // 3: hashicorp.nomad_autoscaler.plugins.target.proto.v1.StatusResponse.meta:type_name -> hashicorp.nomad_autoscaler.plugins.target.proto.v1.StatusResponse.MetaEntry
+	9,  // 4: hashicorp.nomad_autoscaler.plugins.target.proto.v1.CapacityRequest.config:type_name -> hashicorp.nomad_autoscaler.plugins.target.proto.v1.CapacityRequest.ConfigEntry
+	11, // 5: hashicorp.nomad_autoscaler.plugins.target.proto.v1.CapacityResponse.max_count:type_name -> google.protobuf.Int64Value
+	11, // 6: hashicorp.nomad_autoscaler.plugins.target.proto.v1.CapacityResponse.remaining:type_name -> google.protobuf.Int64Value
+	0,  // 7: hashicorp.nomad_autoscaler.plugins.target.proto.v1.TargetPluginService.Scale:input_type -> hashicorp.nomad_autoscaler.plugins.target.proto.v1.ScaleRequest
+	2,  // 8: hashicorp.nomad_autoscaler.plugins.target.proto.v1.TargetPluginService.Status:input_type -> hashicorp.nomad_autoscaler.plugins.target.proto.v1.StatusRequest
+	4,  // 9: hashicorp.nomad_autoscaler.plugins.target.proto.v1.TargetPluginService.Capacity:input_type -> hashicorp.nomad_autoscaler.plugins.target.proto.v1.CapacityRequest
+	1,  // 10: hashicorp.nomad_autoscaler.plugins.target.proto.v1.TargetPluginService.Scale:output_type -> hashicorp.nomad_autoscaler.plugins.target.proto.v1.ScaleResponse
+	3,  // 11: hashicorp.nomad_autoscaler.plugins.target.proto.v1.TargetPluginService.Status:output_type -> hashicorp.nomad_autoscaler.plugins.target.proto.v1.StatusResponse
+	5,  // 12: hashicorp.nomad_autoscaler.plugins.target.proto.v1.TargetPluginService.Capacity:output_type -> hashicorp.nomad_autoscaler.plugins.target.proto.v1.CapacityResponse
+	10, // [10:13] is the sub-list for method output_type
+	7,  // [7:10] is the sub-list for method input_type
+	7,  // [7:7] is the sub-list for extension type_name
+	7,  // [7:7] is the sub-list for extension extendee
+	0,  // [0:7] is the sub-list for field type_name
 }
 
 func init() { file_plugins_target_proto_v1_target_proto_init() }
@@ -406,6 +550,30 @@ func file_plugins_target_proto_v1_target_proto_init() {
 				return nil
 			}
 		}
+		file_plugins_target_proto_v1_target_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CapacityRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_plugins_target_proto_v1_target_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CapacityResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
 	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
@@ -413,7 +581,7 @@ func file_plugins_target_proto_v1_target_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_plugins_target_proto_v1_target_proto_rawDesc,
 			NumEnums:      0,
-			NumMessages:   7,
+			NumMessages:   10,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -441,6 +609,7 @@ const _ = grpc.SupportPackageIsVersion6
 type TargetPluginServiceClient interface {
 	Scale(ctx context.Context, in *ScaleRequest, opts ...grpc.CallOption) (*ScaleResponse, error)
 	Status(ctx context.Context, in *StatusRequest, opts ...grpc.CallOption) (*StatusResponse, error)
+	Capacity(ctx context.Context, in *CapacityRequest, opts ...grpc.CallOption) (*CapacityResponse, error)
 }
 
 type targetPluginServiceClient struct {
@@ -469,10 +638,20 @@ func (c *targetPluginServiceClient) Status(ctx context.Context, in *StatusReques
 	return out, nil
 }
 
+func (c *targetPluginServiceClient) Capacity(ctx context.Context, in *CapacityRequest, opts ...grpc.CallOption) (*CapacityResponse, error) {
+	out := new(CapacityResponse)
+	err := c.cc.Invoke(ctx, "/hashicorp.nomad_autoscaler.plugins.target.proto.v1.TargetPluginService/Capacity", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // TargetPluginServiceServer is the server API for TargetPluginService service.
 type TargetPluginServiceServer interface {
 	Scale(context.Context, *ScaleRequest) (*ScaleResponse, error)
 	Status(context.Context, *StatusRequest) (*StatusResponse, error)
+	Capacity(context.Context, *CapacityRequest) (*CapacityResponse, error)
 }
 
 // UnimplementedTargetPluginServiceServer can be embedded to have forward compatible implementations.
@@ -485,6 +664,9 @@ func (*UnimplementedTargetPluginServiceServer) Scale(context.Context, *ScaleRequ
 func (*UnimplementedTargetPluginServiceServer) Status(context.Context, *StatusRequest) (*StatusResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Status not implemented")
 }
+func (*UnimplementedTargetPluginServiceServer) Capacity(context.Context, *CapacityRequest) (*CapacityResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Capacity not implemented")
+}
 
 func RegisterTargetPluginServiceServer(s *grpc.Server, srv TargetPluginServiceServer) {
 	s.RegisterService(&_TargetPluginService_serviceDesc, srv)
@@ -526,6 +708,24 @@ func _TargetPluginService_Status_Handler(srv interface{}, ctx context.Context, d
 	return interceptor(ctx, in, info, handler)
 }
 
+func _TargetPluginService_Capacity_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CapacityRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TargetPluginServiceServer).Capacity(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/hashicorp.nomad_autoscaler.plugins.target.proto.v1.TargetPluginService/Capacity",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TargetPluginServiceServer).Capacity(ctx, req.(*CapacityRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var _TargetPluginService_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "hashicorp.nomad_autoscaler.plugins.target.proto.v1.TargetPluginService",
 	HandlerType: (*TargetPluginServiceServer)(nil),
@@ -538,6 +738,10 @@ var _TargetPluginService_serviceDesc = grpc.ServiceDesc{
 			MethodName: "Status",
 			Handler:    _TargetPluginService_Status_Handler,
 		},
+		{
+			MethodName: "Capacity",
+			Handler:    _TargetPluginService_Capacity_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "plugins/target/proto/v1/target.proto",
@@ -6,6 +6,7 @@ package target
 import (
 	"context"
 
+	"github.com/golang/protobuf/ptypes/wrappers"
 	plugin "github.com/hashicorp/go-plugin"
 	"github.com/hashicorp/nomad-autoscaler/plugins/shared"
 	"github.com/hashicorp/nomad-autoscaler/plugins/target/proto/v1"
@@ -19,11 +20,17 @@ type pluginServer struct {
 
 // Scale is the gRPC server implementation of the Target.Scale interface
 // function.
-func (p *pluginServer) Scale(_ context.Context, req *proto.ScaleRequest) (*proto.ScaleResponse, error) {
+func (p *pluginServer) Scale(ctx context.Context, req *proto.ScaleRequest) (*proto.ScaleResponse, error) {
 	action, err := shared.ProtoToScalingAction(req.GetAction())
 	if err != nil {
 		return nil, err
 	}
+
+	// Clients which don't include the correlation ID in the action Meta may
+	// still send it as gRPC metadata.
+	if id := shared.CorrelationIDFromContext(ctx); id != "" && action.CorrelationID() == "" {
+		action.SetCorrelationID(id)
+	}
 	return &proto.ScaleResponse{}, p.impl.Scale(action, req.GetConfig())
 }
 
@@ -42,3 +49,29 @@ func (p *pluginServer) Status(_ context.Context, req *proto.StatusRequest) (*pro
 		Meta:  statusResp.Meta,
 	}, nil
 }
+
+// Capacity returns the capacity limits reported by the plugin implementation
+// if it implements the CapacityReporter interface, or no limits otherwise.
+func (p *pluginServer) Capacity(_ context.Context, req *proto.CapacityRequest) (*proto.CapacityResponse, error) {
+	impl, ok := p.impl.(CapacityReporter)
+	if !ok {
+		return &proto.CapacityResponse{}, nil
+	}
+
+	capacity, err := impl.Capacity(req.GetConfig())
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &proto.CapacityResponse{}
+	if capacity == nil {
+		return resp, nil
+	}
+	if capacity.MaxCount != nil {
+		resp.MaxCount = &wrappers.Int64Value{Value: *capacity.MaxCount}
+	}
+	if capacity.Remaining != nil {
+		resp.Remaining = &wrappers.Int64Value{Value: *capacity.Remaining}
+	}
+	return resp, nil
+}
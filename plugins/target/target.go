@@ -27,3 +27,15 @@ type Target interface {
 	// will be used when performing the strategy calculation.
 	Status(config map[string]string) (*sdk.TargetStatus, error)
 }
+
+// CapacityReporter is an optional interface implemented by Target plugins
+// that can report the capacity limits imposed by the remote provider, such
+// as the max size of a cloud autoscaling group or the remaining quota. The
+// Autoscaler uses it to cap scaling actions before they are submitted.
+type CapacityReporter interface {
+
+	// Capacity returns the capacity limits of the remote target as specified
+	// by the config func argument. A nil response indicates the provider
+	// doesn't impose any limit.
+	Capacity(config map[string]string) (*sdk.TargetCapacity, error)
+}
@@ -4,10 +4,13 @@
 package target
 
 import (
+	"context"
+	"errors"
 	"os/exec"
 	"testing"
 
 	plugin "github.com/hashicorp/go-plugin"
+	"github.com/hashicorp/nomad-autoscaler/plugins/target/proto/v1"
 	"github.com/hashicorp/nomad-autoscaler/sdk"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -103,3 +106,71 @@ func TestTargetPluginRPCServerScale(t *testing.T) {
 	err = targetImpl.Scale(sdk.ScalingAction{}, nil)
 	require.NoError(t, err)
 }
+
+func TestTargetPluginRPCServerCapacity(t *testing.T) {
+	client := plugin.NewClient(&plugin.ClientConfig{
+		HandshakeConfig:  handshake,
+		Plugins:          map[string]plugin.Plugin{"target": &PluginTarget{}},
+		Cmd:              exec.Command("../test/bin/noop-target"),
+		AllowedProtocols: []plugin.Protocol{plugin.ProtocolGRPC},
+	})
+	defer client.Kill()
+
+	rpcClient, err := client.Client()
+	require.NoError(t, err)
+
+	raw, err := rpcClient.Dispense("target")
+	require.NoError(t, err)
+
+	capacityImpl, ok := raw.(CapacityReporter)
+	require.True(t, ok)
+
+	// The noop target doesn't report any capacity limits.
+	capacity, err := capacityImpl.Capacity(map[string]string{})
+	require.NoError(t, err)
+	_, ok = capacity.Ceiling(0)
+	assert.False(t, ok)
+}
+
+func Test_pluginServer_Capacity(t *testing.T) {
+	maxCount, remaining := int64(10), int64(3)
+
+	srv := &pluginServer{impl: &testCapacityTarget{
+		capacity: &sdk.TargetCapacity{MaxCount: &maxCount, Remaining: &remaining},
+	}}
+	resp, err := srv.Capacity(context.Background(), &proto.CapacityRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, int64(10), resp.GetMaxCount().GetValue())
+	assert.Equal(t, int64(3), resp.GetRemaining().GetValue())
+
+	// Limits not imposed by the provider are not set.
+	srv = &pluginServer{impl: &testCapacityTarget{capacity: &sdk.TargetCapacity{MaxCount: &maxCount}}}
+	resp, err = srv.Capacity(context.Background(), &proto.CapacityRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, int64(10), resp.GetMaxCount().GetValue())
+	assert.Nil(t, resp.GetRemaining())
+
+	// Errors from the implementation are returned.
+	srv = &pluginServer{impl: &testCapacityTarget{err: errors.New("quota API unavailable")}}
+	_, err = srv.Capacity(context.Background(), &proto.CapacityRequest{})
+	assert.EqualError(t, err, "quota API unavailable")
+
+	// Targets without the capability don't report any limits.
+	srv = &pluginServer{}
+	resp, err = srv.Capacity(context.Background(), &proto.CapacityRequest{})
+	require.NoError(t, err)
+	assert.Nil(t, resp.GetMaxCount())
+	assert.Nil(t, resp.GetRemaining())
+}
+
+// testCapacityTarget is a Target which implements the CapacityReporter
+// interface.
+type testCapacityTarget struct {
+	Target
+	capacity *sdk.TargetCapacity
+	err      error
+}
+
+func (t *testCapacityTarget) Capacity(_ map[string]string) (*sdk.TargetCapacity, error) {
+	return t.capacity, t.err
+}
@@ -29,6 +29,7 @@ var (
 )
 
 var _ apm.APM = (*Noop)(nil)
+var _ apm.LabeledAPM = (*Noop)(nil)
 
 type Noop struct {
 	logger hclog.Logger
@@ -42,6 +43,22 @@ func (n *Noop) QueryMultiple(q string, r sdk.TimeRange) ([]sdk.TimestampedMetric
 	return []sdk.TimestampedMetrics{m}, nil
 }
 
+func (n *Noop) QueryLabeled(q string, r sdk.TimeRange) (sdk.LabeledTimestampedMetrics, error) {
+	m, err := n.Query(q, r)
+	if err != nil {
+		return sdk.LabeledTimestampedMetrics{}, err
+	}
+	return sdk.LabeledTimestampedMetrics{Labels: map[string]string{"query": q}, Metrics: m}, nil
+}
+
+func (n *Noop) QueryMultipleLabeled(q string, r sdk.TimeRange) ([]sdk.LabeledTimestampedMetrics, error) {
+	m, err := n.Query(q, r)
+	if err != nil {
+		return nil, err
+	}
+	return []sdk.LabeledTimestampedMetrics{{Labels: map[string]string{"query": q}, Metrics: m}}, nil
+}
+
 func (n *Noop) Query(q string, r sdk.TimeRange) (sdk.TimestampedMetrics, error) {
 	n.logger.Debug("query request", "query", q, "range", r)
 
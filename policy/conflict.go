@@ -0,0 +1,198 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package policy
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/nomad-autoscaler/sdk"
+)
+
+// conflictTracker keeps track of the resources each enabled policy scales so
+// that policies competing for the same resource can be detected. Without it,
+// two policies pointing at the same Nomad group or cloud resource would
+// silently undo each other's scaling actions.
+type conflictTracker struct {
+	lock sync.RWMutex
+
+	// keys maps each policy to the keys of the resources it scales.
+	keys map[PolicyID][]string
+}
+
+func newConflictTracker() *conflictTracker {
+	return &conflictTracker{
+		keys: make(map[PolicyID][]string),
+	}
+}
+
+// update records the resources scaled by the policy and returns the IDs of
+// the other policies that scale any of the same resources. Disabled policies
+// and policies whose resources cannot be identified are not tracked.
+func (c *conflictTracker) update(id PolicyID, p *sdk.ScalingPolicy) []PolicyID {
+	keys := conflictKeys(p)
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if len(keys) == 0 {
+		delete(c.keys, id)
+		return nil
+	}
+	c.keys[id] = keys
+
+	return c.conflictingLocked(id)
+}
+
+// remove stops tracking the policy.
+func (c *conflictTracker) remove(id PolicyID) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	delete(c.keys, id)
+}
+
+// conflicting returns the IDs of the policies that scale any of the
+// resources scaled by the policy.
+func (c *conflictTracker) conflicting(id PolicyID) []PolicyID {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	return c.conflictingLocked(id)
+}
+
+func (c *conflictTracker) conflictingLocked(id PolicyID) []PolicyID {
+	keys, ok := c.keys[id]
+	if !ok {
+		return nil
+	}
+
+	var ids []PolicyID
+	for otherID, otherKeys := range c.keys {
+		if otherID != id && sharesKey(keys, otherKeys) {
+			ids = append(ids, otherID)
+		}
+	}
+
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+// count returns the number of tracked policies that conflict with at least
+// one other policy.
+func (c *conflictTracker) count() int {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	perKey := make(map[string]int)
+	for _, keys := range c.keys {
+		for _, key := range keys {
+			perKey[key]++
+		}
+	}
+
+	num := 0
+	for _, keys := range c.keys {
+		for _, key := range keys {
+			if perKey[key] > 1 {
+				num++
+				break
+			}
+		}
+	}
+	return num
+}
+
+// sharesKey returns whether a and b have at least one key in common.
+func sharesKey(a, b []string) bool {
+	for _, x := range a {
+		for _, y := range b {
+			if x == y {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// conflictKeys returns strings that uniquely identify the resources scaled
+// by the policy. Most policies scale a single resource, but cluster policies
+// can spread their count across multiple AWS ASGs using aws_asg_weights. No
+// keys are returned if the policy is disabled or if the resources cannot be
+// identified.
+func conflictKeys(p *sdk.ScalingPolicy) []string {
+	if p == nil || !p.Enabled || p.Target == nil || p.Target.Config == nil {
+		return nil
+	}
+	cfg := p.Target.Config
+
+	switch p.Type {
+	case sdk.ScalingPolicyTypeCluster:
+		switch {
+		case cfg["aws_asg_name"] != "":
+			return []string{fmt.Sprintf("aws-asg/%s/%s", cfg["aws_region"], cfg["aws_asg_name"])}
+		case cfg["aws_asg_weights"] != "":
+			return weightedASGKeys(cfg["aws_region"], cfg["aws_asg_weights"])
+		case cfg["mig_name"] != "":
+			return []string{fmt.Sprintf("gce-mig/%s/%s%s/%s", cfg["project"], cfg["region"], cfg["zone"], cfg["mig_name"])}
+		case cfg["vm_scale_set"] != "":
+			return []string{fmt.Sprintf("azure-vmss/%s/%s/%s", cfg["subscription_id"], cfg["resource_group"], cfg["vm_scale_set"])}
+		}
+		return nil
+	}
+
+	job, group := cfg[sdk.TargetConfigKeyJob], cfg[sdk.TargetConfigKeyTaskGroup]
+
+	namespace := cfg[sdk.TargetConfigKeyNamespace]
+	if namespace == "" {
+		namespace = "default"
+	}
+
+	// Policies scaling the dispatched children of a parameterized job don't
+	// target a group.
+	if dispatch, _ := strconv.ParseBool(cfg["dispatch"]); dispatch && job != "" {
+		return []string{fmt.Sprintf("nomad-dispatch/%s/%s", namespace, job)}
+	}
+
+	if job == "" || group == "" {
+		return nil
+	}
+
+	switch p.Type {
+	case sdk.ScalingPolicyTypeVerticalCPU, sdk.ScalingPolicyTypeVerticalMem:
+		return []string{fmt.Sprintf("nomad/%s/%s/%s/%s/%s",
+			namespace, job, group, cfg[sdk.TargetConfigKeyTask], cfg[sdk.TargetConfigKeyResource])}
+	case sdk.ScalingPolicyTypeVertical:
+		return []string{fmt.Sprintf("nomad/%s/%s/%s/%s", namespace, job, group, cfg[sdk.TargetConfigKeyTask])}
+	default:
+		return []string{fmt.Sprintf("nomad/%s/%s/%s", namespace, job, group)}
+	}
+}
+
+// weightedASGKeys returns the keys of the ASGs listed in the aws_asg_weights
+// value, which has the form "on-demand=30,spot=70". The weights are validated
+// by the aws-asg plugin, so malformed entries are only skipped here.
+func weightedASGKeys(region, weights string) []string {
+	seen := make(map[string]struct{})
+	var keys []string
+
+	for _, entry := range strings.Split(weights, ",") {
+		name, _, _ := strings.Cut(entry, "=")
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if _, ok := seen[name]; ok {
+			continue
+		}
+		seen[name] = struct{}{}
+		keys = append(keys, fmt.Sprintf("aws-asg/%s/%s", region, name))
+	}
+
+	sort.Strings(keys)
+	return keys
+}
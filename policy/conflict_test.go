@@ -0,0 +1,174 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package policy
+
+import (
+	"testing"
+
+	"github.com/hashicorp/nomad-autoscaler/sdk"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_conflictKeys(t *testing.T) {
+	testCases := []struct {
+		name     string
+		policy   *sdk.ScalingPolicy
+		expected []string
+	}{
+		{
+			name:     "nil policy",
+			policy:   nil,
+			expected: nil,
+		},
+		{
+			name: "disabled policy",
+			policy: &sdk.ScalingPolicy{
+				Type:    sdk.ScalingPolicyTypeHorizontal,
+				Enabled: false,
+				Target: &sdk.ScalingPolicyTarget{
+					Config: map[string]string{"Job": "example", "Group": "cache"},
+				},
+			},
+			expected: nil,
+		},
+		{
+			name: "horizontal without namespace",
+			policy: &sdk.ScalingPolicy{
+				Type:    sdk.ScalingPolicyTypeHorizontal,
+				Enabled: true,
+				Target: &sdk.ScalingPolicyTarget{
+					Config: map[string]string{"Job": "example", "Group": "cache"},
+				},
+			},
+			expected: []string{"nomad/default/example/cache"},
+		},
+		{
+			name: "dispatch",
+			policy: &sdk.ScalingPolicy{
+				Type:    sdk.ScalingPolicyTypeHorizontal,
+				Enabled: true,
+				Target: &sdk.ScalingPolicyTarget{
+					Config: map[string]string{"Job": "worker", "dispatch": "true"},
+				},
+			},
+			expected: []string{"nomad-dispatch/default/worker"},
+		},
+		{
+			name: "vertical",
+			policy: &sdk.ScalingPolicy{
+				Type:    sdk.ScalingPolicyTypeVerticalCPU,
+				Enabled: true,
+				Target: &sdk.ScalingPolicyTarget{
+					Config: map[string]string{
+						"Namespace": "dev",
+						"Job":       "example",
+						"Group":     "cache",
+						"Task":      "redis",
+						"Resource":  "cpu",
+					},
+				},
+			},
+			expected: []string{"nomad/dev/example/cache/redis/cpu"},
+		},
+		{
+			name: "aws asg",
+			policy: &sdk.ScalingPolicy{
+				Type:    sdk.ScalingPolicyTypeCluster,
+				Enabled: true,
+				Target: &sdk.ScalingPolicyTarget{
+					Name:   "aws-asg",
+					Config: map[string]string{"aws_asg_name": "clients", "node_class": "a"},
+				},
+			},
+			expected: []string{"aws-asg//clients"},
+		},
+		{
+			name: "aws asg weights",
+			policy: &sdk.ScalingPolicy{
+				Type:    sdk.ScalingPolicyTypeCluster,
+				Enabled: true,
+				Target: &sdk.ScalingPolicyTarget{
+					Name: "aws-asg",
+					Config: map[string]string{
+						"aws_region":      "us-east-1",
+						"aws_asg_weights": "spot=70, on-demand=30,spot=10",
+					},
+				},
+			},
+			expected: []string{"aws-asg/us-east-1/on-demand", "aws-asg/us-east-1/spot"},
+		},
+		{
+			name: "unknown cluster target",
+			policy: &sdk.ScalingPolicy{
+				Type:    sdk.ScalingPolicyTypeCluster,
+				Enabled: true,
+				Target: &sdk.ScalingPolicyTarget{
+					Name:   "custom",
+					Config: map[string]string{"node_class": "a"},
+				},
+			},
+			expected: nil,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, conflictKeys(tc.policy))
+		})
+	}
+}
+
+func Test_conflictTracker(t *testing.T) {
+	newPolicy := func(group string, enabled bool) *sdk.ScalingPolicy {
+		return &sdk.ScalingPolicy{
+			Type:    sdk.ScalingPolicyTypeHorizontal,
+			Enabled: enabled,
+			Target: &sdk.ScalingPolicyTarget{
+				Config: map[string]string{"Job": "example", "Group": group},
+			},
+		}
+	}
+
+	c := newConflictTracker()
+
+	assert.Empty(t, c.update("a", newPolicy("cache", true)))
+	assert.Empty(t, c.update("b", newPolicy("web", true)))
+	assert.Equal(t, []PolicyID{"a"}, c.update("c", newPolicy("cache", true)))
+	assert.Equal(t, []PolicyID{"c"}, c.conflicting("a"))
+	assert.Equal(t, 2, c.count())
+
+	// Disabling a policy removes the conflict.
+	assert.Empty(t, c.update("c", newPolicy("cache", false)))
+	assert.Empty(t, c.conflicting("a"))
+	assert.Equal(t, 0, c.count())
+
+	// Removed policies are no longer reported.
+	c.update("c", newPolicy("cache", true))
+	c.remove("a")
+	assert.Empty(t, c.conflicting("c"))
+}
+
+func Test_conflictTracker_weightedASGs(t *testing.T) {
+	newPolicy := func(cfg map[string]string) *sdk.ScalingPolicy {
+		return &sdk.ScalingPolicy{
+			Type:    sdk.ScalingPolicyTypeCluster,
+			Enabled: true,
+			Target:  &sdk.ScalingPolicyTarget{Name: "aws-asg", Config: cfg},
+		}
+	}
+
+	c := newConflictTracker()
+
+	// Policies spreading their count across ASGs conflict with the policies
+	// scaling any of them.
+	assert.Empty(t, c.update("weighted", newPolicy(map[string]string{"aws_asg_weights": "on-demand=30,spot=70"})))
+	assert.Empty(t, c.update("other", newPolicy(map[string]string{"aws_asg_name": "batch"})))
+	assert.Equal(t, []PolicyID{"weighted"}, c.update("spot", newPolicy(map[string]string{"aws_asg_name": "spot"})))
+	assert.Equal(t, []PolicyID{"spot"}, c.conflicting("weighted"))
+	assert.Equal(t, 2, c.count())
+
+	assert.Equal(t, []PolicyID{"spot", "weighted"},
+		c.update("overlap", newPolicy(map[string]string{"aws_asg_weights": "spot=1,other=1"})))
+	assert.Equal(t, 3, c.count())
+}
@@ -4,23 +4,97 @@
 package file
 
 import (
+	"fmt"
+	"path/filepath"
+	"strings"
 	"time"
 
 	multierror "github.com/hashicorp/go-multierror"
-	"github.com/hashicorp/hcl/v2/hclsimple"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/gohcl"
+	"github.com/hashicorp/hcl/v2/hclparse"
+	"github.com/hashicorp/nomad-autoscaler/policy"
 	"github.com/hashicorp/nomad-autoscaler/sdk"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/convert"
 )
 
+// fileSchema is the schema of the top level of a policy file.
+var fileSchema = &hcl.BodySchema{
+	Blocks: []hcl.BlockHeaderSchema{
+		{Type: "variables"},
+		{Type: "scaling", LabelNames: []string{"name"}},
+	},
+}
+
+// scalingSchema is used to extract the for_each argument from a scaling block
+// before decoding the rest of the block into the policy.
+var scalingSchema = &hcl.BodySchema{
+	Attributes: []hcl.AttributeSchema{
+		{Name: "for_each"},
+	},
+}
+
+// decodeFile decodes all the scaling policies defined in file.
+//
+// Values defined in variables blocks can be referenced in policies as
+// var.<name>. A scaling block with a for_each argument set to a list or map
+// generates one policy for each element, which can be referenced as each.key
+// and each.value. Generated policies are named <name>[<key>].
 func decodeFile(file string) (map[string]*sdk.ScalingPolicy, error) {
+	parser := hclparse.NewParser()
+
+	var (
+		f     *hcl.File
+		diags hcl.Diagnostics
+	)
+	if strings.ToLower(filepath.Ext(file)) == ".json" {
+		f, diags = parser.ParseJSONFile(file)
+	} else {
+		f, diags = parser.ParseHCLFile(file)
+	}
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	return decodePolicies(f)
+}
+
+// DecodeSource decodes all the scaling policies defined in src, which uses
+// the same format as policy files, keyed by name. The filename is used in
+// error messages and its extension selects the JSON or HCL syntax. The
+// policies are not validated.
+func DecodeSource(src []byte, filename string) (map[string]*sdk.ScalingPolicy, error) {
+	parser := hclparse.NewParser()
+
+	var (
+		f     *hcl.File
+		diags hcl.Diagnostics
+	)
+	if strings.ToLower(filepath.Ext(filename)) == ".json" {
+		f, diags = parser.ParseJSON(src, filename)
+	} else {
+		f, diags = parser.ParseHCL(src, filename)
+	}
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	return decodePolicies(f)
+}
+
+// decodePolicies decodes all the scaling policies defined in the parsed
+// file f.
+func decodePolicies(f *hcl.File) (map[string]*sdk.ScalingPolicy, error) {
 	policies := make(map[string]*sdk.ScalingPolicy)
 
-	filePolicies := sdk.FileDecodeScalingPolicies{}
-	if err := hclsimple.DecodeFile(file, nil, &filePolicies); err != nil {
-		return nil, err
+	filePolicies, diags := decodeFilePolicies(f)
+	if diags.HasErrors() {
+		return nil, diags
 	}
 
 	var mErr *multierror.Error
-	for _, p := range filePolicies.ScalingPolicies {
+	for _, p := range filePolicies {
 		if err := decodePolicyDoc(p); err != nil {
 			mErr = multierror.Append(mErr, multierror.Prefix(err, p.Name))
 		}
@@ -31,7 +105,166 @@ func decodeFile(file string) (map[string]*sdk.ScalingPolicy, error) {
 	}
 
 	return policies, nil
+}
+
+// DecodeFile decodes all the scaling policies defined in file, keyed by name,
+// outside of a file source. The policies use their name as ID and are
+// processed in the same way as the policies read by the file source.
+func DecodeFile(file string, pr *policy.Processor) (map[string]*sdk.ScalingPolicy, error) {
+	policies, err := decodeFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	var mErr *multierror.Error
+	for name, p := range policies {
+		p.ID = name
+		pr.ApplyPolicyDefaults(p)
+
+		if err := pr.ValidatePolicy(p); err != nil {
+			mErr = multierror.Append(mErr, multierror.Prefix(err, name))
+			continue
+		}
+
+		for _, c := range p.Checks {
+			pr.CanonicalizeCheck(c, p.Target)
+		}
+	}
+
+	return policies, mErr.ErrorOrNil()
+}
+
+// decodeFilePolicies decodes the scaling blocks of the parsed file f,
+// expanding the ones that use for_each.
+func decodeFilePolicies(f *hcl.File) ([]*sdk.FileDecodeScalingPolicy, hcl.Diagnostics) {
+	content, diags := f.Body.Content(fileSchema)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	vars := map[string]cty.Value{}
+	for _, block := range content.Blocks.OfType("variables") {
+		attrs, attrDiags := block.Body.JustAttributes()
+		diags = append(diags, attrDiags...)
+
+		for name, attr := range attrs {
+			if _, ok := vars[name]; ok {
+				diags = append(diags, &hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Duplicate variable",
+					Detail:   fmt.Sprintf("The variable %q is defined more than once.", name),
+					Subject:  attr.NameRange.Ptr(),
+				})
+				continue
+			}
+
+			val, valDiags := attr.Expr.Value(nil)
+			diags = append(diags, valDiags...)
+			vars[name] = val
+		}
+	}
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	var policies []*sdk.FileDecodeScalingPolicy
+	for _, block := range content.Blocks.OfType("scaling") {
+		blockPolicies, blockDiags := decodeScalingBlock(block, vars)
+		diags = append(diags, blockDiags...)
+		policies = append(policies, blockPolicies...)
+	}
+
+	return policies, diags
+}
+
+// decodeScalingBlock decodes a scaling block into one policy or, if it sets
+// for_each, into one policy for each element of the for_each value.
+func decodeScalingBlock(block *hcl.Block, vars map[string]cty.Value) ([]*sdk.FileDecodeScalingPolicy, hcl.Diagnostics) {
+	name := block.Labels[0]
+
+	ctx := &hcl.EvalContext{
+		Variables: map[string]cty.Value{
+			"var": cty.ObjectVal(vars),
+		},
+	}
+
+	content, body, diags := block.Body.PartialContent(scalingSchema)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	forEach, ok := content.Attributes["for_each"]
+	if !ok {
+		p := &sdk.FileDecodeScalingPolicy{}
+		diags = append(diags, gohcl.DecodeBody(body, ctx, p)...)
+		p.Name = name
+		return []*sdk.FileDecodeScalingPolicy{p}, diags
+	}
+
+	forEachVal, valDiags := forEach.Expr.Value(ctx)
+	diags = append(diags, valDiags...)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	ty := forEachVal.Type()
+	if forEachVal.IsNull() || !forEachVal.IsWhollyKnown() ||
+		!(ty.IsListType() || ty.IsSetType() || ty.IsTupleType() || ty.IsMapType() || ty.IsObjectType()) {
+		return nil, append(diags, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Invalid for_each argument",
+			Detail:   "The for_each argument must be a list of strings or a map.",
+			Subject:  forEach.Expr.Range().Ptr(),
+		})
+	}
+
+	var policies []*sdk.FileDecodeScalingPolicy
+	seen := map[string]bool{}
 
+	for it := forEachVal.ElementIterator(); it.Next(); {
+		k, v := it.Element()
+
+		// Elements of lists are keyed by their value, so they must be
+		// strings.
+		if !ty.IsMapType() && !ty.IsObjectType() {
+			k = v
+		}
+
+		key, err := convert.Convert(k, cty.String)
+		if err != nil || key.IsNull() {
+			return nil, append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid for_each argument",
+				Detail:   "The elements of a for_each list must be strings.",
+				Subject:  forEach.Expr.Range().Ptr(),
+			})
+		}
+
+		if seen[key.AsString()] {
+			return nil, append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid for_each argument",
+				Detail:   fmt.Sprintf("The for_each argument contains the duplicate key %q.", key.AsString()),
+				Subject:  forEach.Expr.Range().Ptr(),
+			})
+		}
+		seen[key.AsString()] = true
+
+		eachCtx := ctx.NewChild()
+		eachCtx.Variables = map[string]cty.Value{
+			"each": cty.ObjectVal(map[string]cty.Value{
+				"key":   key,
+				"value": v,
+			}),
+		}
+
+		p := &sdk.FileDecodeScalingPolicy{}
+		diags = append(diags, gohcl.DecodeBody(body, eachCtx, p)...)
+		p.Name = fmt.Sprintf("%s[%s]", name, key.AsString())
+		policies = append(policies, p)
+	}
+
+	return policies, diags
 }
 
 func decodePolicyDoc(decodePolicy *sdk.FileDecodeScalingPolicy) error {
@@ -41,6 +274,16 @@ func decodePolicyDoc(decodePolicy *sdk.FileDecodeScalingPolicy) error {
 		decodePolicy.Type = sdk.ScalingPolicyTypeCluster
 	}
 
+	switch decodePolicy.Type {
+	case sdk.ScalingPolicyTypeCluster, sdk.ScalingPolicyTypeHorizontal:
+	case sdk.ScalingPolicyTypeVerticalCPU, sdk.ScalingPolicyTypeVerticalMem, sdk.ScalingPolicyTypeVertical:
+		if err := decodeVerticalTarget(decodePolicy); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("policy type %q not supported", decodePolicy.Type)
+	}
+
 	if decodePolicy.Doc.CooldownHCL != "" {
 		d, err := time.ParseDuration(decodePolicy.Doc.CooldownHCL)
 		if err != nil {
@@ -57,7 +300,39 @@ func decodePolicyDoc(decodePolicy *sdk.FileDecodeScalingPolicy) error {
 		decodePolicy.Doc.EvaluationInterval = d
 	}
 
-	// Parse query window for each check.
+	if decodePolicy.Doc.EvaluationTimeoutHCL != "" {
+		d, err := time.ParseDuration(decodePolicy.Doc.EvaluationTimeoutHCL)
+		if err != nil {
+			return err
+		}
+		decodePolicy.Doc.EvaluationTimeout = d
+	}
+
+	if decodePolicy.Doc.MaxUnavailableHCL != "" {
+		pct, err := sdk.ParseMaxUnavailable(decodePolicy.Doc.MaxUnavailableHCL)
+		if err != nil {
+			return err
+		}
+		decodePolicy.Doc.MaxUnavailable = pct
+	}
+
+	if decodePolicy.Doc.ApprovalTTLHCL != "" {
+		d, err := time.ParseDuration(decodePolicy.Doc.ApprovalTTLHCL)
+		if err != nil {
+			return err
+		}
+		decodePolicy.Doc.ApprovalTTL = d
+	}
+
+	if decodePolicy.Doc.QueryWindowOffsetHCL != "" {
+		d, err := time.ParseDuration(decodePolicy.Doc.QueryWindowOffsetHCL)
+		if err != nil {
+			return err
+		}
+		decodePolicy.Doc.QueryWindowOffset = d
+	}
+
+	// Parse query window, retry budget and fallback age for each check.
 	for i := 0; i < len(decodePolicy.Doc.Checks); i++ {
 		check := decodePolicy.Doc.Checks[i]
 
@@ -76,7 +351,52 @@ func decodePolicyDoc(decodePolicy *sdk.FileDecodeScalingPolicy) error {
 			}
 			decodePolicy.Doc.Checks[i].QueryWindowOffset = o
 		}
+
+		if check.QueryRetryBudgetHCL != "" {
+			b, err := time.ParseDuration(check.QueryRetryBudgetHCL)
+			if err != nil {
+				return err
+			}
+			decodePolicy.Doc.Checks[i].QueryRetryBudget = b
+		}
+
+		if check.QueryFallbackMaxAgeHCL != "" {
+			a, err := time.ParseDuration(check.QueryFallbackMaxAgeHCL)
+			if err != nil {
+				return err
+			}
+			decodePolicy.Doc.Checks[i].QueryFallbackMaxAge = a
+		}
 	}
 
 	return nil
 }
+
+// decodeVerticalTarget validates the target of a vertical policy and sets the
+// task resource it scales based on the policy type. Policies of the vertical
+// type scale the resource of each of their checks instead.
+func decodeVerticalTarget(decodePolicy *sdk.FileDecodeScalingPolicy) error {
+	target := decodePolicy.Doc.Target
+	if target == nil {
+		return fmt.Errorf("%s policy %q requires a target", decodePolicy.Type, decodePolicy.Name)
+	}
+
+	var missing []string
+	for _, k := range []string{sdk.TargetConfigKeyJob, sdk.TargetConfigKeyTaskGroup, sdk.TargetConfigKeyTask} {
+		if target.Config[k] == "" {
+			missing = append(missing, k)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("%s policy %q target is missing required keys %s",
+			decodePolicy.Type, decodePolicy.Name, strings.Join(missing, ", "))
+	}
+
+	switch decodePolicy.Type {
+	case sdk.ScalingPolicyTypeVerticalCPU:
+		target.Config[sdk.TargetConfigKeyResource] = sdk.TargetResourceCPU
+	case sdk.ScalingPolicyTypeVerticalMem:
+		target.Config[sdk.TargetConfigKeyResource] = sdk.TargetResourceMemory
+	}
+	return nil
+}
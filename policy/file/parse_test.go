@@ -4,11 +4,15 @@
 package file
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
+	"github.com/hashicorp/nomad-autoscaler/policy"
 	"github.com/hashicorp/nomad-autoscaler/sdk"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func Test_decodeFile(t *testing.T) {
@@ -29,15 +33,31 @@ func Test_decodeFile(t *testing.T) {
 					Max:                100,
 					Cooldown:           10 * time.Minute,
 					EvaluationInterval: 1 * time.Minute,
+					EvaluationTimeout:  30 * time.Second,
 					OnCheckError:       "error",
+					OnOutOfBandChange:  "revert",
+					PriorityLane:       "high",
+					MaxUnavailable:     25,
+					MaxHourlyCost:      12.5,
+					ApprovalRequired:   true,
+					ApprovalTTL:        30 * time.Minute,
+					Explain:            true,
+					QueryWindowOffset:  3 * time.Minute,
+					Meta: map[string]string{
+						"team":        "platform",
+						"cost_center": "cc-1234",
+					},
 					Checks: []*sdk.ScalingPolicyCheck{
 						{
-							Name:              "cpu_nomad",
-							Group:             "cpu",
-							Source:            "nomad_apm",
-							Query:             "cpu_high-memory",
-							QueryWindow:       time.Minute,
-							QueryWindowOffset: 2 * time.Minute,
+							Name:                "cpu_nomad",
+							Group:               "cpu",
+							Source:              "nomad_apm",
+							Query:               "cpu_high-memory",
+							QueryWindow:         time.Minute,
+							QueryWindowOffset:   2 * time.Minute,
+							HistorySize:         5,
+							QueryRetryBudget:    30 * time.Second,
+							QueryFallbackMaxAge: 5 * time.Minute,
 							Strategy: &sdk.ScalingPolicyStrategy{
 								Name: "target-value",
 								Config: map[string]string{
@@ -64,6 +84,7 @@ func Test_decodeFile(t *testing.T) {
 							"aws_asg_name":        "my-target-asg",
 							"node_class":          "high-memory",
 							"node_drain_deadline": "15m",
+							"unit_hourly_cost":    "0.25",
 						},
 					},
 				},
@@ -118,6 +139,143 @@ func Test_decodeFile(t *testing.T) {
 			expectedOutputError: nil,
 			name:                "full parsable task group scaling policy",
 		},
+		{
+			inputFile: "./test-fixtures/for-each-policy.hcl",
+			expectedOutputPolicies: map[string]*sdk.ScalingPolicy{
+				"cache[api]": {
+					Type:     sdk.ScalingPolicyTypeHorizontal,
+					Enabled:  true,
+					Min:      1,
+					Max:      10,
+					Cooldown: time.Minute,
+					Checks: []*sdk.ScalingPolicyCheck{
+						{
+							Name:   "cpu_nomad",
+							Source: "nomad_apm",
+							Query:  "avg_cpu",
+							Strategy: &sdk.ScalingPolicyStrategy{
+								Name:   "target-value",
+								Config: map[string]string{"target": "70"},
+							},
+						},
+					},
+					Target: &sdk.ScalingPolicyTarget{
+						Name:   "nomad",
+						Config: map[string]string{"Group": "api", "Job": "cache"},
+					},
+				},
+				"cache[web]": {
+					Type:     sdk.ScalingPolicyTypeHorizontal,
+					Enabled:  true,
+					Min:      2,
+					Max:      20,
+					Cooldown: time.Minute,
+					Checks: []*sdk.ScalingPolicyCheck{
+						{
+							Name:   "cpu_nomad",
+							Source: "nomad_apm",
+							Query:  "avg_cpu",
+							Strategy: &sdk.ScalingPolicyStrategy{
+								Name:   "target-value",
+								Config: map[string]string{"target": "70"},
+							},
+						},
+					},
+					Target: &sdk.ScalingPolicyTarget{
+						Name:   "nomad",
+						Config: map[string]string{"Group": "web", "Job": "cache"},
+					},
+				},
+			},
+			expectedOutputError: nil,
+			name:                "scaling policies generated with for_each",
+		},
+		{
+			inputFile: "./test-fixtures/vertical-cpu-policy.hcl",
+			expectedOutputPolicies: map[string]*sdk.ScalingPolicy{
+				"vertical-cpu-policy": {
+					Type:               sdk.ScalingPolicyTypeVerticalCPU,
+					Enabled:            true,
+					Min:                100,
+					Max:                2000,
+					Cooldown:           time.Hour,
+					EvaluationInterval: 10 * time.Minute,
+					Checks: []*sdk.ScalingPolicyCheck{
+						{
+							Name:        "cpu_p95",
+							Source:      "prometheus",
+							Query:       `nomad_client_allocs_cpu_total_ticks{exported_job="example",task_group="cache",task="redis"}`,
+							QueryWindow: 24 * time.Hour,
+							Strategy: &sdk.ScalingPolicyStrategy{
+								Name:   "percentile",
+								Config: map[string]string{"percentile": "95", "margin": "0.1"},
+							},
+						},
+					},
+					Target: &sdk.ScalingPolicyTarget{
+						Name: "nomad-target",
+						Config: map[string]string{
+							"Job":      "example",
+							"Group":    "cache",
+							"Task":     "redis",
+							"Resource": "cpu",
+						},
+					},
+				},
+			},
+			expectedOutputError: nil,
+			name:                "vertical cpu scaling policy",
+		},
+		{
+			inputFile: "./test-fixtures/vertical-policy.hcl",
+			expectedOutputPolicies: map[string]*sdk.ScalingPolicy{
+				"vertical-policy": {
+					Type:               sdk.ScalingPolicyTypeVertical,
+					Enabled:            true,
+					Min:                100,
+					Max:                2000,
+					Cooldown:           time.Hour,
+					EvaluationInterval: 10 * time.Minute,
+					Resources: map[string]*sdk.ScalingPolicyResource{
+						"memory": {Min: 128, Max: 4096},
+					},
+					Checks: []*sdk.ScalingPolicyCheck{
+						{
+							Name:        "cpu_p95",
+							Resource:    "cpu",
+							Source:      "prometheus",
+							Query:       `nomad_client_allocs_cpu_total_ticks{exported_job="example",task_group="cache",task="redis"}`,
+							QueryWindow: 24 * time.Hour,
+							Strategy: &sdk.ScalingPolicyStrategy{
+								Name:   "percentile",
+								Config: map[string]string{"percentile": "95", "margin": "0.1"},
+							},
+						},
+						{
+							Name:        "mem_max",
+							Resource:    "memory",
+							Source:      "prometheus",
+							Query:       `nomad_client_allocs_memory_usage{exported_job="example",task_group="cache",task="redis"} / 1048576`,
+							QueryWindow: 24 * time.Hour,
+							Strategy: &sdk.ScalingPolicyStrategy{
+								Name:   "percentile",
+								Config: map[string]string{"percentile": "100", "margin": "0.2"},
+							},
+						},
+					},
+					Target: &sdk.ScalingPolicyTarget{
+						Name: "nomad-target",
+						Config: map[string]string{
+							"Job":   "example",
+							"Group": "cache",
+							"Task":  "redis",
+						},
+					},
+				},
+			},
+			expectedOutputError: nil,
+			name:                "vertical scaling policy with multiple resources",
+		},
 	}
 
 	for _, tc := range testCases {
@@ -133,3 +291,186 @@ func Test_decodeFile(t *testing.T) {
 		})
 	}
 }
+
+func TestDecodeFile(t *testing.T) {
+	pr := policy.NewProcessor(&policy.ConfigDefaults{
+		DefaultEvaluationInterval: 10 * time.Second,
+		DefaultCooldown:           5 * time.Minute,
+	}, []string{"nomad_apm"})
+
+	policies, err := DecodeFile("./test-fixtures/full-task-group-policy.hcl", pr)
+	require.NoError(t, err)
+	require.Contains(t, policies, "full-task-group-policy")
+
+	p := policies["full-task-group-policy"]
+	assert.Equal(t, "full-task-group-policy", p.ID)
+	assert.Equal(t, 30*time.Second, p.EvaluationInterval)
+	for _, c := range p.Checks {
+		assert.Equal(t, policy.DefaultQueryWindow, c.QueryWindow)
+	}
+}
+
+func TestDecodeSource(t *testing.T) {
+	src, err := os.ReadFile("./test-fixtures/full-cluster-policy.hcl")
+	require.NoError(t, err)
+
+	fromSource, err := DecodeSource(src, "policy.hcl")
+	require.NoError(t, err)
+
+	fromFile, err := decodeFile("./test-fixtures/full-cluster-policy.hcl")
+	require.NoError(t, err)
+	assert.Equal(t, fromFile, fromSource)
+
+	_, err = DecodeSource([]byte(`scaling "invalid" {`), "policy.hcl")
+	assert.ErrorContains(t, err, "policy.hcl")
+
+	policies, err := DecodeSource([]byte(`{"scaling": {"json": {"enabled": true, "max": 5, "policy": [{}]}}}`), "policy.json")
+	require.NoError(t, err)
+	assert.Contains(t, policies, "json")
+}
+
+func Test_decodeFile_forEach(t *testing.T) {
+	testCases := []struct {
+		name          string
+		input         string
+		expectedNames []string
+		expectedErr   string
+	}{
+		{
+			name: "list of strings",
+			input: `
+variables {
+  jobs = ["web", "api"]
+}
+
+scaling "jobs" {
+  for_each = var.jobs
+  max      = 10
+
+  policy {
+    target "nomad" {
+      Job = each.value
+    }
+  }
+}`,
+			expectedNames: []string{"jobs[api]", "jobs[web]"},
+		},
+		{
+			name: "duplicate keys",
+			input: `
+scaling "jobs" {
+  for_each = ["web", "web"]
+  max      = 10
+  policy {}
+}`,
+			expectedErr: `duplicate key "web"`,
+		},
+		{
+			name: "invalid type",
+			input: `
+scaling "jobs" {
+  for_each = "web"
+  max      = 10
+  policy {}
+}`,
+			expectedErr: "must be a list of strings or a map",
+		},
+		{
+			name: "undefined variable",
+			input: `
+scaling "jobs" {
+  max = var.max
+  policy {}
+}`,
+			expectedErr: "Unsupported attribute",
+		},
+		{
+			name: "each outside for_each",
+			input: `
+scaling "jobs" {
+  max = each.value
+  policy {}
+}`,
+			expectedErr: "Unknown variable",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			file := filepath.Join(t.TempDir(), "policy.hcl")
+			require.NoError(t, os.WriteFile(file, []byte(tc.input), 0600))
+
+			policies, err := decodeFile(file)
+			if tc.expectedErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.expectedErr)
+				return
+			}
+
+			require.NoError(t, err)
+			names := make([]string, 0, len(policies))
+			for name := range policies {
+				names = append(names, name)
+			}
+			assert.ElementsMatch(t, tc.expectedNames, names)
+		})
+	}
+}
+
+func Test_decodePolicyDoc_type(t *testing.T) {
+	testCases := []struct {
+		name             string
+		policyType       string
+		targetConfig     map[string]string
+		expectedResource string
+		expectedErr      string
+	}{
+		{
+			name:       "default to cluster",
+			policyType: "",
+		},
+		{
+			name:             "vertical memory",
+			policyType:       sdk.ScalingPolicyTypeVerticalMem,
+			targetConfig:     map[string]string{"Job": "example", "Group": "cache", "Task": "redis"},
+			expectedResource: sdk.TargetResourceMemory,
+		},
+		{
+			name:         "vertical multiple resources",
+			policyType:   sdk.ScalingPolicyTypeVertical,
+			targetConfig: map[string]string{"Job": "example", "Group": "cache", "Task": "redis"},
+		},
+		{
+			name:         "vertical missing task",
+			policyType:   sdk.ScalingPolicyTypeVerticalCPU,
+			targetConfig: map[string]string{"Job": "example", "Group": "cache"},
+			expectedErr:  `vertical_cpu policy "test" target is missing required keys Task`,
+		},
+		{
+			name:        "unknown type",
+			policyType:  "diagonal",
+			expectedErr: `policy type "diagonal" not supported`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			p := &sdk.FileDecodeScalingPolicy{
+				Name: "test",
+				Type: tc.policyType,
+				Doc: &sdk.FileDecodePolicyDoc{
+					Target: &sdk.ScalingPolicyTarget{Name: "nomad-target", Config: tc.targetConfig},
+				},
+			}
+
+			err := decodePolicyDoc(p)
+			if tc.expectedErr != "" {
+				assert.EqualError(t, err, tc.expectedErr)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedResource, p.Doc.Target.Config[sdk.TargetConfigKeyResource])
+		})
+	}
+}
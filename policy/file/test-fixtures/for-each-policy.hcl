@@ -0,0 +1,37 @@
+# Copyright (c) HashiCorp, Inc.
+# SPDX-License-Identifier: MPL-2.0
+
+variables {
+  target_cpu = "70"
+
+  groups = {
+    web = { min = 2, max = 20 }
+    api = { min = 1, max = 10 }
+  }
+}
+
+scaling "cache" {
+  enabled  = true
+  type     = "horizontal"
+  for_each = var.groups
+  min      = each.value.min
+  max      = each.value.max
+
+  policy {
+    cooldown = "1m"
+
+    check "cpu_nomad" {
+      source = "nomad_apm"
+      query  = "avg_cpu"
+
+      strategy "target-value" {
+        target = var.target_cpu
+      }
+    }
+
+    target "nomad" {
+      Group = each.key
+      Job   = "cache"
+    }
+  }
+}
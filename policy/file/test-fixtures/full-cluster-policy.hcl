@@ -9,9 +9,23 @@ scaling "full-cluster-policy" {
 
   policy {
 
-    cooldown            = "10m"
-    evaluation_interval = "1m"
-    on_check_error      = "error"
+    cooldown              = "10m"
+    evaluation_interval   = "1m"
+    evaluation_timeout    = "30s"
+    on_check_error        = "error"
+    on_out_of_band_change = "revert"
+    priority              = "high"
+    max_unavailable       = "25%"
+    max_hourly_cost       = 12.5
+    approval_required     = true
+    approval_ttl          = "30m"
+    explain               = true
+    query_window_offset   = "3m"
+
+    meta = {
+      team        = "platform"
+      cost_center = "cc-1234"
+    }
 
     check "cpu_nomad" {
       source              = "nomad_apm"
@@ -19,6 +33,10 @@ scaling "full-cluster-policy" {
       query_window        = "1m"
       query_window_offset = "2m"
       group               = "cpu"
+      history_size        = 5
+
+      query_retry_budget     = "30s"
+      query_fallback_max_age = "5m"
 
       strategy "target-value" {
         target = "80"
@@ -39,6 +57,7 @@ scaling "full-cluster-policy" {
       aws_asg_name        = "my-target-asg"
       node_class          = "high-memory"
       node_drain_deadline = "15m"
+      unit_hourly_cost    = "0.25"
     }
   }
 }
@@ -0,0 +1,32 @@
+# Copyright (c) HashiCorp, Inc.
+# SPDX-License-Identifier: MPL-2.0
+
+scaling "vertical-cpu-policy" {
+  enabled = true
+  min     = 100
+  max     = 2000
+  type    = "vertical_cpu"
+
+  policy {
+
+    cooldown            = "1h"
+    evaluation_interval = "10m"
+
+    check "cpu_p95" {
+      source       = "prometheus"
+      query        = "nomad_client_allocs_cpu_total_ticks{exported_job=\"example\",task_group=\"cache\",task=\"redis\"}"
+      query_window = "24h"
+
+      strategy "percentile" {
+        percentile = "95"
+        margin     = "0.1"
+      }
+    }
+
+    target "nomad-target" {
+      Job   = "example"
+      Group = "cache"
+      Task  = "redis"
+    }
+  }
+}
@@ -0,0 +1,50 @@
+# Copyright (c) HashiCorp, Inc.
+# SPDX-License-Identifier: MPL-2.0
+
+scaling "vertical-policy" {
+  enabled = true
+  min     = 100
+  max     = 2000
+  type    = "vertical"
+
+  policy {
+
+    cooldown            = "1h"
+    evaluation_interval = "10m"
+
+    resource "memory" {
+      min = 128
+      max = 4096
+    }
+
+    check "cpu_p95" {
+      resource     = "cpu"
+      source       = "prometheus"
+      query        = "nomad_client_allocs_cpu_total_ticks{exported_job=\"example\",task_group=\"cache\",task=\"redis\"}"
+      query_window = "24h"
+
+      strategy "percentile" {
+        percentile = "95"
+        margin     = "0.1"
+      }
+    }
+
+    check "mem_max" {
+      resource     = "memory"
+      source       = "prometheus"
+      query        = "nomad_client_allocs_memory_usage{exported_job=\"example\",task_group=\"cache\",task=\"redis\"} / 1048576"
+      query_window = "24h"
+
+      strategy "percentile" {
+        percentile = "100"
+        margin     = "0.2"
+      }
+    }
+
+    target "nomad-target" {
+      Job   = "example"
+      Group = "cache"
+      Task  = "redis"
+    }
+  }
+}
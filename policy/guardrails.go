@@ -0,0 +1,102 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package policy
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/nomad-autoscaler/sdk"
+)
+
+// Guardrails are limits enforced on all policies, regardless of what
+// individual policies request. A nil Guardrails doesn't enforce any limit.
+type Guardrails struct {
+	// MaxCount and MaxStep are keyed by policy type.
+	MaxCount map[string]int64
+	MaxStep  map[string]int64
+
+	// DenyTargets and DenyNamespaces are the target plugins and Nomad
+	// namespaces that policies are not allowed to scale.
+	DenyTargets    []string
+	DenyNamespaces []string
+
+	// MinEvaluationInterval and MinCooldown are the shortest evaluation
+	// interval and cooldown allowed for policies.
+	MinEvaluationInterval time.Duration
+	MinCooldown           time.Duration
+
+	// MaxTargetActions is the maximum number of scaling actions a target can
+	// receive within MaxTargetActionsPeriod. A zero value doesn't limit
+	// scaling actions.
+	MaxTargetActions       int
+	MaxTargetActionsPeriod time.Duration
+}
+
+// MutatePolicy limits the min and max values of the policy to the max count
+// of its type, and raises its evaluation interval and cooldown to the
+// minimum allowed.
+func (g *Guardrails) MutatePolicy(p *sdk.ScalingPolicy) Mutations {
+	result := Mutations{}
+	if g == nil {
+		return result
+	}
+
+	if max, ok := g.MaxCount[p.Type]; ok {
+		result = append(result, MaxMutator{Max: max}.MutatePolicy(p)...)
+	}
+
+	if p.EvaluationInterval < g.MinEvaluationInterval {
+		result = append(result, fmt.Sprintf("evaluation interval raised from %s to %s",
+			p.EvaluationInterval, g.MinEvaluationInterval))
+		p.EvaluationInterval = g.MinEvaluationInterval
+	}
+	if p.Cooldown < g.MinCooldown {
+		result = append(result, fmt.Sprintf("cooldown raised from %s to %s", p.Cooldown, g.MinCooldown))
+		p.Cooldown = g.MinCooldown
+	}
+
+	return result
+}
+
+// Check returns an error if the policy targets a denied target plugin or
+// namespace.
+func (g *Guardrails) Check(p *sdk.ScalingPolicy) error {
+	if g == nil || p.Target == nil {
+		return nil
+	}
+
+	for _, t := range g.DenyTargets {
+		if p.Target.Name == t {
+			return fmt.Errorf("target %q is denied by the agent guardrails", t)
+		}
+	}
+
+	ns := p.Target.Config[sdk.TargetConfigKeyNamespace]
+	for _, denied := range g.DenyNamespaces {
+		if ns == denied {
+			return fmt.Errorf("namespace %q is denied by the agent guardrails", ns)
+		}
+	}
+
+	return nil
+}
+
+// MaxStepFor returns the max step of scaling actions for the policy type. A
+// zero value means the step is not limited.
+func (g *Guardrails) MaxStepFor(policyType string) int64 {
+	if g == nil {
+		return 0
+	}
+	return g.MaxStep[policyType]
+}
+
+// newActionLimiter returns the limiter of scaling actions per target. It
+// returns nil if scaling actions are not limited.
+func (g *Guardrails) newActionLimiter() *actionLimiter {
+	if g == nil {
+		return nil
+	}
+	return newActionLimiter(g.MaxTargetActions, g.MaxTargetActionsPeriod)
+}
@@ -0,0 +1,98 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package policy
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hashicorp/nomad-autoscaler/sdk"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGuardrails_MutatePolicy(t *testing.T) {
+	g := &Guardrails{MaxCount: map[string]int64{sdk.ScalingPolicyTypeCluster: 10}}
+
+	p := &sdk.ScalingPolicy{Type: sdk.ScalingPolicyTypeCluster, Min: 1, Max: 10000}
+	assert.Equal(t, Mutations{"max value reduced from 10000 to 10"}, g.MutatePolicy(p))
+	assert.Equal(t, int64(10), p.Max)
+
+	p = &sdk.ScalingPolicy{Type: sdk.ScalingPolicyTypeHorizontal, Min: 1, Max: 10000}
+	assert.Equal(t, Mutations{}, g.MutatePolicy(p))
+	assert.Equal(t, int64(10000), p.Max)
+
+	var nilGuardrails *Guardrails
+	assert.Equal(t, Mutations{}, nilGuardrails.MutatePolicy(p))
+}
+
+func TestGuardrails_MutatePolicy_intervals(t *testing.T) {
+	g := &Guardrails{MinEvaluationInterval: time.Second, MinCooldown: 10 * time.Second}
+
+	p := &sdk.ScalingPolicy{EvaluationInterval: 100 * time.Millisecond, Cooldown: 5 * time.Second}
+	assert.Equal(t, Mutations{
+		"evaluation interval raised from 100ms to 1s",
+		"cooldown raised from 5s to 10s",
+	}, g.MutatePolicy(p))
+	assert.Equal(t, time.Second, p.EvaluationInterval)
+	assert.Equal(t, 10*time.Second, p.Cooldown)
+
+	p = &sdk.ScalingPolicy{EvaluationInterval: 15 * time.Second, Cooldown: 15 * time.Second}
+	assert.Equal(t, Mutations{}, g.MutatePolicy(p))
+	assert.Equal(t, 15*time.Second, p.EvaluationInterval)
+	assert.Equal(t, 15*time.Second, p.Cooldown)
+}
+
+func TestGuardrails_Check(t *testing.T) {
+	g := &Guardrails{
+		DenyTargets:    []string{"aws-asg"},
+		DenyNamespaces: []string{"platform"},
+	}
+
+	testCases := []struct {
+		name        string
+		input       *sdk.ScalingPolicy
+		expectedErr string
+	}{
+		{
+			name: "allowed",
+			input: &sdk.ScalingPolicy{Target: &sdk.ScalingPolicyTarget{
+				Name:   "nomad-target",
+				Config: map[string]string{sdk.TargetConfigKeyNamespace: "default"},
+			}},
+		},
+		{
+			name:        "denied target",
+			input:       &sdk.ScalingPolicy{Target: &sdk.ScalingPolicyTarget{Name: "aws-asg"}},
+			expectedErr: `target "aws-asg" is denied by the agent guardrails`,
+		},
+		{
+			name: "denied namespace",
+			input: &sdk.ScalingPolicy{Target: &sdk.ScalingPolicyTarget{
+				Name:   "nomad-target",
+				Config: map[string]string{sdk.TargetConfigKeyNamespace: "platform"},
+			}},
+			expectedErr: `namespace "platform" is denied by the agent guardrails`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := g.Check(tc.input)
+			if tc.expectedErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, tc.expectedErr)
+		})
+	}
+}
+
+func TestGuardrails_MaxStepFor(t *testing.T) {
+	g := &Guardrails{MaxStep: map[string]int64{sdk.ScalingPolicyTypeCluster: 5}}
+	assert.Equal(t, int64(5), g.MaxStepFor(sdk.ScalingPolicyTypeCluster))
+	assert.Zero(t, g.MaxStepFor(sdk.ScalingPolicyTypeHorizontal))
+
+	var nilGuardrails *Guardrails
+	assert.Zero(t, nilGuardrails.MaxStepFor(sdk.ScalingPolicyTypeCluster))
+}
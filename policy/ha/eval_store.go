@@ -0,0 +1,120 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package ha
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"sync"
+
+	multierror "github.com/hashicorp/go-multierror"
+	"github.com/hashicorp/nomad-autoscaler/sdk"
+	"github.com/hashicorp/nomad/api"
+)
+
+// evalStoreItemKey is the variable item which holds the JSON encoded eval.
+const evalStoreItemKey = "eval"
+
+// VariableEvalStore persists the pending evaluations of the broker in Nomad
+// variables, one per evaluation, under a path prefix. Storing each eval in
+// its own variable keeps the variables small and avoids conflicting updates.
+// In high availability mode, the evals persisted by the previous leader are
+// replayed by the new one.
+type VariableEvalStore struct {
+	client     *api.Client
+	clientLock sync.RWMutex
+
+	namespace string
+	prefix    string
+}
+
+// NewVariableEvalStore returns a VariableEvalStore that stores the evals in
+// variables under prefix in the namespace.
+func NewVariableEvalStore(client *api.Client, namespace, prefix string) *VariableEvalStore {
+	return &VariableEvalStore{
+		client:    client,
+		namespace: namespace,
+		prefix:    prefix,
+	}
+}
+
+// SetNomadClient sets the Nomad client used to read and write the variables.
+func (v *VariableEvalStore) SetNomadClient(client *api.Client) {
+	v.clientLock.Lock()
+	defer v.clientLock.Unlock()
+	v.client = client
+}
+
+func (v *VariableEvalStore) variables() *api.Variables {
+	v.clientLock.RLock()
+	defer v.clientLock.RUnlock()
+	return v.client.Variables()
+}
+
+// Put satisfies the Put function on the policyeval.EvalStore interface.
+func (v *VariableEvalStore) Put(eval *sdk.ScalingEvaluation) error {
+	raw, err := json.Marshal(eval)
+	if err != nil {
+		return fmt.Errorf("failed to encode eval %s: %v", eval.ID, err)
+	}
+
+	variable := api.NewVariable(path.Join(v.prefix, eval.ID))
+	variable.Items[evalStoreItemKey] = string(raw)
+
+	if _, _, err := v.variables().Update(variable, &api.WriteOptions{Namespace: v.namespace}); err != nil {
+		return fmt.Errorf("failed to write variable %s: %v", variable.Path, err)
+	}
+	return nil
+}
+
+// Delete satisfies the Delete function on the policyeval.EvalStore
+// interface.
+func (v *VariableEvalStore) Delete(evalID string) error {
+	p := path.Join(v.prefix, evalID)
+	if _, err := v.variables().Delete(p, &api.WriteOptions{Namespace: v.namespace}); err != nil {
+		return fmt.Errorf("failed to delete variable %s: %v", p, err)
+	}
+	return nil
+}
+
+// List satisfies the List function on the policyeval.EvalStore interface.
+// Variables that can't be decoded are deleted, since they can never be
+// replayed.
+func (v *VariableEvalStore) List() ([]*sdk.ScalingEvaluation, error) {
+	vars := v.variables()
+	opts := &api.QueryOptions{Namespace: v.namespace}
+
+	metas, _, err := vars.PrefixList(v.prefix+"/", opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list variables %s: %v", v.prefix, err)
+	}
+
+	var mErr *multierror.Error
+	evals := make([]*sdk.ScalingEvaluation, 0, len(metas))
+
+	for _, meta := range metas {
+		items, _, err := vars.GetVariableItems(meta.Path, opts)
+		if errors.Is(err, api.ErrVariablePathNotFound) {
+			continue
+		}
+		if err != nil {
+			mErr = multierror.Append(mErr, fmt.Errorf("failed to read variable %s: %v", meta.Path, err))
+			continue
+		}
+
+		var eval sdk.ScalingEvaluation
+		if err := json.Unmarshal([]byte(items[evalStoreItemKey]), &eval); err != nil {
+			mErr = multierror.Append(mErr, fmt.Errorf("failed to decode variable %s: %v", meta.Path, err))
+			if _, err := vars.Delete(meta.Path, &api.WriteOptions{Namespace: v.namespace}); err != nil {
+				mErr = multierror.Append(mErr, fmt.Errorf("failed to delete variable %s: %v", meta.Path, err))
+			}
+			continue
+		}
+		evals = append(evals, &eval)
+	}
+
+	return evals, mErr.ErrorOrNil()
+}
@@ -0,0 +1,68 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package ha
+
+import (
+	"net/http/httptest"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/nomad-autoscaler/sdk"
+	"github.com/hashicorp/nomad/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVariableEvalStore(t *testing.T) {
+	srv := &testVariableServer{variables: make(map[string]*api.Variable)}
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+
+	client, err := api.NewClient(&api.Config{Address: ts.URL})
+	require.NoError(t, err)
+
+	store := NewVariableEvalStore(client, "default", "nomad-autoscaler/pending")
+
+	createTime := time.Date(2024, 6, 1, 14, 30, 0, 0, time.UTC)
+	eval1 := &sdk.ScalingEvaluation{
+		ID:           "eval1",
+		Policy:       &sdk.ScalingPolicy{ID: "policy1", Type: "horizontal", Cooldown: time.Minute},
+		CreateTime:   createTime,
+		HighPriority: true,
+	}
+	eval2 := &sdk.ScalingEvaluation{
+		ID:         "eval2",
+		Policy:     &sdk.ScalingPolicy{ID: "policy2", Type: "cluster"},
+		CreateTime: createTime,
+	}
+
+	require.NoError(t, store.Put(eval1))
+	require.NoError(t, store.Put(eval2))
+
+	// Updating an eval overwrites its variable.
+	require.NoError(t, store.Put(eval2))
+
+	// Variables that can't be decoded are reported and removed.
+	srv.variables["nomad-autoscaler/pending/invalid"] = &api.Variable{
+		Path:  "nomad-autoscaler/pending/invalid",
+		Items: api.VariableItems{evalStoreItemKey: "{"},
+	}
+
+	evals, err := store.List()
+	assert.ErrorContains(t, err, "failed to decode variable nomad-autoscaler/pending/invalid")
+	require.Len(t, evals, 2)
+
+	sort.Slice(evals, func(i, j int) bool { return evals[i].ID < evals[j].ID })
+	assert.Equal(t, eval1, evals[0])
+	assert.Equal(t, eval2, evals[1])
+	assert.NotContains(t, srv.variables, "nomad-autoscaler/pending/invalid")
+
+	require.NoError(t, store.Delete("eval1"))
+	require.NoError(t, store.Delete("eval2"))
+
+	evals, err = store.List()
+	require.NoError(t, err)
+	assert.Empty(t, evals)
+}
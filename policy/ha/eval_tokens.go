@@ -0,0 +1,166 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package ha
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/nomad/api"
+)
+
+// evalTokensMaxRetries is the number of times updating an evaluation token
+// variable is retried when another agent modified it concurrently.
+const evalTokensMaxRetries = 5
+
+// evalTokenItem is the variable item holding the token and its expiration
+// time.
+const evalTokenItem = "token"
+
+// VariableEvalTokens records the tokens of the policy evaluations in flight
+// as Nomad variables, one per policy under a path prefix. The variables are
+// shared by all the agents of a high availability deployment, so an agent
+// that becomes leader doesn't evaluate a policy that the previous leader is
+// still evaluating. Storing each token in its own variable keeps the updates
+// of different policies from conflicting with each other.
+//
+// Updates use the variable modify index to detect concurrent changes, and
+// recorded tokens expire so a policy is not blocked forever if an agent
+// stops without releasing its tokens. The variable of an expired token is
+// removed the next time a token is recorded or released for the policy.
+type VariableEvalTokens struct {
+	client     *api.Client
+	clientLock sync.RWMutex
+
+	namespace string
+	prefix    string
+
+	// now returns the current time. It can be overwritten in tests.
+	now func() time.Time
+}
+
+// NewVariableEvalTokens returns a VariableEvalTokens that records the tokens
+// in variables under the prefix in the namespace.
+func NewVariableEvalTokens(client *api.Client, namespace, prefix string) *VariableEvalTokens {
+	return &VariableEvalTokens{
+		client:    client,
+		namespace: namespace,
+		prefix:    prefix,
+		now:       time.Now,
+	}
+}
+
+// SetNomadClient sets the Nomad client used to read and write the variables.
+func (v *VariableEvalTokens) SetNomadClient(client *api.Client) {
+	v.clientLock.Lock()
+	defer v.clientLock.Unlock()
+	v.client = client
+}
+
+// Acquire satisfies the Acquire function on the policyeval.EvalTokenStore
+// interface.
+func (v *VariableEvalTokens) Acquire(policyID, token string, ttl time.Duration) (bool, error) {
+	acquired := false
+
+	err := v.update(policyID, func(current string, now time.Time) (string, bool) {
+		if current != "" && current != token {
+			acquired = false
+			return "", false
+		}
+
+		acquired = true
+		return formatEvalToken(token, now.Add(ttl)), true
+	})
+
+	return acquired, err
+}
+
+// Release satisfies the Release function on the policyeval.EvalTokenStore
+// interface.
+func (v *VariableEvalTokens) Release(policyID, token string) error {
+	return v.update(policyID, func(current string, _ time.Time) (string, bool) {
+		// The variable is also deleted if the token has expired.
+		return "", current == token || current == ""
+	})
+}
+
+// update reads the variable of the policy and calls fn with the token it
+// records, or an empty string if there is none or it has expired. If fn
+// returns true, the value it returns is written back, or the variable is
+// deleted if the value is empty. The update is retried if the variable was
+// modified since it was read.
+func (v *VariableEvalTokens) update(policyID string, fn func(current string, now time.Time) (string, bool)) error {
+	v.clientLock.RLock()
+	vars := v.client.Variables()
+	v.clientLock.RUnlock()
+
+	p := path.Join(v.prefix, policyID)
+
+	for i := 0; i < evalTokensMaxRetries; i++ {
+		variable, _, err := vars.Peek(p, &api.QueryOptions{Namespace: v.namespace})
+		if err != nil {
+			return fmt.Errorf("failed to read variable %s: %v", p, err)
+		}
+		if variable == nil {
+			variable = api.NewVariable(p)
+		}
+
+		now := v.now()
+		current, _ := parseEvalToken(variable.Items[evalTokenItem], now)
+
+		next, write := fn(current, now)
+		if !write {
+			return nil
+		}
+
+		// Variables without items can't be written, so delete it instead.
+		if next == "" {
+			if variable.ModifyIndex == 0 {
+				return nil
+			}
+			_, err = vars.CheckedDelete(p, variable.ModifyIndex,
+				&api.WriteOptions{Namespace: v.namespace})
+		} else {
+			variable.Items = api.VariableItems{evalTokenItem: next}
+			_, _, err = vars.CheckedUpdate(variable, &api.WriteOptions{Namespace: v.namespace})
+		}
+
+		var conflict api.ErrCASConflict
+		if errors.As(err, &conflict) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to write variable %s: %v", p, err)
+		}
+		return nil
+	}
+
+	return fmt.Errorf("failed to write variable %s: too many concurrent updates", p)
+}
+
+// formatEvalToken encodes the token and its expiration time as the value of
+// a variable item.
+func formatEvalToken(token string, expiresAt time.Time) string {
+	return token + " " + strconv.FormatInt(expiresAt.UnixNano(), 10)
+}
+
+// parseEvalToken decodes the value of a variable item. It returns false if
+// the value is invalid or the token has expired.
+func parseEvalToken(raw string, now time.Time) (string, bool) {
+	token, expiresAtRaw, ok := strings.Cut(raw, " ")
+	if !ok {
+		return "", false
+	}
+
+	expiresAt, err := strconv.ParseInt(expiresAtRaw, 10, 64)
+	if err != nil || !now.Before(time.Unix(0, expiresAt)) {
+		return "", false
+	}
+	return token, true
+}
@@ -0,0 +1,235 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package ha
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/nomad/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testVariableServer is a minimal implementation of the Nomad variables API
+// which supports listing and check-and-set writes.
+type testVariableServer struct {
+	lock      sync.Mutex
+	variables map[string]*api.Variable
+	index     uint64
+
+	// conflicts is the number of writes to reject with a CAS conflict, to
+	// simulate concurrent updates from other agents.
+	conflicts int
+}
+
+func (s *testVariableServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if r.URL.Path == "/v1/vars" {
+		prefix := r.URL.Query().Get("prefix")
+		vars := []*api.VariableMetadata{}
+		for path := range s.variables {
+			if strings.HasPrefix(path, prefix) {
+				vars = append(vars, &api.VariableMetadata{Path: path})
+			}
+		}
+		_ = json.NewEncoder(w).Encode(vars)
+		return
+	}
+
+	path := strings.TrimPrefix(r.URL.Path, "/v1/var/")
+	current := s.variables[path]
+
+	if r.Method == http.MethodGet {
+		if current == nil {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(current)
+		return
+	}
+
+	// Writes are only checked when a check-and-set index is passed.
+	if r.URL.Query().Has("cas") {
+		cas, _ := strconv.ParseUint(r.URL.Query().Get("cas"), 10, 64)
+		currentIndex := uint64(0)
+		if current != nil {
+			currentIndex = current.ModifyIndex
+		}
+		if cas != currentIndex || s.conflicts > 0 {
+			if s.conflicts > 0 {
+				s.conflicts--
+			}
+			w.WriteHeader(http.StatusConflict)
+			if current == nil {
+				current = &api.Variable{}
+			}
+			_ = json.NewEncoder(w).Encode(current)
+			return
+		}
+	}
+
+	if r.Method == http.MethodDelete {
+		delete(s.variables, path)
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	var v api.Variable
+	if err := json.NewDecoder(r.Body).Decode(&v); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	s.index++
+	v.ModifyIndex = s.index
+	s.variables[path] = &v
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func testVariableEvalTokens(t *testing.T, srv *testVariableServer, now *time.Time) *VariableEvalTokens {
+	ts := httptest.NewServer(srv)
+	t.Cleanup(ts.Close)
+
+	client, err := api.NewClient(&api.Config{Address: ts.URL})
+	require.NoError(t, err)
+
+	tokens := NewVariableEvalTokens(client, "default", "nomad-autoscaler/lock/evaluations")
+	tokens.now = func() time.Time { return *now }
+	return tokens
+}
+
+func TestVariableEvalTokens(t *testing.T) {
+	now := time.Date(2024, 6, 1, 14, 30, 0, 0, time.UTC)
+	srv := &testVariableServer{variables: make(map[string]*api.Variable)}
+
+	// Two agents sharing the same variables.
+	previous := testVariableEvalTokens(t, srv, &now)
+	leader := testVariableEvalTokens(t, srv, &now)
+
+	ok, err := previous.Acquire("policy1", "token1", time.Minute)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	// Acquiring the same token again refreshes it.
+	ok, err = previous.Acquire("policy1", "token1", time.Minute)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = leader.Acquire("policy1", "token2", time.Minute)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	ok, err = leader.Acquire("policy2", "token3", time.Minute)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	// Each policy is recorded in its own variable.
+	assert.Len(t, srv.variables, 2)
+	assert.Contains(t, srv.variables, "nomad-autoscaler/lock/evaluations/policy1")
+	assert.Contains(t, srv.variables, "nomad-autoscaler/lock/evaluations/policy2")
+
+	// Releasing a token that isn't recorded is a no-op.
+	require.NoError(t, leader.Release("policy1", "token2"))
+
+	require.NoError(t, previous.Release("policy1", "token1"))
+	ok, err = leader.Acquire("policy1", "token2", time.Minute)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	// Tokens expire if they are not released.
+	now = now.Add(2 * time.Minute)
+	ok, err = previous.Acquire("policy1", "token4", time.Minute)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	// The variable is deleted once the token is released.
+	require.NoError(t, previous.Release("policy1", "token4"))
+	assert.NotContains(t, srv.variables, "nomad-autoscaler/lock/evaluations/policy1")
+
+	// The variable of an expired token is deleted when it's released.
+	require.NoError(t, leader.Release("policy2", "token5"))
+	assert.Empty(t, srv.variables)
+}
+
+func TestVariableEvalTokens_conflicts(t *testing.T) {
+	now := time.Date(2024, 6, 1, 14, 30, 0, 0, time.UTC)
+
+	srv := &testVariableServer{variables: make(map[string]*api.Variable), conflicts: 2}
+	tokens := testVariableEvalTokens(t, srv, &now)
+
+	ok, err := tokens.Acquire("policy1", "token1", time.Minute)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	srv.conflicts = evalTokensMaxRetries
+	_, err = tokens.Acquire("policy2", "token2", time.Minute)
+	assert.ErrorContains(t, err, "too many concurrent updates")
+}
+
+func TestVariableEvalTokens_refresh(t *testing.T) {
+	now := time.Date(2024, 6, 1, 14, 30, 0, 0, time.UTC)
+	srv := &testVariableServer{variables: make(map[string]*api.Variable)}
+	tokens := testVariableEvalTokens(t, srv, &now)
+
+	ok, err := tokens.Acquire("policy1", "token1", time.Minute)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	// Acquiring the token again before it expires extends its expiration.
+	now = now.Add(50 * time.Second)
+	ok, err = tokens.Acquire("policy1", "token1", time.Minute)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	now = now.Add(50 * time.Second)
+	ok, err = tokens.Acquire("policy1", "token2", time.Minute)
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func Test_parseEvalToken(t *testing.T) {
+	now := time.Date(2024, 6, 1, 14, 30, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name          string
+		raw           string
+		expectedToken string
+		expectedOK    bool
+	}{
+		{
+			name:          "valid",
+			raw:           formatEvalToken("token1", now.Add(time.Second)),
+			expectedToken: "token1",
+			expectedOK:    true,
+		},
+		{
+			name: "expired",
+			raw:  formatEvalToken("token1", now),
+		},
+		{
+			name: "empty",
+			raw:  "",
+		},
+		{
+			name: "invalid expiration",
+			raw:  "token1 soon",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			token, ok := parseEvalToken(tc.raw, now)
+			assert.Equal(t, tc.expectedToken, token)
+			assert.Equal(t, tc.expectedOK, ok)
+		})
+	}
+}
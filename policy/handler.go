@@ -13,7 +13,9 @@ import (
 	"sync"
 	"time"
 
+	metrics "github.com/armon/go-metrics"
 	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
 	hclog "github.com/hashicorp/go-hclog"
 	"github.com/hashicorp/go-multierror"
 	"github.com/hashicorp/nomad-autoscaler/plugins/manager"
@@ -23,6 +25,10 @@ import (
 
 const (
 	cooldownIgnoreTime = 1 * time.Second
+
+	// maxSplay is the maximum random delay added before the first evaluation
+	// of a policy.
+	maxSplay = 3 * time.Second
 )
 
 // Handler monitors a policy for changes and controls when them are sent for
@@ -44,6 +50,10 @@ type Handler struct {
 	// mutators is a list of mutations to apply to policies.
 	mutators []Mutator
 
+	// guardrails are used to deny policies that target resources operators
+	// don't allow to be scaled. It is optional and set by the Manager.
+	guardrails *Guardrails
+
 	// ticker controls the frequency the policy is sent for evaluation.
 	ticker *time.Ticker
 
@@ -67,6 +77,52 @@ type Handler struct {
 	// reloadCh is used to communicate to the MonitorPolicy routine that it
 	// should perform a reload.
 	reloadCh chan struct{}
+
+	// conflicts is used to detect other policies that scale the same
+	// resource. It is optional and set by the Manager.
+	conflicts *conflictTracker
+
+	// history stores the results of previous evaluations of the policy
+	// checks, keyed by check name, for checks that have a history size.
+	history     map[string][]sdk.ScalingCheckHistoryEntry
+	historyLock sync.RWMutex
+
+	// queries stores the state of the queries of the policy checks, keyed
+	// by check name, for checks that have a query retry budget or fallback
+	// configured.
+	queries     map[string]CheckQueryState
+	queriesLock sync.RWMutex
+
+	// lastDirection is the direction of the last scaling decision of the
+	// policy. Evaluations of policies that last scaled up are placed in the
+	// priority lane.
+	lastDirection     sdk.ScaleDirection
+	lastDirectionLock sync.RWMutex
+
+	// state is the current state of the handler and stateSince is the time
+	// it entered it. targetMeta is the standard meta of the last status of
+	// the target. lastCheck and lastExplanation describe the last scaling
+	// decision of the policy.
+	state           HandlerState
+	stateSince      time.Time
+	targetMeta      map[string]string
+	lastCheck       string
+	lastExplanation *sdk.ScalingActionExplanation
+	stateLock       sync.RWMutex
+}
+
+// CheckQueryState is the state of the query of a policy check across
+// evaluations.
+type CheckQueryState struct {
+
+	// Metrics are the last metrics successfully returned by the query and
+	// Timestamp is the time at which they were returned.
+	Metrics   sdk.TimestampedMetrics
+	Timestamp time.Time
+
+	// Failures is the number of consecutive evaluations in which the query
+	// failed.
+	Failures int
 }
 
 // NewHandler returns a new handler for a policy.
@@ -84,6 +140,8 @@ func NewHandler(ID PolicyID, log hclog.Logger, pm *manager.PluginManager, ps Sou
 		doneCh:     make(chan struct{}),
 		cooldownCh: make(chan time.Duration),
 		reloadCh:   make(chan struct{}),
+		history:    make(map[string][]sdk.ScalingCheckHistoryEntry),
+		queries:    make(map[string]CheckQueryState),
 	}
 }
 
@@ -100,6 +158,7 @@ func (h *Handler) Run(ctx context.Context, evalCh chan<- *sdk.ScalingEvaluation)
 	h.runningLock.Lock()
 	h.running = true
 	h.runningLock.Unlock()
+	h.setState(HandlerStateIdle)
 
 	// Store a local copy of the policy so we can compare it for changes.
 	var currentPolicy *sdk.ScalingPolicy
@@ -161,6 +220,8 @@ func (h *Handler) Run(ctx context.Context, evalCh chan<- *sdk.ScalingEvaluation)
 		case p := <-h.ch:
 			h.applyMutators(&p)
 			h.updateHandler(currentPolicy, &p)
+			h.checkConflicts(currentPolicy, &p)
+			h.lintPolicy(currentPolicy, &p)
 			currentPolicy = &p
 
 		case <-h.ticker.C:
@@ -175,6 +236,7 @@ func (h *Handler) Run(ctx context.Context, evalCh chan<- *sdk.ScalingEvaluation)
 			}
 
 			if eval != nil {
+				h.setState(HandlerStateWaiting)
 				evalCh <- eval
 			}
 
@@ -217,6 +279,9 @@ func (h *Handler) handleTick(ctx context.Context, policy *sdk.ScalingPolicy) (*s
 	if err != nil {
 		return nil, fmt.Errorf("invalid policy: %v", err)
 	}
+	if err := h.guardrails.Check(policy); err != nil {
+		return nil, fmt.Errorf("invalid policy: %v", err)
+	}
 
 	// Timestamp the invocation of this evaluation run. This can be
 	// used when checking cooldown or emitting metrics to ensure some
@@ -229,7 +294,7 @@ func (h *Handler) handleTick(ctx context.Context, policy *sdk.ScalingPolicy) (*s
 		return nil, nil
 	}
 
-	target, err := h.pluginManager.GetTarget(policy.Target)
+	target, err := h.pluginManager.GetTarget(policy.ID, policy.Target)
 	if err != nil {
 		h.log.Warn("failed to get target", "error", err)
 		return nil, err
@@ -248,10 +313,12 @@ func (h *Handler) handleTick(ctx context.Context, policy *sdk.ScalingPolicy) (*s
 		h.Stop()
 		return nil, nil
 	}
+	h.recordTargetStatus(status)
 
 	// Exit early if the target is not ready yet.
 	if !status.Ready {
 		h.log.Trace("target is not ready")
+		IncrEvaluationSkipped(h.metricLabels(), EvaluationSkipReasonTargetNotReady)
 		return nil, nil
 	}
 
@@ -264,6 +331,9 @@ func (h *Handler) handleTick(ctx context.Context, policy *sdk.ScalingPolicy) (*s
 	if eval == nil {
 		return nil, nil
 	}
+	h.attachHistory(eval)
+	eval.HighPriority = policy.PriorityLane == sdk.ScalingPolicyPriorityHigh ||
+		h.lastScaleDirection() == sdk.ScaleDirectionUp
 
 	// If the target status includes a last event meta key, check for cooldown
 	// due to out-of-band events. This is also useful if the Autoscaler has
@@ -290,8 +360,23 @@ func (h *Handler) handleTick(ctx context.Context, policy *sdk.ScalingPolicy) (*s
 		return eval, nil
 	}
 
+	// Only enter cooldown if the policy respects out-of-band changes. Policies
+	// that revert them are evaluated right away so the worker can scale the
+	// target back.
+	switch policy.OnOutOfBandChange {
+	case sdk.ScalingPolicyOnOutOfBandChangeAlertOnly:
+		h.log.Warn("target was scaled outside of the autoscaler, ignoring cooldown",
+			"last_event", lastTS, "on_out_of_band_change", policy.OnOutOfBandChange)
+		return eval, nil
+	case sdk.ScalingPolicyOnOutOfBandChangeRevert:
+		h.log.Warn("target was scaled outside of the autoscaler, evaluating policy to revert the change",
+			"last_event", lastTS, "on_out_of_band_change", policy.OnOutOfBandChange)
+		return eval, nil
+	}
+
 	// Enforce the cooldown which will block until complete. A false response
 	// means we did not reach the end of cooldown due to a request to shutdown.
+	IncrEvaluationSkipped(h.metricLabels(), EvaluationSkipReasonCooldown)
 	if !h.enforceCooldown(ctx, cdPeriod) {
 		return nil, context.Canceled
 	}
@@ -317,13 +402,71 @@ func (h *Handler) updateHandler(current, next *sdk.ScalingPolicy) {
 	if current == nil || current.EvaluationInterval != next.EvaluationInterval {
 		h.ticker.Stop()
 
-		// Add a small random delay between 0 and 300ms to spread the first
-		// evaluation of policies that are loaded at the same time.
-		splayNs := rand.Intn(30) * 100 * 1000 * 1000
-		time.Sleep(time.Duration(splayNs))
+		// Add a small random delay to spread the first evaluation of
+		// policies that are loaded at the same time.
+		time.Sleep(splayDuration(next.EvaluationInterval))
 
 		h.ticker = time.NewTicker(next.EvaluationInterval)
 	}
+
+	// Plugins launched for the previous config overrides of the policy may
+	// no longer be needed. The ones still used are dispensed again in the
+	// next evaluation.
+	if current != nil && h.pluginManager != nil && !cmp.Equal(pluginOverrides(current), pluginOverrides(next), cmpopts.EquateEmpty()) {
+		h.pluginManager.ReleaseScoped(string(h.policyID))
+	}
+}
+
+// pluginOverrides returns the plugin config overrides of the policy target
+// and checks.
+func pluginOverrides(p *sdk.ScalingPolicy) []map[string]string {
+	var overrides []map[string]string
+	if p.Target != nil {
+		overrides = append(overrides, p.Target.PluginConfig)
+	}
+	for _, c := range p.Checks {
+		overrides = append(overrides, c.SourceConfig)
+	}
+	return overrides
+}
+
+// checkConflicts records the resource scaled by the policy and warns when
+// other enabled policies scale the same resource. Conflicting policies are
+// not stopped, but they share cooldown periods so that their scaling actions
+// are serialized.
+func (h *Handler) checkConflicts(current, next *sdk.ScalingPolicy) {
+	if h.conflicts == nil {
+		return
+	}
+
+	before := h.conflicts.conflicting(h.policyID)
+	after := h.conflicts.update(h.policyID, next)
+
+	// Only warn when the set of conflicting policies changes to avoid
+	// repeating the message on every policy update.
+	if len(after) == 0 || (current != nil && cmp.Equal(before, after)) {
+		return
+	}
+
+	ids := make([]string, len(after))
+	for i, id := range after {
+		ids[i] = string(id)
+	}
+	h.log.Warn("policy scales the same target as other enabled policies, cooldown periods will be shared between them",
+		"conflicting_policy_ids", ids, "target", next.Target.Name)
+}
+
+// lintPolicy logs the lint warnings of the policy. Warnings are only logged
+// when they change to avoid repeating them on every policy update.
+func (h *Handler) lintPolicy(current, next *sdk.ScalingPolicy) {
+	warnings := Lint(next)
+	if len(warnings) == 0 || (current != nil && cmp.Equal(Lint(current), warnings)) {
+		return
+	}
+
+	for _, w := range warnings {
+		h.log.Warn("policy lint warning", "check", w.Check, "warning", w.Message, "suggestion", w.Suggestion)
+	}
 }
 
 // enforceCooldown blocks until the cooldown period has been reached, or the
@@ -336,6 +479,13 @@ func (h *Handler) enforceCooldown(ctx context.Context, t time.Duration) (complet
 	// operators.
 	h.log.Debug("scaling policy has been placed into cooldown", "cooldown", t)
 
+	h.setState(HandlerStateCooldown)
+	defer func() {
+		if complete {
+			h.setState(HandlerStateIdle)
+		}
+	}()
+
 	// Using a timer directly is mentioned to be more efficient than
 	// time.After() as long as we ensure to call Stop(). So setup a timer for
 	// use and defer the stop.
@@ -343,18 +493,43 @@ func (h *Handler) enforceCooldown(ctx context.Context, t time.Duration) (complet
 	defer timer.Stop()
 
 	// Cooldown should not mean we miss other handler control signals. So wait
-	// on all the channels desired here.
-	select {
-	case <-timer.C:
-		complete = true
-		return
-	case <-ctx.Done():
-		return
-	case <-h.doneCh:
-		return
+	// on all the channels desired here. Ticks received during the cooldown
+	// are evaluations that won't happen, so count them as skipped.
+	for {
+		select {
+		case <-timer.C:
+			complete = true
+			return
+		case <-h.ticker.C:
+			IncrEvaluationSkipped(h.metricLabels(), EvaluationSkipReasonCooldown)
+		case <-ctx.Done():
+			return
+		case <-h.doneCh:
+			return
+		}
 	}
 }
 
+// metricLabels returns the labels used to identify the policy of the handler
+// in metrics.
+func (h *Handler) metricLabels() []metrics.Label {
+	return []metrics.Label{{Name: "policy_id", Value: string(h.policyID)}}
+}
+
+// splayDuration returns a random delay between 0 and maxSplay. The delay is
+// limited to a tenth of the evaluation interval so policies with sub-minute
+// intervals are not delayed for a significant part of it.
+func splayDuration(interval time.Duration) time.Duration {
+	limit := interval / 10
+	if limit > maxSplay {
+		limit = maxSplay
+	}
+	if limit <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(limit)))
+}
+
 // calculateRemainingCooldown calculates the remaining cooldown based on the
 // time since the last event. The remaining period can be negative, indicating
 // no cooldown period is required.
@@ -362,6 +537,92 @@ func (h *Handler) calculateRemainingCooldown(cd time.Duration, ts, lastEvent int
 	return cd - time.Duration(ts-lastEvent)
 }
 
+// attachHistory populates the history of each check evaluation that has a
+// history size configured.
+func (h *Handler) attachHistory(eval *sdk.ScalingEvaluation) {
+	h.historyLock.RLock()
+	defer h.historyLock.RUnlock()
+
+	for _, checkEval := range eval.CheckEvaluations {
+		size := checkEval.Check.HistorySize
+		if size <= 0 {
+			continue
+		}
+
+		entries := h.history[checkEval.Check.Name]
+		if len(entries) > size {
+			entries = entries[len(entries)-size:]
+		}
+
+		// Copy the entries so the eval is not affected by future records.
+		checkEval.History = make([]sdk.ScalingCheckHistoryEntry, len(entries))
+		copy(checkEval.History, entries)
+	}
+}
+
+// recordHistory stores the result of a check evaluation, keeping at most
+// size entries for the check.
+func (h *Handler) recordHistory(check string, size int, entry sdk.ScalingCheckHistoryEntry) {
+	h.historyLock.Lock()
+	defer h.historyLock.Unlock()
+
+	if size <= 0 {
+		delete(h.history, check)
+		return
+	}
+
+	entries := append(h.history[check], entry)
+	if len(entries) > size {
+		entries = entries[len(entries)-size:]
+	}
+	h.history[check] = entries
+}
+
+// recordScaleDirection stores the direction of the last scaling decision.
+func (h *Handler) recordScaleDirection(dir sdk.ScaleDirection) {
+	h.lastDirectionLock.Lock()
+	defer h.lastDirectionLock.Unlock()
+	h.lastDirection = dir
+}
+
+// lastScaleDirection returns the direction of the last scaling decision.
+func (h *Handler) lastScaleDirection() sdk.ScaleDirection {
+	h.lastDirectionLock.RLock()
+	defer h.lastDirectionLock.RUnlock()
+	return h.lastDirection
+}
+
+// queryState returns the query state of a check.
+func (h *Handler) queryState(check string) CheckQueryState {
+	h.queriesLock.RLock()
+	defer h.queriesLock.RUnlock()
+
+	return h.queries[check]
+}
+
+// recordQuery updates the query state of a check with the result of a query.
+// Failures are accumulated while successful queries reset the failure count
+// and, if any metrics were returned, replace the stored metrics.
+func (h *Handler) recordQuery(check string, metrics sdk.TimestampedMetrics, err error) {
+	h.queriesLock.Lock()
+	defer h.queriesLock.Unlock()
+
+	state := h.queries[check]
+	if err != nil {
+		state.Failures++
+	} else {
+		state.Failures = 0
+		if len(metrics) > 0 {
+			// Copy the metrics so the state is not affected by changes to
+			// the evaluation.
+			state.Metrics = make(sdk.TimestampedMetrics, len(metrics))
+			copy(state.Metrics, metrics)
+			state.Timestamp = time.Now().UTC()
+		}
+	}
+	h.queries[check] = state
+}
+
 // applyMutators applies the mutators registered with the handler in order and
 // log any modification that was performed.
 func (h *Handler) applyMutators(p *sdk.ScalingPolicy) {
@@ -0,0 +1,204 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package policy
+
+import (
+	"sort"
+	"time"
+
+	metrics "github.com/armon/go-metrics"
+	"github.com/hashicorp/nomad-autoscaler/sdk"
+)
+
+// HandlerState is the state of a policy handler.
+type HandlerState string
+
+const (
+	// HandlerStateIdle indicates the handler is waiting for the next
+	// evaluation of the policy.
+	HandlerStateIdle HandlerState = "idle"
+
+	// HandlerStateWaiting indicates the handler sent the policy for
+	// evaluation and is waiting for a worker to evaluate it.
+	HandlerStateWaiting HandlerState = "waiting"
+
+	// HandlerStateScaling indicates a worker is scaling the policy target.
+	HandlerStateScaling HandlerState = "scaling"
+
+	// HandlerStateCooldown indicates the policy is in cooldown and won't be
+	// evaluated until it's over.
+	HandlerStateCooldown HandlerState = "cooldown"
+)
+
+// handlerStates are all the states a handler can be in.
+var handlerStates = []HandlerState{
+	HandlerStateIdle,
+	HandlerStateWaiting,
+	HandlerStateScaling,
+	HandlerStateCooldown,
+}
+
+// HandlerStatus describes the state of a policy handler.
+type HandlerStatus struct {
+	PolicyID string
+	State    HandlerState
+
+	// Since is the time the handler entered its current state.
+	Since time.Time
+
+	// TargetMeta holds the standard sdk.TargetStatusMetaKeys reported by the
+	// target the last time its status was read, giving operators the context
+	// of the remote provider.
+	TargetMeta map[string]string
+
+	// LastCheck is the name of the check that drove the last scaling
+	// decision of the policy. It is empty if no check requested an action.
+	LastCheck string
+
+	// LastExplanation is the data attached by the strategy of LastCheck to
+	// explain how the action was calculated, if any.
+	LastExplanation *sdk.ScalingActionExplanation
+}
+
+// setState moves the handler to the given state. If any from states are
+// passed, the handler is only moved if it's in one of them. It returns true
+// if the handler moved to the new state.
+func (h *Handler) setState(to HandlerState, from ...HandlerState) bool {
+	h.stateLock.Lock()
+	defer h.stateLock.Unlock()
+
+	if h.state == to {
+		return false
+	}
+
+	if len(from) > 0 {
+		allowed := false
+		for _, s := range from {
+			if h.state == s {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return false
+		}
+	}
+
+	h.log.Trace("handler state changed", "from", h.state, "to", to)
+
+	// The initial state is not a transition.
+	if h.state != "" {
+		labels := []metrics.Label{
+			{Name: "policy_id", Value: string(h.policyID)},
+			{Name: "from", Value: string(h.state)},
+			{Name: "to", Value: string(to)},
+		}
+		metrics.IncrCounterWithLabels([]string{"policy", "handler", "transition"}, 1, labels)
+	}
+
+	h.state = to
+	h.stateSince = time.Now()
+	return true
+}
+
+// status returns the current state of the handler.
+func (h *Handler) status() HandlerStatus {
+	h.stateLock.RLock()
+	defer h.stateLock.RUnlock()
+
+	return HandlerStatus{
+		PolicyID:        string(h.policyID),
+		State:           h.state,
+		Since:           h.stateSince,
+		TargetMeta:      h.targetMeta,
+		LastCheck:       h.lastCheck,
+		LastExplanation: h.lastExplanation,
+	}
+}
+
+// recordTargetStatus stores the standard meta of the last status read from
+// the policy target.
+func (h *Handler) recordTargetStatus(status *sdk.TargetStatus) {
+	h.stateLock.Lock()
+	defer h.stateLock.Unlock()
+	h.targetMeta = sdk.StandardTargetStatusMeta(status.Meta)
+}
+
+// recordDecision stores the check and explanation of the last scaling
+// decision of the policy.
+func (h *Handler) recordDecision(action *sdk.ScalingAction) {
+	h.stateLock.Lock()
+	defer h.stateLock.Unlock()
+	h.lastCheck, _ = action.CheckDetails()
+	h.lastExplanation = action.Explanation()
+}
+
+// HandlerStatuses returns the state of all the policy handlers, sorted by
+// policy ID.
+func (m *Manager) HandlerStatuses() []HandlerStatus {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	out := make([]HandlerStatus, 0, len(m.handlers))
+	for _, h := range m.handlers {
+		out = append(out, h.status())
+	}
+
+	sort.Slice(out, func(i, j int) bool { return out[i].PolicyID < out[j].PolicyID })
+	return out
+}
+
+// RecordHandlerScaling marks the policy handler representing the passed ID
+// as scaling its target.
+func (m *Manager) RecordHandlerScaling(id string) {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	if handler, ok := m.handlers[PolicyID(id)]; ok {
+		handler.setState(HandlerStateScaling)
+	} else {
+		m.log.Debug("attempted to record handler state on non-existent handler", "policy_id", id)
+	}
+}
+
+// RecordScalingDecision stores the check and strategy explanation of the
+// action selected by the last evaluation of the policy handler representing
+// the passed ID, so they can be reported in its status.
+func (m *Manager) RecordScalingDecision(id string, action *sdk.ScalingAction) {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	if handler, ok := m.handlers[PolicyID(id)]; ok {
+		handler.recordDecision(action)
+	} else {
+		m.log.Debug("attempted to record scaling decision on non-existent handler", "policy_id", id)
+	}
+}
+
+// RecordEvaluationDone marks the evaluation of the policy handler
+// representing the passed ID as complete. The handler is moved back to idle,
+// unless the evaluation placed it into cooldown.
+func (m *Manager) RecordEvaluationDone(id string) {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	if handler, ok := m.handlers[PolicyID(id)]; ok {
+		handler.setState(HandlerStateIdle, HandlerStateWaiting, HandlerStateScaling)
+	} else {
+		m.log.Debug("attempted to record handler state on non-existent handler", "policy_id", id)
+	}
+}
+
+// emitHandlerStateMetrics emits the number of handlers in each state.
+func (m *Manager) emitHandlerStateMetrics() {
+	counts := make(map[HandlerState]int, len(handlerStates))
+	for _, s := range m.HandlerStatuses() {
+		counts[s.State]++
+	}
+
+	for _, state := range handlerStates {
+		labels := []metrics.Label{{Name: "state", Value: string(state)}}
+		metrics.SetGaugeWithLabels([]string{"policy", "handler", "state"}, float32(counts[state]), labels)
+	}
+}
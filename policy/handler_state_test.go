@@ -0,0 +1,100 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package policy
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/nomad-autoscaler/sdk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandler_setState(t *testing.T) {
+	h := NewHandler("policy", hclog.NewNullLogger(), nil, nil)
+
+	assert.True(t, h.setState(HandlerStateIdle))
+	assert.False(t, h.setState(HandlerStateIdle))
+
+	status := h.status()
+	assert.Equal(t, "policy", status.PolicyID)
+	assert.Equal(t, HandlerStateIdle, status.State)
+	assert.False(t, status.Since.IsZero())
+
+	assert.True(t, h.setState(HandlerStateWaiting))
+	assert.True(t, h.setState(HandlerStateCooldown))
+
+	// The handler is only moved if it's in one of the from states.
+	assert.False(t, h.setState(HandlerStateIdle, HandlerStateWaiting, HandlerStateScaling))
+	assert.Equal(t, HandlerStateCooldown, h.status().State)
+
+	assert.True(t, h.setState(HandlerStateIdle, HandlerStateCooldown))
+	assert.Equal(t, HandlerStateIdle, h.status().State)
+}
+
+func TestHandler_recordTargetStatus(t *testing.T) {
+	h := NewHandler("policy", hclog.NewNullLogger(), nil, nil)
+	assert.Nil(t, h.status().TargetMeta)
+
+	h.recordTargetStatus(&sdk.TargetStatus{
+		Meta: map[string]string{
+			"nomad_autoscaler.target.nomad.example.stopped": "false",
+			sdk.TargetStatusMetaKeyUnhealthy:                "2",
+		},
+	})
+	assert.Equal(t, map[string]string{sdk.TargetStatusMetaKeyUnhealthy: "2"}, h.status().TargetMeta)
+}
+
+func TestManager_RecordScalingDecision(t *testing.T) {
+	m := NewManager(hclog.NewNullLogger(), nil, nil, time.Second, nil, nil)
+	h := NewHandler("policy", hclog.NewNullLogger(), nil, nil)
+	m.handlers[h.policyID] = h
+
+	action := &sdk.ScalingAction{}
+	action.SetCheckDetails("cpu", "target-value", nil)
+	action.SetExplanation(sdk.ScalingActionExplanation{Ratio: 2, Samples: 1})
+	m.RecordScalingDecision("policy", action)
+
+	status := h.status()
+	assert.Equal(t, "cpu", status.LastCheck)
+	assert.Equal(t, &sdk.ScalingActionExplanation{Ratio: 2, Samples: 1}, status.LastExplanation)
+
+	// Decisions not driven by a check clear the previous explanation.
+	m.RecordScalingDecision("policy", &sdk.ScalingAction{Direction: sdk.ScaleDirectionNone})
+	status = h.status()
+	assert.Empty(t, status.LastCheck)
+	assert.Nil(t, status.LastExplanation)
+
+	// Unknown policies are ignored.
+	m.RecordScalingDecision("unknown", action)
+}
+
+func TestManager_HandlerStatuses(t *testing.T) {
+	m := NewManager(hclog.NewNullLogger(), nil, nil, time.Second, nil, nil)
+
+	h1 := NewHandler("policy-b", hclog.NewNullLogger(), nil, nil)
+	h2 := NewHandler("policy-a", hclog.NewNullLogger(), nil, nil)
+	h1.setState(HandlerStateWaiting)
+	h2.setState(HandlerStateWaiting)
+	m.handlers[h1.policyID] = h1
+	m.handlers[h2.policyID] = h2
+
+	m.RecordHandlerScaling("policy-b")
+	m.RecordEvaluationDone("policy-a")
+
+	out := m.HandlerStatuses()
+	require.Len(t, out, 2)
+	assert.Equal(t, "policy-a", out[0].PolicyID)
+	assert.Equal(t, HandlerStateIdle, out[0].State)
+	assert.Equal(t, "policy-b", out[1].PolicyID)
+	assert.Equal(t, HandlerStateScaling, out[1].State)
+
+	// Evaluations that place the handler into cooldown don't move it back
+	// to idle.
+	h1.setState(HandlerStateCooldown)
+	m.RecordEvaluationDone("policy-b")
+	assert.Equal(t, HandlerStateCooldown, h1.status().State)
+}
@@ -4,10 +4,12 @@
 package policy
 
 import (
+	"errors"
 	"testing"
 	"time"
 
 	hclog "github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/nomad-autoscaler/sdk"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -47,3 +49,100 @@ func TestHandler_calculateRemainingCooldown(t *testing.T) {
 		})
 	}
 }
+
+func Test_splayDuration(t *testing.T) {
+	testCases := []struct {
+		name     string
+		interval time.Duration
+		max      time.Duration
+	}{
+		{
+			name:     "long interval",
+			interval: 5 * time.Minute,
+			max:      maxSplay,
+		},
+		{
+			name:     "sub-minute interval",
+			interval: 10 * time.Second,
+			max:      time.Second,
+		},
+		{
+			name:     "zero interval",
+			interval: 0,
+			max:      0,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			for i := 0; i < 100; i++ {
+				splay := splayDuration(tc.interval)
+				assert.GreaterOrEqual(t, splay, time.Duration(0))
+				if tc.max == 0 {
+					assert.Zero(t, splay)
+				} else {
+					assert.Less(t, splay, tc.max)
+				}
+			}
+		})
+	}
+}
+
+func TestHandler_history(t *testing.T) {
+	h := NewHandler("", hclog.NewNullLogger(), nil, nil)
+
+	// Record more entries than the history size allows.
+	for i := int64(1); i <= 5; i++ {
+		h.recordHistory("check", 3, sdk.ScalingCheckHistoryEntry{Count: i})
+	}
+	h.recordHistory("no-history", 0, sdk.ScalingCheckHistoryEntry{Count: 1})
+
+	policy := &sdk.ScalingPolicy{
+		ID: "id",
+		Checks: []*sdk.ScalingPolicyCheck{
+			{Name: "check", HistorySize: 2},
+			{Name: "no-history"},
+		},
+	}
+	eval := sdk.NewScalingEvaluation(policy)
+	h.attachHistory(eval)
+
+	assert.Equal(t, []sdk.ScalingCheckHistoryEntry{{Count: 4}, {Count: 5}}, eval.CheckEvaluations[0].History)
+	assert.Nil(t, eval.CheckEvaluations[1].History)
+	assert.NotContains(t, h.history, "no-history")
+
+	// New records must not modify the history of existing evals.
+	h.recordHistory("check", 3, sdk.ScalingCheckHistoryEntry{Count: 6})
+	assert.Equal(t, []sdk.ScalingCheckHistoryEntry{{Count: 4}, {Count: 5}}, eval.CheckEvaluations[0].History)
+	assert.Len(t, h.history["check"], 3)
+}
+
+func TestHandler_queryState(t *testing.T) {
+	h := NewHandler("", hclog.NewNullLogger(), nil, nil)
+	metrics := sdk.TimestampedMetrics{{Value: 1}}
+
+	assert.Equal(t, CheckQueryState{}, h.queryState("check"))
+
+	h.recordQuery("check", nil, errors.New("error"))
+	h.recordQuery("check", nil, errors.New("error"))
+	assert.Equal(t, 2, h.queryState("check").Failures)
+	assert.Nil(t, h.queryState("check").Metrics)
+
+	// A successful query resets the failures and stores a copy of the
+	// metrics.
+	h.recordQuery("check", metrics, nil)
+	metrics[0].Value = 2
+
+	state := h.queryState("check")
+	assert.Zero(t, state.Failures)
+	assert.Equal(t, sdk.TimestampedMetrics{{Value: 1}}, state.Metrics)
+	assert.False(t, state.Timestamp.IsZero())
+
+	// Failures and empty results keep the last known metrics.
+	h.recordQuery("check", sdk.TimestampedMetrics{}, nil)
+	h.recordQuery("check", nil, errors.New("error"))
+
+	state = h.queryState("check")
+	assert.Equal(t, 1, state.Failures)
+	assert.Equal(t, sdk.TimestampedMetrics{{Value: 1}}, state.Metrics)
+}
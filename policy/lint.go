@@ -0,0 +1,138 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package policy
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/hashicorp/nomad-autoscaler/sdk"
+)
+
+// metricResolutions are the default resolution of the metrics returned by
+// the APM plugins, keyed by plugin name. Query windows shorter than the
+// resolution may not include any data point.
+var metricResolutions = map[string]time.Duration{
+	// Prometheus scrapes targets every minute by default.
+	"prometheus": time.Minute,
+
+	// The Datadog agent flushes metrics every 15 seconds.
+	"datadog": 15 * time.Second,
+}
+
+// LintWarning describes a policy configuration that is valid but likely to
+// cause unexpected scaling behaviour.
+type LintWarning struct {
+	// Check is the name of the check the warning refers to. It's empty if
+	// the warning refers to the policy.
+	Check string
+
+	Message    string
+	Suggestion string
+}
+
+func (w LintWarning) String() string {
+	if w.Check == "" {
+		return fmt.Sprintf("%s; %s", w.Message, w.Suggestion)
+	}
+	return fmt.Sprintf("check %s: %s; %s", w.Check, w.Message, w.Suggestion)
+}
+
+// Lint returns the warnings for a valid policy. Unlike validation errors,
+// warnings don't prevent the policy from being evaluated.
+func Lint(p *sdk.ScalingPolicy) []LintWarning {
+	var warnings []LintWarning
+
+	if p.Cooldown > 0 && p.Cooldown < p.EvaluationInterval {
+		warnings = append(warnings, LintWarning{
+			Message: fmt.Sprintf("cooldown %s is shorter than the evaluation interval %s",
+				p.Cooldown, p.EvaluationInterval),
+			Suggestion: "the policy is not evaluated during the interval anyway, so set cooldown to at least the evaluation_interval",
+		})
+	}
+
+	if p.EvaluationTimeout > 0 && p.EvaluationTimeout > p.EvaluationInterval {
+		warnings = append(warnings, LintWarning{
+			Message: fmt.Sprintf("evaluation timeout %s is longer than the evaluation interval %s",
+				p.EvaluationTimeout, p.EvaluationInterval),
+			Suggestion: "evaluations that take longer than the interval delay the next ones, so set evaluation_timeout to at most the evaluation_interval",
+		})
+	}
+
+	if p.Min == p.Max && len(p.Checks) > 0 {
+		warnings = append(warnings, LintWarning{
+			Message:    fmt.Sprintf("min and max are both %d, so the checks can never change the count", p.Min),
+			Suggestion: "increase max or remove the checks",
+		})
+	}
+
+	for _, c := range p.Checks {
+		if c.QueryWindow == 0 {
+			continue
+		}
+		if res, ok := metricResolutions[c.Source]; ok && c.QueryWindow < res {
+			warnings = append(warnings, LintWarning{
+				Check: c.Name,
+				Message: fmt.Sprintf("query window %s is smaller than the %s metric resolution %s",
+					c.QueryWindow, c.Source, res),
+				Suggestion: fmt.Sprintf("set query_window to at least %s so queries return data points", res),
+			})
+		}
+	}
+
+	return append(warnings, lintThresholdChecks(p.Checks)...)
+}
+
+// lintThresholdChecks returns warnings for threshold checks that query the
+// same metric and have overlapping bounds, since a single metric value would
+// trigger all of them.
+func lintThresholdChecks(checks []*sdk.ScalingPolicyCheck) []LintWarning {
+	type bounds struct {
+		check        *sdk.ScalingPolicyCheck
+		lower, upper float64
+	}
+
+	var thresholds []bounds
+	for _, c := range checks {
+		if c.Strategy == nil || c.Strategy.Name != "threshold" {
+			continue
+		}
+
+		lower, lowerOK := parseThresholdBound(c.Strategy.Config["lower_bound"], math.Inf(-1))
+		upper, upperOK := parseThresholdBound(c.Strategy.Config["upper_bound"], math.Inf(1))
+		if !lowerOK || !upperOK {
+			continue
+		}
+		thresholds = append(thresholds, bounds{check: c, lower: lower, upper: upper})
+	}
+
+	var warnings []LintWarning
+	for i, a := range thresholds {
+		for _, b := range thresholds[i+1:] {
+			if a.check.Source != b.check.Source || a.check.Query != b.check.Query {
+				continue
+			}
+			if a.lower < b.upper && b.lower < a.upper {
+				warnings = append(warnings, LintWarning{
+					Check:      a.check.Name,
+					Message:    fmt.Sprintf("threshold bounds overlap with check %s on the same query", b.check.Name),
+					Suggestion: "adjust lower_bound and upper_bound so each metric value triggers a single check",
+				})
+			}
+		}
+	}
+	return warnings
+}
+
+// parseThresholdBound parses a threshold strategy bound, returning def if the
+// bound is not set. The boolean return is false if the bound is invalid.
+func parseThresholdBound(s string, def float64) (float64, bool) {
+	if s == "" {
+		return def, true
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	return v, err == nil
+}
@@ -0,0 +1,134 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package policy
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hashicorp/nomad-autoscaler/sdk"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLint(t *testing.T) {
+	thresholdCheck := func(name, query, lower, upper string) *sdk.ScalingPolicyCheck {
+		config := map[string]string{}
+		if lower != "" {
+			config["lower_bound"] = lower
+		}
+		if upper != "" {
+			config["upper_bound"] = upper
+		}
+		return &sdk.ScalingPolicyCheck{
+			Name:     name,
+			Source:   "prometheus",
+			Query:    query,
+			Strategy: &sdk.ScalingPolicyStrategy{Name: "threshold", Config: config},
+		}
+	}
+
+	testCases := []struct {
+		name     string
+		policy   *sdk.ScalingPolicy
+		expected []LintWarning
+	}{
+		{
+			name: "no warnings",
+			policy: &sdk.ScalingPolicy{
+				Min:                1,
+				Max:                10,
+				Cooldown:           5 * time.Minute,
+				EvaluationInterval: time.Minute,
+				Checks: []*sdk.ScalingPolicyCheck{
+					thresholdCheck("high", "q", "80", ""),
+					thresholdCheck("low", "q", "", "20"),
+				},
+			},
+		},
+		{
+			name: "cooldown shorter than evaluation interval",
+			policy: &sdk.ScalingPolicy{
+				Min:                1,
+				Max:                10,
+				Cooldown:           10 * time.Second,
+				EvaluationInterval: time.Minute,
+			},
+			expected: []LintWarning{{
+				Message:    "cooldown 10s is shorter than the evaluation interval 1m0s",
+				Suggestion: "the policy is not evaluated during the interval anyway, so set cooldown to at least the evaluation_interval",
+			}},
+		},
+		{
+			name: "evaluation timeout longer than evaluation interval",
+			policy: &sdk.ScalingPolicy{
+				Min:                1,
+				Max:                10,
+				EvaluationInterval: 10 * time.Second,
+				EvaluationTimeout:  30 * time.Second,
+			},
+			expected: []LintWarning{{
+				Message:    "evaluation timeout 30s is longer than the evaluation interval 10s",
+				Suggestion: "evaluations that take longer than the interval delay the next ones, so set evaluation_timeout to at most the evaluation_interval",
+			}},
+		},
+		{
+			name: "min equal to max with checks",
+			policy: &sdk.ScalingPolicy{
+				Min:    3,
+				Max:    3,
+				Checks: []*sdk.ScalingPolicyCheck{{Name: "cpu"}},
+			},
+			expected: []LintWarning{{
+				Message:    "min and max are both 3, so the checks can never change the count",
+				Suggestion: "increase max or remove the checks",
+			}},
+		},
+		{
+			name: "min equal to max without checks",
+			policy: &sdk.ScalingPolicy{
+				Min: 3,
+				Max: 3,
+			},
+		},
+		{
+			name: "query window smaller than metric resolution",
+			policy: &sdk.ScalingPolicy{
+				Min: 1,
+				Max: 10,
+				Checks: []*sdk.ScalingPolicyCheck{
+					{Name: "cpu", Source: "prometheus", QueryWindow: 30 * time.Second},
+					{Name: "mem", Source: "nomad-apm", QueryWindow: 30 * time.Second},
+				},
+			},
+			expected: []LintWarning{{
+				Check:      "cpu",
+				Message:    "query window 30s is smaller than the prometheus metric resolution 1m0s",
+				Suggestion: "set query_window to at least 1m0s so queries return data points",
+			}},
+		},
+		{
+			name: "threshold bounds overlap",
+			policy: &sdk.ScalingPolicy{
+				Min: 1,
+				Max: 10,
+				Checks: []*sdk.ScalingPolicyCheck{
+					thresholdCheck("high", "q", "60", ""),
+					thresholdCheck("low", "q", "", "70"),
+					thresholdCheck("other", "other_q", "", "70"),
+				},
+			},
+			expected: []LintWarning{{
+				Check:      "high",
+				Message:    "threshold bounds overlap with check low on the same query",
+				Suggestion: "adjust lower_bound and upper_bound so each metric value triggers a single check",
+			}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, Lint(tc.policy))
+		})
+	}
+}
@@ -5,6 +5,8 @@ package policy
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"strings"
 	"sync"
 	"time"
@@ -15,6 +17,10 @@ import (
 	"github.com/hashicorp/nomad-autoscaler/sdk"
 )
 
+// ErrSourceNotFound is returned when reloading a policy source which is not
+// configured in the agent.
+var ErrSourceNotFound = errors.New("policy source not found")
+
 // Manager tracks policies and controls the lifecycle of each policy handler.
 type Manager struct {
 	log           hclog.Logger
@@ -30,6 +36,21 @@ type Manager struct {
 	// keep is used to mark active policies during reconciliation.
 	keep map[PolicyID]bool
 
+	// mutators are applied to all policies, after the default mutators of
+	// the handlers.
+	mutators []Mutator
+
+	// guardrails are the limits enforced on all policies.
+	guardrails *Guardrails
+
+	// conflicts tracks policies that scale the same resource so they can
+	// share cooldown periods instead of fighting each other.
+	conflicts *conflictTracker
+
+	// actions limits the number of scaling actions each target receives,
+	// as configured by the guardrails.
+	actions *actionLimiter
+
 	// metricsInterval is the interval at which the agent is configured to emit
 	// metrics. This is used when creating the periodicMetricsReporter.
 	metricsInterval time.Duration
@@ -43,8 +64,9 @@ type Manager struct {
 	policyIDsErrCh chan error
 }
 
-// NewManager returns a new Manager.
-func NewManager(log hclog.Logger, ps map[SourceName]Source, pm *manager.PluginManager, mInt time.Duration) *Manager {
+// NewManager returns a new Manager. The mutators are applied to all policies
+// in the given order, followed by the guardrails.
+func NewManager(log hclog.Logger, ps map[SourceName]Source, pm *manager.PluginManager, mInt time.Duration, mutators []Mutator, g *Guardrails) *Manager {
 
 	return &Manager{
 		log:             log.ResetNamed("policy_manager"),
@@ -52,6 +74,10 @@ func NewManager(log hclog.Logger, ps map[SourceName]Source, pm *manager.PluginMa
 		pluginManager:   pm,
 		handlers:        make(map[PolicyID]*Handler),
 		keep:            make(map[PolicyID]bool),
+		mutators:        mutators,
+		guardrails:      g,
+		conflicts:       newConflictTracker(),
+		actions:         g.newActionLimiter(),
 		metricsInterval: mInt,
 		policyIDsCh:     make(chan IDMessage, 2),
 		policyIDsErrCh:  make(chan error, 2),
@@ -150,6 +176,12 @@ func (m *Manager) monitorPolicies(ctx context.Context, evalCh chan<- *sdk.Scalin
 					"policy_id", policyID, "policy_source", policyIDs.Source)
 
 				h := NewHandler(policyID, m.log, m.pluginManager, m.policySource[policyIDs.Source])
+				h.conflicts = m.conflicts
+				h.mutators = append(h.mutators, m.mutators...)
+				if m.guardrails != nil {
+					h.mutators = append(h.mutators, m.guardrails)
+					h.guardrails = m.guardrails
+				}
 				m.handlers[policyID] = h
 
 				go func(ID PolicyID) {
@@ -158,7 +190,13 @@ func (m *Manager) monitorPolicies(ctx context.Context, evalCh chan<- *sdk.Scalin
 					// Remove the handler when it stops running.
 					m.lock.Lock()
 					delete(m.handlers, ID)
+					m.conflicts.remove(ID)
 					m.lock.Unlock()
+
+					// Stop the plugins launched only for this policy.
+					if m.pluginManager != nil {
+						m.pluginManager.ReleaseScoped(string(ID))
+					}
 				}(policyID)
 			}
 
@@ -196,6 +234,7 @@ func (m *Manager) stopHandler(h *Handler) {
 
 	h.Stop()
 	delete(m.handlers, h.policyID)
+	m.conflicts.remove(h.policyID)
 }
 
 // EnforceCooldown attempts to enforce cooldown on the policy handler
@@ -217,6 +256,98 @@ func (m *Manager) EnforceCooldown(id string, t time.Duration) {
 	} else {
 		m.log.Debug("attempted to set cooldown on non-existent handler", "policy_id", id)
 	}
+
+	// Policies that scale the same resource share the cooldown so they are
+	// serialized instead of fighting each other. Handlers that are busy, or
+	// already in cooldown, are skipped so that the caller is not blocked.
+	for _, otherID := range m.conflicts.conflicting(PolicyID(id)) {
+		handler, ok := m.handlers[otherID]
+		if !ok || handler.cooldownCh == nil {
+			continue
+		}
+
+		select {
+		case handler.cooldownCh <- t:
+			m.log.Debug("enforced shared cooldown on conflicting policy",
+				"policy_id", otherID, "source_policy_id", id)
+		default:
+			m.log.Debug("unable to enforce shared cooldown on busy handler",
+				"policy_id", otherID, "source_policy_id", id)
+		}
+	}
+}
+
+// RecordCheckHistory stores the result of a check evaluation on the policy
+// handler representing the passed ID, so it can be passed to the strategy in
+// future evaluations of the check.
+func (m *Manager) RecordCheckHistory(id, check string, size int, entry sdk.ScalingCheckHistoryEntry) {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	if handler, ok := m.handlers[PolicyID(id)]; ok {
+		handler.recordHistory(check, size, entry)
+	} else {
+		m.log.Debug("attempted to record check history on non-existent handler", "policy_id", id)
+	}
+}
+
+// RecordScaleDirection stores the direction of the last scaling decision of
+// the policy handler representing the passed ID. The next evaluations of
+// policies whose last decision was to scale up are placed in the priority
+// lane.
+func (m *Manager) RecordScaleDirection(id string, dir sdk.ScaleDirection) {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	if handler, ok := m.handlers[PolicyID(id)]; ok {
+		handler.recordScaleDirection(dir)
+	} else {
+		m.log.Debug("attempted to record scale direction on non-existent handler", "policy_id", id)
+	}
+}
+
+// CheckQueryState returns the query state of a check of the policy handler
+// representing the passed ID.
+func (m *Manager) CheckQueryState(id, check string) CheckQueryState {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	if handler, ok := m.handlers[PolicyID(id)]; ok {
+		return handler.queryState(check)
+	}
+	return CheckQueryState{}
+}
+
+// RecordCheckQuery stores the result of a check query on the policy handler
+// representing the passed ID, so the metrics can be used as a fallback and
+// failures can be tracked across evaluations.
+func (m *Manager) RecordCheckQuery(id, check string, metrics sdk.TimestampedMetrics, err error) {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	if handler, ok := m.handlers[PolicyID(id)]; ok {
+		handler.recordQuery(check, metrics, err)
+	} else {
+		m.log.Debug("attempted to record check query on non-existent handler", "policy_id", id)
+	}
+}
+
+// Guardrails returns the limits enforced on all policies. It may be nil.
+func (m *Manager) Guardrails() *Guardrails {
+	return m.guardrails
+}
+
+// AllowScalingAction consumes a scaling action from the budget of the target
+// of the policy. If the budget is exhausted, it returns false along with the
+// time until the next action is allowed. Policies that scale the same
+// resource share the budget, and policies scaling multiple resources consume
+// from the budget of each of them.
+func (m *Manager) AllowScalingAction(p *sdk.ScalingPolicy) (bool, time.Duration) {
+	keys := conflictKeys(p)
+	if len(keys) == 0 {
+		keys = []string{"policy/" + p.ID}
+	}
+	return m.actions.take(keys...)
 }
 
 // ReloadSources triggers a reload of all the policy sources.
@@ -235,6 +366,27 @@ func (m *Manager) ReloadSources() {
 	}
 }
 
+// ReloadSource triggers a reload of the named policy source and of the
+// handlers of its policies. It returns ErrSourceNotFound if the source is not
+// configured.
+func (m *Manager) ReloadSource(name SourceName) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	policySource, ok := m.policySource[name]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrSourceNotFound, name)
+	}
+	policySource.ReloadIDsMonitor()
+
+	for _, h := range m.handlers {
+		if h.policySource == policySource {
+			h.reloadCh <- struct{}{}
+		}
+	}
+	return nil
+}
+
 // periodicMetricsReporter periodically emits metrics for the policy manager
 // which cannot be performed during inline function calls.
 func (m *Manager) periodicMetricsReporter(ctx context.Context, interval time.Duration) {
@@ -250,6 +402,8 @@ func (m *Manager) periodicMetricsReporter(ctx context.Context, interval time.Dur
 			num := len(m.handlers)
 			m.lock.RUnlock()
 			metrics.SetGauge([]string{"policy", "total_num"}, float32(num))
+			metrics.SetGauge([]string{"policy", "conflict_num"}, float32(m.conflicts.count()))
+			m.emitHandlerStateMetrics()
 		}
 	}
 }
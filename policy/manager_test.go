@@ -0,0 +1,57 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package policy
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// reloadCountSource is a policy source which only counts the reloads of its
+// ID monitor.
+type reloadCountSource struct {
+	name    SourceName
+	reloads int
+}
+
+func (s *reloadCountSource) MonitorIDs(_ context.Context, _ MonitorIDsReq)       {}
+func (s *reloadCountSource) MonitorPolicy(_ context.Context, _ MonitorPolicyReq) {}
+func (s *reloadCountSource) Name() SourceName                                    { return s.name }
+func (s *reloadCountSource) ReloadIDsMonitor()                                   { s.reloads++ }
+
+func TestManager_ReloadSource(t *testing.T) {
+	nomadSource := &reloadCountSource{name: SourceNameNomad}
+	fileSource := &reloadCountSource{name: SourceNameFile}
+
+	m := NewManager(hclog.NewNullLogger(), map[SourceName]Source{
+		SourceNameNomad: nomadSource,
+		SourceNameFile:  fileSource,
+	}, nil, time.Second, nil, nil)
+
+	nomadHandler := NewHandler("nomad-policy", hclog.NewNullLogger(), nil, nomadSource)
+	fileHandler := NewHandler("file-policy", hclog.NewNullLogger(), nil, fileSource)
+	m.handlers[nomadHandler.policyID] = nomadHandler
+	m.handlers[fileHandler.policyID] = fileHandler
+
+	// Only the handlers of the reloaded source must be notified.
+	reloadedCh := make(chan struct{})
+	go func() {
+		<-nomadHandler.reloadCh
+		close(reloadedCh)
+	}()
+
+	require.NoError(t, m.ReloadSource(SourceNameNomad))
+	<-reloadedCh
+
+	assert.Equal(t, 1, nomadSource.reloads)
+	assert.Equal(t, 0, fileSource.reloads)
+
+	err := m.ReloadSource("invalid")
+	assert.ErrorIs(t, err, ErrSourceNotFound)
+}
@@ -4,9 +4,27 @@
 package policy
 
 import (
+	"fmt"
+	"sort"
+	"strconv"
+
 	"github.com/hashicorp/nomad-autoscaler/sdk"
 )
 
+const (
+	// MutatorTypeTargetConfig is the mutator type that sets default target
+	// config keys.
+	MutatorTypeTargetConfig = "target_config"
+
+	// MutatorTypeMax is the mutator type that enforces an upper limit on the
+	// policy max value.
+	MutatorTypeMax = "max"
+
+	// MutatorTypeAPMSource is the mutator type that rewrites the APM source
+	// of the policy checks.
+	MutatorTypeAPMSource = "apm_source"
+)
+
 // Mutations is a list of human-friendly descriptions of the changes performed
 // by a mutator.
 type Mutations []string
@@ -37,3 +55,107 @@ func (m NomadAPMMutator) MutatePolicy(p *sdk.ScalingPolicy) Mutations {
 
 	return result
 }
+
+// NewMutator returns the mutator for the given type and config, as defined in
+// the agent policy configuration.
+func NewMutator(mutatorType string, config map[string]string) (Mutator, error) {
+	switch mutatorType {
+	case MutatorTypeTargetConfig:
+		return TargetConfigMutator{Config: config}, nil
+
+	case MutatorTypeMax:
+		max, err := strconv.ParseInt(config["max"], 10, 64)
+		if err != nil || max < 0 {
+			return nil, fmt.Errorf("invalid max value %q for mutator %q", config["max"], mutatorType)
+		}
+		return MaxMutator{Max: max, PolicyType: config["policy_type"]}, nil
+
+	case MutatorTypeAPMSource:
+		return APMSourceMutator{Sources: config}, nil
+
+	default:
+		return nil, fmt.Errorf("invalid mutator %q", mutatorType)
+	}
+}
+
+// TargetConfigMutator sets target config keys that are not defined in the
+// policy, allowing operators to inject default values.
+type TargetConfigMutator struct {
+	Config map[string]string
+}
+
+func (m TargetConfigMutator) MutatePolicy(p *sdk.ScalingPolicy) Mutations {
+	result := Mutations{}
+
+	if p.Target == nil {
+		return result
+	}
+	if p.Target.Config == nil {
+		p.Target.Config = make(map[string]string, len(m.Config))
+	}
+
+	// Sort the keys so mutations are reported in a consistent order.
+	keys := make([]string, 0, len(m.Config))
+	for k := range m.Config {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		if _, ok := p.Target.Config[k]; ok {
+			continue
+		}
+		p.Target.Config[k] = m.Config[k]
+		result = append(result, fmt.Sprintf("target config %q set to %q", k, m.Config[k]))
+	}
+
+	return result
+}
+
+// MaxMutator limits the min and max values of policies to Max. If PolicyType
+// is set, only policies of that type are modified.
+type MaxMutator struct {
+	Max        int64
+	PolicyType string
+}
+
+func (m MaxMutator) MutatePolicy(p *sdk.ScalingPolicy) Mutations {
+	result := Mutations{}
+
+	if m.PolicyType != "" && p.Type != m.PolicyType {
+		return result
+	}
+
+	if p.Max > m.Max {
+		result = append(result, fmt.Sprintf("max value reduced from %d to %d", p.Max, m.Max))
+		p.Max = m.Max
+	}
+	if p.Min > m.Max {
+		result = append(result, fmt.Sprintf("min value reduced from %d to %d", p.Min, m.Max))
+		p.Min = m.Max
+	}
+
+	return result
+}
+
+// APMSourceMutator rewrites the source of the policy checks using Sources,
+// which maps the APM names used in policies to the ones they should be
+// replaced with.
+type APMSourceMutator struct {
+	Sources map[string]string
+}
+
+func (m APMSourceMutator) MutatePolicy(p *sdk.ScalingPolicy) Mutations {
+	result := Mutations{}
+
+	for _, c := range p.Checks {
+		source, ok := m.Sources[c.Source]
+		if !ok || source == c.Source {
+			continue
+		}
+		result = append(result, fmt.Sprintf("check %q source changed from %q to %q", c.Name, c.Source, source))
+		c.Source = source
+	}
+
+	return result
+}
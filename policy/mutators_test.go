@@ -65,3 +65,97 @@ func TestPolicyMutators_NomadAPMMUtator(t *testing.T) {
 		})
 	}
 }
+
+func TestPolicyMutators_NewMutator(t *testing.T) {
+	m, err := NewMutator(MutatorTypeMax, map[string]string{"max": "10", "policy_type": "cluster"})
+	assert.NoError(t, err)
+	assert.Equal(t, MaxMutator{Max: 10, PolicyType: "cluster"}, m)
+
+	_, err = NewMutator(MutatorTypeMax, map[string]string{"max": "ten"})
+	assert.EqualError(t, err, `invalid max value "ten" for mutator "max"`)
+
+	_, err = NewMutator("min", nil)
+	assert.EqualError(t, err, `invalid mutator "min"`)
+}
+
+func TestPolicyMutators_TargetConfigMutator(t *testing.T) {
+	m := TargetConfigMutator{Config: map[string]string{"datacenter": "dc1", "node_class": "default"}}
+
+	p := &sdk.ScalingPolicy{
+		Target: &sdk.ScalingPolicyTarget{Config: map[string]string{"node_class": "batch"}},
+	}
+	got := m.MutatePolicy(p)
+	assert.Equal(t, Mutations{`target config "datacenter" set to "dc1"`}, got)
+	assert.Equal(t, map[string]string{"datacenter": "dc1", "node_class": "batch"}, p.Target.Config)
+
+	// Policies without target are not modified.
+	assert.Equal(t, Mutations{}, m.MutatePolicy(&sdk.ScalingPolicy{}))
+}
+
+func TestPolicyMutators_MaxMutator(t *testing.T) {
+	testCases := []struct {
+		name        string
+		mutator     MaxMutator
+		input       *sdk.ScalingPolicy
+		expected    Mutations
+		expectedMin int64
+		expectedMax int64
+	}{
+		{
+			name:        "within limit",
+			mutator:     MaxMutator{Max: 10},
+			input:       &sdk.ScalingPolicy{Min: 1, Max: 10},
+			expected:    Mutations{},
+			expectedMin: 1,
+			expectedMax: 10,
+		},
+		{
+			name:        "max above limit",
+			mutator:     MaxMutator{Max: 10},
+			input:       &sdk.ScalingPolicy{Min: 1, Max: 10000},
+			expected:    Mutations{"max value reduced from 10000 to 10"},
+			expectedMin: 1,
+			expectedMax: 10,
+		},
+		{
+			name:        "min and max above limit",
+			mutator:     MaxMutator{Max: 10},
+			input:       &sdk.ScalingPolicy{Min: 20, Max: 30},
+			expected:    Mutations{"max value reduced from 30 to 10", "min value reduced from 20 to 10"},
+			expectedMin: 10,
+			expectedMax: 10,
+		},
+		{
+			name:        "different policy type",
+			mutator:     MaxMutator{Max: 10, PolicyType: sdk.ScalingPolicyTypeCluster},
+			input:       &sdk.ScalingPolicy{Type: sdk.ScalingPolicyTypeHorizontal, Min: 1, Max: 10000},
+			expected:    Mutations{},
+			expectedMin: 1,
+			expectedMax: 10000,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := tc.mutator.MutatePolicy(tc.input)
+			assert.Equal(t, tc.expected, got)
+			assert.Equal(t, tc.expectedMin, tc.input.Min)
+			assert.Equal(t, tc.expectedMax, tc.input.Max)
+		})
+	}
+}
+
+func TestPolicyMutators_APMSourceMutator(t *testing.T) {
+	m := APMSourceMutator{Sources: map[string]string{"prom": "prometheus"}}
+
+	p := &sdk.ScalingPolicy{
+		Checks: []*sdk.ScalingPolicyCheck{
+			{Name: "cpu", Source: "prom"},
+			{Name: "mem", Source: "nomad-apm"},
+		},
+	}
+	got := m.MutatePolicy(p)
+	assert.Equal(t, Mutations{`check "cpu" source changed from "prom" to "prometheus"`}, got)
+	assert.Equal(t, "prometheus", p.Checks[0].Source)
+	assert.Equal(t, "nomad-apm", p.Checks[1].Source)
+}
@@ -0,0 +1,342 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package nodepool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	hclog "github.com/hashicorp/go-hclog"
+	multierror "github.com/hashicorp/go-multierror"
+	"github.com/hashicorp/nomad-autoscaler/policy"
+	filePolicy "github.com/hashicorp/nomad-autoscaler/policy/file"
+	"github.com/hashicorp/nomad-autoscaler/sdk"
+	"github.com/hashicorp/nomad-autoscaler/sdk/helper/uuid"
+	"github.com/hashicorp/nomad/api"
+)
+
+const (
+	// MetaKeyPolicy is the node pool meta key which holds the scaling
+	// policies of the node pool, in the same format as policy files.
+	MetaKeyPolicy = "nomad-autoscaler.policy"
+
+	// VariablePathPrefix is the prefix of the Nomad variables which hold the
+	// scaling policies of node pools. The variable path is the prefix
+	// followed by the node pool name, and each item of the variable holds
+	// policies in the same format as policy files, using the item key as the
+	// file name.
+	VariablePathPrefix = "nomad-autoscaler/node_pool/"
+
+	// defaultRefreshInterval is how often node pools and variables are read
+	// to discover policy changes.
+	defaultRefreshInterval = 30 * time.Second
+
+	// Origins identify where a policy was read from, so the same policy can
+	// be defined in the node pool meta and in a variable.
+	originMeta     = "meta"
+	originVariable = "variable"
+)
+
+// Ensure Source satisfies the Source interface.
+var _ policy.Source = (*Source)(nil)
+
+// Source is an implementation of the policy.Source interface that discovers
+// cluster scaling policies attached to Nomad node pools, either in the node
+// pool meta or in Nomad variables. This allows the cluster scaling
+// configuration to be managed alongside the node pool definitions.
+type Source struct {
+	log             hclog.Logger
+	nomad           *api.Client
+	nomadLock       sync.RWMutex
+	policyProcessor *policy.Processor
+
+	// refreshInterval is how often the node pools and variables are read.
+	refreshInterval time.Duration
+
+	// reloadCh helps coordinate reloading the of the MonitorIDs routine.
+	reloadCh chan struct{}
+
+	// lock protects the fields below.
+	lock sync.RWMutex
+
+	// idMap stores a mapping between the location of a policy and its
+	// policyID. This allows us to keep a consistent PolicyID in the event of
+	// policy changes.
+	idMap map[string]policy.PolicyID
+
+	// policies are the policies discovered in the last refresh.
+	policies map[policy.PolicyID]*sdk.ScalingPolicy
+
+	// updateCh is closed, and replaced, when the policies change so policy
+	// monitors can send the new version of their policy.
+	updateCh chan struct{}
+}
+
+// NewNodePoolSource returns a new node pool policy source.
+func NewNodePoolSource(log hclog.Logger, nomad *api.Client, policyProcessor *policy.Processor) *Source {
+	return &Source{
+		log:             log.ResetNamed("node_pool_policy_source"),
+		nomad:           nomad,
+		policyProcessor: policyProcessor,
+		refreshInterval: defaultRefreshInterval,
+		reloadCh:        make(chan struct{}),
+		idMap:           make(map[string]policy.PolicyID),
+		policies:        make(map[policy.PolicyID]*sdk.ScalingPolicy),
+		updateCh:        make(chan struct{}),
+	}
+}
+
+// SetNomadClient sets the Nomad client used to read node pools and
+// variables.
+func (s *Source) SetNomadClient(nomad *api.Client) {
+	s.nomadLock.Lock()
+	defer s.nomadLock.Unlock()
+	s.nomad = nomad
+}
+
+// Name satisfies the Name function of the policy.Source interface.
+func (s *Source) Name() policy.SourceName {
+	return policy.SourceNameNodePool
+}
+
+// ReloadIDsMonitor satisfies the ReloadIDsMonitor function of the
+// policy.Source interface.
+func (s *Source) ReloadIDsMonitor() {
+	s.reloadCh <- struct{}{}
+}
+
+// MonitorIDs satisfies the MonitorIDs function of the policy.Source
+// interface. Node pools and variables are read periodically and the IDs of
+// the policies found are sent to req.ResultCh.
+//
+// This function blocks until the context is closed.
+func (s *Source) MonitorIDs(ctx context.Context, req policy.MonitorIDsReq) {
+	s.log.Debug("starting node pool policy source ID monitor")
+
+	ticker := time.NewTicker(s.refreshInterval)
+	defer ticker.Stop()
+
+	for {
+		s.identifyPolicyIDs(req.ResultCh, req.ErrCh)
+
+		select {
+		case <-ctx.Done():
+			s.log.Trace("stopping node pool policy source ID monitor")
+			return
+		case <-s.reloadCh:
+			s.log.Info("node pool policy source ID monitor received reload signal")
+		case <-ticker.C:
+		}
+	}
+}
+
+// MonitorPolicy satisfies the MonitorPolicy function of the policy.Source
+// interface. The policy is sent to req.ResultCh when the monitor starts and
+// every time it changes.
+//
+// This function blocks until the context is closed.
+func (s *Source) MonitorPolicy(ctx context.Context, req policy.MonitorPolicyReq) {
+	log := s.log.With("policy_id", req.ID)
+
+	// Close channels when done with the monitoring loop.
+	defer close(req.ResultCh)
+	defer close(req.ErrCh)
+
+	log.Info("starting node pool policy monitor")
+
+	var last *sdk.ScalingPolicy
+	for {
+		s.lock.RLock()
+		p, updateCh := s.policies[req.ID], s.updateCh
+		s.lock.RUnlock()
+
+		switch {
+		case p == nil:
+			policy.HandleSourceError(s.Name(), fmt.Errorf("failed to get policy %s", req.ID), req.ErrCh)
+		case !reflect.DeepEqual(p, last):
+			log.Debug("sending node pool policy")
+			req.ResultCh <- *p
+			last = p
+		}
+
+		select {
+		case <-ctx.Done():
+			log.Trace("done with policy monitoring")
+			return
+		case <-req.ReloadCh:
+			log.Trace("reloading policy monitor")
+		case <-updateCh:
+		}
+	}
+}
+
+// identifyPolicyIDs discovers the policies of the node pools and sends their
+// IDs to resultCh. The IDs are not sent if node pools or variables can't be
+// listed, so a temporary Nomad failure doesn't remove all the policies.
+func (s *Source) identifyPolicyIDs(resultCh chan<- policy.IDMessage, errCh chan<- error) {
+	policies, err := s.discoverPolicies()
+	if err != nil {
+		policy.HandleSourceError(s.Name(), err, errCh)
+	}
+	if policies == nil {
+		return
+	}
+
+	s.lock.Lock()
+	if !reflect.DeepEqual(policies, s.policies) {
+		s.policies = policies
+		close(s.updateCh)
+		s.updateCh = make(chan struct{})
+	}
+	s.lock.Unlock()
+
+	ids := make([]policy.PolicyID, 0, len(policies))
+	for id := range policies {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	resultCh <- policy.IDMessage{IDs: ids, Source: s.Name()}
+}
+
+// discoverPolicies reads the policies of all node pools. It returns a nil map
+// if node pools or variables can't be listed. Otherwise, invalid policies
+// are skipped and reported in the returned error.
+func (s *Source) discoverPolicies() (map[policy.PolicyID]*sdk.ScalingPolicy, error) {
+	s.nomadLock.RLock()
+	client := s.nomad
+	s.nomadLock.RUnlock()
+
+	pools, _, err := client.NodePools().List(nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list node pools: %v", err)
+	}
+
+	vars, _, err := client.Variables().PrefixList(VariablePathPrefix, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list node pool variables: %v", err)
+	}
+
+	policies := make(map[policy.PolicyID]*sdk.ScalingPolicy)
+	poolNames := make(map[string]bool, len(pools))
+	var mErr *multierror.Error
+
+	for _, pool := range pools {
+		poolNames[pool.Name] = true
+
+		if raw, ok := pool.Meta[MetaKeyPolicy]; ok {
+			if err := s.decodePolicies(policies, originMeta, pool.Name, pool.Name+".hcl", []byte(raw)); err != nil {
+				mErr = multierror.Append(mErr, fmt.Errorf("invalid policy in node pool %s meta: %v", pool.Name, err))
+			}
+		}
+	}
+
+	for _, v := range vars {
+		poolName := strings.TrimPrefix(v.Path, VariablePathPrefix)
+		if !poolNames[poolName] {
+			mErr = multierror.Append(mErr, fmt.Errorf("variable %s doesn't match any node pool", v.Path))
+			continue
+		}
+
+		items, _, err := client.Variables().GetVariableItems(v.Path, nil)
+		if errors.Is(err, api.ErrVariablePathNotFound) {
+			continue
+		}
+		if err != nil {
+			mErr = multierror.Append(mErr, fmt.Errorf("failed to read variable %s: %v", v.Path, err))
+			continue
+		}
+
+		for key, raw := range items {
+			if err := s.decodePolicies(policies, originVariable, poolName, key, []byte(raw)); err != nil {
+				mErr = multierror.Append(mErr, fmt.Errorf("invalid policy in variable %s item %s: %v", v.Path, key, err))
+			}
+		}
+	}
+
+	return policies, mErr.ErrorOrNil()
+}
+
+// decodePolicies decodes the policies defined in src for the node pool and
+// adds the enabled ones to policies.
+func (s *Source) decodePolicies(policies map[policy.PolicyID]*sdk.ScalingPolicy, origin, pool, filename string, src []byte) error {
+	decoded, err := filePolicy.DecodeSource(src, filename)
+	if err != nil {
+		return err
+	}
+
+	var mErr *multierror.Error
+	for name, p := range decoded {
+		if !p.Enabled {
+			s.log.Trace("policy is disabled therefore ignoring", "node_pool", pool, "name", name)
+			continue
+		}
+
+		p.ID = string(s.policyID(origin, pool, filename, name))
+
+		if err := s.canonicalizePolicy(p, pool); err != nil {
+			mErr = multierror.Append(mErr, multierror.Prefix(err, name))
+			continue
+		}
+
+		policies[policy.PolicyID(p.ID)] = p
+	}
+
+	return mErr.ErrorOrNil()
+}
+
+// canonicalizePolicy sets the default values of the node pool policy and
+// validates it. Policies target the node pool they are attached to unless
+// their target selects nodes explicitly.
+func (s *Source) canonicalizePolicy(p *sdk.ScalingPolicy, pool string) error {
+	if p.Type == "" {
+		p.Type = sdk.ScalingPolicyTypeCluster
+	}
+	if p.Type != sdk.ScalingPolicyTypeCluster {
+		return fmt.Errorf("policy type must be %q, got %q", sdk.ScalingPolicyTypeCluster, p.Type)
+	}
+
+	if p.Target == nil {
+		return errors.New("policy target is required")
+	}
+	if !p.Target.IsNodePoolTarget() {
+		if p.Target.Config == nil {
+			p.Target.Config = make(map[string]string)
+		}
+		p.Target.Config[sdk.TargetConfigKeyNodePool] = pool
+	}
+
+	s.policyProcessor.ApplyPolicyDefaults(p)
+
+	if err := s.policyProcessor.ValidatePolicy(p); err != nil {
+		return err
+	}
+
+	for _, c := range p.Checks {
+		s.policyProcessor.CanonicalizeCheck(c, p.Target)
+	}
+	return nil
+}
+
+// policyID returns the ID of the policy defined at the location, generating
+// one the first time the location is seen.
+func (s *Source) policyID(origin, pool, filename, name string) policy.PolicyID {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	key := strings.Join([]string{origin, pool, filename, name}, "/")
+
+	id, ok := s.idMap[key]
+	if !ok {
+		id = policy.PolicyID(uuid.Generate())
+		s.idMap[key] = id
+	}
+	return id
+}
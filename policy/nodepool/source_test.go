@@ -0,0 +1,242 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package nodepool
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	hclog "github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/nomad-autoscaler/policy"
+	"github.com/hashicorp/nomad-autoscaler/sdk"
+	"github.com/hashicorp/nomad/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testClusterPolicy = `
+scaling "workers" {
+  enabled = true
+  min     = 1
+  max     = 10
+
+  policy {
+    check "cpu" {
+      source = "prometheus"
+      query  = "cpu"
+
+      strategy "target-value" {
+        target = "70"
+      }
+    }
+
+    target "aws-asg" {
+      aws_asg_name = "workers"
+    }
+  }
+}`
+
+// testNomadServer is a minimal implementation of the Nomad node pools and
+// variables APIs.
+type testNomadServer struct {
+	lock      sync.Mutex
+	pools     []*api.NodePool
+	variables map[string]api.VariableItems
+	fail      bool
+}
+
+func (s *testNomadServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.fail {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	switch {
+	case r.URL.Path == "/v1/node/pools":
+		_ = json.NewEncoder(w).Encode(s.pools)
+
+	case r.URL.Path == "/v1/vars":
+		prefix := r.URL.Query().Get("prefix")
+		vars := []*api.VariableMetadata{}
+		for path := range s.variables {
+			if strings.HasPrefix(path, prefix) {
+				vars = append(vars, &api.VariableMetadata{Path: path})
+			}
+		}
+		_ = json.NewEncoder(w).Encode(vars)
+
+	case strings.HasPrefix(r.URL.Path, "/v1/var/"):
+		path := strings.TrimPrefix(r.URL.Path, "/v1/var/")
+		items, ok := s.variables[path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(&api.Variable{Path: path, Items: items})
+
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func (s *testNomadServer) setPoolMeta(pool, key, value string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for _, p := range s.pools {
+		if p.Name == pool {
+			p.Meta[key] = value
+		}
+	}
+}
+
+func testNodePoolSource(t *testing.T, srv *testNomadServer) *Source {
+	ts := httptest.NewServer(srv)
+	t.Cleanup(ts.Close)
+
+	client, err := api.NewClient(&api.Config{Address: ts.URL})
+	require.NoError(t, err)
+
+	pr := policy.NewProcessor(&policy.ConfigDefaults{
+		DefaultEvaluationInterval: 10 * time.Second,
+		DefaultCooldown:           5 * time.Minute,
+	}, []string{"nomad-apm"})
+
+	return NewNodePoolSource(hclog.NewNullLogger(), client, pr)
+}
+
+func TestSource_discoverPolicies(t *testing.T) {
+	srv := &testNomadServer{
+		pools: []*api.NodePool{
+			{Name: "default", Meta: map[string]string{}},
+			{Name: "gpu", Meta: map[string]string{MetaKeyPolicy: testClusterPolicy}},
+			{Name: "batch", Meta: map[string]string{}},
+		},
+		variables: map[string]api.VariableItems{
+			VariablePathPrefix + "batch": {
+				"workers.hcl": strings.Replace(testClusterPolicy, `aws_asg_name = "workers"`,
+					`aws_asg_name = "batch"
+      node_class   = "batch"`, 1),
+				"invalid.hcl": strings.Replace(testClusterPolicy, `min     = 1`, `type = "horizontal"`, 1),
+			},
+			VariablePathPrefix + "missing": {
+				"workers.hcl": testClusterPolicy,
+			},
+		},
+	}
+	s := testNodePoolSource(t, srv)
+
+	policies, err := s.discoverPolicies()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `policy type must be "cluster", got "horizontal"`)
+	assert.Contains(t, err.Error(), "variable nomad-autoscaler/node_pool/missing doesn't match any node pool")
+	require.Len(t, policies, 2)
+
+	var gpu, batch *sdk.ScalingPolicy
+	for _, p := range policies {
+		switch p.Target.Config["aws_asg_name"] {
+		case "workers":
+			gpu = p
+		case "batch":
+			batch = p
+		}
+	}
+	require.NotNil(t, gpu)
+	require.NotNil(t, batch)
+
+	// Policies target the node pool they are attached to unless they select
+	// nodes explicitly.
+	assert.Equal(t, sdk.ScalingPolicyTypeCluster, gpu.Type)
+	assert.Equal(t, "gpu", gpu.Target.Config[sdk.TargetConfigKeyNodePool])
+	assert.Equal(t, 5*time.Minute, gpu.Cooldown)
+	assert.NotContains(t, batch.Target.Config, sdk.TargetConfigKeyNodePool)
+	assert.Equal(t, "batch", batch.Target.Config[sdk.TargetConfigKeyClass])
+
+	// Policy IDs are stable across refreshes.
+	again, _ := s.discoverPolicies()
+	assert.Contains(t, again, policy.PolicyID(gpu.ID))
+	assert.Contains(t, again, policy.PolicyID(batch.ID))
+}
+
+func TestSource_identifyPolicyIDs(t *testing.T) {
+	srv := &testNomadServer{
+		pools: []*api.NodePool{
+			{Name: "gpu", Meta: map[string]string{MetaKeyPolicy: testClusterPolicy}},
+		},
+		variables: map[string]api.VariableItems{},
+	}
+	s := testNodePoolSource(t, srv)
+
+	resultCh := make(chan policy.IDMessage, 1)
+	errCh := make(chan error, 1)
+
+	s.identifyPolicyIDs(resultCh, errCh)
+	msg := <-resultCh
+	assert.Equal(t, policy.SourceNameNodePool, msg.Source)
+	assert.Len(t, msg.IDs, 1)
+
+	// Failing to reach Nomad must not remove the policies.
+	srv.lock.Lock()
+	srv.fail = true
+	srv.lock.Unlock()
+
+	s.identifyPolicyIDs(resultCh, errCh)
+	assert.ErrorContains(t, <-errCh, "failed to list node pools")
+	assert.Empty(t, resultCh)
+	assert.Len(t, s.policies, 1)
+}
+
+func TestSource_MonitorPolicy(t *testing.T) {
+	srv := &testNomadServer{
+		pools: []*api.NodePool{
+			{Name: "gpu", Meta: map[string]string{MetaKeyPolicy: testClusterPolicy}},
+		},
+		variables: map[string]api.VariableItems{},
+	}
+	s := testNodePoolSource(t, srv)
+
+	idCh := make(chan policy.IDMessage, 1)
+	s.identifyPolicyIDs(idCh, make(chan error, 1))
+	ids := (<-idCh).IDs
+	require.Len(t, ids, 1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	resultCh := make(chan sdk.ScalingPolicy)
+	go s.MonitorPolicy(ctx, policy.MonitorPolicyReq{
+		ID:       ids[0],
+		ErrCh:    make(chan error, 1),
+		ReloadCh: make(chan struct{}),
+		ResultCh: resultCh,
+	})
+
+	select {
+	case p := <-resultCh:
+		assert.Equal(t, int64(10), p.Max)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout waiting for policy")
+	}
+
+	// Changes to the node pool meta are sent to the monitor.
+	srv.setPoolMeta("gpu", MetaKeyPolicy, strings.Replace(testClusterPolicy, "max     = 10", "max     = 20", 1))
+	s.identifyPolicyIDs(idCh, make(chan error, 1))
+	<-idCh
+
+	select {
+	case p := <-resultCh:
+		assert.Equal(t, int64(20), p.Max)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout waiting for policy update")
+	}
+}
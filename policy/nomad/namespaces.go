@@ -0,0 +1,77 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package nomad
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/hashicorp/nomad-autoscaler/sdk"
+)
+
+// NamespaceWildcard is the namespace name used to define the rules of
+// namespaces that don't have their own.
+const NamespaceWildcard = "*"
+
+// NamespaceRule restricts the plugins that policies from a Nomad namespace
+// are allowed to use. An empty list doesn't restrict the plugin type.
+type NamespaceRule struct {
+	Targets    []string
+	Strategies []string
+	APMs       []string
+}
+
+// SetNamespaceRules sets the rules, keyed by namespace, used to authorize the
+// policies read from Nomad. Policies from namespaces without rules are not
+// restricted, unless a rule for NamespaceWildcard is set.
+func (s *Source) SetNamespaceRules(rules map[string]*NamespaceRule) {
+	s.namespaceRulesLock.Lock()
+	defer s.namespaceRulesLock.Unlock()
+	s.namespaceRules = rules
+}
+
+// authorizePolicy returns an error if the policy uses plugins that are not
+// allowed for its namespace.
+func (s *Source) authorizePolicy(ns string, p *sdk.ScalingPolicy) error {
+	s.namespaceRulesLock.RLock()
+	defer s.namespaceRulesLock.RUnlock()
+
+	rule, ok := s.namespaceRules[ns]
+	if !ok {
+		rule, ok = s.namespaceRules[NamespaceWildcard]
+	}
+	if !ok || rule == nil {
+		return nil
+	}
+
+	var result *multierror.Error
+
+	if p.Target != nil && !allowed(rule.Targets, p.Target.Name) {
+		result = multierror.Append(result, fmt.Errorf("target %q is not allowed in namespace %q", p.Target.Name, ns))
+	}
+
+	for _, c := range p.Checks {
+		if c.Strategy != nil && !allowed(rule.Strategies, c.Strategy.Name) {
+			result = multierror.Append(result, fmt.Errorf("check %s: strategy %q is not allowed in namespace %q", c.Name, c.Strategy.Name, ns))
+		}
+		if !allowed(rule.APMs, c.Source) {
+			result = multierror.Append(result, fmt.Errorf("check %s: source %q is not allowed in namespace %q", c.Name, c.Source, ns))
+		}
+	}
+
+	return result.ErrorOrNil()
+}
+
+// allowed returns true if the list is empty or contains name.
+func allowed(list []string, name string) bool {
+	if len(list) == 0 {
+		return true
+	}
+	for _, v := range list {
+		if v == name {
+			return true
+		}
+	}
+	return false
+}
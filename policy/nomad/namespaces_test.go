@@ -0,0 +1,86 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package nomad
+
+import (
+	"testing"
+
+	"github.com/hashicorp/nomad-autoscaler/sdk"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSource_authorizePolicy(t *testing.T) {
+	s := &Source{}
+	s.SetNamespaceRules(map[string]*NamespaceRule{
+		"team-a": {
+			Targets:    []string{"nomad-target"},
+			Strategies: []string{"target-value"},
+			APMs:       []string{"prometheus"},
+		},
+		NamespaceWildcard: {
+			Targets: []string{"nomad-target"},
+		},
+	})
+
+	newPolicy := func(target, strategy, source string) *sdk.ScalingPolicy {
+		return &sdk.ScalingPolicy{
+			Target: &sdk.ScalingPolicyTarget{Name: target},
+			Checks: []*sdk.ScalingPolicyCheck{
+				{Name: "check", Source: source, Strategy: &sdk.ScalingPolicyStrategy{Name: strategy}},
+			},
+		}
+	}
+
+	testCases := []struct {
+		name        string
+		namespace   string
+		input       *sdk.ScalingPolicy
+		expectedErr []string
+	}{
+		{
+			name:      "allowed",
+			namespace: "team-a",
+			input:     newPolicy("nomad-target", "target-value", "prometheus"),
+		},
+		{
+			name:      "not allowed",
+			namespace: "team-a",
+			input:     newPolicy("aws-asg", "threshold", "nomad-apm"),
+			expectedErr: []string{
+				`target "aws-asg" is not allowed in namespace "team-a"`,
+				`check check: strategy "threshold" is not allowed in namespace "team-a"`,
+				`check check: source "nomad-apm" is not allowed in namespace "team-a"`,
+			},
+		},
+		{
+			name:      "wildcard allowed",
+			namespace: "team-b",
+			input:     newPolicy("nomad-target", "threshold", "nomad-apm"),
+		},
+		{
+			name:        "wildcard not allowed",
+			namespace:   "team-b",
+			input:       newPolicy("aws-asg", "threshold", "nomad-apm"),
+			expectedErr: []string{`target "aws-asg" is not allowed in namespace "team-b"`},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := s.authorizePolicy(tc.namespace, tc.input)
+			if len(tc.expectedErr) == 0 {
+				assert.NoError(t, err)
+				return
+			}
+			assert.Error(t, err)
+			for _, expected := range tc.expectedErr {
+				assert.Contains(t, err.Error(), expected)
+			}
+		})
+	}
+
+	// Policies are not restricted without rules.
+	s.SetNamespaceRules(nil)
+	assert.NoError(t, s.authorizePolicy("team-a", newPolicy("aws-asg", "threshold", "nomad-apm")))
+}
@@ -45,6 +45,12 @@ func parsePolicy(p *api.ScalingPolicy) sdk.ScalingPolicy {
 		to.EvaluationInterval, _ = time.ParseDuration(eval)
 	}
 
+	// Parse evaluation_timeout as time.Duration.
+	// Ignore error since we assume policy has been validated.
+	if timeout, ok := p.Policy[keyEvaluationTimeout].(string); ok {
+		to.EvaluationTimeout, _ = time.ParseDuration(timeout)
+	}
+
 	// Parse cooldown as time.Duraction
 	// Ignore error since we assume policy has been validated.
 	if cooldown, ok := p.Policy[keyCooldown].(string); ok {
@@ -56,6 +62,55 @@ func parsePolicy(p *api.ScalingPolicy) sdk.ScalingPolicy {
 		to.OnCheckError = onCheckError
 	}
 
+	// Parse priority.
+	if priority, ok := p.Policy[keyPriority].(string); ok {
+		to.PriorityLane = priority
+	}
+
+	// Parse on_out_of_band_change.
+	if onOutOfBandChange, ok := p.Policy[keyOnOutOfBandChange].(string); ok {
+		to.OnOutOfBandChange = onOutOfBandChange
+	}
+
+	// Parse max_unavailable as a percentage.
+	// Ignore error since we assume policy has been validated.
+	if maxUnavailable, ok := p.Policy[keyMaxUnavailable]; ok {
+		to.MaxUnavailable, _ = sdk.ParseMaxUnavailable(fmt.Sprint(maxUnavailable))
+	}
+
+	// Parse max_hourly_cost.
+	switch maxHourlyCost := p.Policy[keyMaxHourlyCost].(type) {
+	case float64:
+		to.MaxHourlyCost = maxHourlyCost
+	case int:
+		to.MaxHourlyCost = float64(maxHourlyCost)
+	}
+
+	// Parse approval_required and approval_ttl.
+	// Ignore error since we assume policy has been validated.
+	if approvalRequired, ok := p.Policy[keyApprovalRequired].(bool); ok {
+		to.ApprovalRequired = approvalRequired
+	}
+
+	if approvalTTL, ok := p.Policy[keyApprovalTTL].(string); ok {
+		to.ApprovalTTL, _ = time.ParseDuration(approvalTTL)
+	}
+
+	// Parse query_window_offset as time.Duration. Checks use the same key.
+	// Ignore error since we assume policy has been validated.
+	if queryWindowOffset, ok := p.Policy[keyQueryWindowOffset].(string); ok {
+		to.QueryWindowOffset, _ = time.ParseDuration(queryWindowOffset)
+	}
+
+	// Parse explain.
+	// Ignore error since we assume policy has been validated.
+	if explain, ok := p.Policy[keyExplain].(bool); ok {
+		to.Explain = explain
+	}
+
+	// Parse meta.
+	to.Meta = parseConfigMap(p.Policy[keyMeta])
+
 	// Parse target block.
 	var target *sdk.ScalingPolicyTarget
 
@@ -111,15 +166,20 @@ func parseChecks(cs interface{}) []*sdk.ScalingPolicyCheck {
 //
 //	scaling {
 //	  policy {
-//	  +---------------------------------+
-//	  | check "name" {                  |
-//	  |   source = "source"             |
-//	  |   query               = "query" |
-//	  |   query_window        = "5m"    |
-//	  |   query_window_offset = "1m"    |
-//	  |   strategy "strategy" { ... }   |
-//	  | }                               |
-//	  +---------------------------------+
+//	  +------------------------------------+
+//	  | check "name" {                     |
+//	  |   source = "source"                |
+//	  |   query                  = "query" |
+//	  |   query_window           = "5m"    |
+//	  |   query_window_offset    = "1m"    |
+//	  |   query_retry_budget     = "30s"   |
+//	  |   query_fallback_max_age = "5m"    |
+//	  |   history_size           = 10      |
+//	  |   when                   = "..."   |
+//	  |   source_config          = { ... } |
+//	  |   strategy "strategy" { ... }      |
+//	  | }                                  |
+//	  +------------------------------------+
 //	  }
 //	}
 func parseCheck(c interface{}) *sdk.ScalingPolicyCheck {
@@ -148,6 +208,7 @@ func parseCheck(c interface{}) *sdk.ScalingPolicyCheck {
 	source, _ := checkMap[keySource].(string)
 	on_error, _ := checkMap[keyOnError].(string)
 	group, _ := checkMap[keyGroup].(string)
+	when, _ := checkMap[keyWhen].(string)
 
 	// Parse query_window and query_window_offset ignoring errors since we
 	// assume policy has been validated.
@@ -159,14 +220,39 @@ func parseCheck(c interface{}) *sdk.ScalingPolicyCheck {
 		queryWindowOffset, _ = time.ParseDuration(queryWindowOffsetStr)
 	}
 
+	// Parse query_retry_budget and query_fallback_max_age, also ignoring
+	// errors.
+	var queryRetryBudget, queryFallbackMaxAge time.Duration
+	if queryRetryBudgetStr, ok := checkMap[keyQueryRetryBudget].(string); ok {
+		queryRetryBudget, _ = time.ParseDuration(queryRetryBudgetStr)
+	}
+	if queryFallbackAgeStr, ok := checkMap[keyQueryFallbackAge].(string); ok {
+		queryFallbackMaxAge, _ = time.ParseDuration(queryFallbackAgeStr)
+	}
+
+	// Parse history_size. Numbers are decoded from JSON as float64, but
+	// handle int as well for policies built in code.
+	var historySize int
+	switch v := checkMap[keyHistorySize].(type) {
+	case float64:
+		historySize = int(v)
+	case int:
+		historySize = v
+	}
+
 	return &sdk.ScalingPolicyCheck{
-		Group:             group,
-		Query:             query,
-		QueryWindow:       queryWindow,
-		QueryWindowOffset: queryWindowOffset,
-		Source:            source,
-		Strategy:          strategy,
-		OnError:           on_error,
+		Group:               group,
+		Query:               query,
+		QueryWindow:         queryWindow,
+		QueryWindowOffset:   queryWindowOffset,
+		Source:              source,
+		Strategy:            strategy,
+		OnError:             on_error,
+		HistorySize:         historySize,
+		QueryRetryBudget:    queryRetryBudget,
+		QueryFallbackMaxAge: queryFallbackMaxAge,
+		When:                when,
+		SourceConfig:        parseConfigMap(checkMap[keySourceConfig]),
 	}
 }
 
@@ -231,12 +317,49 @@ func parseTarget(targetBlock interface{}, targetAttr map[string]string) *sdk.Sca
 	}
 
 	for k, v := range targetMap {
+		if k == keyPluginConfig {
+			continue
+		}
 		configMapString[k] = fmt.Sprintf("%v", v)
 	}
 
 	return &sdk.ScalingPolicyTarget{
-		Config: configMapString,
+		Config:       configMapString,
+		PluginConfig: parseConfigMap(targetMap[keyPluginConfig]),
+	}
+}
+
+// parseConfigMap parses a map attribute, such as source_config, into a map
+// of strings.
+//
+// Depending on how the job was parsed, the value may be a map or a list with
+// a single map. It returns `nil` if the attribute is not set or is invalid.
+//
+//	scaling {
+//	  policy {
+//	    check "check" {
+//	      source_config = {
+//	      +---------------+
+//	      | key = "value" |
+//	      +---------------+
+//	      }
+//	    }
+//	  }
+//	}
+func parseConfigMap(c interface{}) map[string]string {
+	configMap, ok := c.(map[string]interface{})
+	if !ok {
+		configMap = parseBlock(c)
+	}
+	if len(configMap) == 0 {
+		return nil
+	}
+
+	configMapString := make(map[string]string, len(configMap))
+	for k, v := range configMap {
+		configMapString[k] = fmt.Sprintf("%v", v)
 	}
+	return configMapString
 }
 
 // parseBlock parses the specific structure of a block into a more usable
@@ -28,9 +28,14 @@ func Test_parsePolicy(t *testing.T) {
 				Max:                10,
 				Enabled:            false,
 				EvaluationInterval: 5 * time.Second,
+				EvaluationTimeout:  30 * time.Second,
 				Cooldown:           5 * time.Minute,
 				Type:               "horizontal",
 				OnCheckError:       "fail",
+				PriorityLane:       "high",
+				Meta: map[string]string{
+					"team": "platform",
+				},
 				Target: &sdk.ScalingPolicyTarget{
 					Name: "target",
 					Config: map[string]string{
@@ -44,12 +49,15 @@ func Test_parsePolicy(t *testing.T) {
 				},
 				Checks: []*sdk.ScalingPolicyCheck{
 					{
-						Name:              "check-1",
-						Source:            "source-1",
-						Query:             "query-1",
-						QueryWindow:       time.Minute,
-						QueryWindowOffset: 2 * time.Minute,
-						OnError:           "ignore",
+						Name:                "check-1",
+						Source:              "source-1",
+						Query:               "query-1",
+						QueryWindow:         time.Minute,
+						QueryWindowOffset:   2 * time.Minute,
+						OnError:             "ignore",
+						HistorySize:         10,
+						QueryRetryBudget:    30 * time.Second,
+						QueryFallbackMaxAge: 5 * time.Minute,
 						Strategy: &sdk.ScalingPolicyStrategy{
 							Name: "strategy-1",
 							Config: map[string]string{
@@ -406,3 +414,44 @@ func Test_parseBlock(t *testing.T) {
 		})
 	}
 }
+
+func Test_parseConfigMap(t *testing.T) {
+	testCases := []struct {
+		name     string
+		input    interface{}
+		expected map[string]string
+	}{
+		{
+			name:     "map",
+			input:    map[string]interface{}{"address": "http://example.com", "port": float64(9090)},
+			expected: map[string]string{"address": "http://example.com", "port": "9090"},
+		},
+		{
+			name:     "block",
+			input:    []interface{}{map[string]interface{}{"address": "http://example.com"}},
+			expected: map[string]string{"address": "http://example.com"},
+		},
+		{
+			name:     "empty map",
+			input:    map[string]interface{}{},
+			expected: nil,
+		},
+		{
+			name:     "nil",
+			input:    nil,
+			expected: nil,
+		},
+		{
+			name:     "invalid type",
+			input:    "address",
+			expected: nil,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual := parseConfigMap(tc.input)
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+}
@@ -27,13 +27,28 @@ const (
 	keyQueryWindow        = "query_window"
 	keyQueryWindowOffset  = "query_window_offset"
 	keyEvaluationInterval = "evaluation_interval"
+	keyEvaluationTimeout  = "evaluation_timeout"
 	keyOnCheckError       = "on_check_error"
+	keyPriority           = "priority"
+	keyOnOutOfBandChange  = "on_out_of_band_change"
 	keyOnError            = "on_error"
+	keyHistorySize        = "history_size"
+	keyQueryRetryBudget   = "query_retry_budget"
+	keyQueryFallbackAge   = "query_fallback_max_age"
+	keyWhen               = "when"
+	keySourceConfig       = "source_config"
+	keyPluginConfig       = "plugin_config"
 	keyTarget             = "target"
 	keyChecks             = "check"
 	keyGroup              = "group"
 	keyStrategy           = "strategy"
 	keyCooldown           = "cooldown"
+	keyMaxUnavailable     = "max_unavailable"
+	keyMaxHourlyCost      = "max_hourly_cost"
+	keyApprovalRequired   = "approval_required"
+	keyApprovalTTL        = "approval_ttl"
+	keyExplain            = "explain"
+	keyMeta               = "meta"
 )
 
 // Ensure NomadSource satisfies the Source interface.
@@ -47,6 +62,11 @@ type Source struct {
 	nomadLock       sync.RWMutex
 	policyProcessor *policy.Processor
 
+	// namespaceRules restrict the plugins used by policies, keyed by Nomad
+	// namespace.
+	namespaceRules     map[string]*NamespaceRule
+	namespaceRulesLock sync.RWMutex
+
 	// reloadCh helps coordinate reloading the of the MonitorIDs routine.
 	reloadCh chan struct{}
 }
@@ -261,6 +281,21 @@ func (s *Source) MonitorPolicy(ctx context.Context, req policy.MonitorPolicyReq)
 		autoPolicy := parsePolicy(p)
 		s.canonicalizePolicy(&autoPolicy)
 
+		if err := policy.ValidateChecksWhen(&autoPolicy); err != nil {
+			policy.HandleSourceError(s.Name(), fmt.Errorf("policy validation failed: %v", err), req.ErrCh)
+			continue
+		}
+
+		if err := policy.ValidateQueryTemplates(&autoPolicy); err != nil {
+			policy.HandleSourceError(s.Name(), fmt.Errorf("policy validation failed: %v", err), req.ErrCh)
+			continue
+		}
+
+		if err := s.authorizePolicy(p.Namespace, &autoPolicy); err != nil {
+			policy.HandleSourceError(s.Name(), fmt.Errorf("policy authorization failed: %v", err), req.ErrCh)
+			continue
+		}
+
 		req.ResultCh <- autoPolicy
 	}
 }
@@ -13,8 +13,10 @@ job "full-scaling" {
 
       policy {
         evaluation_interval = "5s"
+        evaluation_timeout  = "30s"
         cooldown            = "5m"
         on_check_error      = "fail"
+        priority            = "high"
 
         target "target" {
           int_config  = 2
@@ -28,6 +30,10 @@ job "full-scaling" {
           query_window        = "1m"
           query_window_offset = "2m"
           on_error            = "ignore"
+          history_size        = 10
+
+          query_retry_budget     = "30s"
+          query_fallback_max_age = "5m"
 
           strategy "strategy-1" {
             int_config  = 2
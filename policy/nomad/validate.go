@@ -6,10 +6,12 @@ package nomad
 import (
 	"errors"
 	"fmt"
+	"math"
 	"time"
 
 	"github.com/hashicorp/go-multierror"
 	"github.com/hashicorp/nomad-autoscaler/plugins"
+	"github.com/hashicorp/nomad-autoscaler/sdk"
 	"github.com/hashicorp/nomad-autoscaler/sdk/helper/ptr"
 	"github.com/hashicorp/nomad/api"
 )
@@ -83,16 +85,98 @@ func validatePolicy(p map[string]interface{}) error {
 
 	// Validate EvaluationInterval, if present.
 	//   1. EvaluationInterval should be a valid duration.
+	//   2. EvaluationInterval should not be negative.
 	if evalInterval, ok := p[keyEvaluationInterval]; ok {
-		if err := validateDuration(evalInterval, path+"."+keyEvaluationInterval); err != nil {
+		if err := validateNonNegativeDuration(evalInterval, path+"."+keyEvaluationInterval); err != nil {
+			result = multierror.Append(result, err)
+		}
+	}
+
+	// Validate EvaluationTimeout, if present.
+	//   1. EvaluationTimeout should be a valid duration.
+	if evalTimeout, ok := p[keyEvaluationTimeout]; ok {
+		if err := validateDuration(evalTimeout, path+"."+keyEvaluationTimeout); err != nil {
 			result = multierror.Append(result, err)
 		}
 	}
 
 	// Validate Cooldown, if present.
 	//   1. Cooldown should be a valid duration.
+	//   2. Cooldown should not be negative.
 	if cooldown, ok := p[keyCooldown]; ok {
-		if err := validateDuration(cooldown, path+"."+keyCooldown); err != nil {
+		if err := validateNonNegativeDuration(cooldown, path+"."+keyCooldown); err != nil {
+			result = multierror.Append(result, err)
+		}
+	}
+
+	// Validate Priority, if present.
+	//   1. Priority should be a string.
+	if priority, ok := p[keyPriority]; ok {
+		if _, ok := priority.(string); !ok {
+			result = multierror.Append(result, fmt.Errorf("%s.%s must be string, found %T", path, keyPriority, priority))
+		}
+	}
+
+	// Validate OnOutOfBandChange, if present.
+	//   1. OnOutOfBandChange should be a string.
+	if onOutOfBandChange, ok := p[keyOnOutOfBandChange]; ok {
+		if _, ok := onOutOfBandChange.(string); !ok {
+			result = multierror.Append(result, fmt.Errorf("%s.%s must be string, found %T", path, keyOnOutOfBandChange, onOutOfBandChange))
+		}
+	}
+
+	// Validate MaxUnavailable, if present.
+	//   1. MaxUnavailable should be a percentage between 0 and 100.
+	if maxUnavailable, ok := p[keyMaxUnavailable]; ok {
+		if _, err := sdk.ParseMaxUnavailable(fmt.Sprint(maxUnavailable)); err != nil {
+			result = multierror.Append(result, fmt.Errorf("%s.%s: %v", path, keyMaxUnavailable, err))
+		}
+	}
+
+	// Validate MaxHourlyCost, if present.
+	//   1. MaxHourlyCost should be a non-negative number.
+	if maxHourlyCost, ok := p[keyMaxHourlyCost]; ok {
+		if err := validateNonNegativeNumber(maxHourlyCost, path+"."+keyMaxHourlyCost); err != nil {
+			result = multierror.Append(result, err)
+		}
+	}
+
+	// Validate ApprovalRequired, if present.
+	//   1. ApprovalRequired should be a bool.
+	if approvalRequired, ok := p[keyApprovalRequired]; ok {
+		if _, ok := approvalRequired.(bool); !ok {
+			result = multierror.Append(result, fmt.Errorf("%s.%s must be bool, found %T", path, keyApprovalRequired, approvalRequired))
+		}
+	}
+
+	// Validate ApprovalTTL, if present.
+	//   1. ApprovalTTL should be a valid duration.
+	if approvalTTL, ok := p[keyApprovalTTL]; ok {
+		if err := validateDuration(approvalTTL, path+"."+keyApprovalTTL); err != nil {
+			result = multierror.Append(result, err)
+		}
+	}
+
+	// Validate QueryWindowOffset, if present.
+	//   1. QueryWindowOffset should be a valid duration.
+	if queryWindowOffset, ok := p[keyQueryWindowOffset]; ok {
+		if err := validateDuration(queryWindowOffset, path+"."+keyQueryWindowOffset); err != nil {
+			result = multierror.Append(result, err)
+		}
+	}
+
+	// Validate Explain, if present.
+	//   1. Explain should be a bool.
+	if explain, ok := p[keyExplain]; ok {
+		if _, ok := explain.(bool); !ok {
+			result = multierror.Append(result, fmt.Errorf("%s.%s must be bool, found %T", path, keyExplain, explain))
+		}
+	}
+
+	// Validate Meta, if present.
+	//   1. Meta must be a map.
+	if meta, ok := p[keyMeta]; ok {
+		if err := validateConfigMap(meta, path+"."+keyMeta); err != nil {
 			result = multierror.Append(result, err)
 		}
 	}
@@ -212,6 +296,51 @@ func validateCheck(c map[string]interface{}, path string, label string) error {
 		}
 	}
 
+	// Validate HistorySize, if present.
+	//   1. HistorySize must be a whole number.
+	//   2. HistorySize must not be negative.
+	if historySize, ok := c[keyHistorySize]; ok {
+		if err := validateHistorySize(historySize, path+"."+keyHistorySize); err != nil {
+			result = multierror.Append(result, err)
+		}
+	}
+
+	// Validate QueryRetryBudget, if present.
+	//   1. QueryRetryBudget should be a valid time duration.
+	if retryBudget, ok := c[keyQueryRetryBudget]; ok {
+		if err := validateDuration(retryBudget, path+"."+keyQueryRetryBudget); err != nil {
+			result = multierror.Append(result, err)
+		}
+	}
+
+	// Validate QueryFallbackMaxAge, if present.
+	//   1. QueryFallbackMaxAge should be a valid time duration.
+	if fallbackAge, ok := c[keyQueryFallbackAge]; ok {
+		if err := validateDuration(fallbackAge, path+"."+keyQueryFallbackAge); err != nil {
+			result = multierror.Append(result, err)
+		}
+	}
+
+	// Validate When, if present.
+	//   1. When must have string value.
+	//   2. When must not be empty.
+	if when, ok := c[keyWhen]; ok {
+		whenStr, ok := when.(string)
+		if !ok {
+			result = multierror.Append(result, fmt.Errorf("%s.%s must be string, found %T", path, keyWhen, when))
+		} else if whenStr == "" {
+			result = multierror.Append(result, fmt.Errorf("%s.%s can't be empty", path, keyWhen))
+		}
+	}
+
+	// Validate SourceConfig, if present.
+	//   1. SourceConfig must be a map.
+	if sourceConfig, ok := c[keySourceConfig]; ok {
+		if err := validateConfigMap(sourceConfig, path+"."+keySourceConfig); err != nil {
+			result = multierror.Append(result, err)
+		}
+	}
+
 	// Some strategy plugins do not require an APM
 	var strategyValidator validatorWithLabelFunc
 	if !queryOk && !sourceOk {
@@ -282,6 +411,72 @@ func validateStrategyWithoutMetric(s map[string]interface{}, path string, label
 	return fmt.Errorf("%s strategy requires a query", path)
 }
 
+// validateConfigMap validates if the input is a map of plugin config values.
+//
+// Validation rules:
+//  1. Input must be a map, or a list with a single map.
+func validateConfigMap(c interface{}, path string) error {
+	if _, ok := c.(map[string]interface{}); ok {
+		return nil
+	}
+
+	if l, ok := c.([]interface{}); ok && len(l) == 1 {
+		if _, ok := l[0].(map[string]interface{}); ok {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%s must be a map, found %T", path, c)
+}
+
+// validateHistorySize validates if the input is a valid check history size.
+//
+// Validation rules:
+//  1. Input must be a number.
+//  2. Input must be a whole, non-negative number.
+func validateHistorySize(h interface{}, path string) error {
+	var size float64
+
+	switch v := h.(type) {
+	case float64:
+		size = v
+	case int:
+		size = float64(v)
+	default:
+		return fmt.Errorf("%s must be number, found %T", path, h)
+	}
+
+	if size < 0 || size != math.Trunc(size) {
+		return fmt.Errorf("%s must be a non-negative whole number, found %v", path, h)
+	}
+
+	return nil
+}
+
+// validateNonNegativeNumber validates if the input is a non-negative number.
+//
+// Validation rules:
+//  1. Input must be a number.
+//  2. Input must not be negative.
+func validateNonNegativeNumber(n interface{}, path string) error {
+	var v float64
+
+	switch t := n.(type) {
+	case float64:
+		v = t
+	case int:
+		v = float64(t)
+	default:
+		return fmt.Errorf("%s must be number, found %T", path, n)
+	}
+
+	if v < 0 {
+		return fmt.Errorf("%s must not be negative, found %v", path, n)
+	}
+
+	return nil
+}
+
 // validateDuration validates if the input has a valid time.Duration format.
 //
 // Validation rules:
@@ -300,6 +495,20 @@ func validateDuration(d interface{}, path string) error {
 	return nil
 }
 
+// validateNonNegativeDuration validates that d is a string in the
+// time.Duration format and that the duration is not negative.
+func validateNonNegativeDuration(d interface{}, path string) error {
+	if err := validateDuration(d, path); err != nil {
+		return err
+	}
+
+	if dur, _ := time.ParseDuration(d.(string)); dur < 0 {
+		return fmt.Errorf(`%s must not be negative, found "%s"`, path, d)
+	}
+
+	return nil
+}
+
 // validateBlock validates the structure of a block parsed from HCL.
 // The content of the block can be further validated by passing a `validator`
 // function.
@@ -40,6 +40,9 @@ func (pr *Processor) ApplyPolicyDefaults(p *sdk.ScalingPolicy) {
 	if p.EvaluationInterval == 0 {
 		p.EvaluationInterval = pr.defaults.DefaultEvaluationInterval
 	}
+	if p.QueryWindowOffset == 0 {
+		p.QueryWindowOffset = pr.defaults.DefaultQueryWindowOffset
+	}
 
 	// we limit the grpc timeout to a 75% of the evaluation interval
 	if p.Target != nil {
@@ -52,6 +55,9 @@ func (pr *Processor) ApplyPolicyDefaults(p *sdk.ScalingPolicy) {
 		if c.QueryWindow == 0 {
 			c.QueryWindow = DefaultQueryWindow
 		}
+		if c.QueryWindowOffset == 0 {
+			c.QueryWindowOffset = p.QueryWindowOffset
+		}
 	}
 }
 
@@ -73,6 +79,18 @@ func (pr *Processor) ValidatePolicy(p *sdk.ScalingPolicy) error {
 	if p.Min > p.Max {
 		mErr = multierror.Append(mErr, errors.New("policy Min must not be greater Max"))
 	}
+	if p.EvaluationInterval < 0 {
+		mErr = multierror.Append(mErr, errors.New("policy EvaluationInterval can't be negative"))
+	}
+	if p.Cooldown < 0 {
+		mErr = multierror.Append(mErr, errors.New("policy Cooldown can't be negative"))
+	}
+	if err := ValidateChecksWhen(p); err != nil {
+		mErr = multierror.Append(mErr, err)
+	}
+	if err := ValidateQueryTemplates(p); err != nil {
+		mErr = multierror.Append(mErr, err)
+	}
 
 	return mErr.ErrorOrNil()
 }
@@ -127,12 +145,26 @@ func (pr *Processor) CanonicalizeAPMQuery(c *sdk.ScalingPolicyCheck, t *sdk.Scal
 	}
 
 	// If the target is a Nomad client node pool, format the query in the
-	// expected manner. Once the autoscaler supports more than just class
-	// identification of pools this func and logic will need to be updated. For
-	// now keep it simple.
+	// expected manner. Targets identified only by class use the original
+	// query format, otherwise a selector is built from all the identifiers
+	// so the query is scoped in the same way as the target.
 	if t.IsNodePoolTarget() {
-		c.Query = fmt.Sprintf("%s_%s/%s/class",
-			nomadAPM.QueryTypeNode, c.Query, t.Config[sdk.TargetConfigKeyClass])
+		class, hasClass := t.Config[sdk.TargetConfigKeyClass]
+		_, hasDC := t.Config[sdk.TargetConfigKeyDatacenter]
+		_, hasPool := t.Config[sdk.TargetConfigKeyNodePool]
+
+		if hasClass && !hasDC && !hasPool {
+			c.Query = fmt.Sprintf("%s_%s/%s/class", nomadAPM.QueryTypeNode, c.Query, class)
+			return
+		}
+
+		var selectors []string
+		for _, key := range []string{sdk.TargetConfigKeyClass, sdk.TargetConfigKeyDatacenter, sdk.TargetConfigKeyNodePool} {
+			if val, ok := t.Config[key]; ok {
+				selectors = append(selectors, key+":"+val)
+			}
+		}
+		c.Query = fmt.Sprintf("%s_%s/%s", nomadAPM.QueryTypeNode, c.Query, strings.Join(selectors, ","))
 	}
 }
 
@@ -81,6 +81,33 @@ func TestProcessor_ValidatePolicy(t *testing.T) {
 			},
 			name: "negative maximum value which is lower than minimum",
 		},
+		{
+			inputPolicy: &sdk.ScalingPolicy{
+				ID:                 "ce888afe-3dd2-144c-7227-74644434f708",
+				Min:                1,
+				Max:                10,
+				EvaluationInterval: -10 * time.Second,
+				Cooldown:           -time.Second,
+			},
+			expectedOutput: &multierror.Error{
+				Errors: []error{
+					errors.New("policy EvaluationInterval can't be negative"),
+					errors.New("policy Cooldown can't be negative"),
+				},
+			},
+			name: "negative evaluation interval and cooldown",
+		},
+		{
+			inputPolicy: &sdk.ScalingPolicy{
+				ID:                 "ce888afe-3dd2-144c-7227-74644434f708",
+				Min:                1,
+				Max:                10,
+				EvaluationInterval: 500 * time.Millisecond,
+				Cooldown:           10 * time.Second,
+			},
+			expectedOutput: nil,
+			name:           "valid sub-minute evaluation interval and cooldown",
+		},
 		{
 			inputPolicy: &sdk.ScalingPolicy{
 				ID:  "ce888afe-3dd2-144c-7227-74644434f708",
@@ -208,6 +235,23 @@ func TestProcessor_CanonicalizeAPMQuery(t *testing.T) {
 			},
 			name: "correctly formatted node target short query",
 		},
+		{
+			inputCheck: &sdk.ScalingPolicyCheck{
+				Name:   "random-check",
+				Source: "nomad-apm",
+				Query:  "percentage-allocated_memory",
+			},
+			inputAPMNames: []string{"nomad-apm"},
+			inputTarget: &sdk.ScalingPolicyTarget{
+				Config: map[string]string{"node_pool": "gpu", "datacenter": "dc1"},
+			},
+			expectedOutputCheck: &sdk.ScalingPolicyCheck{
+				Name:   "random-check",
+				Source: "nomad-apm",
+				Query:  "node_percentage-allocated_memory/datacenter:dc1,node_pool:gpu",
+			},
+			name: "correctly formatted node pool and datacenter target short query",
+		},
 		{
 			inputCheck: &sdk.ScalingPolicyCheck{
 				Name:   "random-check",
@@ -315,6 +359,45 @@ func TestProcessor_ApplyPolicyDefaults(t *testing.T) {
 			},
 			name: "neither set to default",
 		},
+		{
+			inputPolicy: &sdk.ScalingPolicy{
+				Checks: []*sdk.ScalingPolicyCheck{
+					{Name: "default"},
+					{Name: "check", QueryWindowOffset: time.Minute},
+				},
+			},
+			inputDefaults: &ConfigDefaults{
+				DefaultQueryWindowOffset: 2 * time.Minute,
+			},
+			expectedOutputPolicy: &sdk.ScalingPolicy{
+				QueryWindowOffset: 2 * time.Minute,
+				Checks: []*sdk.ScalingPolicyCheck{
+					{Name: "default", QueryWindow: DefaultQueryWindow, QueryWindowOffset: 2 * time.Minute},
+					{Name: "check", QueryWindow: DefaultQueryWindow, QueryWindowOffset: time.Minute},
+				},
+			},
+			name: "query window offset set to agent default",
+		},
+		{
+			inputPolicy: &sdk.ScalingPolicy{
+				QueryWindowOffset: 5 * time.Minute,
+				Checks: []*sdk.ScalingPolicyCheck{
+					{Name: "default"},
+					{Name: "check", QueryWindowOffset: time.Minute},
+				},
+			},
+			inputDefaults: &ConfigDefaults{
+				DefaultQueryWindowOffset: 2 * time.Minute,
+			},
+			expectedOutputPolicy: &sdk.ScalingPolicy{
+				QueryWindowOffset: 5 * time.Minute,
+				Checks: []*sdk.ScalingPolicyCheck{
+					{Name: "default", QueryWindow: DefaultQueryWindow, QueryWindowOffset: 5 * time.Minute},
+					{Name: "check", QueryWindow: DefaultQueryWindow, QueryWindowOffset: time.Minute},
+				},
+			},
+			name: "query window offset set to policy value",
+		},
 	}
 
 	for _, tc := range testCases {
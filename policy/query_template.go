@@ -0,0 +1,94 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package policy
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+
+	multierror "github.com/hashicorp/go-multierror"
+	"github.com/hashicorp/nomad-autoscaler/sdk"
+)
+
+// queryTemplateDelim is the action delimiter of check query templates.
+// Queries that don't contain it are used as is.
+const queryTemplateDelim = "{{"
+
+// parseQueryTemplate parses the query of a check as a Go template. Missing
+// map keys are errors so typos in variable names are not silently rendered
+// as empty values.
+func parseQueryTemplate(c *sdk.ScalingPolicyCheck) (*template.Template, error) {
+	return template.New(c.Name).Option("missingkey=error").Parse(c.Query)
+}
+
+// queryTemplateData returns the values available to check query templates:
+//
+//   - policy: the policy ID, Type, Min, Max and Meta.
+//   - target: the target config, such as .target.Job or .target.Group.
+//   - check: the check Name and Group.
+func queryTemplateData(p *sdk.ScalingPolicy, c *sdk.ScalingPolicyCheck) map[string]any {
+	meta := p.Meta
+	if meta == nil {
+		meta = map[string]string{}
+	}
+
+	target := map[string]string{}
+	if p.Target != nil && p.Target.Config != nil {
+		target = p.Target.Config
+	}
+
+	return map[string]any{
+		"policy": map[string]any{
+			"ID":   p.ID,
+			"Type": p.Type,
+			"Min":  p.Min,
+			"Max":  p.Max,
+			"Meta": meta,
+		},
+		"target": target,
+		"check": map[string]any{
+			"Name":  c.Name,
+			"Group": c.Group,
+		},
+	}
+}
+
+// RenderQuery returns the query of the check with its template actions, such
+// as {{ .policy.ID }} or {{ .target.Group }}, interpolated using the values
+// of the policy. Queries without template actions are returned unchanged.
+func RenderQuery(p *sdk.ScalingPolicy, c *sdk.ScalingPolicyCheck) (string, error) {
+	if !strings.Contains(c.Query, queryTemplateDelim) {
+		return c.Query, nil
+	}
+
+	tmpl, err := parseQueryTemplate(c)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse query template: %v", err)
+	}
+
+	var b strings.Builder
+	if err := tmpl.Execute(&b, queryTemplateData(p, c)); err != nil {
+		return "", fmt.Errorf("failed to render query template: %v", err)
+	}
+	return b.String(), nil
+}
+
+// ValidateQueryTemplates validates the syntax of the check query templates.
+// The templates are only rendered when the checks are evaluated since the
+// target config may still be modified by policy mutators.
+func ValidateQueryTemplates(p *sdk.ScalingPolicy) error {
+	var mErr *multierror.Error
+
+	for _, c := range p.Checks {
+		if !strings.Contains(c.Query, queryTemplateDelim) {
+			continue
+		}
+		if _, err := parseQueryTemplate(c); err != nil {
+			mErr = multierror.Append(mErr, fmt.Errorf("invalid query template in check %s: %v", c.Name, err))
+		}
+	}
+
+	return mErr.ErrorOrNil()
+}
@@ -0,0 +1,86 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package policy
+
+import (
+	"testing"
+
+	"github.com/hashicorp/nomad-autoscaler/sdk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderQuery(t *testing.T) {
+	p := &sdk.ScalingPolicy{
+		ID:   "policy1",
+		Type: sdk.ScalingPolicyTypeHorizontal,
+		Min:  1,
+		Max:  10,
+		Meta: map[string]string{"team": "payments"},
+		Target: &sdk.ScalingPolicyTarget{
+			Config: map[string]string{"Job": "api", "Group": "web"},
+		},
+	}
+
+	testCases := []struct {
+		name          string
+		query         string
+		expected      string
+		expectedError string
+	}{
+		{
+			name:     "no template",
+			query:    `sum(rate(http_requests{job="api"}[1m]))`,
+			expected: `sum(rate(http_requests{job="api"}[1m]))`,
+		},
+		{
+			name:     "policy and target values",
+			query:    `avg(cpu{policy="{{ .policy.ID }}",group="{{ .target.Group }}",team="{{ .policy.Meta.team }}"})`,
+			expected: `avg(cpu{policy="policy1",group="web",team="payments"})`,
+		},
+		{
+			name:     "check values",
+			query:    `{{ .check.Name }}/{{ .policy.Max }}`,
+			expected: "cpu/10",
+		},
+		{
+			name:          "missing key",
+			query:         `cpu{owner="{{ .policy.Meta.owner }}"}`,
+			expectedError: `failed to render query template`,
+		},
+		{
+			name:          "invalid syntax",
+			query:         `cpu{group="{{ .target.Group "}`,
+			expectedError: `failed to parse query template`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := RenderQuery(p, &sdk.ScalingPolicyCheck{Name: "cpu", Query: tc.query})
+			if tc.expectedError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, got)
+		})
+	}
+}
+
+func TestValidateQueryTemplates(t *testing.T) {
+	p := &sdk.ScalingPolicy{
+		Checks: []*sdk.ScalingPolicyCheck{
+			{Name: "cpu", Query: `cpu{group="{{ .target.Group }}"}`},
+			{Name: "mem", Query: `mem{group="{{ .target.Group"}`},
+			{Name: "plain", Query: "avg_cpu"},
+		},
+	}
+
+	err := ValidateQueryTemplates(p)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid query template in check mem")
+	assert.NotContains(t, err.Error(), "check cpu")
+}
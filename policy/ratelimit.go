@@ -0,0 +1,99 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package policy
+
+import (
+	"sync"
+	"time"
+)
+
+// actionLimiter bounds the number of scaling actions each target receives
+// within a period using a token bucket per target. Targets are identified
+// by the same key used to detect conflicting policies, so policies scaling
+// the same resource share a bucket. This protects targets from being scaled
+// too often when several policies or very short cooldowns are in place.
+type actionLimiter struct {
+	lock sync.Mutex
+
+	// max is the number of tokens in a full bucket, and period is the time
+	// it takes to refill an empty bucket.
+	max    float64
+	period time.Duration
+
+	buckets map[string]*actionBucket
+
+	// now returns the current time. It can be overwritten in tests.
+	now func() time.Time
+}
+
+// actionBucket is the token bucket of a single target.
+type actionBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// newActionLimiter returns an actionLimiter that allows max actions per
+// period. A nil limiter is returned if max or period are not positive.
+func newActionLimiter(max int, period time.Duration) *actionLimiter {
+	if max <= 0 || period <= 0 {
+		return nil
+	}
+
+	return &actionLimiter{
+		max:     float64(max),
+		period:  period,
+		buckets: make(map[string]*actionBucket),
+		now:     time.Now,
+	}
+}
+
+// take consumes a token from the buckets of the targets identified by keys.
+// If any of the buckets is empty, no token is consumed and it returns false
+// along with the time until all the buckets have a token available. A nil
+// limiter allows all actions.
+func (l *actionLimiter) take(keys ...string) (bool, time.Duration) {
+	if l == nil {
+		return true, 0
+	}
+
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	now := l.now()
+	rate := l.max / float64(l.period)
+
+	buckets := make([]*actionBucket, len(keys))
+	var wait time.Duration
+
+	for i, key := range keys {
+		b, ok := l.buckets[key]
+		if !ok {
+			b = &actionBucket{tokens: l.max, last: now}
+			l.buckets[key] = b
+		}
+
+		// Refill the bucket in proportion to the time since it was last used.
+		b.tokens += float64(now.Sub(b.last)) * rate
+		if b.tokens > l.max {
+			b.tokens = l.max
+		}
+		b.last = now
+
+		if b.tokens < 1 {
+			if w := time.Duration((1 - b.tokens) / rate); w > wait {
+				wait = w
+			}
+		}
+		buckets[i] = b
+	}
+
+	if wait > 0 {
+		return false, wait
+	}
+
+	for _, b := range buckets {
+		b.tokens--
+	}
+	return true, 0
+}
@@ -0,0 +1,91 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package policy
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestActionLimiter_take(t *testing.T) {
+	now := time.Date(2024, 6, 1, 14, 30, 0, 0, time.UTC)
+
+	l := newActionLimiter(2, time.Hour)
+	l.now = func() time.Time { return now }
+
+	// A new target has a full bucket.
+	ok, _ := l.take("target-a")
+	assert.True(t, ok)
+	ok, _ = l.take("target-a")
+	assert.True(t, ok)
+
+	// The bucket is empty, with a token every 30 minutes.
+	ok, wait := l.take("target-a")
+	assert.False(t, ok)
+	assert.Equal(t, 30*time.Minute, wait)
+
+	// Other targets have their own bucket.
+	ok, _ = l.take("target-b")
+	assert.True(t, ok)
+
+	// Tokens are refilled over time.
+	now = now.Add(20 * time.Minute)
+	ok, wait = l.take("target-a")
+	assert.False(t, ok)
+	assert.Equal(t, 10*time.Minute, wait.Round(time.Second))
+
+	now = now.Add(10 * time.Minute)
+	ok, _ = l.take("target-a")
+	assert.True(t, ok)
+
+	// Buckets don't fill past their size.
+	now = now.Add(24 * time.Hour)
+	for i := 0; i < 2; i++ {
+		ok, _ = l.take("target-a")
+		assert.True(t, ok)
+	}
+	ok, _ = l.take("target-a")
+	assert.False(t, ok)
+}
+
+func TestActionLimiter_take_multipleKeys(t *testing.T) {
+	now := time.Date(2024, 6, 1, 14, 30, 0, 0, time.UTC)
+
+	l := newActionLimiter(1, time.Hour)
+	l.now = func() time.Time { return now }
+
+	ok, _ := l.take("target-a")
+	assert.True(t, ok)
+
+	// No token is consumed unless all the buckets have one.
+	ok, wait := l.take("target-a", "target-b")
+	assert.False(t, ok)
+	assert.Equal(t, time.Hour, wait)
+
+	ok, _ = l.take("target-b")
+	assert.True(t, ok)
+
+	// The longest wait is returned.
+	now = now.Add(30 * time.Minute)
+	ok, wait = l.take("target-a", "target-b", "target-c")
+	assert.False(t, ok)
+	assert.Equal(t, 30*time.Minute, wait.Round(time.Second))
+
+	ok, _ = l.take("target-c")
+	assert.True(t, ok)
+}
+
+func TestActionLimiter_disabled(t *testing.T) {
+	assert.Nil(t, newActionLimiter(0, time.Hour))
+	assert.Nil(t, newActionLimiter(5, 0))
+
+	var l *actionLimiter
+	for i := 0; i < 10; i++ {
+		ok, wait := l.take("target-a")
+		assert.True(t, ok)
+		assert.Zero(t, wait)
+	}
+}
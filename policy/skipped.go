@@ -0,0 +1,43 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package policy
+
+import (
+	metrics "github.com/armon/go-metrics"
+)
+
+// EvaluationSkipReason describes why a policy evaluation didn't result in a
+// scaling action being submitted to the target.
+type EvaluationSkipReason string
+
+const (
+	// EvaluationSkipReasonCooldown indicates the policy was due for
+	// evaluation while in cooldown.
+	EvaluationSkipReasonCooldown EvaluationSkipReason = "cooldown"
+
+	// EvaluationSkipReasonTargetNotReady indicates the target reported it was
+	// not ready to be scaled.
+	EvaluationSkipReasonTargetNotReady EvaluationSkipReason = "target_not_ready"
+
+	// EvaluationSkipReasonNoMetrics indicates a check query didn't return any
+	// metric, so the check couldn't make a decision.
+	EvaluationSkipReasonNoMetrics EvaluationSkipReason = "no_metrics"
+
+	// EvaluationSkipReasonDryRun indicates the scaling action was not applied
+	// because the policy target is in dry-run mode.
+	EvaluationSkipReasonDryRun EvaluationSkipReason = "dry_run"
+
+	// EvaluationSkipReasonInFlight indicates another evaluation of the policy
+	// was already running.
+	EvaluationSkipReasonInFlight EvaluationSkipReason = "in_flight"
+)
+
+// IncrEvaluationSkipped increments the counter of skipped evaluations for
+// the reason. The labels identify the policy, and the reason is added to
+// them so operators investigating why a policy isn't scaling can break the
+// count down.
+func IncrEvaluationSkipped(labels []metrics.Label, reason EvaluationSkipReason) {
+	labels = append(labels[:len(labels):len(labels)], metrics.Label{Name: "reason", Value: string(reason)})
+	metrics.IncrCounterWithLabels([]string{"scale", "evaluate", "skipped"}, 1, labels)
+}
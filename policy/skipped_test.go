@@ -0,0 +1,45 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package policy
+
+import (
+	"testing"
+	"time"
+
+	metrics "github.com/armon/go-metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIncrEvaluationSkipped(t *testing.T) {
+	sink := metrics.NewInmemSink(time.Hour, time.Hour)
+	cfg := metrics.DefaultConfig("nomad-autoscaler")
+	cfg.EnableHostname = false
+	_, err := metrics.NewGlobal(cfg, sink)
+	require.NoError(t, err)
+
+	labels := make([]metrics.Label, 1, 2)
+	labels[0] = metrics.Label{Name: "policy_id", Value: "policy1"}
+
+	IncrEvaluationSkipped(labels, EvaluationSkipReasonCooldown)
+	IncrEvaluationSkipped(labels, EvaluationSkipReasonCooldown)
+	IncrEvaluationSkipped(labels, EvaluationSkipReasonTargetNotReady)
+
+	// The labels passed, including their spare capacity, must not be
+	// modified.
+	assert.Len(t, labels, 1)
+	assert.Equal(t, metrics.Label{}, labels[:2][1])
+
+	data := sink.Data()
+	require.NotEmpty(t, data)
+
+	counters := data[0].Counters
+	cooldown, ok := counters["nomad-autoscaler.scale.evaluate.skipped;policy_id=policy1;reason=cooldown"]
+	require.True(t, ok, "missing counter, got %v", counters)
+	assert.Equal(t, 2, cooldown.Count)
+
+	notReady, ok := counters["nomad-autoscaler.scale.evaluate.skipped;policy_id=policy1;reason=target_not_ready"]
+	require.True(t, ok, "missing counter, got %v", counters)
+	assert.Equal(t, 1, notReady.Count)
+}
@@ -19,6 +19,7 @@ const DefaultQueryWindow = time.Minute
 type ConfigDefaults struct {
 	DefaultEvaluationInterval time.Duration
 	DefaultCooldown           time.Duration
+	DefaultQueryWindowOffset  time.Duration
 }
 
 type MonitorIDsReq struct {
@@ -71,6 +72,10 @@ const (
 	// SourceNameFile is the source for policies that are loaded from disk.
 	SourceNameFile SourceName = "file"
 
+	// SourceNameNodePool is the source for cluster policies that are attached
+	// to Nomad node pools.
+	SourceNameNodePool SourceName = "node_pool"
+
 	// SourceNameHA is the source for HA policy sources
 	SourceNameHA SourceName = "ha"
 )
@@ -0,0 +1,158 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package policy
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	multierror "github.com/hashicorp/go-multierror"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/nomad-autoscaler/sdk"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// The variables available to check when expressions.
+const (
+	whenVarTime   = "time"
+	whenVarTarget = "target"
+	whenVarMetric = "metric"
+)
+
+// WhenContext holds the values available to the when expression of a check.
+type WhenContext struct {
+	// Time is the time of the evaluation, available as time.hour,
+	// time.minute and time.weekday, the lowercase name of the day.
+	Time time.Time
+
+	// TargetCount is the current count of the target, available as
+	// target.count.
+	TargetCount int64
+
+	// Metrics are the last metric values returned by the checks of the
+	// policy that don't have a when expression, available as
+	// metric.<check name>.
+	Metrics map[string]float64
+}
+
+// parseWhen parses the when expression of a check. Expressions use the HCL
+// syntax and must evaluate to a boolean.
+func parseWhen(expr string) (hcl.Expression, error) {
+	e, diags := hclsyntax.ParseExpression([]byte(expr), "when", hcl.InitialPos)
+	if diags.HasErrors() {
+		return nil, fmt.Errorf("failed to parse expression: %v", diags.Error())
+	}
+	return e, nil
+}
+
+// ValidateChecksWhen validates the when expressions of the policy checks.
+func ValidateChecksWhen(p *sdk.ScalingPolicy) error {
+	var mErr *multierror.Error
+
+	for _, c := range p.Checks {
+		if c.When == "" {
+			continue
+		}
+		if err := validateWhen(p, c); err != nil {
+			mErr = multierror.Append(mErr, fmt.Errorf("invalid when expression in check %s: %v", c.Name, err))
+		}
+	}
+
+	return mErr.ErrorOrNil()
+}
+
+// validateWhen validates the when expression of the check, including the
+// variables it references.
+func validateWhen(p *sdk.ScalingPolicy, c *sdk.ScalingPolicyCheck) error {
+	e, err := parseWhen(c.When)
+	if err != nil {
+		return err
+	}
+
+	for _, v := range e.Variables() {
+		switch root := v.RootName(); root {
+		case whenVarTime, whenVarTarget:
+		case whenVarMetric:
+			name, ok := whenMetricName(v)
+			if !ok {
+				return fmt.Errorf("%s must reference a check, such as %s.<check name>", whenVarMetric, whenVarMetric)
+			}
+			if !hasCheckWithoutWhen(p, name) {
+				return fmt.Errorf("%s.%s must reference a check of the policy without a when expression", whenVarMetric, name)
+			}
+		default:
+			return fmt.Errorf("unknown variable %q, must be one of %s, %s, or %s",
+				root, whenVarTime, whenVarTarget, whenVarMetric)
+		}
+	}
+
+	return nil
+}
+
+// EvaluateWhen evaluates the when expression of a check and returns whether
+// the check must run.
+func EvaluateWhen(expr string, wc *WhenContext) (bool, error) {
+	e, err := parseWhen(expr)
+	if err != nil {
+		return false, err
+	}
+
+	metrics := make(map[string]cty.Value, len(wc.Metrics))
+	for name, v := range wc.Metrics {
+		metrics[name] = cty.NumberFloatVal(v)
+	}
+
+	ctx := &hcl.EvalContext{
+		Variables: map[string]cty.Value{
+			whenVarTime: cty.ObjectVal(map[string]cty.Value{
+				"hour":    cty.NumberIntVal(int64(wc.Time.Hour())),
+				"minute":  cty.NumberIntVal(int64(wc.Time.Minute())),
+				"weekday": cty.StringVal(strings.ToLower(wc.Time.Weekday().String())),
+			}),
+			whenVarTarget: cty.ObjectVal(map[string]cty.Value{
+				"count": cty.NumberIntVal(wc.TargetCount),
+			}),
+			whenVarMetric: cty.ObjectVal(metrics),
+		},
+	}
+
+	val, diags := e.Value(ctx)
+	if diags.HasErrors() {
+		return false, fmt.Errorf("failed to evaluate expression: %v", diags.Error())
+	}
+	if val.IsNull() || !val.IsKnown() || val.Type() != cty.Bool {
+		return false, fmt.Errorf("expression must evaluate to a boolean, found %s", val.Type().FriendlyName())
+	}
+
+	return val.True(), nil
+}
+
+// whenMetricName returns the name of the check referenced by a metric
+// variable.
+func whenMetricName(v hcl.Traversal) (string, bool) {
+	if len(v) < 2 {
+		return "", false
+	}
+
+	switch t := v[1].(type) {
+	case hcl.TraverseAttr:
+		return t.Name, true
+	case hcl.TraverseIndex:
+		if t.Key.Type() == cty.String {
+			return t.Key.AsString(), true
+		}
+	}
+	return "", false
+}
+
+func hasCheckWithoutWhen(p *sdk.ScalingPolicy, name string) bool {
+	for _, c := range p.Checks {
+		if c.Name == name && c.When == "" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,145 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package policy
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hashicorp/nomad-autoscaler/sdk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEvaluateWhen(t *testing.T) {
+	// Saturday at 14:30.
+	wc := &WhenContext{
+		Time:        time.Date(2024, 6, 1, 14, 30, 0, 0, time.UTC),
+		TargetCount: 5,
+		Metrics:     map[string]float64{"cpu": 85, "queue-depth": 12},
+	}
+
+	testCases := []struct {
+		name          string
+		expr          string
+		expected      bool
+		expectedError string
+	}{
+		{
+			name:     "time of day",
+			expr:     "time.hour >= 9 && time.hour < 17",
+			expected: true,
+		},
+		{
+			name:     "minute",
+			expr:     "time.minute < 30",
+			expected: false,
+		},
+		{
+			name:     "day of week",
+			expr:     `time.weekday != "saturday" && time.weekday != "sunday"`,
+			expected: false,
+		},
+		{
+			name:     "target count",
+			expr:     "target.count > 3",
+			expected: true,
+		},
+		{
+			name:     "metric",
+			expr:     "metric.cpu > 80",
+			expected: true,
+		},
+		{
+			name:     "metric with index",
+			expr:     `metric["queue-depth"] < 10`,
+			expected: false,
+		},
+		{
+			name:          "missing metric",
+			expr:          "metric.mem > 80",
+			expectedError: "failed to evaluate expression",
+		},
+		{
+			name:          "not a boolean",
+			expr:          "target.count + 1",
+			expectedError: "expression must evaluate to a boolean, found number",
+		},
+		{
+			name:          "invalid syntax",
+			expr:          "time.hour >",
+			expectedError: "failed to parse expression",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := EvaluateWhen(tc.expr, wc)
+			if tc.expectedError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, got)
+		})
+	}
+}
+
+func TestValidateChecksWhen(t *testing.T) {
+	testCases := []struct {
+		name          string
+		when          string
+		expectedError string
+	}{
+		{
+			name: "valid",
+			when: `time.hour < 9 && metric.cpu < 20 && metric["cpu"] < 20 && target.count > 1`,
+		},
+		{
+			name:          "invalid syntax",
+			when:          "time.hour >",
+			expectedError: "invalid when expression in check scale-down: failed to parse expression",
+		},
+		{
+			name:          "unknown variable",
+			when:          "now.hour > 9",
+			expectedError: `unknown variable "now"`,
+		},
+		{
+			name:          "metric without check",
+			when:          "metric > 9",
+			expectedError: "metric must reference a check",
+		},
+		{
+			name:          "metric of unknown check",
+			when:          "metric.mem > 9",
+			expectedError: "metric.mem must reference a check of the policy without a when expression",
+		},
+		{
+			name:          "metric of check with when",
+			when:          `metric["scale-down"] > 9`,
+			expectedError: "metric.scale-down must reference a check of the policy without a when expression",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			p := &sdk.ScalingPolicy{
+				Checks: []*sdk.ScalingPolicyCheck{
+					{Name: "cpu"},
+					{Name: "scale-down", When: tc.when},
+				},
+			}
+
+			err := ValidateChecksWhen(p)
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tc.expectedError)
+		})
+	}
+}
@@ -0,0 +1,199 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package policyeval
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	metrics "github.com/armon/go-metrics"
+	"github.com/hashicorp/nomad-autoscaler/sdk"
+	"github.com/hashicorp/nomad-autoscaler/sdk/helper/uuid"
+)
+
+// DefaultApprovalTTL is the amount of time a scaling action waits for
+// approval when the policy doesn't specify an approval_ttl.
+const DefaultApprovalTTL = time.Hour
+
+var (
+	// ErrPendingActionNotFound is returned when the requested pending action
+	// doesn't exist, either because the ID is wrong or the action has
+	// expired or been replaced by a newer one.
+	ErrPendingActionNotFound = errors.New("pending action not found")
+
+	// ErrPendingActionStale is returned when approving an action whose
+	// target count has changed since the action was computed.
+	ErrPendingActionStale = errors.New("target count changed since the action was computed")
+
+	// ErrPendingActionBusy is returned when approving an action while an
+	// evaluation of its policy is running. The action is kept in the queue
+	// so the approval can be retried.
+	ErrPendingActionBusy = errors.New("policy is being evaluated, retry the approval")
+)
+
+// PendingAction is a scaling action waiting for approval from an operator
+// before being executed.
+type PendingAction struct {
+	ID        string
+	PolicyID  string
+	Target    string
+	Check     string
+	Count     int64
+	Action    sdk.ScalingAction
+	CreatedAt time.Time
+	ExpiresAt time.Time
+
+	// execute runs the scaling action once it has been approved.
+	execute func() error
+}
+
+// sameAction returns true if both actions scale the target to the same count
+// in the same direction.
+func (a *PendingAction) sameAction(other *PendingAction) bool {
+	return a.Action.Count == other.Action.Count && a.Action.Direction == other.Action.Direction
+}
+
+// ApprovalQueue holds the scaling actions of policies that require manual
+// approval. Only the most recent action of each policy is kept, since older
+// actions were computed against stale data.
+type ApprovalQueue struct {
+	lock sync.Mutex
+
+	// actions holds the pending actions keyed by their ID.
+	actions map[string]*PendingAction
+
+	// byPolicy maps a policy ID to the ID of its pending action.
+	byPolicy map[string]string
+}
+
+// NewApprovalQueue returns a new, empty, ApprovalQueue.
+func NewApprovalQueue() *ApprovalQueue {
+	return &ApprovalQueue{
+		actions:  make(map[string]*PendingAction),
+		byPolicy: make(map[string]string),
+	}
+}
+
+// add stores the action, replacing any action already pending for the same
+// policy. If the pending action scales the target to the same count in the
+// same direction, the new action keeps its ID and expiry so operators can
+// still approve it and the approval TTL is not extended by each evaluation.
+func (q *ApprovalQueue) add(a *PendingAction, ttl time.Duration) *PendingAction {
+	if ttl <= 0 {
+		ttl = DefaultApprovalTTL
+	}
+
+	now := time.Now().UTC()
+
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	q.expireLocked(now)
+
+	a.ID = uuid.Generate()
+	a.CreatedAt = now
+	a.ExpiresAt = now.Add(ttl)
+
+	if id, ok := q.byPolicy[a.PolicyID]; ok {
+		if current := q.actions[id]; current.sameAction(a) {
+			a.ID = current.ID
+			a.CreatedAt = current.CreatedAt
+			a.ExpiresAt = current.ExpiresAt
+		}
+		delete(q.actions, id)
+	}
+	q.actions[a.ID] = a
+	q.byPolicy[a.PolicyID] = a.ID
+
+	return a
+}
+
+// restore puts back an action removed from the queue, unless it has expired
+// or a newer action was added for the policy in the meantime.
+func (q *ApprovalQueue) restore(a *PendingAction) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	if _, ok := q.byPolicy[a.PolicyID]; ok || time.Now().UTC().After(a.ExpiresAt) {
+		return
+	}
+	q.actions[a.ID] = a
+	q.byPolicy[a.PolicyID] = a.ID
+}
+
+// List returns the actions waiting for approval, oldest first.
+func (q *ApprovalQueue) List() []*PendingAction {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	q.expireLocked(time.Now().UTC())
+
+	out := make([]*PendingAction, 0, len(q.actions))
+	for _, a := range q.actions {
+		out = append(out, a)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].CreatedAt.Before(out[j].CreatedAt) })
+	return out
+}
+
+// Approve removes the action from the queue and executes it. The action is
+// removed even if its execution fails so it is not retried with stale data,
+// unless it couldn't run because its policy was being evaluated.
+func (q *ApprovalQueue) Approve(id string) (*PendingAction, error) {
+	a, err := q.remove(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := a.execute(); err != nil {
+		if errors.Is(err, ErrPendingActionBusy) {
+			q.restore(a)
+		}
+		return a, err
+	}
+
+	metrics.IncrCounter([]string{"scale", "approval", "approved_count"}, 1)
+	return a, nil
+}
+
+// Reject removes the action from the queue without executing it.
+func (q *ApprovalQueue) Reject(id string) (*PendingAction, error) {
+	a, err := q.remove(id)
+	if err != nil {
+		return nil, err
+	}
+
+	metrics.IncrCounter([]string{"scale", "approval", "rejected_count"}, 1)
+	return a, nil
+}
+
+func (q *ApprovalQueue) remove(id string) (*PendingAction, error) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	q.expireLocked(time.Now().UTC())
+
+	a, ok := q.actions[id]
+	if !ok {
+		return nil, ErrPendingActionNotFound
+	}
+
+	delete(q.actions, id)
+	delete(q.byPolicy, a.PolicyID)
+	return a, nil
+}
+
+// expireLocked removes the actions that have not been approved in time. The
+// lock must be held when calling it.
+func (q *ApprovalQueue) expireLocked(now time.Time) {
+	for id, a := range q.actions {
+		if now.After(a.ExpiresAt) {
+			delete(q.actions, id)
+			delete(q.byPolicy, a.PolicyID)
+			metrics.IncrCounter([]string{"scale", "approval", "expired_count"}, 1)
+		}
+	}
+}
@@ -0,0 +1,215 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package policyeval
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	hclog "github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/nomad-autoscaler/agent/config"
+	"github.com/hashicorp/nomad-autoscaler/plugins"
+	"github.com/hashicorp/nomad-autoscaler/plugins/manager"
+	"github.com/hashicorp/nomad-autoscaler/sdk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApprovalQueue(t *testing.T) {
+	q := NewApprovalQueue()
+
+	executed := 0
+	newAction := func(policyID string, count int64) *PendingAction {
+		return &PendingAction{
+			PolicyID: policyID,
+			Action:   sdk.ScalingAction{Count: count, Direction: sdk.ScaleDirectionUp},
+			execute: func() error {
+				executed++
+				return nil
+			},
+		}
+	}
+
+	first := q.add(newAction("a", 3), 0)
+	assert.Equal(t, first.CreatedAt.Add(DefaultApprovalTTL), first.ExpiresAt)
+
+	// A new action for the same policy replaces the previous one.
+	second := q.add(newAction("a", 4), time.Minute)
+	other := q.add(newAction("b", 3), time.Minute)
+	assert.ElementsMatch(t, []*PendingAction{second, other}, q.List())
+
+	_, err := q.Approve(first.ID)
+	assert.ErrorIs(t, err, ErrPendingActionNotFound)
+
+	approved, err := q.Approve(second.ID)
+	require.NoError(t, err)
+	assert.Equal(t, second, approved)
+	assert.Equal(t, 1, executed)
+
+	rejected, err := q.Reject(other.ID)
+	require.NoError(t, err)
+	assert.Equal(t, other, rejected)
+	assert.Equal(t, 1, executed)
+	assert.Empty(t, q.List())
+}
+
+func TestApprovalQueue_expiry(t *testing.T) {
+	q := NewApprovalQueue()
+
+	a := q.add(&PendingAction{PolicyID: "a"}, time.Minute)
+	a.ExpiresAt = time.Now().UTC().Add(-time.Second)
+
+	assert.Empty(t, q.List())
+
+	_, err := q.Approve(a.ID)
+	assert.ErrorIs(t, err, ErrPendingActionNotFound)
+}
+
+func TestApprovalQueue_executeError(t *testing.T) {
+	q := NewApprovalQueue()
+
+	a := q.add(&PendingAction{
+		PolicyID: "a",
+		execute:  func() error { return ErrPendingActionStale },
+	}, time.Minute)
+
+	_, err := q.Approve(a.ID)
+	assert.True(t, errors.Is(err, ErrPendingActionStale))
+
+	// The action is discarded even if it failed to execute.
+	assert.Empty(t, q.List())
+}
+
+func TestApprovalQueue_sameAction(t *testing.T) {
+	q := NewApprovalQueue()
+
+	first := q.add(&PendingAction{
+		PolicyID: "a",
+		Check:    "cpu",
+		Action:   sdk.ScalingAction{Count: 3, Direction: sdk.ScaleDirectionUp},
+	}, time.Minute)
+
+	// The same action computed by a later evaluation keeps the ID and expiry
+	// of the pending action, but its data is refreshed.
+	refreshed := q.add(&PendingAction{
+		PolicyID: "a",
+		Check:    "memory",
+		Action:   sdk.ScalingAction{Count: 3, Direction: sdk.ScaleDirectionUp},
+	}, time.Hour)
+	assert.Equal(t, first.ID, refreshed.ID)
+	assert.Equal(t, first.CreatedAt, refreshed.CreatedAt)
+	assert.Equal(t, first.ExpiresAt, refreshed.ExpiresAt)
+	assert.Equal(t, []*PendingAction{refreshed}, q.List())
+	assert.Equal(t, "memory", q.List()[0].Check)
+
+	// A different action gets a new ID and expiry.
+	changed := q.add(&PendingAction{
+		PolicyID: "a",
+		Action:   sdk.ScalingAction{Count: 4, Direction: sdk.ScaleDirectionUp},
+	}, time.Hour)
+	assert.NotEqual(t, first.ID, changed.ID)
+	assert.Equal(t, changed.CreatedAt.Add(time.Hour), changed.ExpiresAt)
+	assert.Equal(t, []*PendingAction{changed}, q.List())
+}
+
+func TestApprovalQueue_busy(t *testing.T) {
+	q := NewApprovalQueue()
+
+	busy := true
+	a := q.add(&PendingAction{
+		PolicyID: "a",
+		execute: func() error {
+			if busy {
+				return ErrPendingActionBusy
+			}
+			return nil
+		},
+	}, time.Minute)
+
+	// The action is kept if its policy is being evaluated, so the approval
+	// can be retried.
+	_, err := q.Approve(a.ID)
+	assert.ErrorIs(t, err, ErrPendingActionBusy)
+	assert.Equal(t, []*PendingAction{a}, q.List())
+
+	busy = false
+	_, err = q.Approve(a.ID)
+	require.NoError(t, err)
+	assert.Empty(t, q.List())
+}
+
+func TestBaseWorker_parkAction_inFlight(t *testing.T) {
+	broker := NewBroker(hclog.NewNullLogger(), time.Minute, 1)
+	w := &BaseWorker{broker: broker, approvals: NewApprovalQueue()}
+
+	policy := &sdk.ScalingPolicy{ID: "policy1", Target: &sdk.ScalingPolicyTarget{Name: "target"}}
+	action := sdk.ScalingAction{Count: 3, Direction: sdk.ScaleDirectionUp}
+	w.parkAction(hclog.NewNullLogger(), policy, "cpu", action, &sdk.TargetStatus{Count: 1})
+
+	pending := w.approvals.List()
+	require.Len(t, pending, 1)
+
+	// Approved actions don't run while the policy is being evaluated.
+	eval := &sdk.ScalingEvaluation{ID: "eval1", Policy: policy}
+	started, err := broker.StartEval(eval, "token1")
+	require.NoError(t, err)
+	require.True(t, started)
+
+	_, err = w.approvals.Approve(pending[0].ID)
+	assert.ErrorIs(t, err, ErrPendingActionBusy)
+	assert.Len(t, w.approvals.List(), 1)
+
+	// The approved action releases the in-flight slot once done.
+	require.NoError(t, broker.FinishEval(eval, "token1"))
+	w.pluginManager = manager.NewPluginManager(hclog.NewNullLogger(), "", "", 0, nil)
+
+	_, err = w.approvals.Approve(pending[0].ID)
+	assert.Error(t, err)
+
+	started, err = broker.StartEval(eval, "token1")
+	require.NoError(t, err)
+	assert.True(t, started)
+}
+
+func TestBaseWorker_handlePolicy_revertApproval(t *testing.T) {
+	pm := manager.NewPluginManager(hclog.NewNullLogger(), "", "", 0, map[string][]*config.Plugin{
+		"target": {{Name: plugins.InternalTargetSimulator, Driver: plugins.InternalTargetSimulator}},
+	})
+	require.NoError(t, pm.Load())
+	defer pm.KillPlugins()
+
+	w := &BaseWorker{logger: hclog.NewNullLogger(), pluginManager: pm, approvals: NewApprovalQueue()}
+
+	policy := &sdk.ScalingPolicy{
+		ID:                "revert-approval",
+		Min:               1,
+		Max:               10,
+		OnOutOfBandChange: sdk.ScalingPolicyOnOutOfBandChangeRevert,
+		ApprovalRequired:  true,
+		Target: &sdk.ScalingPolicyTarget{
+			Name:   plugins.InternalTargetSimulator,
+			Config: map[string]string{"id": "revert-approval", "initial_count": "5"},
+		},
+	}
+	desiredCounts.record(policy, 3)
+	defer desiredCounts.remove(desiredCountKey(policy))
+
+	err := w.handlePolicy(context.Background(), &sdk.ScalingEvaluation{ID: "eval1", Policy: policy})
+	require.NoError(t, err)
+
+	// The revert waits for approval instead of scaling the target.
+	pending := w.approvals.List()
+	require.Len(t, pending, 1)
+	assert.Equal(t, int64(5), pending[0].Count)
+	assert.Equal(t, int64(3), pending[0].Action.Count)
+	assert.Equal(t, sdk.ScaleDirection(sdk.ScaleDirectionDown), pending[0].Action.Direction)
+
+	target, err := pm.GetTarget(policy.ID, policy.Target)
+	require.NoError(t, err)
+	status, err := target.Status(policy.Target.Config)
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), status.Count)
+}
@@ -25,6 +25,10 @@ import (
 // is not ready.
 var errTargetNotReady = errors.New("target not ready")
 
+// errEvaluationTimeout is used by a check handler to indicate the policy
+// evaluation timeout was reached before the check finished.
+var errEvaluationTimeout = errors.New("evaluation timeout exceeded")
+
 // Worker is responsible for executing a policy evaluation request.
 type BaseWorker struct {
 	id            string
@@ -33,10 +37,16 @@ type BaseWorker struct {
 	policyManager *policy.Manager
 	broker        *Broker
 	queue         string
+
+	// approvals holds the actions of policies that require manual approval.
+	approvals *ApprovalQueue
+
+	// explain enables the decision log for all policies.
+	explain bool
 }
 
 // NewBaseWorker returns a new BaseWorker instance.
-func NewBaseWorker(l hclog.Logger, pm *manager.PluginManager, m *policy.Manager, b *Broker, queue string) *BaseWorker {
+func NewBaseWorker(l hclog.Logger, pm *manager.PluginManager, m *policy.Manager, b *Broker, a *ApprovalQueue, explain bool, queue string) *BaseWorker {
 	id := uuid.Generate()
 
 	return &BaseWorker{
@@ -46,6 +56,8 @@ func NewBaseWorker(l hclog.Logger, pm *manager.PluginManager, m *policy.Manager,
 		policyManager: m,
 		broker:        b,
 		queue:         queue,
+		approvals:     a,
+		explain:       explain,
 	}
 }
 
@@ -76,7 +88,36 @@ func (w *BaseWorker) Run(ctx context.Context) {
 			"eval_token", token,
 			"policy_id", eval.Policy.ID)
 
-		if err := w.handlePolicy(ctx, eval); err != nil {
+		// Only one evaluation per policy can run at a time, so skip this one
+		// if a previous delivery, or the previous leader in HA mode, is still
+		// evaluating the policy.
+		started, err := w.broker.StartEval(eval, token)
+		if err != nil {
+			logger.Error("failed to start policy evaluation", "error", err)
+			if err := w.broker.Nack(eval.ID, token); err != nil {
+				logger.Warn("failed to NACK policy evaluation", "error", err)
+			}
+			continue
+		}
+		if !started {
+			logger.Info("skipping policy evaluation, another evaluation is in flight")
+			metrics.IncrCounterWithLabels([]string{"scale", "evaluate", "in_flight_skipped"}, 1,
+				policyMetricLabels(eval.Policy))
+			emitEvaluationSkipped(eval.Policy, policy.EvaluationSkipReasonInFlight)
+			if err := w.broker.Ack(eval.ID, token); err != nil {
+				logger.Warn("failed to ACK policy evaluation", "error", err)
+			}
+			continue
+		}
+
+		err = w.handlePolicy(ctx, eval)
+		w.policyManager.RecordEvaluationDone(eval.Policy.ID)
+
+		if err := w.broker.FinishEval(eval, token); err != nil {
+			logger.Warn("failed to finish policy evaluation", "error", err)
+		}
+
+		if err != nil {
 			logger.Error("failed to evaluate policy", "error", err)
 
 			// Notify broker that policy eval was not successful.
@@ -96,18 +137,24 @@ func (w *BaseWorker) Run(ctx context.Context) {
 // HandlePolicy evaluates a policy and execute a scaling action if necessary.
 func (w *BaseWorker) handlePolicy(ctx context.Context, eval *sdk.ScalingEvaluation) error {
 
+	// Vertical policies scaling multiple task resources are evaluated once
+	// per resource.
+	if eval.Policy.Type == sdk.ScalingPolicyTypeVertical {
+		return w.handleVerticalPolicy(ctx, eval)
+	}
+
 	// Record the start time of the eval portion of this function. The labels
 	// are also used across multiple metrics, so define them.
 	evalStartTime := time.Now()
-	labels := []metrics.Label{
-		{Name: "policy_id", Value: eval.Policy.ID},
-		{Name: "target_name", Value: eval.Policy.Target.Name},
-	}
+	labels := policyMetricLabels(eval.Policy)
 
-	logger := w.logger.With("policy_id", eval.Policy.ID, "target", eval.Policy.Target.Name)
+	// The evaluation ID is used as the correlation ID of the scaling
+	// decision, so it's included in every log line and action produced.
+	logger := w.logger.With("policy_id", eval.Policy.ID, "target", eval.Policy.Target.Name,
+		"correlation_id", eval.ID)
 	logger.Debug("received policy for evaluation")
 
-	target, err := w.pluginManager.GetTarget(eval.Policy.Target)
+	target, err := w.pluginManager.GetTarget(eval.Policy.ID, eval.Policy.Target)
 	if err != nil {
 		return fmt.Errorf("failed to fetch current count: %v", err)
 	}
@@ -117,7 +164,17 @@ func (w *BaseWorker) handlePolicy(ctx context.Context, eval *sdk.ScalingEvaluati
 		return fmt.Errorf("failed to get target status: %v", err)
 	}
 
+	// Record the details of the evaluation if explain is enabled. The
+	// decision log is nil otherwise, which makes all its methods no-ops.
+	var decision *decisionLog
+	if w.explain || eval.Policy.Explain {
+		decision = newDecisionLog(eval, currentStatus, evalStartTime)
+		defer decision.write(logger)
+	}
+
 	if !currentStatus.Ready {
+		emitEvaluationSkipped(eval.Policy, policy.EvaluationSkipReasonTargetNotReady)
+		decision.setOutcome("target not ready", "", nil, errTargetNotReady)
 		return errTargetNotReady
 	}
 
@@ -132,7 +189,9 @@ func (w *BaseWorker) handlePolicy(ctx context.Context, eval *sdk.ScalingEvaluati
 			Reason:    reason,
 			Direction: sdk.ScaleDirectionUp,
 		}
-		return w.scaleTarget(logger, target, eval.Policy, action, currentStatus)
+		action.SetCorrelationID(eval.ID)
+		decision.setOutcome("scale to policy min", "", &action, nil)
+		return w.scaleTarget(logger, target, eval.Policy, "", action, currentStatus, decision)
 	}
 	if currentStatus.Count > eval.Policy.Max {
 		reason := fmt.Sprintf("scaling down because current count %d is greater than policy max value of %d",
@@ -143,19 +202,79 @@ func (w *BaseWorker) handlePolicy(ctx context.Context, eval *sdk.ScalingEvaluati
 			Reason:    reason,
 			Direction: sdk.ScaleDirectionDown,
 		}
-		return w.scaleTarget(logger, target, eval.Policy, action, currentStatus)
+		action.SetCorrelationID(eval.ID)
+		decision.setOutcome("scale to policy max", "", &action, nil)
+		return w.scaleTarget(logger, target, eval.Policy, "", action, currentStatus, decision)
+	}
+
+	// Scale the target back to the last count desired by the policy if it
+	// was modified outside the autoscaler and the policy reverts such
+	// changes.
+	if action, ok := revertAction(eval.Policy, currentStatus.Count); ok {
+		action.SetCorrelationID(eval.ID)
+
+		// Reverts modify the target like any other action, so they wait for
+		// approval if the policy requires it.
+		if w.approvalRequired(eval.Policy) {
+			logger.Info("out-of-band change of the target awaiting approval to be reverted",
+				"from", currentStatus.Count, "to", action.Count)
+			decision.setOutcome("awaiting approval", "", &action, nil)
+			w.parkAction(logger, eval.Policy, "", action, currentStatus)
+			return nil
+		}
+
+		logger.Info("reverting out-of-band change of the target",
+			"from", currentStatus.Count, "to", action.Count)
+		decision.setOutcome("revert out-of-band change", "", &action, nil)
+		return w.scaleTarget(logger, target, eval.Policy, "", action, currentStatus, decision)
 	}
 
-	// Prepare handlers.
-	handlersCtx, cancel := context.WithCancel(ctx)
+	// Prepare handlers. If the policy has an evaluation timeout, the checks
+	// are stopped once it's reached so a plugin that hangs doesn't block the
+	// worker.
+	var handlersCtx context.Context
+	var cancel context.CancelFunc
+	if eval.Policy.EvaluationTimeout > 0 {
+		handlersCtx, cancel = context.WithTimeout(ctx, eval.Policy.EvaluationTimeout)
+	} else {
+		handlersCtx, cancel = context.WithCancel(ctx)
+	}
 	defer cancel()
 
 	// Store check results by group so we can compare their results together.
 	checkGroups := make(map[string][]checkResult)
 
+	// Store the last metric of each check so they can be referenced by the
+	// when expressions of the checks that run after them.
+	whenMetrics := make(map[string]float64)
+
 	// Start check handlers.
-	for _, checkEval := range eval.CheckEvaluations {
+	for _, checkEval := range orderCheckEvaluations(eval.CheckEvaluations) {
+		if when := checkEval.Check.When; when != "" {
+			run, err := policy.EvaluateWhen(when, &policy.WhenContext{
+				Time:        time.Now(),
+				TargetCount: currentStatus.Count,
+				Metrics:     whenMetrics,
+			})
+			if err != nil {
+				logger.Warn("skipping check, failed to evaluate when expression",
+					"check", checkEval.Check.Name, "error", err)
+				decision.skipCheck(checkEval.Check, err)
+				continue
+			}
+			if !run {
+				logger.Debug("skipping check, when expression is false", "check", checkEval.Check.Name)
+				decision.skipCheck(checkEval.Check, nil)
+				continue
+			}
+		}
+
 		checkHandler := newCheckHandler(logger, eval.Policy, checkEval, w.pluginManager)
+		checkHandler.maxStep = w.policyManager.Guardrails().MaxStepFor(eval.Policy.Type)
+
+		if checkEval.Check.QueryRetryBudget > 0 || checkEval.Check.QueryFallbackMaxAge > 0 {
+			checkHandler.queryState = w.policyManager.CheckQueryState(eval.Policy.ID, checkEval.Check.Name)
+		}
 
 		// Wrap target status call in a goroutine so we can listen for ctx as well.
 		var action *sdk.ScalingAction
@@ -170,10 +289,37 @@ func (w *BaseWorker) handlePolicy(ctx context.Context, eval *sdk.ScalingEvaluati
 		select {
 		case <-ctx.Done():
 			w.logger.Info("stopping worker")
+			decision.setOutcome("evaluation cancelled", "", nil, nil)
 			return nil
 		case <-doneCh:
 		}
 
+		// The check may still be running in the background, so its results
+		// must not be used.
+		if errors.Is(err, errEvaluationTimeout) {
+			metrics.IncrCounterWithLabels([]string{"scale", "evaluate", "timeout"}, 1, labels)
+			decision.setOutcome("evaluation timed out", "", nil, err)
+			return err
+		}
+
+		// Store the strategy result so it can be used in future evaluations
+		// of checks that have opted in to receiving their history.
+		if checkHandler.historyEntry != nil {
+			w.policyManager.RecordCheckHistory(eval.Policy.ID, checkEval.Check.Name,
+				checkEval.Check.HistorySize, *checkHandler.historyEntry)
+		}
+
+		// Store the query result so the metrics can be used as a fallback
+		// and failures are tracked across evaluations.
+		if checkHandler.queryResult != nil {
+			w.policyManager.RecordCheckQuery(eval.Policy.ID, checkEval.Check.Name,
+				checkHandler.queryResult.metrics, checkHandler.queryResult.err)
+		}
+
+		emitCheckMetrics(eval.Policy, checkHandler.checkEval, action, err, currentStatus.Count)
+		recordDecisionMetric(eval.Policy.ID, checkEval.Check.Name, checkHandler.checkEval.Metrics)
+		decision.addCheck(checkHandler, currentStatus.Count, action, err)
+
 		if err != nil {
 			logger.Warn("failed to run check",
 				"check", checkEval.Check.Name,
@@ -187,15 +333,21 @@ func (w *BaseWorker) handlePolicy(ctx context.Context, eval *sdk.ScalingEvaluati
 			case sdk.ScalingPolicyOnErrorIgnore:
 				continue
 			case sdk.ScalingPolicyOnErrorFail:
+				decision.setOutcome("check failed", "", nil, err)
 				return err
 			default:
 				if eval.Policy.OnCheckError == sdk.ScalingPolicyOnErrorFail {
+					decision.setOutcome("check failed", "", nil, err)
 					return err
 				}
 			}
 			continue
 		}
 
+		if m := checkHandler.checkEval.Metrics; len(m) > 0 {
+			whenMetrics[checkEval.Check.Name] = m[len(m)-1].Value
+		}
+
 		group := checkEval.Check.Group
 		checkGroups[group] = append(checkGroups[group], checkResult{
 			action:  action,
@@ -237,6 +389,7 @@ func (w *BaseWorker) handlePolicy(ctx context.Context, eval *sdk.ScalingEvaluati
 		if noneCount > 0 && noneCount == len(results) {
 			groupWinner = results[0]
 		}
+		decision.addGroup(group, results, noneCount, groupWinner)
 
 		if groupWinner.handler == nil {
 			logger.Trace(fmt.Sprintf("no winner in group %s", group))
@@ -254,8 +407,26 @@ func (w *BaseWorker) handlePolicy(ctx context.Context, eval *sdk.ScalingEvaluati
 	// tracking how long it takes to run all the checks within a policy.
 	metrics.MeasureSinceWithLabels([]string{"scale", "evaluate_ms"}, evalStartTime, labels)
 
+	var winnerName string
+	if winner.handler != nil && winner.action != nil && winner.action.Direction != sdk.ScaleDirectionNone {
+		winnerName = winner.handler.checkEval.Check.Name
+	}
+	emitCheckSelected(eval.Policy, eval.CheckEvaluations, winnerName)
+
 	if winner.handler == nil || winner.action == nil || winner.action.Direction == sdk.ScaleDirectionNone {
 		logger.Debug("no checks need to be executed")
+
+		action := sdk.ScalingAction{
+			Count:     currentStatus.Count,
+			Reason:    "no checks need to be executed",
+			Direction: sdk.ScaleDirectionNone,
+		}
+		action.SetCorrelationID(eval.ID)
+		decision.setOutcome("no action", "", &action, nil)
+		w.policyManager.RecordScaleDirection(eval.Policy.ID, action.Direction)
+		w.policyManager.RecordScalingDecision(eval.Policy.ID, &action)
+		w.sendEvent(logger, newTargetScalingEvent(eval.Policy, "", currentStatus, action, nil))
+		desiredCounts.record(eval.Policy, currentStatus.Count)
 		return nil
 	}
 
@@ -273,11 +444,25 @@ func (w *BaseWorker) handlePolicy(ctx context.Context, eval *sdk.ScalingEvaluati
 	select {
 	case <-ctx.Done():
 		w.logger.Info("stopping worker")
+		decision.setOutcome("evaluation cancelled", winnerName, winner.action, nil)
 		return nil
 	default:
 	}
 
-	err = w.scaleTarget(logger, target, eval.Policy, *winner.action, currentStatus)
+	winner.action.SetCorrelationID(eval.ID)
+	winner.action.SetCheckDetails(winnerName, winner.handler.checkEval.Check.Strategy.Name, winner.handler.checkEval.Metrics)
+	w.policyManager.RecordScalingDecision(eval.Policy.ID, winner.action)
+
+	// Park the action until an operator approves it if the policy requires
+	// manual approval.
+	if w.approvalRequired(eval.Policy) {
+		decision.setOutcome("awaiting approval", winnerName, winner.action, nil)
+		w.parkAction(logger, eval.Policy, winnerName, *winner.action, currentStatus)
+		return nil
+	}
+
+	decision.setOutcome("scale", winnerName, winner.action, nil)
+	err = w.scaleTarget(logger, target, eval.Policy, winnerName, *winner.action, currentStatus, decision)
 	if err != nil {
 		return err
 	}
@@ -287,15 +472,25 @@ func (w *BaseWorker) handlePolicy(ctx context.Context, eval *sdk.ScalingEvaluati
 }
 
 // scaleTarget performs all the necessary checks and actions necessary to scale
-// a target.
+// a target. The decision log is updated with the result of the action, and can
+// be nil if explain is not enabled.
 func (w *BaseWorker) scaleTarget(
 	logger hclog.Logger,
 	targetImpl target.Target,
 	policy *sdk.ScalingPolicy,
+	check string,
 	action sdk.ScalingAction,
 	currentStatus *sdk.TargetStatus,
+	decision *decisionLog,
 ) error {
 
+	// Track the direction of the decision so the next evaluation of policies
+	// that are scaling up can be prioritized.
+	w.policyManager.RecordScaleDirection(policy.ID, action.Direction)
+	w.policyManager.RecordHandlerScaling(policy.ID)
+	scalingOps.start(policy, action, currentStatus.Count)
+	defer scalingOps.done(policy.ID)
+
 	// If the policy is configured with dry-run:true then we set the
 	// action count to nil so its no-nop. This allows us to still
 	// submit the job, but not alter its state.
@@ -304,57 +499,244 @@ func (w *BaseWorker) scaleTarget(
 		action.SetDryRun()
 	}
 
+	// Include the policy meta so targets can attribute the scaling action.
+	action.SetPolicyMeta(policy.Meta)
+
+	metricLabels := policyMetricLabels(policy)
 	if action.Count == sdk.StrategyActionMetaValueDryRunCount {
-		logger.Debug("registering scaling event",
-			"count", currentStatus.Count, "reason", action.Reason, "meta", action.Meta)
-	} else {
-		logger.Info("scaling target",
-			"from", currentStatus.Count, "to", action.Count,
-			"reason", action.Reason, "meta", action.Meta)
+		emitDryRunSkipped(policy)
 	}
 
-	metricLabels := []metrics.Label{
-		{Name: "policy_id", Value: policy.ID},
-		{Name: "target_name", Value: policy.Target.Name},
+	// Estimate the cost impact of the action so it's visible in the logs,
+	// metrics and events.
+	if unitCost, ok := policy.Target.UnitHourlyCost(); ok && action.Count != sdk.StrategyActionMetaValueDryRunCount {
+		delta := action.SetCostEstimate(currentStatus.Count, unitCost)
+		metrics.SetGaugeWithLabels([]string{"scale", "cost", "hourly_delta"}, float32(delta), metricLabels)
+	}
+
+	// Limit the number of scaling actions the target receives, regardless of
+	// how many policies scale it. Dry-run actions don't change the target so
+	// they are not limited.
+	var err error
+	if action.Count != sdk.StrategyActionMetaValueDryRunCount {
+		if ok, wait := w.policyManager.AllowScalingAction(policy); !ok {
+			metrics.IncrCounterWithLabels([]string{"scale", "invoke", "rate_limited_count"}, 1, metricLabels)
+			err = sdk.NewTargetScalingNoOpError(
+				"target scaling action limit reached, next action allowed in %s", wait.Round(time.Second))
+		}
 	}
 
-	err := runTargetScale(targetImpl, policy, action)
+	// Cap scale out actions to the capacity reported by the target provider,
+	// so actions that can't succeed are not submitted.
+	if err == nil && action.Count != sdk.StrategyActionMetaValueDryRunCount && action.Count > currentStatus.Count {
+		err = applyTargetCapacity(logger, targetImpl, policy, &action, currentStatus.Count)
+	}
+
+	if err == nil {
+		if action.Count == sdk.StrategyActionMetaValueDryRunCount {
+			logger.Debug("registering scaling event",
+				"count", currentStatus.Count, "reason", action.Reason, "meta", action.Meta)
+		} else {
+			logger.Info("scaling target",
+				"from", currentStatus.Count, "to", action.Count,
+				"reason", action.Reason, "meta", action.Meta)
+		}
+
+		err = runTargetScale(targetImpl, policy, action)
+	}
+
+	// Publish the outcome of the scaling action, including failed and
+	// skipped actions, to the configured event sinks.
+	w.sendEvent(logger, newTargetScalingEvent(policy, check, currentStatus, action, err))
+
 	if err != nil {
+		if errors.Is(err, sdk.ErrTargetCapacityReached) {
+			metrics.IncrCounterWithLabels([]string{"scale", "invoke", "quota_reached_count"}, 1, metricLabels)
+		}
 		if _, ok := err.(*sdk.TargetScalingNoOpError); ok {
 			logger.Info("scaling action skipped", "reason", err)
+			decision.setOutcome("scaling action skipped", check, &action, err)
 			return nil
 		}
 
 		metrics.IncrCounterWithLabels([]string{"scale", "invoke", "error_count"}, 1, metricLabels)
-		return fmt.Errorf("failed to scale target: %v", err)
+		err = fmt.Errorf("failed to scale target: %v", err)
+		decision.setOutcome("scaling action failed", check, &action, err)
+		return err
 	}
 
 	logger.Debug("successfully submitted scaling action to target",
 		"desired_count", action.Count)
 	metrics.IncrCounterWithLabels([]string{"scale", "invoke", "success_count"}, 1, metricLabels)
 
+	// Track the count desired by the policy so changes made to the target by
+	// other actors can be detected. Dry-run actions don't change the target.
+	if action.Count != sdk.StrategyActionMetaValueDryRunCount {
+		desiredCounts.record(policy, action.Count)
+	}
+
 	// Enforce the cooldown after a successful scaling event.
 	w.policyManager.EnforceCooldown(policy.ID, policy.Cooldown)
+	decision.setCooldown(policy.Cooldown)
 	return nil
 }
 
+// approvalRequired returns whether the actions of the policy must be
+// approved by an operator before being applied. Dry-run actions don't modify
+// the target so they don't need approval.
+func (w *BaseWorker) approvalRequired(p *sdk.ScalingPolicy) bool {
+	return p.ApprovalRequired && w.approvals != nil && p.Target.Config["dry-run"] != "true"
+}
+
+// parkAction stores the action in the approval queue. The action is executed
+// by the worker once approved, as long as the target count hasn't changed in
+// the meantime.
+func (w *BaseWorker) parkAction(
+	logger hclog.Logger,
+	policy *sdk.ScalingPolicy,
+	check string,
+	action sdk.ScalingAction,
+	currentStatus *sdk.TargetStatus,
+) {
+	// Include the cost estimate so operators can take it into account when
+	// reviewing the action.
+	if unitCost, ok := policy.Target.UnitHourlyCost(); ok {
+		action.SetCostEstimate(currentStatus.Count, unitCost)
+	}
+
+	pending := &PendingAction{
+		PolicyID: policy.ID,
+		Target:   policy.Target.Name,
+		Check:    check,
+		Count:    currentStatus.Count,
+		Action:   action,
+	}
+
+	pending.execute = func() error {
+
+		// Approved actions take the in-flight slot of the policy, so they
+		// don't run concurrently with an evaluation of the same policy.
+		eval := &sdk.ScalingEvaluation{ID: pending.ID, Policy: policy}
+		started, err := w.broker.StartEval(eval, pending.ID)
+		if err != nil {
+			return fmt.Errorf("failed to start approved action: %v", err)
+		}
+		if !started {
+			return ErrPendingActionBusy
+		}
+		defer func() {
+			if err := w.broker.FinishEval(eval, pending.ID); err != nil {
+				logger.Warn("failed to finish approved action", "error", err)
+			}
+		}()
+
+		targetImpl, err := w.pluginManager.GetTarget(policy.ID, policy.Target)
+		if err != nil {
+			return fmt.Errorf("failed to get target: %v", err)
+		}
+
+		status, err := runTargetStatus(targetImpl, policy)
+		if err != nil {
+			return fmt.Errorf("failed to get target status: %v", err)
+		}
+		if status == nil || status.Count != pending.Count {
+			return ErrPendingActionStale
+		}
+
+		l := logger.With("pending_action_id", pending.ID)
+		defer w.policyManager.RecordEvaluationDone(policy.ID)
+		return w.scaleTarget(l, targetImpl, policy, check, action, status, nil)
+	}
+
+	w.approvals.add(pending, policy.ApprovalTTL)
+
+	logger.Info("scaling action awaiting approval",
+		"pending_action_id", pending.ID, "from", currentStatus.Count, "to", action.Count,
+		"expires_at", pending.ExpiresAt, "reason", action.Reason)
+	metrics.IncrCounterWithLabels([]string{"scale", "approval", "pending_count"}, 1, policyMetricLabels(policy))
+}
+
+// orderCheckEvaluations returns the check evaluations with the checks that
+// have a when expression last, so their expressions can reference the
+// metrics of the other checks.
+func orderCheckEvaluations(evals []*sdk.ScalingCheckEvaluation) []*sdk.ScalingCheckEvaluation {
+	ordered := make([]*sdk.ScalingCheckEvaluation, 0, len(evals))
+	for _, e := range evals {
+		if e.Check.When == "" {
+			ordered = append(ordered, e)
+		}
+	}
+	for _, e := range evals {
+		if e.Check.When != "" {
+			ordered = append(ordered, e)
+		}
+	}
+	return ordered
+}
+
 // runTargetStatus wraps the target.Status call to provide operational
 // functionality.
 func runTargetStatus(t target.Target, policy *sdk.ScalingPolicy) (*sdk.TargetStatus, error) {
 
 	// Trigger a metric measure to track latency of the call.
 	labels := []metrics.Label{{Name: "plugin_name", Value: policy.Target.Name}, {Name: "policy_id", Value: policy.ID}}
-	defer metrics.MeasureSinceWithLabels([]string{"plugin", "target", "status", "invoke_ms"}, time.Now(), labels)
+	defer measurePluginCall("target", "status", policy.Target.Name, labels, time.Now())
 
 	return t.Status(policy.Target.Config)
 }
 
+// applyTargetCapacity caps the scale out action to the capacity reported by
+// the target, if it implements the target.CapacityReporter interface. A
+// no-op error wrapping sdk.ErrTargetCapacityReached is returned when the
+// target can't be scaled out any further. Failures to read the capacity are
+// only logged, so the action is still submitted.
+func applyTargetCapacity(
+	logger hclog.Logger,
+	targetImpl target.Target,
+	policy *sdk.ScalingPolicy,
+	action *sdk.ScalingAction,
+	current int64,
+) error {
+	capacityImpl, ok := targetImpl.(target.CapacityReporter)
+	if !ok {
+		return nil
+	}
+
+	capacity, err := runTargetCapacity(capacityImpl, policy)
+	if err != nil {
+		logger.Warn("failed to get target capacity", "error", err)
+		return nil
+	}
+
+	ceiling, ok := capacity.Ceiling(current)
+	if !ok {
+		return nil
+	}
+	if ceiling <= current {
+		return sdk.NewTargetScalingNoOpError("%w: target count %d reached the provider limit of %d",
+			sdk.ErrTargetCapacityReached, current, ceiling)
+	}
+
+	action.CapCapacity(current, capacity)
+	return nil
+}
+
+// runTargetCapacity wraps the target.Capacity call to provide operational
+// functionality.
+func runTargetCapacity(c target.CapacityReporter, policy *sdk.ScalingPolicy) (*sdk.TargetCapacity, error) {
+	// Trigger a metric measure to track latency of the call.
+	labels := []metrics.Label{{Name: "plugin_name", Value: policy.Target.Name}, {Name: "policy_id", Value: policy.ID}}
+	defer measurePluginCall("target", "capacity", policy.Target.Name, labels, time.Now())
+
+	return c.Capacity(policy.Target.Config)
+}
+
 // runTargetScale wraps the target.Scale call to provide operational
 // functionality.
 func runTargetScale(targetImpl target.Target, policy *sdk.ScalingPolicy, action sdk.ScalingAction) error {
 	// Trigger a metric measure to track latency of the call.
 	labels := []metrics.Label{{Name: "plugin_name", Value: policy.Target.Name}, {Name: "policy_id", Value: policy.ID}}
-	defer metrics.MeasureSinceWithLabels([]string{"plugin", "target", "scale", "invoke_ms"}, time.Now(), labels)
+	defer measurePluginCall("target", "scale", policy.Target.Name, labels, time.Now())
 
 	return targetImpl.Scale(action, policy.Target.Config)
 }
@@ -365,6 +747,46 @@ type checkHandler struct {
 	policy        *sdk.ScalingPolicy
 	checkEval     *sdk.ScalingCheckEvaluation
 	pluginManager *manager.PluginManager
+
+	// historyEntry is the result of the strategy run. It is only populated
+	// when the check has a history size configured.
+	historyEntry *sdk.ScalingCheckHistoryEntry
+
+	// strategyAction is a copy of the action returned by the strategy and
+	// caps are the limits applied to it. They are used by the decision log.
+	strategyAction *sdk.ScalingAction
+	caps           []capOperation
+
+	// queryState is the state of the check query from previous evaluations.
+	// It is only populated when the check has a query retry budget or
+	// fallback configured.
+	queryState policy.CheckQueryState
+
+	// retryBackoff is the initial backoff between query retries. If not set,
+	// queryRetryInitialBackoff is used.
+	retryBackoff time.Duration
+
+	// queryResult is the result of the check query before any fallback. It
+	// is only populated when the check has a query retry budget or fallback
+	// configured.
+	queryResult *queryResult
+
+	// queryAttempts is the number of times the query was run and
+	// metricsFallback indicates if the last known metrics were used.
+	queryAttempts   int
+	metricsFallback bool
+
+	// seriesLabels are the labels of the series returned by the query, if
+	// the source is able to identify it.
+	seriesLabels map[string]string
+
+	// maxStep is the max step of scaling actions set by the agent
+	// guardrails. A zero value doesn't limit the action.
+	maxStep int64
+
+	// lookback is the metrics history required by the check strategy. The
+	// query range is extended when it is larger than the check query window.
+	lookback time.Duration
 }
 
 // newCheckHandler returns a new checkHandler instance.
@@ -386,26 +808,43 @@ func (h *checkHandler) start(ctx context.Context, currentStatus *sdk.TargetStatu
 	h.logger.Debug("received policy check for evaluation")
 
 	var source apm.APM
-	var strategy strategy.Strategy
+	var strategyImpl strategy.Strategy
 
-	source, err := h.pluginManager.GetAPM(h.checkEval.Check.Source)
+	source, err := h.pluginManager.GetScopedAPM(h.policy.ID, h.checkEval.Check.Source, h.checkEval.Check.SourceConfig)
 	if err != nil {
 		return nil, fmt.Errorf("failed to dispense APM plugin: %v", err)
 	}
 
+	// The strategy is dispensed before querying the source since it may
+	// require more history than the check query window.
+	strategyImpl, err = h.pluginManager.GetStrategy(h.checkEval.Check.Strategy.Name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to dispense strategy plugin: %v", err)
+	}
+
+	// Bind the plugin calls to the handler context so the RPCs in progress
+	// are cancelled when the evaluation times out, instead of only being
+	// abandoned by the selects below.
+	source = apm.WithContext(ctx, source)
+	strategyImpl = strategy.WithContext(ctx, strategyImpl)
+
+	h.lookback = h.runStrategyLookback(strategyImpl)
+
 	// Query check's APM.
 	// Wrap call in a goroutine so we can listen for ctx as well.
+	var m sdk.TimestampedMetrics
 	apmQueryDoneCh := make(chan interface{})
 	go func() {
 		defer close(apmQueryDoneCh)
-		h.checkEval.Metrics, err = h.runAPMQuery(source)
+		m, err = h.queryMetrics(ctx, source)
 	}()
 
 	select {
 	case <-ctx.Done():
-		return nil, nil
+		return nil, h.ctxErr(ctx)
 	case <-apmQueryDoneCh:
 	}
+	h.checkEval.Metrics = m
 
 	if err != nil {
 		return nil, fmt.Errorf("failed to query source: %v", err)
@@ -417,6 +856,8 @@ func (h *checkHandler) start(ctx context.Context, currentStatus *sdk.TargetStatu
 
 		if len(h.checkEval.Metrics) == 0 {
 			h.logger.Warn("no metrics available")
+			emitEvaluationSkipped(h.policy, policy.EvaluationSkipReasonNoMetrics,
+				metrics.Label{Name: "check", Value: h.checkEval.Check.Name})
 			return &sdk.ScalingAction{Direction: sdk.ScaleDirectionNone}, nil
 		}
 
@@ -430,13 +871,22 @@ func (h *checkHandler) start(ctx context.Context, currentStatus *sdk.TargetStatu
 	}
 
 	// Calculate new count using check's Strategy.
-	strategy, err = h.pluginManager.GetStrategy(h.checkEval.Check.Strategy.Name)
-	if err != nil {
-		return nil, fmt.Errorf("failed to dispense strategy plugin: %v", err)
+	h.logger.Debug("calculating new count", "count", currentStatus.Count)
+
+	// Wrap call in a goroutine so we can listen for ctx as well.
+	var runResp *sdk.ScalingCheckEvaluation
+	strategyRunDoneCh := make(chan interface{})
+	go func() {
+		defer close(strategyRunDoneCh)
+		runResp, err = h.runStrategyRun(strategyImpl, currentStatus.Count)
+	}()
+
+	select {
+	case <-ctx.Done():
+		return nil, h.ctxErr(ctx)
+	case <-strategyRunDoneCh:
 	}
 
-	h.logger.Debug("calculating new count", "count", currentStatus.Count)
-	runResp, err := h.runStrategyRun(strategy, currentStatus.Count)
 	if err != nil {
 		return nil, fmt.Errorf("failed to execute strategy: %v", err)
 	}
@@ -445,6 +895,21 @@ func (h *checkHandler) start(ctx context.Context, currentStatus *sdk.TargetStatu
 	}
 
 	h.checkEval = runResp
+	if h.checkEval.Action != nil {
+		h.strategyAction = copyAction(h.checkEval.Action)
+	}
+
+	if h.checkEval.Check.HistorySize > 0 && h.checkEval.Action != nil {
+		entry := sdk.ScalingCheckHistoryEntry{
+			Timestamp: time.Now().UTC(),
+			Count:     currentStatus.Count,
+			Action:    *h.checkEval.Action,
+		}
+		if len(h.checkEval.Metrics) > 0 {
+			entry.Metric = h.checkEval.Metrics[len(h.checkEval.Metrics)-1].Value
+		}
+		h.historyEntry = &entry
+	}
 
 	if h.checkEval.Action.Direction == sdk.ScaleDirectionNone {
 		// Make sure we are currently within [min, max] limits even if there's
@@ -477,7 +942,24 @@ func (h *checkHandler) start(ctx context.Context, currentStatus *sdk.TargetStatu
 	h.checkEval.Action.Canonicalize()
 
 	// Make sure new count value is within [min, max] limits
-	h.checkEval.Action.CapCount(h.policy.Min, h.policy.Max)
+	h.applyCap("min_max", func(a *sdk.ScalingAction) { a.CapCount(h.policy.Min, h.policy.Max) })
+
+	// Limit how much of the target can be removed in a single action.
+	h.applyCap("max_unavailable", func(a *sdk.ScalingAction) {
+		a.CapScaleIn(currentStatus.Count, h.policy.MaxUnavailable)
+	})
+
+	// Limit scale-out to the policy budget, if the target has a known cost.
+	if unitCost, ok := h.policy.Target.UnitHourlyCost(); ok {
+		h.applyCap("max_hourly_cost", func(a *sdk.ScalingAction) {
+			a.CapHourlyCost(currentStatus.Count, unitCost, h.policy.MaxHourlyCost)
+		})
+	}
+
+	// Limit how much the count can change in a single action.
+	if h.maxStep > 0 {
+		h.applyCap("max_step", func(a *sdk.ScalingAction) { a.CapStep(currentStatus.Count, h.maxStep) })
+	}
 
 	// Skip action if count doesn't change.
 	if currentStatus.Count == h.checkEval.Action.Count {
@@ -488,24 +970,101 @@ func (h *checkHandler) start(ctx context.Context, currentStatus *sdk.TargetStatu
 	return h.checkEval.Action, nil
 }
 
+// ctxErr returns the error for a check stopped by its context. Reaching the
+// policy evaluation timeout is an error, while a cancelled context means the
+// worker is stopping.
+func (h *checkHandler) ctxErr(ctx context.Context) error {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("%w after %s", errEvaluationTimeout, h.policy.EvaluationTimeout)
+	}
+	return nil
+}
+
+// applyCap applies the limit to the check action and records the count before
+// and after it for the decision log.
+func (h *checkHandler) applyCap(name string, capFn func(*sdk.ScalingAction)) {
+	from := h.checkEval.Action.Count
+	capFn(h.checkEval.Action)
+	h.caps = append(h.caps, capOperation{
+		Name:    name,
+		From:    from,
+		To:      h.checkEval.Action.Count,
+		Applied: from != h.checkEval.Action.Count,
+	})
+}
+
 // runAPMQuery wraps the apm.Query call to provide operational functionality.
 func (h *checkHandler) runAPMQuery(apmImpl apm.APM) (sdk.TimestampedMetrics, error) {
 	if h.checkEval.Check.Query == "" {
 		return nil, nil
 	}
 
-	h.logger.Debug("querying source", "query", h.checkEval.Check.Query, "source", h.checkEval.Check.Source)
+	query, err := policy.RenderQuery(h.policy, h.checkEval.Check)
+	if err != nil {
+		return nil, err
+	}
+
+	h.logger.Debug("querying source", "query", query, "source", h.checkEval.Check.Source)
 
 	// Trigger a metric measure to track latency of the call.
 	labels := []metrics.Label{{Name: "plugin_name", Value: h.checkEval.Check.Source}, {Name: "policy_id", Value: h.policy.ID}}
-	defer metrics.MeasureSinceWithLabels([]string{"plugin", "apm", "query", "invoke_ms"}, time.Now(), labels)
+	defer measurePluginCall("apm", "query", h.checkEval.Check.Source, labels, time.Now())
 
 	// Calculate query range from the query window defined in the check.
 	to := time.Now().Add(-h.checkEval.Check.QueryWindowOffset)
 	from := to.Add(-h.checkEval.Check.QueryWindow)
 	r := sdk.TimeRange{From: from, To: to}
 
-	return apmImpl.Query(h.checkEval.Check.Query, r)
+	// Strategies that require more history than the query window read the
+	// extended range. QueryMultiple is used since APMs may limit the range
+	// of single queries.
+	if h.lookback > h.checkEval.Check.QueryWindow {
+		r.From = to.Add(-h.lookback)
+		h.logger.Debug("extending query range for strategy lookback", "lookback", h.lookback)
+		m, err := queryLookback(apmImpl, query, r)
+		if err != nil {
+			return nil, err
+		}
+		h.seriesLabels = m.Labels
+		return m.Metrics, nil
+	}
+
+	// Sources able to identify the series they return include its labels,
+	// which are reported in the decision log.
+	if labeled, ok := apmImpl.(apm.LabeledAPM); ok {
+		m, err := labeled.QueryLabeled(query, r)
+		if err != nil {
+			return nil, err
+		}
+		h.seriesLabels = m.Labels
+		return m.Metrics, nil
+	}
+
+	return apmImpl.Query(query, r)
+}
+
+// runStrategyLookback returns the metrics history required by the strategy,
+// if it implements the strategy.LookbackStrategy capability. Failures are
+// logged and the check query window is used instead.
+func (h *checkHandler) runStrategyLookback(strategyImpl strategy.Strategy) time.Duration {
+	lookbackImpl, ok := strategyImpl.(strategy.LookbackStrategy)
+	if !ok {
+		return 0
+	}
+
+	// Trigger a metric measure to track latency of the call.
+	labels := []metrics.Label{
+		{Name: "plugin_name", Value: h.checkEval.Check.Strategy.Name},
+		{Name: "policy_id", Value: h.policy.ID},
+	}
+	defer measurePluginCall("strategy", "lookback", h.checkEval.Check.Strategy.Name, labels, time.Now())
+
+	lookback, err := lookbackImpl.Lookback(h.checkEval.Check)
+	if err != nil {
+		h.logger.Warn("failed to read strategy lookback, using query window", "error", err)
+		return 0
+	}
+	return lookback
 }
 
 // runStrategyRun wraps the strategy.Run call to provide operational functionality.
@@ -516,7 +1075,7 @@ func (h *checkHandler) runStrategyRun(strategyImpl strategy.Strategy, count int6
 		{Name: "plugin_name", Value: h.checkEval.Check.Strategy.Name},
 		{Name: "policy_id", Value: h.policy.ID},
 	}
-	defer metrics.MeasureSinceWithLabels([]string{"plugin", "strategy", "run", "invoke_ms"}, time.Now(), labels)
+	defer measurePluginCall("strategy", "run", h.checkEval.Check.Strategy.Name, labels, time.Now())
 
 	return strategyImpl.Run(h.checkEval, count)
 }
@@ -533,3 +1092,16 @@ func (c checkResult) preempt(other checkResult) checkResult {
 	}
 	return other
 }
+
+// copyAction returns a copy of the action which is not affected by changes to
+// the Meta of the original.
+func copyAction(a *sdk.ScalingAction) *sdk.ScalingAction {
+	out := *a
+	if a.Meta != nil {
+		out.Meta = make(map[string]interface{}, len(a.Meta))
+		for k, v := range a.Meta {
+			out.Meta[k] = v
+		}
+	}
+	return &out
+}
@@ -0,0 +1,144 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package policyeval
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	hclog "github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/nomad-autoscaler/plugins/target"
+	"github.com/hashicorp/nomad-autoscaler/sdk"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_checkHandler_ctxErr(t *testing.T) {
+	h := &checkHandler{
+		logger: hclog.NewNullLogger(),
+		policy: &sdk.ScalingPolicy{EvaluationTimeout: time.Millisecond},
+	}
+
+	// Reaching the evaluation timeout is an error.
+	timeoutCtx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	<-timeoutCtx.Done()
+
+	err := h.ctxErr(timeoutCtx)
+	assert.ErrorIs(t, err, errEvaluationTimeout)
+	assert.EqualError(t, err, "evaluation timeout exceeded after 1ms")
+
+	// Cancelled contexts indicate the worker is stopping.
+	cancelCtx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.NoError(t, h.ctxErr(cancelCtx))
+}
+
+func Test_orderCheckEvaluations(t *testing.T) {
+	evals := []*sdk.ScalingCheckEvaluation{
+		{Check: &sdk.ScalingPolicyCheck{Name: "off-hours", When: "time.hour < 9"}},
+		{Check: &sdk.ScalingPolicyCheck{Name: "cpu"}},
+		{Check: &sdk.ScalingPolicyCheck{Name: "queue", When: "metric.cpu > 80"}},
+		{Check: &sdk.ScalingPolicyCheck{Name: "mem"}},
+	}
+
+	var names []string
+	for _, e := range orderCheckEvaluations(evals) {
+		names = append(names, e.Check.Name)
+	}
+	assert.Equal(t, []string{"cpu", "mem", "off-hours", "queue"}, names)
+}
+
+func Test_applyTargetCapacity(t *testing.T) {
+	maxCount := int64(10)
+
+	testCases := []struct {
+		name          string
+		target        target.Target
+		current       int64
+		count         int64
+		expectedCount int64
+		expectedError string
+	}{
+		{
+			name:          "target without capability",
+			target:        &testTarget{},
+			current:       5,
+			count:         20,
+			expectedCount: 20,
+		},
+		{
+			name:          "no limits",
+			target:        &testCapacityTarget{},
+			current:       5,
+			count:         20,
+			expectedCount: 20,
+		},
+		{
+			name:          "within capacity",
+			target:        &testCapacityTarget{capacity: &sdk.TargetCapacity{MaxCount: &maxCount}},
+			current:       5,
+			count:         8,
+			expectedCount: 8,
+		},
+		{
+			name:          "capped to capacity",
+			target:        &testCapacityTarget{capacity: &sdk.TargetCapacity{MaxCount: &maxCount}},
+			current:       5,
+			count:         20,
+			expectedCount: 10,
+		},
+		{
+			name:          "quota reached",
+			target:        &testCapacityTarget{capacity: &sdk.TargetCapacity{MaxCount: &maxCount}},
+			current:       10,
+			count:         20,
+			expectedCount: 20,
+			expectedError: "provider quota reached: target count 10 reached the provider limit of 10",
+		},
+		{
+			name:          "capacity error is ignored",
+			target:        &testCapacityTarget{err: errors.New("quota API unavailable")},
+			current:       5,
+			count:         20,
+			expectedCount: 20,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			action := &sdk.ScalingAction{Count: tc.count, Meta: map[string]interface{}{}}
+			policy := &sdk.ScalingPolicy{ID: "policy", Target: &sdk.ScalingPolicyTarget{Name: "target"}}
+
+			err := applyTargetCapacity(hclog.NewNullLogger(), tc.target, policy, action, tc.current)
+			if tc.expectedError != "" {
+				assert.EqualError(t, err, tc.expectedError)
+				assert.ErrorIs(t, err, sdk.ErrTargetCapacityReached)
+				assert.IsType(t, &sdk.TargetScalingNoOpError{}, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tc.expectedCount, action.Count)
+		})
+	}
+}
+
+// testTarget is a target.Target that doesn't implement any optional
+// capability.
+type testTarget struct {
+	target.Target
+}
+
+// testCapacityTarget is a target.Target which implements the
+// target.CapacityReporter interface.
+type testCapacityTarget struct {
+	testTarget
+	capacity *sdk.TargetCapacity
+	err      error
+}
+
+func (t *testCapacityTarget) Capacity(_ map[string]string) (*sdk.TargetCapacity, error) {
+	return t.capacity, t.err
+}
@@ -86,6 +86,15 @@ type Broker struct {
 
 	// waiting tracks Dequeue requests that are blocked waiting for work.
 	waiting map[string]chan struct{}
+
+	// inflight guarantees that only one evaluation per policy runs at a
+	// time, even if the eval is redelivered while a previous delivery is
+	// still running.
+	inflight *singleFlight
+
+	// persister stores the pending evals so they survive agent restarts. It
+	// is nil when persistence is disabled.
+	persister *evalPersister
 }
 
 // unackEval tracks an unacknowledged evaluation along with the Nack timer
@@ -97,8 +106,10 @@ type unackEval struct {
 
 // NewBroker returns a new Broker object.
 func NewBroker(l hclog.Logger, timeout time.Duration, deliveryLimit int) *Broker {
+	logger := l.Named("broker")
+
 	return &Broker{
-		logger:           l.Named("broker"),
+		logger:           logger,
 		nackTimeout:      timeout,
 		deliveryLimit:    deliveryLimit,
 		pendingEvals:     make(map[string]PendingEvaluations),
@@ -106,14 +117,33 @@ func NewBroker(l hclog.Logger, timeout time.Duration, deliveryLimit int) *Broker
 		enqueuedPolicies: make(map[string]string),
 		unack:            make(map[string]*unackEval),
 		waiting:          make(map[string]chan struct{}),
+		inflight:         newSingleFlight(logger, timeout),
 	}
 }
 
+// SetEvalTokenStore sets the store where the tokens of the evaluations in
+// flight are shared with other agents. It must be called before evals are
+// dequeued.
+func (b *Broker) SetEvalTokenStore(store EvalTokenStore) {
+	b.inflight.store = store
+}
+
 // Enqueue adds an eval to the broker.
 func (b *Broker) Enqueue(eval *sdk.ScalingEvaluation) {
 	b.l.Lock()
 	defer b.l.Unlock()
+
+	previous := b.enqueuedPolicies[eval.Policy.ID]
 	b.enqueueLocked(eval, "")
+
+	// Persist the eval if it's now the pending eval of the policy, replacing
+	// the one it superseded.
+	if current := b.enqueuedPolicies[eval.Policy.ID]; current == eval.ID && previous != eval.ID {
+		b.persister.put(eval)
+		if previous != "" {
+			b.persister.delete(previous)
+		}
+	}
 }
 
 func (b *Broker) enqueueLocked(eval *sdk.ScalingEvaluation, token string) {
@@ -293,6 +323,7 @@ func (b *Broker) Ack(evalID, token string) error {
 	delete(b.unack, evalID)
 	delete(b.enqueuedEvals, evalID)
 	delete(b.enqueuedPolicies, unack.Eval.Policy.ID)
+	b.persister.delete(evalID)
 
 	b.logger.Debug("eval ack'd", "policy_id", unack.Eval.Policy.ID)
 	return nil
@@ -330,6 +361,7 @@ func (b *Broker) Nack(evalID, token string) error {
 
 		delete(b.enqueuedEvals, evalID)
 		delete(b.enqueuedPolicies, unack.Eval.Policy.ID)
+		b.persister.delete(evalID)
 		return nil
 	}
 
@@ -339,6 +371,19 @@ func (b *Broker) Nack(evalID, token string) error {
 	return nil
 }
 
+// StartEval marks the eval delivery identified by the token as running. It
+// returns false if another delivery of an eval for the same policy is still
+// running, in which case the eval must not be evaluated.
+func (b *Broker) StartEval(eval *sdk.ScalingEvaluation, token string) (bool, error) {
+	return b.inflight.acquire(eval.Policy.ID, token)
+}
+
+// FinishEval marks the eval delivery identified by the token as done, so
+// other evals for the same policy can run.
+func (b *Broker) FinishEval(eval *sdk.ScalingEvaluation, token string) error {
+	return b.inflight.release(eval.Policy.ID, token)
+}
+
 // PendingEvaluations is a list of waiting evaluations.
 // We implement the container/heap interface so that this is a
 // priority queue
@@ -351,8 +396,11 @@ func (p PendingEvaluations) Len() int {
 
 // Less is for the sorting interface. We flip the check
 // so that the "min" in the min-heap is the element with the
-// highest priority
+// highest priority. Evaluations in the priority lane always come first.
 func (p PendingEvaluations) Less(i, j int) bool {
+	if p[i].HighPriority != p[j].HighPriority {
+		return p[i].HighPriority
+	}
 	if p[i].Policy.Priority != p[j].Policy.Priority {
 		return !(p[i].Policy.Priority < p[j].Policy.Priority)
 	}
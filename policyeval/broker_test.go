@@ -190,3 +190,32 @@ func TestBroker(t *testing.T) {
 	must.Eq(t, "", token)
 	must.NoError(t, err)
 }
+
+func TestBroker_HighPriority(t *testing.T) {
+	b := NewBroker(hclog.NewNullLogger(), time.Second, 2)
+
+	now := time.Now()
+	normal := &sdk.ScalingEvaluation{
+		ID:         "normal",
+		Policy:     &sdk.ScalingPolicy{ID: "policy1", Type: "horizontal", Priority: 100},
+		CreateTime: now,
+	}
+	high := &sdk.ScalingEvaluation{
+		ID:           "high",
+		Policy:       &sdk.ScalingPolicy{ID: "policy2", Type: "horizontal", Priority: 1},
+		CreateTime:   now.Add(time.Second),
+		HighPriority: true,
+	}
+	b.Enqueue(normal)
+	b.Enqueue(high)
+
+	// Evals in the priority lane are dequeued first, regardless of the
+	// policy priority and create time.
+	e, _, err := b.Dequeue(context.Background(), "horizontal")
+	must.NoError(t, err)
+	must.Eq(t, high, e)
+
+	e, _, err = b.Dequeue(context.Background(), "horizontal")
+	must.NoError(t, err)
+	must.Eq(t, normal, e)
+}
@@ -0,0 +1,134 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package policyeval
+
+import (
+	"slices"
+	"sort"
+
+	"github.com/armon/go-metrics"
+	"github.com/hashicorp/nomad-autoscaler/policy"
+	"github.com/hashicorp/nomad-autoscaler/sdk"
+)
+
+const (
+	checkDecisionUp    = "up"
+	checkDecisionDown  = "down"
+	checkDecisionNone  = "none"
+	checkDecisionError = "error"
+)
+
+// checkDecisions are all the possible values of the decision label emitted
+// for each check.
+var checkDecisions = []string{
+	checkDecisionUp,
+	checkDecisionDown,
+	checkDecisionNone,
+	checkDecisionError,
+}
+
+// checkDecision returns the decision made by a check evaluation.
+func checkDecision(action *sdk.ScalingAction, err error) string {
+	switch {
+	case err != nil:
+		return checkDecisionError
+	case action == nil:
+		return checkDecisionNone
+	default:
+		return action.Direction.String()
+	}
+}
+
+// policyMetricLabels returns the labels used to identify a policy in metrics,
+// followed by the labels passed and the policy meta.
+func policyMetricLabels(policy *sdk.ScalingPolicy, extra ...metrics.Label) []metrics.Label {
+	labels := append([]metrics.Label{
+		{Name: "policy_id", Value: policy.ID},
+		{Name: "target_name", Value: policy.Target.Name},
+	}, extra...)
+	return appendPolicyMetaLabels(labels, policy.Meta)
+}
+
+// emitEvaluationSkipped counts an evaluation of the policy that was skipped
+// for the reason. Extra labels, such as the check, are added after the policy
+// ones.
+func emitEvaluationSkipped(p *sdk.ScalingPolicy, reason policy.EvaluationSkipReason, extra ...metrics.Label) {
+	policy.IncrEvaluationSkipped(policyMetricLabels(p, extra...), reason)
+}
+
+// emitDryRunSkipped counts a scaling action of the policy that was not
+// applied because its target is in dry-run mode.
+func emitDryRunSkipped(p *sdk.ScalingPolicy) {
+	emitEvaluationSkipped(p, policy.EvaluationSkipReasonDryRun)
+}
+
+// appendPolicyMetaLabels appends a label for each entry of the policy meta,
+// sorted by key so the labels are stable. Entries which would replace one of
+// the existing labels are skipped.
+func appendPolicyMetaLabels(labels []metrics.Label, meta map[string]string) []metrics.Label {
+	keys := make([]string, 0, len(meta))
+	for k := range meta {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		if !slices.ContainsFunc(labels, func(l metrics.Label) bool { return l.Name == k }) {
+			labels = append(labels, metrics.Label{Name: k, Value: meta[k]})
+		}
+	}
+	return labels
+}
+
+// checkMetricLabels returns the labels used to identify a check in metrics.
+func checkMetricLabels(policy *sdk.ScalingPolicy, check string) []metrics.Label {
+	return policyMetricLabels(policy, metrics.Label{Name: "check", Value: check})
+}
+
+// emitCheckMetrics emits gauges describing the result of a check evaluation:
+// the last metric value read from the APM, the count requested by the check
+// and the decision taken. The decision is emitted once per possible value,
+// with 1 set for the current decision and 0 for the others, so it can be
+// queried without knowing the previous state.
+func emitCheckMetrics(policy *sdk.ScalingPolicy, checkEval *sdk.ScalingCheckEvaluation,
+	action *sdk.ScalingAction, err error, currentCount int64) {
+
+	labels := checkMetricLabels(policy, checkEval.Check.Name)
+
+	if len(checkEval.Metrics) > 0 {
+		last := checkEval.Metrics[len(checkEval.Metrics)-1]
+		metrics.SetGaugeWithLabels([]string{"scale", "check", "metric"}, float32(last.Value), labels)
+	}
+
+	decision := checkDecision(action, err)
+	if decision != checkDecisionError {
+		desired := currentCount
+		if decision != checkDecisionNone {
+			desired = action.Count
+		}
+		metrics.SetGaugeWithLabels([]string{"scale", "check", "desired_count"}, float32(desired), labels)
+	}
+
+	for _, d := range checkDecisions {
+		var val float32
+		if d == decision {
+			val = 1
+		}
+		metrics.SetGaugeWithLabels([]string{"scale", "check", "decision"}, val,
+			append(labels, metrics.Label{Name: "decision", Value: d}))
+	}
+}
+
+// emitCheckSelected emits a gauge for each check of a policy set to 1 if the
+// check was selected to drive the scaling action and 0 otherwise.
+func emitCheckSelected(policy *sdk.ScalingPolicy, checkEvals []*sdk.ScalingCheckEvaluation, winner string) {
+	for _, checkEval := range checkEvals {
+		var val float32
+		if checkEval.Check.Name == winner {
+			val = 1
+		}
+		metrics.SetGaugeWithLabels([]string{"scale", "check", "selected"}, val,
+			checkMetricLabels(policy, checkEval.Check.Name))
+	}
+}
@@ -0,0 +1,75 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package policyeval
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/armon/go-metrics"
+	"github.com/hashicorp/nomad-autoscaler/sdk"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_checkDecision(t *testing.T) {
+	testCases := []struct {
+		name     string
+		action   *sdk.ScalingAction
+		err      error
+		expected string
+	}{
+		{
+			name:     "error",
+			action:   &sdk.ScalingAction{Direction: sdk.ScaleDirectionUp},
+			err:      errors.New("error"),
+			expected: checkDecisionError,
+		},
+		{
+			name:     "nil action",
+			expected: checkDecisionNone,
+		},
+		{
+			name:     "up",
+			action:   &sdk.ScalingAction{Direction: sdk.ScaleDirectionUp},
+			expected: checkDecisionUp,
+		},
+		{
+			name:     "down",
+			action:   &sdk.ScalingAction{Direction: sdk.ScaleDirectionDown},
+			expected: checkDecisionDown,
+		},
+		{
+			name:     "none",
+			action:   &sdk.ScalingAction{Direction: sdk.ScaleDirectionNone},
+			expected: checkDecisionNone,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, checkDecision(tc.action, tc.err))
+		})
+	}
+}
+
+func Test_checkMetricLabels(t *testing.T) {
+	policy := &sdk.ScalingPolicy{
+		ID:     "policy-1",
+		Target: &sdk.ScalingPolicyTarget{Name: "nomad-target"},
+		Meta: map[string]string{
+			"team":        "platform",
+			"cost_center": "cc-1234",
+			"policy_id":   "ignored",
+		},
+	}
+
+	expected := []metrics.Label{
+		{Name: "policy_id", Value: "policy-1"},
+		{Name: "target_name", Value: "nomad-target"},
+		{Name: "check", Value: "cpu"},
+		{Name: "cost_center", Value: "cc-1234"},
+		{Name: "team", Value: "platform"},
+	}
+	assert.Equal(t, expected, checkMetricLabels(policy, "cpu"))
+}
@@ -0,0 +1,155 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package policyeval
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/nomad-autoscaler/sdk"
+)
+
+const (
+	// decisionHistoryRetention is how long the points of the policy decision
+	// history are kept.
+	decisionHistoryRetention = 24 * time.Hour
+
+	// decisionHistoryMaxPoints is the maximum number of points kept for each
+	// series, so policies with short evaluation intervals use bounded memory.
+	decisionHistoryMaxPoints = 2880
+)
+
+const (
+	// DecisionSeriesDesiredCount and DecisionSeriesActualCount are the names
+	// of the series holding the count requested by each evaluation and the
+	// count of the target when it was evaluated.
+	DecisionSeriesDesiredCount = "desired_count"
+	DecisionSeriesActualCount  = "actual_count"
+
+	// DecisionSeriesMetricPrefix is the prefix of the series holding the
+	// last metric value used by each check, followed by the check name.
+	DecisionSeriesMetricPrefix = "metric."
+)
+
+// decisionHistory holds the recent decisions of all the policies evaluated by
+// the workers of the agent.
+var decisionHistory = newDecisionHistoryRegistry(time.Now)
+
+// DecisionSeries is the recent history of a value related to the decisions
+// of a policy, such as its desired count.
+type DecisionSeries struct {
+	PolicyID string
+	Name     string
+	Points   []DecisionPoint
+}
+
+// DecisionPoint is the value of a decision series at a point in time.
+type DecisionPoint struct {
+	Timestamp time.Time
+	Value     float64
+}
+
+// DecisionHistory returns the points recorded between from and to, inclusive,
+// of each policy decision series, sorted by policy ID and series name. A zero
+// from or to leaves the range open on that side. Series without points in the
+// range are not included.
+func DecisionHistory(from, to time.Time) []DecisionSeries {
+	return decisionHistory.series(from, to)
+}
+
+// recordDecisionEvent records the desired and actual counts of the scaling
+// event.
+func recordDecisionEvent(event *sdk.ScalingEvent) {
+	decisionHistory.record(event.PolicyID, DecisionSeriesActualCount, event.Timestamp, float64(event.Count))
+	decisionHistory.record(event.PolicyID, DecisionSeriesDesiredCount, event.Timestamp, float64(event.Action.DesiredCount()))
+}
+
+// recordDecisionMetric records the last metric value used by the check, if
+// any.
+func recordDecisionMetric(policyID, check string, m sdk.TimestampedMetrics) {
+	if len(m) == 0 {
+		return
+	}
+	decisionHistory.record(policyID, DecisionSeriesMetricPrefix+check, time.Now().UTC(), m[len(m)-1].Value)
+}
+
+// decisionSeriesKey identifies a decision series.
+type decisionSeriesKey struct {
+	policyID string
+	name     string
+}
+
+// decisionHistoryRegistry holds the points of each decision series, sorted by
+// time. Points older than the retention are discarded as new points are
+// recorded and when the series are read.
+type decisionHistoryRegistry struct {
+	lock   sync.Mutex
+	now    func() time.Time
+	points map[decisionSeriesKey][]DecisionPoint
+}
+
+func newDecisionHistoryRegistry(now func() time.Time) *decisionHistoryRegistry {
+	return &decisionHistoryRegistry{
+		now:    now,
+		points: make(map[decisionSeriesKey][]DecisionPoint),
+	}
+}
+
+// record appends a point to the series.
+func (r *decisionHistoryRegistry) record(policyID, name string, ts time.Time, value float64) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	key := decisionSeriesKey{policyID: policyID, name: name}
+	points := append(r.points[key], DecisionPoint{Timestamp: ts, Value: value})
+	if len(points) > decisionHistoryMaxPoints {
+		points = points[len(points)-decisionHistoryMaxPoints:]
+	}
+	r.points[key] = r.expire(points)
+}
+
+// series returns the points of each series within from and to.
+func (r *decisionHistoryRegistry) series(from, to time.Time) []DecisionSeries {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	var out []DecisionSeries
+	for key, points := range r.points {
+		points = r.expire(points)
+		if len(points) == 0 {
+			delete(r.points, key)
+			continue
+		}
+		r.points[key] = points
+
+		var inRange []DecisionPoint
+		for _, p := range points {
+			if (from.IsZero() || !p.Timestamp.Before(from)) && (to.IsZero() || !p.Timestamp.After(to)) {
+				inRange = append(inRange, p)
+			}
+		}
+		if len(inRange) == 0 {
+			continue
+		}
+
+		out = append(out, DecisionSeries{PolicyID: key.policyID, Name: key.name, Points: inRange})
+	}
+
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].PolicyID != out[j].PolicyID {
+			return out[i].PolicyID < out[j].PolicyID
+		}
+		return out[i].Name < out[j].Name
+	})
+	return out
+}
+
+// expire returns the points recorded within the retention. The lock must be
+// held by the caller.
+func (r *decisionHistoryRegistry) expire(points []DecisionPoint) []DecisionPoint {
+	cutoff := r.now().Add(-decisionHistoryRetention)
+	idx := sort.Search(len(points), func(i int) bool { return !points[i].Timestamp.Before(cutoff) })
+	return points[idx:]
+}
@@ -0,0 +1,56 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package policyeval
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_decisionHistoryRegistry(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	r := newDecisionHistoryRegistry(func() time.Time { return now })
+
+	r.record("policy-b", DecisionSeriesActualCount, now.Add(-2*time.Minute), 1)
+	r.record("policy-a", DecisionSeriesDesiredCount, now.Add(-2*time.Minute), 2)
+	r.record("policy-a", DecisionSeriesDesiredCount, now.Add(-time.Minute), 3)
+	r.record("policy-a", DecisionSeriesActualCount, now.Add(-time.Minute), 2)
+
+	out := r.series(time.Time{}, time.Time{})
+	require.Len(t, out, 3)
+	assert.Equal(t, "policy-a", out[0].PolicyID)
+	assert.Equal(t, DecisionSeriesActualCount, out[0].Name)
+	assert.Equal(t, DecisionSeriesDesiredCount, out[1].Name)
+	assert.Len(t, out[1].Points, 2)
+	assert.Equal(t, "policy-b", out[2].PolicyID)
+
+	// Only the points within the range are returned.
+	out = r.series(now.Add(-90*time.Second), now)
+	require.Len(t, out, 2)
+	assert.Equal(t, []DecisionPoint{{Timestamp: now.Add(-time.Minute), Value: 3}}, out[1].Points)
+
+	// Points older than the retention are discarded.
+	now = now.Add(decisionHistoryRetention - 90*time.Second)
+	out = r.series(time.Time{}, time.Time{})
+	require.Len(t, out, 2)
+	assert.Len(t, out[1].Points, 1)
+	assert.Len(t, r.points, 2)
+}
+
+func Test_decisionHistoryRegistry_maxPoints(t *testing.T) {
+	now := time.Now()
+	r := newDecisionHistoryRegistry(func() time.Time { return now })
+
+	for i := 0; i < decisionHistoryMaxPoints+10; i++ {
+		r.record("policy", DecisionSeriesDesiredCount, now, float64(i))
+	}
+
+	out := r.series(time.Time{}, time.Time{})
+	require.Len(t, out, 1)
+	require.Len(t, out[0].Points, decisionHistoryMaxPoints)
+	assert.Equal(t, 10.0, out[0].Points[0].Value)
+}
@@ -0,0 +1,308 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package policyeval
+
+import (
+	"encoding/json"
+	"sort"
+	"strconv"
+	"time"
+
+	hclog "github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/nomad-autoscaler/sdk"
+)
+
+// decisionLog explains a single policy evaluation. It is written to the agent
+// logs as JSON when explain is enabled for the policy or the agent, so
+// operators can understand why an action was, or wasn't, taken.
+//
+// All the methods are safe to call on a nil decisionLog, which allows the
+// worker to record the evaluation without checking if explain is enabled.
+type decisionLog struct {
+	PolicyID      string
+	Target        string
+	CorrelationID string
+	Timestamp     time.Time
+
+	// CurrentCount is the count of the target at the start of the
+	// evaluation and Min and Max are the policy limits.
+	CurrentCount int64
+	Min          int64
+	Max          int64
+
+	Checks []*checkDecisionLog
+	Groups []*groupDecisionLog
+
+	// Winner is the name of the check that drove the scaling action. It is
+	// empty if no check requested an action.
+	Winner string
+
+	// Outcome describes the result of the evaluation and Action is the
+	// action that was executed, parked or discarded.
+	Outcome string
+	Action  *sdk.ScalingAction
+	Error   string
+
+	Cooldown cooldownDecisionLog
+}
+
+// checkDecisionLog explains the result of a policy check.
+type checkDecisionLog struct {
+	Name   string
+	Group  string
+	Source string
+	Query  string
+
+	// Metrics summarizes the metrics used by the strategy and Labels
+	// identify the series they belong to, if the source supports them.
+	// QueryAttempts is the number of times the query was run and
+	// MetricsFallback indicates the last known metrics were used because the
+	// query failed.
+	Metrics         metricsSummary
+	Labels          map[string]string
+	QueryAttempts   int
+	MetricsFallback bool
+
+	// StrategyName, StrategyConfig, StrategyCount and StrategyHistory are
+	// the inputs of the strategy and StrategyAction is the action it
+	// returned before being capped.
+	StrategyName    string
+	StrategyConfig  map[string]string
+	StrategyCount   int64
+	StrategyHistory int
+	StrategyAction  *sdk.ScalingAction
+
+	// Caps are the limits applied to the strategy action.
+	Caps []capOperation
+
+	// Decision is the final decision of the check and Action the capped
+	// action, if any.
+	Decision string
+	Action   *sdk.ScalingAction
+	Error    string
+
+	// When is the expression that decides if the check runs.
+	When string
+}
+
+// metricsSummary summarizes the metrics returned by a check query.
+type metricsSummary struct {
+	Count int
+	From  time.Time
+	To    time.Time
+	Min   float64
+	Max   float64
+	Mean  float64
+	Last  float64
+}
+
+// capOperation records the count before and after a limit is applied to a
+// check action.
+type capOperation struct {
+	Name    string
+	From    int64
+	To      int64
+	Applied bool
+}
+
+// groupDecisionLog explains how the winner of a check group was selected.
+// Checks without a group are reported in a group with an empty name.
+type groupDecisionLog struct {
+	Name       string
+	Candidates []string
+	NoneCount  int
+	Winner     string
+}
+
+// cooldownDecisionLog explains the cooldown calculations of the policy. The
+// remaining cooldown is calculated from the last event reported by the target,
+// and Enforced is the cooldown applied after a successful scaling action.
+// Durations are formatted as strings so they are readable in the logs.
+type cooldownDecisionLog struct {
+	Period    string
+	LastEvent *time.Time
+	Elapsed   string
+	Remaining string
+	Enforced  string
+}
+
+// checkDecisionSkipped is the decision of checks skipped because of their
+// when expression. Skipped checks don't emit metrics.
+const checkDecisionSkipped = "skipped"
+
+// newDecisionLog returns the decision log of the evaluation using the current
+// status of the target.
+func newDecisionLog(eval *sdk.ScalingEvaluation, status *sdk.TargetStatus, now time.Time) *decisionLog {
+	d := &decisionLog{
+		PolicyID:      eval.Policy.ID,
+		Target:        eval.Policy.Target.Name,
+		CorrelationID: eval.ID,
+		Timestamp:     now.UTC(),
+		CurrentCount:  status.Count,
+		Min:           eval.Policy.Min,
+		Max:           eval.Policy.Max,
+		Cooldown:      cooldownDecisionLog{Period: eval.Policy.Cooldown.String()},
+	}
+
+	// Follow the same calculation as the policy handler, which is the one
+	// actually blocking the evaluations.
+	if ts, ok := status.Meta[sdk.TargetStatusMetaKeyLastEvent]; ok {
+		if lastTS, err := strconv.ParseInt(ts, 10, 64); err == nil {
+			last := time.Unix(0, lastTS).UTC()
+			elapsed := now.Sub(last)
+
+			remaining := eval.Policy.Cooldown - elapsed
+			if remaining < 0 {
+				remaining = 0
+			}
+
+			d.Cooldown.LastEvent = &last
+			d.Cooldown.Elapsed = elapsed.String()
+			d.Cooldown.Remaining = remaining.String()
+		}
+	}
+
+	return d
+}
+
+// addCheck records the result of a check handler.
+func (d *decisionLog) addCheck(h *checkHandler, count int64, action *sdk.ScalingAction, err error) {
+	if d == nil {
+		return
+	}
+
+	check := h.checkEval.Check
+	c := &checkDecisionLog{
+		Name:            check.Name,
+		Group:           check.Group,
+		Source:          check.Source,
+		Query:           check.Query,
+		When:            check.When,
+		Metrics:         summarizeMetrics(h.checkEval.Metrics),
+		Labels:          h.seriesLabels,
+		QueryAttempts:   h.queryAttempts,
+		MetricsFallback: h.metricsFallback,
+		StrategyCount:   count,
+		StrategyHistory: len(h.checkEval.History),
+		StrategyAction:  h.strategyAction,
+		Caps:            h.caps,
+		Decision:        checkDecision(action, err),
+		Action:          action,
+	}
+	if check.Strategy != nil {
+		c.StrategyName = check.Strategy.Name
+		c.StrategyConfig = check.Strategy.Config
+	}
+	if err != nil {
+		c.Error = err.Error()
+	}
+
+	d.Checks = append(d.Checks, c)
+}
+
+// skipCheck records a check skipped because its when expression is false or
+// failed to evaluate.
+func (d *decisionLog) skipCheck(check *sdk.ScalingPolicyCheck, err error) {
+	if d == nil {
+		return
+	}
+
+	c := &checkDecisionLog{
+		Name:     check.Name,
+		Group:    check.Group,
+		Source:   check.Source,
+		Query:    check.Query,
+		When:     check.When,
+		Decision: checkDecisionSkipped,
+	}
+	if err != nil {
+		c.Error = err.Error()
+	}
+
+	d.Checks = append(d.Checks, c)
+}
+
+// addGroup records the selection of the winner of a check group.
+func (d *decisionLog) addGroup(name string, results []checkResult, noneCount int, winner checkResult) {
+	if d == nil {
+		return
+	}
+
+	g := &groupDecisionLog{Name: name, NoneCount: noneCount}
+	for _, r := range results {
+		g.Candidates = append(g.Candidates, r.handler.checkEval.Check.Name)
+	}
+	if winner.handler != nil {
+		g.Winner = winner.handler.checkEval.Check.Name
+	}
+
+	d.Groups = append(d.Groups, g)
+}
+
+// setOutcome records the final result of the evaluation.
+func (d *decisionLog) setOutcome(outcome, winner string, action *sdk.ScalingAction, err error) {
+	if d == nil {
+		return
+	}
+
+	d.Outcome = outcome
+	d.Winner = winner
+	d.Action = action
+	if err != nil {
+		d.Error = err.Error()
+	}
+}
+
+// setCooldown records the cooldown enforced after a scaling action.
+func (d *decisionLog) setCooldown(t time.Duration) {
+	if d == nil {
+		return
+	}
+	d.Cooldown.Enforced = t.String()
+}
+
+// write logs the decision log as JSON. Groups are sorted by name so the
+// output is stable between evaluations.
+func (d *decisionLog) write(logger hclog.Logger) {
+	if d == nil {
+		return
+	}
+
+	sort.Slice(d.Groups, func(i, j int) bool { return d.Groups[i].Name < d.Groups[j].Name })
+
+	out, err := json.Marshal(d)
+	if err != nil {
+		logger.Warn("failed to encode policy decision log", "error", err)
+		return
+	}
+	logger.Info("policy decision log", "decision", string(out))
+}
+
+// summarizeMetrics returns the summary of the metrics, which are expected to
+// be sorted by timestamp.
+func summarizeMetrics(m sdk.TimestampedMetrics) metricsSummary {
+	s := metricsSummary{Count: len(m)}
+	if len(m) == 0 {
+		return s
+	}
+
+	s.From = m[0].Timestamp
+	s.To = m[len(m)-1].Timestamp
+	s.Min, s.Max = m[0].Value, m[0].Value
+	s.Last = m[len(m)-1].Value
+
+	var sum float64
+	for _, v := range m {
+		sum += v.Value
+		if v.Value < s.Min {
+			s.Min = v.Value
+		}
+		if v.Value > s.Max {
+			s.Max = v.Value
+		}
+	}
+	s.Mean = sum / float64(len(m))
+
+	return s
+}
@@ -0,0 +1,162 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package policyeval
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	hclog "github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/nomad-autoscaler/sdk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_newDecisionLog_cooldown(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name              string
+		meta              map[string]string
+		expectedElapsed   string
+		expectedRemaining string
+	}{
+		{
+			name: "no last event",
+		},
+		{
+			name:              "within cooldown",
+			meta:              map[string]string{sdk.TargetStatusMetaKeyLastEvent: strconv.FormatInt(now.Add(-2*time.Minute).UnixNano(), 10)},
+			expectedElapsed:   "2m0s",
+			expectedRemaining: "3m0s",
+		},
+		{
+			name:              "cooldown elapsed",
+			meta:              map[string]string{sdk.TargetStatusMetaKeyLastEvent: strconv.FormatInt(now.Add(-10*time.Minute).UnixNano(), 10)},
+			expectedElapsed:   "10m0s",
+			expectedRemaining: "0s",
+		},
+		{
+			name: "malformed last event",
+			meta: map[string]string{sdk.TargetStatusMetaKeyLastEvent: "yesterday"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			eval := &sdk.ScalingEvaluation{
+				ID: "eval",
+				Policy: &sdk.ScalingPolicy{
+					ID:       "policy",
+					Min:      1,
+					Max:      10,
+					Cooldown: 5 * time.Minute,
+					Target:   &sdk.ScalingPolicyTarget{Name: "target"},
+				},
+			}
+
+			d := newDecisionLog(eval, &sdk.TargetStatus{Count: 3, Meta: tc.meta}, now)
+			assert.Equal(t, "5m0s", d.Cooldown.Period)
+			assert.Equal(t, tc.expectedElapsed, d.Cooldown.Elapsed)
+			assert.Equal(t, tc.expectedRemaining, d.Cooldown.Remaining)
+			assert.Equal(t, tc.expectedElapsed == "", d.Cooldown.LastEvent == nil)
+		})
+	}
+}
+
+func Test_summarizeMetrics(t *testing.T) {
+	ts := time.Now()
+
+	assert.Equal(t, metricsSummary{}, summarizeMetrics(nil))
+	assert.Equal(t, metricsSummary{
+		Count: 3,
+		From:  ts,
+		To:    ts.Add(2 * time.Minute),
+		Min:   2,
+		Max:   7,
+		Mean:  4,
+		Last:  3,
+	}, summarizeMetrics(sdk.TimestampedMetrics{
+		{Timestamp: ts, Value: 7},
+		{Timestamp: ts.Add(time.Minute), Value: 2},
+		{Timestamp: ts.Add(2 * time.Minute), Value: 3},
+	}))
+}
+
+func Test_decisionLog_write(t *testing.T) {
+	// A nil decision log is a no-op.
+	var d *decisionLog
+	d.addCheck(nil, 0, nil, nil)
+	d.setOutcome("scale", "", nil, nil)
+	d.write(hclog.NewNullLogger())
+
+	eval := &sdk.ScalingEvaluation{
+		ID: "eval",
+		Policy: &sdk.ScalingPolicy{
+			ID:       "policy",
+			Max:      10,
+			Cooldown: time.Minute,
+			Target:   &sdk.ScalingPolicyTarget{Name: "target"},
+		},
+	}
+	d = newDecisionLog(eval, &sdk.TargetStatus{Count: 3}, time.Now())
+
+	cpu := &checkHandler{
+		checkEval: &sdk.ScalingCheckEvaluation{
+			Check: &sdk.ScalingPolicyCheck{
+				Name:     "cpu",
+				Group:    "resources",
+				Strategy: &sdk.ScalingPolicyStrategy{Name: "target-value", Config: map[string]string{"target": "70"}},
+			},
+			Metrics: sdk.TimestampedMetrics{{Timestamp: time.Now(), Value: 95}},
+		},
+		strategyAction: &sdk.ScalingAction{Count: 12, Direction: sdk.ScaleDirectionUp},
+		caps:           []capOperation{{Name: "min_max", From: 12, To: 10, Applied: true}},
+	}
+	action := &sdk.ScalingAction{Count: 10, Direction: sdk.ScaleDirectionUp}
+	d.addCheck(cpu, 3, action, nil)
+
+	mem := &checkHandler{
+		checkEval: &sdk.ScalingCheckEvaluation{
+			Check: &sdk.ScalingPolicyCheck{Name: "mem", Group: "resources"},
+		},
+	}
+	d.addCheck(mem, 3, nil, errors.New("query failed"))
+
+	d.addGroup("resources", []checkResult{{action: action, handler: cpu}}, 0, checkResult{action: action, handler: cpu})
+	d.setOutcome("scale", "cpu", action, nil)
+	d.setCooldown(time.Minute)
+
+	var buf bytes.Buffer
+	d.write(hclog.New(&hclog.LoggerOptions{Output: &buf, JSONFormat: true}))
+
+	var line map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &line))
+	assert.Equal(t, "policy decision log", line["@message"])
+
+	var out decisionLog
+	require.NoError(t, json.Unmarshal([]byte(line["decision"].(string)), &out))
+
+	assert.Equal(t, "policy", out.PolicyID)
+	assert.Equal(t, "eval", out.CorrelationID)
+	assert.Equal(t, "scale", out.Outcome)
+	assert.Equal(t, "cpu", out.Winner)
+	assert.Equal(t, "1m0s", out.Cooldown.Enforced)
+
+	require.Len(t, out.Checks, 2)
+	assert.Equal(t, "up", out.Checks[0].Decision)
+	assert.Equal(t, int64(12), out.Checks[0].StrategyAction.Count)
+	assert.Equal(t, "70", out.Checks[0].StrategyConfig["target"])
+	assert.Equal(t, []capOperation{{Name: "min_max", From: 12, To: 10, Applied: true}}, out.Checks[0].Caps)
+	assert.Equal(t, 95.0, out.Checks[0].Metrics.Last)
+	assert.Equal(t, "error", out.Checks[1].Decision)
+	assert.Equal(t, "query failed", out.Checks[1].Error)
+
+	require.Len(t, out.Groups, 1)
+	assert.Equal(t, "cpu", out.Groups[0].Winner)
+}
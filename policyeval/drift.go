@@ -0,0 +1,299 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package policyeval
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/armon/go-metrics"
+	hclog "github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/nomad-autoscaler/plugins/manager"
+	"github.com/hashicorp/nomad-autoscaler/policy"
+	"github.com/hashicorp/nomad-autoscaler/sdk"
+)
+
+// desiredCounts tracks the last count desired by each policy evaluated by the
+// workers of the agent.
+var desiredCounts = newDesiredCountRegistry()
+
+// desiredCount is the last count desired by a policy.
+type desiredCount struct {
+	policy *sdk.ScalingPolicy
+	count  int64
+
+	// driftReported is set once the drift of the target to driftCount has
+	// been reported, so it's only reported once.
+	driftReported bool
+	driftCount    int64
+}
+
+// desiredCountKey returns the key of the count desired by the policy. Vertical
+// policies scaling multiple task resources desire a count for each of them,
+// so the resource is included in the key.
+func desiredCountKey(p *sdk.ScalingPolicy) string {
+	if p.Target != nil {
+		if r := p.Target.Config[sdk.TargetConfigKeyResource]; r != "" {
+			return p.ID + "/" + r
+		}
+	}
+	return p.ID
+}
+
+// desiredCountRegistry holds the last count desired by each policy, keyed by
+// desiredCountKey.
+type desiredCountRegistry struct {
+	lock   sync.Mutex
+	counts map[string]*desiredCount
+}
+
+func newDesiredCountRegistry() *desiredCountRegistry {
+	return &desiredCountRegistry{counts: make(map[string]*desiredCount)}
+}
+
+// record stores the count desired by the policy, resetting any reported
+// drift.
+func (r *desiredCountRegistry) record(p *sdk.ScalingPolicy, count int64) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.counts[desiredCountKey(p)] = &desiredCount{policy: p, count: count}
+}
+
+// get returns the last count desired by the policy.
+func (r *desiredCountRegistry) get(key string) (int64, bool) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	c, ok := r.counts[key]
+	if !ok {
+		return 0, false
+	}
+	return c.count, true
+}
+
+// list returns a copy of the desired count of all policies.
+func (r *desiredCountRegistry) list() []desiredCount {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	out := make([]desiredCount, 0, len(r.counts))
+	for _, c := range r.counts {
+		out = append(out, *c)
+	}
+	return out
+}
+
+// setDrift records the drift of the policy target. It returns true if the
+// drift to the count has not been reported before. It's a no-op if the
+// desired count of the policy changed since the check started.
+func (r *desiredCountRegistry) setDrift(key string, desired, actual int64) bool {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	c, ok := r.counts[key]
+	if !ok || c.count != desired {
+		return false
+	}
+
+	if desired == actual {
+		c.driftReported = false
+		return false
+	}
+
+	if c.driftReported && c.driftCount == actual {
+		return false
+	}
+	c.driftReported = true
+	c.driftCount = actual
+	return true
+}
+
+func (r *desiredCountRegistry) remove(key string) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	delete(r.counts, key)
+}
+
+// DriftMonitor periodically compares the last count desired by each policy
+// with the current count of its target, outside of the policy evaluations.
+// A difference means the target was modified by another actor, such as an
+// operator or a competing controller, and is reported with a metric and an
+// event.
+type DriftMonitor struct {
+	logger        hclog.Logger
+	pluginManager *manager.PluginManager
+	policyManager *policy.Manager
+
+	interval time.Duration
+
+	// desiredCounts holds the last count desired by each policy.
+	desiredCounts *desiredCountRegistry
+
+	// targetCount returns the current count of the policy target, or false
+	// if it's not available. It can be replaced in tests.
+	targetCount func(p *sdk.ScalingPolicy) (int64, bool, error)
+}
+
+// NewDriftMonitor returns a new DriftMonitor instance.
+func NewDriftMonitor(l hclog.Logger, pm *manager.PluginManager, m *policy.Manager, interval time.Duration) *DriftMonitor {
+	d := &DriftMonitor{
+		logger:        l.Named("drift_monitor"),
+		pluginManager: pm,
+		policyManager: m,
+		interval:      interval,
+		desiredCounts: desiredCounts,
+	}
+	d.targetCount = d.getTargetCount
+	return d
+}
+
+// Run periodically checks the policies for drift until the context is
+// canceled. It returns immediately if the interval is not positive.
+func (d *DriftMonitor) Run(ctx context.Context) {
+	if d.interval <= 0 {
+		d.logger.Debug("drift detection is disabled")
+		return
+	}
+
+	ticker := time.NewTicker(d.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			d.check()
+		}
+	}
+}
+
+// check compares the desired and actual count of the policies whose handlers
+// are not currently evaluating or scaling the target.
+func (d *DriftMonitor) check() {
+	states := make(map[string]policy.HandlerState)
+	for _, s := range d.policyManager.HandlerStatuses() {
+		states[s.PolicyID] = s.State
+	}
+
+	for _, desired := range d.desiredCounts.list() {
+		id := desired.policy.ID
+		key := desiredCountKey(desired.policy)
+
+		state, ok := states[id]
+		if !ok {
+			// The policy is no longer handled by the agent.
+			d.desiredCounts.remove(key)
+			continue
+		}
+		if state != policy.HandlerStateIdle && state != policy.HandlerStateCooldown {
+			continue
+		}
+
+		logger := d.logger.With("policy_id", id)
+
+		actual, ok, err := d.targetCount(desired.policy)
+		if err != nil {
+			logger.Warn("failed to get target status to check drift", "error", err)
+			continue
+		}
+		if !ok {
+			continue
+		}
+
+		labels := []metrics.Label{{Name: "policy_id", Value: id}}
+		metrics.SetGaugeWithLabels([]string{"policy", "drift"}, float32(actual-desired.count), labels)
+
+		if !d.desiredCounts.setDrift(key, desired.count, actual) {
+			continue
+		}
+
+		logger.Warn("target count differs from the count desired by the policy, it may have been modified outside the autoscaler",
+			"desired_count", desired.count, "actual_count", actual)
+		metrics.IncrCounterWithLabels([]string{"policy", "drift", "detected"}, 1, labels)
+
+		action := sdk.ScalingAction{
+			Count:     desired.count,
+			Direction: sdk.ScaleDirectionNone,
+			Reason: fmt.Sprintf("target count %d differs from the count %d desired by the policy",
+				actual, desired.count),
+		}
+		event := newScalingEvent(desired.policy, "", actual, action, nil)
+		recordDecisionEvent(event)
+		publishEvent(logger, d.pluginManager, event)
+
+		d.handleDrift(logger, desired.policy, state, actual)
+	}
+}
+
+// handleDrift reacts to the drift of the policy target according to the
+// policy on_out_of_band_change value. Policies that revert the change are
+// left untouched since the target is scaled back on their next evaluation.
+func (d *DriftMonitor) handleDrift(logger hclog.Logger, p *sdk.ScalingPolicy, state policy.HandlerState, actual int64) {
+	switch p.OnOutOfBandChange {
+	case sdk.ScalingPolicyOnOutOfBandChangeRevert:
+		logger.Info("target will be scaled back on the next policy evaluation")
+		return
+	case sdk.ScalingPolicyOnOutOfBandChangeAlertOnly:
+		return
+	}
+
+	// Adopt the new count as the baseline of the policy and place it into
+	// cooldown. Handlers already in cooldown are skipped since they would
+	// block until their cooldown is complete.
+	logger.Info("adopting target count as the policy baseline", "count", actual)
+	d.desiredCounts.record(p, actual)
+	if state == policy.HandlerStateIdle {
+		d.policyManager.EnforceCooldown(p.ID, p.Cooldown)
+	}
+}
+
+// getTargetCount returns the current count of the policy target. The boolean
+// return is false if the target doesn't exist or is not ready.
+func (d *DriftMonitor) getTargetCount(p *sdk.ScalingPolicy) (int64, bool, error) {
+	targetImpl, err := d.pluginManager.GetTarget(p.ID, p.Target)
+	if err != nil {
+		return 0, false, err
+	}
+
+	status, err := runTargetStatus(targetImpl, p)
+	if err != nil {
+		return 0, false, err
+	}
+	if status == nil || !status.Ready {
+		return 0, false, nil
+	}
+	return status.Count, true, nil
+}
+
+// revertAction returns the action that scales the target back to the last
+// count desired by the policy if the target count was changed outside the
+// autoscaler and the policy is configured to revert such changes. The
+// boolean return is false if no action is needed.
+func revertAction(p *sdk.ScalingPolicy, current int64) (sdk.ScalingAction, bool) {
+	if p.OnOutOfBandChange != sdk.ScalingPolicyOnOutOfBandChangeRevert {
+		return sdk.ScalingAction{}, false
+	}
+
+	// Desired counts outside the policy limits are stale since the limits
+	// changed after they were recorded.
+	desired, ok := desiredCounts.get(desiredCountKey(p))
+	if !ok || desired == current || desired < p.Min || desired > p.Max {
+		return sdk.ScalingAction{}, false
+	}
+
+	var direction sdk.ScaleDirection = sdk.ScaleDirectionUp
+	if desired < current {
+		direction = sdk.ScaleDirectionDown
+	}
+
+	return sdk.ScalingAction{
+		Count:     desired,
+		Direction: direction,
+		Reason: fmt.Sprintf("reverting out-of-band change of the target count from %d to %d",
+			current, desired),
+	}, true
+}
@@ -0,0 +1,122 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package policyeval
+
+import (
+	"testing"
+
+	"github.com/hashicorp/nomad-autoscaler/sdk"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_desiredCountRegistry(t *testing.T) {
+	r := newDesiredCountRegistry()
+	p := &sdk.ScalingPolicy{ID: "policy"}
+
+	assert.Empty(t, r.list())
+	assert.False(t, r.setDrift("policy", 3, 5))
+
+	r.record(p, 3)
+	list := r.list()
+	assert.Len(t, list, 1)
+	assert.Equal(t, p, list[0].policy)
+	assert.Equal(t, int64(3), list[0].count)
+
+	// No drift.
+	assert.False(t, r.setDrift("policy", 3, 3))
+
+	// Drift is only reported once per count.
+	assert.True(t, r.setDrift("policy", 3, 5))
+	assert.False(t, r.setDrift("policy", 3, 5))
+	assert.True(t, r.setDrift("policy", 3, 6))
+
+	// Drift is reported again after the target recovers.
+	assert.False(t, r.setDrift("policy", 3, 3))
+	assert.True(t, r.setDrift("policy", 3, 6))
+
+	// The desired count changed since the check started.
+	r.record(p, 4)
+	assert.False(t, r.setDrift("policy", 3, 6))
+	assert.True(t, r.setDrift("policy", 4, 6))
+
+	r.remove("policy")
+	assert.Empty(t, r.list())
+}
+
+func Test_revertAction(t *testing.T) {
+	testCases := []struct {
+		name           string
+		mode           string
+		desired        int64
+		current        int64
+		expectedOK     bool
+		expectedAction sdk.ScalingAction
+	}{
+		{
+			name:    "respect",
+			mode:    sdk.ScalingPolicyOnOutOfBandChangeRespect,
+			desired: 3,
+			current: 5,
+		},
+		{
+			name:    "alert only",
+			mode:    sdk.ScalingPolicyOnOutOfBandChangeAlertOnly,
+			desired: 3,
+			current: 5,
+		},
+		{
+			name:    "revert without drift",
+			mode:    sdk.ScalingPolicyOnOutOfBandChangeRevert,
+			desired: 3,
+			current: 3,
+		},
+		{
+			name:    "revert to stale desired count",
+			mode:    sdk.ScalingPolicyOnOutOfBandChangeRevert,
+			desired: 20,
+			current: 5,
+		},
+		{
+			name:       "revert down",
+			mode:       sdk.ScalingPolicyOnOutOfBandChangeRevert,
+			desired:    3,
+			current:    5,
+			expectedOK: true,
+			expectedAction: sdk.ScalingAction{
+				Count:     3,
+				Direction: sdk.ScaleDirectionDown,
+				Reason:    "reverting out-of-band change of the target count from 5 to 3",
+			},
+		},
+		{
+			name:       "revert up",
+			mode:       sdk.ScalingPolicyOnOutOfBandChangeRevert,
+			desired:    5,
+			current:    3,
+			expectedOK: true,
+			expectedAction: sdk.ScalingAction{
+				Count:     5,
+				Direction: sdk.ScaleDirectionUp,
+				Reason:    "reverting out-of-band change of the target count from 3 to 5",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			p := &sdk.ScalingPolicy{
+				ID:                "revert-" + tc.name,
+				Min:               1,
+				Max:               10,
+				OnOutOfBandChange: tc.mode,
+			}
+			desiredCounts.record(p, tc.desired)
+			defer desiredCounts.remove(p.ID)
+
+			action, ok := revertAction(p, tc.current)
+			assert.Equal(t, tc.expectedOK, ok)
+			assert.Equal(t, tc.expectedAction, action)
+		})
+	}
+}
@@ -0,0 +1,147 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package policyeval
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/armon/go-metrics"
+	hclog "github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/nomad-autoscaler/plugins/eventsink"
+	"github.com/hashicorp/nomad-autoscaler/plugins/manager"
+	"github.com/hashicorp/nomad-autoscaler/sdk"
+	"github.com/hashicorp/nomad-autoscaler/sdk/helper/uuid"
+)
+
+// newScalingEvent builds the event describing the outcome of a policy
+// evaluation.
+func newScalingEvent(policy *sdk.ScalingPolicy, check string, count int64, action sdk.ScalingAction, err error) *sdk.ScalingEvent {
+	event := &sdk.ScalingEvent{
+		ID:            uuid.Generate(),
+		Type:          sdk.ScalingEventTypeScaling,
+		CorrelationID: action.CorrelationID(),
+		Timestamp:     time.Now().UTC(),
+		PolicyID:      policy.ID,
+		Target:        policy.Target.Name,
+		PolicyMeta:    policy.Meta,
+		Check:         check,
+		Count:         count,
+		Action:        action,
+	}
+	if err != nil {
+		event.Error = err.Error()
+	}
+	if errors.Is(err, sdk.ErrTargetCapacityReached) {
+		event.Type = sdk.ScalingEventTypeQuotaReached
+	}
+	return event
+}
+
+// newTargetScalingEvent builds the event describing the outcome of a policy
+// evaluation, including the standard meta of the target status.
+func newTargetScalingEvent(policy *sdk.ScalingPolicy, check string, status *sdk.TargetStatus, action sdk.ScalingAction, err error) *sdk.ScalingEvent {
+	event := newScalingEvent(policy, check, status.Count, action, err)
+	event.TargetMeta = sdk.StandardTargetStatusMeta(status.Meta)
+	return event
+}
+
+// eventSinkQueueSize is the maximum number of events waiting to be sent to
+// each event sink. Events published while the queue is full are dropped.
+const eventSinkQueueSize = 1000
+
+// eventQueues holds the events waiting to be sent to each event sink. Events
+// are sent asynchronously so slow or unreachable sinks don't delay the policy
+// evaluations.
+var eventQueues = newEventSinkQueues(eventSinkQueueSize)
+
+// sendEvent publishes the scaling event to all the event sink plugins
+// configured in the agent. Failing to send an event does not affect the
+// policy evaluation, so errors are only logged. The event is also recorded
+// in the decision history.
+func (w *BaseWorker) sendEvent(logger hclog.Logger, event *sdk.ScalingEvent) {
+	recordDecisionEvent(event)
+	publishEvent(logger, w.pluginManager, event)
+}
+
+// publishEvent queues the event to be sent to all the event sink plugins
+// configured in the agent.
+func publishEvent(logger hclog.Logger, pm *manager.PluginManager, event *sdk.ScalingEvent) {
+	for name, sink := range pm.GetEventSinks() {
+		eventQueues.push(logger, name, sink, event)
+	}
+}
+
+// queuedEvent is an event waiting to be sent to an event sink.
+type queuedEvent struct {
+	logger hclog.Logger
+	sink   eventsink.EventSink
+	event  *sdk.ScalingEvent
+}
+
+// eventSinkQueues holds a bounded queue of events for each event sink. Each
+// queue is drained by its own goroutine, so a slow sink doesn't delay the
+// events sent to the others.
+type eventSinkQueues struct {
+	size int
+
+	lock   sync.Mutex
+	queues map[string]chan queuedEvent
+}
+
+func newEventSinkQueues(size int) *eventSinkQueues {
+	return &eventSinkQueues{
+		size:   size,
+		queues: make(map[string]chan queuedEvent),
+	}
+}
+
+// push adds the event to the queue of the named sink, starting the queue if
+// needed. If the queue is full, the event is dropped and false is returned.
+func (q *eventSinkQueues) push(logger hclog.Logger, name string, sink eventsink.EventSink, event *sdk.ScalingEvent) bool {
+	q.lock.Lock()
+	ch, ok := q.queues[name]
+	if !ok {
+		ch = make(chan queuedEvent, q.size)
+		q.queues[name] = ch
+		go drainEventSinkQueue(name, ch)
+	}
+	q.lock.Unlock()
+
+	select {
+	case ch <- queuedEvent{logger: logger, sink: sink, event: event}:
+		return true
+	default:
+		metrics.IncrCounterWithLabels([]string{"plugin", "event_sink", "send", "dropped_count"}, 1,
+			[]metrics.Label{{Name: "plugin_name", Value: name}})
+		logger.Warn("event sink queue is full, dropping scaling event",
+			"event_sink", name, "queue_size", q.size, "event_id", event.ID)
+		return false
+	}
+}
+
+// drainEventSinkQueue sends the events of the queue to the sink, logging any
+// errors. It runs for the lifetime of the agent.
+func drainEventSinkQueue(name string, ch <-chan queuedEvent) {
+	for e := range ch {
+		if err := runEventSinkSend(name, e.sink, e.event); err != nil {
+			e.logger.Warn("failed to send scaling event", "event_sink", name, "error", err)
+		}
+	}
+}
+
+// runEventSinkSend wraps the eventsink.Send call to provide operational
+// functionality.
+func runEventSinkSend(name string, sink eventsink.EventSink, event *sdk.ScalingEvent) error {
+	// Trigger a metric measure to track latency of the call.
+	labels := []metrics.Label{{Name: "plugin_name", Value: name}, {Name: "policy_id", Value: event.PolicyID}}
+	defer measurePluginCall("event_sink", "send", name, labels, time.Now())
+
+	err := sink.Send(event)
+	if err != nil {
+		metrics.IncrCounterWithLabels([]string{"plugin", "event_sink", "send", "error_count"}, 1, labels)
+	}
+	return err
+}
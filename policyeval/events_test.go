@@ -0,0 +1,117 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package policyeval
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	hclog "github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/nomad-autoscaler/plugins/base"
+	"github.com/hashicorp/nomad-autoscaler/sdk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_newScalingEvent(t *testing.T) {
+	policy := &sdk.ScalingPolicy{
+		ID:     "policy-1",
+		Target: &sdk.ScalingPolicyTarget{Name: "aws-asg"},
+	}
+	action := sdk.ScalingAction{Count: 12, Direction: sdk.ScaleDirectionUp}
+
+	testCases := []struct {
+		name          string
+		err           error
+		expectedType  sdk.ScalingEventType
+		expectedError string
+	}{
+		{
+			name:         "scaling action",
+			expectedType: sdk.ScalingEventTypeScaling,
+		},
+		{
+			name:          "scaling action failed",
+			err:           errors.New("failed to scale"),
+			expectedType:  sdk.ScalingEventTypeScaling,
+			expectedError: "failed to scale",
+		},
+		{
+			name: "provider quota reached",
+			err: sdk.NewTargetScalingNoOpError("%w: target count %d reached the provider limit of %d",
+				sdk.ErrTargetCapacityReached, 10, 10),
+			expectedType:  sdk.ScalingEventTypeQuotaReached,
+			expectedError: "provider quota reached: target count 10 reached the provider limit of 10",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			event := newScalingEvent(policy, "cpu", 10, action, tc.err)
+			assert.Equal(t, tc.expectedType, event.Type)
+			assert.Equal(t, tc.expectedError, event.Error)
+			assert.Equal(t, "policy-1", event.PolicyID)
+		})
+	}
+}
+
+type testEventSink struct {
+	unblock chan struct{}
+	sent    chan *sdk.ScalingEvent
+}
+
+func (s *testEventSink) PluginInfo() (*base.PluginInfo, error) { return nil, nil }
+func (s *testEventSink) SetConfig(map[string]string) error     { return nil }
+
+func (s *testEventSink) Send(event *sdk.ScalingEvent) error {
+	<-s.unblock
+	s.sent <- event
+	return nil
+}
+
+func Test_eventSinkQueues(t *testing.T) {
+	logger := hclog.NewNullLogger()
+	sink := &testEventSink{
+		unblock: make(chan struct{}),
+		sent:    make(chan *sdk.ScalingEvent, 10),
+	}
+	q := newEventSinkQueues(2)
+
+	// The first event is taken by the drain goroutine, which blocks on the
+	// sink, so wait for it before filling the queue.
+	require.True(t, q.push(logger, "test", sink, &sdk.ScalingEvent{ID: "1"}))
+	require.Eventually(t, func() bool {
+		q.lock.Lock()
+		defer q.lock.Unlock()
+		return len(q.queues["test"]) == 0
+	}, time.Second, 10*time.Millisecond)
+
+	assert.True(t, q.push(logger, "test", sink, &sdk.ScalingEvent{ID: "2"}))
+	assert.True(t, q.push(logger, "test", sink, &sdk.ScalingEvent{ID: "3"}))
+
+	// The queue is full, so new events are dropped without blocking.
+	assert.False(t, q.push(logger, "test", sink, &sdk.ScalingEvent{ID: "4"}))
+
+	// Other sinks are not affected by the blocked one.
+	other := &testEventSink{
+		unblock: make(chan struct{}),
+		sent:    make(chan *sdk.ScalingEvent, 10),
+	}
+	close(other.unblock)
+	assert.True(t, q.push(logger, "other", other, &sdk.ScalingEvent{ID: "5"}))
+	assert.Equal(t, "5", (<-other.sent).ID)
+
+	// Once the sink recovers the queued events are sent in order.
+	close(sink.unblock)
+	for _, id := range []string{"1", "2", "3"} {
+		select {
+		case e := <-sink.sent:
+			assert.Equal(t, id, e.ID)
+		case <-time.After(time.Second):
+			t.Fatalf("timeout waiting for event %s", id)
+		}
+	}
+	assert.Empty(t, sink.sent)
+}
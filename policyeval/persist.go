@@ -0,0 +1,225 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package policyeval
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/armon/go-metrics"
+	hclog "github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/nomad-autoscaler/sdk"
+)
+
+// EvalStore persists the evaluations pending in the broker, so they are not
+// lost when the agent restarts and can be replayed on startup.
+type EvalStore interface {
+
+	// Put stores the eval, replacing any previous version with the same ID.
+	Put(eval *sdk.ScalingEvaluation) error
+
+	// Delete removes the eval with the ID. Deleting an eval that is not
+	// stored is not an error.
+	Delete(evalID string) error
+
+	// List returns all the stored evals.
+	List() ([]*sdk.ScalingEvaluation, error)
+}
+
+// evalPersisterMaxPending is the maximum number of evals with changes
+// waiting to be applied to the EvalStore. Changes to other evals are dropped
+// while the limit is reached.
+const evalPersisterMaxPending = 1000
+
+// persistOp is a change to the evals stored in the EvalStore. Only one of
+// put or deleteID is set.
+type persistOp struct {
+	put      *sdk.ScalingEvaluation
+	deleteID string
+}
+
+// evalID returns the ID of the eval changed by the operation.
+func (op persistOp) evalID() string {
+	if op.put != nil {
+		return op.put.ID
+	}
+	return op.deleteID
+}
+
+// evalPersister applies the changes to the pending evals to an EvalStore in
+// the background and in order, so slow store calls don't block the broker.
+// Store errors are logged, since failing to persist an eval must not stop it
+// from being evaluated.
+//
+// Only the latest change of each eval waiting to be applied is kept, so an
+// eval that is stored and then deleted before the store is called results
+// in a single delete, and the queue is bounded by the number of evals.
+type evalPersister struct {
+	logger     hclog.Logger
+	store      EvalStore
+	maxPending int
+
+	lock sync.Mutex
+
+	// pending holds the latest change of each eval waiting to be applied,
+	// and order holds their IDs in the order they were first queued.
+	pending  map[string]persistOp
+	order    []string
+	draining bool
+}
+
+func newEvalPersister(logger hclog.Logger, store EvalStore) *evalPersister {
+	return &evalPersister{
+		logger:     logger,
+		store:      store,
+		maxPending: evalPersisterMaxPending,
+		pending:    make(map[string]persistOp),
+	}
+}
+
+// put queues the eval to be stored. It is a no-op if p is nil, so the broker
+// doesn't need to check whether persistence is enabled.
+func (p *evalPersister) put(eval *sdk.ScalingEvaluation) {
+	p.push(persistOp{put: eval})
+}
+
+// delete queues the eval to be removed from the store.
+func (p *evalPersister) delete(evalID string) {
+	p.push(persistOp{deleteID: evalID})
+}
+
+func (p *evalPersister) push(op persistOp) {
+	if p == nil {
+		return
+	}
+
+	id := op.evalID()
+
+	p.lock.Lock()
+	if _, ok := p.pending[id]; !ok {
+		if len(p.pending) >= p.maxPending {
+			p.lock.Unlock()
+			metrics.IncrCounter([]string{"scale", "evaluate", "persist", "dropped_count"}, 1)
+			p.logger.Warn("too many evals waiting to be persisted, dropping change",
+				"eval_id", id, "max_pending", p.maxPending)
+			return
+		}
+		p.order = append(p.order, id)
+	}
+	p.pending[id] = op
+	start := !p.draining
+	p.draining = true
+	p.lock.Unlock()
+
+	if start {
+		go p.drain()
+	}
+}
+
+// drain applies the queued operations until there are none left.
+func (p *evalPersister) drain() {
+	for {
+		p.lock.Lock()
+		if len(p.order) == 0 {
+			p.draining = false
+			p.lock.Unlock()
+			return
+		}
+		id := p.order[0]
+		p.order = p.order[1:]
+		op := p.pending[id]
+		delete(p.pending, id)
+		p.lock.Unlock()
+
+		if op.put != nil {
+			if err := p.store.Put(op.put); err != nil {
+				p.logger.Warn("failed to persist eval", "eval_id", op.put.ID, "error", err)
+			}
+			continue
+		}
+		if err := p.store.Delete(op.deleteID); err != nil {
+			p.logger.Warn("failed to delete persisted eval", "eval_id", op.deleteID, "error", err)
+		}
+	}
+}
+
+// SetEvalStore sets the store where pending evals are persisted. It must be
+// called before evals are enqueued, usually followed by Restore.
+func (b *Broker) SetEvalStore(store EvalStore) {
+	b.l.Lock()
+	defer b.l.Unlock()
+	b.persister = newEvalPersister(b.logger, store)
+}
+
+// Restore enqueues the evals persisted by a previous run of the agent and
+// returns how many were replayed, along with any error reading the store.
+// Evals older than maxAge are discarded, as the policy may have changed
+// since, and only the newest eval of each policy is replayed. Evals enqueued
+// afterwards for the same policy replace the replayed ones if they are
+// newer.
+func (b *Broker) Restore(maxAge time.Duration) (int, error) {
+	b.l.RLock()
+	persister := b.persister
+	b.l.RUnlock()
+
+	if persister == nil {
+		return 0, nil
+	}
+
+	// Stores may return the evals they were able to read along with an
+	// error, so replay them anyway.
+	evals, err := persister.store.List()
+	if err != nil && len(evals) == 0 {
+		return 0, err
+	}
+
+	// Replay the newest evals first so older evals for the same policy are
+	// discarded.
+	sort.Slice(evals, func(i, j int) bool {
+		return evals[i].CreateTime.After(evals[j].CreateTime)
+	})
+
+	cutoff := time.Now().Add(-maxAge)
+	replayed := 0
+
+	b.l.Lock()
+	defer b.l.Unlock()
+
+	for _, eval := range evals {
+		if eval.Policy == nil || eval.CreateTime.Before(cutoff) {
+			persister.delete(eval.ID)
+			continue
+		}
+		if _, ok := b.enqueuedPolicies[eval.Policy.ID]; ok {
+			persister.delete(eval.ID)
+			continue
+		}
+
+		relinkChecks(eval)
+		b.enqueueLocked(eval, "")
+		replayed++
+	}
+
+	b.logger.Info("restored persisted evals", "replayed", replayed, "total", len(evals))
+	return replayed, err
+}
+
+// relinkChecks points the check evaluations of an eval decoded from the
+// store to the checks of its policy, as they are when the eval is created.
+func relinkChecks(eval *sdk.ScalingEvaluation) {
+	checks := make(map[string]*sdk.ScalingPolicyCheck, len(eval.Policy.Checks))
+	for _, c := range eval.Policy.Checks {
+		checks[c.Name] = c
+	}
+
+	for _, ce := range eval.CheckEvaluations {
+		if ce.Check == nil {
+			continue
+		}
+		if c, ok := checks[ce.Check.Name]; ok {
+			ce.Check = c
+		}
+	}
+}
@@ -0,0 +1,252 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package policyeval
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	hclog "github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/nomad-autoscaler/sdk"
+	"github.com/shoenig/test/must"
+	"github.com/shoenig/test/wait"
+)
+
+// testEvalStore is an in-memory EvalStore.
+type testEvalStore struct {
+	lock  sync.Mutex
+	evals map[string]*sdk.ScalingEvaluation
+	err   error
+}
+
+func newTestEvalStore(evals ...*sdk.ScalingEvaluation) *testEvalStore {
+	s := &testEvalStore{evals: make(map[string]*sdk.ScalingEvaluation)}
+	for _, e := range evals {
+		s.evals[e.ID] = e
+	}
+	return s
+}
+
+func (s *testEvalStore) Put(eval *sdk.ScalingEvaluation) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.evals[eval.ID] = eval
+	return nil
+}
+
+func (s *testEvalStore) Delete(evalID string) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	delete(s.evals, evalID)
+	return nil
+}
+
+func (s *testEvalStore) List() ([]*sdk.ScalingEvaluation, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	evals := make([]*sdk.ScalingEvaluation, 0, len(s.evals))
+	for _, e := range s.evals {
+		evals = append(evals, e)
+	}
+	return evals, s.err
+}
+
+// ids returns the IDs of the stored evals once all the broker changes are
+// persisted.
+func (s *testEvalStore) ids(t *testing.T, b *Broker) []string {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		b.persister.lock.Lock()
+		draining := b.persister.draining
+		b.persister.lock.Unlock()
+
+		if !draining {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("timeout waiting for evals to be persisted")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	ids := make([]string, 0, len(s.evals))
+	for id := range s.evals {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+func TestBroker_persistence(t *testing.T) {
+	store := newTestEvalStore()
+	b := NewBroker(hclog.NewNullLogger(), time.Minute, 1)
+	b.SetEvalStore(store)
+
+	now := time.Now()
+	policy1 := &sdk.ScalingPolicy{ID: "policy1", Type: "horizontal"}
+	policy2 := &sdk.ScalingPolicy{ID: "policy2", Type: "horizontal"}
+
+	b.Enqueue(&sdk.ScalingEvaluation{ID: "eval1", Policy: policy1, CreateTime: now})
+	b.Enqueue(&sdk.ScalingEvaluation{ID: "eval2", Policy: policy2, CreateTime: now})
+	must.Eq(t, []string{"eval1", "eval2"}, store.ids(t, b))
+
+	// Newer evals replace the pending eval of the policy.
+	b.Enqueue(&sdk.ScalingEvaluation{ID: "eval1b", Policy: policy1, CreateTime: now.Add(time.Second)})
+	must.Eq(t, []string{"eval1b", "eval2"}, store.ids(t, b))
+
+	// Older evals are discarded.
+	b.Enqueue(&sdk.ScalingEvaluation{ID: "eval1c", Policy: policy1, CreateTime: now.Add(-time.Second)})
+	must.Eq(t, []string{"eval1b", "eval2"}, store.ids(t, b))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// Evals remain persisted while they are evaluated, and are removed once
+	// ACK'd or when the delivery limit is reached.
+	eval, token, err := b.Dequeue(ctx, "horizontal")
+	must.NoError(t, err)
+	must.Eq(t, []string{"eval1b", "eval2"}, store.ids(t, b))
+	must.NoError(t, b.Ack(eval.ID, token))
+
+	eval, token, err = b.Dequeue(ctx, "horizontal")
+	must.NoError(t, err)
+	must.NoError(t, b.Nack(eval.ID, token))
+	must.Eq(t, []string{}, store.ids(t, b))
+}
+
+func TestBroker_Restore(t *testing.T) {
+	now := time.Now()
+	policy1 := &sdk.ScalingPolicy{
+		ID:     "policy1",
+		Type:   "horizontal",
+		Checks: []*sdk.ScalingPolicyCheck{{Name: "cpu"}},
+	}
+	policy2 := &sdk.ScalingPolicy{ID: "policy2", Type: "horizontal"}
+
+	store := newTestEvalStore(
+		&sdk.ScalingEvaluation{
+			ID:               "eval1",
+			Policy:           policy1,
+			CreateTime:       now.Add(-time.Minute),
+			CheckEvaluations: []*sdk.ScalingCheckEvaluation{{Check: &sdk.ScalingPolicyCheck{Name: "cpu"}}},
+		},
+		&sdk.ScalingEvaluation{ID: "eval1-old", Policy: policy1, CreateTime: now.Add(-2 * time.Minute)},
+		&sdk.ScalingEvaluation{ID: "eval2-expired", Policy: policy2, CreateTime: now.Add(-time.Hour)},
+	)
+
+	b := NewBroker(hclog.NewNullLogger(), time.Minute, 1)
+	b.SetEvalStore(store)
+
+	replayed, err := b.Restore(10 * time.Minute)
+	must.NoError(t, err)
+	must.Eq(t, 1, replayed)
+
+	// Only the newest eval of each policy is kept.
+	must.Eq(t, []string{"eval1"}, store.ids(t, b))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	eval, _, err := b.Dequeue(ctx, "horizontal")
+	must.NoError(t, err)
+	must.Eq(t, "eval1", eval.ID)
+	must.True(t, eval.CheckEvaluations[0].Check == policy1.Checks[0])
+
+	// Brokers without a store don't restore any eval.
+	replayed, err = NewBroker(hclog.NewNullLogger(), time.Minute, 1).Restore(time.Minute)
+	must.NoError(t, err)
+	must.Zero(t, replayed)
+
+	// Store errors are reported.
+	failing := newTestEvalStore()
+	failing.err = errors.New("nomad unavailable")
+
+	b = NewBroker(hclog.NewNullLogger(), time.Minute, 1)
+	b.SetEvalStore(failing)
+	_, err = b.Restore(time.Minute)
+	must.ErrorContains(t, err, "nomad unavailable")
+}
+
+// testRecordingEvalStore is an EvalStore that records the calls it receives.
+// Calls block while unblock is open, to simulate a slow store.
+type testRecordingEvalStore struct {
+	unblock chan struct{}
+
+	lock  sync.Mutex
+	calls []string
+}
+
+func (s *testRecordingEvalStore) record(call string) error {
+	<-s.unblock
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.calls = append(s.calls, call)
+	return nil
+}
+
+func (s *testRecordingEvalStore) Put(eval *sdk.ScalingEvaluation) error {
+	return s.record("put " + eval.ID)
+}
+
+func (s *testRecordingEvalStore) Delete(evalID string) error {
+	return s.record("delete " + evalID)
+}
+
+func (s *testRecordingEvalStore) List() ([]*sdk.ScalingEvaluation, error) {
+	return nil, nil
+}
+
+func Test_evalPersister(t *testing.T) {
+	store := &testRecordingEvalStore{unblock: make(chan struct{})}
+	p := newEvalPersister(hclog.NewNullLogger(), store)
+	p.maxPending = 3
+
+	// The first change is taken by the drain goroutine, which blocks on the
+	// store, so wait for it before queueing the rest.
+	p.put(&sdk.ScalingEvaluation{ID: "eval1"})
+	must.Wait(t, wait.InitialSuccess(
+		wait.BoolFunc(func() bool {
+			p.lock.Lock()
+			defer p.lock.Unlock()
+			return len(p.order) == 0
+		}),
+		wait.Timeout(time.Second),
+		wait.Gap(10*time.Millisecond),
+	))
+
+	// Changes to the same eval are merged, keeping the latest one.
+	p.put(&sdk.ScalingEvaluation{ID: "eval2"})
+	p.delete("eval2")
+	p.put(&sdk.ScalingEvaluation{ID: "eval3"})
+	p.put(&sdk.ScalingEvaluation{ID: "eval3"})
+	p.delete("eval1")
+
+	// New evals are dropped once the limit is reached, but changes to the
+	// queued evals are still merged.
+	p.put(&sdk.ScalingEvaluation{ID: "eval4"})
+	p.delete("eval3")
+
+	close(store.unblock)
+	must.Wait(t, wait.InitialSuccess(
+		wait.BoolFunc(func() bool {
+			p.lock.Lock()
+			defer p.lock.Unlock()
+			return !p.draining
+		}),
+		wait.Timeout(time.Second),
+		wait.Gap(10*time.Millisecond),
+	))
+
+	must.Eq(t, []string{"put eval1", "delete eval2", "delete eval3", "delete eval1"}, store.calls)
+}
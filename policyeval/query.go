@@ -0,0 +1,171 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package policyeval
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/armon/go-metrics"
+	"github.com/hashicorp/nomad-autoscaler/plugins/apm"
+	"github.com/hashicorp/nomad-autoscaler/sdk"
+)
+
+const (
+	// queryRetryInitialBackoff and queryRetryMaxBackoff are the limits of the
+	// exponential backoff used between query retries.
+	queryRetryInitialBackoff = time.Second
+	queryRetryMaxBackoff     = 30 * time.Second
+
+	// queryCircuitBreakerThreshold is the number of consecutive evaluations
+	// with failed queries after which retries are skipped. The query is still
+	// attempted once per evaluation, so the check recovers as soon as the
+	// source is available again.
+	queryCircuitBreakerThreshold = 3
+
+	// queryFallbackReasonError and queryFallbackReasonNoData are the reasons
+	// for which the last known metrics of a check can be used.
+	queryFallbackReasonError  = "error"
+	queryFallbackReasonNoData = "no_data"
+)
+
+// queryResult is the result of the check query, without any fallback
+// applied. It is recorded by the worker so it can be used in future
+// evaluations of the check.
+type queryResult struct {
+	metrics sdk.TimestampedMetrics
+	err     error
+}
+
+// queryMetrics runs the check query, retrying with backoff until the check
+// retry budget is exhausted. If the query still fails, or doesn't return any
+// metrics, the last metrics returned by the query are used, as long as they
+// are within the check fallback max age.
+func (h *checkHandler) queryMetrics(ctx context.Context, source apm.APM) (sdk.TimestampedMetrics, error) {
+	check := h.checkEval.Check
+
+	h.queryAttempts = 1
+	m, err := h.runAPMQuery(source)
+
+	budget := check.QueryRetryBudget
+	if err != nil && budget > 0 && h.queryState.Failures >= queryCircuitBreakerThreshold {
+		h.logger.Debug("skipping query retries due to consecutive failures",
+			"failures", h.queryState.Failures)
+		budget = 0
+	}
+
+	deadline := time.Now().Add(budget)
+	backoff := h.retryBackoff
+	if backoff <= 0 {
+		backoff = queryRetryInitialBackoff
+	}
+
+	for err != nil && budget > 0 {
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			break
+		}
+
+		wait := backoff
+		if wait > remaining {
+			wait = remaining
+		}
+		h.logger.Debug("retrying failed query", "attempt", h.queryAttempts, "backoff", wait, "error", err)
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+
+		h.queryAttempts++
+		m, err = h.runAPMQuery(source)
+
+		backoff *= 2
+		if backoff > queryRetryMaxBackoff {
+			backoff = queryRetryMaxBackoff
+		}
+	}
+
+	if check.QueryRetryBudget > 0 || check.QueryFallbackMaxAge > 0 {
+		h.queryResult = &queryResult{metrics: m, err: err}
+	}
+
+	switch {
+	case err != nil:
+		if fallback, ok := h.fallbackMetrics(queryFallbackReasonError, err); ok {
+			return fallback, nil
+		}
+		return nil, err
+
+	case check.Query != "" && len(m) == 0:
+		if fallback, ok := h.fallbackMetrics(queryFallbackReasonNoData, nil); ok {
+			return fallback, nil
+		}
+	}
+
+	return m, nil
+}
+
+// fallbackMetrics returns a copy of the last known metrics of the check if
+// they are within the check fallback max age. The reason and the query error,
+// if any, are reported when the fallback is used.
+func (h *checkHandler) fallbackMetrics(reason string, queryErr error) (sdk.TimestampedMetrics, bool) {
+	check := h.checkEval.Check
+	state := h.queryState
+
+	if check.QueryFallbackMaxAge <= 0 || len(state.Metrics) == 0 {
+		return nil, false
+	}
+
+	age := time.Since(state.Timestamp)
+	if age > check.QueryFallbackMaxAge {
+		h.logger.Debug("last known metrics are too old to be used",
+			"age", age, "max_age", check.QueryFallbackMaxAge)
+		return nil, false
+	}
+
+	h.logger.Warn("using last known metrics", "age", age, "reason", reason,
+		"attempts", h.queryAttempts, "error", queryErr)
+	metrics.IncrCounterWithLabels([]string{"scale", "check", "query_fallback"}, 1,
+		append(checkMetricLabels(h.policy, check.Name), metrics.Label{Name: "reason", Value: reason}))
+
+	h.metricsFallback = true
+	fallback := make(sdk.TimestampedMetrics, len(state.Metrics))
+	copy(fallback, state.Metrics)
+	return fallback, true
+}
+
+// queryLookback runs the query over the range using QueryMultiple. The query
+// must return at most one series, which is returned with its labels if the
+// source supports them.
+func queryLookback(apmImpl apm.APM, query string, r sdk.TimeRange) (sdk.LabeledTimestampedMetrics, error) {
+	var series []sdk.LabeledTimestampedMetrics
+
+	if labeled, ok := apmImpl.(apm.LabeledAPM); ok {
+		m, err := labeled.QueryMultipleLabeled(query, r)
+		if err != nil {
+			return sdk.LabeledTimestampedMetrics{}, err
+		}
+		series = m
+	} else {
+		m, err := apmImpl.QueryMultiple(query, r)
+		if err != nil {
+			return sdk.LabeledTimestampedMetrics{}, err
+		}
+		for _, s := range m {
+			series = append(series, sdk.LabeledTimestampedMetrics{Metrics: s})
+		}
+	}
+
+	result, err := apm.SingleLabeledSeries(series)
+	if err != nil {
+		return sdk.LabeledTimestampedMetrics{}, err
+	}
+	sort.Sort(result.Metrics)
+	return result, nil
+}
@@ -0,0 +1,398 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package policyeval
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	hclog "github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/nomad-autoscaler/plugins/base"
+	targetvalue "github.com/hashicorp/nomad-autoscaler/plugins/builtin/strategy/target-value/plugin"
+	"github.com/hashicorp/nomad-autoscaler/plugins/strategy"
+	"github.com/hashicorp/nomad-autoscaler/policy"
+	"github.com/hashicorp/nomad-autoscaler/sdk"
+	"github.com/stretchr/testify/assert"
+)
+
+// testAPM is an APM that fails the first failures queries. If empty is set,
+// successful queries don't return any metrics. QueryMultiple returns series.
+// The range of the last query is stored in lastRange.
+type testAPM struct {
+	failures  int
+	empty     bool
+	queries   int
+	series    []sdk.TimestampedMetrics
+	lastRange sdk.TimeRange
+}
+
+func (a *testAPM) PluginInfo() (*base.PluginInfo, error) { return nil, nil }
+func (a *testAPM) SetConfig(map[string]string) error     { return nil }
+func (a *testAPM) QueryMultiple(_ string, r sdk.TimeRange) ([]sdk.TimestampedMetrics, error) {
+	a.lastRange = r
+	return a.series, nil
+}
+
+func (a *testAPM) Query(_ string, r sdk.TimeRange) (sdk.TimestampedMetrics, error) {
+	a.lastRange = r
+	a.queries++
+	if a.queries <= a.failures {
+		return nil, errors.New("query failed")
+	}
+	if a.empty {
+		return sdk.TimestampedMetrics{}, nil
+	}
+	return sdk.TimestampedMetrics{{Value: 10}}, nil
+}
+
+func Test_checkHandler_queryMetrics(t *testing.T) {
+	lastKnown := sdk.TimestampedMetrics{{Value: 5}}
+
+	testCases := []struct {
+		name            string
+		failures        int
+		empty           bool
+		retryBudget     time.Duration
+		fallbackMaxAge  time.Duration
+		state           policy.CheckQueryState
+		expectedMetrics sdk.TimestampedMetrics
+		expectedErr     bool
+		// expectedAttempts is the exact number of attempts expected. If zero,
+		// the query is only expected to have been retried.
+		expectedAttempts int
+		expectedFallback bool
+		expectedResult   bool
+	}{
+		{
+			name:             "success",
+			expectedMetrics:  sdk.TimestampedMetrics{{Value: 10}},
+			expectedAttempts: 1,
+		},
+		{
+			name:             "failure without retries",
+			failures:         1,
+			expectedErr:      true,
+			expectedAttempts: 1,
+		},
+		{
+			name:             "success after retries",
+			failures:         2,
+			retryBudget:      time.Second,
+			expectedMetrics:  sdk.TimestampedMetrics{{Value: 10}},
+			expectedAttempts: 3,
+			expectedResult:   true,
+		},
+		{
+			name:           "retry budget exhausted",
+			failures:       100,
+			retryBudget:    50 * time.Millisecond,
+			expectedErr:    true,
+			expectedResult: true,
+		},
+		{
+			name:             "retries skipped by circuit breaker",
+			failures:         2,
+			retryBudget:      time.Second,
+			state:            policy.CheckQueryState{Failures: queryCircuitBreakerThreshold},
+			expectedErr:      true,
+			expectedAttempts: 1,
+			expectedResult:   true,
+		},
+		{
+			name:             "fallback to last known metrics",
+			failures:         1,
+			fallbackMaxAge:   time.Minute,
+			state:            policy.CheckQueryState{Metrics: lastKnown, Timestamp: time.Now()},
+			expectedMetrics:  lastKnown,
+			expectedAttempts: 1,
+			expectedFallback: true,
+			expectedResult:   true,
+		},
+		{
+			name:             "last known metrics too old",
+			failures:         1,
+			fallbackMaxAge:   time.Minute,
+			state:            policy.CheckQueryState{Metrics: lastKnown, Timestamp: time.Now().Add(-time.Hour)},
+			expectedErr:      true,
+			expectedAttempts: 1,
+			expectedResult:   true,
+		},
+		{
+			name:             "no metrics",
+			empty:            true,
+			expectedMetrics:  sdk.TimestampedMetrics{},
+			expectedAttempts: 1,
+		},
+		{
+			name:             "no metrics fallback to last known metrics",
+			empty:            true,
+			fallbackMaxAge:   time.Minute,
+			state:            policy.CheckQueryState{Metrics: lastKnown, Timestamp: time.Now()},
+			expectedMetrics:  lastKnown,
+			expectedAttempts: 1,
+			expectedFallback: true,
+			expectedResult:   true,
+		},
+		{
+			name:             "no metrics and last known metrics too old",
+			empty:            true,
+			fallbackMaxAge:   time.Minute,
+			state:            policy.CheckQueryState{Metrics: lastKnown, Timestamp: time.Now().Add(-time.Hour)},
+			expectedMetrics:  sdk.TimestampedMetrics{},
+			expectedAttempts: 1,
+			expectedResult:   true,
+		},
+		{
+			name:             "no last known metrics",
+			failures:         1,
+			fallbackMaxAge:   time.Minute,
+			expectedErr:      true,
+			expectedAttempts: 1,
+			expectedResult:   true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			h := &checkHandler{
+				logger: hclog.NewNullLogger(),
+				policy: &sdk.ScalingPolicy{ID: "policy", Target: &sdk.ScalingPolicyTarget{Name: "target"}},
+				checkEval: &sdk.ScalingCheckEvaluation{
+					Check: &sdk.ScalingPolicyCheck{
+						Name:                "check",
+						Source:              "source",
+						Query:               "query",
+						QueryRetryBudget:    tc.retryBudget,
+						QueryFallbackMaxAge: tc.fallbackMaxAge,
+					},
+				},
+				queryState:   tc.state,
+				retryBackoff: 10 * time.Millisecond,
+			}
+
+			m, err := h.queryMetrics(context.Background(), &testAPM{failures: tc.failures, empty: tc.empty})
+			assert.Equal(t, tc.expectedErr, err != nil)
+			assert.Equal(t, tc.expectedMetrics, m)
+			if tc.expectedAttempts > 0 {
+				assert.Equal(t, tc.expectedAttempts, h.queryAttempts)
+			} else {
+				assert.Greater(t, h.queryAttempts, 1)
+			}
+			assert.Equal(t, tc.expectedFallback, h.metricsFallback)
+
+			// The recorded result must not include the fallback metrics.
+			if tc.expectedResult {
+				if assert.NotNil(t, h.queryResult) {
+					assert.Equal(t, tc.expectedErr || (tc.expectedFallback && !tc.empty), h.queryResult.err != nil)
+				}
+			} else {
+				assert.Nil(t, h.queryResult)
+			}
+		})
+	}
+}
+
+func Test_checkHandler_queryMetrics_cancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	h := &checkHandler{
+		logger: hclog.NewNullLogger(),
+		policy: &sdk.ScalingPolicy{ID: "policy", Target: &sdk.ScalingPolicyTarget{Name: "target"}},
+		checkEval: &sdk.ScalingCheckEvaluation{
+			Check: &sdk.ScalingPolicyCheck{Name: "check", Query: "query", QueryRetryBudget: time.Hour},
+		},
+	}
+
+	_, err := h.queryMetrics(ctx, &testAPM{failures: 100})
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, 1, h.queryAttempts)
+}
+
+func Test_checkHandler_runAPMQuery_lookback(t *testing.T) {
+	testCases := []struct {
+		name            string
+		lookback        time.Duration
+		series          []sdk.TimestampedMetrics
+		expectedMetrics sdk.TimestampedMetrics
+		expectedWindow  time.Duration
+		expectedErr     string
+	}{
+		{
+			name:            "no lookback",
+			expectedMetrics: sdk.TimestampedMetrics{{Value: 10}},
+			expectedWindow:  time.Minute,
+		},
+		{
+			name:            "lookback within query window",
+			lookback:        30 * time.Second,
+			expectedMetrics: sdk.TimestampedMetrics{{Value: 10}},
+			expectedWindow:  time.Minute,
+		},
+		{
+			name:     "lookback extends query window",
+			lookback: time.Hour,
+			series: []sdk.TimestampedMetrics{{
+				{Timestamp: time.Unix(2, 0), Value: 2},
+				{Timestamp: time.Unix(1, 0), Value: 1},
+			}},
+			expectedMetrics: sdk.TimestampedMetrics{
+				{Timestamp: time.Unix(1, 0), Value: 1},
+				{Timestamp: time.Unix(2, 0), Value: 2},
+			},
+			expectedWindow: time.Hour,
+		},
+		{
+			name:            "lookback without metrics",
+			lookback:        time.Hour,
+			expectedMetrics: sdk.TimestampedMetrics{},
+			expectedWindow:  time.Hour,
+		},
+		{
+			name:           "lookback with multiple series",
+			lookback:       time.Hour,
+			series:         []sdk.TimestampedMetrics{{{Value: 1}}, {{Value: 2}}},
+			expectedWindow: time.Hour,
+			expectedErr:    "query returned 2 metric streams, only 1 is expected",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			h := &checkHandler{
+				logger: hclog.NewNullLogger(),
+				policy: &sdk.ScalingPolicy{ID: "policy", Target: &sdk.ScalingPolicyTarget{Name: "target"}},
+				checkEval: &sdk.ScalingCheckEvaluation{
+					Check: &sdk.ScalingPolicyCheck{
+						Name:        "check",
+						Source:      "source",
+						Query:       "query",
+						QueryWindow: time.Minute,
+					},
+				},
+				lookback: tc.lookback,
+			}
+
+			source := &testAPM{series: tc.series}
+			m, err := h.runAPMQuery(source)
+			if tc.expectedErr != "" {
+				assert.EqualError(t, err, tc.expectedErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expectedMetrics, m)
+			}
+			assert.Equal(t, tc.expectedWindow, source.lastRange.To.Sub(source.lastRange.From))
+		})
+	}
+}
+
+// testLabeledAPM is a testAPM which labels the series it returns.
+type testLabeledAPM struct {
+	testAPM
+	labels map[string]string
+}
+
+func (a *testLabeledAPM) QueryLabeled(q string, r sdk.TimeRange) (sdk.LabeledTimestampedMetrics, error) {
+	m, err := a.Query(q, r)
+	return sdk.LabeledTimestampedMetrics{Labels: a.labels, Metrics: m}, err
+}
+
+func (a *testLabeledAPM) QueryMultipleLabeled(q string, r sdk.TimeRange) ([]sdk.LabeledTimestampedMetrics, error) {
+	series, err := a.QueryMultiple(q, r)
+	out := make([]sdk.LabeledTimestampedMetrics, len(series))
+	for i, m := range series {
+		out[i] = sdk.LabeledTimestampedMetrics{Labels: map[string]string{"series": strconv.Itoa(i)}, Metrics: m}
+	}
+	return out, err
+}
+
+func Test_checkHandler_runAPMQuery_labels(t *testing.T) {
+	newHandler := func(lookback time.Duration) *checkHandler {
+		return &checkHandler{
+			logger: hclog.NewNullLogger(),
+			policy: &sdk.ScalingPolicy{ID: "policy", Target: &sdk.ScalingPolicyTarget{Name: "target"}},
+			checkEval: &sdk.ScalingCheckEvaluation{
+				Check: &sdk.ScalingPolicyCheck{Name: "check", Source: "source", Query: "query", QueryWindow: time.Minute},
+			},
+			lookback: lookback,
+		}
+	}
+
+	// The labels of the query series are recorded.
+	h := newHandler(0)
+	m, err := h.runAPMQuery(&testLabeledAPM{labels: map[string]string{"job": "web"}})
+	assert.NoError(t, err)
+	assert.Equal(t, sdk.TimestampedMetrics{{Value: 10}}, m)
+	assert.Equal(t, map[string]string{"job": "web"}, h.seriesLabels)
+
+	// So are the ones of the lookback series.
+	h = newHandler(time.Hour)
+	_, err = h.runAPMQuery(&testLabeledAPM{testAPM: testAPM{series: []sdk.TimestampedMetrics{{{Value: 1}}}}})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"series": "0"}, h.seriesLabels)
+
+	// Multiple series are reported using their labels.
+	h = newHandler(time.Hour)
+	_, err = h.runAPMQuery(&testLabeledAPM{testAPM: testAPM{series: []sdk.TimestampedMetrics{{{Value: 1}}, {{Value: 2}}}}})
+	assert.EqualError(t, err, `query returned 2 metric streams, only 1 is expected: {series="0"}, {series="1"}`)
+}
+
+// testLookbackStrategy is a strategy that requires the lookback set in its
+// check config.
+type testLookbackStrategy struct {
+	strategy.Strategy
+}
+
+func (s *testLookbackStrategy) Lookback(check *sdk.ScalingPolicyCheck) (time.Duration, error) {
+	return time.ParseDuration(check.Strategy.Config["lookback"])
+}
+
+func Test_checkHandler_runStrategyLookback(t *testing.T) {
+	testCases := []struct {
+		name     string
+		impl     strategy.Strategy
+		lookback string
+		expected time.Duration
+	}{
+		{
+			name:     "strategy without capability",
+			impl:     targetvalue.NewTargetValuePlugin(hclog.NewNullLogger()),
+			expected: 0,
+		},
+		{
+			name:     "strategy with lookback",
+			impl:     &testLookbackStrategy{},
+			lookback: "24h",
+			expected: 24 * time.Hour,
+		},
+		{
+			name:     "failed lookback uses query window",
+			impl:     &testLookbackStrategy{},
+			lookback: "invalid",
+			expected: 0,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			h := &checkHandler{
+				logger: hclog.NewNullLogger(),
+				policy: &sdk.ScalingPolicy{ID: "policy"},
+				checkEval: &sdk.ScalingCheckEvaluation{
+					Check: &sdk.ScalingPolicyCheck{
+						Name: "check",
+						Strategy: &sdk.ScalingPolicyStrategy{
+							Name:   "strategy",
+							Config: map[string]string{"lookback": tc.lookback},
+						},
+					},
+				},
+			}
+			assert.Equal(t, tc.expected, h.runStrategyLookback(tc.impl))
+		})
+	}
+}
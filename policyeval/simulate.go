@@ -0,0 +1,261 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package policyeval
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/hashicorp/nomad-autoscaler/plugins/apm"
+	"github.com/hashicorp/nomad-autoscaler/plugins/strategy"
+	"github.com/hashicorp/nomad-autoscaler/policy"
+	"github.com/hashicorp/nomad-autoscaler/sdk"
+)
+
+// SimulationPlugins is the subset of the plugin manager used to simulate a
+// policy.
+type SimulationPlugins interface {
+	GetScopedAPM(policyID, source string, overrides map[string]string) (apm.APM, error)
+	GetStrategy(name string) (strategy.Strategy, error)
+}
+
+// SimulationStep is the result of a single simulated evaluation of a policy.
+type SimulationStep struct {
+	// Time is the time of the evaluation.
+	Time time.Time
+
+	// Count is the count of the target after the evaluation.
+	Count int64
+
+	// Direction and Reason describe the action that won the evaluation.
+	Direction sdk.ScaleDirection
+	Reason    string
+
+	// Check is the name of the check that produced the action, if any.
+	Check string
+
+	// Metrics holds the last metric value available to each check, keyed by
+	// check name. Checks without metrics are not included.
+	Metrics map[string]float64
+}
+
+// Simulate replays the historical metrics of the policy checks within the
+// time range through their strategies, evaluating the policy once per
+// evaluation interval starting from the initial count. Policy limits and
+// cooldown are respected, but check groups and the target state are not
+// considered.
+func Simulate(plugins SimulationPlugins, p *sdk.ScalingPolicy, r sdk.TimeRange, initial int64) ([]SimulationStep, error) {
+	if p.EvaluationInterval <= 0 {
+		return nil, errors.New("policy evaluation interval must be positive")
+	}
+	if !r.From.Before(r.To) {
+		return nil, errors.New("time range start must be before its end")
+	}
+
+	// Query the metrics of all checks once for the whole range, so each
+	// evaluation only needs to select the metrics within its query window.
+	metrics := make(map[string]sdk.TimestampedMetrics, len(p.Checks))
+	strategies := make(map[string]strategy.Strategy, len(p.Checks))
+	windows := make(map[string]time.Duration, len(p.Checks))
+
+	for _, c := range p.Checks {
+		s, err := plugins.GetStrategy(c.Strategy.Name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to dispense strategy for check %s: %v", c.Name, err)
+		}
+		strategies[c.Name] = s
+
+		window, err := simulationQueryWindow(c, s)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read strategy lookback for check %s: %v", c.Name, err)
+		}
+		windows[c.Name] = window
+
+		m, err := querySimulationMetrics(plugins, p, c, window, r)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query metrics for check %s: %v", c.Name, err)
+		}
+		metrics[c.Name] = m
+	}
+
+	var (
+		steps         []SimulationStep
+		history       = make(map[string][]sdk.ScalingCheckHistoryEntry)
+		count         = initial
+		cooldownUntil time.Time
+	)
+
+	for t := r.From; !t.After(r.To); t = t.Add(p.EvaluationInterval) {
+		step := SimulationStep{
+			Time:      t,
+			Direction: sdk.ScaleDirectionNone,
+			Metrics:   make(map[string]float64),
+		}
+
+		if t.Before(cooldownUntil) {
+			step.Count = count
+			step.Reason = "policy in cooldown"
+			steps = append(steps, step)
+			continue
+		}
+
+		var winner *sdk.ScalingAction
+		eval := sdk.NewScalingEvaluation(p)
+
+		for _, checkEval := range orderCheckEvaluations(eval.CheckEvaluations) {
+			c := checkEval.Check
+
+			if c.When != "" {
+				run, err := policy.EvaluateWhen(c.When, &policy.WhenContext{
+					Time:        t,
+					TargetCount: count,
+					Metrics:     step.Metrics,
+				})
+				if err != nil || !run {
+					continue
+				}
+			}
+
+			// Checks without a query, such as the ones using the
+			// fixed-value strategy, don't need metrics.
+			if c.Query != "" {
+				to := t.Add(-c.QueryWindowOffset)
+				checkEval.Metrics = metricsInRange(metrics[c.Name], to.Add(-windows[c.Name]), to)
+				if len(checkEval.Metrics) == 0 {
+					continue
+				}
+				step.Metrics[c.Name] = checkEval.Metrics[len(checkEval.Metrics)-1].Value
+			}
+
+			if c.HistorySize > 0 {
+				checkEval.History = history[c.Name]
+			}
+
+			result, err := strategies[c.Name].Run(checkEval, count)
+			if err != nil {
+				return nil, fmt.Errorf("failed to run strategy for check %s at %s: %v", c.Name, t, err)
+			}
+			if result == nil || result.Action == nil {
+				continue
+			}
+			action := result.Action
+
+			if c.HistorySize > 0 {
+				entry := sdk.ScalingCheckHistoryEntry{
+					Timestamp: t,
+					Count:     count,
+					Action:    *copyAction(action),
+					Metric:    step.Metrics[c.Name],
+				}
+				history[c.Name] = append(history[c.Name], entry)
+				if len(history[c.Name]) > c.HistorySize {
+					history[c.Name] = history[c.Name][1:]
+				}
+			}
+
+			if action.Direction == sdk.ScaleDirectionNone {
+				continue
+			}
+
+			action.Canonicalize()
+			action.CapCount(p.Min, p.Max)
+			action.CapScaleIn(count, p.MaxUnavailable)
+
+			if action.Count == count {
+				continue
+			}
+			if sdk.PreemptScalingAction(winner, action) == action {
+				winner = action
+				step.Check = c.Name
+			}
+		}
+
+		// Make sure the count is within the policy limits, even if no check
+		// requested a change.
+		if winner == nil {
+			if count < p.Min {
+				winner = &sdk.ScalingAction{Count: p.Min, Direction: sdk.ScaleDirectionUp,
+					Reason: fmt.Sprintf("current count (%d) below limit (%d)", count, p.Min)}
+			} else if count > p.Max {
+				winner = &sdk.ScalingAction{Count: p.Max, Direction: sdk.ScaleDirectionDown,
+					Reason: fmt.Sprintf("current count (%d) above limit (%d)", count, p.Max)}
+			}
+		}
+
+		if winner != nil {
+			count = winner.Count
+			step.Direction = winner.Direction
+			step.Reason = winner.Reason
+			cooldownUntil = t.Add(p.Cooldown)
+		}
+		step.Count = count
+		steps = append(steps, step)
+	}
+
+	return steps, nil
+}
+
+// simulationQueryWindow returns the window of metrics passed to the strategy
+// of the check. It is extended when the strategy implements the
+// strategy.LookbackStrategy capability and requires more history than the
+// check query window.
+func simulationQueryWindow(c *sdk.ScalingPolicyCheck, s strategy.Strategy) (time.Duration, error) {
+	lookbackImpl, ok := s.(strategy.LookbackStrategy)
+	if !ok {
+		return c.QueryWindow, nil
+	}
+
+	lookback, err := lookbackImpl.Lookback(c)
+	if err != nil {
+		return 0, err
+	}
+	if lookback > c.QueryWindow {
+		return lookback, nil
+	}
+	return c.QueryWindow, nil
+}
+
+// querySimulationMetrics queries the metrics of the check for the whole time
+// range, including the query window and offset of the first evaluation.
+func querySimulationMetrics(plugins SimulationPlugins, p *sdk.ScalingPolicy, c *sdk.ScalingPolicyCheck, window time.Duration, r sdk.TimeRange) (sdk.TimestampedMetrics, error) {
+	if c.Query == "" {
+		return nil, nil
+	}
+
+	query, err := policy.RenderQuery(p, c)
+	if err != nil {
+		return nil, err
+	}
+
+	apmImpl, err := plugins.GetScopedAPM(p.ID, c.Source, c.SourceConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	queryRange := sdk.TimeRange{
+		From: r.From.Add(-c.QueryWindowOffset - window),
+		To:   r.To.Add(-c.QueryWindowOffset),
+	}
+	m, err := queryLookback(apmImpl, query, queryRange)
+	if err != nil {
+		return nil, err
+	}
+	return m.Metrics, nil
+}
+
+// metricsInRange returns the sorted metrics with a timestamp within from and
+// to, inclusive.
+func metricsInRange(m sdk.TimestampedMetrics, from, to time.Time) sdk.TimestampedMetrics {
+	start := sort.Search(len(m), func(i int) bool { return !m[i].Timestamp.Before(from) })
+	end := sort.Search(len(m), func(i int) bool { return m[i].Timestamp.After(to) })
+	if start >= end {
+		return sdk.TimestampedMetrics{}
+	}
+
+	result := make(sdk.TimestampedMetrics, end-start)
+	copy(result, m[start:end])
+	return result
+}
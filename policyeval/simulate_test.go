@@ -0,0 +1,138 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package policyeval
+
+import (
+	"testing"
+	"time"
+
+	hclog "github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/nomad-autoscaler/plugins/apm"
+	"github.com/hashicorp/nomad-autoscaler/plugins/base"
+	targetvalue "github.com/hashicorp/nomad-autoscaler/plugins/builtin/strategy/target-value/plugin"
+	"github.com/hashicorp/nomad-autoscaler/plugins/strategy"
+	"github.com/hashicorp/nomad-autoscaler/sdk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testSimulationAPM struct {
+	series []sdk.TimestampedMetrics
+}
+
+func (a *testSimulationAPM) PluginInfo() (*base.PluginInfo, error) { return &base.PluginInfo{}, nil }
+func (a *testSimulationAPM) SetConfig(map[string]string) error     { return nil }
+
+func (a *testSimulationAPM) Query(string, sdk.TimeRange) (sdk.TimestampedMetrics, error) {
+	return a.series[0], nil
+}
+
+func (a *testSimulationAPM) QueryMultiple(string, sdk.TimeRange) ([]sdk.TimestampedMetrics, error) {
+	return a.series, nil
+}
+
+type testSimulationPlugins struct {
+	apm apm.APM
+}
+
+func (p *testSimulationPlugins) GetScopedAPM(string, string, map[string]string) (apm.APM, error) {
+	return p.apm, nil
+}
+
+func (p *testSimulationPlugins) GetStrategy(string) (strategy.Strategy, error) {
+	return targetvalue.NewTargetValuePlugin(hclog.NewNullLogger()), nil
+}
+
+func TestSimulate(t *testing.T) {
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	r := sdk.TimeRange{From: from, To: from.Add(4 * time.Minute)}
+
+	p := &sdk.ScalingPolicy{
+		ID:                 "policy",
+		Min:                1,
+		Max:                10,
+		Cooldown:           2 * time.Minute,
+		EvaluationInterval: time.Minute,
+		Checks: []*sdk.ScalingPolicyCheck{
+			{
+				Name:        "check",
+				Source:      "apm",
+				Query:       "query",
+				QueryWindow: time.Minute,
+				Strategy: &sdk.ScalingPolicyStrategy{
+					Name:   "target-value",
+					Config: map[string]string{"target": "50"},
+				},
+			},
+		},
+	}
+
+	plugins := &testSimulationPlugins{apm: &testSimulationAPM{
+		series: []sdk.TimestampedMetrics{{
+			{Timestamp: from.Add(3*time.Minute + 30*time.Second), Value: 25},
+			{Timestamp: from.Add(-30 * time.Second), Value: 100},
+			{Timestamp: from.Add(90 * time.Second), Value: 100},
+		}},
+	}}
+
+	steps, err := Simulate(plugins, p, r, 2)
+	require.NoError(t, err)
+	require.Len(t, steps, 5)
+
+	counts := make([]int64, len(steps))
+	for i, s := range steps {
+		counts[i] = s.Count
+	}
+	assert.Equal(t, []int64{4, 4, 8, 8, 4}, counts)
+
+	assert.Equal(t, sdk.ScaleDirection(sdk.ScaleDirectionUp), steps[0].Direction)
+	assert.Equal(t, "check", steps[0].Check)
+	assert.Equal(t, map[string]float64{"check": 100}, steps[0].Metrics)
+	assert.Equal(t, "policy in cooldown", steps[1].Reason)
+	assert.Equal(t, sdk.ScaleDirection(sdk.ScaleDirectionDown), steps[4].Direction)
+
+	// Queries must return a single series.
+	plugins.apm = &testSimulationAPM{series: []sdk.TimestampedMetrics{{}, {}}}
+	_, err = Simulate(plugins, p, r, 2)
+	assert.EqualError(t, err, "failed to query metrics for check check: query returned 2 metric streams, only 1 is expected")
+}
+
+func Test_metricsInRange(t *testing.T) {
+	ts := time.Now()
+	m := sdk.TimestampedMetrics{
+		{Timestamp: ts, Value: 1},
+		{Timestamp: ts.Add(time.Minute), Value: 2},
+		{Timestamp: ts.Add(2 * time.Minute), Value: 3},
+	}
+
+	assert.Equal(t, m[1:], metricsInRange(m, ts.Add(time.Second), ts.Add(2*time.Minute)))
+	assert.Equal(t, sdk.TimestampedMetrics{}, metricsInRange(m, ts.Add(3*time.Minute), ts.Add(4*time.Minute)))
+}
+
+func Test_simulationQueryWindow(t *testing.T) {
+	check := &sdk.ScalingPolicyCheck{
+		QueryWindow: time.Hour,
+		Strategy:    &sdk.ScalingPolicyStrategy{Config: map[string]string{}},
+	}
+
+	// Strategies without the capability use the check query window.
+	window, err := simulationQueryWindow(check, targetvalue.NewTargetValuePlugin(hclog.NewNullLogger()))
+	require.NoError(t, err)
+	assert.Equal(t, time.Hour, window)
+
+	// Lookbacks smaller than the query window are ignored.
+	check.Strategy.Config["lookback"] = "10m"
+	window, err = simulationQueryWindow(check, &testLookbackStrategy{})
+	require.NoError(t, err)
+	assert.Equal(t, time.Hour, window)
+
+	check.Strategy.Config["lookback"] = "24h"
+	window, err = simulationQueryWindow(check, &testLookbackStrategy{})
+	require.NoError(t, err)
+	assert.Equal(t, 24*time.Hour, window)
+
+	check.Strategy.Config["lookback"] = "invalid"
+	_, err = simulationQueryWindow(check, &testLookbackStrategy{})
+	assert.Error(t, err)
+}
@@ -0,0 +1,180 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package policyeval
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	hclog "github.com/hashicorp/go-hclog"
+)
+
+// EvalTokenStore records the token of the evaluation in flight for each
+// policy in a storage shared by all the agents of a high availability
+// deployment. It prevents the agent that becomes leader from evaluating a
+// policy while the previous leader is still running an evaluation for it.
+type EvalTokenStore interface {
+	// Acquire records the token as the one of the evaluation in flight for
+	// the policy. It returns false, without recording the token, if another
+	// token that hasn't expired is already recorded for the policy. The
+	// recorded token expires after ttl.
+	Acquire(policyID, token string, ttl time.Duration) (bool, error)
+
+	// Release removes the token recorded for the policy if it's still the
+	// passed token.
+	Release(policyID, token string) error
+}
+
+// singleFlight guarantees that only one evaluation per policy runs at a
+// time. Evaluations are identified by the token returned by the broker when
+// they are dequeued, so an evaluation that is redelivered after its ACK
+// timeout doesn't run while its previous delivery is still in flight.
+type singleFlight struct {
+	logger hclog.Logger
+
+	lock sync.Mutex
+
+	// tokens holds the evaluation in flight for each policy ID in this
+	// agent.
+	tokens map[string]*inflightEval
+
+	// store is the optional EvalTokenStore where tokens are also recorded to
+	// be shared with other agents.
+	store EvalTokenStore
+
+	// ttl is how long tokens recorded in the store are valid. It bounds how
+	// long a policy can't be evaluated if an agent stops without releasing
+	// its tokens. Tokens are refreshed while their evaluation runs, so
+	// evaluations can take longer than ttl.
+	ttl time.Duration
+}
+
+// inflightEval is an evaluation in flight in this agent.
+type inflightEval struct {
+	token string
+
+	// stopRefresh stops refreshing the token in the store, and refreshDone
+	// is closed once the refresh has stopped. They are nil if the token is
+	// not recorded in a store.
+	stopRefresh chan struct{}
+	refreshDone chan struct{}
+}
+
+func newSingleFlight(logger hclog.Logger, ttl time.Duration) *singleFlight {
+	return &singleFlight{
+		logger: logger,
+		tokens: make(map[string]*inflightEval),
+		ttl:    ttl,
+	}
+}
+
+// acquire marks the evaluation with the token as in flight for the policy.
+// It returns false if another evaluation for the policy is in flight in
+// this agent or in any other agent sharing the store.
+func (s *singleFlight) acquire(policyID, token string) (bool, error) {
+	s.lock.Lock()
+	if current, ok := s.tokens[policyID]; ok {
+		s.lock.Unlock()
+		return current.token == token, nil
+	}
+	inflight := &inflightEval{token: token}
+	s.tokens[policyID] = inflight
+	s.lock.Unlock()
+
+	if s.store == nil {
+		return true, nil
+	}
+
+	// The token is reserved locally while the store is called, so the lock
+	// is not held during the request.
+	ok, err := s.store.Acquire(policyID, token, s.ttl)
+	if err != nil || !ok {
+		s.forget(policyID, token)
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to record evaluation token: %v", err)
+	}
+	if !ok {
+		return false, nil
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	// The evaluation may have been released while the store was called.
+	if s.tokens[policyID] == inflight && s.ttl > 0 {
+		inflight.stopRefresh = make(chan struct{})
+		inflight.refreshDone = make(chan struct{})
+		go s.refresh(policyID, inflight)
+	}
+	return true, nil
+}
+
+// refresh records the token in the store again every third of the ttl, so
+// it doesn't expire while the evaluation is running. It runs until the
+// evaluation is released.
+func (s *singleFlight) refresh(policyID string, inflight *inflightEval) {
+	defer close(inflight.refreshDone)
+
+	ticker := time.NewTicker(s.ttl / 3)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-inflight.stopRefresh:
+			return
+		case <-ticker.C:
+		}
+
+		ok, err := s.store.Acquire(policyID, inflight.token, s.ttl)
+		switch {
+		case err != nil:
+			s.logger.Warn("failed to refresh evaluation token",
+				"policy_id", policyID, "token", inflight.token, "error", err)
+		case !ok:
+			s.logger.Warn("evaluation token expired while the evaluation was running",
+				"policy_id", policyID, "token", inflight.token)
+			return
+		}
+	}
+}
+
+// release marks the evaluation with the token as done for the policy.
+func (s *singleFlight) release(policyID, token string) error {
+	inflight := s.forget(policyID, token)
+	if inflight == nil || s.store == nil {
+		return nil
+	}
+
+	// Wait for any refresh in progress, so the token is not recorded again
+	// after it's removed.
+	if inflight.refreshDone != nil {
+		<-inflight.refreshDone
+	}
+
+	if err := s.store.Release(policyID, token); err != nil {
+		return fmt.Errorf("failed to remove evaluation token: %v", err)
+	}
+	return nil
+}
+
+// forget removes the evaluation of the policy if its token is still the
+// passed token, stopping the refresh of the token. It returns the removed
+// evaluation, or nil if none was removed.
+func (s *singleFlight) forget(policyID, token string) *inflightEval {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	inflight, ok := s.tokens[policyID]
+	if !ok || inflight.token != token {
+		return nil
+	}
+	delete(s.tokens, policyID)
+
+	if inflight.stopRefresh != nil {
+		close(inflight.stopRefresh)
+	}
+	return inflight
+}
@@ -0,0 +1,182 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package policyeval
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	hclog "github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/nomad-autoscaler/sdk"
+	"github.com/shoenig/test/must"
+	"github.com/shoenig/test/wait"
+)
+
+// testEvalTokenStore is an in-memory EvalTokenStore shared by multiple
+// brokers, like the agents of a high availability deployment.
+type testEvalTokenStore struct {
+	lock     sync.Mutex
+	tokens   map[string]string
+	err      error
+	acquires int
+}
+
+func (s *testEvalTokenStore) Acquire(policyID, token string, _ time.Duration) (bool, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.acquires++
+
+	if s.err != nil {
+		return false, s.err
+	}
+	if current, ok := s.tokens[policyID]; ok && current != token {
+		return false, nil
+	}
+	s.tokens[policyID] = token
+	return true, nil
+}
+
+func (s *testEvalTokenStore) Release(policyID, token string) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.tokens[policyID] == token {
+		delete(s.tokens, policyID)
+	}
+	return nil
+}
+
+func TestBroker_StartEval_redelivery(t *testing.T) {
+	b := NewBroker(hclog.NewNullLogger(), 50*time.Millisecond, 3)
+
+	eval := &sdk.ScalingEvaluation{
+		ID:     "eval1",
+		Policy: &sdk.ScalingPolicy{ID: "policy1", Type: "horizontal"},
+	}
+	b.Enqueue(eval)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, token1, err := b.Dequeue(ctx, "horizontal")
+	must.NoError(t, err)
+
+	started, err := b.StartEval(eval, token1)
+	must.NoError(t, err)
+	must.True(t, started)
+
+	// The eval is redelivered once the nack timeout is reached, but it must
+	// not run while the first delivery is still in flight.
+	_, token2, err := b.Dequeue(ctx, "horizontal")
+	must.NoError(t, err)
+	must.NotEq(t, token1, token2)
+
+	started, err = b.StartEval(eval, token2)
+	must.NoError(t, err)
+	must.False(t, started)
+
+	// Evals for other policies are not affected.
+	other := &sdk.ScalingEvaluation{ID: "eval2", Policy: &sdk.ScalingPolicy{ID: "policy2"}}
+	started, err = b.StartEval(other, token2)
+	must.NoError(t, err)
+	must.True(t, started)
+
+	// Finishing a delivery that didn't start is a no-op.
+	must.NoError(t, b.FinishEval(eval, token2))
+	started, err = b.StartEval(eval, token2)
+	must.NoError(t, err)
+	must.False(t, started)
+
+	// Once the first delivery finishes, the policy can be evaluated again.
+	must.NoError(t, b.FinishEval(eval, token1))
+	started, err = b.StartEval(eval, token2)
+	must.NoError(t, err)
+	must.True(t, started)
+}
+
+func TestBroker_StartEval_store(t *testing.T) {
+	store := &testEvalTokenStore{tokens: make(map[string]string)}
+	eval := &sdk.ScalingEvaluation{ID: "eval1", Policy: &sdk.ScalingPolicy{ID: "policy1"}}
+
+	// The previous leader is still evaluating the policy.
+	previous := NewBroker(hclog.NewNullLogger(), time.Minute, 1)
+	previous.SetEvalTokenStore(store)
+
+	started, err := previous.StartEval(eval, "token1")
+	must.NoError(t, err)
+	must.True(t, started)
+
+	// The new leader must not evaluate the policy until the previous leader
+	// finishes.
+	leader := NewBroker(hclog.NewNullLogger(), time.Minute, 1)
+	leader.SetEvalTokenStore(store)
+
+	started, err = leader.StartEval(eval, "token2")
+	must.NoError(t, err)
+	must.False(t, started)
+
+	must.NoError(t, previous.FinishEval(eval, "token1"))
+
+	started, err = leader.StartEval(eval, "token2")
+	must.NoError(t, err)
+	must.True(t, started)
+	must.Eq(t, "token2", store.tokens["policy1"])
+
+	// Store errors prevent the evaluation from running and don't leave the
+	// policy marked as in flight.
+	must.NoError(t, leader.FinishEval(eval, "token2"))
+	store.err = errors.New("nomad unavailable")
+
+	started, err = leader.StartEval(eval, "token3")
+	must.ErrorContains(t, err, "nomad unavailable")
+	must.False(t, started)
+
+	store.err = nil
+	started, err = leader.StartEval(eval, "token3")
+	must.NoError(t, err)
+	must.True(t, started)
+}
+
+func TestBroker_StartEval_refresh(t *testing.T) {
+	store := &testEvalTokenStore{tokens: make(map[string]string)}
+	eval := &sdk.ScalingEvaluation{ID: "eval1", Policy: &sdk.ScalingPolicy{ID: "policy1"}}
+
+	b := NewBroker(hclog.NewNullLogger(), 30*time.Millisecond, 1)
+	b.SetEvalTokenStore(store)
+
+	started, err := b.StartEval(eval, "token1")
+	must.NoError(t, err)
+	must.True(t, started)
+
+	// The token is refreshed while the evaluation runs, so evaluations
+	// longer than the ack timeout keep the policy locked.
+	must.Wait(t, wait.InitialSuccess(
+		wait.BoolFunc(func() bool {
+			store.lock.Lock()
+			defer store.lock.Unlock()
+			return store.acquires >= 3
+		}),
+		wait.Timeout(time.Second),
+		wait.Gap(10*time.Millisecond),
+	))
+
+	// Refreshes stop once the evaluation finishes.
+	must.NoError(t, b.FinishEval(eval, "token1"))
+
+	store.lock.Lock()
+	acquires := store.acquires
+	must.MapEmpty(t, store.tokens)
+	store.lock.Unlock()
+
+	time.Sleep(50 * time.Millisecond)
+
+	store.lock.Lock()
+	defer store.lock.Unlock()
+	must.Eq(t, acquires, store.acquires)
+	must.MapEmpty(t, store.tokens)
+}
@@ -0,0 +1,219 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package policyeval
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/armon/go-metrics"
+	hclog "github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/nomad-autoscaler/plugins/manager"
+	"github.com/hashicorp/nomad-autoscaler/policy"
+	"github.com/hashicorp/nomad-autoscaler/sdk"
+)
+
+const (
+	// stuckScalingCheckInterval is the interval at which the policy handlers
+	// are checked for stuck scaling operations.
+	stuckScalingCheckInterval = 30 * time.Second
+
+	// stuckScalingDefaultExpected is the expected duration of the target
+	// scale operation when there are no recent calls to the target plugin.
+	stuckScalingDefaultExpected = time.Minute
+
+	// stuckScalingMinThreshold is the minimum amount of time a policy must be
+	// scaling before it's considered stuck, so fast targets are not reported
+	// due to small variations in latency.
+	stuckScalingMinThreshold = time.Minute
+)
+
+// scalingOps tracks the scaling operations being executed by all the workers
+// of the agent.
+var scalingOps = newScalingOpRegistry()
+
+// scalingOp is a target scale operation in progress.
+type scalingOp struct {
+	policy *sdk.ScalingPolicy
+	action sdk.ScalingAction
+	count  int64
+}
+
+// scalingOpRegistry holds the scaling operation in progress of each policy.
+type scalingOpRegistry struct {
+	lock sync.RWMutex
+	ops  map[string]scalingOp
+}
+
+func newScalingOpRegistry() *scalingOpRegistry {
+	return &scalingOpRegistry{ops: make(map[string]scalingOp)}
+}
+
+func (r *scalingOpRegistry) start(p *sdk.ScalingPolicy, action sdk.ScalingAction, count int64) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.ops[p.ID] = scalingOp{policy: p, action: action, count: count}
+}
+
+func (r *scalingOpRegistry) done(policyID string) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	delete(r.ops, policyID)
+}
+
+func (r *scalingOpRegistry) get(policyID string) (scalingOp, bool) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	op, ok := r.ops[policyID]
+	return op, ok
+}
+
+// StuckScalingMonitor detects policy handlers that remain in the scaling
+// state for longer than expected, which may happen if a target plugin call
+// hangs. Stuck policies are reported and, once their target status is
+// verified, their handlers are moved back to idle.
+type StuckScalingMonitor struct {
+	logger        hclog.Logger
+	pluginManager *manager.PluginManager
+	policyManager *policy.Manager
+
+	// multiplier is the multiple of the expected target scale duration after
+	// which scaling is considered stuck.
+	multiplier float64
+
+	// now returns the current time and can be replaced in tests.
+	now func() time.Time
+
+	// scalingOps holds the scaling operations in progress.
+	scalingOps *scalingOpRegistry
+
+	// expectedDuration returns the expected duration of the scale operation
+	// of the named target plugin.
+	expectedDuration func(target string) time.Duration
+}
+
+// NewStuckScalingMonitor returns a new StuckScalingMonitor instance.
+func NewStuckScalingMonitor(l hclog.Logger, pm *manager.PluginManager, m *policy.Manager, multiplier float64) *StuckScalingMonitor {
+	return &StuckScalingMonitor{
+		logger:           l.Named("stuck_scaling_monitor"),
+		pluginManager:    pm,
+		policyManager:    m,
+		multiplier:       multiplier,
+		now:              time.Now,
+		scalingOps:       scalingOps,
+		expectedDuration: expectedScaleDuration,
+	}
+}
+
+// Run periodically checks the policy handlers for stuck scaling operations
+// until the context is canceled.
+func (s *StuckScalingMonitor) Run(ctx context.Context) {
+	ticker := time.NewTicker(stuckScalingCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.check()
+		}
+	}
+}
+
+// check reports and recovers the handlers that have been scaling for longer
+// than the threshold of their target.
+func (s *StuckScalingMonitor) check() {
+	for _, status := range s.policyManager.HandlerStatuses() {
+		if status.State != policy.HandlerStateScaling {
+			continue
+		}
+
+		op, ok := s.scalingOps.get(status.PolicyID)
+
+		var target string
+		if ok {
+			target = op.policy.Target.Name
+		}
+
+		elapsed := s.now().Sub(status.Since)
+		threshold := s.threshold(target)
+		if elapsed < threshold {
+			continue
+		}
+
+		logger := s.logger.With("policy_id", status.PolicyID, "elapsed", elapsed, "threshold", threshold)
+		logger.Warn("policy has been scaling for longer than expected")
+		metrics.IncrCounterWithLabels([]string{"policy", "handler", "stuck_scaling"}, 1,
+			[]metrics.Label{{Name: "policy_id", Value: status.PolicyID}})
+
+		// If no scaling operation is in progress the handler state is out of
+		// sync, so there's nothing to verify.
+		if !ok {
+			logger.Info("no scaling operation in progress, moving policy handler to idle")
+			s.policyManager.RecordEvaluationDone(status.PolicyID)
+			continue
+		}
+
+		s.recoverHandler(logger, op, elapsed)
+	}
+}
+
+// recoverHandler publishes an event for the stuck scaling operation and
+// verifies the status of its target. The policy handler is moved back to idle
+// once the target is ready, so the policy evaluations are not blocked by the
+// stuck operation.
+func (s *StuckScalingMonitor) recoverHandler(logger hclog.Logger, op scalingOp, elapsed time.Duration) {
+	stuckErr := fmt.Errorf("scaling operation has not completed after %s", elapsed.Round(time.Second))
+	publishEvent(logger, s.pluginManager, newScalingEvent(op.policy, "", op.count, op.action, stuckErr))
+
+	targetImpl, err := s.pluginManager.GetTarget(op.policy.ID, op.policy.Target)
+	if err != nil {
+		logger.Warn("failed to get target to verify stuck scaling", "error", err)
+		return
+	}
+
+	status, err := runTargetStatus(targetImpl, op.policy)
+	if err != nil {
+		logger.Warn("failed to get target status to verify stuck scaling", "error", err)
+		return
+	}
+	if status == nil || !status.Ready {
+		logger.Warn("target is not ready, policy will remain in scaling state")
+		return
+	}
+
+	logger.Info("target status verified, moving policy handler to idle", "count", status.Count)
+	s.policyManager.RecordEvaluationDone(op.policy.ID)
+}
+
+// threshold returns the amount of time a policy using the named target plugin
+// can be scaling before it's considered stuck.
+func (s *StuckScalingMonitor) threshold(target string) time.Duration {
+	expected := stuckScalingDefaultExpected
+	if target != "" && s.expectedDuration != nil {
+		if d := s.expectedDuration(target); d > 0 {
+			expected = d
+		}
+	}
+
+	threshold := time.Duration(float64(expected) * s.multiplier)
+	if threshold < stuckScalingMinThreshold {
+		threshold = stuckScalingMinThreshold
+	}
+	return threshold
+}
+
+// expectedScaleDuration returns the P99 latency of the recent scale calls to
+// the named target plugin, or zero if there are none.
+func expectedScaleDuration(target string) time.Duration {
+	for _, t := range PluginTimings() {
+		if t.PluginType == "target" && t.PluginName == target && t.Operation == "scale" {
+			return time.Duration(t.P99Ms * float64(time.Millisecond))
+		}
+	}
+	return 0
+}
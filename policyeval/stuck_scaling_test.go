@@ -0,0 +1,77 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package policyeval
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hashicorp/nomad-autoscaler/sdk"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStuckScalingMonitor_threshold(t *testing.T) {
+	testCases := []struct {
+		name       string
+		target     string
+		expected   time.Duration
+		multiplier float64
+		want       time.Duration
+	}{
+		{
+			name:       "no recent calls",
+			target:     "nomad-target",
+			multiplier: 5,
+			want:       5 * time.Minute,
+		},
+		{
+			name:       "unknown target",
+			multiplier: 5,
+			want:       5 * time.Minute,
+		},
+		{
+			name:       "recent calls",
+			target:     "nomad-target",
+			expected:   30 * time.Second,
+			multiplier: 10,
+			want:       5 * time.Minute,
+		},
+		{
+			name:       "below minimum",
+			target:     "nomad-target",
+			expected:   100 * time.Millisecond,
+			multiplier: 10,
+			want:       stuckScalingMinThreshold,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := &StuckScalingMonitor{
+				multiplier:       tc.multiplier,
+				expectedDuration: func(string) time.Duration { return tc.expected },
+			}
+			assert.Equal(t, tc.want, s.threshold(tc.target))
+		})
+	}
+}
+
+func Test_scalingOpRegistry(t *testing.T) {
+	r := newScalingOpRegistry()
+	p := &sdk.ScalingPolicy{ID: "policy"}
+
+	_, ok := r.get("policy")
+	assert.False(t, ok)
+
+	r.start(p, sdk.ScalingAction{Count: 3}, 2)
+	op, ok := r.get("policy")
+	assert.True(t, ok)
+	assert.Equal(t, p, op.policy)
+	assert.Equal(t, int64(3), op.action.Count)
+	assert.Equal(t, int64(2), op.count)
+
+	r.done("policy")
+	_, ok = r.get("policy")
+	assert.False(t, ok)
+}
@@ -0,0 +1,250 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package policyeval
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/armon/go-metrics"
+)
+
+// pluginTimingWindow is the duration after which the plugin timing
+// histograms are rotated. Summaries cover between one and two windows of
+// calls, so they reflect recent latency rather than the whole agent lifetime.
+const pluginTimingWindow = 5 * time.Minute
+
+// pluginTimingBuckets are the upper bounds, in milliseconds, of the buckets of
+// the plugin call latency histograms.
+var pluginTimingBuckets = []float64{1, 2.5, 5, 10, 25, 50, 100, 250, 500, 1000, 2500, 5000, 10000, 30000, 60000}
+
+// pluginTimings holds the latency histograms of the plugin calls made by all
+// the workers of the agent.
+var pluginTimings = newPluginTimingRegistry(time.Now)
+
+// PluginTiming summarizes the latency of the recent calls made to a plugin
+// operation, such as the query calls of an APM plugin.
+type PluginTiming struct {
+	PluginType string
+	PluginName string
+	Operation  string
+
+	// Count is the number of calls in the summary.
+	Count uint64
+
+	// MeanMs, P50Ms, P95Ms, P99Ms and MaxMs are the latency statistics of
+	// the calls, in milliseconds. Percentiles are estimated from the
+	// histogram buckets.
+	MeanMs float64
+	P50Ms  float64
+	P95Ms  float64
+	P99Ms  float64
+	MaxMs  float64
+}
+
+// PluginTimings returns the latency summaries of the plugin operations called
+// recently, sorted by plugin type, plugin name and operation.
+func PluginTimings() []PluginTiming { return pluginTimings.summaries() }
+
+// measurePluginCall emits the latency of a plugin call as a sample, keeping
+// the existing invoke_ms metrics, and as a histogram labelled with the plugin
+// name. The latency is also recorded for the status API.
+func measurePluginCall(pluginType, operation, pluginName string, labels []metrics.Label, start time.Time) {
+	metrics.MeasureSinceWithLabels([]string{"plugin", pluginType, operation, "invoke_ms"}, start, labels)
+
+	ms := float64(time.Since(start)) / float64(time.Millisecond)
+	emitLatencyHistogram([]string{"plugin", pluginType, operation, "latency_bucket"}, pluginName, ms)
+	pluginTimings.observe(pluginTimingKey{pluginType: pluginType, pluginName: pluginName, operation: operation}, ms)
+}
+
+// emitLatencyHistogram increments the cumulative bucket counters of the
+// histogram following the Prometheus conventions, so the latency percentiles
+// can be calculated using histogram_quantile. The policy ID is not included
+// in the labels to keep the number of series bounded.
+func emitLatencyHistogram(key []string, pluginName string, ms float64) {
+	for _, le := range pluginTimingBuckets {
+		if ms <= le {
+			metrics.IncrCounterWithLabels(key, 1, []metrics.Label{
+				{Name: "plugin_name", Value: pluginName},
+				{Name: "le", Value: strconv.FormatFloat(le, 'f', -1, 64)},
+			})
+		}
+	}
+	metrics.IncrCounterWithLabels(key, 1, []metrics.Label{
+		{Name: "plugin_name", Value: pluginName},
+		{Name: "le", Value: "+Inf"},
+	})
+}
+
+// pluginTimingKey identifies a plugin operation.
+type pluginTimingKey struct {
+	pluginType string
+	pluginName string
+	operation  string
+}
+
+// pluginTimingRegistry holds the latency histograms of each plugin operation.
+// Two histograms are kept for each operation, one for the current window and
+// one for the previous, so summaries always include at least one full window
+// of calls.
+type pluginTimingRegistry struct {
+	lock      sync.Mutex
+	now       func() time.Time
+	rotatedAt time.Time
+	current   map[pluginTimingKey]*latencyHistogram
+	previous  map[pluginTimingKey]*latencyHistogram
+}
+
+func newPluginTimingRegistry(now func() time.Time) *pluginTimingRegistry {
+	return &pluginTimingRegistry{
+		now:       now,
+		rotatedAt: now(),
+		current:   make(map[pluginTimingKey]*latencyHistogram),
+		previous:  make(map[pluginTimingKey]*latencyHistogram),
+	}
+}
+
+// observe records the latency, in milliseconds, of a call to the operation.
+func (r *pluginTimingRegistry) observe(key pluginTimingKey, ms float64) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.rotate()
+
+	h, ok := r.current[key]
+	if !ok {
+		h = newLatencyHistogram()
+		r.current[key] = h
+	}
+	h.observe(ms)
+}
+
+// summaries returns the latency summary of each operation with calls in the
+// current or previous window.
+func (r *pluginTimingRegistry) summaries() []PluginTiming {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.rotate()
+
+	merged := make(map[pluginTimingKey]*latencyHistogram, len(r.current))
+	for _, m := range []map[pluginTimingKey]*latencyHistogram{r.previous, r.current} {
+		for key, h := range m {
+			if _, ok := merged[key]; !ok {
+				merged[key] = newLatencyHistogram()
+			}
+			merged[key].merge(h)
+		}
+	}
+
+	out := make([]PluginTiming, 0, len(merged))
+	for key, h := range merged {
+		out = append(out, PluginTiming{
+			PluginType: key.pluginType,
+			PluginName: key.pluginName,
+			Operation:  key.operation,
+			Count:      h.count,
+			MeanMs:     h.sum / float64(h.count),
+			P50Ms:      h.quantile(0.5),
+			P95Ms:      h.quantile(0.95),
+			P99Ms:      h.quantile(0.99),
+			MaxMs:      h.max,
+		})
+	}
+
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].PluginType != out[j].PluginType {
+			return out[i].PluginType < out[j].PluginType
+		}
+		if out[i].PluginName != out[j].PluginName {
+			return out[i].PluginName < out[j].PluginName
+		}
+		return out[i].Operation < out[j].Operation
+	})
+	return out
+}
+
+// rotate moves the current histograms to the previous window once the window
+// duration has passed. If no calls were recorded for two windows, both are
+// discarded. The lock must be held by the caller.
+func (r *pluginTimingRegistry) rotate() {
+	elapsed := r.now().Sub(r.rotatedAt)
+	if elapsed < pluginTimingWindow {
+		return
+	}
+
+	if elapsed >= 2*pluginTimingWindow {
+		r.previous = make(map[pluginTimingKey]*latencyHistogram)
+	} else {
+		r.previous = r.current
+	}
+	r.current = make(map[pluginTimingKey]*latencyHistogram)
+	r.rotatedAt = r.now()
+}
+
+// latencyHistogram counts latencies, in milliseconds, using the
+// pluginTimingBuckets. The last count holds the latencies above the largest
+// bucket.
+type latencyHistogram struct {
+	counts []uint64
+	count  uint64
+	sum    float64
+	max    float64
+}
+
+func newLatencyHistogram() *latencyHistogram {
+	return &latencyHistogram{counts: make([]uint64, len(pluginTimingBuckets)+1)}
+}
+
+func (h *latencyHistogram) observe(ms float64) {
+	idx := sort.SearchFloat64s(pluginTimingBuckets, ms)
+	h.counts[idx]++
+	h.count++
+	h.sum += ms
+	h.max = math.Max(h.max, ms)
+}
+
+func (h *latencyHistogram) merge(o *latencyHistogram) {
+	for i := range h.counts {
+		h.counts[i] += o.counts[i]
+	}
+	h.count += o.count
+	h.sum += o.sum
+	h.max = math.Max(h.max, o.max)
+}
+
+// quantile estimates the q quantile by interpolating linearly within the
+// bucket that contains it. The estimate never exceeds the largest latency
+// observed.
+func (h *latencyHistogram) quantile(q float64) float64 {
+	if h.count == 0 {
+		return 0
+	}
+
+	rank := q * float64(h.count)
+	var cumulative uint64
+
+	for i, c := range h.counts {
+		if c == 0 || float64(cumulative+c) < rank {
+			cumulative += c
+			continue
+		}
+
+		lower := 0.0
+		if i > 0 {
+			lower = pluginTimingBuckets[i-1]
+		}
+		upper := h.max
+		if i < len(pluginTimingBuckets) {
+			upper = math.Min(pluginTimingBuckets[i], h.max)
+		}
+
+		v := lower + (upper-lower)*(rank-float64(cumulative))/float64(c)
+		return math.Min(v, h.max)
+	}
+	return h.max
+}
@@ -0,0 +1,97 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package policyeval
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_latencyHistogram_quantile(t *testing.T) {
+	testCases := []struct {
+		name     string
+		input    []float64
+		q        float64
+		expected float64
+	}{
+		{
+			name:     "empty histogram",
+			q:        0.5,
+			expected: 0,
+		},
+		{
+			name:     "bucket upper bound is capped at max",
+			input:    []float64{3},
+			q:        0.99,
+			expected: 2.995,
+		},
+		{
+			name:     "interpolated within bucket",
+			input:    []float64{12, 14, 16, 18},
+			q:        0.5,
+			expected: 14,
+		},
+		{
+			name:     "values above the largest bucket",
+			input:    []float64{1, 90000},
+			q:        0.99,
+			expected: 89400,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			h := newLatencyHistogram()
+			for _, v := range tc.input {
+				h.observe(v)
+			}
+			assert.InDelta(t, tc.expected, h.quantile(tc.q), 0.001)
+		})
+	}
+}
+
+func Test_pluginTimingRegistry(t *testing.T) {
+	now := time.Now()
+	r := newPluginTimingRegistry(func() time.Time { return now })
+
+	apmKey := pluginTimingKey{pluginType: "apm", pluginName: "prometheus", operation: "query"}
+	targetKey := pluginTimingKey{pluginType: "target", pluginName: "nomad-target", operation: "status"}
+
+	r.observe(targetKey, 4)
+	r.observe(apmKey, 10)
+	r.observe(apmKey, 30)
+
+	out := r.summaries()
+	require.Len(t, out, 2)
+	assert.Equal(t, "apm", out[0].PluginType)
+	assert.Equal(t, uint64(2), out[0].Count)
+	assert.Equal(t, 20.0, out[0].MeanMs)
+	assert.Equal(t, 30.0, out[0].MaxMs)
+	assert.Equal(t, "target", out[1].PluginType)
+
+	// After one window the calls are still reported from the previous
+	// window and merged with the new ones.
+	now = now.Add(pluginTimingWindow)
+	r.observe(apmKey, 50)
+
+	out = r.summaries()
+	require.Len(t, out, 2)
+	assert.Equal(t, uint64(3), out[0].Count)
+	assert.Equal(t, 50.0, out[0].MaxMs)
+
+	// After another window only the latest calls are reported.
+	now = now.Add(pluginTimingWindow)
+
+	out = r.summaries()
+	require.Len(t, out, 1)
+	assert.Equal(t, uint64(1), out[0].Count)
+	assert.Equal(t, 50.0, out[0].MaxMs)
+
+	// With no calls for two windows, nothing is reported.
+	now = now.Add(2 * pluginTimingWindow)
+	assert.Empty(t, r.summaries())
+}
@@ -0,0 +1,92 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package policyeval
+
+import (
+	"context"
+	"fmt"
+	"maps"
+
+	multierror "github.com/hashicorp/go-multierror"
+	"github.com/hashicorp/nomad-autoscaler/sdk"
+)
+
+// verticalResources are the task resources a vertical policy can scale, in
+// the order they are evaluated.
+var verticalResources = []struct {
+	resource   string
+	policyType string
+}{
+	{resource: sdk.TargetResourceCPU, policyType: sdk.ScalingPolicyTypeVerticalCPU},
+	{resource: sdk.TargetResourceMemory, policyType: sdk.ScalingPolicyTypeVerticalMem},
+}
+
+// handleVerticalPolicy evaluates a vertical policy which scales multiple task
+// resources. Each resource is evaluated like a policy of its own, using the
+// checks and limits of the resource, so CPU and memory recommendations are
+// produced in the same evaluation cycle. The evaluation of a resource
+// doesn't stop the others if it fails.
+func (w *BaseWorker) handleVerticalPolicy(ctx context.Context, eval *sdk.ScalingEvaluation) error {
+	var mErr *multierror.Error
+
+	for _, resourceEval := range verticalResourceEvals(eval) {
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		resource := resourceEval.Policy.Target.Config[sdk.TargetConfigKeyResource]
+		if err := w.handlePolicy(ctx, resourceEval); err != nil {
+			mErr = multierror.Append(mErr, fmt.Errorf("failed to evaluate resource %s: %w", resource, err))
+		}
+	}
+
+	return mErr.ErrorOrNil()
+}
+
+// verticalResourceEvals splits the evaluation of a vertical policy into an
+// evaluation for each task resource targeted by its checks. The policy of each
+// evaluation is a copy of the vertical policy which only holds the checks of
+// the resource, uses the resource limits and targets the resource, the same
+// way policies of the vertical_cpu and vertical_mem types do.
+func verticalResourceEvals(eval *sdk.ScalingEvaluation) []*sdk.ScalingEvaluation {
+	var out []*sdk.ScalingEvaluation
+
+	for _, r := range verticalResources {
+		var checkEvals []*sdk.ScalingCheckEvaluation
+		for _, ce := range eval.CheckEvaluations {
+			if ce.Check.Resource == r.resource {
+				checkEvals = append(checkEvals, ce)
+			}
+		}
+		if len(checkEvals) == 0 {
+			continue
+		}
+
+		p := *eval.Policy
+		p.Type = r.policyType
+		p.Min, p.Max = eval.Policy.Limits(r.resource)
+
+		p.Checks = make([]*sdk.ScalingPolicyCheck, 0, len(checkEvals))
+		for _, ce := range checkEvals {
+			p.Checks = append(p.Checks, ce.Check)
+		}
+
+		if eval.Policy.Target != nil {
+			target := *eval.Policy.Target
+			target.Config = maps.Clone(eval.Policy.Target.Config)
+			if target.Config == nil {
+				target.Config = make(map[string]string)
+			}
+			target.Config[sdk.TargetConfigKeyResource] = r.resource
+			p.Target = &target
+		}
+
+		resourceEval := *eval
+		resourceEval.Policy = &p
+		resourceEval.CheckEvaluations = checkEvals
+		out = append(out, &resourceEval)
+	}
+
+	return out
+}
@@ -0,0 +1,78 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package policyeval
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hashicorp/nomad-autoscaler/sdk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_verticalResourceEvals(t *testing.T) {
+	cpuCheck := &sdk.ScalingPolicyCheck{Name: "cpu_p95", Resource: sdk.TargetResourceCPU}
+	memCheck := &sdk.ScalingPolicyCheck{Name: "mem_max", Resource: sdk.TargetResourceMemory}
+	memCheck2 := &sdk.ScalingPolicyCheck{Name: "mem_p99", Resource: sdk.TargetResourceMemory}
+
+	eval := &sdk.ScalingEvaluation{
+		ID: "eval",
+		Policy: &sdk.ScalingPolicy{
+			ID:        "policy",
+			Type:      sdk.ScalingPolicyTypeVertical,
+			Min:       100,
+			Max:       2000,
+			Resources: map[string]*sdk.ScalingPolicyResource{"memory": {Min: 128, Max: 4096}},
+			Checks:    []*sdk.ScalingPolicyCheck{memCheck, cpuCheck, memCheck2},
+			Target: &sdk.ScalingPolicyTarget{
+				Name:   "nomad-target",
+				Config: map[string]string{"Job": "example", "Group": "cache", "Task": "redis"},
+			},
+		},
+		CheckEvaluations: []*sdk.ScalingCheckEvaluation{
+			{Check: memCheck}, {Check: cpuCheck}, {Check: memCheck2},
+		},
+		CreateTime:   time.Now(),
+		HighPriority: true,
+	}
+
+	evals := verticalResourceEvals(eval)
+	require.Len(t, evals, 2)
+
+	cpu, mem := evals[0], evals[1]
+
+	assert.Equal(t, "eval", cpu.ID)
+	assert.Equal(t, "policy", cpu.Policy.ID)
+	assert.Equal(t, sdk.ScalingPolicyTypeVerticalCPU, cpu.Policy.Type)
+	assert.Equal(t, int64(100), cpu.Policy.Min)
+	assert.Equal(t, int64(2000), cpu.Policy.Max)
+	assert.Equal(t, []*sdk.ScalingPolicyCheck{cpuCheck}, cpu.Policy.Checks)
+	assert.Equal(t, []*sdk.ScalingCheckEvaluation{{Check: cpuCheck}}, cpu.CheckEvaluations)
+	assert.Equal(t, "cpu", cpu.Policy.Target.Config[sdk.TargetConfigKeyResource])
+	assert.Equal(t, eval.CreateTime, cpu.CreateTime)
+	assert.True(t, cpu.HighPriority)
+
+	assert.Equal(t, sdk.ScalingPolicyTypeVerticalMem, mem.Policy.Type)
+	assert.Equal(t, int64(128), mem.Policy.Min)
+	assert.Equal(t, int64(4096), mem.Policy.Max)
+	assert.Equal(t, []*sdk.ScalingPolicyCheck{memCheck, memCheck2}, mem.Policy.Checks)
+	assert.Equal(t, "memory", mem.Policy.Target.Config[sdk.TargetConfigKeyResource])
+
+	// The vertical policy is not modified.
+	assert.Equal(t, sdk.ScalingPolicyTypeVertical, eval.Policy.Type)
+	assert.NotContains(t, eval.Policy.Target.Config, sdk.TargetConfigKeyResource)
+	assert.Len(t, eval.Policy.Checks, 3)
+
+	// The desired counts of the resources are tracked separately.
+	assert.Equal(t, "policy/cpu", desiredCountKey(cpu.Policy))
+	assert.Equal(t, "policy/memory", desiredCountKey(mem.Policy))
+	assert.Equal(t, "policy", desiredCountKey(eval.Policy))
+
+	// Resources without checks are not evaluated.
+	eval.CheckEvaluations = eval.CheckEvaluations[:1]
+	evals = verticalResourceEvals(eval)
+	require.Len(t, evals, 1)
+	assert.Equal(t, sdk.ScalingPolicyTypeVerticalMem, evals[0].Policy.Type)
+}
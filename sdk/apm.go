@@ -25,6 +25,14 @@ func (t TimestampedMetrics) Less(i, j int) bool { return t[i].Timestamp.Before(t
 // Swap satisfies the Swap function of the sort.Interface interface.
 func (t TimestampedMetrics) Swap(i, j int) { t[i], t[j] = t[j], t[i] }
 
+// LabeledTimestampedMetrics is a series of timestamped metric values along
+// with the labels that identify the series. This allows consumers of queries
+// which return multiple series to tell them apart.
+type LabeledTimestampedMetrics struct {
+	Labels  map[string]string
+	Metrics TimestampedMetrics
+}
+
 // TimeRange defines a range of time.
 type TimeRange struct {
 	From time.Time
@@ -16,6 +16,28 @@ type ScalingEvaluation struct {
 	Policy           *ScalingPolicy
 	CheckEvaluations []*ScalingCheckEvaluation
 	CreateTime       time.Time
+
+	// HighPriority places the evaluation in the priority lane of its queue,
+	// so it's picked before evaluations that are not high priority.
+	HighPriority bool
+}
+
+// ScalingCheckHistoryEntry is the result of a previous evaluation of a policy
+// check. Strategies can use these entries to make decisions based on trends
+// without having to store state across policies within the plugin.
+type ScalingCheckHistoryEntry struct {
+
+	// Timestamp is the time at which the check was evaluated.
+	Timestamp time.Time
+
+	// Count is the target count at the time of the evaluation.
+	Count int64
+
+	// Metric is the most recent metric value used by the evaluation.
+	Metric float64
+
+	// Action is the action calculated by the strategy.
+	Action ScalingAction
 }
 
 // NewScalingEvaluation creates a new ScalingEvaluation based off the passed
@@ -20,4 +20,9 @@ type ScalingCheckEvaluation struct {
 
 	// Action is the calculated desired state and is populated by strategy.Run.
 	Action *ScalingAction
+
+	// History contains the results of the previous evaluations of the check,
+	// ordered from oldest to newest. It is only populated when the check has
+	// a HistorySize configured.
+	History []ScalingCheckHistoryEntry
 }
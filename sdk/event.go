@@ -0,0 +1,73 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package sdk
+
+import "time"
+
+// ScalingEvent describes the outcome of a policy evaluation. It is sent to
+// all the configured event sink plugins so operators can build automation and
+// analytics on top of the decisions taken by the Autoscaler.
+type ScalingEvent struct {
+
+	// ID is a unique identifier of the event.
+	ID string
+
+	// Type identifies the kind of outcome described by the event, so
+	// consumers can react to specific outcomes without parsing the error.
+	Type ScalingEventType
+
+	// CorrelationID is the ID of the evaluation that generated the event. It
+	// is shared with the logs, plugin calls and Nomad scale requests related
+	// to the same scaling decision.
+	CorrelationID string
+
+	// Timestamp is the time at which the event was generated.
+	Timestamp time.Time
+
+	// PolicyID is the ID of the scaling policy that was evaluated.
+	PolicyID string
+
+	// Target is the name of the target plugin of the policy.
+	Target string
+
+	// PolicyMeta is the user metadata of the scaling policy.
+	PolicyMeta map[string]string
+
+	// Check is the name of the policy check that drove the decision. It is
+	// empty when no check requested a scaling action.
+	Check string
+
+	// Count is the count of the target at the time of the evaluation.
+	Count int64
+
+	// TargetMeta holds the standard TargetStatusMetaKeys reported by the
+	// target at the time of the evaluation, such as operations in progress or
+	// unhealthy instances.
+	TargetMeta map[string]string
+
+	// Action is the scaling action selected by the evaluation. Its direction
+	// is ScaleDirectionNone when the evaluation decided no scaling was
+	// needed.
+	Action ScalingAction
+
+	// Error is the error message of a failed scaling action.
+	Error string
+}
+
+// ScalingEventType identifies the kind of outcome described by a
+// ScalingEvent.
+type ScalingEventType string
+
+const (
+	// ScalingEventTypeScaling is the type of the events describing the
+	// scaling action selected by an evaluation, whether it succeeded,
+	// failed or was skipped.
+	ScalingEventTypeScaling ScalingEventType = "scaling"
+
+	// ScalingEventTypeQuotaReached is the type of the events describing a
+	// scaling action that couldn't be performed because the target reached
+	// the capacity limits of its provider. The event Error holds the
+	// details of the limit.
+	ScalingEventTypeQuotaReached ScalingEventType = "quota_reached"
+)
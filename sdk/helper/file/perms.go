@@ -0,0 +1,58 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package file
+
+import (
+	"fmt"
+	"os"
+)
+
+// PermissionIssue describes a file or directory with unsafe ownership or
+// permissions.
+type PermissionIssue struct {
+	Path   string
+	Reason string
+
+	// WorldWritable is true if the path can be modified by any user.
+	WorldWritable bool
+}
+
+func (p PermissionIssue) String() string {
+	return fmt.Sprintf("%s %s", p.Path, p.Reason)
+}
+
+// CheckPermissions returns the permission issues found on path, which must be
+// writable only by its owner, and owned by root or the current user. Checks
+// are skipped on systems without Unix style permissions.
+func CheckPermissions(path string) ([]PermissionIssue, error) {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	return checkPermissions(path, fi), nil
+}
+
+// CheckDirPermissions returns the permission issues found on dir and on all
+// the files within it that have one of the passed suffixes.
+func CheckDirPermissions(dir string, suffixes ...string) ([]PermissionIssue, error) {
+	issues, err := CheckPermissions(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := GetFileListFromDir(dir, suffixes...)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, f := range files {
+		fileIssues, err := CheckPermissions(f)
+		if err != nil {
+			return nil, err
+		}
+		issues = append(issues, fileIssues...)
+	}
+
+	return issues, nil
+}
@@ -0,0 +1,43 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+//go:build !windows
+// +build !windows
+
+package file
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+)
+
+func checkPermissions(path string, fi os.FileInfo) []PermissionIssue {
+	var issues []PermissionIssue
+
+	mode := fi.Mode().Perm()
+	switch {
+	case mode&0002 != 0:
+		issues = append(issues, PermissionIssue{
+			Path:          path,
+			Reason:        fmt.Sprintf("is world-writable (mode %04o)", mode),
+			WorldWritable: true,
+		})
+	case mode&0020 != 0:
+		issues = append(issues, PermissionIssue{
+			Path:   path,
+			Reason: fmt.Sprintf("is group-writable (mode %04o)", mode),
+		})
+	}
+
+	if st, ok := fi.Sys().(*syscall.Stat_t); ok {
+		if uid := int(st.Uid); uid != 0 && uid != os.Getuid() {
+			issues = append(issues, PermissionIssue{
+				Path:   path,
+				Reason: fmt.Sprintf("is owned by uid %d, which is neither root nor the agent user", uid),
+			})
+		}
+	}
+
+	return issues
+}
@@ -0,0 +1,86 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+//go:build !windows
+// +build !windows
+
+package file
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckPermissions(t *testing.T) {
+	testCases := []struct {
+		name                  string
+		mode                  os.FileMode
+		expectedReasons       []string
+		expectedWorldWritable bool
+	}{
+		{
+			name: "owner writable",
+			mode: 0755,
+		},
+		{
+			name:            "group writable",
+			mode:            0775,
+			expectedReasons: []string{"is group-writable (mode 0775)"},
+		},
+		{
+			name:                  "world writable",
+			mode:                  0777,
+			expectedReasons:       []string{"is world-writable (mode 0777)"},
+			expectedWorldWritable: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "plugin")
+			require.NoError(t, os.WriteFile(path, nil, 0600))
+			require.NoError(t, os.Chmod(path, tc.mode))
+
+			issues, err := CheckPermissions(path)
+			require.NoError(t, err)
+
+			var reasons []string
+			for _, issue := range issues {
+				assert.Equal(t, path, issue.Path)
+				assert.Equal(t, tc.expectedWorldWritable, issue.WorldWritable)
+				reasons = append(reasons, issue.Reason)
+			}
+			assert.Equal(t, tc.expectedReasons, reasons)
+		})
+	}
+
+	_, err := CheckPermissions("/honeybadger")
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestCheckDirPermissions(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.Chmod(dir, 0755))
+
+	safe := filepath.Join(dir, "safe.hcl")
+	require.NoError(t, os.WriteFile(safe, nil, 0644))
+
+	unsafe := filepath.Join(dir, "unsafe.hcl")
+	require.NoError(t, os.WriteFile(unsafe, nil, 0600))
+	require.NoError(t, os.Chmod(unsafe, 0666))
+
+	// Files without the suffix are ignored.
+	ignored := filepath.Join(dir, "README")
+	require.NoError(t, os.WriteFile(ignored, nil, 0600))
+	require.NoError(t, os.Chmod(ignored, 0666))
+
+	issues, err := CheckDirPermissions(dir, ".hcl")
+	require.NoError(t, err)
+	require.Len(t, issues, 1)
+	assert.Equal(t, unsafe, issues[0].Path)
+	assert.True(t, issues[0].WorldWritable)
+}
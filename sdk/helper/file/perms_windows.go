@@ -0,0 +1,15 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+//go:build windows
+// +build windows
+
+package file
+
+import "os"
+
+// Windows does not use Unix style permissions, so os.FileMode does not
+// reflect who is able to modify a file and no checks are performed.
+func checkPermissions(_ string, _ os.FileInfo) []PermissionIssue {
+	return nil
+}
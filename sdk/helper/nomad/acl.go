@@ -0,0 +1,160 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package nomad
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	errHelper "github.com/hashicorp/nomad-autoscaler/sdk/helper/error"
+	"github.com/hashicorp/nomad-autoscaler/sdk/helper/uuid"
+	"github.com/hashicorp/nomad/api"
+)
+
+// Capability is a Nomad ACL capability used by the autoscaler.
+type Capability string
+
+const (
+	// CapabilityReadJob allows reading the jobs scaled by the Nomad target.
+	CapabilityReadJob Capability = "read-job"
+
+	// CapabilityScaleJob allows scaling the task groups of jobs.
+	CapabilityScaleJob Capability = "scale-job"
+
+	// CapabilityListScalingPolicies allows listing the scaling policies read
+	// by the Nomad policy source.
+	CapabilityListScalingPolicies Capability = "list-scaling-policies"
+
+	// CapabilityNodeWrite allows draining and marking the nodes removed by
+	// cluster scaling policies as ineligible.
+	CapabilityNodeWrite Capability = "node:write"
+)
+
+// MissingCapability is a capability missing from the ACL token.
+type MissingCapability struct {
+	Capability Capability
+
+	// Namespace is the namespace the capability is missing in. It's empty
+	// for capabilities that are not namespaced, such as node:write.
+	Namespace string
+}
+
+// String returns the capability along with the namespace it's missing in.
+func (m MissingCapability) String() string {
+	if m.Namespace == "" {
+		return string(m.Capability)
+	}
+	return fmt.Sprintf("%s (namespace %s)", m.Capability, m.Namespace)
+}
+
+// MissingCapabilities returns the capabilities the ACL token of the client
+// is missing in the namespace. If namespace is the wildcard, the
+// capabilities are checked in each namespace listed by Nomad. Nomad only
+// lists the namespaces the token has a capability in, so namespaces the token
+// can't access at all are not reported.
+//
+// Nomad has no API to list the capabilities of a token, so each capability
+// is probed with a request against a job or node that doesn't exist. Nomad
+// checks the ACL token before looking up the object, so a permission denied
+// error means the capability is missing while any other API error means it's
+// granted. The probes don't modify the cluster. All the capabilities are
+// granted when ACLs are disabled.
+func MissingCapabilities(client *api.Client, namespace string, caps []Capability) ([]MissingCapability, error) {
+	namespaces, err := probeNamespaces(client, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	var missing []MissingCapability
+	for _, c := range caps {
+		// Node capabilities are not namespaced, so they are only checked
+		// once.
+		capNamespaces := namespaces
+		if !isNamespaced(c) {
+			capNamespaces = []string{""}
+		}
+
+		for _, ns := range capNamespaces {
+			err := probeCapability(client, ns, c)
+			switch {
+			case err == nil:
+			case errHelper.APIErrIs(err, http.StatusForbidden, "Permission denied"):
+				missing = append(missing, MissingCapability{Capability: c, Namespace: ns})
+			case isAPIError(err):
+				// The object doesn't exist, which is expected.
+			default:
+				return nil, fmt.Errorf("failed to check %s capability: %v", c, err)
+			}
+		}
+	}
+
+	return missing, nil
+}
+
+// probeNamespaces returns the namespaces where the capabilities must be
+// checked.
+func probeNamespaces(client *api.Client, namespace string) ([]string, error) {
+	switch namespace {
+	case "":
+		return []string{api.DefaultNamespace}, nil
+	case api.AllNamespacesNamespace:
+	default:
+		return []string{namespace}, nil
+	}
+
+	list, _, err := client.Namespaces().List(nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list namespaces: %v", err)
+	}
+	if len(list) == 0 {
+		return []string{api.DefaultNamespace}, nil
+	}
+
+	namespaces := make([]string, len(list))
+	for i, ns := range list {
+		namespaces[i] = ns.Name
+	}
+	return namespaces, nil
+}
+
+// isNamespaced returns true if the capability is granted per namespace.
+func isNamespaced(c Capability) bool {
+	return c != CapabilityNodeWrite
+}
+
+// probeCapability performs a request that requires the capability.
+func probeCapability(client *api.Client, namespace string, c Capability) error {
+	if namespace == "" {
+		namespace = api.DefaultNamespace
+	}
+	q := &api.QueryOptions{Namespace: namespace}
+	w := &api.WriteOptions{Namespace: namespace}
+
+	// The random ID ensures the request never matches an existing object.
+	id := uuid.Generate()
+
+	var err error
+	switch c {
+	case CapabilityReadJob:
+		_, _, err = client.Jobs().Info(id, q)
+	case CapabilityScaleJob:
+		count := 0
+		_, _, err = client.Jobs().Scale(id, id, &count, "nomad-autoscaler ACL preflight check", false, nil, w)
+	case CapabilityListScalingPolicies:
+		_, _, err = client.Scaling().ListPolicies(q)
+	case CapabilityNodeWrite:
+		_, err = client.Nodes().UpdateDrain(id, nil, false, w)
+	default:
+		err = fmt.Errorf("unsupported capability %q", c)
+	}
+	return err
+}
+
+// isAPIError returns true if err is a response returned by the Nomad API, as
+// opposed to a failure to reach it.
+func isAPIError(err error) bool {
+	var sc errHelper.StatusCoder
+	return errors.As(err, &sc)
+}
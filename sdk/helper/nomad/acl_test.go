@@ -0,0 +1,140 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package nomad
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/nomad/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMissingCapabilities(t *testing.T) {
+	allCaps := []Capability{
+		CapabilityReadJob,
+		CapabilityScaleJob,
+		CapabilityListScalingPolicies,
+		CapabilityNodeWrite,
+	}
+
+	testCases := []struct {
+		name               string
+		denied             func(r *http.Request) bool
+		expectedMissing    []MissingCapability
+		expectedNamespaces []string
+		namespace          string
+	}{
+		{
+			name:               "all capabilities granted",
+			denied:             func(*http.Request) bool { return false },
+			namespace:          "*",
+			expectedNamespaces: []string{"default", "platform"},
+		},
+		{
+			name:               "all capabilities missing",
+			denied:             func(*http.Request) bool { return true },
+			namespace:          "platform",
+			expectedNamespaces: []string{"platform"},
+			expectedMissing: []MissingCapability{
+				{Capability: CapabilityReadJob, Namespace: "platform"},
+				{Capability: CapabilityScaleJob, Namespace: "platform"},
+				{Capability: CapabilityListScalingPolicies, Namespace: "platform"},
+				{Capability: CapabilityNodeWrite},
+			},
+		},
+		{
+			name: "write capabilities missing",
+			denied: func(r *http.Request) bool {
+				return r.Method != http.MethodGet
+			},
+			expectedNamespaces: []string{"default"},
+			expectedMissing: []MissingCapability{
+				{Capability: CapabilityScaleJob, Namespace: "default"},
+				{Capability: CapabilityNodeWrite},
+			},
+		},
+		{
+			name: "node write missing",
+			denied: func(r *http.Request) bool {
+				return strings.HasPrefix(r.URL.Path, "/v1/node/")
+			},
+			expectedNamespaces: []string{"default"},
+			expectedMissing:    []MissingCapability{{Capability: CapabilityNodeWrite}},
+		},
+		{
+			name: "capabilities missing in some namespaces",
+			denied: func(r *http.Request) bool {
+				return r.Method != http.MethodGet && r.URL.Query().Get("namespace") == "platform"
+			},
+			namespace:          "*",
+			expectedNamespaces: []string{"default", "platform"},
+			expectedMissing: []MissingCapability{
+				{Capability: CapabilityScaleJob, Namespace: "platform"},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				// Query responses must include the index headers.
+				w.Header().Set("X-Nomad-Index", "1")
+				w.Header().Set("X-Nomad-LastContact", "0")
+
+				if r.URL.Path == "/v1/namespaces" {
+					_, _ = w.Write([]byte(`[{"Name": "default"}, {"Name": "platform"}]`))
+					return
+				}
+
+				// Nodes are not namespaced.
+				ns := r.URL.Query().Get("namespace")
+				if !strings.HasPrefix(r.URL.Path, "/v1/node/") && !slices.Contains(tc.expectedNamespaces, ns) {
+					t.Errorf("unexpected namespace %q in %s %s", ns, r.Method, r.URL.Path)
+				}
+
+				switch {
+				case tc.denied(r):
+					w.WriteHeader(http.StatusForbidden)
+					_, _ = w.Write([]byte("Permission denied"))
+				case r.URL.Path == "/v1/scaling/policies":
+					_, _ = w.Write([]byte(`[]`))
+				default:
+					// The probed jobs and nodes don't exist.
+					w.WriteHeader(http.StatusNotFound)
+				}
+			}))
+			defer srv.Close()
+
+			client, err := api.NewClient(&api.Config{Address: srv.URL})
+			require.NoError(t, err)
+
+			missing, err := MissingCapabilities(client, tc.namespace, allCaps)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedMissing, missing)
+		})
+	}
+}
+
+func TestMissingCapability_String(t *testing.T) {
+	assert.Equal(t, "node:write", MissingCapability{Capability: CapabilityNodeWrite}.String())
+	assert.Equal(t, "scale-job (namespace platform)",
+		MissingCapability{Capability: CapabilityScaleJob, Namespace: "platform"}.String())
+}
+
+func TestMissingCapabilities_unreachable(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	srv.Close()
+
+	client, err := api.NewClient(&api.Config{Address: srv.URL})
+	require.NoError(t, err)
+
+	missing, err := MissingCapabilities(client, "", []Capability{CapabilityReadJob})
+	assert.Error(t, err)
+	assert.Nil(t, missing)
+}
@@ -0,0 +1,14 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package pluginconfig
+
+// GetValue handles parameters that are optional in the operator's plugin
+// config but required by the plugin, returning the default value when the key
+// is not set or is empty.
+func GetValue(config map[string]string, key, defaultValue string) string {
+	if value, ok := config[key]; ok && value != "" {
+		return value
+	}
+	return defaultValue
+}
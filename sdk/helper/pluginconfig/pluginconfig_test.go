@@ -0,0 +1,45 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package pluginconfig
+
+import (
+	"testing"
+
+	"github.com/shoenig/test/must"
+)
+
+func Test_GetValue(t *testing.T) {
+	testCases := []struct {
+		name     string
+		config   map[string]string
+		expected string
+	}{
+		{
+			name:     "nil config",
+			config:   nil,
+			expected: "default",
+		},
+		{
+			name:     "key not set",
+			config:   map[string]string{"other": "value"},
+			expected: "default",
+		},
+		{
+			name:     "empty value",
+			config:   map[string]string{"key": ""},
+			expected: "default",
+		},
+		{
+			name:     "value set",
+			config:   map[string]string{"key": "value"},
+			expected: "value",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			must.Eq(t, tc.expected, GetValue(tc.config, "key", "default"))
+		})
+	}
+}
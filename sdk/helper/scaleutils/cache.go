@@ -0,0 +1,159 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package scaleutils
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	hclog "github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/nomad-autoscaler/sdk/helper/blocking"
+	"github.com/hashicorp/nomad/api"
+)
+
+// nodeCacheRetryInterval is the time to wait before retrying a failed
+// blocking query.
+const nodeCacheRetryInterval = 10 * time.Second
+
+// nodeCache holds the Nomad nodes, which are kept up to date using a blocking
+// query. This allows cluster scaling evaluations for any number of policies
+// to read them without listing the nodes from the Nomad API every time.
+//
+// Allocations are not cached. Listing the allocations of the whole cluster
+// requires read-job in every namespace, and Nomad silently omits the
+// namespaces the token can't read, so they are read per node, which only
+// requires node:read.
+//
+// The cache is populated on first use. If the initial read fails, the error is
+// returned and the read is attempted again on the next use.
+type nodeCache struct {
+	client *api.Client
+	log    hclog.Logger
+
+	// initLock serializes the initial read of the cache.
+	initLock sync.Mutex
+	synced   bool
+
+	lock  sync.RWMutex
+	nodes []*api.NodeListStub
+
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// newNodeCache returns a nodeCache which reads from the Nomad API using the
+// passed client.
+func newNodeCache(client *api.Client, log hclog.Logger) *nodeCache {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &nodeCache{
+		client: client,
+		log:    log.Named("node_cache"),
+		ctx:    ctx,
+		cancel: cancel,
+	}
+}
+
+// Nodes returns the list of Nomad nodes, including their resources. The
+// nodes are shared between callers and must not be modified.
+func (n *nodeCache) Nodes() ([]*api.NodeListStub, error) {
+	if err := n.sync(); err != nil {
+		return nil, err
+	}
+
+	n.lock.RLock()
+	defer n.lock.RUnlock()
+
+	out := make([]*api.NodeListStub, len(n.nodes))
+	copy(out, n.nodes)
+	return out, nil
+}
+
+// stop ends the blocking query which keeps the cache up to date.
+func (n *nodeCache) stop() { n.cancel() }
+
+// sync performs the initial read of the nodes if it has not been done yet,
+// and starts the blocking query which keeps them up to date.
+func (n *nodeCache) sync() error {
+	n.initLock.Lock()
+	defer n.initLock.Unlock()
+
+	if n.synced {
+		return nil
+	}
+
+	nodesIndex, err := n.readNodes(&api.QueryOptions{})
+	if err != nil {
+		return err
+	}
+
+	go n.watch("nodes", nodesIndex, n.readNodes)
+
+	n.synced = true
+	return nil
+}
+
+// watch runs blocking queries using the read function until the cache is
+// stopped, starting from the passed index.
+func (n *nodeCache) watch(name string, index uint64, read func(*api.QueryOptions) (uint64, error)) {
+	n.log.Debug("starting blocking query watcher", "resource", name)
+
+	for {
+		var (
+			newIndex uint64
+			err      error
+		)
+
+		// The call is done in a goroutine so we can still listen for the
+		// cache being stopped.
+		doneCh := make(chan struct{})
+		go func() {
+			newIndex, err = read(&api.QueryOptions{WaitIndex: index})
+			close(doneCh)
+		}()
+
+		select {
+		case <-n.ctx.Done():
+			n.log.Trace("stopping blocking query watcher", "resource", name)
+			return
+		case <-doneCh:
+		}
+
+		// If we get an error at this point, we should sleep and try again.
+		// The cache continues to serve the last values read.
+		if err != nil {
+			n.log.Warn("failed to refresh cache", "resource", name, "error", err)
+			select {
+			case <-n.ctx.Done():
+				n.log.Trace("stopping blocking query watcher", "resource", name)
+				return
+			case <-time.After(nodeCacheRetryInterval):
+				continue
+			}
+		}
+
+		if blocking.IndexHasChanged(newIndex, index) {
+			n.log.Trace("refreshed cache", "resource", name, "index", newIndex)
+		}
+		index = newIndex
+	}
+}
+
+// readNodes lists the Nomad nodes and stores them in the cache, returning the
+// index of the response.
+func (n *nodeCache) readNodes(q *api.QueryOptions) (uint64, error) {
+	q.Params = map[string]string{"resources": "true"}
+
+	nodes, meta, err := n.client.Nodes().List(q.WithContext(n.ctx))
+	if err != nil {
+		return 0, fmt.Errorf("failed to list Nomad nodes from API: %v", err)
+	}
+
+	n.lock.Lock()
+	n.nodes = nodes
+	n.lock.Unlock()
+
+	return meta.LastIndex, nil
+}
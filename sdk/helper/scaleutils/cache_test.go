@@ -0,0 +1,181 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package scaleutils
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/nomad/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeNomadState is a minimal Nomad API which supports blocking queries on
+// the node list endpoint and reading the allocations of a node.
+type fakeNomadState struct {
+	lock    sync.Mutex
+	index   uint64
+	changed chan struct{}
+
+	nodes  []*api.NodeListStub
+	allocs map[string][]*api.Allocation
+
+	requests map[string]int
+}
+
+func newFakeNomadState() *fakeNomadState {
+	return &fakeNomadState{
+		index:    1,
+		changed:  make(chan struct{}),
+		allocs:   make(map[string][]*api.Allocation),
+		requests: make(map[string]int),
+	}
+}
+
+func (f *fakeNomadState) update(fn func()) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	fn()
+	f.index++
+	close(f.changed)
+	f.changed = make(chan struct{})
+}
+
+func (f *fakeNomadState) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.lock.Lock()
+	f.requests[r.URL.Path]++
+	index, changed := f.index, f.changed
+	f.lock.Unlock()
+
+	// Block until the state changes if the caller already has the current
+	// index, or until a short timeout to emulate the blocking query wait.
+	if waitIndex, _ := strconv.ParseUint(r.URL.Query().Get("index"), 10, 64); waitIndex >= index {
+		select {
+		case <-changed:
+		case <-time.After(50 * time.Millisecond):
+		case <-r.Context().Done():
+			return
+		}
+	}
+
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	w.Header().Set("X-Nomad-Index", strconv.FormatUint(f.index, 10))
+	switch {
+	case r.URL.Path == "/v1/nodes":
+		_ = json.NewEncoder(w).Encode(f.nodes)
+	case strings.HasPrefix(r.URL.Path, "/v1/node/") && strings.HasSuffix(r.URL.Path, "/allocations"):
+		nodeID := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/v1/node/"), "/allocations")
+		_ = json.NewEncoder(w).Encode(f.allocs[nodeID])
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func Test_nodeCache(t *testing.T) {
+	state := newFakeNomadState()
+	state.nodes = []*api.NodeListStub{{ID: "node1"}}
+
+	ts := httptest.NewServer(state)
+	defer ts.Close()
+
+	cfg := api.DefaultConfig()
+	cfg.Address = ts.URL
+	client, err := api.NewClient(cfg)
+	require.NoError(t, err)
+
+	cache := newNodeCache(client, hclog.NewNullLogger())
+	defer cache.stop()
+
+	// The initial read populates the cache.
+	nodes, err := cache.Nodes()
+	require.NoError(t, err)
+	require.Len(t, nodes, 1)
+	assert.Equal(t, "node1", nodes[0].ID)
+
+	// Changes are picked up by the blocking query.
+	state.update(func() {
+		state.nodes = append(state.nodes, &api.NodeListStub{ID: "node2"})
+	})
+
+	require.Eventually(t, func() bool {
+		nodes, _ := cache.Nodes()
+		return len(nodes) == 2
+	}, 2*time.Second, 10*time.Millisecond)
+
+	// Reads are served from the cache without calling the API again.
+	state.lock.Lock()
+	nodeRequests := state.requests["/v1/nodes"]
+	state.lock.Unlock()
+
+	for i := 0; i < 10; i++ {
+		_, err := cache.Nodes()
+		require.NoError(t, err)
+	}
+
+	state.lock.Lock()
+	assert.LessOrEqual(t, state.requests["/v1/nodes"]-nodeRequests, 1)
+	state.lock.Unlock()
+}
+
+func TestClusterScaleUtils_nodeAllocations(t *testing.T) {
+	state := newFakeNomadState()
+	state.nodes = []*api.NodeListStub{{ID: "node1"}}
+	state.allocs["node1"] = []*api.Allocation{
+		{ID: "alloc1", NodeID: "node1", Namespace: "default"},
+		{ID: "alloc2", NodeID: "node1", Namespace: "platform"},
+	}
+
+	ts := httptest.NewServer(state)
+	defer ts.Close()
+
+	cfg := api.DefaultConfig()
+	cfg.Address = ts.URL
+	client, err := api.NewClient(cfg)
+	require.NoError(t, err)
+
+	c := &ClusterScaleUtils{client: client, cache: newNodeCache(client, hclog.NewNullLogger())}
+	defer c.cache.stop()
+
+	// The allocations are read per node, so they include the allocations of
+	// all the namespaces with only node:read.
+	allocs, err := c.nodeAllocations("node1")
+	require.NoError(t, err)
+	require.Len(t, allocs, 2)
+	assert.Equal(t, "platform", allocs[1].Namespace)
+
+	state.lock.Lock()
+	defer state.lock.Unlock()
+	assert.Equal(t, 1, state.requests["/v1/node/node1/allocations"])
+	assert.Zero(t, state.requests["/v1/allocations"])
+}
+
+func Test_nodeCache_initialReadError(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer ts.Close()
+
+	cfg := api.DefaultConfig()
+	cfg.Address = ts.URL
+	client, err := api.NewClient(cfg)
+	require.NoError(t, err)
+
+	cache := newNodeCache(client, hclog.NewNullLogger())
+	defer cache.stop()
+
+	_, err = cache.Nodes()
+	assert.ErrorContains(t, err, "failed to list Nomad nodes from API")
+	assert.False(t, cache.synced)
+}
@@ -45,6 +45,10 @@ type ClusterScaleUtils struct {
 	ClusterNodeIDLookupFunc ClusterNodeIDLookupFunc
 
 	drainer nodeDrainer
+
+	// cache holds the Nomad nodes, so they don't have to be listed from the
+	// API for every scaling evaluation. If nil, they are read from the API.
+	cache *nodeCache
 }
 
 // NewClusterScaleUtils instantiates a new ClusterScaleUtils object for use.
@@ -67,6 +71,7 @@ func NewClusterScaleUtils(cfg *api.Config, log hclog.Logger) (*ClusterScaleUtils
 		client:    client,
 		curNodeID: id,
 		drainer:   client.Nodes(),
+		cache:     newNodeCache(client, log),
 	}, nil
 }
 
@@ -195,11 +200,10 @@ func (c *ClusterScaleUtils) IdentifyScaleInNodes(cfg map[string]string, num int)
 	}
 	c.log.Debug("performing node pool filtering", poolID.Key(), poolID.Value())
 
-	// Pull a current list of Nomad nodes from the API including populated
-	// resource fields.
-	nodes, _, err := c.client.Nodes().List(&api.QueryOptions{Params: map[string]string{"resources": "true"}})
+	// Pull a current list of Nomad nodes including populated resource fields.
+	nodes, err := c.listNodes()
 	if err != nil {
-		return nil, fmt.Errorf("failed to list Nomad nodes from API: %v", err)
+		return nil, err
 	}
 
 	if c.log.IsDebug() {
@@ -287,15 +291,32 @@ func (c *ClusterScaleUtils) IdentifyScaleInRemoteIDs(nodes []*api.NodeListStub)
 
 func (c *ClusterScaleUtils) SelectScaleInNodes(nodes []*api.NodeListStub, cfg map[string]string, num int) ([]*api.NodeListStub, error) {
 	// Setup the node selector used to identify suitable nodes for termination.
-	selector, err := nodeselector.NewSelector(cfg, c.client, c.log)
+	selector, err := nodeselector.NewSelectorWithAllocationsFunc(cfg, c.client, c.log, c.nodeAllocations)
 	if err != nil {
 		return nil, err
 	}
 	c.log.Debug("performing node selection", "selector_strategy", selector.Name())
 
+	checkPlacement, err := placementCheckEnabled(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	// When the placement check is enabled, ask the selector to order all the
+	// nodes so that nodes whose allocations wouldn't fit elsewhere can be
+	// replaced by the next best candidate.
+	var out []*api.NodeListStub
+	if checkPlacement {
+		out, err = c.selectPlaceableNodes(nodes, selector.Select(nodes, len(nodes)), num)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		out = selector.Select(nodes, num)
+	}
+
 	// It is possible the selector is unable to identify suitable nodes and so
 	// we should return an error to stop additional execution.
-	out := selector.Select(nodes, num)
 	if len(out) < 1 {
 		return nil, fmt.Errorf("no nodes selected using strategy %s", selector.Name())
 	}
@@ -358,6 +379,59 @@ func (c *ClusterScaleUtils) RunPostScaleInTasks(_ context.Context, cfg map[strin
 	return errHelper.FormattedMultiError(mErr)
 }
 
+// RunPostScaleOutTasks triggers any tasks which should occur after instances
+// that were previously scaled in are brought back by the remote provider,
+// such as suspended instances that are resumed. The current tasks are:
+//
+//   - modify node eligibility to true for the nodes of the instances
+func (c *ClusterScaleUtils) RunPostScaleOutTasks(_ context.Context, cfg map[string]string, remoteIDs []string) error {
+
+	// Check that the ClusterNodeIDLookupFunc has been set, otherwise we cannot
+	// attempt to identify the nodes of the remote resource IDs.
+	if c.ClusterNodeIDLookupFunc == nil {
+		return errors.New("required ClusterNodeIDLookupFunc not set")
+	}
+
+	poolID, err := nodepool.NewClusterNodePoolIdentifier(cfg)
+	if err != nil {
+		return err
+	}
+
+	nodes, err := c.listNodes()
+	if err != nil {
+		return err
+	}
+
+	wanted := make(map[string]struct{}, len(remoteIDs))
+	for _, id := range remoteIDs {
+		wanted[id] = struct{}{}
+	}
+
+	// Only nodes left ineligible by the scale in drain need to be updated.
+	// Nodes that were purged register again once their instance is running.
+	var ids []NodeResourceID
+	for _, node := range nodes {
+		if !poolID.IsPoolMember(node) || node.SchedulingEligibility != api.NodeSchedulingIneligible {
+			continue
+		}
+
+		nodeInfo, _, err := c.client.Nodes().Info(node.ID, nil)
+		if err != nil {
+			return err
+		}
+
+		id, err := c.ClusterNodeIDLookupFunc(nodeInfo)
+		if err != nil {
+			continue
+		}
+		if _, ok := wanted[id]; ok {
+			ids = append(ids, NodeResourceID{NomadNodeID: node.ID, RemoteResourceID: id})
+		}
+	}
+
+	return c.setNodesEligible(ids)
+}
+
 // IsPoolReady provides a method for understanding whether the node pool is in
 // a state that allows it to be safely scaled. This should be used by target
 // plugins when providing their status response. A non-nil error indicates
@@ -369,9 +443,9 @@ func (c *ClusterScaleUtils) IsPoolReady(cfg map[string]string) (bool, error) {
 		return false, err
 	}
 
-	nodes, _, err := c.client.Nodes().List(nil)
+	nodes, err := c.listNodes()
 	if err != nil {
-		return false, fmt.Errorf("failed to list Nomad nodes: %v", err)
+		return false, err
 	}
 
 	filterOpts, err := NewNodeFilterOptions(cfg)
@@ -385,6 +459,28 @@ func (c *ClusterScaleUtils) IsPoolReady(cfg map[string]string) (bool, error) {
 	return true, nil
 }
 
+// listNodes returns the Nomad nodes, including their resources, from the
+// cache if available or the Nomad API otherwise.
+func (c *ClusterScaleUtils) listNodes() ([]*api.NodeListStub, error) {
+	if c.cache != nil {
+		return c.cache.Nodes()
+	}
+
+	nodes, _, err := c.client.Nodes().List(&api.QueryOptions{Params: map[string]string{"resources": "true"}})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list Nomad nodes from API: %v", err)
+	}
+	return nodes, nil
+}
+
+// nodeAllocations returns the allocations of the node from the Nomad API.
+// They are read per node, rather than listed in all namespaces, so all the
+// allocations are returned to tokens which only have node:read.
+func (c *ClusterScaleUtils) nodeAllocations(nodeID string) ([]*api.Allocation, error) {
+	allocs, _, err := c.client.Nodes().Allocations(nodeID, nil)
+	return allocs, err
+}
+
 // autoscalerNodeID identifies the NodeID which the Nomad Autoscaler is running
 // on so that it can be protected from scaling in actions.
 func autoscalerNodeID(client *api.Client) (string, error) {
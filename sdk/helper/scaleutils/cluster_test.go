@@ -4,9 +4,19 @@
 package scaleutils
 
 import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
 	"testing"
 
+	"github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/nomad-autoscaler/sdk"
+	"github.com/hashicorp/nomad/api"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func Test_autoscalerNodeID(t *testing.T) {
@@ -32,3 +42,72 @@ func Test_autoscalerNodeID(t *testing.T) {
 		})
 	}
 }
+
+func TestClusterScaleUtils_RunPostScaleOutTasks(t *testing.T) {
+	nodes := map[string]*api.Node{
+		"node1": {ID: "node1", NodeClass: "web", SchedulingEligibility: api.NodeSchedulingIneligible,
+			Attributes: map[string]string{"remote_id": "instance1"}},
+		"node2": {ID: "node2", NodeClass: "web", SchedulingEligibility: api.NodeSchedulingIneligible,
+			Attributes: map[string]string{"remote_id": "instance2"}},
+		"node3": {ID: "node3", NodeClass: "web", SchedulingEligibility: api.NodeSchedulingEligible,
+			Attributes: map[string]string{"remote_id": "instance3"}},
+		"node4": {ID: "node4", NodeClass: "batch", SchedulingEligibility: api.NodeSchedulingIneligible,
+			Attributes: map[string]string{"remote_id": "instance4"}},
+	}
+
+	var (
+		lock     sync.Mutex
+		eligible []string
+	)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Nomad-Index", "1")
+
+		if r.URL.Path == "/v1/nodes" {
+			var stubs []*api.NodeListStub
+			for _, id := range []string{"node1", "node2", "node3", "node4"} {
+				n := nodes[id]
+				stubs = append(stubs, &api.NodeListStub{ID: n.ID, NodeClass: n.NodeClass, SchedulingEligibility: n.SchedulingEligibility})
+			}
+			_ = json.NewEncoder(w).Encode(stubs)
+			return
+		}
+
+		id := strings.TrimPrefix(r.URL.Path, "/v1/node/")
+		if strings.HasSuffix(id, "/eligibility") {
+			lock.Lock()
+			eligible = append(eligible, strings.TrimSuffix(id, "/eligibility"))
+			lock.Unlock()
+			_ = json.NewEncoder(w).Encode(&api.NodeEligibilityUpdateResponse{})
+			return
+		}
+		if n, ok := nodes[id]; ok {
+			_ = json.NewEncoder(w).Encode(n)
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer ts.Close()
+
+	cfg := api.DefaultConfig()
+	cfg.Address = ts.URL
+	client, err := api.NewClient(cfg)
+	require.NoError(t, err)
+
+	c := &ClusterScaleUtils{log: hclog.NewNullLogger(), client: client}
+	targetCfg := map[string]string{sdk.TargetConfigKeyClass: "web"}
+
+	// The lookup function is required.
+	err = c.RunPostScaleOutTasks(context.Background(), targetCfg, []string{"instance1"})
+	assert.EqualError(t, err, "required ClusterNodeIDLookupFunc not set")
+
+	c.ClusterNodeIDLookupFunc = func(n *api.Node) (string, error) {
+		return n.Attributes["remote_id"], nil
+	}
+
+	// Only the ineligible nodes of the pool and remote IDs are updated.
+	err = c.RunPostScaleOutTasks(context.Background(), targetCfg,
+		[]string{"instance1", "instance3", "instance4"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"node1"}, eligible)
+}
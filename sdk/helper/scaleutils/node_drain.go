@@ -173,6 +173,11 @@ func (c *ClusterScaleUtils) monitorNodeDrain(ctx context.Context, nodeID string,
 //
 //   - modify node eligibility to true
 func (c *ClusterScaleUtils) RunPostScaleInTasksOnFailure(nodes []NodeResourceID) error {
+	return c.setNodesEligible(nodes)
+}
+
+// setNodesEligible marks the nodes as eligible for scheduling.
+func (c *ClusterScaleUtils) setNodesEligible(nodes []NodeResourceID) error {
 
 	var mErr *multierror.Error
 
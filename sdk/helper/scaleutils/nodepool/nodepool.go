@@ -28,8 +28,9 @@ type ClusterNodePoolIdentifier interface {
 }
 
 // NewClusterNodePoolIdentifier generates a new ClusterNodePoolIdentifier based
-// on the provided configuration. If a valid option is not found, an error will
-// be returned.
+// on the provided configuration, including the custom identifiers registered
+// with RegisterClusterNodePoolIdentifier. If a valid option is not found, an
+// error will be returned.
 func NewClusterNodePoolIdentifier(cfg map[string]string) (ClusterNodePoolIdentifier, error) {
 	class, hasClass := cfg[sdk.TargetConfigKeyClass]
 	dc, hasDC := cfg[sdk.TargetConfigKeyDatacenter]
@@ -46,6 +47,12 @@ func NewClusterNodePoolIdentifier(cfg map[string]string) (ClusterNodePoolIdentif
 		ids = append(ids, NewNodePoolClusterPoolIdentifier(pool))
 	}
 
+	custom, err := registeredIdentifiers(cfg)
+	if err != nil {
+		return nil, err
+	}
+	ids = append(ids, custom...)
+
 	switch len(ids) {
 	case 0:
 		return nil, fmt.Errorf("node pool identification method required")
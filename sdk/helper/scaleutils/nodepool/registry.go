@@ -0,0 +1,92 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package nodepool
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/hashicorp/nomad-autoscaler/sdk"
+)
+
+// ClusterNodePoolIdentifierFactory builds a ClusterNodePoolIdentifier for the
+// value of the target config key it is registered for. The whole target config
+// is also provided so implementations can read additional options.
+type ClusterNodePoolIdentifierFactory func(value string, cfg map[string]string) (ClusterNodePoolIdentifier, error)
+
+var (
+	// registryLock protects registry.
+	registryLock sync.RWMutex
+
+	// registry holds the custom identifier factories keyed by the target
+	// config key they handle.
+	registry = make(map[string]ClusterNodePoolIdentifierFactory)
+)
+
+// RegisterClusterNodePoolIdentifier registers a custom ClusterNodePoolIdentifier
+// used by NewClusterNodePoolIdentifier when the target config contains key.
+// This allows external target plugins to implement their own node pool
+// membership logic, such as looking up cloud provider tags, while reusing the
+// scaleutils cluster scaling functionality. It is usually called once, when
+// the plugin starts.
+//
+// The built-in node_class, datacenter and node_pool keys can't be overridden,
+// and each key can only be registered once.
+func RegisterClusterNodePoolIdentifier(key string, factory ClusterNodePoolIdentifierFactory) error {
+	if key == "" {
+		return fmt.Errorf("node pool identifier key is required")
+	}
+	if factory == nil {
+		return fmt.Errorf("node pool identifier factory for %q is required", key)
+	}
+
+	switch key {
+	case sdk.TargetConfigKeyClass, sdk.TargetConfigKeyDatacenter, sdk.TargetConfigKeyNodePool:
+		return fmt.Errorf("node pool identifier %q is built-in and can't be registered", key)
+	}
+
+	registryLock.Lock()
+	defer registryLock.Unlock()
+
+	if _, ok := registry[key]; ok {
+		return fmt.Errorf("node pool identifier %q is already registered", key)
+	}
+	registry[key] = factory
+	return nil
+}
+
+// DeregisterClusterNodePoolIdentifier removes a custom identifier previously
+// registered with RegisterClusterNodePoolIdentifier.
+func DeregisterClusterNodePoolIdentifier(key string) {
+	registryLock.Lock()
+	defer registryLock.Unlock()
+	delete(registry, key)
+}
+
+// registeredIdentifiers builds the custom identifiers whose key is set in the
+// target config. They are returned sorted by key so the combined identifier
+// is deterministic.
+func registeredIdentifiers(cfg map[string]string) ([]ClusterNodePoolIdentifier, error) {
+	registryLock.RLock()
+	defer registryLock.RUnlock()
+
+	keys := make([]string, 0, len(registry))
+	for key := range registry {
+		if _, ok := cfg[key]; ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	ids := make([]ClusterNodePoolIdentifier, 0, len(keys))
+	for _, key := range keys {
+		id, err := registry[key](cfg[key], cfg)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create %s node pool identifier: %v", key, err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
@@ -0,0 +1,89 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package nodepool
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/hashicorp/nomad/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testTagPoolIdentifier is a custom ClusterNodePoolIdentifier which filters
+// nodes by a tag stored in their ID, in place of a cloud provider lookup.
+type testTagPoolIdentifier struct {
+	tag string
+}
+
+func (t testTagPoolIdentifier) IsPoolMember(n *api.NodeListStub) bool { return n.ID == t.tag }
+func (t testTagPoolIdentifier) Key() string                           { return "cloud_tag" }
+func (t testTagPoolIdentifier) Value() string                         { return t.tag }
+
+func testTagPoolIdentifierFactory(value string, cfg map[string]string) (ClusterNodePoolIdentifier, error) {
+	if value == "" {
+		return nil, errors.New("tag can't be empty")
+	}
+	return testTagPoolIdentifier{tag: value}, nil
+}
+
+func TestRegisterClusterNodePoolIdentifier(t *testing.T) {
+	require.NoError(t, RegisterClusterNodePoolIdentifier("cloud_tag", testTagPoolIdentifierFactory))
+	t.Cleanup(func() { DeregisterClusterNodePoolIdentifier("cloud_tag") })
+
+	assert.ErrorContains(t, RegisterClusterNodePoolIdentifier("cloud_tag", testTagPoolIdentifierFactory), "already registered")
+	assert.ErrorContains(t, RegisterClusterNodePoolIdentifier("node_class", testTagPoolIdentifierFactory), "built-in")
+	assert.ErrorContains(t, RegisterClusterNodePoolIdentifier("other", nil), "factory for \"other\" is required")
+
+	testCases := []struct {
+		name                string
+		inputCfg            map[string]string
+		expectedOutputKey   string
+		expectedOutputValue string
+		expectedOutputErr   string
+	}{
+		{
+			name:                "custom identifier only",
+			inputCfg:            map[string]string{"cloud_tag": "web"},
+			expectedOutputKey:   "cloud_tag",
+			expectedOutputValue: "web",
+		},
+		{
+			name:                "custom identifier combined with built-in",
+			inputCfg:            map[string]string{"cloud_tag": "web", "node_pool": "gpu"},
+			expectedOutputKey:   "combined_identifier",
+			expectedOutputValue: "node_pool:gpu and cloud_tag:web",
+		},
+		{
+			name:              "custom identifier error",
+			inputCfg:          map[string]string{"cloud_tag": ""},
+			expectedOutputErr: "failed to create cloud_tag node pool identifier: tag can't be empty",
+		},
+		{
+			name:              "unregistered key",
+			inputCfg:          map[string]string{"other": "web"},
+			expectedOutputErr: "node pool identification method required",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			impl, err := NewClusterNodePoolIdentifier(tc.inputCfg)
+			if tc.expectedOutputErr != "" {
+				assert.EqualError(t, err, tc.expectedOutputErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedOutputKey, impl.Key())
+			assert.Equal(t, tc.expectedOutputValue, impl.Value())
+		})
+	}
+
+	// Nodes are filtered using the custom membership logic.
+	impl, err := NewClusterNodePoolIdentifier(map[string]string{"cloud_tag": "web"})
+	require.NoError(t, err)
+	assert.True(t, impl.IsPoolMember(&api.NodeListStub{ID: "web"}))
+	assert.False(t, impl.IsPoolMember(&api.NodeListStub{ID: "batch"}))
+}
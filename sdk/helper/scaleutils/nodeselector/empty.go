@@ -14,7 +14,7 @@ import (
 // if they do not have any non-terminal allocations, and therefore can be
 // classed as empty.
 type emptyClusterScaleInNodeSelector struct {
-	client           *api.Client
+	allocs           NodeAllocationsFunc
 	log              hclog.Logger
 	ignoreSystemJobs bool
 }
@@ -22,9 +22,9 @@ type emptyClusterScaleInNodeSelector struct {
 // newEmptyClusterScaleInNodeSelector returns a new
 // emptyClusterScaleInNodeSelector implementation of the
 // ClusterScaleInNodeSelector interface.
-func newEmptyClusterScaleInNodeSelector(c *api.Client, log hclog.Logger, ignoreSystemJobs bool) ClusterScaleInNodeSelector {
+func newEmptyClusterScaleInNodeSelector(allocs NodeAllocationsFunc, log hclog.Logger, ignoreSystemJobs bool) ClusterScaleInNodeSelector {
 	return &emptyClusterScaleInNodeSelector{
-		client:           c,
+		allocs:           allocs,
 		log:              log,
 		ignoreSystemJobs: ignoreSystemJobs,
 	}
@@ -70,7 +70,7 @@ func (e *emptyClusterScaleInNodeSelector) Select(nodes []*api.NodeListStub, num
 // nodeHasAllocs returns whether the node has non-terminal allocations.
 func (e *emptyClusterScaleInNodeSelector) nodeHasAllocs(id string) bool {
 
-	allocs, _, err := e.client.Nodes().Allocations(id, nil)
+	allocs, err := e.allocs(id)
 	if err != nil {
 		e.log.Error("failed to detail node allocs", "error", err)
 		return true
@@ -107,7 +107,7 @@ func Test_emptyClusterScaleInNodeSelectorSelect(t *testing.T) {
 	for _, tc := range testCases {
 		for _, ignoreSystem := range []bool{true, false} {
 			// Create a `empty` selector instance.
-			selector := newEmptyClusterScaleInNodeSelector(client, hclog.NewNullLogger(), ignoreSystem)
+			selector := newEmptyClusterScaleInNodeSelector(apiNodeAllocationsFunc(client), hclog.NewNullLogger(), ignoreSystem)
 			name := fmt.Sprintf("%s/%s", selector.Name(), tc.name)
 			t.Run(name, func(t *testing.T) {
 				expectedIDs := tc.expectedIDs
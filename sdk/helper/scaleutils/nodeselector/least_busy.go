@@ -18,15 +18,17 @@ import (
 // value.
 type leastBusyClusterScaleInNodeSelector struct {
 	client *api.Client
+	allocs NodeAllocationsFunc
 	log    hclog.Logger
 }
 
 // newLeastBusyClusterScaleInNodeSelector returns a new
 // leastBusyClusterScaleInNodeSelector implementation of the
 // ClusterScaleInNodeSelector interface.
-func newLeastBusyClusterScaleInNodeSelector(c *api.Client, log hclog.Logger) ClusterScaleInNodeSelector {
+func newLeastBusyClusterScaleInNodeSelector(c *api.Client, log hclog.Logger, allocs NodeAllocationsFunc) ClusterScaleInNodeSelector {
 	return &leastBusyClusterScaleInNodeSelector{
 		client: c,
+		allocs: allocs,
 		log:    log,
 	}
 }
@@ -105,7 +107,7 @@ func (l *leastBusyClusterScaleInNodeSelector) Select(nodes []*api.NodeListStub,
 // to safely understand the least allocated nodes.
 func (l *leastBusyClusterScaleInNodeSelector) computeNodeResources(node *api.NodeListStub) (*nodeResourceStats, error) {
 
-	nodeAllocs, _, err := l.client.Nodes().Allocations(node.ID, nil)
+	nodeAllocs, err := l.allocs(node.ID)
 	if err != nil {
 		return nil, err
 	}
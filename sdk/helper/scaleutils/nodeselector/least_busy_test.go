@@ -14,7 +14,7 @@ import (
 )
 
 func Test_leastBusyClusterScaleInNodeSelectorName(t *testing.T) {
-	assert.Equal(t, "least_busy", newLeastBusyClusterScaleInNodeSelector(nil, nil).Name())
+	assert.Equal(t, "least_busy", newLeastBusyClusterScaleInNodeSelector(nil, nil, nil).Name())
 }
 
 func Test_leastBusyClusterScaleInNodeSelectorSelect(t *testing.T) {
@@ -31,7 +31,7 @@ func Test_leastBusyClusterScaleInNodeSelectorSelect(t *testing.T) {
 	}
 
 	// Create a `least_busy` selector instance.
-	selector := newLeastBusyClusterScaleInNodeSelector(client, hclog.NewNullLogger())
+	selector := newLeastBusyClusterScaleInNodeSelector(client, hclog.NewNullLogger(), apiNodeAllocationsFunc(client))
 
 	testCases := []struct {
 		name        string
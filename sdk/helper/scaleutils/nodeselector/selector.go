@@ -26,10 +26,26 @@ type ClusterScaleInNodeSelector interface {
 	Select([]*api.NodeListStub, int) []*api.NodeListStub
 }
 
+// NodeAllocationsFunc returns the allocations of the node identified by the
+// passed ID. It allows the allocations to be read from a cache rather than
+// calling the Nomad API for each node.
+type NodeAllocationsFunc func(nodeID string) ([]*api.Allocation, error)
+
 // NewSelector takes the user configuration and creates a
 // ClusterScaleInNodeSelector for use. In the event the configuration cannot be
 // understood, an error will be returned.
 func NewSelector(cfg map[string]string, client *api.Client, log hclog.Logger) (ClusterScaleInNodeSelector, error) {
+	return NewSelectorWithAllocationsFunc(cfg, client, log, nil)
+}
+
+// NewSelectorWithAllocationsFunc works like NewSelector, but reads the node
+// allocations using the passed function. If the function is nil, allocations
+// are read from the Nomad API.
+func NewSelectorWithAllocationsFunc(cfg map[string]string, client *api.Client, log hclog.Logger, allocsFn NodeAllocationsFunc) (ClusterScaleInNodeSelector, error) {
+
+	if allocsFn == nil {
+		allocsFn = apiNodeAllocationsFunc(client)
+	}
 
 	// If the user has not configured the strategy, set the default.
 	val, ok := cfg[sdk.TargetConfigNodeSelectorStrategy]
@@ -42,16 +58,25 @@ func NewSelector(cfg map[string]string, client *api.Client, log hclog.Logger) (C
 	// that the user wasn't expecting.
 	switch val {
 	case sdk.TargetNodeSelectorStrategyLeastBusy:
-		return newLeastBusyClusterScaleInNodeSelector(client, log), nil
+		return newLeastBusyClusterScaleInNodeSelector(client, log, allocsFn), nil
 	case sdk.TargetNodeSelectorStrategyNewestCreateIndex:
 		return newNewestCreateIndexClusterScaleInNodeSelector(), nil
 	case sdk.TargetNodeSelectorStrategyEmpty:
-		return newEmptyClusterScaleInNodeSelector(client, log, false), nil
+		return newEmptyClusterScaleInNodeSelector(allocsFn, log, false), nil
 	case sdk.TargetNodeSelectorStrategyEmptyIgnoreSystemJobs:
-		return newEmptyClusterScaleInNodeSelector(client, log, true), nil
+		return newEmptyClusterScaleInNodeSelector(allocsFn, log, true), nil
 	case sdk.TargetNodeSelectorStrategyOldestCreateIndex:
 		return newOldestCreateIndexClusterScaleInNodeSelector(), nil
 	default:
 		return nil, fmt.Errorf("unsupported node selector strategy: %v", val)
 	}
 }
+
+// apiNodeAllocationsFunc returns a NodeAllocationsFunc which reads the node
+// allocations from the Nomad API.
+func apiNodeAllocationsFunc(client *api.Client) NodeAllocationsFunc {
+	return func(nodeID string) ([]*api.Allocation, error) {
+		allocs, _, err := client.Nodes().Allocations(nodeID, nil)
+		return allocs, err
+	}
+}
@@ -0,0 +1,241 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package scaleutils
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/hashicorp/nomad-autoscaler/sdk"
+	"github.com/hashicorp/nomad/api"
+)
+
+// nodePlacement describes a node in terms of the resources it has available
+// and the resources requested by the allocations that would need to be
+// placed elsewhere if it was removed.
+type nodePlacement struct {
+	node *api.NodeListStub
+
+	// freeCPU and freeMemoryMB are the resources that are not allocated on
+	// the node.
+	freeCPU      int64
+	freeMemoryMB int64
+
+	// allocs are the allocations which would need to be rescheduled if the
+	// node was removed. System allocations are not included as they are not
+	// rescheduled onto other nodes.
+	allocs []allocPlacement
+}
+
+// allocPlacement is the amount of resource requested by an allocation.
+type allocPlacement struct {
+	id       string
+	cpu      int64
+	memoryMB int64
+}
+
+// placementCheckEnabled returns whether the operator has requested that the
+// allocations of nodes selected for removal are checked for placement on the
+// remaining nodes.
+func placementCheckEnabled(cfg map[string]string) (bool, error) {
+	val, ok := cfg[sdk.TargetConfigKeyNodePlacementCheck]
+	if !ok || val == "" {
+		return false, nil
+	}
+
+	enabled, err := strconv.ParseBool(val)
+	if err != nil {
+		return false, fmt.Errorf("invalid value for %s: %v", sdk.TargetConfigKeyNodePlacementCheck, err)
+	}
+	return enabled, nil
+}
+
+// selectPlaceableNodes picks up to num nodes from the ordered list of
+// candidates, skipping any node whose allocations cannot be placed on the
+// nodes that would remain. The pool must contain all the nodes that can
+// receive allocations, including the candidates.
+func (c *ClusterScaleUtils) selectPlaceableNodes(pool, candidates []*api.NodeListStub, num int) ([]*api.NodeListStub, error) {
+	placements := make(map[string]*nodePlacement, len(pool))
+	for _, node := range pool {
+		p, err := c.nodePlacement(node)
+		if err != nil {
+			return nil, err
+		}
+		placements[node.ID] = p
+	}
+
+	ordered := make([]*nodePlacement, 0, len(candidates))
+	for _, node := range candidates {
+		if p, ok := placements[node.ID]; ok {
+			ordered = append(ordered, p)
+		}
+	}
+
+	remaining := make([]*nodePlacement, 0, len(pool))
+	for _, node := range pool {
+		remaining = append(remaining, placements[node.ID])
+	}
+
+	out, skipped := simulateScaleIn(remaining, ordered, num)
+	for _, p := range skipped {
+		c.log.Info("skipping node as its allocations would not fit on the remaining nodes",
+			"node_id", p.node.ID, "num_allocs", len(p.allocs))
+	}
+	return out, nil
+}
+
+// nodePlacement reads the resources of the node and its allocations.
+func (c *ClusterScaleUtils) nodePlacement(node *api.NodeListStub) (*nodePlacement, error) {
+	resources, reserved := node.NodeResources, node.ReservedResources
+
+	// The NodeResources object is only available within the Nomad
+	// api.NodeListStub from v1.0.0 onwards, so fallback to reading the node.
+	if resources == nil {
+		nodeInfo, _, err := c.client.Nodes().Info(node.ID, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read node %s: %v", node.ID, err)
+		}
+		if nodeInfo.NodeResources == nil {
+			return nil, fmt.Errorf("node %s does not contain resource info", node.ID)
+		}
+		resources, reserved = nodeInfo.NodeResources, nodeInfo.ReservedResources
+	}
+
+	allocs, err := c.nodeAllocations(node.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list allocations of node %s: %v", node.ID, err)
+	}
+
+	p := &nodePlacement{
+		node:         node,
+		freeCPU:      resources.Cpu.CpuShares,
+		freeMemoryMB: resources.Memory.MemoryMB,
+	}
+	if reserved != nil {
+		p.freeCPU -= int64(reserved.Cpu.CpuShares)
+		p.freeMemoryMB -= int64(reserved.Memory.MemoryMB)
+	}
+
+	for _, alloc := range allocs {
+		if alloc.ClientStatus != api.AllocClientStatusRunning &&
+			alloc.ClientStatus != api.AllocClientStatusPending {
+			continue
+		}
+		if alloc.Resources == nil || alloc.Resources.CPU == nil || alloc.Resources.MemoryMB == nil {
+			continue
+		}
+
+		a := allocPlacement{
+			id:       alloc.ID,
+			cpu:      int64(*alloc.Resources.CPU),
+			memoryMB: int64(*alloc.Resources.MemoryMB),
+		}
+		p.freeCPU -= a.cpu
+		p.freeMemoryMB -= a.memoryMB
+
+		// System allocations run on every node and are not rescheduled, so
+		// they don't need to be placed elsewhere.
+		if alloc.Job != nil && alloc.Job.Type != nil &&
+			(*alloc.Job.Type == api.JobTypeSystem || *alloc.Job.Type == api.JobTypeSysbatch) {
+			continue
+		}
+		p.allocs = append(p.allocs, a)
+	}
+
+	return p, nil
+}
+
+// simulateScaleIn walks the ordered candidates and selects up to num of them
+// for removal. Each candidate is only selected if its allocations, along with
+// the allocations of the previously selected nodes, can be placed on the
+// nodes that remain. Placement only considers CPU and memory using a first
+// fit decreasing approach, so it is an approximation of the Nomad scheduler
+// which ignores constraints, affinities and other resources.
+func simulateScaleIn(pool, candidates []*nodePlacement, num int) ([]*api.NodeListStub, []*nodePlacement) {
+	// free tracks the resources still available on each node as the
+	// simulation places allocations.
+	type capacity struct{ cpu, mem int64 }
+	free := make(map[string]*capacity, len(pool))
+	for _, p := range pool {
+		free[p.node.ID] = &capacity{cpu: p.freeCPU, mem: p.freeMemoryMB}
+	}
+
+	removed := make(map[string]bool)
+
+	// moved tracks the allocations placed on each node by the simulation, so
+	// they are placed again if the node is also selected for removal.
+	moved := make(map[string][]allocPlacement)
+
+	var (
+		out     []*api.NodeListStub
+		skipped []*nodePlacement
+	)
+
+	for _, candidate := range candidates {
+		if len(out) >= num {
+			break
+		}
+
+		// Work on a copy of the capacity so the simulation can be discarded
+		// if the candidate's allocations don't fit.
+		trial := make(map[string]*capacity, len(free))
+		for id, c := range free {
+			if id == candidate.node.ID || removed[id] {
+				continue
+			}
+			trial[id] = &capacity{cpu: c.cpu, mem: c.mem}
+		}
+
+		// Place the largest allocations first, and try nodes in a stable
+		// order so results are deterministic.
+		allocs := make([]allocPlacement, 0, len(candidate.allocs)+len(moved[candidate.node.ID]))
+		allocs = append(allocs, candidate.allocs...)
+		allocs = append(allocs, moved[candidate.node.ID]...)
+		sort.Slice(allocs, func(i, j int) bool {
+			return allocs[i].cpu+allocs[i].memoryMB > allocs[j].cpu+allocs[j].memoryMB
+		})
+
+		nodeIDs := make([]string, 0, len(trial))
+		for id := range trial {
+			nodeIDs = append(nodeIDs, id)
+		}
+		sort.Strings(nodeIDs)
+
+		fits := true
+		trialMoved := make(map[string][]allocPlacement)
+		for _, a := range allocs {
+			placed := false
+			for _, id := range nodeIDs {
+				c := trial[id]
+				if c.cpu >= a.cpu && c.mem >= a.memoryMB {
+					c.cpu -= a.cpu
+					c.mem -= a.memoryMB
+					trialMoved[id] = append(trialMoved[id], a)
+					placed = true
+					break
+				}
+			}
+			if !placed {
+				fits = false
+				break
+			}
+		}
+
+		if !fits {
+			skipped = append(skipped, candidate)
+			continue
+		}
+
+		free = trial
+		removed[candidate.node.ID] = true
+		delete(moved, candidate.node.ID)
+		for id, a := range trialMoved {
+			moved[id] = append(moved[id], a...)
+		}
+		out = append(out, candidate.node)
+	}
+
+	return out, skipped
+}
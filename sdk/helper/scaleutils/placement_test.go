@@ -0,0 +1,111 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package scaleutils
+
+import (
+	"testing"
+
+	"github.com/hashicorp/nomad/api"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_placementCheckEnabled(t *testing.T) {
+	enabled, err := placementCheckEnabled(map[string]string{})
+	assert.NoError(t, err)
+	assert.False(t, enabled)
+
+	enabled, err = placementCheckEnabled(map[string]string{"node_placement_check": "true"})
+	assert.NoError(t, err)
+	assert.True(t, enabled)
+
+	_, err = placementCheckEnabled(map[string]string{"node_placement_check": "yes please"})
+	assert.ErrorContains(t, err, "invalid value for node_placement_check")
+}
+
+func Test_simulateScaleIn(t *testing.T) {
+	newPlacement := func(id string, cpu, mem int64, allocs ...allocPlacement) *nodePlacement {
+		return &nodePlacement{
+			node:         &api.NodeListStub{ID: id},
+			freeCPU:      cpu,
+			freeMemoryMB: mem,
+			allocs:       allocs,
+		}
+	}
+
+	testCases := []struct {
+		name            string
+		pool            []*nodePlacement
+		num             int
+		expectedNodes   []string
+		expectedSkipped []string
+	}{
+		{
+			name: "empty nodes are always selected",
+			pool: []*nodePlacement{
+				newPlacement("a", 1000, 1000),
+				newPlacement("b", 0, 0),
+			},
+			num:           2,
+			expectedNodes: []string{"a", "b"},
+		},
+		{
+			name: "allocations fit on remaining nodes",
+			pool: []*nodePlacement{
+				newPlacement("a", 100, 100, allocPlacement{id: "1", cpu: 500, memoryMB: 256}),
+				newPlacement("b", 1000, 1000),
+			},
+			num:           1,
+			expectedNodes: []string{"a"},
+		},
+		{
+			name: "node skipped when allocations don't fit",
+			pool: []*nodePlacement{
+				newPlacement("a", 100, 100, allocPlacement{id: "1", cpu: 2000, memoryMB: 256}),
+				newPlacement("b", 1000, 1000, allocPlacement{id: "2", cpu: 200, memoryMB: 200}),
+				newPlacement("c", 1000, 1000),
+			},
+			num:             1,
+			expectedNodes:   []string{"b"},
+			expectedSkipped: []string{"a"},
+		},
+		{
+			name: "previously selected nodes reduce capacity",
+			pool: []*nodePlacement{
+				newPlacement("a", 0, 0, allocPlacement{id: "1", cpu: 600, memoryMB: 100}),
+				newPlacement("b", 0, 0, allocPlacement{id: "2", cpu: 600, memoryMB: 100}),
+				newPlacement("c", 1000, 1000),
+			},
+			num:             2,
+			expectedNodes:   []string{"a"},
+			expectedSkipped: []string{"b", "c"},
+		},
+		{
+			name: "stop once enough nodes are selected",
+			pool: []*nodePlacement{
+				newPlacement("a", 1000, 1000),
+				newPlacement("b", 1000, 1000),
+				newPlacement("c", 1000, 1000),
+			},
+			num:           1,
+			expectedNodes: []string{"a"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Candidates are in the same order as the pool.
+			out, skipped := simulateScaleIn(tc.pool, tc.pool, tc.num)
+
+			var nodes, skippedNodes []string
+			for _, n := range out {
+				nodes = append(nodes, n.ID)
+			}
+			for _, p := range skipped {
+				skippedNodes = append(skippedNodes, p.node.ID)
+			}
+			assert.Equal(t, tc.expectedNodes, nodes)
+			assert.Equal(t, tc.expectedSkipped, skippedNodes)
+		})
+	}
+}
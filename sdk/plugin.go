@@ -15,4 +15,7 @@ const (
 
 	// PluginTypeStrategy is a plugin which satisfies the Strategy interface.
 	PluginTypeStrategy = "strategy"
+
+	// PluginTypeEventSink is a plugin which satisfies the EventSink interface.
+	PluginTypeEventSink = "event_sink"
 )
@@ -5,6 +5,7 @@ package sdk
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
@@ -13,11 +14,25 @@ import (
 )
 
 const (
-	ScalingPolicyTypeCluster    = "cluster"
-	ScalingPolicyTypeHorizontal = "horizontal"
+	ScalingPolicyTypeCluster     = "cluster"
+	ScalingPolicyTypeHorizontal  = "horizontal"
+	ScalingPolicyTypeVerticalCPU = "vertical_cpu"
+	ScalingPolicyTypeVerticalMem = "vertical_mem"
+
+	// ScalingPolicyTypeVertical scales multiple resources of a task in a
+	// single evaluation. Each of its checks targets one of the task
+	// resources, identified by the check Resource.
+	ScalingPolicyTypeVertical = "vertical"
 
 	ScalingPolicyOnErrorFail   = "fail"
 	ScalingPolicyOnErrorIgnore = "ignore"
+
+	ScalingPolicyPriorityNormal = "normal"
+	ScalingPolicyPriorityHigh   = "high"
+
+	ScalingPolicyOnOutOfBandChangeRespect   = "respect"
+	ScalingPolicyOnOutOfBandChangeRevert    = "revert"
+	ScalingPolicyOnOutOfBandChangeAlertOnly = "alert-only"
 )
 
 // ScalingPolicy is the internal representation of a scaling document and
@@ -35,6 +50,11 @@ type ScalingPolicy struct {
 	// Priority controls the order in which a policy is picked for evaluation.
 	Priority int
 
+	// PriorityLane is the lane in which the evaluations of the policy are
+	// enqueued. Possible values are "normal" or "high". Evaluations in the
+	// high lane are picked before all the others of the same policy type.
+	PriorityLane string
+
 	// Min forms a lower bound at which the target should never be asked to
 	// break. The autoscaler will actively adjust recommendations to ensure
 	// this value is not violated.
@@ -45,6 +65,17 @@ type ScalingPolicy struct {
 	// this value is not violated.
 	Max int64
 
+	// MaxUnavailable is the maximum percentage of the current count that a
+	// single scale-in action is allowed to remove. It protects the target
+	// from aggressive strategies by spreading large reductions over multiple
+	// evaluations. A value of zero disables the limit.
+	MaxUnavailable float64
+
+	// MaxHourlyCost is the budget ceiling of the target, calculated using the
+	// target unit_hourly_cost. Scale-out actions are capped so the estimated
+	// hourly cost doesn't exceed it. A value of zero disables the limit.
+	MaxHourlyCost float64
+
 	// Enabled indicates whether the autoscaler should actively evaluate the
 	// policy or not.
 	Enabled bool
@@ -61,11 +92,56 @@ type ScalingPolicy struct {
 	// which no policy evaluations will be started.
 	Cooldown time.Duration
 
+	// OnOutOfBandChange defines how the Autoscaler reacts when the target is
+	// scaled outside of the Autoscaler, as detected by the target last event
+	// or by drift detection. Possible values are "respect", "revert" or
+	// "alert-only".
+	//
+	// If "respect" the new count is adopted as the baseline and the policy
+	// is placed into cooldown. If "revert" the target is scaled back to the
+	// last count desired by the policy. If "alert-only" the change is
+	// reported but the policy evaluation is not affected. An empty value
+	// behaves like "respect".
+	OnOutOfBandChange string
+
+	// ApprovalRequired indicates that scaling actions computed for the policy
+	// must be approved by an operator through the HTTP API before they are
+	// executed.
+	ApprovalRequired bool
+
+	// ApprovalTTL is the amount of time an action waits for approval before
+	// it expires and is discarded. A zero value uses the agent default.
+	ApprovalTTL time.Duration
+
+	// Explain indicates that a structured decision log describing each
+	// evaluation of the policy should be written to the agent logs.
+	Explain bool
+
+	// QueryWindowOffset is the query window offset used by the checks of the
+	// policy that don't define their own. A zero value uses the agent
+	// default.
+	QueryWindowOffset time.Duration
+
 	// EvaluationInterval indicates the frequency at which the policy is
 	// evaluated. A lower value means more frequent evaluation and can result
 	// in a high rate of change in the target.
 	EvaluationInterval time.Duration
 
+	// EvaluationTimeout is the maximum amount of time the checks of the
+	// policy, including the APM queries and strategy runs, can take during
+	// an evaluation. A zero value doesn't limit the evaluation.
+	EvaluationTimeout time.Duration
+
+	// Meta is arbitrary user metadata, such as the team, service or cost
+	// center owning the policy. It is carried through evaluations and
+	// included in scaling events and metric labels.
+	Meta map[string]string
+
+	// Resources holds the limits of each task resource scaled by vertical
+	// policies, keyed by resource. Resources without limits use the policy
+	// Min and Max.
+	Resources map[string]*ScalingPolicyResource
+
 	// Checks is an array of checks which will be triggered in parallel to
 	// determine the desired state of the ScalingPolicyTarget.
 	Checks []*ScalingPolicyCheck
@@ -91,6 +167,69 @@ func (p *ScalingPolicy) Validate() error {
 		result = multierror.Append(result, err)
 	}
 
+	switch p.PriorityLane {
+	case "", ScalingPolicyPriorityNormal, ScalingPolicyPriorityHigh:
+	default:
+		err := fmt.Errorf("invalid value for priority: only %s and %s are allowed",
+			ScalingPolicyPriorityNormal, ScalingPolicyPriorityHigh)
+		result = multierror.Append(result, err)
+	}
+
+	switch p.OnOutOfBandChange {
+	case "", ScalingPolicyOnOutOfBandChangeRespect, ScalingPolicyOnOutOfBandChangeRevert, ScalingPolicyOnOutOfBandChangeAlertOnly:
+	default:
+		err := fmt.Errorf("invalid value for on_out_of_band_change: only %s, %s and %s are allowed",
+			ScalingPolicyOnOutOfBandChangeRespect, ScalingPolicyOnOutOfBandChangeRevert, ScalingPolicyOnOutOfBandChangeAlertOnly)
+		result = multierror.Append(result, err)
+	}
+
+	if p.MaxUnavailable < 0 || p.MaxUnavailable > 100 {
+		err := fmt.Errorf("invalid value for max_unavailable: must be between 0%% and 100%%")
+		result = multierror.Append(result, err)
+	}
+
+	if p.MaxHourlyCost < 0 {
+		err := fmt.Errorf("invalid value for max_hourly_cost: must not be negative")
+		result = multierror.Append(result, err)
+	}
+
+	if p.Target != nil {
+		if raw, ok := p.Target.Config[TargetConfigKeyUnitHourlyCost]; ok {
+			if cost, err := strconv.ParseFloat(raw, 64); err != nil || cost < 0 {
+				err := fmt.Errorf("invalid value for %s: must be a non-negative number", TargetConfigKeyUnitHourlyCost)
+				result = multierror.Append(result, err)
+			}
+		}
+	}
+
+	if p.MaxHourlyCost > 0 {
+		if _, ok := p.Target.UnitHourlyCost(); !ok {
+			err := fmt.Errorf("max_hourly_cost requires the target %s to be set", TargetConfigKeyUnitHourlyCost)
+			result = multierror.Append(result, err)
+		}
+	}
+
+	if p.ApprovalTTL < 0 {
+		err := fmt.Errorf("invalid value for approval_ttl: must not be negative")
+		result = multierror.Append(result, err)
+	}
+
+	if p.QueryWindowOffset < 0 {
+		err := fmt.Errorf("invalid value for query_window_offset: must not be negative")
+		result = multierror.Append(result, err)
+	}
+
+	if p.EvaluationTimeout < 0 {
+		err := fmt.Errorf("invalid value for evaluation_timeout: must not be negative")
+		result = multierror.Append(result, err)
+	}
+
+	if p.Type == ScalingPolicyTypeVertical {
+		if err := p.validateResources(); err != nil {
+			result = multierror.Append(result, err)
+		}
+	}
+
 	for _, c := range p.Checks {
 		if c.Strategy == nil || c.Strategy.Name == "" {
 			result = multierror.Append(result, fmt.Errorf("invalid check %s: missing strategy value", c.Name))
@@ -111,11 +250,74 @@ func (p *ScalingPolicy) Validate() error {
 				c.Name, ScalingPolicyOnErrorFail, ScalingPolicyOnErrorIgnore)
 			result = multierror.Append(result, err)
 		}
+
+		if c.HistorySize < 0 {
+			err := fmt.Errorf("invalid value for history_size in check %s: must not be negative", c.Name)
+			result = multierror.Append(result, err)
+		}
+
+		if c.QueryRetryBudget < 0 {
+			err := fmt.Errorf("invalid value for query_retry_budget in check %s: must not be negative", c.Name)
+			result = multierror.Append(result, err)
+		}
+
+		if c.QueryFallbackMaxAge < 0 {
+			err := fmt.Errorf("invalid value for query_fallback_max_age in check %s: must not be negative", c.Name)
+			result = multierror.Append(result, err)
+		}
 	}
 
 	return errHelper.FormattedMultiError(result)
 }
 
+// validateResources validates the resources targeted by the checks of a
+// vertical policy and their limits.
+func (p *ScalingPolicy) validateResources() error {
+	var result *multierror.Error
+
+	for _, c := range p.Checks {
+		switch c.Resource {
+		case TargetResourceCPU, TargetResourceMemory:
+		case "":
+			result = multierror.Append(result, fmt.Errorf("invalid check %s: missing resource value", c.Name))
+		default:
+			result = multierror.Append(result, fmt.Errorf("invalid value for resource in check %s: only %s and %s are allowed",
+				c.Name, TargetResourceCPU, TargetResourceMemory))
+		}
+	}
+
+	for name, r := range p.Resources {
+		switch name {
+		case TargetResourceCPU, TargetResourceMemory:
+		default:
+			result = multierror.Append(result, fmt.Errorf("invalid resource %s: only %s and %s are allowed",
+				name, TargetResourceCPU, TargetResourceMemory))
+			continue
+		}
+		if r.Min < 0 || r.Max < r.Min {
+			result = multierror.Append(result, fmt.Errorf("invalid limits for resource %s: min must not be negative or greater than max", name))
+		}
+	}
+
+	return result.ErrorOrNil()
+}
+
+// Limits returns the min and max values of the task resource, falling back
+// to the policy limits if the resource doesn't define its own.
+func (p *ScalingPolicy) Limits(resource string) (int64, int64) {
+	if r, ok := p.Resources[resource]; ok && r != nil {
+		return r.Min, r.Max
+	}
+	return p.Min, p.Max
+}
+
+// ScalingPolicyResource holds the limits of a task resource scaled by a
+// vertical policy. CPU is measured in MHz and memory in MB.
+type ScalingPolicyResource struct {
+	Min int64
+	Max int64
+}
+
 // ScalingPolicyCheck is an individual check within a scaling policy.This check
 // will be executed in isolation alongside other checks within the policy.
 type ScalingPolicyCheck struct {
@@ -135,6 +337,12 @@ type ScalingPolicyCheck struct {
 	// Query is run against the Source in order to receive a metric response.
 	Query string
 
+	// SourceConfig overrides the agent configuration of the Source plugin for
+	// this check. Only keys allowed by the agent plugin configuration can be
+	// overridden, except for the query options of the plugin, such as the
+	// Prometheus query_step, which are passed with each query instead.
+	SourceConfig map[string]string
+
 	// QueryWindow is used to define how further back in time to query for
 	// metrics.
 	QueryWindow time.Duration
@@ -156,6 +364,32 @@ type ScalingPolicyCheck struct {
 	// If "fail" the the entire policy evaluation will stop and no action will
 	// be taken.
 	OnError string
+
+	// HistorySize is the number of previous evaluation results of this check
+	// that are passed to the strategy plugin. A value of zero, the default,
+	// disables the tracking of check history.
+	HistorySize int
+
+	// QueryRetryBudget is the total amount of time the query can be retried
+	// with backoff within a single evaluation before it is considered failed.
+	// A value of zero, the default, disables retries.
+	QueryRetryBudget time.Duration
+
+	// QueryFallbackMaxAge enables the use of the last metrics successfully
+	// returned by the query when it fails or returns no metrics, as long as
+	// they are not older than this value. A value of zero, the default,
+	// disables the fallback.
+	QueryFallbackMaxAge time.Duration
+
+	// When is an optional boolean expression evaluated before running the
+	// check. The check is skipped if the expression is false. It can
+	// reference the time of the evaluation, the current count of the target
+	// and the metrics of the checks of the policy without a when expression.
+	When string
+
+	// Resource is the task resource the check calculates the value of in
+	// vertical policies, either TargetResourceCPU or TargetResourceMemory.
+	Resource string
 }
 
 // ScalingPolicyStrategy contains the plugin and configuration details for
@@ -182,6 +416,30 @@ type ScalingPolicyTarget struct {
 	// Config is the mapping of config values used by the target plugin. Each
 	// plugin has a set of potentially uniquely supported keys.
 	Config map[string]string `hcl:",remain"`
+
+	// PluginConfig overrides the agent configuration of the target plugin for
+	// this policy. Only keys allowed by the agent plugin configuration can be
+	// overridden.
+	PluginConfig map[string]string `hcl:"plugin_config,optional"`
+}
+
+// UnitHourlyCost returns the hourly cost of a single unit of the target count
+// and whether it has been configured.
+func (t *ScalingPolicyTarget) UnitHourlyCost() (float64, bool) {
+	if t == nil {
+		return 0, false
+	}
+
+	raw, ok := t.Config[TargetConfigKeyUnitHourlyCost]
+	if !ok {
+		return 0, false
+	}
+
+	cost, err := strconv.ParseFloat(raw, 64)
+	if err != nil || cost < 0 {
+		return 0, false
+	}
+	return cost, true
 }
 
 // IsJobTaskGroupTarget identifies whether the ScalingPolicyTarget relates to a
@@ -200,7 +458,22 @@ func (t *ScalingPolicyTarget) IsNodePoolTarget() bool {
 	}
 	_, classOK := t.Config[TargetConfigKeyClass]
 	_, dcOK := t.Config[TargetConfigKeyDatacenter]
-	return classOK || dcOK
+	_, poolOK := t.Config[TargetConfigKeyNodePool]
+	return classOK || dcOK || poolOK
+}
+
+// ParseMaxUnavailable parses a max_unavailable value into a percentage. The
+// value may be expressed with or without a trailing percent sign, such as
+// "25%" or "25".
+func ParseMaxUnavailable(v string) (float64, error) {
+	pct, err := strconv.ParseFloat(strings.TrimSuffix(strings.TrimSpace(v), "%"), 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value for max_unavailable %q: %v", v, err)
+	}
+	if pct < 0 || pct > 100 {
+		return 0, fmt.Errorf("invalid value for max_unavailable %q: must be between 0%% and 100%%", v)
+	}
+	return pct, nil
 }
 
 type FileDecodeScalingPolicies struct {
@@ -224,23 +497,54 @@ type FileDecodePolicyDoc struct {
 	Cooldown              time.Duration
 	CooldownHCL           string `hcl:"cooldown,optional"`
 	EvaluationInterval    time.Duration
-	EvaluationIntervalHCL string                      `hcl:"evaluation_interval,optional"`
+	EvaluationIntervalHCL string `hcl:"evaluation_interval,optional"`
+	EvaluationTimeout     time.Duration
+	EvaluationTimeoutHCL  string `hcl:"evaluation_timeout,optional"`
+	MaxUnavailable        float64
+	MaxUnavailableHCL     string  `hcl:"max_unavailable,optional"`
+	MaxHourlyCost         float64 `hcl:"max_hourly_cost,optional"`
+	ApprovalRequired      bool    `hcl:"approval_required,optional"`
+	ApprovalTTL           time.Duration
+	ApprovalTTLHCL        string `hcl:"approval_ttl,optional"`
+	Explain               bool   `hcl:"explain,optional"`
+	QueryWindowOffset     time.Duration
+	QueryWindowOffsetHCL  string                      `hcl:"query_window_offset,optional"`
 	OnCheckError          string                      `hcl:"on_check_error,optional"`
+	OnOutOfBandChange     string                      `hcl:"on_out_of_band_change,optional"`
+	Priority              string                      `hcl:"priority,optional"`
+	Meta                  map[string]string           `hcl:"meta,optional"`
+	Resources             []*FileDecodeResourceDoc    `hcl:"resource,block"`
 	Checks                []*FileDecodePolicyCheckDoc `hcl:"check,block"`
 	Target                *ScalingPolicyTarget        `hcl:"target,block"`
 }
 
 type FileDecodePolicyCheckDoc struct {
-	Name                 string `hcl:"name,label"`
-	Group                string `hcl:"group,optional"`
-	Source               string `hcl:"source,optional"`
-	Query                string `hcl:"query,optional"`
-	QueryWindow          time.Duration
-	QueryWindowHCL       string `hcl:"query_window,optional"`
-	QueryWindowOffset    time.Duration
-	QueryWindowOffsetHCL string                 `hcl:"query_window_offset,optional"`
-	OnError              string                 `hcl:"on_error,optional"`
-	Strategy             *ScalingPolicyStrategy `hcl:"strategy,block"`
+	Name                   string            `hcl:"name,label"`
+	Group                  string            `hcl:"group,optional"`
+	Source                 string            `hcl:"source,optional"`
+	Query                  string            `hcl:"query,optional"`
+	SourceConfig           map[string]string `hcl:"source_config,optional"`
+	QueryWindow            time.Duration
+	QueryWindowHCL         string `hcl:"query_window,optional"`
+	QueryWindowOffset      time.Duration
+	QueryWindowOffsetHCL   string `hcl:"query_window_offset,optional"`
+	OnError                string `hcl:"on_error,optional"`
+	HistorySize            int    `hcl:"history_size,optional"`
+	QueryRetryBudget       time.Duration
+	QueryRetryBudgetHCL    string `hcl:"query_retry_budget,optional"`
+	QueryFallbackMaxAge    time.Duration
+	QueryFallbackMaxAgeHCL string                 `hcl:"query_fallback_max_age,optional"`
+	When                   string                 `hcl:"when,optional"`
+	Resource               string                 `hcl:"resource,optional"`
+	Strategy               *ScalingPolicyStrategy `hcl:"strategy,block"`
+}
+
+// FileDecodeResourceDoc holds the limits of a task resource scaled by a
+// vertical policy.
+type FileDecodeResourceDoc struct {
+	Name string `hcl:"name,label"`
+	Min  int64  `hcl:"min,optional"`
+	Max  int64  `hcl:"max"`
 }
 
 // Translate all values from the decoded policy file into our internal policy
@@ -254,9 +558,26 @@ func (fpd *FileDecodeScalingPolicy) Translate() *ScalingPolicy {
 	p.Type = fpd.Type
 	p.Cooldown = fpd.Doc.Cooldown
 	p.EvaluationInterval = fpd.Doc.EvaluationInterval
+	p.EvaluationTimeout = fpd.Doc.EvaluationTimeout
 	p.OnCheckError = fpd.Doc.OnCheckError
+	p.OnOutOfBandChange = fpd.Doc.OnOutOfBandChange
+	p.PriorityLane = fpd.Doc.Priority
+	p.MaxUnavailable = fpd.Doc.MaxUnavailable
+	p.MaxHourlyCost = fpd.Doc.MaxHourlyCost
+	p.ApprovalRequired = fpd.Doc.ApprovalRequired
+	p.ApprovalTTL = fpd.Doc.ApprovalTTL
+	p.Explain = fpd.Doc.Explain
+	p.QueryWindowOffset = fpd.Doc.QueryWindowOffset
+	p.Meta = fpd.Doc.Meta
 	p.Target = fpd.Doc.Target
 
+	if len(fpd.Doc.Resources) > 0 {
+		p.Resources = make(map[string]*ScalingPolicyResource, len(fpd.Doc.Resources))
+		for _, r := range fpd.Doc.Resources {
+			p.Resources[r.Name] = &ScalingPolicyResource{Min: r.Min, Max: r.Max}
+		}
+	}
+
 	fpd.translateChecks(p)
 
 	return p
@@ -280,8 +601,14 @@ func (fdc *FileDecodePolicyCheckDoc) Translate(c *ScalingPolicyCheck) {
 	c.Group = fdc.Group
 	c.Source = fdc.Source
 	c.Query = fdc.Query
+	c.SourceConfig = fdc.SourceConfig
 	c.QueryWindow = fdc.QueryWindow
 	c.QueryWindowOffset = fdc.QueryWindowOffset
 	c.OnError = fdc.OnError
+	c.HistorySize = fdc.HistorySize
+	c.QueryRetryBudget = fdc.QueryRetryBudget
+	c.QueryFallbackMaxAge = fdc.QueryFallbackMaxAge
+	c.When = fdc.When
+	c.Resource = fdc.Resource
 	c.Strategy = fdc.Strategy
 }
@@ -29,6 +29,59 @@ func TestScalingPolicy_Validate(t *testing.T) {
 			},
 			expectedError: "invalid value for on_check_error",
 		},
+		{
+			name: "invalid priority",
+			policy: &ScalingPolicy{
+				Type:         "horizontal",
+				PriorityLane: "urgent",
+			},
+			expectedError: "invalid value for priority",
+		},
+		{
+			name: "invalid on_out_of_band_change",
+			policy: &ScalingPolicy{
+				Type:              "horizontal",
+				OnOutOfBandChange: "ignore",
+			},
+			expectedError: "invalid value for on_out_of_band_change",
+		},
+		{
+			name: "negative evaluation_timeout",
+			policy: &ScalingPolicy{
+				Type:              "horizontal",
+				EvaluationTimeout: -time.Second,
+			},
+			expectedError: "invalid value for evaluation_timeout",
+		},
+		{
+			name: "invalid max_unavailable",
+			policy: &ScalingPolicy{
+				Type:           "cluster",
+				MaxUnavailable: 120,
+			},
+			expectedError: "invalid value for max_unavailable",
+		},
+		{
+			name: "max_hourly_cost without unit cost",
+			policy: &ScalingPolicy{
+				Type:          "cluster",
+				MaxHourlyCost: 10,
+				Target: &ScalingPolicyTarget{
+					Config: map[string]string{},
+				},
+			},
+			expectedError: "max_hourly_cost requires the target unit_hourly_cost to be set",
+		},
+		{
+			name: "invalid unit_hourly_cost",
+			policy: &ScalingPolicy{
+				Type: "cluster",
+				Target: &ScalingPolicyTarget{
+					Config: map[string]string{"unit_hourly_cost": "cheap"},
+				},
+			},
+			expectedError: "invalid value for unit_hourly_cost",
+		},
 		{
 			name: "invalid on_error",
 			policy: &ScalingPolicy{
@@ -52,6 +105,38 @@ func TestScalingPolicy_Validate(t *testing.T) {
 			},
 			expectedError: "invalid value for on_error in check",
 		},
+		{
+			name: "negative history_size",
+			policy: &ScalingPolicy{
+				Type: "horizontal",
+				Checks: []*ScalingPolicyCheck{
+					{
+						Name:        "invalid",
+						HistorySize: -1,
+						Strategy: &ScalingPolicyStrategy{
+							Name: "target-value",
+						},
+					},
+				},
+			},
+			expectedError: "invalid value for history_size in check invalid",
+		},
+		{
+			name: "negative query_retry_budget",
+			policy: &ScalingPolicy{
+				Type: "horizontal",
+				Checks: []*ScalingPolicyCheck{
+					{
+						Name:             "invalid",
+						QueryRetryBudget: -time.Second,
+						Strategy: &ScalingPolicyStrategy{
+							Name: "target-value",
+						},
+					},
+				},
+			},
+			expectedError: "invalid value for query_retry_budget in check invalid",
+		},
 		{
 			name: "DAS plugin with non-vertical policy",
 			policy: &ScalingPolicy{
@@ -85,6 +170,46 @@ func TestScalingPolicy_Validate(t *testing.T) {
 			},
 			expectedError: "missing strategy",
 		},
+		{
+			name: "vertical check missing resource",
+			policy: &ScalingPolicy{
+				Type: "vertical",
+				Checks: []*ScalingPolicyCheck{
+					{Name: "cpu", Strategy: &ScalingPolicyStrategy{Name: "percentile"}},
+				},
+			},
+			expectedError: "invalid check cpu: missing resource value",
+		},
+		{
+			name: "vertical check invalid resource",
+			policy: &ScalingPolicy{
+				Type: "vertical",
+				Checks: []*ScalingPolicyCheck{
+					{Name: "disk", Resource: "disk", Strategy: &ScalingPolicyStrategy{Name: "percentile"}},
+				},
+			},
+			expectedError: "invalid value for resource in check disk",
+		},
+		{
+			name: "vertical invalid resource limits",
+			policy: &ScalingPolicy{
+				Type:      "vertical",
+				Resources: map[string]*ScalingPolicyResource{"memory": {Min: 512, Max: 256}},
+			},
+			expectedError: "invalid limits for resource memory",
+		},
+		{
+			name: "valid vertical policy",
+			policy: &ScalingPolicy{
+				Type:      "vertical",
+				Resources: map[string]*ScalingPolicyResource{"memory": {Min: 128, Max: 1024}},
+				Checks: []*ScalingPolicyCheck{
+					{Name: "cpu", Resource: "cpu", Strategy: &ScalingPolicyStrategy{Name: "percentile"}},
+					{Name: "mem", Resource: "memory", Strategy: &ScalingPolicyStrategy{Name: "percentile"}},
+				},
+			},
+			expectedError: "",
+		},
 		{
 			name: "valid policy",
 			policy: &ScalingPolicy{
@@ -117,6 +242,22 @@ func TestScalingPolicy_Validate(t *testing.T) {
 	}
 }
 
+func TestScalingPolicy_Limits(t *testing.T) {
+	p := &ScalingPolicy{
+		Min:       100,
+		Max:       2000,
+		Resources: map[string]*ScalingPolicyResource{"memory": {Min: 128, Max: 4096}},
+	}
+
+	min, max := p.Limits("memory")
+	assert.Equal(t, int64(128), min)
+	assert.Equal(t, int64(4096), max)
+
+	min, max = p.Limits("cpu")
+	assert.Equal(t, int64(100), min)
+	assert.Equal(t, int64(2000), max)
+}
+
 func TestScalingPolicyTarget_IsNodePoolTarget(t *testing.T) {
 	testCases := []struct {
 		inputScalingPolicyTarget *ScalingPolicyTarget
@@ -144,6 +285,13 @@ func TestScalingPolicyTarget_IsNodePoolTarget(t *testing.T) {
 			expectedOutput: true,
 			name:           "datacenter input target",
 		},
+		{
+			inputScalingPolicyTarget: &ScalingPolicyTarget{
+				Config: map[string]string{"node_pool": "gpu"},
+			},
+			expectedOutput: true,
+			name:           "node_pool input target",
+		},
 	}
 
 	for _, tc := range testCases {
@@ -234,3 +382,45 @@ func TestFileDecodePolicy_Translate(t *testing.T) {
 		})
 	}
 }
+
+func TestParseMaxUnavailable(t *testing.T) {
+	testCases := []struct {
+		name          string
+		input         string
+		expected      float64
+		expectedError string
+	}{
+		{
+			name:     "percentage",
+			input:    "25%",
+			expected: 25,
+		},
+		{
+			name:     "number",
+			input:    "12.5",
+			expected: 12.5,
+		},
+		{
+			name:          "out of range",
+			input:         "150%",
+			expectedError: "must be between 0% and 100%",
+		},
+		{
+			name:          "invalid",
+			input:         "half",
+			expectedError: "invalid value for max_unavailable",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := ParseMaxUnavailable(tc.input)
+			if tc.expectedError != "" {
+				assert.ErrorContains(t, err, tc.expectedError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, got)
+		})
+	}
+}
@@ -3,7 +3,11 @@
 
 package sdk
 
-import "fmt"
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+)
 
 const (
 	// strategyActionMetaKey are standardised keys used by the autoscaler to
@@ -14,6 +18,14 @@ const (
 	strategyActionMetaKeyCountCapped   = "nomad_autoscaler.count.capped"
 	strategyActionMetaKeyCountOriginal = "nomad_autoscaler.count.original"
 	strategyActionMetaKeyReasonHistory = "nomad_autoscaler.reason_history"
+	strategyActionMetaKeyHourlyCost    = "nomad_autoscaler.cost.hourly"
+	strategyActionMetaKeyHourlyDelta   = "nomad_autoscaler.cost.hourly_delta"
+	strategyActionMetaKeyCorrelationID = "nomad_autoscaler.correlation_id"
+	strategyActionMetaKeyCheck         = "nomad_autoscaler.check"
+	strategyActionMetaKeyCheckMetric   = "nomad_autoscaler.check.metric"
+	strategyActionMetaKeyStrategy      = "nomad_autoscaler.strategy"
+	strategyActionMetaKeyPolicyMeta    = "nomad_autoscaler.policy_meta"
+	strategyActionMetaKeyExplanation   = "nomad_autoscaler.explanation"
 
 	// StrategyActionMetaValueDryRunCount is a special count value used when
 	// performing dry-run scaling activities. The Autoscaler will never set a
@@ -121,6 +133,249 @@ func (a *ScalingAction) CapCount(min, max int64) {
 	}
 }
 
+// CapScaleIn limits a scale-in action so that it removes at most
+// maxUnavailable percent of the current count. At least one unit is always
+// allowed to be removed so that the target can still make progress. A
+// maxUnavailable of zero disables the limit.
+func (a *ScalingAction) CapScaleIn(current int64, maxUnavailable float64) {
+	if a.Count == StrategyActionMetaValueDryRunCount || maxUnavailable <= 0 || a.Count >= current {
+		return
+	}
+
+	step := int64(math.Floor(float64(current) * maxUnavailable / 100))
+	if step < 1 {
+		step = 1
+	}
+
+	floor := current - step
+	if a.Count >= floor {
+		return
+	}
+
+	oldCount := a.Count
+	a.Meta[strategyActionMetaKeyCountCapped] = true
+	a.Meta[strategyActionMetaKeyCountOriginal] = oldCount
+	a.pushReason(fmt.Sprintf("capped count from %d to %d to respect max_unavailable of %g%%",
+		oldCount, floor, maxUnavailable))
+	a.Count = floor
+}
+
+// CapHourlyCost limits a scale-out action so that the estimated hourly cost
+// of the target, calculated as the count multiplied by unitCost, doesn't
+// exceed maxCost. Scale-in actions are never modified, and the count is never
+// capped below the current count. A maxCost of zero disables the limit.
+func (a *ScalingAction) CapHourlyCost(current int64, unitCost, maxCost float64) {
+	if a.Count == StrategyActionMetaValueDryRunCount || maxCost <= 0 || unitCost <= 0 || a.Count <= current {
+		return
+	}
+
+	ceiling := int64(math.Floor(maxCost / unitCost))
+	if ceiling < current {
+		ceiling = current
+	}
+
+	if a.Count <= ceiling {
+		return
+	}
+
+	oldCount := a.Count
+	a.Meta[strategyActionMetaKeyCountCapped] = true
+	a.Meta[strategyActionMetaKeyCountOriginal] = oldCount
+	a.pushReason(fmt.Sprintf("capped count from %d to %d to stay within max_hourly_cost of %g",
+		oldCount, ceiling, maxCost))
+	a.Count = ceiling
+}
+
+// CapCapacity limits a scale-out action so that the count doesn't exceed the
+// ceiling reported by the target provider. Scale-in actions are never
+// modified, and the count is never capped below the current count.
+func (a *ScalingAction) CapCapacity(current int64, capacity *TargetCapacity) {
+	if a.Count == StrategyActionMetaValueDryRunCount || a.Count <= current {
+		return
+	}
+
+	ceiling, ok := capacity.Ceiling(current)
+	if !ok {
+		return
+	}
+	if ceiling < current {
+		ceiling = current
+	}
+
+	if a.Count <= ceiling {
+		return
+	}
+
+	oldCount := a.Count
+	a.Meta[strategyActionMetaKeyCountCapped] = true
+	a.Meta[strategyActionMetaKeyCountOriginal] = oldCount
+	a.pushReason(fmt.Sprintf("capped count from %d to %d to stay within provider capacity", oldCount, ceiling))
+	a.Count = ceiling
+}
+
+// CapStep limits the action so that the count changes by at most maxStep
+// compared to the current count. A maxStep of zero disables the limit.
+func (a *ScalingAction) CapStep(current, maxStep int64) {
+	if a.Count == StrategyActionMetaValueDryRunCount || maxStep <= 0 {
+		return
+	}
+
+	newCount := a.Count
+	if newCount > current+maxStep {
+		newCount = current + maxStep
+	} else if newCount < current-maxStep {
+		newCount = current - maxStep
+	}
+
+	if newCount == a.Count {
+		return
+	}
+
+	oldCount := a.Count
+	a.Meta[strategyActionMetaKeyCountCapped] = true
+	a.Meta[strategyActionMetaKeyCountOriginal] = oldCount
+	a.pushReason(fmt.Sprintf("capped count from %d to %d to respect max step of %d", oldCount, newCount, maxStep))
+	a.Count = newCount
+}
+
+// SetCostEstimate stores the estimated hourly cost of the target after the
+// action, and the difference compared to the current count, in the action
+// Meta. It returns the estimated difference.
+func (a *ScalingAction) SetCostEstimate(current int64, unitCost float64) float64 {
+	if a.Count == StrategyActionMetaValueDryRunCount {
+		return 0
+	}
+
+	if a.Meta == nil {
+		a.Meta = make(map[string]interface{})
+	}
+
+	delta := float64(a.Count-current) * unitCost
+	a.Meta[strategyActionMetaKeyHourlyCost] = float64(a.Count) * unitCost
+	a.Meta[strategyActionMetaKeyHourlyDelta] = delta
+	return delta
+}
+
+// SetCorrelationID stores the ID of the evaluation that produced the action
+// in the action Meta. The Meta is forwarded to target plugins, Nomad and
+// event sinks, allowing a single scaling decision to be traced across them.
+func (a *ScalingAction) SetCorrelationID(id string) {
+	if a.Meta == nil {
+		a.Meta = make(map[string]interface{})
+	}
+	a.Meta[strategyActionMetaKeyCorrelationID] = id
+}
+
+// CorrelationID returns the ID of the evaluation that produced the action,
+// or an empty string if it is not set.
+func (a *ScalingAction) CorrelationID() string {
+	id, _ := a.Meta[strategyActionMetaKeyCorrelationID].(string)
+	return id
+}
+
+// SetCheckDetails stores the name of the check which produced the action, the
+// name of its strategy and the last metric value read by the check in the
+// action Meta. The Meta is forwarded to target plugins, allowing targets such
+// as Nomad to record why the count changed.
+func (a *ScalingAction) SetCheckDetails(check, strategy string, metrics TimestampedMetrics) {
+	if a.Meta == nil {
+		a.Meta = make(map[string]interface{})
+	}
+	a.Meta[strategyActionMetaKeyCheck] = check
+	a.Meta[strategyActionMetaKeyStrategy] = strategy
+	if len(metrics) > 0 {
+		a.Meta[strategyActionMetaKeyCheckMetric] = metrics[len(metrics)-1].Value
+	}
+}
+
+// CheckDetails returns the name of the check which produced the action and
+// the name of its strategy, or empty strings if they are not set.
+func (a *ScalingAction) CheckDetails() (string, string) {
+	check, _ := a.Meta[strategyActionMetaKeyCheck].(string)
+	strategy, _ := a.Meta[strategyActionMetaKeyStrategy].(string)
+	return check, strategy
+}
+
+// SetPolicyMeta stores the user metadata of the policy which produced the
+// action in the action Meta.
+func (a *ScalingAction) SetPolicyMeta(meta map[string]string) {
+	if len(meta) == 0 {
+		return
+	}
+	if a.Meta == nil {
+		a.Meta = make(map[string]interface{})
+	}
+	a.Meta[strategyActionMetaKeyPolicyMeta] = meta
+}
+
+// ScalingActionExplanation is the structured data a strategy plugin can
+// attach to an action to explain how its count was calculated. It is stored
+// in the action Meta, so it is forwarded to event sinks and reported by the
+// policy status API. All fields are optional.
+type ScalingActionExplanation struct {
+
+	// Ratio is the ratio computed by the strategy, such as the metric value
+	// divided by the target value.
+	Ratio float64 `json:"ratio,omitempty"`
+
+	// Samples is the number of metric data points used to calculate the
+	// action.
+	Samples int `json:"samples,omitempty"`
+
+	// Confidence is the confidence of the strategy in the action, between 0
+	// and 1. It is mostly useful for strategies that forecast the metric.
+	Confidence float64 `json:"confidence,omitempty"`
+
+	// Details holds any additional strategy specific data.
+	Details map[string]interface{} `json:"details,omitempty"`
+}
+
+// SetExplanation stores the explanation of how the strategy calculated the
+// action in the action Meta.
+func (a *ScalingAction) SetExplanation(e ScalingActionExplanation) {
+	if a.Meta == nil {
+		a.Meta = make(map[string]interface{})
+	}
+	a.Meta[strategyActionMetaKeyExplanation] = e
+}
+
+// Explanation returns the explanation attached to the action by the strategy
+// or nil if there isn't one. Meta is JSON encoded when it is sent by external
+// plugins, so the explanation may have been decoded as a generic map.
+func (a *ScalingAction) Explanation() *ScalingActionExplanation {
+	switch v := a.Meta[strategyActionMetaKeyExplanation].(type) {
+	case nil:
+		return nil
+	case ScalingActionExplanation:
+		return &v
+	case *ScalingActionExplanation:
+		return v
+	default:
+		raw, err := json.Marshal(v)
+		if err != nil {
+			return nil
+		}
+		var e ScalingActionExplanation
+		if err := json.Unmarshal(raw, &e); err != nil {
+			return nil
+		}
+		return &e
+	}
+}
+
+// DesiredCount returns the count the action intends to set on the target. For
+// dry-run actions, it is the count that would have been set if the action
+// was not in dry-run mode.
+func (a *ScalingAction) DesiredCount() int64 {
+	if a.Count != StrategyActionMetaValueDryRunCount {
+		return a.Count
+	}
+	if count, ok := a.Meta[strategyActionMetaKeyDryRunCount].(int64); ok {
+		return count
+	}
+	return a.Count
+}
+
 // PushReason updates the Reason value and stores previous Reason into Meta.
 func (a *ScalingAction) pushReason(r string) {
 	history := []string{}
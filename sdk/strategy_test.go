@@ -4,6 +4,7 @@
 package sdk
 
 import (
+	"encoding/json"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -176,6 +177,405 @@ func TestAction_CapCount(t *testing.T) {
 	}
 }
 
+func TestAction_CapScaleIn(t *testing.T) {
+	testCases := []struct {
+		inputAction          *ScalingAction
+		inputCurrent         int64
+		inputMaxUnavailable  float64
+		expectedOutputAction *ScalingAction
+		name                 string
+	}{
+		{
+			inputAction: &ScalingAction{
+				Count: 2,
+				Meta:  map[string]interface{}{},
+			},
+			inputCurrent:        10,
+			inputMaxUnavailable: 0,
+			expectedOutputAction: &ScalingAction{
+				Count: 2,
+				Meta:  map[string]interface{}{},
+			},
+			name: "limit disabled",
+		},
+		{
+			inputAction: &ScalingAction{
+				Count: 12,
+				Meta:  map[string]interface{}{},
+			},
+			inputCurrent:        10,
+			inputMaxUnavailable: 25,
+			expectedOutputAction: &ScalingAction{
+				Count: 12,
+				Meta:  map[string]interface{}{},
+			},
+			name: "scale out is not limited",
+		},
+		{
+			inputAction: &ScalingAction{
+				Count: 8,
+				Meta:  map[string]interface{}{},
+			},
+			inputCurrent:        10,
+			inputMaxUnavailable: 25,
+			expectedOutputAction: &ScalingAction{
+				Count: 8,
+				Meta:  map[string]interface{}{},
+			},
+			name: "scale in within limit",
+		},
+		{
+			inputAction: &ScalingAction{
+				Count:  2,
+				Meta:   map[string]interface{}{},
+				Reason: "scaled to 2",
+			},
+			inputCurrent:        10,
+			inputMaxUnavailable: 25,
+			expectedOutputAction: &ScalingAction{
+				Count: 8,
+				Meta: map[string]interface{}{
+					"nomad_autoscaler.count.capped":   true,
+					"nomad_autoscaler.count.original": int64(2),
+					"nomad_autoscaler.reason_history": []string{"scaled to 2"},
+				},
+				Reason: "capped count from 2 to 8 to respect max_unavailable of 25%",
+			},
+			name: "scale in above limit",
+		},
+		{
+			inputAction: &ScalingAction{
+				Count: 0,
+				Meta:  map[string]interface{}{},
+			},
+			inputCurrent:        3,
+			inputMaxUnavailable: 10,
+			expectedOutputAction: &ScalingAction{
+				Count: 2,
+				Meta: map[string]interface{}{
+					"nomad_autoscaler.count.capped":   true,
+					"nomad_autoscaler.count.original": int64(0),
+					"nomad_autoscaler.reason_history": []string{},
+				},
+				Reason: "capped count from 0 to 2 to respect max_unavailable of 10%",
+			},
+			name: "always allow removing one",
+		},
+		{
+			inputAction: &ScalingAction{
+				Count: StrategyActionMetaValueDryRunCount,
+				Meta:  map[string]interface{}{},
+			},
+			inputCurrent:        10,
+			inputMaxUnavailable: 25,
+			expectedOutputAction: &ScalingAction{
+				Count: StrategyActionMetaValueDryRunCount,
+				Meta:  map[string]interface{}{},
+			},
+			name: "dry-run count",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.inputAction.CapScaleIn(tc.inputCurrent, tc.inputMaxUnavailable)
+			assert.Equal(t, tc.expectedOutputAction, tc.inputAction)
+		})
+	}
+}
+
+func TestAction_CapHourlyCost(t *testing.T) {
+	testCases := []struct {
+		inputAction          *ScalingAction
+		inputCurrent         int64
+		inputUnitCost        float64
+		inputMaxCost         float64
+		expectedOutputAction *ScalingAction
+		name                 string
+	}{
+		{
+			inputAction:          &ScalingAction{Count: 20, Meta: map[string]interface{}{}},
+			inputCurrent:         5,
+			inputUnitCost:        0.5,
+			inputMaxCost:         0,
+			expectedOutputAction: &ScalingAction{Count: 20, Meta: map[string]interface{}{}},
+			name:                 "limit disabled",
+		},
+		{
+			inputAction:          &ScalingAction{Count: 8, Meta: map[string]interface{}{}},
+			inputCurrent:         5,
+			inputUnitCost:        0.5,
+			inputMaxCost:         5,
+			expectedOutputAction: &ScalingAction{Count: 8, Meta: map[string]interface{}{}},
+			name:                 "within budget",
+		},
+		{
+			inputAction:   &ScalingAction{Count: 20, Meta: map[string]interface{}{}},
+			inputCurrent:  5,
+			inputUnitCost: 0.5,
+			inputMaxCost:  5,
+			expectedOutputAction: &ScalingAction{
+				Count: 10,
+				Meta: map[string]interface{}{
+					"nomad_autoscaler.count.capped":   true,
+					"nomad_autoscaler.count.original": int64(20),
+					"nomad_autoscaler.reason_history": []string{},
+				},
+				Reason: "capped count from 20 to 10 to stay within max_hourly_cost of 5",
+			},
+			name: "scale out above budget",
+		},
+		{
+			inputAction:   &ScalingAction{Count: 20, Meta: map[string]interface{}{}},
+			inputCurrent:  12,
+			inputUnitCost: 0.5,
+			inputMaxCost:  5,
+			expectedOutputAction: &ScalingAction{
+				Count: 12,
+				Meta: map[string]interface{}{
+					"nomad_autoscaler.count.capped":   true,
+					"nomad_autoscaler.count.original": int64(20),
+					"nomad_autoscaler.reason_history": []string{},
+				},
+				Reason: "capped count from 20 to 12 to stay within max_hourly_cost of 5",
+			},
+			name: "never capped below current",
+		},
+		{
+			inputAction:          &ScalingAction{Count: 11, Meta: map[string]interface{}{}},
+			inputCurrent:         12,
+			inputUnitCost:        0.5,
+			inputMaxCost:         5,
+			expectedOutputAction: &ScalingAction{Count: 11, Meta: map[string]interface{}{}},
+			name:                 "scale in is not limited",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.inputAction.CapHourlyCost(tc.inputCurrent, tc.inputUnitCost, tc.inputMaxCost)
+			assert.Equal(t, tc.expectedOutputAction, tc.inputAction)
+		})
+	}
+}
+
+func TestAction_CapCapacity(t *testing.T) {
+	testCases := []struct {
+		inputAction          *ScalingAction
+		inputCurrent         int64
+		inputCapacity        *TargetCapacity
+		expectedOutputAction *ScalingAction
+		name                 string
+	}{
+		{
+			inputAction:          &ScalingAction{Count: 20, Meta: map[string]interface{}{}},
+			inputCurrent:         5,
+			inputCapacity:        nil,
+			expectedOutputAction: &ScalingAction{Count: 20, Meta: map[string]interface{}{}},
+			name:                 "no capacity",
+		},
+		{
+			inputAction:          &ScalingAction{Count: 8, Meta: map[string]interface{}{}},
+			inputCurrent:         5,
+			inputCapacity:        &TargetCapacity{MaxCount: int64ToPtr(10)},
+			expectedOutputAction: &ScalingAction{Count: 8, Meta: map[string]interface{}{}},
+			name:                 "within capacity",
+		},
+		{
+			inputAction:   &ScalingAction{Count: 20, Meta: map[string]interface{}{}},
+			inputCurrent:  5,
+			inputCapacity: &TargetCapacity{MaxCount: int64ToPtr(10), Remaining: int64ToPtr(3)},
+			expectedOutputAction: &ScalingAction{
+				Count: 8,
+				Meta: map[string]interface{}{
+					"nomad_autoscaler.count.capped":   true,
+					"nomad_autoscaler.count.original": int64(20),
+					"nomad_autoscaler.reason_history": []string{},
+				},
+				Reason: "capped count from 20 to 8 to stay within provider capacity",
+			},
+			name: "scale out above remaining quota",
+		},
+		{
+			inputAction:   &ScalingAction{Count: 20, Meta: map[string]interface{}{}},
+			inputCurrent:  12,
+			inputCapacity: &TargetCapacity{MaxCount: int64ToPtr(10)},
+			expectedOutputAction: &ScalingAction{
+				Count: 12,
+				Meta: map[string]interface{}{
+					"nomad_autoscaler.count.capped":   true,
+					"nomad_autoscaler.count.original": int64(20),
+					"nomad_autoscaler.reason_history": []string{},
+				},
+				Reason: "capped count from 20 to 12 to stay within provider capacity",
+			},
+			name: "never capped below current",
+		},
+		{
+			inputAction:          &ScalingAction{Count: 11, Meta: map[string]interface{}{}},
+			inputCurrent:         12,
+			inputCapacity:        &TargetCapacity{MaxCount: int64ToPtr(10)},
+			expectedOutputAction: &ScalingAction{Count: 11, Meta: map[string]interface{}{}},
+			name:                 "scale in is not limited",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.inputAction.CapCapacity(tc.inputCurrent, tc.inputCapacity)
+			assert.Equal(t, tc.expectedOutputAction, tc.inputAction)
+		})
+	}
+}
+
+func TestAction_CapStep(t *testing.T) {
+	testCases := []struct {
+		inputAction          *ScalingAction
+		inputCurrent         int64
+		inputMaxStep         int64
+		expectedOutputAction *ScalingAction
+		name                 string
+	}{
+		{
+			inputAction:          &ScalingAction{Count: 20, Meta: map[string]interface{}{}},
+			inputCurrent:         5,
+			inputMaxStep:         0,
+			expectedOutputAction: &ScalingAction{Count: 20, Meta: map[string]interface{}{}},
+			name:                 "limit disabled",
+		},
+		{
+			inputAction:          &ScalingAction{Count: 8, Meta: map[string]interface{}{}},
+			inputCurrent:         5,
+			inputMaxStep:         3,
+			expectedOutputAction: &ScalingAction{Count: 8, Meta: map[string]interface{}{}},
+			name:                 "within step",
+		},
+		{
+			inputAction:  &ScalingAction{Count: 20, Meta: map[string]interface{}{}},
+			inputCurrent: 5,
+			inputMaxStep: 3,
+			expectedOutputAction: &ScalingAction{
+				Count: 8,
+				Meta: map[string]interface{}{
+					"nomad_autoscaler.count.capped":   true,
+					"nomad_autoscaler.count.original": int64(20),
+					"nomad_autoscaler.reason_history": []string{},
+				},
+				Reason: "capped count from 20 to 8 to respect max step of 3",
+			},
+			name: "scale out above step",
+		},
+		{
+			inputAction:  &ScalingAction{Count: 1, Meta: map[string]interface{}{}},
+			inputCurrent: 10,
+			inputMaxStep: 3,
+			expectedOutputAction: &ScalingAction{
+				Count: 7,
+				Meta: map[string]interface{}{
+					"nomad_autoscaler.count.capped":   true,
+					"nomad_autoscaler.count.original": int64(1),
+					"nomad_autoscaler.reason_history": []string{},
+				},
+				Reason: "capped count from 1 to 7 to respect max step of 3",
+			},
+			name: "scale in above step",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.inputAction.CapStep(tc.inputCurrent, tc.inputMaxStep)
+			assert.Equal(t, tc.expectedOutputAction, tc.inputAction)
+		})
+	}
+}
+
+func TestAction_SetCostEstimate(t *testing.T) {
+	a := &ScalingAction{Count: 4}
+	assert.Equal(t, -1.0, a.SetCostEstimate(6, 0.5))
+	assert.Equal(t, map[string]interface{}{
+		"nomad_autoscaler.cost.hourly":       2.0,
+		"nomad_autoscaler.cost.hourly_delta": -1.0,
+	}, a.Meta)
+
+	dryRun := &ScalingAction{Count: StrategyActionMetaValueDryRunCount}
+	assert.Equal(t, 0.0, dryRun.SetCostEstimate(6, 0.5))
+	assert.Nil(t, dryRun.Meta)
+}
+
+func TestAction_CorrelationID(t *testing.T) {
+	a := &ScalingAction{}
+	assert.Empty(t, a.CorrelationID())
+
+	a.SetCorrelationID("eval-id")
+	assert.Equal(t, "eval-id", a.CorrelationID())
+	assert.Equal(t, map[string]interface{}{"nomad_autoscaler.correlation_id": "eval-id"}, a.Meta)
+}
+
+func TestAction_SetCheckDetails(t *testing.T) {
+	a := &ScalingAction{}
+	check, strategy := a.CheckDetails()
+	assert.Empty(t, check)
+	assert.Empty(t, strategy)
+
+	a.SetCheckDetails("cpu", "target-value", TimestampedMetrics{{Value: 70}, {Value: 85.5}})
+	check, strategy = a.CheckDetails()
+	assert.Equal(t, "cpu", check)
+	assert.Equal(t, "target-value", strategy)
+	assert.Equal(t, map[string]interface{}{
+		"nomad_autoscaler.check":        "cpu",
+		"nomad_autoscaler.check.metric": 85.5,
+		"nomad_autoscaler.strategy":     "target-value",
+	}, a.Meta)
+
+	// The metric is omitted when the check didn't read any.
+	a = &ScalingAction{}
+	a.SetCheckDetails("cpu", "fixed-value", nil)
+	assert.NotContains(t, a.Meta, "nomad_autoscaler.check.metric")
+}
+
+func TestAction_SetPolicyMeta(t *testing.T) {
+	a := &ScalingAction{}
+	a.SetPolicyMeta(nil)
+	assert.Nil(t, a.Meta)
+
+	a.SetPolicyMeta(map[string]string{"team": "platform"})
+	assert.Equal(t, map[string]interface{}{
+		"nomad_autoscaler.policy_meta": map[string]string{"team": "platform"},
+	}, a.Meta)
+}
+
+func TestAction_Explanation(t *testing.T) {
+	a := &ScalingAction{}
+	assert.Nil(t, a.Explanation())
+
+	e := ScalingActionExplanation{Ratio: 1.5, Samples: 3, Details: map[string]interface{}{"target": 70.0}}
+	a.SetExplanation(e)
+	assert.Equal(t, &e, a.Explanation())
+
+	// Meta sent by external plugins is JSON encoded, so the explanation is
+	// decoded as a map.
+	raw, err := json.Marshal(a.Meta)
+	assert.NoError(t, err)
+
+	decoded := &ScalingAction{}
+	assert.NoError(t, json.Unmarshal(raw, &decoded.Meta))
+	assert.IsType(t, map[string]interface{}{}, decoded.Meta["nomad_autoscaler.explanation"])
+	assert.Equal(t, &e, decoded.Explanation())
+
+	// Invalid values are ignored.
+	a.Meta["nomad_autoscaler.explanation"] = "not-an-explanation"
+	assert.Nil(t, a.Explanation())
+}
+
+func TestAction_DesiredCount(t *testing.T) {
+	a := &ScalingAction{Count: 3, Meta: map[string]interface{}{}}
+	assert.Equal(t, int64(3), a.DesiredCount())
+
+	a.SetDryRun()
+	assert.Equal(t, int64(3), a.DesiredCount())
+}
+
 func TestAction_pushReason(t *testing.T) {
 	testCases := []struct {
 		inputAction          *ScalingAction
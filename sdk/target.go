@@ -4,9 +4,16 @@
 package sdk
 
 import (
+	"errors"
 	"fmt"
+	"math"
 )
 
+// ErrTargetCapacityReached is the error used when a scaling action can't be
+// performed because the target reached the capacity limits of its provider,
+// such as the max size of a cloud autoscaling group or an exhausted quota.
+var ErrTargetCapacityReached = errors.New("provider quota reached")
+
 // TargetScalingNoOpError is a special error type that can be used by target
 // plugins to indicate that a scaling request didn't result in any action, but
 // didn't fail either.
@@ -27,6 +34,43 @@ func (n *TargetScalingNoOpError) Error() string {
 	return n.Err.Error()
 }
 
+// Unwrap returns the underlying error.
+func (n *TargetScalingNoOpError) Unwrap() error {
+	return n.Err
+}
+
+// TargetCapacity is the response object when performing the optional
+// Capacity call of the target plugin interface. It describes the limits the
+// remote provider imposes on the target count. Nil fields indicate the
+// provider doesn't impose the limit.
+type TargetCapacity struct {
+
+	// MaxCount is the maximum count the provider allows for the target, such
+	// as the max size of a cloud autoscaling group.
+	MaxCount *int64
+
+	// Remaining is the number of units that can still be added to the target
+	// before a provider quota is exhausted.
+	Remaining *int64
+}
+
+// Ceiling returns the highest count the target can be scaled to from the
+// current count, and false if the provider doesn't impose any limit.
+func (c *TargetCapacity) Ceiling(current int64) (int64, bool) {
+	if c == nil || (c.MaxCount == nil && c.Remaining == nil) {
+		return 0, false
+	}
+
+	ceiling := int64(math.MaxInt64)
+	if c.MaxCount != nil {
+		ceiling = *c.MaxCount
+	}
+	if c.Remaining != nil && current+*c.Remaining < ceiling {
+		ceiling = current + *c.Remaining
+	}
+	return ceiling, true
+}
+
 // TargetStatus is the response object when performing the Status call of the
 // target plugin interface. The response details key information about the
 // current state of the target.
@@ -54,6 +98,24 @@ const (
 	// cooldown where out-of-band scaling activities have been triggered.
 	TargetStatusMetaKeyLastEvent = "nomad_autoscaler.last_event"
 
+	// TargetStatusMetaKeyInProgress is an optional meta key that can be added
+	// to the status return. The value is a comma separated list of the
+	// operations the remote provider is currently performing on the target,
+	// such as a deployment or a scaling activity.
+	TargetStatusMetaKeyInProgress = "nomad_autoscaler.in_progress"
+
+	// TargetStatusMetaKeyUnhealthy is an optional meta key that can be added
+	// to the status return. The value is the number of instances or
+	// allocations of the target which the remote provider reports as
+	// unhealthy.
+	TargetStatusMetaKeyUnhealthy = "nomad_autoscaler.unhealthy"
+
+	// TargetStatusMetaKeyVersion is an optional meta key that can be added to
+	// the status return. The value identifies the current version of the
+	// target definition, such as the job version or the launch template
+	// version of a cluster.
+	TargetStatusMetaKeyVersion = "nomad_autoscaler.version"
+
 	// TargetConfigKeyNamespace is the config key used within horizontal app
 	// scaling to identify the Nomad namespace targeted for autoscaling.
 	TargetConfigKeyNamespace = "Namespace"
@@ -66,6 +128,22 @@ const (
 	// scaling to identify the Nomad job group targeted for autoscaling.
 	TargetConfigKeyTaskGroup = "Group"
 
+	// TargetConfigKeyTask is the config key used within vertical app scaling
+	// to identify the Nomad task targeted for autoscaling.
+	TargetConfigKeyTask = "Task"
+
+	// TargetConfigKeyResource is the config key used within vertical app
+	// scaling to identify the task resource targeted for autoscaling. It is
+	// set by the Autoscaler based on the policy type and is either
+	// TargetResourceCPU or TargetResourceMemory.
+	TargetConfigKeyResource = "Resource"
+
+	// TargetResourceCPU and TargetResourceMemory are the task resources that
+	// can be targeted by vertical app scaling. CPU is measured in MHz and
+	// memory in MB.
+	TargetResourceCPU    = "cpu"
+	TargetResourceMemory = "memory"
+
 	// TargetConfigKeyClass is the horizontal cluster scaling target
 	// config key which identifies nodes as part of a pool of resources using
 	// the clients node_class configuration param.
@@ -95,6 +173,18 @@ const (
 	// within their provider.
 	TargetConfigKeyNodePurge = "node_purge"
 
+	// TargetConfigKeyNodePlacementCheck is the optional horizontal cluster
+	// scaling target config key which, when true, makes the Autoscaler skip
+	// nodes selected for scale in whose allocations would not fit on the
+	// remaining nodes of the pool.
+	TargetConfigKeyNodePlacementCheck = "node_placement_check"
+
+	// TargetConfigKeyUnitHourlyCost is the optional target config key which
+	// defines the hourly cost of a single unit of the target count, such as
+	// the price of an instance or of an allocation. When set, the Autoscaler
+	// estimates the cost impact of each scaling action.
+	TargetConfigKeyUnitHourlyCost = "unit_hourly_cost"
+
 	// TargetConfigNodeSelectorStrategy is the optional node target config
 	// option which dictates how the Nomad Autoscaler selects nodes when
 	// scaling in.
@@ -130,6 +220,32 @@ const (
 	TargetNodeSelectorStrategyOldestCreateIndex = "oldest_create_index"
 )
 
+// TargetStatusMetaKeys is the list of standard TargetStatus meta keys which
+// targets use to describe the state of the target from the remote providers
+// view point. These are surfaced to operators in the policy status API and
+// scaling events.
+var TargetStatusMetaKeys = []string{
+	TargetStatusMetaKeyLastEvent,
+	TargetStatusMetaKeyInProgress,
+	TargetStatusMetaKeyUnhealthy,
+	TargetStatusMetaKeyVersion,
+}
+
+// StandardTargetStatusMeta returns the standard keys and values found within
+// the passed TargetStatus meta. It returns nil if none are found.
+func StandardTargetStatusMeta(meta map[string]string) map[string]string {
+	var out map[string]string
+	for _, key := range TargetStatusMetaKeys {
+		if v, ok := meta[key]; ok {
+			if out == nil {
+				out = make(map[string]string)
+			}
+			out[key] = v
+		}
+	}
+	return out
+}
+
 // TargetConfigConflictingClusterParams is a list containing horizontal cluster
 // scaling target configuration options which conflict. This makes it easier to
 // create error messages.
@@ -0,0 +1,114 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package sdk
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStandardTargetStatusMeta(t *testing.T) {
+	testCases := []struct {
+		inputMeta          map[string]string
+		expectedOutputMeta map[string]string
+		name               string
+	}{
+		{
+			inputMeta:          nil,
+			expectedOutputMeta: nil,
+			name:               "nil meta",
+		},
+		{
+			inputMeta: map[string]string{
+				"nomad_autoscaler.target.nomad.example.stopped": "false",
+			},
+			expectedOutputMeta: nil,
+			name:               "no standard keys",
+		},
+		{
+			inputMeta: map[string]string{
+				"nomad_autoscaler.target.nomad.example.stopped": "false",
+				TargetStatusMetaKeyLastEvent:                    "1700000000000000000",
+				TargetStatusMetaKeyInProgress:                   "deployment",
+				TargetStatusMetaKeyUnhealthy:                    "2",
+				TargetStatusMetaKeyVersion:                      "4",
+			},
+			expectedOutputMeta: map[string]string{
+				TargetStatusMetaKeyLastEvent:  "1700000000000000000",
+				TargetStatusMetaKeyInProgress: "deployment",
+				TargetStatusMetaKeyUnhealthy:  "2",
+				TargetStatusMetaKeyVersion:    "4",
+			},
+			name: "standard keys",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expectedOutputMeta, StandardTargetStatusMeta(tc.inputMeta), tc.name)
+		})
+	}
+}
+
+func TestTargetCapacity_Ceiling(t *testing.T) {
+	testCases := []struct {
+		inputCapacity   *TargetCapacity
+		inputCurrent    int64
+		expectedCeiling int64
+		expectedOK      bool
+		name            string
+	}{
+		{
+			inputCapacity: nil,
+			inputCurrent:  5,
+			name:          "nil capacity",
+		},
+		{
+			inputCapacity: &TargetCapacity{},
+			inputCurrent:  5,
+			name:          "no limits",
+		},
+		{
+			inputCapacity:   &TargetCapacity{MaxCount: int64ToPtr(10)},
+			inputCurrent:    5,
+			expectedCeiling: 10,
+			expectedOK:      true,
+			name:            "max count",
+		},
+		{
+			inputCapacity:   &TargetCapacity{Remaining: int64ToPtr(2)},
+			inputCurrent:    5,
+			expectedCeiling: 7,
+			expectedOK:      true,
+			name:            "remaining quota",
+		},
+		{
+			inputCapacity:   &TargetCapacity{MaxCount: int64ToPtr(10), Remaining: int64ToPtr(8)},
+			inputCurrent:    5,
+			expectedCeiling: 10,
+			expectedOK:      true,
+			name:            "max count below remaining quota",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ceiling, ok := tc.inputCapacity.Ceiling(tc.inputCurrent)
+			assert.Equal(t, tc.expectedCeiling, ceiling)
+			assert.Equal(t, tc.expectedOK, ok)
+		})
+	}
+}
+
+func TestTargetScalingNoOpError_Unwrap(t *testing.T) {
+	err := NewTargetScalingNoOpError("%w: max count of 10", ErrTargetCapacityReached)
+	assert.True(t, errors.Is(err, ErrTargetCapacityReached))
+	assert.Equal(t, "provider quota reached: max count of 10", err.Error())
+}
+
+func int64ToPtr(v int64) *int64 {
+	return &v
+}
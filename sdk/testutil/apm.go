@@ -0,0 +1,139 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+// Package testutil provides plugin and policy fixtures which allow plugin
+// authors to write integration tests against the autoscaler evaluation
+// pipeline.
+package testutil
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	hclog "github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/nomad-autoscaler/plugins/base"
+	"github.com/hashicorp/nomad-autoscaler/sdk"
+)
+
+// QueryResult is the scripted result of a single APM query.
+type QueryResult struct {
+	Metrics sdk.TimestampedMetrics
+	Err     error
+}
+
+// APM is an APM plugin fixture which returns scripted results for each query.
+// The results of a query are returned in order, and the last one is repeated
+// once the script is exhausted. The query time range is ignored, so scripted
+// metrics are returned as is. It is safe for concurrent use.
+type APM struct {
+	name string
+
+	lock    sync.Mutex
+	config  map[string]string
+	scripts map[string][]QueryResult
+	calls   map[string]int
+	queries []string
+}
+
+// NewAPM returns a new APM fixture which reports name as its plugin name.
+func NewAPM(name string) *APM {
+	return &APM{
+		name:    name,
+		scripts: make(map[string][]QueryResult),
+		calls:   make(map[string]int),
+	}
+}
+
+// Factory returns the plugin factory of the fixture, which can be registered
+// with the plugin manager. The factory always returns the same instance so
+// tests can keep scripting it once registered.
+func (a *APM) Factory() func(hclog.Logger) interface{} {
+	return func(hclog.Logger) interface{} { return a }
+}
+
+// Script sets the results returned by query, restarting its script.
+func (a *APM) Script(query string, results ...QueryResult) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	a.scripts[query] = results
+	a.calls[query] = 0
+}
+
+// ScriptSeries is a helper to script query to return a single series with
+// the values.
+func (a *APM) ScriptSeries(query string, start time.Time, step time.Duration, values ...float64) {
+	a.Script(query, QueryResult{Metrics: Series(start, step, values...)})
+}
+
+// Queries returns the queries performed, in order.
+func (a *APM) Queries() []string {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	return append([]string(nil), a.queries...)
+}
+
+// Config returns the last config set on the plugin.
+func (a *APM) Config() map[string]string {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	return a.config
+}
+
+// PluginInfo satisfies the PluginInfo function on the base.Base interface.
+func (a *APM) PluginInfo() (*base.PluginInfo, error) {
+	return &base.PluginInfo{Name: a.name, PluginType: sdk.PluginTypeAPM}, nil
+}
+
+// SetConfig satisfies the SetConfig function on the base.Base interface.
+func (a *APM) SetConfig(config map[string]string) error {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	a.config = config
+	return nil
+}
+
+// Query satisfies the Query function on the apm.APM interface.
+func (a *APM) Query(query string, _ sdk.TimeRange) (sdk.TimestampedMetrics, error) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	a.queries = append(a.queries, query)
+
+	script, ok := a.scripts[query]
+	if !ok || len(script) == 0 {
+		return nil, fmt.Errorf("no results scripted for query %q", query)
+	}
+
+	i := a.calls[query]
+	if i >= len(script) {
+		i = len(script) - 1
+	}
+	a.calls[query]++
+
+	return script[i].Metrics, script[i].Err
+}
+
+// QueryMultiple satisfies the QueryMultiple function on the apm.APM
+// interface. The scripted series is returned as the only result.
+func (a *APM) QueryMultiple(query string, r sdk.TimeRange) ([]sdk.TimestampedMetrics, error) {
+	m, err := a.Query(query, r)
+	if err != nil {
+		return nil, err
+	}
+	return []sdk.TimestampedMetrics{m}, nil
+}
+
+// Series returns a series with the values, starting at start and separated
+// by step.
+func Series(start time.Time, step time.Duration, values ...float64) sdk.TimestampedMetrics {
+	m := make(sdk.TimestampedMetrics, 0, len(values))
+	for i, v := range values {
+		m = append(m, sdk.TimestampedMetric{
+			Timestamp: start.Add(time.Duration(i) * step),
+			Value:     v,
+		})
+	}
+	return m
+}
@@ -0,0 +1,48 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package testutil
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/nomad-autoscaler/sdk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPM_Query(t *testing.T) {
+	start := time.Date(2024, 6, 1, 14, 30, 0, 0, time.UTC)
+
+	a := NewAPM("test-apm")
+	a.Script("cpu",
+		QueryResult{Metrics: Series(start, time.Minute, 10, 20)},
+		QueryResult{Err: errors.New("timeout")},
+		QueryResult{Metrics: Series(start, time.Minute, 30)},
+	)
+
+	m, err := a.Query("cpu", sdk.TimeRange{})
+	require.NoError(t, err)
+	assert.Equal(t, sdk.TimestampedMetrics{
+		{Timestamp: start, Value: 10},
+		{Timestamp: start.Add(time.Minute), Value: 20},
+	}, m)
+
+	_, err = a.Query("cpu", sdk.TimeRange{})
+	assert.EqualError(t, err, "timeout")
+
+	// The last result is repeated once the script is exhausted.
+	for i := 0; i < 2; i++ {
+		multiple, err := a.QueryMultiple("cpu", sdk.TimeRange{})
+		require.NoError(t, err)
+		require.Len(t, multiple, 1)
+		assert.Equal(t, 30.0, multiple[0][0].Value)
+	}
+
+	_, err = a.Query("memory", sdk.TimeRange{})
+	assert.ErrorContains(t, err, `no results scripted for query "memory"`)
+
+	assert.Equal(t, []string{"cpu", "cpu", "cpu", "cpu", "memory"}, a.Queries())
+}
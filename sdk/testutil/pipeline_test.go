@@ -0,0 +1,80 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package testutil_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	hclog "github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/nomad-autoscaler/agent/config"
+	"github.com/hashicorp/nomad-autoscaler/plugins/manager"
+	"github.com/hashicorp/nomad-autoscaler/policy"
+	"github.com/hashicorp/nomad-autoscaler/policyeval"
+	"github.com/hashicorp/nomad-autoscaler/sdk"
+	"github.com/hashicorp/nomad-autoscaler/sdk/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestPipeline runs a policy through the policy handler and evaluation
+// workers using the APM and target fixtures, like a plugin author would do to
+// test their plugin with the autoscaler.
+func TestPipeline(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	log := hclog.NewNullLogger()
+
+	apm := testutil.NewAPM("test-apm")
+	apm.Script(`load{job="api"}`,
+		testutil.QueryResult{Metrics: testutil.Series(time.Now(), time.Second, 2, 4)})
+
+	target := testutil.NewTarget("test-target", 1)
+
+	pm := manager.NewPluginManager(log, "", config.PermissionChecksWarn, 0, map[string][]*config.Plugin{
+		sdk.PluginTypeStrategy: {{Name: "pass-through", Driver: "pass-through"}},
+	})
+	pm.RegisterInternalPlugin("test-apm", sdk.PluginTypeAPM, apm.Factory(), nil)
+	pm.RegisterInternalPlugin("test-target", sdk.PluginTypeTarget, target.Factory(), nil)
+	require.NoError(t, pm.Load())
+	defer pm.KillPlugins()
+
+	p := testutil.NewPolicy("policy1").
+		WithLimits(1, 3).
+		WithTarget("test-target", map[string]string{"Job": "api"}).
+		WithCheck(testutil.Check("load", "test-apm", `load{job="{{ .target.Job }}"}`, "pass-through", nil)).
+		Build()
+	source := testutil.NewPolicySource(p)
+
+	policyManager := policy.NewManager(log, map[policy.SourceName]policy.Source{testutil.SourceName: source},
+		pm, time.Minute, nil, nil)
+	evalCh := make(chan *sdk.ScalingEvaluation, 10)
+	go policyManager.Run(ctx, evalCh)
+
+	broker := policyeval.NewBroker(log, time.Minute, 1)
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case eval := <-evalCh:
+				broker.Enqueue(eval)
+			}
+		}
+	}()
+
+	worker := policyeval.NewBaseWorker(log, pm, policyManager, broker, nil, false, sdk.ScalingPolicyTypeHorizontal)
+	go worker.Run(ctx)
+
+	// The metric is passed through to the target, capped by the policy max.
+	require.Eventually(t, func() bool { return target.Count() == 3 }, 5*time.Second, 10*time.Millisecond)
+
+	actions := target.Actions()
+	require.NotEmpty(t, actions)
+	assert.Equal(t, int64(3), actions[0].Count)
+	assert.Equal(t, sdk.ScaleDirection(sdk.ScaleDirectionUp), actions[0].Direction)
+	assert.Contains(t, apm.Queries(), `load{job="api"}`)
+}
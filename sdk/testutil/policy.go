@@ -0,0 +1,110 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package testutil
+
+import (
+	"time"
+
+	"github.com/hashicorp/nomad-autoscaler/sdk"
+)
+
+const (
+	// DefaultEvaluationInterval is the evaluation interval of the policies
+	// built by PolicyBuilder. It is short so tests don't wait for long.
+	DefaultEvaluationInterval = 100 * time.Millisecond
+
+	// DefaultQueryWindow is the query window of the checks built by Check.
+	DefaultQueryWindow = time.Minute
+)
+
+// PolicyBuilder builds scaling policies for tests. Policies are enabled,
+// horizontal and scale between 0 and 10 unless configured otherwise.
+type PolicyBuilder struct {
+	p *sdk.ScalingPolicy
+}
+
+// NewPolicy returns a new PolicyBuilder for a policy with the ID.
+func NewPolicy(id string) *PolicyBuilder {
+	return &PolicyBuilder{
+		p: &sdk.ScalingPolicy{
+			ID:                 id,
+			Type:               sdk.ScalingPolicyTypeHorizontal,
+			Enabled:            true,
+			Min:                0,
+			Max:                10,
+			EvaluationInterval: DefaultEvaluationInterval,
+			Meta:               make(map[string]string),
+		},
+	}
+}
+
+// WithType sets the policy type.
+func (b *PolicyBuilder) WithType(t string) *PolicyBuilder {
+	b.p.Type = t
+	return b
+}
+
+// WithLimits sets the policy min and max.
+func (b *PolicyBuilder) WithLimits(min, max int64) *PolicyBuilder {
+	b.p.Min = min
+	b.p.Max = max
+	return b
+}
+
+// WithCooldown sets the policy cooldown.
+func (b *PolicyBuilder) WithCooldown(d time.Duration) *PolicyBuilder {
+	b.p.Cooldown = d
+	return b
+}
+
+// WithEvaluationInterval sets the policy evaluation interval.
+func (b *PolicyBuilder) WithEvaluationInterval(d time.Duration) *PolicyBuilder {
+	b.p.EvaluationInterval = d
+	return b
+}
+
+// WithMeta sets a policy meta value.
+func (b *PolicyBuilder) WithMeta(key, value string) *PolicyBuilder {
+	b.p.Meta[key] = value
+	return b
+}
+
+// WithTarget sets the target plugin and its config.
+func (b *PolicyBuilder) WithTarget(name string, config map[string]string) *PolicyBuilder {
+	if config == nil {
+		config = make(map[string]string)
+	}
+	b.p.Target = &sdk.ScalingPolicyTarget{Name: name, Config: config}
+	return b
+}
+
+// WithCheck adds a check to the policy. Checks are usually built using
+// Check.
+func (b *PolicyBuilder) WithCheck(c *sdk.ScalingPolicyCheck) *PolicyBuilder {
+	b.p.Checks = append(b.p.Checks, c)
+	return b
+}
+
+// Build returns the policy. The builder must not be used afterwards.
+func (b *PolicyBuilder) Build() *sdk.ScalingPolicy {
+	return b.p
+}
+
+// Check returns a check which queries source and runs the strategy using the
+// config.
+func Check(name, source, query, strategy string, config map[string]string) *sdk.ScalingPolicyCheck {
+	if config == nil {
+		config = make(map[string]string)
+	}
+	return &sdk.ScalingPolicyCheck{
+		Name:        name,
+		Source:      source,
+		Query:       query,
+		QueryWindow: DefaultQueryWindow,
+		Strategy: &sdk.ScalingPolicyStrategy{
+			Name:   strategy,
+			Config: config,
+		},
+	}
+}
@@ -0,0 +1,145 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package testutil
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/hashicorp/nomad-autoscaler/policy"
+	"github.com/hashicorp/nomad-autoscaler/sdk"
+)
+
+// SourceName is the name of the PolicySource fixture.
+const SourceName policy.SourceName = "testutil"
+
+// Ensure PolicySource satisfies the Source interface.
+var _ policy.Source = (*PolicySource)(nil)
+
+// PolicySource is an in-memory policy source fixture. It allows tests to feed
+// policies, usually built using PolicyBuilder, to the policy manager and to
+// update or remove them while the manager is running.
+type PolicySource struct {
+	lock     sync.RWMutex
+	policies map[policy.PolicyID]*sdk.ScalingPolicy
+
+	// updateCh is closed, and replaced, when the policies change so monitors
+	// can send the new IDs and policies.
+	updateCh chan struct{}
+}
+
+// NewPolicySource returns a new PolicySource holding the policies.
+func NewPolicySource(policies ...*sdk.ScalingPolicy) *PolicySource {
+	s := &PolicySource{
+		policies: make(map[policy.PolicyID]*sdk.ScalingPolicy),
+		updateCh: make(chan struct{}),
+	}
+	for _, p := range policies {
+		s.policies[policy.PolicyID(p.ID)] = p
+	}
+	return s
+}
+
+// SetPolicy adds or replaces a policy.
+func (s *PolicySource) SetPolicy(p *sdk.ScalingPolicy) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.policies[policy.PolicyID(p.ID)] = p
+	s.notifyLocked()
+}
+
+// RemovePolicy removes the policy with the ID.
+func (s *PolicySource) RemovePolicy(id string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	delete(s.policies, policy.PolicyID(id))
+	s.notifyLocked()
+}
+
+// notifyLocked wakes up the monitors. It must be called while holding the
+// lock.
+func (s *PolicySource) notifyLocked() {
+	close(s.updateCh)
+	s.updateCh = make(chan struct{})
+}
+
+// Name satisfies the Name function of the policy.Source interface.
+func (s *PolicySource) Name() policy.SourceName {
+	return SourceName
+}
+
+// ReloadIDsMonitor satisfies the ReloadIDsMonitor function of the
+// policy.Source interface.
+func (s *PolicySource) ReloadIDsMonitor() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.notifyLocked()
+}
+
+// MonitorIDs satisfies the MonitorIDs function of the policy.Source
+// interface. The policy IDs are sent when the monitor starts and every time
+// the policies change.
+func (s *PolicySource) MonitorIDs(ctx context.Context, req policy.MonitorIDsReq) {
+	for {
+		s.lock.RLock()
+		ids := make([]policy.PolicyID, 0, len(s.policies))
+		for id := range s.policies {
+			ids = append(ids, id)
+		}
+		updateCh := s.updateCh
+		s.lock.RUnlock()
+
+		sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+		select {
+		case <-ctx.Done():
+			return
+		case req.ResultCh <- policy.IDMessage{IDs: ids, Source: SourceName}:
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-updateCh:
+		}
+	}
+}
+
+// MonitorPolicy satisfies the MonitorPolicy function of the policy.Source
+// interface. The policy is sent when the monitor starts and every time it is
+// set.
+func (s *PolicySource) MonitorPolicy(ctx context.Context, req policy.MonitorPolicyReq) {
+	defer close(req.ResultCh)
+	defer close(req.ErrCh)
+
+	var last *sdk.ScalingPolicy
+	for {
+		s.lock.RLock()
+		p, updateCh := s.policies[req.ID], s.updateCh
+		s.lock.RUnlock()
+
+		switch {
+		case p == nil:
+			policy.HandleSourceError(SourceName, fmt.Errorf("failed to get policy %s", req.ID), req.ErrCh)
+		case p != last:
+			select {
+			case <-ctx.Done():
+				return
+			case req.ResultCh <- *p:
+			}
+			last = p
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-req.ReloadCh:
+		case <-updateCh:
+		}
+	}
+}
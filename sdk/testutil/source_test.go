@@ -0,0 +1,56 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package testutil
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/nomad-autoscaler/policy"
+	"github.com/hashicorp/nomad-autoscaler/sdk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPolicySource(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	s := NewPolicySource(NewPolicy("policy1").Build())
+
+	idCh := make(chan policy.IDMessage)
+	go s.MonitorIDs(ctx, policy.MonitorIDsReq{ErrCh: make(chan error, 1), ResultCh: idCh})
+
+	msg := <-idCh
+	assert.Equal(t, SourceName, msg.Source)
+	assert.Equal(t, []policy.PolicyID{"policy1"}, msg.IDs)
+
+	resultCh := make(chan sdk.ScalingPolicy)
+	go s.MonitorPolicy(ctx, policy.MonitorPolicyReq{
+		ID:       "policy1",
+		ErrCh:    make(chan error, 1),
+		ReloadCh: make(chan struct{}),
+		ResultCh: resultCh,
+	})
+	assert.Equal(t, int64(10), (<-resultCh).Max)
+
+	// Updated policies are sent to the monitors.
+	s.SetPolicy(NewPolicy("policy1").WithLimits(1, 5).Build())
+	select {
+	case p := <-resultCh:
+		assert.Equal(t, int64(5), p.Max)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout waiting for policy update")
+	}
+	<-idCh
+
+	s.SetPolicy(NewPolicy("policy2").Build())
+	msg = <-idCh
+	assert.Equal(t, []policy.PolicyID{"policy1", "policy2"}, msg.IDs)
+
+	s.RemovePolicy("policy1")
+	msg = <-idCh
+	require.Equal(t, []policy.PolicyID{"policy2"}, msg.IDs)
+}
@@ -0,0 +1,149 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package testutil
+
+import (
+	"sync"
+
+	hclog "github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/nomad-autoscaler/plugins/base"
+	"github.com/hashicorp/nomad-autoscaler/sdk"
+)
+
+// StatusResult is the scripted result of a single target status call.
+type StatusResult struct {
+	Status *sdk.TargetStatus
+	Err    error
+}
+
+// Target is a target plugin fixture which keeps the count of the target in
+// memory. Scale actions update the count unless a scale error is scripted.
+// Status returns the scripted results in order, which allows tests to model
+// status transitions such as a target which isn't ready for a while after
+// scaling, and the current count once the script is exhausted. It is safe for
+// concurrent use.
+type Target struct {
+	name string
+
+	lock      sync.Mutex
+	config    map[string]string
+	count     int64
+	meta      map[string]string
+	statuses  []StatusResult
+	scaleErrs []error
+	actions   []sdk.ScalingAction
+}
+
+// NewTarget returns a new Target fixture which reports name as its plugin
+// name and starts with count.
+func NewTarget(name string, count int64) *Target {
+	return &Target{
+		name:  name,
+		count: count,
+		meta:  make(map[string]string),
+	}
+}
+
+// Factory returns the plugin factory of the fixture, which can be registered
+// with the plugin manager. The factory always returns the same instance so
+// tests can keep scripting it once registered.
+func (t *Target) Factory() func(hclog.Logger) interface{} {
+	return func(hclog.Logger) interface{} { return t }
+}
+
+// ScriptStatus queues results returned by the next Status calls.
+func (t *Target) ScriptStatus(results ...StatusResult) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.statuses = append(t.statuses, results...)
+}
+
+// ScriptScaleErrors queues errors returned by the next Scale calls. A nil
+// error lets the corresponding action succeed.
+func (t *Target) ScriptScaleErrors(errs ...error) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.scaleErrs = append(t.scaleErrs, errs...)
+}
+
+// SetMeta sets a meta value returned by Status once the script is exhausted.
+func (t *Target) SetMeta(key, value string) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.meta[key] = value
+}
+
+// Count returns the current count of the target.
+func (t *Target) Count() int64 {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	return t.count
+}
+
+// Actions returns the actions received by Scale, in order, including the
+// failed ones.
+func (t *Target) Actions() []sdk.ScalingAction {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	return append([]sdk.ScalingAction(nil), t.actions...)
+}
+
+// Config returns the last config set on the plugin.
+func (t *Target) Config() map[string]string {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	return t.config
+}
+
+// PluginInfo satisfies the PluginInfo function on the base.Base interface.
+func (t *Target) PluginInfo() (*base.PluginInfo, error) {
+	return &base.PluginInfo{Name: t.name, PluginType: sdk.PluginTypeTarget}, nil
+}
+
+// SetConfig satisfies the SetConfig function on the base.Base interface.
+func (t *Target) SetConfig(config map[string]string) error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.config = config
+	return nil
+}
+
+// Scale satisfies the Scale function on the target.Target interface.
+func (t *Target) Scale(action sdk.ScalingAction, _ map[string]string) error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	t.actions = append(t.actions, action)
+
+	if len(t.scaleErrs) > 0 {
+		err := t.scaleErrs[0]
+		t.scaleErrs = t.scaleErrs[1:]
+		if err != nil {
+			return err
+		}
+	}
+
+	if action.Direction != sdk.ScaleDirectionNone {
+		t.count = action.Count
+	}
+	return nil
+}
+
+// Status satisfies the Status function on the target.Target interface.
+func (t *Target) Status(_ map[string]string) (*sdk.TargetStatus, error) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if len(t.statuses) > 0 {
+		r := t.statuses[0]
+		t.statuses = t.statuses[1:]
+		return r.Status, r.Err
+	}
+
+	meta := make(map[string]string, len(t.meta))
+	for k, v := range t.meta {
+		meta[k] = v
+	}
+	return &sdk.TargetStatus{Ready: true, Count: t.count, Meta: meta}, nil
+}
@@ -0,0 +1,53 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package testutil
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/hashicorp/nomad-autoscaler/sdk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTarget(t *testing.T) {
+	target := NewTarget("test-target", 2)
+	target.SetMeta(sdk.TargetStatusMetaKeyLastEvent, "1")
+
+	status, err := target.Status(nil)
+	require.NoError(t, err)
+	assert.Equal(t, &sdk.TargetStatus{
+		Ready: true,
+		Count: 2,
+		Meta:  map[string]string{sdk.TargetStatusMetaKeyLastEvent: "1"},
+	}, status)
+
+	// Failed actions don't modify the count.
+	target.ScriptScaleErrors(errors.New("quota exceeded"), nil)
+	assert.EqualError(t, target.Scale(sdk.ScalingAction{Count: 5, Direction: sdk.ScaleDirectionUp}, nil), "quota exceeded")
+	assert.Equal(t, int64(2), target.Count())
+
+	require.NoError(t, target.Scale(sdk.ScalingAction{Count: 5, Direction: sdk.ScaleDirectionUp}, nil))
+	assert.Equal(t, int64(5), target.Count())
+	assert.Len(t, target.Actions(), 2)
+
+	// Scripted statuses are returned before the current state.
+	target.ScriptStatus(
+		StatusResult{Status: &sdk.TargetStatus{Ready: false, Count: 2}},
+		StatusResult{Err: errors.New("unavailable")},
+	)
+
+	status, err = target.Status(nil)
+	require.NoError(t, err)
+	assert.False(t, status.Ready)
+
+	_, err = target.Status(nil)
+	assert.EqualError(t, err, "unavailable")
+
+	status, err = target.Status(nil)
+	require.NoError(t, err)
+	assert.True(t, status.Ready)
+	assert.Equal(t, int64(5), status.Count)
+}